@@ -3,12 +3,19 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"procodus.dev/demo-app/internal/backend"
+	applog "procodus.dev/demo-app/pkg/logger"
+	"procodus.dev/demo-app/pkg/metrics"
+	"procodus.dev/demo-app/pkg/objectstore"
 )
 
 var backendCmd = &cobra.Command{
@@ -26,18 +33,84 @@ func init() {
 	rootCmd.AddCommand(backendCmd)
 
 	// Backend-specific flags
+	backendCmd.Flags().String("db-driver", backend.DriverPostgres, "Database driver (postgres, sqlite)")
 	backendCmd.Flags().String("db-host", "localhost", "PostgreSQL host")
 	backendCmd.Flags().Int("db-port", 5432, "PostgreSQL port")
 	backendCmd.Flags().String("db-user", "postgres", "PostgreSQL user")
 	backendCmd.Flags().String("db-password", "postgres", "PostgreSQL password")
-	backendCmd.Flags().String("db-name", "iot", "PostgreSQL database name")
+	backendCmd.Flags().String("db-name", "iot", "PostgreSQL database name, or the database file for sqlite")
 	backendCmd.Flags().String("db-sslmode", "disable", "PostgreSQL SSL mode")
-	backendCmd.Flags().String("rabbitmq-url", "amqp://localhost:5672", "RabbitMQ URL")
+	backendCmd.Flags().Int("db-connect-attempts", 1, "Number of database connection attempts at startup")
+	backendCmd.Flags().Duration("db-connect-backoff", time.Second, "Wait after the first failed database connection attempt, doubling up to 30s")
+	backendCmd.Flags().Bool("wait-for-db", false, "Retry connecting to the database at startup until it succeeds")
+	backendCmd.Flags().Bool("skip-schema-check", false, "Migrate the database schema at startup instead of failing when its version does not match")
+	backendCmd.Flags().Bool("db-prepare-stmt", false, "Prepare and cache every database statement per connection")
+	backendCmd.Flags().Bool("db-skip-default-transaction", false, "Run single creates, updates and deletes without a transaction")
+	backendCmd.Flags().Duration("db-statement-timeout", 0, "Cancel PostgreSQL statements running longer than this (0 = no limit)")
+	backendCmd.Flags().Duration("db-slow-query-threshold", 0, "Log database statements taking at least this long (0 = disabled)")
+	backendCmd.Flags().String("rabbitmq-url", "amqp://localhost:5672", "RabbitMQ URL (inmem:// for an in-process broker)")
 	backendCmd.Flags().String("queue-name", "sensor-data", "RabbitMQ queue name for sensor readings")
 	backendCmd.Flags().String("device-queue-name", "device-data", "RabbitMQ queue name for device creation messages")
+	backendCmd.Flags().Bool("durable-queues", false, "Declare durable queues and publish persistent messages (must match the generator)")
+	backendCmd.Flags().Bool("priority-queues", false, "Declare queues with message priorities (must match the generator)")
+	backendCmd.Flags().String("instance-id", "", "Identifies this replica in consumer tags and metrics (default: hostname)")
 	backendCmd.Flags().Int("grpc-port", 9090, "gRPC server port")
+	backendCmd.Flags().String("grpc-bind-address", "", "Host or IP the gRPC server listens on, or unix:///path for a Unix socket (empty = all interfaces)")
+	backendCmd.Flags().Bool("grpc-reflection", false, "Register the gRPC reflection service for tools like grpcurl")
+	backendCmd.Flags().Duration("grpc-keepalive-time", 0, "Idle time before the server pings a gRPC client (0 = gRPC default, 2h)")
+	backendCmd.Flags().Duration("grpc-keepalive-timeout", 0, "Time to wait for a keepalive ping ack (0 = gRPC default, 20s)")
+	backendCmd.Flags().Duration("grpc-max-connection-idle", 0, "Close gRPC connections idle for this long (0 = never)")
+	backendCmd.Flags().Duration("grpc-max-connection-age", 0, "Close gRPC connections this old (0 = never)")
+	backendCmd.Flags().Duration("grpc-max-connection-age-grace", 0, "Time for RPCs to finish after the max connection age (0 = unlimited)")
+	backendCmd.Flags().Duration("grpc-min-ping-interval", 0, "Shortest keepalive ping interval allowed from clients (0 = gRPC default, 5m)")
+	backendCmd.Flags().Bool("grpc-permit-ping-without-stream", false, "Allow client keepalive pings on connections without RPCs")
+	backendCmd.Flags().Int("grpc-max-recv-msg-size", 0, "Largest gRPC request in bytes (0 = gRPC default, 4 MiB)")
+	backendCmd.Flags().Int("grpc-max-send-msg-size", 0, "Largest gRPC response in bytes (0 = unlimited)")
+	backendCmd.Flags().Int("grpc-compress-min-size", 64*1024, "Gzip-compress gRPC responses of at least this many bytes for clients accepting gzip (0 = disabled)")
+	backendCmd.Flags().Duration("grpc-request-timeout", 10*time.Second, "Longest time a gRPC request may take; clients may set shorter deadlines")
+	backendCmd.Flags().Duration("grpc-export-timeout", 5*time.Minute, "Longest time an ExportReadings request may take")
+	backendCmd.Flags().Int("metrics-port", 0, "Prometheus metrics HTTP port (0 = disabled)")
+	backendCmd.Flags().String("metrics-bind-address", "", "Host or IP the metrics server listens on, or unix:///path for a Unix socket (empty = all interfaces)")
+	backendCmd.Flags().Bool("pprof", false, "Serve /debug/pprof on the metrics HTTP server")
+	backendCmd.Flags().Int64("quota-requests-per-minute", 0, "Max gRPC requests per tenant per minute (0 = unlimited)")
+	backendCmd.Flags().Int64("quota-device-requests-per-minute", 0, "Max gRPC requests per tenant and device per minute (0 = unlimited)")
+	backendCmd.Flags().Int64("quota-export-rows-per-day", 0, "Max sensor readings returned per tenant per day (0 = unlimited)")
+	backendCmd.Flags().Duration("battery-window", 7*24*time.Hour, "Reading history used to fit battery drain rates")
+	backendCmd.Flags().Duration("battery-interval", 15*time.Minute, "Interval between battery projection runs")
+	backendCmd.Flags().Duration("retention", 0, "How long sensor readings are kept, dropped per monthly partition (0 = forever)")
+	backendCmd.Flags().Duration("rollup-interval", 5*time.Minute, "Interval between reading rollup refreshes")
+	backendCmd.Flags().Duration("dedup-ttl", 7*24*time.Hour, "How long processed message IDs are kept to skip redeliveries")
+	backendCmd.Flags().String("timestamp-policy", backend.TimestampPolicyAccept, "Treatment of readings with out-of-bounds timestamps: accept, correct, clamp or reject")
+	backendCmd.Flags().Duration("max-future", time.Hour, "Largest allowed distance of a reading timestamp ahead of its receive time")
+	backendCmd.Flags().Duration("max-age", 0, "Largest allowed distance of a reading timestamp behind its receive time (0 = unlimited)")
+	backendCmd.Flags().String("smtp-addr", "", "SMTP server (host:port) for emailed reports (empty = email disabled)")
+	backendCmd.Flags().String("smtp-from", "", "Sender address of emailed reports")
+	backendCmd.Flags().String("smtp-username", "", "SMTP username (optional)")
+	backendCmd.Flags().String("smtp-password", "", "SMTP password (optional)")
+	backendCmd.Flags().String("export-bucket", "", "Bucket that exports and reports are written to (empty = bucket exports disabled)")
+	backendCmd.Flags().String("export-endpoint", "", "S3 endpoint URL, e.g. https://storage.googleapis.com for GCS (empty = AWS S3)")
+	backendCmd.Flags().String("export-region", "", "S3 region of the export bucket (empty = us-east-1)")
+	backendCmd.Flags().Bool("export-path-style", false, "Address the export bucket in the URL path, as MinIO requires")
+	backendCmd.Flags().String("export-access-key-id", "", "Access key ID of the export bucket (GCS: HMAC key)")
+	backendCmd.Flags().String("export-secret-access-key", "", "Secret access key of the export bucket (GCS: HMAC secret)")
+	backendCmd.Flags().String("export-prefix", "", "Key prefix of exported objects, e.g. exports/")
+	backendCmd.Flags().String("influx-url", "", "InfluxDB or line protocol write URL that saved readings are forwarded to (empty = disabled)")
+	backendCmd.Flags().String("influx-token", "", "Token sent to the InfluxDB write URL (optional)")
+	backendCmd.Flags().String("influx-measurement", "sensor_reading", "Measurement of the readings written to InfluxDB")
+	backendCmd.Flags().Int("influx-batch-size", 5000, "Most readings written to InfluxDB in one request")
+	backendCmd.Flags().Duration("influx-flush-interval", 5*time.Second, "Longest time a reading waits before it is written to InfluxDB")
+	backendCmd.Flags().Int("influx-max-pending", 100000, "Most readings buffered while InfluxDB is unavailable; newer ones are dropped")
+	backendCmd.Flags().String("remote-write-url", "", "Prometheus remote write URL that saved readings are exported to (empty = disabled)")
+	backendCmd.Flags().String("remote-write-token", "", "Bearer token sent to the remote write URL (optional)")
+	backendCmd.Flags().Int("remote-write-max-devices", 1000, "Most devices exported with remote write; readings of further devices are skipped")
+	backendCmd.Flags().Int("remote-write-batch-size", 5000, "Most readings sent in one remote write request")
+	backendCmd.Flags().Duration("remote-write-flush-interval", 5*time.Second, "Longest time a reading waits before it is sent with remote write")
+	backendCmd.Flags().Int("remote-write-max-pending", 100000, "Most readings buffered while the remote write endpoint is unavailable; newer ones are dropped")
 
 	// Bind flags to viper
+	if err := viper.BindPFlag("backend.db.driver", backendCmd.Flags().Lookup("db-driver")); err != nil {
+		log.Fatalf("failed to bind db-driver flag: %v", err)
+	}
 	if err := viper.BindPFlag("backend.db.host", backendCmd.Flags().Lookup("db-host")); err != nil {
 		log.Fatalf("failed to bind db-host flag: %v", err)
 	}
@@ -56,6 +129,30 @@ func init() {
 	if err := viper.BindPFlag("backend.db.sslmode", backendCmd.Flags().Lookup("db-sslmode")); err != nil {
 		log.Fatalf("failed to bind db-sslmode flag: %v", err)
 	}
+	if err := viper.BindPFlag("backend.db.connect_attempts", backendCmd.Flags().Lookup("db-connect-attempts")); err != nil {
+		log.Fatalf("failed to bind db-connect-attempts flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.db.connect_backoff", backendCmd.Flags().Lookup("db-connect-backoff")); err != nil {
+		log.Fatalf("failed to bind db-connect-backoff flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.db.wait", backendCmd.Flags().Lookup("wait-for-db")); err != nil {
+		log.Fatalf("failed to bind wait-for-db flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.db.skip_schema_check", backendCmd.Flags().Lookup("skip-schema-check")); err != nil {
+		log.Fatalf("failed to bind skip-schema-check flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.db.prepare_stmt", backendCmd.Flags().Lookup("db-prepare-stmt")); err != nil {
+		log.Fatalf("failed to bind db-prepare-stmt flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.db.skip_default_transaction", backendCmd.Flags().Lookup("db-skip-default-transaction")); err != nil {
+		log.Fatalf("failed to bind db-skip-default-transaction flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.db.statement_timeout", backendCmd.Flags().Lookup("db-statement-timeout")); err != nil {
+		log.Fatalf("failed to bind db-statement-timeout flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.db.slow_query_threshold", backendCmd.Flags().Lookup("db-slow-query-threshold")); err != nil {
+		log.Fatalf("failed to bind db-slow-query-threshold flag: %v", err)
+	}
 	if err := viper.BindPFlag("backend.rabbitmq.url", backendCmd.Flags().Lookup("rabbitmq-url")); err != nil {
 		log.Fatalf("failed to bind rabbitmq-url flag: %v", err)
 	}
@@ -65,46 +162,370 @@ func init() {
 	if err := viper.BindPFlag("backend.rabbitmq.device_queue_name", backendCmd.Flags().Lookup("device-queue-name")); err != nil {
 		log.Fatalf("failed to bind device-queue-name flag: %v", err)
 	}
+	if err := viper.BindPFlag("backend.rabbitmq.durable", backendCmd.Flags().Lookup("durable-queues")); err != nil {
+		log.Fatalf("failed to bind durable-queues flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.rabbitmq.priority", backendCmd.Flags().Lookup("priority-queues")); err != nil {
+		log.Fatalf("failed to bind priority-queues flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.instance_id", backendCmd.Flags().Lookup("instance-id")); err != nil {
+		log.Fatalf("failed to bind instance-id flag: %v", err)
+	}
 	if err := viper.BindPFlag("backend.grpc.port", backendCmd.Flags().Lookup("grpc-port")); err != nil {
 		log.Fatalf("failed to bind grpc-port flag: %v", err)
 	}
+	if err := viper.BindPFlag("backend.grpc.bind_address", backendCmd.Flags().Lookup("grpc-bind-address")); err != nil {
+		log.Fatalf("failed to bind grpc-bind-address flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.grpc.reflection", backendCmd.Flags().Lookup("grpc-reflection")); err != nil {
+		log.Fatalf("failed to bind grpc-reflection flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.grpc.keepalive.time", backendCmd.Flags().Lookup("grpc-keepalive-time")); err != nil {
+		log.Fatalf("failed to bind grpc-keepalive-time flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.grpc.keepalive.timeout", backendCmd.Flags().Lookup("grpc-keepalive-timeout")); err != nil {
+		log.Fatalf("failed to bind grpc-keepalive-timeout flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.grpc.keepalive.max_connection_idle", backendCmd.Flags().Lookup("grpc-max-connection-idle")); err != nil {
+		log.Fatalf("failed to bind grpc-max-connection-idle flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.grpc.keepalive.max_connection_age", backendCmd.Flags().Lookup("grpc-max-connection-age")); err != nil {
+		log.Fatalf("failed to bind grpc-max-connection-age flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.grpc.keepalive.max_connection_age_grace", backendCmd.Flags().Lookup("grpc-max-connection-age-grace")); err != nil {
+		log.Fatalf("failed to bind grpc-max-connection-age-grace flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.grpc.keepalive.min_ping_interval", backendCmd.Flags().Lookup("grpc-min-ping-interval")); err != nil {
+		log.Fatalf("failed to bind grpc-min-ping-interval flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.grpc.keepalive.permit_without_stream", backendCmd.Flags().Lookup("grpc-permit-ping-without-stream")); err != nil {
+		log.Fatalf("failed to bind grpc-permit-ping-without-stream flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.grpc.max_recv_msg_size", backendCmd.Flags().Lookup("grpc-max-recv-msg-size")); err != nil {
+		log.Fatalf("failed to bind grpc-max-recv-msg-size flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.grpc.max_send_msg_size", backendCmd.Flags().Lookup("grpc-max-send-msg-size")); err != nil {
+		log.Fatalf("failed to bind grpc-max-send-msg-size flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.grpc.compress_min_size", backendCmd.Flags().Lookup("grpc-compress-min-size")); err != nil {
+		log.Fatalf("failed to bind grpc-compress-min-size flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.grpc.request_timeout", backendCmd.Flags().Lookup("grpc-request-timeout")); err != nil {
+		log.Fatalf("failed to bind grpc-request-timeout flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.grpc.export_timeout", backendCmd.Flags().Lookup("grpc-export-timeout")); err != nil {
+		log.Fatalf("failed to bind grpc-export-timeout flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.metrics.port", backendCmd.Flags().Lookup("metrics-port")); err != nil {
+		log.Fatalf("failed to bind metrics-port flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.metrics.bind_address", backendCmd.Flags().Lookup("metrics-bind-address")); err != nil {
+		log.Fatalf("failed to bind metrics-bind-address flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.metrics.pprof", backendCmd.Flags().Lookup("pprof")); err != nil {
+		log.Fatalf("failed to bind pprof flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.quotas.requests_per_minute", backendCmd.Flags().Lookup("quota-requests-per-minute")); err != nil {
+		log.Fatalf("failed to bind quota-requests-per-minute flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.quotas.device_requests_per_minute", backendCmd.Flags().Lookup("quota-device-requests-per-minute")); err != nil {
+		log.Fatalf("failed to bind quota-device-requests-per-minute flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.quotas.export_rows_per_day", backendCmd.Flags().Lookup("quota-export-rows-per-day")); err != nil {
+		log.Fatalf("failed to bind quota-export-rows-per-day flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.battery.window", backendCmd.Flags().Lookup("battery-window")); err != nil {
+		log.Fatalf("failed to bind battery-window flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.battery.interval", backendCmd.Flags().Lookup("battery-interval")); err != nil {
+		log.Fatalf("failed to bind battery-interval flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.retention", backendCmd.Flags().Lookup("retention")); err != nil {
+		log.Fatalf("failed to bind retention flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.rollups.interval", backendCmd.Flags().Lookup("rollup-interval")); err != nil {
+		log.Fatalf("failed to bind rollup-interval flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.dedup.ttl", backendCmd.Flags().Lookup("dedup-ttl")); err != nil {
+		log.Fatalf("failed to bind dedup-ttl flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.timestamps.policy", backendCmd.Flags().Lookup("timestamp-policy")); err != nil {
+		log.Fatalf("failed to bind timestamp-policy flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.timestamps.max_future", backendCmd.Flags().Lookup("max-future")); err != nil {
+		log.Fatalf("failed to bind max-future flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.timestamps.max_age", backendCmd.Flags().Lookup("max-age")); err != nil {
+		log.Fatalf("failed to bind max-age flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.smtp.addr", backendCmd.Flags().Lookup("smtp-addr")); err != nil {
+		log.Fatalf("failed to bind smtp-addr flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.smtp.from", backendCmd.Flags().Lookup("smtp-from")); err != nil {
+		log.Fatalf("failed to bind smtp-from flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.smtp.username", backendCmd.Flags().Lookup("smtp-username")); err != nil {
+		log.Fatalf("failed to bind smtp-username flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.smtp.password", backendCmd.Flags().Lookup("smtp-password")); err != nil {
+		log.Fatalf("failed to bind smtp-password flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.export.bucket", backendCmd.Flags().Lookup("export-bucket")); err != nil {
+		log.Fatalf("failed to bind export-bucket flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.export.endpoint", backendCmd.Flags().Lookup("export-endpoint")); err != nil {
+		log.Fatalf("failed to bind export-endpoint flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.export.region", backendCmd.Flags().Lookup("export-region")); err != nil {
+		log.Fatalf("failed to bind export-region flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.export.path_style", backendCmd.Flags().Lookup("export-path-style")); err != nil {
+		log.Fatalf("failed to bind export-path-style flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.export.access_key_id", backendCmd.Flags().Lookup("export-access-key-id")); err != nil {
+		log.Fatalf("failed to bind export-access-key-id flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.export.secret_access_key", backendCmd.Flags().Lookup("export-secret-access-key")); err != nil {
+		log.Fatalf("failed to bind export-secret-access-key flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.export.prefix", backendCmd.Flags().Lookup("export-prefix")); err != nil {
+		log.Fatalf("failed to bind export-prefix flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.influx.url", backendCmd.Flags().Lookup("influx-url")); err != nil {
+		log.Fatalf("failed to bind influx-url flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.influx.token", backendCmd.Flags().Lookup("influx-token")); err != nil {
+		log.Fatalf("failed to bind influx-token flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.influx.measurement", backendCmd.Flags().Lookup("influx-measurement")); err != nil {
+		log.Fatalf("failed to bind influx-measurement flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.influx.batch_size", backendCmd.Flags().Lookup("influx-batch-size")); err != nil {
+		log.Fatalf("failed to bind influx-batch-size flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.influx.flush_interval", backendCmd.Flags().Lookup("influx-flush-interval")); err != nil {
+		log.Fatalf("failed to bind influx-flush-interval flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.influx.max_pending", backendCmd.Flags().Lookup("influx-max-pending")); err != nil {
+		log.Fatalf("failed to bind influx-max-pending flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.remote_write.url", backendCmd.Flags().Lookup("remote-write-url")); err != nil {
+		log.Fatalf("failed to bind remote-write-url flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.remote_write.token", backendCmd.Flags().Lookup("remote-write-token")); err != nil {
+		log.Fatalf("failed to bind remote-write-token flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.remote_write.max_devices", backendCmd.Flags().Lookup("remote-write-max-devices")); err != nil {
+		log.Fatalf("failed to bind remote-write-max-devices flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.remote_write.batch_size", backendCmd.Flags().Lookup("remote-write-batch-size")); err != nil {
+		log.Fatalf("failed to bind remote-write-batch-size flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.remote_write.flush_interval", backendCmd.Flags().Lookup("remote-write-flush-interval")); err != nil {
+		log.Fatalf("failed to bind remote-write-flush-interval flag: %v", err)
+	}
+	if err := viper.BindPFlag("backend.remote_write.max_pending", backendCmd.Flags().Lookup("remote-write-max-pending")); err != nil {
+		log.Fatalf("failed to bind remote-write-max-pending flag: %v", err)
+	}
 }
 
-func runBackend(_ *cobra.Command, _ []string) error {
-	logger := GetLogger()
-	logger.Info("starting backend service")
+// backendConfig builds the backend configuration from viper.
+func backendConfig(logger *slog.Logger) (*backend.ServerConfig, error) {
+	// Replicas are told apart by their instance ID, the hostname unless set
+	instanceID := viper.GetString("backend.instance_id")
+	if instanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine instance ID: %w", err)
+		}
+		instanceID = hostname
+	}
 
 	// Create backend configuration from viper
 	config := &backend.ServerConfig{
-		Logger:          logger,
-		DBHost:          viper.GetString("backend.db.host"),
-		DBPort:          viper.GetInt("backend.db.port"),
-		DBUser:          viper.GetString("backend.db.user"),
-		DBPassword:      viper.GetString("backend.db.password"),
-		DBName:          viper.GetString("backend.db.name"),
-		DBSSLMode:       viper.GetString("backend.db.sslmode"),
-		RabbitMQURL:     viper.GetString("backend.rabbitmq.url"),
-		QueueName:       viper.GetString("backend.rabbitmq.queue_name"),
-		DeviceQueueName: viper.GetString("backend.rabbitmq.device_queue_name"),
-		GRPCPort:        viper.GetInt("backend.grpc.port"),
+		Logger:            logger,
+		DBDriver:          viper.GetString("backend.db.driver"),
+		DBHost:            viper.GetString("backend.db.host"),
+		DBPort:            viper.GetInt("backend.db.port"),
+		DBUser:            viper.GetString("backend.db.user"),
+		DBPassword:        viper.GetString("backend.db.password"),
+		DBName:            viper.GetString("backend.db.name"),
+		DBSSLMode:         viper.GetString("backend.db.sslmode"),
+		DBConnectAttempts: viper.GetInt("backend.db.connect_attempts"),
+		DBConnectBackoff:  viper.GetDuration("backend.db.connect_backoff"),
+		WaitForDB:         viper.GetBool("backend.db.wait"),
+		SkipSchemaCheck:   viper.GetBool("backend.db.skip_schema_check"),
+		RabbitMQURL:       viper.GetString("backend.rabbitmq.url"),
+		QueueName:         viper.GetString("backend.rabbitmq.queue_name"),
+		DeviceQueueName:   viper.GetString("backend.rabbitmq.device_queue_name"),
+		DurableQueues:     viper.GetBool("backend.rabbitmq.durable"),
+		PriorityQueues:    viper.GetBool("backend.rabbitmq.priority"),
+		InstanceID:        instanceID,
+		GRPCPort:          viper.GetInt("backend.grpc.port"),
+		GRPCBindAddress:   viper.GetString("backend.grpc.bind_address"),
+		EnableReflection:  viper.GetBool("backend.grpc.reflection"),
+
+		DBPrepareStmt:            viper.GetBool("backend.db.prepare_stmt"),
+		DBSkipDefaultTransaction: viper.GetBool("backend.db.skip_default_transaction"),
+		DBStatementTimeout:       viper.GetDuration("backend.db.statement_timeout"),
+		DBSlowQueryThreshold:     viper.GetDuration("backend.db.slow_query_threshold"),
+		Keepalive: backend.KeepaliveConfig{
+			Time:                  viper.GetDuration("backend.grpc.keepalive.time"),
+			Timeout:               viper.GetDuration("backend.grpc.keepalive.timeout"),
+			MaxConnectionIdle:     viper.GetDuration("backend.grpc.keepalive.max_connection_idle"),
+			MaxConnectionAge:      viper.GetDuration("backend.grpc.keepalive.max_connection_age"),
+			MaxConnectionAgeGrace: viper.GetDuration("backend.grpc.keepalive.max_connection_age_grace"),
+			MinPingInterval:       viper.GetDuration("backend.grpc.keepalive.min_ping_interval"),
+			PermitWithoutStream:   viper.GetBool("backend.grpc.keepalive.permit_without_stream"),
+		},
+		MaxRecvMsgSize:     viper.GetInt("backend.grpc.max_recv_msg_size"),
+		MaxSendMsgSize:     viper.GetInt("backend.grpc.max_send_msg_size"),
+		CompressMinSize:    viper.GetInt("backend.grpc.compress_min_size"),
+		RequestTimeout:     viper.GetDuration("backend.grpc.request_timeout"),
+		ExportTimeout:      viper.GetDuration("backend.grpc.export_timeout"),
+		MetricsPort:        viper.GetInt("backend.metrics.port"),
+		MetricsBindAddress: viper.GetString("backend.metrics.bind_address"),
+		EnablePprof:        viper.GetBool("backend.metrics.pprof"),
+		Quotas: backend.QuotaConfig{
+			RequestsPerMinute:       viper.GetInt64("backend.quotas.requests_per_minute"),
+			DeviceRequestsPerMinute: viper.GetInt64("backend.quotas.device_requests_per_minute"),
+			ExportRowsPerDay:        viper.GetInt64("backend.quotas.export_rows_per_day"),
+		},
+		BatteryWindow:   viper.GetDuration("backend.battery.window"),
+		BatteryInterval: viper.GetDuration("backend.battery.interval"),
+		Retention:       viper.GetDuration("backend.retention"),
+		RollupInterval:  viper.GetDuration("backend.rollups.interval"),
+		DedupTTL:        viper.GetDuration("backend.dedup.ttl"),
+		Timestamps: backend.TimestampConfig{
+			Policy:    viper.GetString("backend.timestamps.policy"),
+			MaxFuture: viper.GetDuration("backend.timestamps.max_future"),
+			MaxAge:    viper.GetDuration("backend.timestamps.max_age"),
+		},
+		SMTP: backend.SMTPConfig{
+			Addr:     viper.GetString("backend.smtp.addr"),
+			From:     viper.GetString("backend.smtp.from"),
+			Username: viper.GetString("backend.smtp.username"),
+			Password: viper.GetString("backend.smtp.password"),
+		},
+		Influx: backend.InfluxConfig{
+			URL:           viper.GetString("backend.influx.url"),
+			Token:         viper.GetString("backend.influx.token"),
+			Measurement:   viper.GetString("backend.influx.measurement"),
+			BatchSize:     viper.GetInt("backend.influx.batch_size"),
+			FlushInterval: viper.GetDuration("backend.influx.flush_interval"),
+			MaxPending:    viper.GetInt("backend.influx.max_pending"),
+		},
+		RemoteWrite: backend.RemoteWriteConfig{
+			URL:           viper.GetString("backend.remote_write.url"),
+			Token:         viper.GetString("backend.remote_write.token"),
+			MaxDevices:    viper.GetInt("backend.remote_write.max_devices"),
+			BatchSize:     viper.GetInt("backend.remote_write.batch_size"),
+			FlushInterval: viper.GetDuration("backend.remote_write.flush_interval"),
+			MaxPending:    viper.GetInt("backend.remote_write.max_pending"),
+		},
 	}
 
-	// Create and run server
-	server, err := backend.NewServer(config)
+	// Bucket exports are enabled by naming a bucket
+	if bucket := viper.GetString("backend.export.bucket"); bucket != "" {
+		store, err := objectstore.NewS3(&objectstore.S3Config{
+			Bucket:          bucket,
+			Endpoint:        viper.GetString("backend.export.endpoint"),
+			Region:          viper.GetString("backend.export.region"),
+			PathStyle:       viper.GetBool("backend.export.path_style"),
+			AccessKeyID:     viper.GetString("backend.export.access_key_id"),
+			SecretAccessKey: viper.GetString("backend.export.secret_access_key"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid export bucket configuration: %w", err)
+		}
+		config.Export = backend.ExportConfig{
+			Store:  store,
+			Prefix: viper.GetString("backend.export.prefix"),
+		}
+	}
+
+	injector, err := GetFaults(logger)
 	if err != nil {
-		logger.Error("failed to create backend server", "error", err)
-		return err
+		return nil, err
+	}
+	config.Faults = injector
+	config.MQBroker = GetMQBroker(config.RabbitMQURL, logger)
+
+	// Metrics are only collected when the metrics server is enabled
+	if config.ServesMetrics() {
+		metrics.SetInstanceID(config.InstanceID)
+		config.Metrics = metrics.NewBackendMetrics(metrics.BackendNamespace)
+		config.MQMetrics = metrics.NewMQMetrics(metrics.BackendNamespace)
 	}
 
-	logger.Info("backend server configuration",
+	return config, nil
+}
+
+func runBackend(_ *cobra.Command, _ []string) error {
+	logger := GetLogger()
+	logger.Info("starting backend service")
+
+	config, err := backendConfig(logger)
+	if err != nil {
+		return err
+	}
+	config.HandleSignals = true
+	// Logged with the startup event once the server runs
+	config.StartupConfig = []any{
+		"db_driver", config.DBDriver,
 		"db_host", config.DBHost,
 		"db_port", config.DBPort,
 		"db_name", config.DBName,
-		"rabbitmq_url", config.RabbitMQURL,
+		"db_connect_attempts", config.DBConnectAttempts,
+		"wait_for_db", config.WaitForDB,
+		"skip_schema_check", config.SkipSchemaCheck,
+		"db_prepare_stmt", config.DBPrepareStmt,
+		"db_statement_timeout", config.DBStatementTimeout,
+		"db_slow_query_threshold", config.DBSlowQueryThreshold,
+		"rabbitmq_url", applog.RedactURL(config.RabbitMQURL),
 		"sensor_queue", config.QueueName,
 		"device_queue", config.DeviceQueueName,
+		"durable_queues", config.DurableQueues,
+		"priority_queues", config.PriorityQueues,
+		"instance_id", config.InstanceID,
 		"grpc_port", config.GRPCPort,
-	)
+		"grpc_bind_address", config.GRPCBindAddress,
+		"grpc_reflection", config.EnableReflection,
+		"grpc_keepalive_time", config.Keepalive.Time,
+		"grpc_max_connection_age", config.Keepalive.MaxConnectionAge,
+		"grpc_compress_min_size", config.CompressMinSize,
+		"grpc_request_timeout", config.RequestTimeout,
+		"grpc_export_timeout", config.ExportTimeout,
+		"metrics_port", config.MetricsPort,
+		"metrics_bind_address", config.MetricsBindAddress,
+		"pprof", config.EnablePprof,
+		"quota_requests_per_minute", config.Quotas.RequestsPerMinute,
+		"quota_device_requests_per_minute", config.Quotas.DeviceRequestsPerMinute,
+		"quota_export_rows_per_day", config.Quotas.ExportRowsPerDay,
+		"battery_window", config.BatteryWindow,
+		"battery_interval", config.BatteryInterval,
+		"retention", config.Retention,
+		"rollup_interval", config.RollupInterval,
+		"dedup_ttl", config.DedupTTL,
+		"timestamp_policy", config.Timestamps.Policy,
+		"max_future", config.Timestamps.MaxFuture,
+		"max_age", config.Timestamps.MaxAge,
+		"smtp_addr", config.SMTP.Addr,
+		"export_bucket", viper.GetString("backend.export.bucket"),
+		"influx_measurement", config.Influx.Measurement,
+		"influx_batch_size", config.Influx.BatchSize,
+		"influx_flush_interval", config.Influx.FlushInterval,
+		"remote_write_max_devices", config.RemoteWrite.MaxDevices,
+		"remote_write_batch_size", config.RemoteWrite.BatchSize,
+		"remote_write_flush_interval", config.RemoteWrite.FlushInterval,
+	}
+
+	// Create and run server
+	server, err := backend.NewServer(config)
+	if err != nil {
+		logger.Error("failed to create backend server", "error", err)
+		return err
+	}
 
 	if err := server.Run(context.Background()); err != nil {
 		logger.Error("backend server error", "error", err)
@@ -0,0 +1,202 @@
+// Package main provides the unified CLI entry point for the demo-app services.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"procodus.dev/demo-app/internal/backend"
+	"procodus.dev/demo-app/pkg/objectstore"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup [file]",
+	Short: "Back up devices and readings",
+	Long: `Back up all devices, including deleted ones, and all sensor readings of the
+backend database to a file, or to the export bucket with --to-bucket.
+
+Backups are gzip-compressed JSON lines that end with a SHA-256 checksum of
+their records, which restore verifies. They work with PostgreSQL and SQLite
+and can be restored into either. Use "-" to write to standard output.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runBackup,
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [file]",
+	Short: "Restore devices and readings from a backup",
+	Long: `Restore a backup written by "demo-app backup" from a file, or from the export
+bucket with --from-bucket. Use "-" to read from standard input.
+
+The restore runs in one transaction and is rolled back if the checksum or row
+counts do not match, so a damaged backup leaves the database unchanged. It
+refuses to restore into a database that has devices unless --replace is set,
+which deletes all devices, readings and reading rollups first.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+
+	backupCmd.Flags().Bool("to-bucket", false, "Upload the backup to the export bucket (backend.export.*) instead of a file")
+	restoreCmd.Flags().String("from-bucket", "", "Key of a backup in the export bucket to restore")
+	restoreCmd.Flags().Bool("replace", false, "Delete all devices, readings and reading rollups before restoring")
+}
+
+// backupServer builds a backend server from the configuration, for its
+// database settings and export bucket. It logs to standard error, as backups
+// can be written to standard output.
+func backupServer(cmd *cobra.Command) (*backend.Server, *backend.ServerConfig, error) {
+	config, err := backendConfig(newLogger(cmd.ErrOrStderr()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	server, err := backend.NewServer(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid backend configuration: %w", err)
+	}
+
+	return server, config, nil
+}
+
+func runBackup(cmd *cobra.Command, args []string) error {
+	toBucket, err := cmd.Flags().GetBool("to-bucket")
+	if err != nil {
+		return err
+	}
+	if toBucket == (len(args) == 1) {
+		return errors.New("either a file or --to-bucket is required")
+	}
+
+	server, config, err := backupServer(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if toBucket {
+		if config.Export.Store == nil {
+			return errors.New("no export bucket configured")
+		}
+
+		key := fmt.Sprintf("%sbackups/%s.ndjson.gz", config.Export.Prefix, time.Now().UTC().Format("20060102T150405Z"))
+		w, err := config.Export.Store.NewWriter(ctx, key, objectstore.WriteOptions{ContentType: "application/gzip"})
+		if err != nil {
+			return err
+		}
+
+		summary, err := server.Backup(ctx, w)
+		if err != nil {
+			_ = w.Abort()
+			return fmt.Errorf("backup failed: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("failed to upload backup: %w", err)
+		}
+
+		printBackupSummary(cmd.OutOrStdout(), "backed up", config.Export.Store.URL(key)+" (key "+key+")", summary)
+		return nil
+	}
+
+	if args[0] == "-" {
+		summary, err := server.Backup(ctx, cmd.OutOrStdout())
+		if err != nil {
+			return fmt.Errorf("backup failed: %w", err)
+		}
+		printBackupSummary(cmd.ErrOrStderr(), "backed up", "standard output", summary)
+		return nil
+	}
+
+	// Write next to the target and rename, so a failed backup never
+	// replaces a good one
+	f, err := os.CreateTemp(filepath.Dir(args[0]), ".backup-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	summary, err := server.Backup(ctx, f)
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), args[0]); err != nil {
+		return err
+	}
+
+	printBackupSummary(cmd.OutOrStdout(), "backed up", args[0], summary)
+	return nil
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	key, err := cmd.Flags().GetString("from-bucket")
+	if err != nil {
+		return err
+	}
+	replace, err := cmd.Flags().GetBool("replace")
+	if err != nil {
+		return err
+	}
+	if (key != "") == (len(args) == 1) {
+		return errors.New("either a file or --from-bucket is required")
+	}
+
+	server, config, err := backupServer(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var (
+		r      io.ReadCloser
+		source string
+	)
+	switch {
+	case key != "":
+		if config.Export.Store == nil {
+			return errors.New("no export bucket configured")
+		}
+		if r, err = config.Export.Store.Open(ctx, key); err != nil {
+			return fmt.Errorf("failed to open backup: %w", err)
+		}
+		source = config.Export.Store.URL(key)
+	case args[0] == "-":
+		r, source = io.NopCloser(cmd.InOrStdin()), "standard input"
+	default:
+		if r, err = os.Open(args[0]); err != nil {
+			return err
+		}
+		source = args[0]
+	}
+	defer func() { _ = r.Close() }()
+
+	summary, err := server.Restore(ctx, r, backend.RestoreOptions{Replace: replace})
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+
+	printBackupSummary(cmd.OutOrStdout(), "restored", source, summary)
+	return nil
+}
+
+// printBackupSummary reports a finished backup or restore.
+func printBackupSummary(w io.Writer, action, location string, summary *backend.BackupSummary) {
+	fmt.Fprintf(w, "%s %d devices, %d device events, %d locations, %d notes and %d readings: %s\ncreated %s, sha256 %s\n",
+		action, summary.Devices, summary.Events, summary.Locations, summary.Notes, summary.Readings, location,
+		summary.CreatedAt.Format(time.RFC3339), summary.SHA256)
+}
@@ -4,13 +4,22 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/spf13/viper"
+
+	"procodus.dev/demo-app/pkg/faults"
+	"procodus.dev/demo-app/pkg/mq/inmem"
 )
 
+// sharedBroker is the in-process broker of all services in this process, so
+// the backend and generator started by dev exchange messages through it.
+var sharedBroker = sync.OnceValue(func() *inmem.Broker { return inmem.NewBroker(0) })
+
 // InitConfig initializes Viper configuration.
 // It supports reading from config files (config.yaml) and environment variables.
 func InitConfig(cfgFile string) error {
@@ -45,6 +54,11 @@ func InitConfig(cfgFile string) error {
 
 // GetLogger creates a slog.Logger based on configuration.
 func GetLogger() *slog.Logger {
+	return newLogger(os.Stdout)
+}
+
+// newLogger creates a slog.Logger writing to w at the configured level.
+func newLogger(w io.Writer) *slog.Logger {
 	logLevel := viper.GetString("log.level")
 	if logLevel == "" {
 		logLevel = "info"
@@ -64,7 +78,53 @@ func GetLogger() *slog.Logger {
 		level = slog.LevelInfo
 	}
 
-	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
 		Level: level,
 	}))
 }
+
+// GetFaults returns the fault injector configured by the DEMO_APP_FAULTS
+// environment variable, or nil if it is not set.
+func GetFaults(logger *slog.Logger) (*faults.Injector, error) {
+	injector, err := faults.FromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	if injector != nil {
+		cfg := injector.Config()
+		logger.Warn("fault injection enabled, do not use in production",
+			"mq_drop_rate", cfg.MQDropRate,
+			"db_delay", cfg.DBDelay,
+			"db_delay_rate", cfg.DBDelayRate,
+			"grpc_unavailable_rate", cfg.GRPCUnavailableRate,
+		)
+	}
+
+	return injector, nil
+}
+
+// GetMQBroker returns the in-process broker if url selects it (inmem://),
+// or nil to connect to RabbitMQ at url.
+func GetMQBroker(url string, logger *slog.Logger) *inmem.Broker {
+	if !inmem.IsURL(url) {
+		return nil
+	}
+
+	logger.Warn("using the in-process message broker, messages are lost on exit and not shared with other processes")
+	return sharedBroker()
+}
+
+// GetList returns a list setting. Flags and config files give lists directly;
+// environment variables give one string, which is split on commas.
+func GetList(key string) []string {
+	var list []string
+	for _, value := range viper.GetStringSlice(key) {
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
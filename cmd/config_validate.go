@@ -0,0 +1,56 @@
+// Package main provides the unified CLI entry point for the demo-app services.
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"procodus.dev/demo-app/internal/backend"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the backend configuration",
+	Long: `Validate the backend configuration without starting the backend:
+- Checks the settings like the backend does at startup
+- Pings the database
+- Connects to RabbitMQ, unless the in-process broker (inmem://) is configured
+
+All failures are reported at once. Exits non-zero if any check fails.`,
+	// Failed checks are not usage errors
+	SilenceUsage: true,
+	RunE:         runConfigValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+}
+
+func runConfigValidate(cmd *cobra.Command, _ []string) error {
+	logger := GetLogger()
+
+	config, err := backendConfig(logger)
+	if err != nil {
+		return err
+	}
+
+	server, err := backend.NewServer(config)
+	if err != nil {
+		return fmt.Errorf("invalid backend configuration: %w", err)
+	}
+
+	if err := server.Validate(context.Background()); err != nil {
+		return fmt.Errorf("backend configuration check failed:\n%w", err)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), "backend configuration is valid")
+	return nil
+}
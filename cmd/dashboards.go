@@ -0,0 +1,81 @@
+// Package main provides the unified CLI entry point for the demo-app services.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+var dashboardsCmd = &cobra.Command{
+	Use:   "dashboards",
+	Short: "Manage Grafana dashboards and Prometheus alert rules",
+}
+
+var dashboardsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export Grafana dashboards and Prometheus alert rules",
+	Long: `Export monitoring assets generated from the metric definitions in pkg/metrics:
+- One Grafana dashboard JSON file per service (<service>-dashboard.json)
+- A Prometheus alert rules file (alert-rules.yaml)
+
+Regenerate after changing metrics so dashboards stay in sync with the code.`,
+	RunE: runDashboardsExport,
+}
+
+func init() {
+	rootCmd.AddCommand(dashboardsCmd)
+	dashboardsCmd.AddCommand(dashboardsExportCmd)
+
+	dashboardsExportCmd.Flags().StringP("output-dir", "o", "dashboards", "Directory to write dashboards and alert rules to")
+	if err := dashboardsExportCmd.MarkFlagDirname("output-dir"); err != nil {
+		log.Fatalf("failed to mark output-dir flag: %v", err)
+	}
+}
+
+func runDashboardsExport(cmd *cobra.Command, _ []string) error {
+	outputDir, err := cmd.Flags().GetString("output-dir")
+	if err != nil {
+		return err
+	}
+
+	services, err := metrics.Catalog()
+	if err != nil {
+		return fmt.Errorf("failed to build metrics catalog: %w", err)
+	}
+
+	if err := os.MkdirAll(outputDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, svc := range services {
+		dashboard, err := metrics.GrafanaDashboard(svc)
+		if err != nil {
+			return fmt.Errorf("failed to render %s dashboard: %w", svc.Name, err)
+		}
+
+		path := filepath.Join(outputDir, svc.Name+"-dashboard.json")
+		if err := os.WriteFile(path, dashboard, 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "wrote %s (%d panels)\n", path, len(svc.Definitions))
+	}
+
+	rules, err := metrics.AlertRules(services)
+	if err != nil {
+		return fmt.Errorf("failed to render alert rules: %w", err)
+	}
+
+	path := filepath.Join(outputDir, "alert-rules.yaml")
+	if err := os.WriteFile(path, rules, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "wrote %s\n", path)
+
+	return nil
+}
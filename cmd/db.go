@@ -0,0 +1,183 @@
+// Package main provides the unified CLI entry point for the demo-app services.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"procodus.dev/demo-app/internal/backend"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the backend database",
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the database schema",
+	Long: `Migrate the backend database to the schema version of this release.
+
+The backend checks the schema version at startup and refuses to start on an
+older or unversioned schema, so upgrades migrate the database on purpose
+instead of by whichever replica starts first. Run this before rolling out a
+release with a new schema version. Schemas migrated by newer releases are not
+downgraded.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runDBMigrate,
+}
+
+var dbAnalyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Report missing-index candidates",
+	Long: `Report statements and tables of the backend database that may be missing an
+index, from the statistics PostgreSQL collected since they were last reset.
+
+Tables are listed when they are read by sequential scans. Statements are listed
+when they read many buffer blocks per returned row; this needs the
+pg_stat_statements extension in the backend database. The command only reads
+statistics and does not change the database.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runDBAnalyze,
+}
+
+var dbRebuildDevicesCmd = &cobra.Command{
+	Use:   "rebuild-devices",
+	Short: "Rebuild the devices table from the device event log",
+	Long: `Rebuild the devices of the backend database by replaying the device event
+log.
+
+Device messages and CreateOrUpdateDevice calls are appended to the
+device_events table before they update the devices table, so the devices table
+can be recomputed from the log, for example after it was damaged by a bad
+migration or a manual edit. Devices missing from the table are recreated.
+Devices without events, last updated by a release before the event log, are
+left as they are, as are the trash and the location history. The backend may
+keep running meanwhile.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runDBRebuildDevices,
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbCmd.AddCommand(dbAnalyzeCmd)
+	dbCmd.AddCommand(dbRebuildDevicesCmd)
+
+	dbAnalyzeCmd.Flags().Int("min-calls", 10, "Skip statements run fewer times")
+	dbAnalyzeCmd.Flags().Float64("min-blocks-per-row", 100, "Buffer blocks a statement must read per returned row to be listed")
+	dbAnalyzeCmd.Flags().Int("limit", 20, "Maximum number of statements and tables to list")
+}
+
+func runDBMigrate(cmd *cobra.Command, _ []string) error {
+	config, err := backendConfig(GetLogger())
+	if err != nil {
+		return err
+	}
+
+	server, err := backend.NewServer(config)
+	if err != nil {
+		return fmt.Errorf("invalid backend configuration: %w", err)
+	}
+
+	if err := server.Migrate(context.Background()); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "database schema is at version %d\n", backend.SchemaVersion)
+	return nil
+}
+
+func runDBAnalyze(cmd *cobra.Command, _ []string) error {
+	var opts backend.AnalyzeOptions
+	var err error
+	if opts.MinCalls, err = cmd.Flags().GetInt("min-calls"); err != nil {
+		return err
+	}
+	if opts.MinBlocksPerRow, err = cmd.Flags().GetFloat64("min-blocks-per-row"); err != nil {
+		return err
+	}
+	if opts.Limit, err = cmd.Flags().GetInt("limit"); err != nil {
+		return err
+	}
+
+	config, err := backendConfig(newLogger(cmd.ErrOrStderr()))
+	if err != nil {
+		return err
+	}
+
+	server, err := backend.NewServer(config)
+	if err != nil {
+		return fmt.Errorf("invalid backend configuration: %w", err)
+	}
+
+	report, err := server.AnalyzeIndexes(context.Background(), opts)
+	if err != nil {
+		return fmt.Errorf("analysis failed: %w", err)
+	}
+
+	return printIndexReport(cmd.OutOrStdout(), report)
+}
+
+func runDBRebuildDevices(cmd *cobra.Command, _ []string) error {
+	config, err := backendConfig(newLogger(cmd.ErrOrStderr()))
+	if err != nil {
+		return err
+	}
+
+	server, err := backend.NewServer(config)
+	if err != nil {
+		return fmt.Errorf("invalid backend configuration: %w", err)
+	}
+
+	report, err := server.RebuildDeviceProjection(context.Background())
+	if err != nil {
+		return fmt.Errorf("rebuild failed: %w", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "replayed %d events of %d devices, %d devices changed\n",
+		report.Events, report.Devices, report.Changed)
+	return nil
+}
+
+// printIndexReport writes the tables and statements of report as tables.
+func printIndexReport(w io.Writer, report *backend.IndexReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Tables read by sequential scans:")
+	if len(report.Tables) == 0 {
+		fmt.Fprintln(tw, "  none")
+	} else {
+		fmt.Fprintln(tw, "  TABLE\tROWS\tSEQ SCANS\tROWS READ\tINDEX SCANS")
+		for _, t := range report.Tables {
+			fmt.Fprintf(tw, "  %s\t%d\t%d\t%d\t%d\n", t.Table, t.LiveRows, t.SeqScans, t.SeqRowsRead, t.IndexScans)
+		}
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Statements reading many blocks per row:")
+	switch {
+	case !report.StatementsAvailable:
+		fmt.Fprintln(tw, "  pg_stat_statements is not installed; add it to shared_preload_libraries and run CREATE EXTENSION pg_stat_statements")
+	case len(report.Candidates) == 0:
+		fmt.Fprintln(tw, "  none")
+	default:
+		fmt.Fprintln(tw, "  CALLS\tMEAN\tTOTAL\tBLOCKS/ROW\tQUERY")
+		for _, c := range report.Candidates {
+			fmt.Fprintf(tw, "  %d\t%s\t%s\t%.0f\t%s\n", c.Calls,
+				c.MeanTime.Round(time.Microsecond), c.TotalTime.Round(time.Millisecond), c.BlocksPerRow,
+				strings.Join(strings.Fields(c.Query), " "))
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,128 @@
+// Package main provides the unified CLI entry point for the demo-app services.
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"procodus.dev/demo-app/internal/backend"
+	"procodus.dev/demo-app/internal/frontend"
+	"procodus.dev/demo-app/internal/producer"
+	"procodus.dev/demo-app/pkg/listener"
+	"procodus.dev/demo-app/pkg/runner"
+)
+
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Run backend, frontend and generator in one process",
+	Long: `Run all services in one process for local development:
+- Starts the backend and waits for its gRPC port
+- Starts the frontend and waits for its HTTP port
+- Starts the generator
+- Stops them in reverse order on SIGINT/SIGTERM or when one fails
+
+Each service reads its usual backend, frontend and generator settings from
+the config file and environment variables. PostgreSQL (or SQLite) and
+RabbitMQ must be running, unless the backend and generator RabbitMQ URLs are
+set to inmem:// to exchange messages through an in-process broker:
+
+  DEMO_APP_BACKEND_RABBITMQ_URL=inmem:// DEMO_APP_GENERATOR_RABBITMQ_URL=inmem:// demo-app dev`,
+	RunE: runDev,
+}
+
+func init() {
+	rootCmd.AddCommand(devCmd)
+
+	// Dev-specific flags
+	devCmd.Flags().Duration("start-timeout", 30*time.Second, "Time to wait for each service to become ready")
+	devCmd.Flags().Duration("stop-timeout", 30*time.Second, "Time to wait for each service to stop")
+
+	// Bind flags to viper
+	if err := viper.BindPFlag("dev.start_timeout", devCmd.Flags().Lookup("start-timeout")); err != nil {
+		log.Fatalf("failed to bind start-timeout flag: %v", err)
+	}
+	if err := viper.BindPFlag("dev.stop_timeout", devCmd.Flags().Lookup("stop-timeout")); err != nil {
+		log.Fatalf("failed to bind stop-timeout flag: %v", err)
+	}
+}
+
+func runDev(_ *cobra.Command, _ []string) error {
+	logger := GetLogger()
+	logger.Info("starting all services")
+
+	backendCfg, err := backendConfig(logger.With("service", "backend"))
+	if err != nil {
+		return err
+	}
+
+	backendServer, err := backend.NewServer(backendCfg)
+	if err != nil {
+		logger.Error("failed to create backend server", "error", err)
+		return err
+	}
+
+	frontendCfg, err := frontendConfig(logger.With("service", "frontend"))
+	if err != nil {
+		return err
+	}
+	if frontendCfg.Sessions != nil {
+		defer func() { _ = frontendCfg.Sessions.Close() }()
+	}
+
+	frontendServer, err := frontend.NewServer(frontendCfg)
+	if err != nil {
+		logger.Error("failed to create frontend server", "error", err)
+		return err
+	}
+
+	generatorCfg, err := generatorConfig(logger.With("service", "generator"))
+	if err != nil {
+		return err
+	}
+
+	generatorServer, err := producer.NewServer(generatorCfg)
+	if err != nil {
+		logger.Error("failed to create generator server", "error", err)
+		return err
+	}
+
+	r, err := runner.New(&runner.Config{
+		Logger: logger,
+		Services: []runner.Service{
+			{
+				Name:  "backend",
+				Run:   backendServer.Run,
+				Ready: runner.WaitListening(listener.Address(backendCfg.GRPCBindAddress, backendCfg.GRPCPort)),
+			},
+			{
+				Name:  "frontend",
+				Run:   frontendServer.Run,
+				Ready: runner.WaitListening(listener.Address(frontendCfg.HTTPBindAddress, frontendCfg.HTTPPort)),
+			},
+			{
+				Name: "generator",
+				Run:  generatorServer.Run,
+			},
+		},
+		// The runner handles signals for all services, which leave them unset
+		HandleSignals: true,
+		StartTimeout:  viper.GetDuration("dev.start_timeout"),
+		StopTimeout:   viper.GetDuration("dev.stop_timeout"),
+	})
+	if err != nil {
+		_ = generatorServer.Shutdown()
+		return err
+	}
+
+	if err := r.Run(context.Background()); err != nil {
+		logger.Error("dev services error", "error", err)
+		return err
+	}
+
+	logger.Info("all services stopped")
+	return nil
+}
@@ -0,0 +1,61 @@
+// Package main provides the unified CLI entry point for the demo-app services.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"procodus.dev/demo-app/internal/frontend"
+)
+
+var embedURLCmd = &cobra.Command{
+	Use:   "embed-url <device-id>",
+	Short: "Print the URL of an embeddable device panel",
+	Long: `Print the URL of the read-only panel of a device, for framing into wikis and
+other sites with an iframe. The URL carries a token signed with the frontend's
+embed signing key (frontend.embed.signing_key in the config file, or
+DEMO_APP_FRONTEND_EMBED_SIGNING_KEY).
+
+Tokens do not expire unless --ttl is set. Change the signing key to revoke
+every issued URL.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEmbedURL,
+}
+
+func init() {
+	rootCmd.AddCommand(embedURLCmd)
+
+	embedURLCmd.Flags().String("base-url", "http://localhost:8080", "URL the frontend is reachable at")
+	embedURLCmd.Flags().Duration("ttl", 0, "How long the URL is valid (0 = no expiry)")
+}
+
+func runEmbedURL(cmd *cobra.Command, args []string) error {
+	baseURL, err := cmd.Flags().GetString("base-url")
+	if err != nil {
+		return err
+	}
+	ttl, err := cmd.Flags().GetDuration("ttl")
+	if err != nil {
+		return err
+	}
+	if ttl < 0 {
+		return errors.New("ttl cannot be negative")
+	}
+
+	key := viper.GetString("frontend.embed.signing_key")
+	if key == "" {
+		return errors.New("no embed signing key configured")
+	}
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), frontend.EmbedURL(baseURL, key, args[0], expires))
+	return nil
+}
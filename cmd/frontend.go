@@ -3,12 +3,17 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"procodus.dev/demo-app/internal/frontend"
+	"procodus.dev/demo-app/pkg/metrics"
+	"procodus.dev/demo-app/pkg/session"
 )
 
 var frontendCmd = &cobra.Command{
@@ -27,26 +32,244 @@ func init() {
 
 	// Frontend-specific flags
 	frontendCmd.Flags().Int("http-port", 8080, "HTTP server port")
-	frontendCmd.Flags().String("backend-addr", "localhost:9090", "Backend gRPC server address")
+	frontendCmd.Flags().String("http-bind-address", "", "Host or IP the HTTP server listens on, e.g. [::1], or unix:///path for a Unix socket (empty = all interfaces)")
+	frontendCmd.Flags().String("backend-addr", "localhost:9090", "Backend gRPC server address, or a comma-separated list of replicas")
+	frontendCmd.Flags().String("backend-load-balancing", frontend.LoadBalancingRoundRobin, "Policy spreading calls over backend replicas: round_robin or pick_first")
+	frontendCmd.Flags().Int("backend-max-recv-msg-size", 0, "Largest backend response in bytes (0 = gRPC default, 4 MiB)")
+	frontendCmd.Flags().Duration("backend-startup-timeout", 10*time.Second, "How long startup waits for the backend connection")
+	frontendCmd.Flags().Bool("backend-fail-fast", false, "Exit when the backend is unreachable at startup instead of retrying in the background")
+	frontendCmd.Flags().Duration("http-read-header-timeout", 10*time.Second, "How long clients may take to send request headers")
+	frontendCmd.Flags().Duration("http-read-timeout", 30*time.Second, "How long clients may take to send a whole request")
+	frontendCmd.Flags().Duration("http-write-timeout", 30*time.Second, "How long a request may take to answer; backend calls stop a second earlier")
+	frontendCmd.Flags().Duration("http-idle-timeout", 2*time.Minute, "How long keep-alive connections wait for the next request")
+	frontendCmd.Flags().Int("http-max-header-bytes", 1<<20, "Largest request headers in bytes")
+	frontendCmd.Flags().Int64("http-max-body-bytes", 1<<20, "Largest request body in bytes; larger requests get 413")
+	frontendCmd.Flags().Duration("backend-call-timeout", 5*time.Second, "Longest time a single backend call may take")
+	frontendCmd.Flags().Int("pprof-port", 0, "pprof debug HTTP port (0 = disabled)")
+	frontendCmd.Flags().String("pprof-bind-address", "", "Host or IP the pprof debug server listens on (empty = all interfaces)")
+	frontendCmd.Flags().Bool("enable-metrics", true, "Enable Prometheus metrics at /metrics")
+	frontendCmd.Flags().String("tenant-id", "", "Tenant ID sent to the backend for quota accounting (empty = backend default)")
+	frontendCmd.Flags().StringSlice("cors-allowed-origins", nil, "Origins allowed to call /api/ routes, e.g. https://dashboard.example.com, or * for any (empty = CORS disabled)")
+	frontendCmd.Flags().StringSlice("cors-allowed-methods", []string{"GET", "HEAD"}, "Methods allowed in cross-origin API requests")
+	frontendCmd.Flags().StringSlice("cors-allowed-headers", []string{"Content-Type"}, "Request headers allowed in cross-origin API requests")
+	frontendCmd.Flags().Bool("cors-allow-credentials", false, "Let cross-origin API requests send cookies (not with the * origin)")
+	frontendCmd.Flags().Duration("cors-max-age", 10*time.Minute, "How long browsers cache CORS preflight results")
+	frontendCmd.Flags().String("embed-signing-key", "", "Key signing the tokens of embeddable device panels, at least 32 bytes (empty = embedding disabled)")
+	frontendCmd.Flags().StringSlice("embed-frame-ancestors", nil, "Origins allowed to frame device panels, e.g. https://wiki.example.com (empty = any origin)")
+	frontendCmd.Flags().Bool("status-page", false, "Serve a public fleet and backend health summary at /status")
+	frontendCmd.Flags().String("session-store", "", "Server-side session store: memory or redis (empty = cookies only)")
+	frontendCmd.Flags().String("session-redis-url", "redis://localhost:6379/0", "Redis URL of the redis session store")
+	frontendCmd.Flags().Duration("session-ttl", session.DefaultTTL, "How long idle sessions are kept")
 
 	// Bind flags to viper
 	if err := viper.BindPFlag("frontend.http.port", frontendCmd.Flags().Lookup("http-port")); err != nil {
 		log.Fatalf("failed to bind http-port flag: %v", err)
 	}
+	if err := viper.BindPFlag("frontend.http.bind_address", frontendCmd.Flags().Lookup("http-bind-address")); err != nil {
+		log.Fatalf("failed to bind http-bind-address flag: %v", err)
+	}
 	if err := viper.BindPFlag("frontend.backend.addr", frontendCmd.Flags().Lookup("backend-addr")); err != nil {
 		log.Fatalf("failed to bind backend-addr flag: %v", err)
 	}
+	if err := viper.BindPFlag("frontend.backend.load_balancing", frontendCmd.Flags().Lookup("backend-load-balancing")); err != nil {
+		log.Fatalf("failed to bind backend-load-balancing flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.backend.max_recv_msg_size", frontendCmd.Flags().Lookup("backend-max-recv-msg-size")); err != nil {
+		log.Fatalf("failed to bind backend-max-recv-msg-size flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.backend.startup_timeout", frontendCmd.Flags().Lookup("backend-startup-timeout")); err != nil {
+		log.Fatalf("failed to bind backend-startup-timeout flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.backend.fail_fast", frontendCmd.Flags().Lookup("backend-fail-fast")); err != nil {
+		log.Fatalf("failed to bind backend-fail-fast flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.http.read_header_timeout", frontendCmd.Flags().Lookup("http-read-header-timeout")); err != nil {
+		log.Fatalf("failed to bind http-read-header-timeout flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.http.read_timeout", frontendCmd.Flags().Lookup("http-read-timeout")); err != nil {
+		log.Fatalf("failed to bind http-read-timeout flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.http.write_timeout", frontendCmd.Flags().Lookup("http-write-timeout")); err != nil {
+		log.Fatalf("failed to bind http-write-timeout flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.http.idle_timeout", frontendCmd.Flags().Lookup("http-idle-timeout")); err != nil {
+		log.Fatalf("failed to bind http-idle-timeout flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.http.max_header_bytes", frontendCmd.Flags().Lookup("http-max-header-bytes")); err != nil {
+		log.Fatalf("failed to bind http-max-header-bytes flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.http.max_body_bytes", frontendCmd.Flags().Lookup("http-max-body-bytes")); err != nil {
+		log.Fatalf("failed to bind http-max-body-bytes flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.backend.call_timeout", frontendCmd.Flags().Lookup("backend-call-timeout")); err != nil {
+		log.Fatalf("failed to bind backend-call-timeout flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.pprof.port", frontendCmd.Flags().Lookup("pprof-port")); err != nil {
+		log.Fatalf("failed to bind pprof-port flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.pprof.bind_address", frontendCmd.Flags().Lookup("pprof-bind-address")); err != nil {
+		log.Fatalf("failed to bind pprof-bind-address flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.enable_metrics", frontendCmd.Flags().Lookup("enable-metrics")); err != nil {
+		log.Fatalf("failed to bind enable-metrics flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.tenant_id", frontendCmd.Flags().Lookup("tenant-id")); err != nil {
+		log.Fatalf("failed to bind tenant-id flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.cors.allowed_origins", frontendCmd.Flags().Lookup("cors-allowed-origins")); err != nil {
+		log.Fatalf("failed to bind cors-allowed-origins flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.cors.allowed_methods", frontendCmd.Flags().Lookup("cors-allowed-methods")); err != nil {
+		log.Fatalf("failed to bind cors-allowed-methods flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.cors.allowed_headers", frontendCmd.Flags().Lookup("cors-allowed-headers")); err != nil {
+		log.Fatalf("failed to bind cors-allowed-headers flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.cors.allow_credentials", frontendCmd.Flags().Lookup("cors-allow-credentials")); err != nil {
+		log.Fatalf("failed to bind cors-allow-credentials flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.cors.max_age", frontendCmd.Flags().Lookup("cors-max-age")); err != nil {
+		log.Fatalf("failed to bind cors-max-age flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.embed.signing_key", frontendCmd.Flags().Lookup("embed-signing-key")); err != nil {
+		log.Fatalf("failed to bind embed-signing-key flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.embed.frame_ancestors", frontendCmd.Flags().Lookup("embed-frame-ancestors")); err != nil {
+		log.Fatalf("failed to bind embed-frame-ancestors flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.status_page", frontendCmd.Flags().Lookup("status-page")); err != nil {
+		log.Fatalf("failed to bind status-page flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.session.store", frontendCmd.Flags().Lookup("session-store")); err != nil {
+		log.Fatalf("failed to bind session-store flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.session.redis_url", frontendCmd.Flags().Lookup("session-redis-url")); err != nil {
+		log.Fatalf("failed to bind session-redis-url flag: %v", err)
+	}
+	if err := viper.BindPFlag("frontend.session.ttl", frontendCmd.Flags().Lookup("session-ttl")); err != nil {
+		log.Fatalf("failed to bind session-ttl flag: %v", err)
+	}
+}
+
+// frontendConfig builds the frontend configuration from viper. The caller
+// must close config.Sessions when it is set.
+func frontendConfig(logger *slog.Logger) (*frontend.ServerConfig, error) {
+	// Create frontend configuration from viper
+	config := &frontend.ServerConfig{
+		Logger:                logger,
+		HTTPPort:              viper.GetInt("frontend.http.port"),
+		HTTPBindAddress:       viper.GetString("frontend.http.bind_address"),
+		BackendGRPCAddr:       viper.GetString("frontend.backend.addr"),
+		BackendLoadBalancing:  viper.GetString("frontend.backend.load_balancing"),
+		BackendMaxRecvMsgSize: viper.GetInt("frontend.backend.max_recv_msg_size"),
+		BackendStartupTimeout: viper.GetDuration("frontend.backend.startup_timeout"),
+		BackendFailFast:       viper.GetBool("frontend.backend.fail_fast"),
+		PprofPort:             viper.GetInt("frontend.pprof.port"),
+		PprofBindAddress:      viper.GetString("frontend.pprof.bind_address"),
+		TenantID:              viper.GetString("frontend.tenant_id"),
+		CORS: frontend.CORSConfig{
+			AllowedOrigins:   GetList("frontend.cors.allowed_origins"),
+			AllowedMethods:   GetList("frontend.cors.allowed_methods"),
+			AllowedHeaders:   GetList("frontend.cors.allowed_headers"),
+			AllowCredentials: viper.GetBool("frontend.cors.allow_credentials"),
+			MaxAge:           viper.GetDuration("frontend.cors.max_age"),
+		},
+		Embed: frontend.EmbedConfig{
+			SigningKey:     viper.GetString("frontend.embed.signing_key"),
+			FrameAncestors: GetList("frontend.embed.frame_ancestors"),
+		},
+		Timeouts: frontend.TimeoutConfig{
+			ReadHeader:  viper.GetDuration("frontend.http.read_header_timeout"),
+			Read:        viper.GetDuration("frontend.http.read_timeout"),
+			Write:       viper.GetDuration("frontend.http.write_timeout"),
+			Idle:        viper.GetDuration("frontend.http.idle_timeout"),
+			BackendCall: viper.GetDuration("frontend.backend.call_timeout"),
+		},
+		MaxHeaderBytes: viper.GetInt("frontend.http.max_header_bytes"),
+		MaxBodyBytes:   viper.GetInt64("frontend.http.max_body_bytes"),
+		StatusPage:     viper.GetBool("frontend.status_page"),
+	}
+
+	sessions, err := frontendSessions(logger)
+	if err != nil {
+		return nil, err
+	}
+	config.Sessions = sessions
+
+	if viper.GetBool("frontend.enable_metrics") {
+		config.Metrics = metrics.NewFrontendMetrics(metrics.FrontendNamespace)
+	}
+
+	return config, nil
+}
+
+// frontendSessions creates the session manager of the configured store, or
+// returns nil when sessions are disabled.
+func frontendSessions(logger *slog.Logger) (*session.Manager, error) {
+	var store session.Store
+	switch kind := viper.GetString("frontend.session.store"); kind {
+	case "":
+		return nil, nil
+	case "memory":
+		store = session.NewMemoryStore()
+	case "redis":
+		redisStore, err := session.NewRedisStore(&session.RedisConfig{URL: viper.GetString("frontend.session.redis_url")})
+		if err != nil {
+			return nil, err
+		}
+
+		// Sessions survive a Redis outage, so only warn when it is down at startup
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := redisStore.Ping(ctx); err != nil {
+			logger.Warn("session store not reachable", "error", err)
+		}
+
+		store = redisStore
+	default:
+		return nil, fmt.Errorf("unknown session store %q (want memory or redis)", kind)
+	}
+
+	return session.NewManager(&session.ManagerConfig{
+		Store:  store,
+		Logger: logger,
+		TTL:    viper.GetDuration("frontend.session.ttl"),
+	})
 }
 
 func runFrontend(_ *cobra.Command, _ []string) error {
 	logger := GetLogger()
 	logger.Info("starting frontend service")
 
-	// Create frontend configuration from viper
-	config := &frontend.ServerConfig{
-		Logger:          logger,
-		HTTPPort:        viper.GetInt("frontend.http.port"),
-		BackendGRPCAddr: viper.GetString("frontend.backend.addr"),
+	config, err := frontendConfig(logger)
+	if err != nil {
+		logger.Error("failed to configure frontend", "error", err)
+		return err
+	}
+	if config.Sessions != nil {
+		defer func() { _ = config.Sessions.Close() }()
+	}
+	config.HandleSignals = true
+	// Logged with the startup event once the server runs
+	config.StartupConfig = []any{
+		"http_port", config.HTTPPort,
+		"http_bind_address", config.HTTPBindAddress,
+		"backend_addr", config.BackendGRPCAddr,
+		"backend_max_recv_msg_size", config.BackendMaxRecvMsgSize,
+		"backend_startup_timeout", config.BackendStartupTimeout,
+		"backend_fail_fast", config.BackendFailFast,
+		"backend_call_timeout", config.Timeouts.BackendCall,
+		"http_read_timeout", config.Timeouts.Read,
+		"http_write_timeout", config.Timeouts.Write,
+		"http_max_body_bytes", config.MaxBodyBytes,
+		"pprof_port", config.PprofPort,
+		"pprof_bind_address", config.PprofBindAddress,
+		"metrics_enabled", config.Metrics != nil,
+		"tenant_id", config.TenantID,
+		"cors_allowed_origins", config.CORS.AllowedOrigins,
+		"embed_enabled", config.Embed.SigningKey != "",
+		"status_page", config.StatusPage,
+		"session_store", viper.GetString("frontend.session.store"),
 	}
 
 	// Create and run server
@@ -56,11 +279,6 @@ func runFrontend(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
-	logger.Info("frontend server configuration",
-		"http_port", config.HTTPPort,
-		"backend_addr", config.BackendGRPCAddr,
-	)
-
 	if err := server.Run(context.Background()); err != nil {
 		logger.Error("frontend server error", "error", err)
 		return err
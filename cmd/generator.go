@@ -4,12 +4,15 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"procodus.dev/demo-app/internal/producer"
+	applog "procodus.dev/demo-app/pkg/logger"
+	"procodus.dev/demo-app/pkg/metrics"
 )
 
 var generatorCmd = &cobra.Command{
@@ -27,11 +30,30 @@ func init() {
 	rootCmd.AddCommand(generatorCmd)
 
 	// Generator-specific flags
-	generatorCmd.Flags().String("rabbitmq-url", "amqp://localhost:5672", "RabbitMQ URL")
+	generatorCmd.Flags().String("rabbitmq-url", "amqp://localhost:5672", "RabbitMQ URL (inmem:// for an in-process broker)")
 	generatorCmd.Flags().String("queue-name", "sensor-data", "RabbitMQ queue name for sensor readings")
 	generatorCmd.Flags().String("device-queue-name", "device-data", "RabbitMQ queue name for device creation messages")
+	generatorCmd.Flags().String("backend-addr", "", "Backend gRPC address to register devices through instead of the device queue (empty = device queue)")
+	generatorCmd.Flags().Bool("durable-queues", false, "Declare durable queues and publish persistent messages (must match the backend)")
+	generatorCmd.Flags().Bool("priority-queues", false, "Declare queues with message priorities (must match the backend)")
+	generatorCmd.Flags().String("compression", "", "Compress message bodies of 512 bytes or more: gzip or zstd (empty = none)")
 	generatorCmd.Flags().Int("producer-count", 5, "Number of concurrent producers")
 	generatorCmd.Flags().Duration("interval", 5*time.Second, "Interval between data generation")
+	generatorCmd.Flags().Int("devices-per-producer", 0, "Number of devices each producer simulates (0 = random from 1 to 5)")
+	generatorCmd.Flags().String("fleet-file", "", "JSON or YAML file to load the device fleet from, or to save the generated fleet to if it does not exist")
+	generatorCmd.Flags().Bool("device-tickers", false, "Let every device generate readings each interval on its own instead of one random device per producer")
+	generatorCmd.Flags().Float64("tick-jitter", 0, "Fraction (0-1) by which device intervals vary in either direction; requires --device-tickers")
+	generatorCmd.Flags().Duration("batch-interval", 0, "Publish the readings generated in this interval as one batch message (0 = one message per reading)")
+	generatorCmd.Flags().Duration("clock-skew", 0, "Largest random offset of a simulated device clock from real time (0 = exact clocks)")
+	generatorCmd.Flags().Float64("out-of-order-rate", 0, "Fraction of readings published after the following one (0-1)")
+	generatorCmd.Flags().Float64("duplicate-rate", 0, "Fraction of readings published twice (0-1)")
+	generatorCmd.Flags().Float64("device-skew", 0, "Zipf exponent (> 1) weighting readings towards a few hot devices (0 = uniform)")
+	generatorCmd.Flags().Bool("imperial-units", false, "Publish temperatures in Fahrenheit and pressures in inches of mercury")
+	generatorCmd.Flags().Duration("unhealthy-after", time.Minute, "Time without a successful push after which a producer is restarted")
+	generatorCmd.Flags().Duration("push-timeout", 10*time.Second, "Timeout for publishing a single data point")
+	generatorCmd.Flags().Duration("drain-timeout", 5*time.Second, "Time to wait for in-flight publishes on shutdown before canceling them (0 = cancel immediately)")
+	generatorCmd.Flags().Int("metrics-port", 0, "Prometheus metrics HTTP port (0 = disabled)")
+	generatorCmd.Flags().Int("pprof-port", 0, "pprof debug HTTP port (0 = disabled)")
 
 	// Bind flags to viper
 	if err := viper.BindPFlag("generator.rabbitmq.url", generatorCmd.Flags().Lookup("rabbitmq-url")); err != nil {
@@ -43,26 +65,155 @@ func init() {
 	if err := viper.BindPFlag("generator.rabbitmq.device_queue_name", generatorCmd.Flags().Lookup("device-queue-name")); err != nil {
 		log.Fatalf("failed to bind device-queue-name flag: %v", err)
 	}
+	if err := viper.BindPFlag("generator.backend.addr", generatorCmd.Flags().Lookup("backend-addr")); err != nil {
+		log.Fatalf("failed to bind backend-addr flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.rabbitmq.durable", generatorCmd.Flags().Lookup("durable-queues")); err != nil {
+		log.Fatalf("failed to bind durable-queues flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.rabbitmq.priority", generatorCmd.Flags().Lookup("priority-queues")); err != nil {
+		log.Fatalf("failed to bind priority-queues flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.rabbitmq.compression", generatorCmd.Flags().Lookup("compression")); err != nil {
+		log.Fatalf("failed to bind compression flag: %v", err)
+	}
 	if err := viper.BindPFlag("generator.producer_count", generatorCmd.Flags().Lookup("producer-count")); err != nil {
 		log.Fatalf("failed to bind producer-count flag: %v", err)
 	}
 	if err := viper.BindPFlag("generator.interval", generatorCmd.Flags().Lookup("interval")); err != nil {
 		log.Fatalf("failed to bind interval flag: %v", err)
 	}
+	if err := viper.BindPFlag("generator.devices_per_producer", generatorCmd.Flags().Lookup("devices-per-producer")); err != nil {
+		log.Fatalf("failed to bind devices-per-producer flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.fleet_file", generatorCmd.Flags().Lookup("fleet-file")); err != nil {
+		log.Fatalf("failed to bind fleet-file flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.device_tickers", generatorCmd.Flags().Lookup("device-tickers")); err != nil {
+		log.Fatalf("failed to bind device-tickers flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.tick_jitter", generatorCmd.Flags().Lookup("tick-jitter")); err != nil {
+		log.Fatalf("failed to bind tick-jitter flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.batch_interval", generatorCmd.Flags().Lookup("batch-interval")); err != nil {
+		log.Fatalf("failed to bind batch-interval flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.disorder.clock_skew", generatorCmd.Flags().Lookup("clock-skew")); err != nil {
+		log.Fatalf("failed to bind clock-skew flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.disorder.out_of_order_rate", generatorCmd.Flags().Lookup("out-of-order-rate")); err != nil {
+		log.Fatalf("failed to bind out-of-order-rate flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.disorder.duplicate_rate", generatorCmd.Flags().Lookup("duplicate-rate")); err != nil {
+		log.Fatalf("failed to bind duplicate-rate flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.device_skew", generatorCmd.Flags().Lookup("device-skew")); err != nil {
+		log.Fatalf("failed to bind device-skew flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.imperial_units", generatorCmd.Flags().Lookup("imperial-units")); err != nil {
+		log.Fatalf("failed to bind imperial-units flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.supervision.unhealthy_after", generatorCmd.Flags().Lookup("unhealthy-after")); err != nil {
+		log.Fatalf("failed to bind unhealthy-after flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.supervision.push_timeout", generatorCmd.Flags().Lookup("push-timeout")); err != nil {
+		log.Fatalf("failed to bind push-timeout flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.drain_timeout", generatorCmd.Flags().Lookup("drain-timeout")); err != nil {
+		log.Fatalf("failed to bind drain-timeout flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.metrics.port", generatorCmd.Flags().Lookup("metrics-port")); err != nil {
+		log.Fatalf("failed to bind metrics-port flag: %v", err)
+	}
+	if err := viper.BindPFlag("generator.pprof.port", generatorCmd.Flags().Lookup("pprof-port")); err != nil {
+		log.Fatalf("failed to bind pprof-port flag: %v", err)
+	}
+}
+
+// generatorConfig builds the generator configuration from viper.
+func generatorConfig(logger *slog.Logger) (*producer.ServerConfig, error) {
+	// Create producer configuration from viper
+	config := &producer.ServerConfig{
+		Logger:             logger,
+		RabbitMQURL:        viper.GetString("generator.rabbitmq.url"),
+		QueueName:          viper.GetString("generator.rabbitmq.queue_name"),
+		DeviceQueueName:    viper.GetString("generator.rabbitmq.device_queue_name"),
+		BackendGRPCAddr:    viper.GetString("generator.backend.addr"),
+		DurableQueues:      viper.GetBool("generator.rabbitmq.durable"),
+		PriorityQueues:     viper.GetBool("generator.rabbitmq.priority"),
+		Compression:        viper.GetString("generator.rabbitmq.compression"),
+		ProducerCount:      viper.GetInt("generator.producer_count"),
+		Interval:           viper.GetDuration("generator.interval"),
+		DevicesPerProducer: viper.GetInt("generator.devices_per_producer"),
+		FleetFile:          viper.GetString("generator.fleet_file"),
+		DeviceTickers:      viper.GetBool("generator.device_tickers"),
+		TickJitter:         viper.GetFloat64("generator.tick_jitter"),
+		BatchInterval:      viper.GetDuration("generator.batch_interval"),
+		Disorder: producer.DisorderConfig{
+			ClockSkew:      viper.GetDuration("generator.disorder.clock_skew"),
+			OutOfOrderRate: viper.GetFloat64("generator.disorder.out_of_order_rate"),
+			DuplicateRate:  viper.GetFloat64("generator.disorder.duplicate_rate"),
+		},
+		DeviceSkew:     viper.GetFloat64("generator.device_skew"),
+		ImperialUnits:  viper.GetBool("generator.imperial_units"),
+		UnhealthyAfter: viper.GetDuration("generator.supervision.unhealthy_after"),
+		PushTimeout:    viper.GetDuration("generator.supervision.push_timeout"),
+		DrainTimeout:   viper.GetDuration("generator.drain_timeout"),
+		MetricsPort:    viper.GetInt("generator.metrics.port"),
+		PprofPort:      viper.GetInt("generator.pprof.port"),
+	}
+
+	// Metrics are only collected when the metrics server is enabled
+	if config.MetricsPort > 0 {
+		config.Metrics = metrics.NewProducerMetrics(metrics.GeneratorNamespace)
+		config.MQMetrics = metrics.NewMQMetrics(metrics.GeneratorNamespace)
+	}
+
+	injector, err := GetFaults(logger)
+	if err != nil {
+		return nil, err
+	}
+	config.Faults = injector
+	config.MQBroker = GetMQBroker(config.RabbitMQURL, logger)
+
+	return config, nil
 }
 
 func runGenerator(_ *cobra.Command, _ []string) error {
 	logger := GetLogger()
 	logger.Info("starting generator service")
 
-	// Create producer configuration from viper
-	config := &producer.ServerConfig{
-		Logger:          logger,
-		RabbitMQURL:     viper.GetString("generator.rabbitmq.url"),
-		QueueName:       viper.GetString("generator.rabbitmq.queue_name"),
-		DeviceQueueName: viper.GetString("generator.rabbitmq.device_queue_name"),
-		ProducerCount:   viper.GetInt("generator.producer_count"),
-		Interval:        viper.GetDuration("generator.interval"),
+	config, err := generatorConfig(logger)
+	if err != nil {
+		return err
+	}
+	config.HandleSignals = true
+	// Logged with the startup event once the server runs
+	config.StartupConfig = []any{
+		"rabbitmq_url", applog.RedactURL(config.RabbitMQURL),
+		"sensor_queue", config.QueueName,
+		"device_queue", config.DeviceQueueName,
+		"backend_addr", config.BackendGRPCAddr,
+		"durable_queues", config.DurableQueues,
+		"priority_queues", config.PriorityQueues,
+		"compression", config.Compression,
+		"producer_count", config.ProducerCount,
+		"interval", config.Interval,
+		"devices_per_producer", config.DevicesPerProducer,
+		"fleet_file", config.FleetFile,
+		"device_tickers", config.DeviceTickers,
+		"tick_jitter", config.TickJitter,
+		"batch_interval", config.BatchInterval,
+		"clock_skew", config.Disorder.ClockSkew,
+		"out_of_order_rate", config.Disorder.OutOfOrderRate,
+		"duplicate_rate", config.Disorder.DuplicateRate,
+		"device_skew", config.DeviceSkew,
+		"imperial_units", config.ImperialUnits,
+		"unhealthy_after", config.UnhealthyAfter,
+		"push_timeout", config.PushTimeout,
+		"drain_timeout", config.DrainTimeout,
+		"metrics_port", config.MetricsPort,
+		"pprof_port", config.PprofPort,
 	}
 
 	// Create and run server
@@ -72,14 +223,6 @@ func runGenerator(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
-	logger.Info("generator server configuration",
-		"rabbitmq_url", config.RabbitMQURL,
-		"sensor_queue", config.QueueName,
-		"device_queue", config.DeviceQueueName,
-		"producer_count", config.ProducerCount,
-		"interval", config.Interval,
-	)
-
 	if err := server.Run(context.Background()); err != nil {
 		logger.Error("generator server error", "error", err)
 		return err
@@ -0,0 +1,164 @@
+// Package main provides the unified CLI entry point for the demo-app services.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"procodus.dev/demo-app/internal/loadtest"
+	"procodus.dev/demo-app/pkg/mq"
+)
+
+// loadtestConnectTimeout bounds the wait for the RabbitMQ connection.
+const loadtestConnectTimeout = 30 * time.Second
+
+var loadtestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Short: "Measure the throughput of a running stack",
+	Long: `Publish sensor readings at a target rate to a running stack and report:
+- Publish rate, latency (P50/P95) and errors
+- Readings stored by the backend per second (DB write throughput)
+- Backend ingest latency (P50/P95) and errors, from its metrics endpoint
+
+The backend must expose metrics (--metrics-port) for the backend statistics.
+SIGINT/SIGTERM end the test early and print the report so far.`,
+	// Failed tests are not usage errors
+	SilenceUsage: true,
+	RunE:         runLoadtest,
+}
+
+func init() {
+	rootCmd.AddCommand(loadtestCmd)
+
+	// Loadtest-specific flags
+	loadtestCmd.Flags().String("rabbitmq-url", "amqp://localhost:5672", "RabbitMQ URL")
+	loadtestCmd.Flags().String("queue-name", "sensor-data", "RabbitMQ queue name for sensor readings")
+	loadtestCmd.Flags().String("device-queue-name", "device-data", "RabbitMQ queue name for device creation messages")
+	loadtestCmd.Flags().Bool("durable-queues", false, "Declare durable queues and publish persistent messages (must match the backend)")
+	loadtestCmd.Flags().Bool("priority-queues", false, "Declare queues with message priorities (must match the backend)")
+	loadtestCmd.Flags().Float64("rate", 100, "Target sensor readings per second")
+	loadtestCmd.Flags().Duration("duration", time.Minute, "How long to publish readings")
+	loadtestCmd.Flags().Int("devices", 0, "Number of simulated devices (0 = one per reading per second, at least 10)")
+	loadtestCmd.Flags().Int("workers", 8, "Number of concurrent publishes")
+	loadtestCmd.Flags().String("metrics-url", "", "Backend Prometheus endpoint, e.g. http://localhost:9090/metrics (empty = no backend statistics)")
+	loadtestCmd.Flags().Duration("settle", 5*time.Second, "Time to wait for the backend to catch up after publishing devices and readings")
+
+	// Bind flags to viper
+	if err := viper.BindPFlag("loadtest.rabbitmq.url", loadtestCmd.Flags().Lookup("rabbitmq-url")); err != nil {
+		log.Fatalf("failed to bind rabbitmq-url flag: %v", err)
+	}
+	if err := viper.BindPFlag("loadtest.rabbitmq.queue_name", loadtestCmd.Flags().Lookup("queue-name")); err != nil {
+		log.Fatalf("failed to bind queue-name flag: %v", err)
+	}
+	if err := viper.BindPFlag("loadtest.rabbitmq.device_queue_name", loadtestCmd.Flags().Lookup("device-queue-name")); err != nil {
+		log.Fatalf("failed to bind device-queue-name flag: %v", err)
+	}
+	if err := viper.BindPFlag("loadtest.rabbitmq.durable", loadtestCmd.Flags().Lookup("durable-queues")); err != nil {
+		log.Fatalf("failed to bind durable-queues flag: %v", err)
+	}
+	if err := viper.BindPFlag("loadtest.rabbitmq.priority", loadtestCmd.Flags().Lookup("priority-queues")); err != nil {
+		log.Fatalf("failed to bind priority-queues flag: %v", err)
+	}
+	if err := viper.BindPFlag("loadtest.rate", loadtestCmd.Flags().Lookup("rate")); err != nil {
+		log.Fatalf("failed to bind rate flag: %v", err)
+	}
+	if err := viper.BindPFlag("loadtest.duration", loadtestCmd.Flags().Lookup("duration")); err != nil {
+		log.Fatalf("failed to bind duration flag: %v", err)
+	}
+	if err := viper.BindPFlag("loadtest.devices", loadtestCmd.Flags().Lookup("devices")); err != nil {
+		log.Fatalf("failed to bind devices flag: %v", err)
+	}
+	if err := viper.BindPFlag("loadtest.workers", loadtestCmd.Flags().Lookup("workers")); err != nil {
+		log.Fatalf("failed to bind workers flag: %v", err)
+	}
+	if err := viper.BindPFlag("loadtest.metrics_url", loadtestCmd.Flags().Lookup("metrics-url")); err != nil {
+		log.Fatalf("failed to bind metrics-url flag: %v", err)
+	}
+	if err := viper.BindPFlag("loadtest.settle", loadtestCmd.Flags().Lookup("settle")); err != nil {
+		log.Fatalf("failed to bind settle flag: %v", err)
+	}
+}
+
+func runLoadtest(cmd *cobra.Command, _ []string) error {
+	logger := GetLogger()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	rabbitmqURL := viper.GetString("loadtest.rabbitmq.url")
+	durable := viper.GetBool("loadtest.rabbitmq.durable")
+	var maxPriority uint8
+	if viper.GetBool("loadtest.rabbitmq.priority") {
+		maxPriority = mq.MaxPriority
+	}
+	sensorQueue := viper.GetString("loadtest.rabbitmq.queue_name")
+
+	newPublisher := func(queue string) (*mq.Client, error) {
+		// Closed explicitly, so publishes in flight when interrupted can finish
+		client, err := mq.NewWithOptions(context.Background(), rabbitmqURL, mq.Options{Queues: []string{queue}, Durable: durable, MaxPriority: maxPriority}, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, loadtestConnectTimeout)
+		defer cancel()
+
+		if err := client.WaitReady(waitCtx); err != nil {
+			_ = client.Close()
+			return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+		}
+		return client, nil
+	}
+
+	sensors, err := newPublisher(sensorQueue)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = sensors.Close() }()
+
+	devices, err := newPublisher(viper.GetString("loadtest.rabbitmq.device_queue_name"))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = devices.Close() }()
+
+	lt, err := loadtest.New(&loadtest.Config{
+		Logger:          logger,
+		SensorPublisher: sensors,
+		DevicePublisher: devices,
+		Rate:            viper.GetFloat64("loadtest.rate"),
+		Duration:        viper.GetDuration("loadtest.duration"),
+		Devices:         viper.GetInt("loadtest.devices"),
+		Workers:         viper.GetInt("loadtest.workers"),
+		MetricsURL:      viper.GetString("loadtest.metrics_url"),
+		SensorQueue:     sensorQueue,
+		Settle:          viper.GetDuration("loadtest.settle"),
+	})
+	if err != nil {
+		return err
+	}
+
+	report, err := lt.Run(ctx)
+	if report != nil {
+		if printErr := report.Print(cmd.OutOrStdout()); printErr != nil {
+			return printErr
+		}
+	}
+	if err != nil {
+		logger.Error("load test failed", "error", err)
+		return err
+	}
+
+	if ctx.Err() != nil {
+		fmt.Fprintln(cmd.ErrOrStderr(), "load test interrupted; the report covers the readings published so far")
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+// Package main provides the unified CLI entry point for the demo-app services.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"procodus.dev/demo-app/internal/frontend"
+)
+
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Write the OpenAPI document of the frontend JSON routes",
+	Long: `Write the OpenAPI 3 document generated from the frontend's JSON routes and
+response types. The running frontend serves the same document at /openapi.json.
+
+Regenerate docs/openapi.json with go generate ./internal/frontend after changing
+the JSON routes.`,
+	RunE: runOpenAPI,
+}
+
+func init() {
+	rootCmd.AddCommand(openapiCmd)
+
+	openapiCmd.Flags().StringP("output", "o", "-", "File to write the document to (- for stdout)")
+	if err := openapiCmd.MarkFlagFilename("output", "json"); err != nil {
+		log.Fatalf("failed to mark output flag: %v", err)
+	}
+}
+
+func runOpenAPI(cmd *cobra.Command, _ []string) error {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+
+	spec, err := frontend.OpenAPISpec()
+	if err != nil {
+		return fmt.Errorf("failed to build OpenAPI document: %w", err)
+	}
+
+	if output == "-" {
+		_, err := cmd.OutOrStdout().Write(spec)
+		return err
+	}
+
+	if err := os.WriteFile(output, spec, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "wrote %s\n", output)
+
+	return nil
+}
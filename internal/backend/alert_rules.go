@@ -0,0 +1,246 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// alertRuleMetrics are the sensor metrics an alert rule can watch.
+var alertRuleMetrics = []string{"temperature", "humidity", "pressure", "battery_level"}
+
+// alertRuleOperators are the supported threshold comparisons.
+var alertRuleOperators = []string{"gt", "lt"}
+
+// trackRequest records in-flight, duration and result metrics for one RPC.
+// The returned function must be called with the RPC's error when it completes.
+func (s *IoTServiceImpl) trackRequest(method string) func(err error) {
+	if s.metrics == nil {
+		return func(error) {}
+	}
+
+	s.metrics.GRPCRequestsInFlight.WithLabelValues(method).Inc()
+	timer := prometheus.NewTimer(s.metrics.GRPCRequestDuration.WithLabelValues(method))
+
+	return func(err error) {
+		timer.ObserveDuration()
+		s.metrics.GRPCRequestsInFlight.WithLabelValues(method).Dec()
+
+		result := "success"
+		if err != nil {
+			result = "error"
+		}
+		s.metrics.GRPCRequestsTotal.WithLabelValues(method, result).Inc()
+	}
+}
+
+// ListAlertRules returns all alert rules ordered by name.
+func (s *IoTServiceImpl) ListAlertRules(ctx context.Context, _ *iotv1.ListAlertRulesRequest) (resp *iotv1.ListAlertRulesResponse, err error) {
+	done := s.trackRequest("ListAlertRules")
+	defer func() { done(err) }()
+
+	var rules []AlertRule
+	if err := s.db.WithContext(ctx).Order("name").Order("id").Find(&rules).Error; err != nil {
+		s.logger.Error("failed to fetch alert rules", "error", err)
+		return nil, databaseError("failed to fetch alert rules")
+	}
+
+	protoRules := make([]*iotv1.AlertRule, len(rules))
+	for i := range rules {
+		protoRules[i] = alertRuleToProto(&rules[i])
+	}
+
+	return &iotv1.ListAlertRulesResponse{
+		Rules: protoRules,
+	}, nil
+}
+
+// GetAlertRule returns a single alert rule by ID.
+func (s *IoTServiceImpl) GetAlertRule(ctx context.Context, req *iotv1.GetAlertRuleRequest) (resp *iotv1.GetAlertRuleResponse, err error) {
+	done := s.trackRequest("GetAlertRule")
+	defer func() { done(err) }()
+
+	rule, err := s.findAlertRule(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &iotv1.GetAlertRuleResponse{
+		Rule: alertRuleToProto(rule),
+	}, nil
+}
+
+// CreateAlertRule validates and stores a new alert rule.
+func (s *IoTServiceImpl) CreateAlertRule(ctx context.Context, req *iotv1.CreateAlertRuleRequest) (resp *iotv1.CreateAlertRuleResponse, err error) {
+	done := s.trackRequest("CreateAlertRule")
+	defer func() { done(err) }()
+
+	if err := validateAlertRule(req.GetRule()); err != nil {
+		return nil, err
+	}
+
+	rule := &AlertRule{}
+	applyAlertRule(rule, req.GetRule())
+
+	if err := s.db.WithContext(ctx).Create(rule).Error; err != nil {
+		s.logger.Error("failed to create alert rule", "error", err)
+		return nil, databaseError("failed to create alert rule")
+	}
+
+	s.logger.Info("created alert rule", "id", rule.ID, "name", rule.Name)
+
+	return &iotv1.CreateAlertRuleResponse{
+		Rule: alertRuleToProto(rule),
+	}, nil
+}
+
+// UpdateAlertRule replaces the editable fields of an existing alert rule.
+func (s *IoTServiceImpl) UpdateAlertRule(ctx context.Context, req *iotv1.UpdateAlertRuleRequest) (resp *iotv1.UpdateAlertRuleResponse, err error) {
+	done := s.trackRequest("UpdateAlertRule")
+	defer func() { done(err) }()
+
+	if err := validateAlertRule(req.GetRule()); err != nil {
+		return nil, err
+	}
+
+	rule, err := s.findAlertRule(ctx, req.GetRule().GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	applyAlertRule(rule, req.GetRule())
+
+	if err := s.db.WithContext(ctx).Save(rule).Error; err != nil {
+		s.logger.Error("failed to update alert rule", "id", rule.ID, "error", err)
+		return nil, databaseError("failed to update alert rule")
+	}
+
+	s.logger.Info("updated alert rule", "id", rule.ID, "name", rule.Name)
+
+	return &iotv1.UpdateAlertRuleResponse{
+		Rule: alertRuleToProto(rule),
+	}, nil
+}
+
+// DeleteAlertRule removes an alert rule.
+func (s *IoTServiceImpl) DeleteAlertRule(ctx context.Context, req *iotv1.DeleteAlertRuleRequest) (resp *iotv1.DeleteAlertRuleResponse, err error) {
+	done := s.trackRequest("DeleteAlertRule")
+	defer func() { done(err) }()
+
+	result := s.db.WithContext(ctx).Delete(&AlertRule{}, req.GetId())
+	if result.Error != nil {
+		s.logger.Error("failed to delete alert rule", "id", req.GetId(), "error", result.Error)
+		return nil, databaseError("failed to delete alert rule")
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, iotv1.AlertRuleNotFoundError(req.GetId())
+	}
+
+	s.logger.Info("deleted alert rule", "id", req.GetId())
+
+	return &iotv1.DeleteAlertRuleResponse{}, nil
+}
+
+// findAlertRule loads an alert rule or returns a NotFound error.
+func (s *IoTServiceImpl) findAlertRule(ctx context.Context, id uint64) (*AlertRule, error) {
+	var rule AlertRule
+	if err := s.db.WithContext(ctx).First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, iotv1.AlertRuleNotFoundError(id)
+		}
+		s.logger.Error("failed to fetch alert rule", "id", id, "error", err)
+		return nil, databaseError("failed to fetch alert rule")
+	}
+
+	return &rule, nil
+}
+
+// validateAlertRule checks the user-editable fields of an alert rule.
+func validateAlertRule(rule *iotv1.AlertRule) error {
+	if rule == nil {
+		return iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "rule", "cannot be empty")
+	}
+
+	var violations []iotv1.FieldViolation
+
+	if strings.TrimSpace(rule.GetName()) == "" {
+		violations = append(violations, iotv1.FieldViolation{Field: "name", Description: "cannot be empty"})
+	}
+
+	if !slices.Contains(alertRuleMetrics, rule.GetMetric()) {
+		violations = append(violations, iotv1.FieldViolation{Field: "metric", Description: "must be one of " + strings.Join(alertRuleMetrics, ", ")})
+	}
+
+	if !slices.Contains(alertRuleOperators, rule.GetOperator()) {
+		violations = append(violations, iotv1.FieldViolation{Field: "operator", Description: "must be gt or lt"})
+	}
+
+	if (rule.GetSilenceStart() == 0) != (rule.GetSilenceEnd() == 0) {
+		violations = append(violations, iotv1.FieldViolation{Field: "silence_end", Description: "silence start and end must be set together"})
+	} else if rule.GetSilenceStart() != 0 && rule.GetSilenceStart() >= rule.GetSilenceEnd() {
+		violations = append(violations, iotv1.FieldViolation{Field: "silence_end", Description: "must be after silence_start"})
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return iotv1.NewError(codes.InvalidArgument, iotv1.ReasonInvalidArgument, "invalid alert rule", nil, violations...)
+}
+
+// applyAlertRule copies the editable fields of a proto rule onto the model.
+func applyAlertRule(rule *AlertRule, in *iotv1.AlertRule) {
+	rule.Name = strings.TrimSpace(in.GetName())
+	rule.Metric = in.GetMetric()
+	rule.Operator = in.GetOperator()
+	rule.Threshold = in.GetThreshold()
+	rule.DeviceID = strings.TrimSpace(in.GetDeviceId())
+	rule.Enabled = in.GetEnabled()
+	rule.SilenceStart = unixToTime(in.GetSilenceStart())
+	rule.SilenceEnd = unixToTime(in.GetSilenceEnd())
+}
+
+// alertRuleToProto converts an alert rule model to its proto message.
+func alertRuleToProto(rule *AlertRule) *iotv1.AlertRule {
+	return &iotv1.AlertRule{
+		Id:           uint64(rule.ID),
+		Name:         rule.Name,
+		Metric:       rule.Metric,
+		Operator:     rule.Operator,
+		Threshold:    rule.Threshold,
+		DeviceId:     rule.DeviceID,
+		Enabled:      rule.Enabled,
+		SilenceStart: timeToUnix(rule.SilenceStart),
+		SilenceEnd:   timeToUnix(rule.SilenceEnd),
+		CreatedAt:    rule.CreatedAt.Unix(),
+		UpdatedAt:    rule.UpdatedAt.Unix(),
+	}
+}
+
+// unixToTime converts a Unix timestamp to a time, treating 0 as unset.
+func unixToTime(ts int64) *time.Time {
+	if ts == 0 {
+		return nil
+	}
+
+	t := time.Unix(ts, 0)
+	return &t
+}
+
+// timeToUnix converts an optional time to a Unix timestamp, 0 when unset.
+func timeToUnix(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+
+	return t.Unix()
+}
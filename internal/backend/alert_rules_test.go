@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Alert rules", func() {
+	validRule := func() *iotv1.AlertRule {
+		return &iotv1.AlertRule{
+			Name:      "Hot room",
+			Metric:    "temperature",
+			Operator:  "gt",
+			Threshold: 30,
+			Enabled:   true,
+		}
+	}
+
+	Describe("validateAlertRule", func() {
+		It("should accept a valid rule", func() {
+			Expect(validateAlertRule(validRule())).To(Succeed())
+		})
+
+		It("should report every invalid field", func() {
+			rule := &iotv1.AlertRule{Metric: "voltage", Operator: "eq"}
+
+			err := validateAlertRule(rule)
+
+			Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonInvalidArgument))
+			Expect(iotv1.FieldViolations(err)).To(ConsistOf(
+				HaveField("Field", "name"),
+				HaveField("Field", "metric"),
+				HaveField("Field", "operator"),
+			))
+		})
+
+		It("should require a complete, ordered silence window", func() {
+			rule := validRule()
+			rule.SilenceStart = 100
+			Expect(iotv1.FieldViolations(validateAlertRule(rule))).To(ContainElement(HaveField("Field", "silence_end")))
+
+			rule.SilenceEnd = 50
+			Expect(iotv1.FieldViolations(validateAlertRule(rule))).To(ContainElement(HaveField("Field", "silence_end")))
+
+			rule.SilenceEnd = 200
+			Expect(validateAlertRule(rule)).To(Succeed())
+		})
+	})
+
+	It("should round-trip rules through the model", func() {
+		in := validRule()
+		in.DeviceId = " device-001 "
+		in.SilenceStart = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+		in.SilenceEnd = time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC).Unix()
+
+		model := &AlertRule{ID: 7}
+		applyAlertRule(model, in)
+		out := alertRuleToProto(model)
+
+		Expect(out.GetId()).To(Equal(uint64(7)))
+		Expect(out.GetDeviceId()).To(Equal("device-001"))
+		Expect(out.GetSilenceStart()).To(Equal(in.GetSilenceStart()))
+		Expect(out.GetSilenceEnd()).To(Equal(in.GetSilenceEnd()))
+		Expect(out.GetEnabled()).To(BeTrue())
+	})
+})
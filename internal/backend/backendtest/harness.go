@@ -0,0 +1,266 @@
+// Package backendtest runs the backend in-process for tests: the gRPC service
+// and both consumers on an in-memory SQLite database, fed by mocked message
+// queues. It covers the consumer → database → API flow without containers.
+package backendtest
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"sync/atomic"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+
+	"procodus.dev/demo-app/internal/backend"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/pkg/mq/mock"
+)
+
+// settleTimeout is how long Publish waits for a consumer to settle a message.
+const settleTimeout = 5 * time.Second
+
+// TB is the part of testing.TB the harness needs; GinkgoT() satisfies it.
+type TB interface {
+	Helper()
+	Cleanup(f func())
+	Fatalf(format string, args ...any)
+}
+
+// Outcome is how a consumer settled a published message.
+type Outcome string
+
+// Message outcomes.
+const (
+	Acked    Outcome = "ack"
+	Requeued Outcome = "nack-requeue"
+	Nacked   Outcome = "nack"
+	Rejected Outcome = "reject"
+)
+
+// Harness is an in-process backend. It is torn down when the test ends.
+type Harness struct {
+	// DB is the harness database, for seeding and assertions.
+	DB *gorm.DB
+	// Client calls the gRPC service over an in-memory connection.
+	Client iotv1.IoTServiceClient
+
+	tb       TB
+	readings chan amqp.Delivery
+	devices  chan amqp.Delivery
+	tag      atomic.Uint64
+}
+
+// New starts a harness with an empty database.
+func New(tb TB) *Harness {
+	tb.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	db, err := backend.NewDB(&backend.DBConfig{
+		Logger: logger,
+		Driver: backend.DriverSQLite,
+		DBName: ":memory:",
+	})
+	if err != nil {
+		tb.Fatalf("failed to open database: %v", err)
+	}
+	tb.Cleanup(func() { _ = backend.CloseDB(db, logger) })
+
+	h := &Harness{
+		DB:       db,
+		tb:       tb,
+		readings: make(chan amqp.Delivery),
+		devices:  make(chan amqp.Delivery),
+	}
+
+	h.startConsumers(logger)
+	h.startGRPC(logger)
+
+	return h
+}
+
+// startConsumers runs both consumers on one mocked MQ client, as the server
+// shares a single client between them.
+func (h *Harness) startConsumers(logger *slog.Logger) {
+	client := mock.NewMockClient()
+	client.QueueChannels = map[string]<-chan amqp.Delivery{
+		"sensor-data": h.readings,
+		"device-data": h.devices,
+	}
+
+	consumer, err := backend.NewConsumer(&backend.ConsumerConfig{
+		Logger:    logger,
+		DB:        h.DB,
+		QueueName: "sensor-data",
+		MQClient:  client,
+	})
+	if err != nil {
+		h.tb.Fatalf("failed to create consumer: %v", err)
+	}
+
+	deviceConsumer, err := backend.NewDeviceConsumer(&backend.DeviceConsumerConfig{
+		Logger:    logger,
+		DB:        h.DB,
+		QueueName: "device-data",
+		MQClient:  client,
+	})
+	if err != nil {
+		h.tb.Fatalf("failed to create device consumer: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := consumer.Start(ctx); err != nil {
+		h.tb.Fatalf("failed to start consumer: %v", err)
+	}
+	h.tb.Cleanup(func() { _ = consumer.Stop() })
+
+	if err := deviceConsumer.Start(ctx); err != nil {
+		h.tb.Fatalf("failed to start device consumer: %v", err)
+	}
+	h.tb.Cleanup(func() { _ = deviceConsumer.Stop() })
+}
+
+// startGRPC serves the IoT service on an in-memory listener and connects Client.
+func (h *Harness) startGRPC(logger *slog.Logger) {
+	service, err := backend.NewIoTService(logger, h.DB, nil)
+	if err != nil {
+		h.tb.Fatalf("failed to create gRPC service: %v", err)
+	}
+
+	// Calls pass the interceptors of a production server, e.g. validation
+	interceptors, err := backend.UnaryInterceptors(&backend.ServerConfig{Logger: logger})
+	if err != nil {
+		h.tb.Fatalf("failed to build gRPC interceptors: %v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	iotv1.RegisterIoTServiceServer(srv, service)
+	go func() { _ = srv.Serve(lis) }()
+	h.tb.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///backendtest",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		h.tb.Fatalf("failed to connect to gRPC service: %v", err)
+	}
+	h.tb.Cleanup(func() { _ = conn.Close() })
+
+	h.Client = iotv1.NewIoTServiceClient(conn)
+}
+
+// PublishDevice delivers a device message to the device consumer and returns
+// once it has been settled.
+func (h *Harness) PublishDevice(device *iotv1.IoTDevice) Outcome {
+	h.tb.Helper()
+	return h.publish(h.devices, h.marshal(device))
+}
+
+// PublishReading delivers a sensor reading to the reading consumer and returns
+// once it has been settled.
+func (h *Harness) PublishReading(reading *iotv1.SensorReading) Outcome {
+	h.tb.Helper()
+	return h.publish(h.readings, h.marshal(reading))
+}
+
+// PublishRawReading delivers an arbitrary body to the reading consumer, e.g.
+// to test malformed messages.
+func (h *Harness) PublishRawReading(body []byte) Outcome {
+	h.tb.Helper()
+	return h.publish(h.readings, body)
+}
+
+// SeedDevice stores a device directly, bypassing the consumer.
+func (h *Harness) SeedDevice(deviceID string) *backend.IoTDevice {
+	h.tb.Helper()
+
+	device := &backend.IoTDevice{DeviceID: deviceID, LastSeen: time.Now().UTC()}
+	if err := h.DB.Create(device).Error; err != nil {
+		h.tb.Fatalf("failed to seed device %s: %v", deviceID, err)
+	}
+
+	return device
+}
+
+// SeedReadings stores readings directly, bypassing the consumer.
+func (h *Harness) SeedReadings(readings ...backend.SensorReading) {
+	h.tb.Helper()
+
+	if err := h.DB.Create(&readings).Error; err != nil {
+		h.tb.Fatalf("failed to seed readings: %v", err)
+	}
+}
+
+func (h *Harness) marshal(m proto.Message) []byte {
+	h.tb.Helper()
+
+	body, err := proto.Marshal(m)
+	if err != nil {
+		h.tb.Fatalf("failed to marshal message: %v", err)
+	}
+
+	return body
+}
+
+func (h *Harness) publish(queue chan<- amqp.Delivery, body []byte) Outcome {
+	h.tb.Helper()
+
+	ack := &acknowledger{settled: make(chan Outcome, 1)}
+	delivery := amqp.Delivery{
+		Acknowledger: ack,
+		DeliveryTag:  h.tag.Add(1),
+		ContentType:  "application/x-protobuf",
+		Body:         body,
+	}
+
+	timeout := time.After(settleTimeout)
+
+	select {
+	case queue <- delivery:
+	case <-timeout:
+		h.tb.Fatalf("timed out publishing message")
+	}
+
+	select {
+	case outcome := <-ack.settled:
+		return outcome
+	case <-timeout:
+		h.tb.Fatalf("timed out waiting for message to be settled")
+		return ""
+	}
+}
+
+// acknowledger records how a single delivery was settled.
+type acknowledger struct {
+	settled chan Outcome
+}
+
+func (a *acknowledger) Ack(_ uint64, _ bool) error {
+	a.settled <- Acked
+	return nil
+}
+
+func (a *acknowledger) Nack(_ uint64, _ bool, requeue bool) error {
+	if requeue {
+		a.settled <- Requeued
+	} else {
+		a.settled <- Nacked
+	}
+	return nil
+}
+
+func (a *acknowledger) Reject(_ uint64, _ bool) error {
+	a.settled <- Rejected
+	return nil
+}
@@ -0,0 +1,111 @@
+package backendtest_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"procodus.dev/demo-app/internal/backend"
+	"procodus.dev/demo-app/internal/backend/backendtest"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Harness", func() {
+	var (
+		h   *backendtest.Harness
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		h = backendtest.New(GinkgoT())
+		ctx = context.Background()
+	})
+
+	It("should serve devices and readings consumed from the queues", func() {
+		now := time.Now().UTC().Truncate(time.Second)
+
+		Expect(h.PublishDevice(&iotv1.IoTDevice{
+			DeviceId:  "device-001",
+			Location:  "Berlin",
+			Timestamp: now.Unix(),
+		})).To(Equal(backendtest.Acked))
+
+		for i := range 3 {
+			Expect(h.PublishReading(&iotv1.SensorReading{
+				DeviceId:     "device-001",
+				Timestamp:    now.Add(time.Duration(i-3) * time.Minute).Unix(),
+				Temperature:  20 + float64(i),
+				Humidity:     45,
+				Pressure:     1013,
+				BatteryLevel: 90,
+			})).To(Equal(backendtest.Acked))
+		}
+
+		device, err := h.Client.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{DeviceId: "device-001"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(device.GetDevice().GetLocation()).To(Equal("Berlin"))
+
+		readings, err := h.Client.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{DeviceId: "device-001"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(readings.GetReading()).To(HaveLen(3))
+		Expect(readings.GetReading()[0].GetTemperature()).To(Equal(22.0))
+	})
+
+	It("should update devices published twice", func() {
+		Expect(h.PublishDevice(&iotv1.IoTDevice{DeviceId: "device-001", Location: "Berlin"})).To(Equal(backendtest.Acked))
+		Expect(h.PublishDevice(&iotv1.IoTDevice{DeviceId: "device-001", Location: "Hamburg"})).To(Equal(backendtest.Acked))
+
+		devices, err := h.Client.GetAllDevice(ctx, &iotv1.GetAllDevicesRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(devices.GetDevices()).To(ConsistOf(HaveField("Location", "Hamburg")))
+	})
+
+	It("should store redelivered readings once", func() {
+		h.SeedDevice("device-001")
+		reading := &iotv1.SensorReading{DeviceId: "device-001", Timestamp: time.Now().Unix(), Temperature: 21}
+
+		Expect(h.PublishReading(reading)).To(Equal(backendtest.Acked))
+		Expect(h.PublishReading(reading)).To(Equal(backendtest.Acked))
+
+		var count int64
+		Expect(h.DB.Model(&backend.SensorReading{}).Count(&count).Error).To(Succeed())
+		Expect(count).To(Equal(int64(1)))
+	})
+
+	It("should acknowledge and drop readings of unknown devices", func() {
+		Expect(h.PublishReading(&iotv1.SensorReading{DeviceId: "unknown", Timestamp: time.Now().Unix()})).To(Equal(backendtest.Acked))
+
+		var count int64
+		Expect(h.DB.Model(&backend.SensorReading{}).Count(&count).Error).To(Succeed())
+		Expect(count).To(BeZero())
+	})
+
+	It("should acknowledge malformed messages", func() {
+		Expect(h.PublishRawReading([]byte{0xff, 0xff})).To(Equal(backendtest.Acked))
+	})
+
+	It("should serve seeded data", func() {
+		h.SeedDevice("device-002")
+		h.SeedReadings(backend.SensorReading{DeviceID: "device-002", Timestamp: time.Now().UTC(), Temperature: 18})
+
+		count, err := h.Client.CountReadings(ctx, &iotv1.CountReadingsRequest{DeviceId: "device-002"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count.GetCount()).To(Equal(int64(1)))
+	})
+
+	It("should validate requests like a production server", func() {
+		_, err := h.Client.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{DeviceId: "device-001", PageSize: -1})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+	})
+
+	It("should isolate databases between harnesses", func() {
+		h.SeedDevice("device-003")
+
+		_, err := backendtest.New(GinkgoT()).Client.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{DeviceId: "device-003"})
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+	})
+})
@@ -0,0 +1,76 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+const (
+	// dbPauseMinBackoff is the first wait before pinging an unreachable
+	// database again; it doubles up to dbPauseMaxBackoff.
+	dbPauseMinBackoff = 500 * time.Millisecond
+	dbPauseMaxBackoff = 30 * time.Second
+	// dbPingTimeout bounds a single ping.
+	dbPingTimeout = 5 * time.Second
+)
+
+// pauseWhileDBDown blocks while the database is unreachable, pinging it with
+// exponential backoff, and returns once a ping succeeds or ctx is done.
+// Consumers call it after a failed save: messages are delivered one at a time
+// (prefetch count 1), so nothing else is delivered until the handler returns
+// and the requeued message is not redelivered in a tight loop. Failures with
+// a reachable database, e.g. constraint violations, do not pause.
+func pauseWhileDBDown(ctx context.Context, db *gorm.DB, logger *slog.Logger, m *metrics.BackendMetrics, queue string) {
+	err := pingDB(ctx, db)
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+
+	logger.Warn("database unavailable, pausing consumption", "queue", queue, "error", err)
+
+	start := time.Now()
+	if m != nil {
+		m.ConsumerPaused.WithLabelValues(queue).Set(1)
+	}
+	defer func() {
+		if m != nil {
+			m.ConsumerPaused.WithLabelValues(queue).Set(0)
+			m.ConsumerPausedSeconds.WithLabelValues(queue).Add(time.Since(start).Seconds())
+		}
+	}()
+
+	backoff := dbPauseMinBackoff
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		if err := pingDB(ctx, db); err == nil {
+			logger.Info("database available again, resuming consumption", "queue", queue, "paused", time.Since(start))
+			return
+		}
+
+		backoff = min(backoff*2, dbPauseMaxBackoff)
+	}
+}
+
+// pingDB pings the database behind db.
+func pingDB(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dbPingTimeout)
+	defer cancel()
+
+	return sqlDB.PingContext(ctx)
+}
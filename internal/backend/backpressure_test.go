@@ -0,0 +1,117 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// flakyConnector opens in-memory SQLite connections, or fails while down.
+type flakyConnector struct {
+	driver driver.Driver
+	down   atomic.Bool
+}
+
+func (c *flakyConnector) Connect(context.Context) (driver.Conn, error) {
+	if c.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	return c.driver.Open(":memory:")
+}
+
+func (c *flakyConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+var _ = Describe("Consumer backpressure", func() {
+	var (
+		logger    *slog.Logger
+		connector *flakyConnector
+		db        *gorm.DB
+	)
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError + 1,
+		}))
+
+		probe, err := sql.Open(sqlite.DriverName, ":memory:")
+		Expect(err).NotTo(HaveOccurred())
+		connector = &flakyConnector{driver: probe.Driver()}
+		Expect(probe.Close()).To(Succeed())
+
+		// Without idle connections every ping opens a new one
+		sqlDB := sql.OpenDB(connector)
+		sqlDB.SetMaxIdleConns(0)
+		DeferCleanup(sqlDB.Close)
+
+		db, err = gorm.Open(sqlite.Dialector{Conn: sqlDB}, &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not pause while the database is reachable", func() {
+		start := time.Now()
+		pauseWhileDBDown(context.Background(), db, logger, consumerTestMetrics, "pause-healthy-test")
+
+		Expect(time.Since(start)).To(BeNumerically("<", dbPauseMinBackoff))
+		Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerPausedSeconds.WithLabelValues("pause-healthy-test"))).To(BeZero())
+	})
+
+	It("should stop taking messages until the database is back", func() {
+		c := &Consumer{
+			logger:    logger,
+			db:        db,
+			metrics:   consumerTestMetrics,
+			queueName: "pause-test",
+		}
+		body, err := proto.Marshal(&iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: 1700000000})
+		Expect(err).NotTo(HaveOccurred())
+
+		connector.down.Store(true)
+		ack := &fakeAcknowledger{}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.handleDelivery(context.Background(), amqp.Delivery{Acknowledger: ack, Body: body})
+		}()
+
+		paused := func() float64 {
+			return testutil.ToFloat64(consumerTestMetrics.ConsumerPaused.WithLabelValues("pause-test"))
+		}
+		Eventually(paused).Should(Equal(1.0))
+		Consistently(done, 200*time.Millisecond).ShouldNot(BeClosed())
+
+		connector.down.Store(false)
+		Eventually(done, 5*time.Second).Should(BeClosed())
+
+		Expect(ack.nacks).To(Equal(1))
+		Expect(ack.requeued).To(BeTrue())
+		Expect(paused()).To(BeZero())
+		Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerPausedSeconds.WithLabelValues("pause-test"))).To(BeNumerically(">", 0.2))
+	})
+
+	It("should stop pausing when the consumer stops", func() {
+		connector.down.Store(true)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		pauseWhileDBDown(ctx, db, logger, nil, "pause-stop-test")
+
+		Expect(ctx.Err()).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,555 @@
+package backend
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// backupVersion is the version of the backup format written by WriteBackup.
+// Version 1 backups, without device events, locations and notes, can still
+// be restored.
+const backupVersion = 2
+
+// backupBatchSize is how many rows are read or inserted per query while
+// backing up and restoring.
+const backupBatchSize = 1000
+
+// Record kinds of a backup. A backup is gzip-compressed JSON lines: a header,
+// one line per device, device event, location, note and reading, and a
+// trailer carrying the row counts and the SHA-256 of every line before it.
+const (
+	backupKindHeader   = "header"
+	backupKindDevice   = "device"
+	backupKindEvent    = "device_event"
+	backupKindLocation = "location"
+	backupKindNote     = "note"
+	backupKindReading  = "reading"
+	backupKindTrailer  = "trailer"
+)
+
+// BackupSummary describes a written or restored backup.
+type BackupSummary struct {
+	CreatedAt time.Time
+	SHA256    string // Checksum of the backup records, as stored in the trailer
+	Devices   int64
+	Events    int64
+	Locations int64
+	Notes     int64
+	Readings  int64
+}
+
+// RestoreOptions controls how a backup is restored.
+type RestoreOptions struct {
+	// Replace deletes the devices and everything recorded about them before
+	// restoring: device events, location history, notes, readings, rollups,
+	// battery projections, quarantined readings and processed message IDs.
+	// Alert rules, report schedules and the uptime history are kept. Without
+	// it, restoring into a database that has devices fails.
+	Replace bool
+}
+
+// backupRecord is one line of a backup. Kind decides which fields are set.
+type backupRecord struct {
+	CreatedAt *time.Time      `json:"created_at,omitempty"`
+	Device    *backupDevice   `json:"device,omitempty"`
+	Event     *backupEvent    `json:"device_event,omitempty"`
+	Location  *backupLocation `json:"location,omitempty"`
+	Note      *backupNote     `json:"note,omitempty"`
+	Reading   *backupReading  `json:"reading,omitempty"`
+	Kind      string          `json:"kind"`
+	SHA256    string          `json:"sha256,omitempty"`
+	Version   int             `json:"version,omitempty"`
+	Devices   int64           `json:"devices,omitempty"`
+	Events    int64           `json:"device_events,omitempty"`
+	Locations int64           `json:"locations,omitempty"`
+	Notes     int64           `json:"notes,omitempty"`
+	Readings  int64           `json:"readings,omitempty"`
+}
+
+// backupDevice is a device in a backup. Deleted devices are kept with their
+// deletion time so the trash survives a restore.
+type backupDevice struct {
+	LastSeen   time.Time  `json:"last_seen"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	DeviceID   string     `json:"device_id"`
+	Location   string     `json:"location"`
+	MACAddress string     `json:"mac_address"`
+	IPAddress  string     `json:"ip_address"`
+	Firmware   string     `json:"firmware"`
+	Latitude   float32    `json:"latitude"`
+	Longitude  float32    `json:"longitude"`
+}
+
+// backupEvent is a device event in a backup. Events are restored in backup
+// order, so they replay the devices as before.
+type backupEvent struct {
+	RecordedAt time.Time `json:"recorded_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	Payload    []byte    `json:"payload"`
+	DeviceID   string    `json:"device_id"`
+	Source     string    `json:"source"`
+	MessageID  string    `json:"message_id,omitempty"`
+}
+
+// backupLocation is an entry of the location history in a backup.
+type backupLocation struct {
+	RecordedAt time.Time `json:"recorded_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	DeviceID   string    `json:"device_id"`
+	IPAddress  string    `json:"ip_address"`
+	Latitude   float32   `json:"latitude"`
+	Longitude  float32   `json:"longitude"`
+}
+
+// backupNote is a device note in a backup.
+type backupNote struct {
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	DeviceID      string    `json:"device_id"`
+	Author        string    `json:"author"`
+	Body          string    `json:"body"`
+	AttachmentURL string    `json:"attachment_url,omitempty"`
+}
+
+// backupReading is a sensor reading in a backup.
+type backupReading struct {
+	Timestamp    time.Time `json:"timestamp"`
+	DeviceID     string    `json:"device_id"`
+	Temperature  float64   `json:"temperature"`
+	Humidity     float64   `json:"humidity"`
+	Pressure     float64   `json:"pressure"`
+	BatteryLevel float64   `json:"battery_level"`
+}
+
+// backupWriter encodes records and hashes every line it writes.
+type backupWriter struct {
+	enc  *json.Encoder
+	hash hash.Hash
+}
+
+func newBackupWriter(w io.Writer) *backupWriter {
+	h := sha256.New()
+	return &backupWriter{enc: json.NewEncoder(io.MultiWriter(w, h)), hash: h}
+}
+
+// write appends one record.
+func (w *backupWriter) write(record *backupRecord) error {
+	return w.enc.Encode(record)
+}
+
+// WriteBackup writes all devices, including deleted ones, their events,
+// location history and notes, and all readings of db to w. Rows are read in ID
+// order in batches, so backups of any size need little memory, within one
+// read-only transaction, so the backup is a consistent snapshot.
+func WriteBackup(ctx context.Context, db *gorm.DB, w io.Writer) (*BackupSummary, error) {
+	zw := gzip.NewWriter(w)
+	bw := newBackupWriter(zw)
+
+	summary := &BackupSummary{CreatedAt: time.Now().UTC()}
+	if err := bw.write(&backupRecord{Kind: backupKindHeader, Version: backupVersion, CreatedAt: &summary.CreatedAt}); err != nil {
+		return nil, err
+	}
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := backupRows(tx.Unscoped(), bw, &summary.Devices, func(d *IoTDevice) *backupRecord {
+			return &backupRecord{Kind: backupKindDevice, Device: deviceToBackup(d)}
+		}); err != nil {
+			return fmt.Errorf("failed to back up devices: %w", err)
+		}
+
+		if err := backupRows(tx, bw, &summary.Events, func(e *DeviceEvent) *backupRecord {
+			return &backupRecord{Kind: backupKindEvent, Event: &backupEvent{
+				RecordedAt: e.RecordedAt.UTC(),
+				CreatedAt:  e.CreatedAt.UTC(),
+				Payload:    e.Payload,
+				DeviceID:   e.DeviceID,
+				Source:     e.Source,
+				MessageID:  e.MessageID,
+			}}
+		}); err != nil {
+			return fmt.Errorf("failed to back up device events: %w", err)
+		}
+
+		if err := backupRows(tx, bw, &summary.Locations, func(l *DeviceLocationHistory) *backupRecord {
+			return &backupRecord{Kind: backupKindLocation, Location: &backupLocation{
+				RecordedAt: l.RecordedAt.UTC(),
+				CreatedAt:  l.CreatedAt.UTC(),
+				DeviceID:   l.DeviceID,
+				IPAddress:  l.IPAddress,
+				Latitude:   l.Latitude,
+				Longitude:  l.Longitude,
+			}}
+		}); err != nil {
+			return fmt.Errorf("failed to back up location history: %w", err)
+		}
+
+		if err := backupRows(tx, bw, &summary.Notes, func(n *DeviceNote) *backupRecord {
+			return &backupRecord{Kind: backupKindNote, Note: &backupNote{
+				CreatedAt:     n.CreatedAt.UTC(),
+				UpdatedAt:     n.UpdatedAt.UTC(),
+				DeviceID:      n.DeviceID,
+				Author:        n.Author,
+				Body:          n.Body,
+				AttachmentURL: n.AttachmentURL,
+			}}
+		}); err != nil {
+			return fmt.Errorf("failed to back up notes: %w", err)
+		}
+
+		if err := backupRows(tx, bw, &summary.Readings, func(r *SensorReading) *backupRecord {
+			return &backupRecord{Kind: backupKindReading, Reading: &backupReading{
+				Timestamp:    r.Timestamp.UTC(),
+				DeviceID:     r.DeviceID,
+				Temperature:  r.Temperature,
+				Humidity:     r.Humidity,
+				Pressure:     r.Pressure,
+				BatteryLevel: r.BatteryLevel,
+			}}
+		}); err != nil {
+			return fmt.Errorf("failed to back up readings: %w", err)
+		}
+
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	summary.SHA256 = hex.EncodeToString(bw.hash.Sum(nil))
+	if err := bw.write(&backupRecord{
+		Kind:      backupKindTrailer,
+		Devices:   summary.Devices,
+		Events:    summary.Events,
+		Locations: summary.Locations,
+		Notes:     summary.Notes,
+		Readings:  summary.Readings,
+		SHA256:    summary.SHA256,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// backupRows writes a record for every row of model T, in ID order, and
+// counts the rows in count.
+func backupRows[T any](tx *gorm.DB, bw *backupWriter, count *int64, record func(*T) *backupRecord) error {
+	var rows []T
+	return tx.Order("id").FindInBatches(&rows, backupBatchSize, func(_ *gorm.DB, _ int) error {
+		for i := range rows {
+			if err := bw.write(record(&rows[i])); err != nil {
+				return err
+			}
+			*count++
+		}
+		return nil
+	}).Error
+}
+
+// RestoreBackup loads a backup written by WriteBackup into db in a single
+// transaction. The checksum and row counts in the trailer are verified
+// before the transaction commits, so a truncated or corrupted backup leaves
+// the database unchanged.
+func RestoreBackup(ctx context.Context, db *gorm.DB, r io.Reader, opts RestoreOptions) (*BackupSummary, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a backup: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	summary := &BackupSummary{}
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := prepareRestore(tx, opts); err != nil {
+			return err
+		}
+
+		return restoreRecords(tx, bufio.NewReader(zr), summary)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// prepareRestore empties the tables restored into, or checks that they are
+// empty.
+func prepareRestore(tx *gorm.DB, opts RestoreOptions) error {
+	if !opts.Replace {
+		var devices int64
+		if err := tx.Model(&IoTDevice{}).Unscoped().Count(&devices).Error; err != nil {
+			return fmt.Errorf("failed to count devices: %w", err)
+		}
+		if devices > 0 {
+			return fmt.Errorf("database already has %d devices", devices)
+		}
+		return nil
+	}
+
+	// The rollup job rolls up the restored readings as newly stored ones,
+	// and the battery projector recomputes the projections. The message IDs
+	// of the replaced data would make consumers skip redelivered messages
+	// whose data is not restored.
+	tables := []struct {
+		model any
+		name  string
+	}{
+		{&SensorReading{}, "readings"},
+		{&HourlyReadingRollup{}, "rollups"},
+		{&DailyReadingRollup{}, "rollups"},
+		{&BatteryProjection{}, "battery projections"},
+		{&QuarantinedReading{}, "quarantined readings"},
+		{&ProcessedMessage{}, "processed messages"},
+		{&DeviceEvent{}, "device events"},
+		{&DeviceLocationHistory{}, "location history"},
+		{&DeviceNote{}, "notes"},
+	}
+	for _, table := range tables {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(table.model).Error; err != nil {
+			return fmt.Errorf("failed to delete %s: %w", table.name, err)
+		}
+	}
+	if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(&IoTDevice{}).Error; err != nil {
+		return fmt.Errorf("failed to delete devices: %w", err)
+	}
+
+	return nil
+}
+
+// restoreRecords inserts the records read from r, hashing every line up to
+// the trailer, and verifies the trailer.
+func restoreRecords(tx *gorm.DB, r *bufio.Reader, summary *BackupSummary) error {
+	h := sha256.New()
+	var (
+		devices   []IoTDevice
+		events    []DeviceEvent
+		locations []DeviceLocationHistory
+		notes     []DeviceNote
+		readings  []SensorReading
+		trailer   *backupRecord
+	)
+
+	// flush inserts the pending rows, devices first so the foreign keys of
+	// readings are satisfied
+	flush := func() error {
+		if len(devices) > 0 {
+			if err := tx.Create(&devices).Error; err != nil {
+				return fmt.Errorf("failed to restore devices: %w", err)
+			}
+			devices = devices[:0]
+		}
+		if len(events) > 0 {
+			if err := tx.Create(&events).Error; err != nil {
+				return fmt.Errorf("failed to restore device events: %w", err)
+			}
+			events = events[:0]
+		}
+		if len(locations) > 0 {
+			if err := tx.Create(&locations).Error; err != nil {
+				return fmt.Errorf("failed to restore location history: %w", err)
+			}
+			locations = locations[:0]
+		}
+		if len(notes) > 0 {
+			if err := tx.Create(&notes).Error; err != nil {
+				return fmt.Errorf("failed to restore notes: %w", err)
+			}
+			notes = notes[:0]
+		}
+		if len(readings) > 0 {
+			if err := tx.Create(&readings).Error; err != nil {
+				return fmt.Errorf("failed to restore readings: %w", err)
+			}
+			readings = readings[:0]
+		}
+		return nil
+	}
+
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(data) == 0 {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read backup: %w", err)
+		}
+		if trailer != nil {
+			return fmt.Errorf("line %d: unexpected data after the trailer", line)
+		}
+
+		var record backupRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if line == 1 {
+			if record.Kind != backupKindHeader || record.CreatedAt == nil {
+				return errors.New("not a backup: missing header")
+			}
+			if record.Version < 1 || record.Version > backupVersion {
+				return fmt.Errorf("unsupported backup version %d", record.Version)
+			}
+			summary.CreatedAt = *record.CreatedAt
+			h.Write(data)
+			continue
+		}
+
+		switch {
+		case record.Kind == backupKindDevice && record.Device != nil:
+			devices = append(devices, deviceFromBackup(record.Device))
+			summary.Devices++
+		case record.Kind == backupKindEvent && record.Event != nil:
+			events = append(events, DeviceEvent{
+				RecordedAt: record.Event.RecordedAt,
+				CreatedAt:  record.Event.CreatedAt,
+				Payload:    record.Event.Payload,
+				DeviceID:   record.Event.DeviceID,
+				Source:     record.Event.Source,
+				MessageID:  record.Event.MessageID,
+			})
+			summary.Events++
+		case record.Kind == backupKindLocation && record.Location != nil:
+			locations = append(locations, DeviceLocationHistory{
+				RecordedAt: record.Location.RecordedAt,
+				CreatedAt:  record.Location.CreatedAt,
+				DeviceID:   record.Location.DeviceID,
+				IPAddress:  record.Location.IPAddress,
+				Latitude:   record.Location.Latitude,
+				Longitude:  record.Location.Longitude,
+			})
+			summary.Locations++
+		case record.Kind == backupKindNote && record.Note != nil:
+			notes = append(notes, DeviceNote{
+				CreatedAt:     record.Note.CreatedAt,
+				UpdatedAt:     record.Note.UpdatedAt,
+				DeviceID:      record.Note.DeviceID,
+				Author:        record.Note.Author,
+				Body:          record.Note.Body,
+				AttachmentURL: record.Note.AttachmentURL,
+			})
+			summary.Notes++
+		case record.Kind == backupKindReading && record.Reading != nil:
+			readings = append(readings, SensorReading{
+				Timestamp:    record.Reading.Timestamp,
+				DeviceID:     record.Reading.DeviceID,
+				Temperature:  record.Reading.Temperature,
+				Humidity:     record.Reading.Humidity,
+				Pressure:     record.Reading.Pressure,
+				BatteryLevel: record.Reading.BatteryLevel,
+			})
+			summary.Readings++
+		case record.Kind == backupKindTrailer:
+			trailer = &record
+			continue
+		default:
+			return fmt.Errorf("line %d: unexpected %q record", line, record.Kind)
+		}
+		h.Write(data)
+
+		if len(devices)+len(events)+len(locations)+len(notes)+len(readings) >= backupBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if trailer == nil {
+		return errors.New("backup is truncated: missing trailer")
+	}
+	summary.SHA256 = hex.EncodeToString(h.Sum(nil))
+	if summary.SHA256 != trailer.SHA256 {
+		return fmt.Errorf("checksum mismatch: backup records hash to %s, trailer has %s", summary.SHA256, trailer.SHA256)
+	}
+	if summary.Devices != trailer.Devices || summary.Events != trailer.Events || summary.Locations != trailer.Locations ||
+		summary.Notes != trailer.Notes || summary.Readings != trailer.Readings {
+		return fmt.Errorf("row count mismatch: read %d devices, %d device events, %d locations, %d notes and %d readings, trailer has %d, %d, %d, %d and %d",
+			summary.Devices, summary.Events, summary.Locations, summary.Notes, summary.Readings,
+			trailer.Devices, trailer.Events, trailer.Locations, trailer.Notes, trailer.Readings)
+	}
+
+	return flush()
+}
+
+// deviceToBackup converts a device model to its backup record.
+func deviceToBackup(d *IoTDevice) *backupDevice {
+	device := &backupDevice{
+		LastSeen:   d.LastSeen.UTC(),
+		CreatedAt:  d.CreatedAt.UTC(),
+		UpdatedAt:  d.UpdatedAt.UTC(),
+		DeviceID:   d.DeviceID,
+		Location:   d.Location,
+		MACAddress: d.MACAddress,
+		IPAddress:  d.IPAddress,
+		Firmware:   d.Firmware,
+		Latitude:   d.Latitude,
+		Longitude:  d.Longitude,
+	}
+	if d.DeletedAt.Valid {
+		deletedAt := d.DeletedAt.Time.UTC()
+		device.DeletedAt = &deletedAt
+	}
+
+	return device
+}
+
+// deviceFromBackup converts a backup record to a device model. IDs are
+// assigned by the database; rows reference devices by device ID.
+func deviceFromBackup(d *backupDevice) IoTDevice {
+	device := IoTDevice{
+		LastSeen:   d.LastSeen,
+		CreatedAt:  d.CreatedAt,
+		UpdatedAt:  d.UpdatedAt,
+		DeviceID:   d.DeviceID,
+		Location:   d.Location,
+		MACAddress: d.MACAddress,
+		IPAddress:  d.IPAddress,
+		Firmware:   d.Firmware,
+		Latitude:   d.Latitude,
+		Longitude:  d.Longitude,
+	}
+	if d.DeletedAt != nil {
+		device.DeletedAt = gorm.DeletedAt{Time: *d.DeletedAt, Valid: true}
+	}
+
+	return device
+}
+
+// Backup opens the configured database and writes a backup of it to w.
+func (s *Server) Backup(ctx context.Context, w io.Writer) (*BackupSummary, error) {
+	db, err := NewDBContext(ctx, s.dbConfig())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = CloseDB(db, s.logger) }()
+
+	return WriteBackup(ctx, db, w)
+}
+
+// Restore opens the configured database and restores the backup read from r.
+func (s *Server) Restore(ctx context.Context, r io.Reader, opts RestoreOptions) (*BackupSummary, error) {
+	db, err := NewDBContext(ctx, s.dbConfig())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = CloseDB(db, s.logger) }()
+
+	return RestoreBackup(ctx, db, r, opts)
+}
@@ -0,0 +1,215 @@
+package backend
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Backup", func() {
+	var (
+		ctx    context.Context
+		logger *slog.Logger
+		source *gorm.DB
+		target *gorm.DB
+		now    time.Time
+	)
+
+	backup := func() []byte {
+		var buf bytes.Buffer
+		_, err := WriteBackup(ctx, source, &buf)
+		Expect(err).NotTo(HaveOccurred())
+		return buf.Bytes()
+	}
+
+	// rewrite decompresses a backup, applies fn to its lines and compresses it again.
+	rewrite := func(data []byte, fn func(lines []string) []string) []byte {
+		lines := strings.Split(strings.TrimSuffix(gunzip(data), "\n"), "\n")
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write([]byte(strings.Join(fn(lines), "\n") + "\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(zw.Close()).To(Succeed())
+		return buf.Bytes()
+	}
+
+	countRows := func(db *gorm.DB) (int64, int64) {
+		var devices, readings int64
+		Expect(db.Model(&IoTDevice{}).Unscoped().Count(&devices).Error).To(Succeed())
+		Expect(db.Model(&SensorReading{}).Count(&readings).Error).To(Succeed())
+		return devices, readings
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError,
+		}))
+		source = newTestDB(logger)
+		target = newTestDB(logger)
+
+		now = time.Now().UTC().Truncate(time.Second)
+		Expect(source.Create(&[]IoTDevice{
+			{DeviceID: "sensor-1", Location: "Hall A", Firmware: "1.2.0", Latitude: 52.5, LastSeen: now},
+			{DeviceID: "sensor-2", Location: "Roof", LastSeen: now},
+		}).Error).To(Succeed())
+		Expect(source.Create(&[]SensorReading{
+			{DeviceID: "sensor-1", Timestamp: now.Add(-time.Hour), Temperature: 21.5, BatteryLevel: 90},
+			{DeviceID: "sensor-2", Timestamp: now.Add(-time.Minute), Temperature: 19},
+		}).Error).To(Succeed())
+		Expect(source.Where("device_id = ?", "sensor-2").Delete(&IoTDevice{}).Error).To(Succeed())
+		Expect(source.Create(&[]DeviceEvent{
+			{DeviceID: "sensor-1", RecordedAt: now.Add(-2 * time.Hour), Payload: []byte{1}, Source: "queue", MessageID: "m-1"},
+			{DeviceID: "sensor-1", RecordedAt: now.Add(-time.Hour), Payload: []byte{2}, Source: "api"},
+		}).Error).To(Succeed())
+		Expect(source.Create(&DeviceLocationHistory{DeviceID: "sensor-1", RecordedAt: now, IPAddress: "10.0.0.1", Latitude: 52.5}).Error).To(Succeed())
+		Expect(source.Create(&DeviceNote{DeviceID: "sensor-1", Author: "ops", Body: "Replaced the battery"}).Error).To(Succeed())
+	})
+
+	It("should restore devices, deleted devices and readings", func() {
+		written, err := WriteBackup(ctx, source, &bytes.Buffer{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(written.Devices).To(Equal(int64(2)))
+		Expect(written.Readings).To(Equal(int64(2)))
+
+		restored, err := RestoreBackup(ctx, target, bytes.NewReader(backup()), RestoreOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restored.Devices).To(Equal(int64(2)))
+		Expect(restored.Readings).To(Equal(int64(2)))
+		Expect(restored.SHA256).To(HaveLen(64))
+
+		var device IoTDevice
+		Expect(target.Where("device_id = ?", "sensor-1").First(&device).Error).To(Succeed())
+		Expect(device.Location).To(Equal("Hall A"))
+		Expect(device.Firmware).To(Equal("1.2.0"))
+		Expect(device.LastSeen).To(BeTemporally("==", now))
+
+		var deleted IoTDevice
+		Expect(target.Unscoped().Where("device_id = ?", "sensor-2").First(&deleted).Error).To(Succeed())
+		Expect(deleted.DeletedAt.Valid).To(BeTrue())
+
+		var reading SensorReading
+		Expect(target.Where("device_id = ?", "sensor-1").First(&reading).Error).To(Succeed())
+		Expect(reading.Temperature).To(Equal(21.5))
+		Expect(reading.Timestamp).To(BeTemporally("==", now.Add(-time.Hour)))
+	})
+
+	It("should restore device events, location history and notes", func() {
+		restored, err := RestoreBackup(ctx, target, bytes.NewReader(backup()), RestoreOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restored.Events).To(Equal(int64(2)))
+		Expect(restored.Locations).To(Equal(int64(1)))
+		Expect(restored.Notes).To(Equal(int64(1)))
+
+		// Events keep their order, so they replay the devices as before
+		var events []DeviceEvent
+		Expect(target.Order("id").Find(&events).Error).To(Succeed())
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Payload).To(Equal([]byte{1}))
+		Expect(events[0].MessageID).To(Equal("m-1"))
+		Expect(events[1].Source).To(Equal("api"))
+
+		var location DeviceLocationHistory
+		Expect(target.First(&location).Error).To(Succeed())
+		Expect(location.IPAddress).To(Equal("10.0.0.1"))
+		Expect(location.RecordedAt).To(BeTemporally("==", now))
+
+		var note DeviceNote
+		Expect(target.First(&note).Error).To(Succeed())
+		Expect(note.Body).To(Equal("Replaced the battery"))
+	})
+
+	It("should refuse to restore into a database with devices", func() {
+		data := backup()
+
+		_, err := RestoreBackup(ctx, source, bytes.NewReader(data), RestoreOptions{})
+		Expect(err).To(MatchError(ContainSubstring("already has 2 devices")))
+	})
+
+	It("should replace existing data when asked to", func() {
+		Expect(target.Create(&IoTDevice{DeviceID: "stale"}).Error).To(Succeed())
+
+		_, err := RestoreBackup(ctx, target, bytes.NewReader(backup()), RestoreOptions{Replace: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		devices, readings := countRows(target)
+		Expect(devices).To(Equal(int64(2)))
+		Expect(readings).To(Equal(int64(2)))
+		Expect(target.Unscoped().Where("device_id = ?", "stale").First(&IoTDevice{}).Error).To(MatchError(gorm.ErrRecordNotFound))
+	})
+
+	It("should replace everything recorded about devices, and nothing else", func() {
+		Expect(target.Create(&IoTDevice{DeviceID: "stale"}).Error).To(Succeed())
+		Expect(target.Create(&DeviceEvent{DeviceID: "stale", RecordedAt: now, Payload: []byte{9}, Source: "api"}).Error).To(Succeed())
+		Expect(target.Create(&DeviceLocationHistory{DeviceID: "stale", RecordedAt: now}).Error).To(Succeed())
+		Expect(target.Create(&DeviceNote{DeviceID: "stale", Author: "ops", Body: "Old"}).Error).To(Succeed())
+		Expect(target.Create(&BatteryProjection{DeviceID: "stale", ComputedAt: now}).Error).To(Succeed())
+		Expect(target.Create(&QuarantinedReading{DeviceID: "stale", Timestamp: now, Reason: "future"}).Error).To(Succeed())
+		Expect(target.Create(&ProcessedMessage{Queue: "sensor-data", MessageID: "m-1", ProcessedAt: now}).Error).To(Succeed())
+		Expect(target.Create(&AlertRule{Name: "Hot", Metric: "temperature", Operator: ">", Threshold: 30}).Error).To(Succeed())
+		Expect(target.Create(&ReportSchedule{Name: "Weekly", Frequency: "weekly", Format: "csv", NextRunAt: now}).Error).To(Succeed())
+		Expect(target.Create(&ComponentUptime{Component: "database", Day: now, LastCheckedAt: now}).Error).To(Succeed())
+
+		_, err := RestoreBackup(ctx, target, bytes.NewReader(backup()), RestoreOptions{Replace: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		count := func(model any) int64 {
+			var n int64
+			Expect(target.Model(model).Count(&n).Error).To(Succeed())
+			return n
+		}
+		Expect(count(&DeviceEvent{})).To(Equal(int64(2)))
+		Expect(count(&DeviceLocationHistory{})).To(Equal(int64(1)))
+		Expect(count(&DeviceNote{})).To(Equal(int64(1)))
+		Expect(target.Where("device_id = ?", "stale").First(&DeviceEvent{}).Error).To(MatchError(gorm.ErrRecordNotFound))
+		Expect(count(&BatteryProjection{})).To(BeZero())
+		Expect(count(&QuarantinedReading{})).To(BeZero())
+		Expect(count(&ProcessedMessage{})).To(BeZero())
+
+		Expect(count(&AlertRule{})).To(Equal(int64(1)))
+		Expect(count(&ReportSchedule{})).To(Equal(int64(1)))
+		Expect(count(&ComponentUptime{})).To(Equal(int64(1)))
+	})
+
+	It("should leave the database unchanged when a record was modified", func() {
+		data := rewrite(backup(), func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], "Hall A", "Hall B", 1)
+			return lines
+		})
+
+		_, err := RestoreBackup(ctx, target, bytes.NewReader(data), RestoreOptions{})
+		Expect(err).To(MatchError(ContainSubstring("checksum mismatch")))
+
+		devices, readings := countRows(target)
+		Expect(devices).To(BeZero())
+		Expect(readings).To(BeZero())
+	})
+
+	It("should reject truncated backups", func() {
+		data := rewrite(backup(), func(lines []string) []string {
+			return lines[:len(lines)-1]
+		})
+
+		_, err := RestoreBackup(ctx, target, bytes.NewReader(data), RestoreOptions{})
+		Expect(err).To(MatchError(ContainSubstring("missing trailer")))
+	})
+
+	It("should reject files that are not backups", func() {
+		_, err := RestoreBackup(ctx, target, strings.NewReader("device_id,location\n"), RestoreOptions{})
+		Expect(err).To(MatchError(ContainSubstring("not a backup")))
+
+		data := rewrite(backup(), func(lines []string) []string {
+			return lines[1:]
+		})
+		_, err = RestoreBackup(ctx, target, bytes.NewReader(data), RestoreOptions{})
+		Expect(err).To(MatchError(ContainSubstring("missing header")))
+	})
+})
@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Sensor reading batches", func() {
+	var (
+		db *gorm.DB
+		c  *Consumer
+	)
+
+	BeforeEach(func() {
+		logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError,
+		}))
+
+		db = newTestDB(logger)
+
+		Expect(db.Create(&IoTDevice{DeviceID: "sensor-1", Location: "Lab", LastSeen: time.Now()}).Error).To(Succeed())
+		Expect(db.Create(&IoTDevice{DeviceID: "sensor-2", Location: "Office", LastSeen: time.Now()}).Error).To(Succeed())
+
+		c = &Consumer{
+			logger:    logger,
+			db:        db,
+			metrics:   consumerTestMetrics,
+			queueName: "sensor-batch-test",
+		}
+	})
+
+	// deliver hands readings to the consumer as a batch message with messageID.
+	deliver := func(messageID string, readings ...*iotv1.SensorReading) *fakeAcknowledger {
+		body, err := proto.Marshal(&iotv1.SensorReadingBatch{Readings: readings})
+		Expect(err).NotTo(HaveOccurred())
+
+		ack := &fakeAcknowledger{}
+		c.handleDelivery(context.Background(), amqp.Delivery{
+			Acknowledger: ack,
+			MessageId:    messageID,
+			Type:         iotv1.MessageTypeSensorReadingBatch,
+			Body:         body,
+		})
+		return ack
+	}
+
+	storedDevices := func() []string {
+		var ids []string
+		Expect(db.Model(&SensorReading{}).Order("device_id").Pluck("device_id", &ids).Error).To(Succeed())
+		return ids
+	}
+
+	It("should save all readings of a batch", func() {
+		ack := deliver("batch-1",
+			&iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: 1700000000, Temperature: 21},
+			&iotv1.SensorReading{DeviceId: "sensor-2", Timestamp: 1700000000, Temperature: 19},
+			&iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: 1700000060, Temperature: 22},
+		)
+
+		Expect(ack.acks).To(Equal(1))
+		Expect(storedDevices()).To(Equal([]string{"sensor-1", "sensor-1", "sensor-2"}))
+	})
+
+	It("should skip readings of unknown devices", func() {
+		ack := deliver("batch-2",
+			&iotv1.SensorReading{DeviceId: "unknown", Timestamp: 1700000000},
+			&iotv1.SensorReading{DeviceId: "sensor-2", Timestamp: 1700000000},
+		)
+
+		Expect(ack.acks).To(Equal(1))
+		Expect(storedDevices()).To(Equal([]string{"sensor-2"}))
+	})
+
+	It("should save a redelivered batch once", func() {
+		reading := &iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: 1700000000}
+
+		Expect(deliver("batch-3", reading).acks).To(Equal(1))
+		Expect(deliver("batch-3", reading).acks).To(Equal(1))
+
+		Expect(storedDevices()).To(HaveLen(1))
+		Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerMessagesTotal.WithLabelValues("sensor-batch-test", "duplicate"))).To(Equal(1.0))
+	})
+})
@@ -0,0 +1,318 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+const (
+	// defaultBatteryWindow is the history used for the drain fit when none is configured.
+	defaultBatteryWindow = 7 * 24 * time.Hour
+	// defaultBatteryInterval is how often projections are recomputed when not configured.
+	defaultBatteryInterval = 15 * time.Minute
+	// minBatterySamples is the number of readings needed for a meaningful fit.
+	minBatterySamples = 10
+	// maxProjectionDays caps projections; slower drains are reported without an empty date.
+	maxProjectionDays = 3650
+)
+
+// batteryStatsQuery aggregates the least-squares sums of battery level over time per
+// active device. Times are seconds since the window start to keep the sums well
+// conditioned in float64.
+const batteryStatsQuery = `
+SELECT device_id,
+       COUNT(*) AS n,
+       SUM(x) AS sum_x,
+       SUM(battery_level) AS sum_y,
+       SUM(x * battery_level) AS sum_xy,
+       SUM(x * x) AS sum_xx,
+       MAX(x) AS last_x
+FROM (
+    SELECT r.device_id, r.battery_level, EXTRACT(EPOCH FROM r.timestamp) - ? AS x
+    FROM sensor_readings r
+    JOIN iot_devices d ON d.device_id = r.device_id AND d.deleted_at IS NULL
+    WHERE r.timestamp >= ?
+) w
+GROUP BY device_id`
+
+// batteryStats holds the least-squares sums of one device's readings.
+type batteryStats struct {
+	DeviceID string
+	N        int64
+	SumX     float64
+	SumY     float64
+	SumXY    float64
+	SumXX    float64
+	LastX    float64
+}
+
+// BatteryProjector periodically fits a linear drain rate to each device's battery
+// readings and stores the projected time the battery will be empty.
+type BatteryProjector struct {
+	logger   *slog.Logger
+	db       *gorm.DB
+	done     chan struct{}
+	cancel   context.CancelFunc
+	now      func() time.Time
+	window   time.Duration
+	interval time.Duration
+}
+
+// BatteryProjectorConfig holds the configuration for the BatteryProjector.
+type BatteryProjectorConfig struct {
+	Logger   *slog.Logger
+	DB       *gorm.DB
+	Window   time.Duration // History used for the fit (optional, default 7 days)
+	Interval time.Duration // Time between recomputations (optional, default 15 minutes)
+}
+
+// NewBatteryProjector creates a new BatteryProjector instance.
+func NewBatteryProjector(cfg *BatteryProjectorConfig) (*BatteryProjector, error) {
+	if cfg == nil {
+		return nil, errors.New("battery projector config cannot be nil")
+	}
+
+	if cfg.Logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+
+	if cfg.DB == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+
+	if cfg.Window < 0 || cfg.Interval < 0 {
+		return nil, errors.New("battery window and interval cannot be negative")
+	}
+
+	window := cfg.Window
+	if window == 0 {
+		window = defaultBatteryWindow
+	}
+
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = defaultBatteryInterval
+	}
+
+	return &BatteryProjector{
+		logger:   cfg.Logger,
+		db:       cfg.DB,
+		done:     make(chan struct{}),
+		now:      time.Now,
+		window:   window,
+		interval: interval,
+	}, nil
+}
+
+// Start computes projections immediately and then on every interval until Stop
+// is called or ctx is canceled.
+func (p *BatteryProjector) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	p.logger.Info("starting battery projector", "window", p.window, "interval", p.interval)
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := p.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				p.logger.Error("failed to compute battery projections", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the projector and waits for a running computation to finish.
+func (p *BatteryProjector) Stop() {
+	if p.cancel == nil {
+		return
+	}
+
+	p.cancel()
+	<-p.done
+
+	p.logger.Info("battery projector stopped")
+}
+
+// RunOnce recomputes the projections of all active devices.
+func (p *BatteryProjector) RunOnce(ctx context.Context) error {
+	// Whole seconds so computed_at compares exactly after the database round trip
+	now := p.now().UTC().Truncate(time.Second)
+	since := now.Add(-p.window)
+
+	var stats []batteryStats
+	if err := p.db.WithContext(ctx).Raw(batteryStatsQuery, since.Unix(), since).Scan(&stats).Error; err != nil {
+		return fmt.Errorf("failed to aggregate battery readings: %w", err)
+	}
+
+	projections := make([]BatteryProjection, 0, len(stats))
+	for _, st := range stats {
+		if projection, ok := fitBatteryProjection(st, since, now); ok {
+			projections = append(projections, projection)
+		}
+	}
+
+	if len(projections) > 0 {
+		if err := p.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "device_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"projected_empty_at", "computed_at", "drain_per_day", "battery_level", "sample_count",
+			}),
+		}).Create(&projections).Error; err != nil {
+			return fmt.Errorf("failed to store battery projections: %w", err)
+		}
+	}
+
+	// Devices without enough recent readings keep no projection
+	stale := p.db.WithContext(ctx).Where("computed_at < ?", now).Delete(&BatteryProjection{})
+	if stale.Error != nil {
+		return fmt.Errorf("failed to remove stale battery projections: %w", stale.Error)
+	}
+
+	p.logger.Info("computed battery projections", "devices", len(projections), "removed", stale.RowsAffected)
+	return nil
+}
+
+// fitBatteryProjection fits a least-squares line to the battery readings summarized
+// in st. It returns false when there are too few readings or they share one timestamp.
+func fitBatteryProjection(st batteryStats, since, now time.Time) (BatteryProjection, bool) {
+	n := float64(st.N)
+	denom := n*st.SumXX - st.SumX*st.SumX
+	if st.N < minBatterySamples || denom <= 0 {
+		return BatteryProjection{}, false
+	}
+
+	slope := (n*st.SumXY - st.SumX*st.SumY) / denom // percentage points per second
+	intercept := (st.SumY - slope*st.SumX) / n
+
+	level := math.Max(0, math.Min(100, intercept+slope*st.LastX))
+	drainPerDay := -slope * (24 * time.Hour).Seconds()
+
+	projection := BatteryProjection{
+		DeviceID:     st.DeviceID,
+		DrainPerDay:  drainPerDay,
+		BatteryLevel: level,
+		SampleCount:  st.N,
+		ComputedAt:   now,
+	}
+
+	if drainPerDay > 0 && level/drainPerDay <= maxProjectionDays {
+		lastReading := since.Add(time.Duration(st.LastX * float64(time.Second)))
+		emptyAt := lastReading.Add(time.Duration(level / drainPerDay * float64(24*time.Hour))).Truncate(time.Second)
+		projection.ProjectedEmptyAt = &emptyAt
+	}
+
+	return projection, true
+}
+
+const (
+	// defaultLowBatteryDays is used when ListLowBatteryDevices does not set within_days.
+	defaultLowBatteryDays = 14
+	// maxLowBatteryDays bounds within_days of ListLowBatteryDevices.
+	maxLowBatteryDays = 365
+)
+
+// ListLowBatteryDevices returns the active devices whose battery is projected to
+// be empty within the requested number of days, soonest first.
+func (s *IoTServiceImpl) ListLowBatteryDevices(ctx context.Context, req *iotv1.ListLowBatteryDevicesRequest) (resp *iotv1.ListLowBatteryDevicesResponse, err error) {
+	done := s.trackRequest("ListLowBatteryDevices")
+	defer func() { done(err) }()
+
+	days := int(req.GetWithinDays())
+	switch {
+	case days == 0:
+		days = defaultLowBatteryDays
+	case days < 0 || days > maxLowBatteryDays:
+		return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "within_days", fmt.Sprintf("must be between 1 and %d", maxLowBatteryDays))
+	}
+
+	cutoff := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+
+	var projections []BatteryProjection
+	if err := s.db.WithContext(ctx).
+		Joins("JOIN iot_devices ON iot_devices.device_id = battery_projections.device_id AND iot_devices.deleted_at IS NULL").
+		Where("battery_projections.projected_empty_at <= ?", cutoff).
+		Order("battery_projections.projected_empty_at").
+		Find(&projections).Error; err != nil {
+		s.logger.Error("failed to fetch battery projections", "error", err)
+		return nil, databaseError("failed to fetch battery projections")
+	}
+
+	deviceIDs := make([]string, len(projections))
+	for i := range projections {
+		deviceIDs[i] = projections[i].DeviceID
+	}
+
+	var devices []IoTDevice
+	if len(deviceIDs) > 0 {
+		if err := s.db.WithContext(ctx).Where("device_id IN ?", deviceIDs).Find(&devices).Error; err != nil {
+			s.logger.Error("failed to fetch devices", "error", err)
+			return nil, databaseError("failed to fetch devices")
+		}
+	}
+
+	byID := make(map[string]*IoTDevice, len(devices))
+	for i := range devices {
+		byID[devices[i].DeviceID] = &devices[i]
+	}
+
+	resp = &iotv1.ListLowBatteryDevicesResponse{}
+	for i := range projections {
+		device, ok := byID[projections[i].DeviceID]
+		if !ok {
+			continue
+		}
+		resp.Devices = append(resp.Devices, &iotv1.LowBatteryDevice{
+			Device:            deviceToProto(device),
+			BatteryProjection: batteryProjectionToProto(&projections[i]),
+		})
+	}
+
+	return resp, nil
+}
+
+// findBatteryProjection returns the stored projection of a device, or nil when
+// there is none. Lookup failures are logged and treated as missing since the
+// projection is supplementary.
+func (s *IoTServiceImpl) findBatteryProjection(ctx context.Context, deviceID string) *iotv1.BatteryProjection {
+	var projections []BatteryProjection
+	if err := s.db.WithContext(ctx).Where("device_id = ?", deviceID).Limit(1).Find(&projections).Error; err != nil {
+		s.logger.Warn("failed to fetch battery projection", "device_id", deviceID, "error", err)
+		return nil
+	}
+
+	if len(projections) == 0 {
+		return nil
+	}
+
+	return batteryProjectionToProto(&projections[0])
+}
+
+// batteryProjectionToProto converts a battery projection model to its proto message.
+func batteryProjectionToProto(p *BatteryProjection) *iotv1.BatteryProjection {
+	return &iotv1.BatteryProjection{
+		DrainPerDay:      p.DrainPerDay,
+		BatteryLevel:     p.BatteryLevel,
+		ProjectedEmptyAt: timeToUnix(p.ProjectedEmptyAt),
+		ComputedAt:       p.ComputedAt.Unix(),
+		SampleCount:      p.SampleCount,
+	}
+}
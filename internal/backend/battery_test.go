@@ -0,0 +1,99 @@
+package backend
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// statsFor builds least-squares sums for readings taken every step starting at
+// offset seconds after the window start.
+func statsFor(deviceID string, offset, step float64, levels ...float64) batteryStats {
+	st := batteryStats{DeviceID: deviceID, N: int64(len(levels))}
+	for i, y := range levels {
+		x := offset + float64(i)*step
+		st.SumX += x
+		st.SumY += y
+		st.SumXY += x * y
+		st.SumXX += x * x
+		st.LastX = x
+	}
+	return st
+}
+
+var _ = Describe("Battery projection", func() {
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	now := since.Add(7 * 24 * time.Hour)
+	day := (24 * time.Hour).Seconds()
+
+	Describe("fitBatteryProjection", func() {
+		It("should project when a steadily draining battery is empty", func() {
+			// 2 points per day from 80% over ten days
+			levels := make([]float64, 10)
+			for i := range levels {
+				levels[i] = 80 - 2*float64(i)
+			}
+
+			projection, ok := fitBatteryProjection(statsFor("sensor-1", 0, day, levels...), since, now)
+
+			Expect(ok).To(BeTrue())
+			Expect(projection.DeviceID).To(Equal("sensor-1"))
+			Expect(projection.DrainPerDay).To(BeNumerically("~", 2, 1e-9))
+			Expect(projection.BatteryLevel).To(BeNumerically("~", 62, 1e-9))
+			Expect(projection.SampleCount).To(Equal(int64(10)))
+			Expect(projection.ComputedAt).To(Equal(now))
+
+			// Last reading on day 9 at 62%, empty 31 days later
+			Expect(projection.ProjectedEmptyAt).NotTo(BeNil())
+			Expect(*projection.ProjectedEmptyAt).To(Equal(since.Add(40 * 24 * time.Hour)))
+		})
+
+		It("should not project an empty date for charging or flat batteries", func() {
+			charging := statsFor("sensor-1", 0, day, 50, 51, 52, 53, 54, 55, 56, 57, 58, 59)
+			projection, ok := fitBatteryProjection(charging, since, now)
+			Expect(ok).To(BeTrue())
+			Expect(projection.DrainPerDay).To(BeNumerically("<", 0))
+			Expect(projection.ProjectedEmptyAt).To(BeNil())
+
+			flat := statsFor("sensor-1", 0, day, 70, 70, 70, 70, 70, 70, 70, 70, 70, 70)
+			projection, ok = fitBatteryProjection(flat, since, now)
+			Expect(ok).To(BeTrue())
+			Expect(projection.ProjectedEmptyAt).To(BeNil())
+		})
+
+		It("should not project drains slower than the projection cap", func() {
+			levels := make([]float64, 10)
+			for i := range levels {
+				levels[i] = 90 - 0.001*float64(i)
+			}
+
+			projection, ok := fitBatteryProjection(statsFor("sensor-1", 0, day, levels...), since, now)
+			Expect(ok).To(BeTrue())
+			Expect(projection.DrainPerDay).To(BeNumerically(">", 0))
+			Expect(projection.ProjectedEmptyAt).To(BeNil())
+		})
+
+		It("should skip devices with too few or simultaneous readings", func() {
+			_, ok := fitBatteryProjection(statsFor("sensor-1", 0, day, 80, 79, 78), since, now)
+			Expect(ok).To(BeFalse())
+
+			_, ok = fitBatteryProjection(statsFor("sensor-1", 3600, 0, 80, 80, 80, 80, 80, 80, 80, 80, 80, 80), since, now)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("NewBatteryProjector", func() {
+		logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+		It("should require a logger and database", func() {
+			_, err := NewBatteryProjector(nil)
+			Expect(err).To(HaveOccurred())
+
+			_, err = NewBatteryProjector(&BatteryProjectorConfig{Logger: logger})
+			Expect(err).To(MatchError(ContainSubstring("database")))
+		})
+	})
+})
@@ -0,0 +1,131 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultInsertBatchSize is the number of rows per INSERT statement of the
+// GORM reading writer.
+const defaultInsertBatchSize = 1000
+
+// readingCopyColumns are the sensor_readings columns written by COPY; id is
+// left to its sequence.
+var readingCopyColumns = []string{
+	"timestamp", "created_at", "updated_at", "device_id",
+	"temperature", "humidity", "pressure", "battery_level",
+}
+
+// ReadingWriter persists sensor readings in bulk, e.g. for historical imports.
+type ReadingWriter interface {
+	// WriteReadings stores all readings and returns how many were written.
+	// Either all readings are written or none.
+	WriteReadings(ctx context.Context, readings []SensorReading) (int64, error)
+}
+
+// NewReadingWriter returns the COPY based writer when useCopy is set and the
+// GORM batch insert writer otherwise.
+func NewReadingWriter(db *gorm.DB, useCopy bool) ReadingWriter {
+	if useCopy {
+		return &copyReadingWriter{db: db, now: time.Now}
+	}
+
+	return &gormReadingWriter{db: db, batchSize: defaultInsertBatchSize}
+}
+
+// gormReadingWriter writes readings with multi-row INSERT statements. Readings
+// already stored for the same device and timestamp are skipped.
+type gormReadingWriter struct {
+	db        *gorm.DB
+	batchSize int
+}
+
+// WriteReadings implements ReadingWriter.
+func (w *gormReadingWriter) WriteReadings(ctx context.Context, readings []SensorReading) (int64, error) {
+	if len(readings) == 0 {
+		return 0, nil
+	}
+
+	result := w.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(readings, w.batchSize)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to insert readings: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// copyReadingWriter streams readings into sensor_readings with COPY FROM on
+// the pgx connection underneath GORM, which avoids per-row statement overhead.
+// IDs are not written back to the readings. COPY cannot skip duplicates, so a
+// reading already stored for the same device and timestamp fails the batch.
+type copyReadingWriter struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+// WriteReadings implements ReadingWriter.
+func (w *copyReadingWriter) WriteReadings(ctx context.Context, readings []SensorReading) (int64, error) {
+	if len(readings) == 0 {
+		return 0, nil
+	}
+
+	sqlDB, err := w.db.DB()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get database instance: %w", err)
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	rows := readingCopyRows(readings, w.now().UTC())
+
+	var copied int64
+	err = conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("COPY requires the pgx driver")
+		}
+
+		copied, err = c.Conn().CopyFrom(ctx, pgx.Identifier{"sensor_readings"}, readingCopyColumns, pgx.CopyFromRows(rows))
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy readings: %w", err)
+	}
+
+	return copied, nil
+}
+
+// readingCopyRows converts readings to COPY rows in readingCopyColumns order.
+// Unset creation and update times are filled with now, as GORM would.
+func readingCopyRows(readings []SensorReading, now time.Time) [][]any {
+	rows := make([][]any, len(readings))
+	for i := range readings {
+		r := &readings[i]
+
+		createdAt, updatedAt := r.CreatedAt, r.UpdatedAt
+		if createdAt.IsZero() {
+			createdAt = now
+		}
+		if updatedAt.IsZero() {
+			updatedAt = now
+		}
+
+		rows[i] = []any{
+			r.Timestamp.UTC(), createdAt, updatedAt, r.DeviceID,
+			r.Temperature, r.Humidity, r.Pressure, r.BatteryLevel,
+		}
+	}
+
+	return rows
+}
@@ -0,0 +1,57 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Reading writers", func() {
+	It("should select the writer implementation", func() {
+		db := &gorm.DB{}
+
+		Expect(NewReadingWriter(db, true)).To(BeAssignableToTypeOf(&copyReadingWriter{}))
+		Expect(NewReadingWriter(db, false)).To(BeAssignableToTypeOf(&gormReadingWriter{}))
+	})
+
+	It("should not touch the database for empty batches", func() {
+		for _, useCopy := range []bool{true, false} {
+			n, err := NewReadingWriter(&gorm.DB{}, useCopy).WriteReadings(context.Background(), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(BeZero())
+		}
+	})
+
+	Describe("readingCopyRows", func() {
+		now := time.Date(2026, 4, 15, 12, 0, 0, 0, time.UTC)
+		ts := time.Date(2026, 4, 1, 8, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+		It("should order values like readingCopyColumns", func() {
+			created := now.Add(-time.Hour)
+			rows := readingCopyRows([]SensorReading{{
+				Timestamp:    ts,
+				CreatedAt:    created,
+				UpdatedAt:    created,
+				DeviceID:     "sensor-1",
+				Temperature:  21.5,
+				Humidity:     40,
+				Pressure:     1013.25,
+				BatteryLevel: 87,
+			}}, now)
+
+			Expect(rows).To(HaveLen(1))
+			Expect(rows[0]).To(HaveLen(len(readingCopyColumns)))
+			Expect(rows[0]).To(Equal([]any{ts.UTC(), created, created, "sensor-1", 21.5, 40.0, 1013.25, 87.0}))
+		})
+
+		It("should fill unset timestamps with now", func() {
+			rows := readingCopyRows([]SensorReading{{Timestamp: ts, DeviceID: "sensor-1"}}, now)
+
+			Expect(rows[0][1]).To(Equal(now))
+			Expect(rows[0][2]).To(Equal(now))
+		})
+	})
+})
@@ -5,26 +5,48 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"google.golang.org/protobuf/proto"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
 	"procodus.dev/demo-app/pkg/metrics"
 	"procodus.dev/demo-app/pkg/mq"
 )
 
+// mqReadyTimeout is how long consumers wait for the MQ client to connect on start.
+const mqReadyTimeout = 30 * time.Second
+
 // Consumer consumes messages from RabbitMQ and persists them to PostgreSQL.
 type Consumer struct {
-	logger   *slog.Logger
-	db       *gorm.DB
-	mqClient mq.ClientInterface
-	done     chan struct{}
-	metrics  *metrics.BackendMetrics // Optional metrics
+	logger     *slog.Logger
+	db         *gorm.DB
+	mqClient   mq.ClientInterface
+	ownsMQ     bool               // Whether Stop closes mqClient
+	cancel     context.CancelFunc // Stops message processing
+	done       chan struct{}
+	metrics    *metrics.BackendMetrics // Optional metrics
+	mqMetrics  *metrics.MQMetrics      // Optional MQ metrics
+	queueName  string
+	timestamps TimestampConfig
+	sinks      []*ReadingSink // Optional, get the saved readings
+	buffers    sync.Pool      // *readingBuffers reused across deliveries
+}
+
+// readingBuffers hold the decoded message and database model of one delivery.
+// They are reused so a steady stream of readings does not allocate them per
+// message.
+type readingBuffers struct {
+	message iotv1.SensorReading
+	batch   iotv1.SensorReadingBatch
+	model   SensorReading
 }
 
 // ConsumerConfig holds the configuration for the Consumer.
@@ -35,6 +57,9 @@ type ConsumerConfig struct {
 	QueueName   string
 	Metrics     *metrics.BackendMetrics // Optional metrics
 	MQMetrics   *metrics.MQMetrics      // Optional MQ metrics
+	MQClient    mq.ClientInterface      // Optional, shared RabbitMQ client or a mock in tests; not closed by Stop
+	Timestamps  TimestampConfig         // Optional, default accepts all timestamps
+	Sinks       []*ReadingSink          // Optional, get the saved readings, e.g. for InfluxDB; not stopped by Stop
 }
 
 // NewConsumer creates a new Consumer instance.
@@ -51,7 +76,7 @@ func NewConsumer(cfg *ConsumerConfig) (*Consumer, error) {
 		return nil, errors.New("database cannot be nil")
 	}
 
-	if cfg.RabbitMQURL == "" {
+	if cfg.RabbitMQURL == "" && cfg.MQClient == nil {
 		return nil, errors.New("rabbitmq URL cannot be empty")
 	}
 
@@ -59,20 +84,37 @@ func NewConsumer(cfg *ConsumerConfig) (*Consumer, error) {
 		return nil, errors.New("queue name cannot be empty")
 	}
 
-	// Create MQ client
-	mqClient := mq.New(cfg.QueueName, cfg.RabbitMQURL, cfg.Logger)
+	if err := cfg.Timestamps.validate(); err != nil {
+		return nil, err
+	}
+
+	// Create MQ client unless one is provided
+	mqClient := cfg.MQClient
+	if mqClient == nil {
+		client, err := mq.NewWithContext(context.Background(), cfg.QueueName, cfg.RabbitMQURL, cfg.Logger)
+		if err != nil {
+			return nil, err
+		}
+
+		// Enable MQ metrics if configured
+		if cfg.MQMetrics != nil {
+			client.SetMetrics(cfg.MQMetrics)
+		}
 
-	// Enable MQ metrics if configured
-	if cfg.MQMetrics != nil {
-		mqClient.SetMetrics(cfg.MQMetrics)
+		mqClient = client
 	}
 
 	return &Consumer{
-		logger:   cfg.Logger,
-		db:       cfg.DB,
-		mqClient: mqClient,
-		done:     make(chan struct{}),
-		metrics:  cfg.Metrics,
+		logger:     cfg.Logger,
+		db:         cfg.DB,
+		mqClient:   mqClient,
+		ownsMQ:     cfg.MQClient == nil,
+		done:       make(chan struct{}),
+		metrics:    cfg.Metrics,
+		mqMetrics:  cfg.MQMetrics,
+		queueName:  cfg.QueueName,
+		timestamps: cfg.Timestamps,
+		sinks:      cfg.Sinks,
 	}, nil
 }
 
@@ -85,12 +127,8 @@ func (c *Consumer) Start(ctx context.Context) error {
 		c.metrics.ActiveConsumers.Inc()
 	}
 
-	// Wait for MQ client to be ready
-	time.Sleep(2 * time.Second)
-
-	// Start consuming messages
-	deliveries, err := c.mqClient.Consume()
-	if err != nil {
+	// Fail fast when RabbitMQ cannot be reached on start
+	if err := waitReady(ctx, c.mqClient); err != nil {
 		// Decrement on error
 		if c.metrics != nil {
 			c.metrics.ActiveConsumers.Dec()
@@ -100,53 +138,85 @@ func (c *Consumer) Start(ctx context.Context) error {
 
 	c.logger.Info("consumer started, waiting for messages")
 
-	// Process messages in a goroutine
-	go c.processMessages(ctx, deliveries)
+	// Process messages in a goroutine, resubscribing after reconnects
+	ctx, c.cancel = context.WithCancel(ctx)
+	go c.processMessages(ctx)
 
 	return nil
 }
 
-// processMessages processes incoming messages from the deliveries channel.
-func (c *Consumer) processMessages(ctx context.Context, deliveries <-chan amqp.Delivery) {
-	for {
-		select {
-		case <-ctx.Done():
-			c.logger.Info("context canceled, stopping message processing")
-			close(c.done)
-			return
-
-		case delivery, ok := <-deliveries:
-			if !ok {
-				c.logger.Warn("deliveries channel closed")
-				close(c.done)
-				return
-			}
+// waitReady waits up to mqReadyTimeout for the MQ client to connect.
+func waitReady(ctx context.Context, client mq.ClientInterface) error {
+	ctx, cancel := context.WithTimeout(ctx, mqReadyTimeout)
+	defer cancel()
 
-			c.handleDelivery(ctx, delivery)
-		}
+	if err := client.WaitReady(ctx); err != nil {
+		return fmt.Errorf("message queue not ready: %w", err)
 	}
+
+	return nil
+}
+
+// processMessages handles deliveries until the context is canceled or the MQ
+// client is closed.
+func (c *Consumer) processMessages(ctx context.Context) {
+	defer close(c.done)
+
+	if err := c.mqClient.ConsumeQueues(ctx, map[string]mq.Handler{c.queueName: c.handleDelivery}, mq.ConsumeHooks{}); err != nil && ctx.Err() == nil {
+		c.logger.Error("message processing stopped", "error", err)
+		return
+	}
+
+	c.logger.Info("stopping message processing")
 }
 
 // handleDelivery processes a single message delivery.
 func (c *Consumer) handleDelivery(ctx context.Context, delivery amqp.Delivery) {
+	// A message that makes processing panic is rejected instead of crashing the process
+	delivery = trackSettlement(delivery)
+	defer recoverDelivery(c.logger, c.metrics, c.mqMetrics, c.queueName, delivery)
+
 	// Track processing duration
 	var timer *prometheus.Timer
 	if c.metrics != nil {
-		timer = prometheus.NewTimer(c.metrics.ProcessingDuration.WithLabelValues("sensor-data"))
+		timer = prometheus.NewTimer(c.metrics.ProcessingDuration.WithLabelValues(c.queueName))
 		defer timer.ObserveDuration()
 	}
+	if c.mqMetrics != nil {
+		mqTimer := prometheus.NewTimer(c.mqMetrics.ConsumeDuration.WithLabelValues(c.queueName))
+		defer mqTimer.ObserveDuration()
+	}
 
-	// Parse the protobuf message
-	reading := &iot.SensorReading{}
-	if err := proto.Unmarshal(delivery.Body, reading); err != nil {
-		c.logger.Error("failed to unmarshal sensor reading",
+	buffers := c.getBuffers()
+	defer c.buffers.Put(buffers)
+
+	// Parse the protobuf message; Unmarshal resets the reused message first.
+	// Batches are flagged by the message type, units by headers.
+	var reading *iotv1.SensorReading
+	batch := delivery.Type == iotv1.MessageTypeSensorReadingBatch
+	errType := "unmarshal_error"
+	units, err := unitsOf(delivery)
+	switch {
+	case err != nil:
+		errType = "unit_error"
+	case batch:
+		err = proto.Unmarshal(delivery.Body, &buffers.batch)
+	default:
+		reading = &buffers.message
+		err = proto.Unmarshal(delivery.Body, reading)
+	}
+	if err != nil {
+		c.logger.Error("failed to decode sensor reading",
 			"error", err,
 		)
 
 		// Track failure
 		if c.metrics != nil {
-			c.metrics.ConsumerMessagesTotal.WithLabelValues("sensor-data", "error").Inc()
-			c.metrics.ConsumerErrors.WithLabelValues("sensor-data", "unmarshal_error").Inc()
+			c.metrics.ConsumerMessagesTotal.WithLabelValues(c.queueName, "error").Inc()
+			c.metrics.ConsumerErrors.WithLabelValues(c.queueName, errType).Inc()
+		}
+		if c.mqMetrics != nil {
+			c.mqMetrics.ConsumptionFailures.WithLabelValues(c.queueName, errType).Inc()
 		}
 
 		// Acknowledge message even on parse error to avoid reprocessing
@@ -156,56 +226,132 @@ func (c *Consumer) handleDelivery(ctx context.Context, delivery amqp.Delivery) {
 		return
 	}
 
-	// Log the received reading
-	c.logger.Info("received sensor reading",
-		"device_id", reading.GetDeviceId(),
-		"timestamp", reading.GetTimestamp(),
-		"temperature", reading.GetTemperature(),
-	)
+	// Convert readings to the canonical units before they are checked and saved
+	if batch {
+		for _, r := range buffers.batch.GetReadings() {
+			units.normalize(r)
+		}
+	} else {
+		units.normalize(reading)
+	}
+
+	// Apply the timestamp policy; rejected readings are quarantined
+	now := time.Now()
+	var quarantined []QuarantinedReading
+	if batch {
+		buffers.batch.Readings = slices.DeleteFunc(buffers.batch.Readings, func(r *iotv1.SensorReading) bool {
+			if reason := c.checkTimestamp(r, now); reason != "" {
+				quarantined = append(quarantined, quarantinedReading(delivery.MessageId, r, reason))
+				return true
+			}
+			return false
+		})
+	} else if reason := c.checkTimestamp(reading, now); reason != "" {
+		quarantined = append(quarantined, quarantinedReading(delivery.MessageId, reading, reason))
+	}
 
-	// Save to database
-	if err := c.saveSensorReading(ctx, reading); err != nil {
+	// Log the received reading and save it to the database
+	var duplicate bool
+	switch {
+	case batch:
+		c.logger.Info("received sensor reading batch",
+			"readings", len(buffers.batch.GetReadings()),
+			"quarantined", len(quarantined),
+		)
+		duplicate, err = c.saveSensorReadingBatch(ctx, delivery.MessageId, buffers.batch.GetReadings(), quarantined)
+	case len(quarantined) > 0:
+		duplicate, err = c.saveQuarantined(ctx, delivery.MessageId, quarantined)
+	default:
+		c.logger.Info("received sensor reading",
+			"device_id", reading.GetDeviceId(),
+			"timestamp", reading.GetTimestamp(),
+			"temperature", reading.GetTemperature(),
+		)
+		duplicate, err = c.saveSensorReading(ctx, delivery.MessageId, reading, &buffers.model)
+	}
+	if err != nil {
 		c.logger.Error("failed to save sensor reading",
 			"device_id", reading.GetDeviceId(),
+			"batch", batch,
 			"error", err,
 		)
 
 		// Track failure
 		if c.metrics != nil {
-			c.metrics.ConsumerMessagesTotal.WithLabelValues("sensor-data", "error").Inc()
-			c.metrics.ConsumerErrors.WithLabelValues("sensor-data", "database_error").Inc()
+			c.metrics.ConsumerMessagesTotal.WithLabelValues(c.queueName, "error").Inc()
+			c.metrics.ConsumerErrors.WithLabelValues(c.queueName, "database_error").Inc()
+		}
+		if c.mqMetrics != nil {
+			c.mqMetrics.ConsumptionFailures.WithLabelValues(c.queueName, "database_error").Inc()
 		}
 
 		// Nack the message so it can be reprocessed
 		if nackErr := delivery.Nack(false, true); nackErr != nil {
 			c.logger.Error("failed to nack message", "error", nackErr)
 		}
+
+		// Stop taking messages until the database is back
+		pauseWhileDBDown(ctx, c.db, c.logger, c.metrics, c.queueName)
 		return
 	}
 
 	// Acknowledge successful processing
 	if err := delivery.Ack(false); err != nil {
 		c.logger.Error("failed to ack message", "error", err)
+		if c.mqMetrics != nil {
+			c.mqMetrics.ConsumptionFailures.WithLabelValues(c.queueName, "ack_error").Inc()
+		}
 		return
 	}
 
 	// Track success
+	status := "success"
+	switch {
+	case duplicate:
+		status = "duplicate"
+	case !batch && len(quarantined) > 0:
+		status = "rejected"
+	}
 	if c.metrics != nil {
-		c.metrics.ConsumerMessagesTotal.WithLabelValues("sensor-data", "success").Inc()
+		c.metrics.ConsumerMessagesTotal.WithLabelValues(c.queueName, status).Inc()
+	}
+	if c.mqMetrics != nil {
+		c.mqMetrics.MessagesConsumed.WithLabelValues(c.queueName).Inc()
 	}
 
-	c.logger.Debug("sensor reading saved successfully",
-		"device_id", reading.GetDeviceId(),
-	)
+	if !duplicate {
+		c.logger.Debug("sensor reading saved successfully",
+			"device_id", reading.GetDeviceId(),
+			"batch", batch,
+		)
+	}
+}
+
+// getBuffers returns reusable buffers for a delivery, allocating new ones
+// only when none are available.
+func (c *Consumer) getBuffers() *readingBuffers {
+	if buffers, ok := c.buffers.Get().(*readingBuffers); ok {
+		return buffers
+	}
+
+	// Track allocation
+	if c.metrics != nil {
+		c.metrics.ConsumerBufferAllocations.WithLabelValues(c.queueName).Inc()
+	}
+
+	return &readingBuffers{}
 }
 
-// saveSensorReading saves a sensor reading to the database.
-func (c *Consumer) saveSensorReading(ctx context.Context, reading *iot.SensorReading) error {
+// saveSensorReading saves a sensor reading to the database, using dbReading
+// as the model so it can be reused. It reports whether the reading was
+// skipped as a duplicate: messageID was processed before, or the device
+// already has a reading at that timestamp.
+func (c *Consumer) saveSensorReading(ctx context.Context, messageID string, reading *iotv1.SensorReading, dbReading *SensorReading) (bool, error) {
 	// Convert protobuf timestamp to time.Time
 	timestamp := time.Unix(reading.GetTimestamp(), 0).UTC()
 
-	// Create database model
-	dbReading := &SensorReading{
+	// Fill the database model, clearing what the previous insert set
+	*dbReading = SensorReading{
 		DeviceID:     reading.GetDeviceId(),
 		Timestamp:    timestamp,
 		Temperature:  reading.GetTemperature(),
@@ -214,26 +360,143 @@ func (c *Consumer) saveSensorReading(ctx context.Context, reading *iot.SensorRea
 		BatteryLevel: reading.GetBatteryLevel(),
 	}
 
-	// Save to database
-	if err := c.db.WithContext(ctx).Create(dbReading).Error; err != nil {
+	// The message ID is recorded in the same transaction as the reading, so
+	// a redelivery is skipped exactly when the reading was committed
+	var duplicate bool
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		first, err := markProcessed(tx, c.queueName, messageID)
+		if err != nil {
+			return err
+		}
+		if !first {
+			duplicate = true
+			return nil
+		}
+
+		// Messages without an ID hit the unique device and timestamp index
+		// when redelivered and are skipped
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(dbReading)
+		if result.Error != nil {
+			return fmt.Errorf("failed to create sensor reading: %w", result.Error)
+		}
+
+		duplicate = result.RowsAffected == 0
+		return nil
+	})
+	if err != nil {
 		// Check for foreign key violation (device doesn't exist)
-		// GORM may wrap it as ErrForeignKeyViolated, or it may be a raw PostgreSQL error
+		// GORM may wrap it as ErrForeignKeyViolated, or it may be a raw driver error
 		// PostgreSQL SQLSTATE 23503: foreign_key_violation
 		if errors.Is(err, gorm.ErrForeignKeyViolated) ||
 			strings.Contains(err.Error(), "violates foreign key constraint") ||
-			strings.Contains(err.Error(), "SQLSTATE 23503") {
+			strings.Contains(err.Error(), "SQLSTATE 23503") ||
+			strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
 			// Foreign key violation - device doesn't exist
 			// Acknowledge message anyway since retrying won't help
 			c.logger.Warn("sensor reading for non-existent device, acknowledging message",
 				"device_id", reading.GetDeviceId(),
 				"error", err,
 			)
+			return false, nil
+		}
+		return false, err
+	}
+
+	if duplicate {
+		c.logger.Debug("duplicate sensor reading skipped",
+			"device_id", reading.GetDeviceId(),
+			"timestamp", reading.GetTimestamp(),
+			"message_id", messageID,
+		)
+	} else {
+		for _, sink := range c.sinks {
+			sink.Add(*dbReading)
+		}
+	}
+
+	return duplicate, nil
+}
+
+// saveSensorReadingBatch saves the readings of a batch message and its
+// quarantined readings in one transaction. Readings of unknown devices are
+// skipped since retrying would not help, and so are readings the device
+// already has at that timestamp. It reports whether the batch was skipped
+// because messageID was processed before.
+func (c *Consumer) saveSensorReadingBatch(ctx context.Context, messageID string, readings []*iotv1.SensorReading, quarantined []QuarantinedReading) (bool, error) {
+	deviceIDs := make([]string, 0, len(readings))
+	for _, reading := range readings {
+		deviceIDs = append(deviceIDs, reading.GetDeviceId())
+	}
+
+	var (
+		duplicate  bool
+		dbReadings []SensorReading
+	)
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		first, err := markProcessed(tx, c.queueName, messageID)
+		if err != nil {
+			return err
+		}
+		if !first {
+			duplicate = true
 			return nil
 		}
-		return fmt.Errorf("failed to create sensor reading: %w", err)
+
+		if err := quarantine(tx, quarantined); err != nil {
+			return err
+		}
+
+		// Soft-deleted devices still satisfy the foreign key, as for single readings
+		var known []string
+		if err := tx.Unscoped().Model(&IoTDevice{}).Where("device_id IN ?", deviceIDs).Pluck("device_id", &known).Error; err != nil {
+			return fmt.Errorf("failed to look up devices: %w", err)
+		}
+		exists := make(map[string]bool, len(known))
+		for _, id := range known {
+			exists[id] = true
+		}
+
+		dbReadings = make([]SensorReading, 0, len(readings))
+		for _, reading := range readings {
+			if !exists[reading.GetDeviceId()] {
+				c.logger.Warn("sensor reading for non-existent device in batch, skipping it",
+					"device_id", reading.GetDeviceId(),
+					"message_id", messageID,
+				)
+				continue
+			}
+
+			dbReadings = append(dbReadings, SensorReading{
+				DeviceID:     reading.GetDeviceId(),
+				Timestamp:    time.Unix(reading.GetTimestamp(), 0).UTC(),
+				Temperature:  reading.GetTemperature(),
+				Humidity:     reading.GetHumidity(),
+				Pressure:     reading.GetPressure(),
+				BatteryLevel: reading.GetBatteryLevel(),
+			})
+		}
+
+		_, err = NewReadingWriter(tx, false).WriteReadings(ctx, dbReadings)
+		return err
+	})
+	if err != nil {
+		return false, err
 	}
 
-	return nil
+	if duplicate {
+		c.logger.Debug("duplicate sensor reading batch skipped",
+			"readings", len(readings),
+			"message_id", messageID,
+		)
+	} else {
+		// Readings the database already had are forwarded again, which sinks
+		// treat as writing the same point twice
+		for _, sink := range c.sinks {
+			sink.Add(dbReadings...)
+		}
+	}
+
+	return duplicate, nil
 }
 
 // Stop stops the consumer and closes the MQ client.
@@ -245,9 +508,16 @@ func (c *Consumer) Stop() error {
 		defer c.metrics.ActiveConsumers.Dec()
 	}
 
-	// Close MQ client
-	if err := c.mqClient.Close(); err != nil {
-		return fmt.Errorf("failed to close mq client: %w", err)
+	// Stop message processing
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	// Close the MQ client unless it is shared
+	if c.ownsMQ {
+		if err := c.mqClient.Close(); err != nil {
+			return fmt.Errorf("failed to close mq client: %w", err)
+		}
 	}
 
 	// Wait for message processing to complete
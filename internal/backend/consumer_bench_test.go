@@ -0,0 +1,63 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/protobuf/proto"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// BenchmarkConsumerHandleDelivery measures a sensor reading from delivery to
+// ack: unmarshal, insert into an in-memory SQLite database and ack.
+func BenchmarkConsumerHandleDelivery(b *testing.B) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	db, err := NewDB(&DBConfig{Logger: logger, Driver: DriverSQLite, DBName: ":memory:"})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	deviceID := "bench-device"
+	if err := db.Create(&IoTDevice{DeviceID: deviceID, LastSeen: time.Now()}).Error; err != nil {
+		b.Fatal(err)
+	}
+
+	c := &Consumer{logger: logger, db: db, queueName: "sensor-bench"}
+
+	// Readings need unique timestamps, or they are skipped as duplicates
+	start := time.Now()
+	bodies := make([][]byte, b.N)
+	for i := range bodies {
+		bodies[i], err = proto.Marshal(&iotv1.SensorReading{
+			DeviceId:     deviceID,
+			Timestamp:    start.Add(time.Duration(i) * time.Second).Unix(),
+			Temperature:  22.5,
+			Humidity:     60,
+			Pressure:     1013,
+			BatteryLevel: 90,
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	ack := &fakeAcknowledger{}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		c.handleDelivery(ctx, amqp.Delivery{Acknowledger: ack, Body: bodies[i]})
+	}
+	b.StopTimer()
+
+	if ack.nacks > 0 || ack.acks != b.N {
+		b.Fatalf("expected %d acks and no nacks, got %d acks and %d nacks", b.N, ack.acks, ack.nacks)
+	}
+}
@@ -0,0 +1,116 @@
+package backend
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+// fakeAcknowledger records ack/nack calls made on a delivery.
+type fakeAcknowledger struct {
+	acks     int
+	nacks    int
+	requeued bool // requeue flag of the last nack
+}
+
+func (f *fakeAcknowledger) Ack(_ uint64, _ bool) error {
+	f.acks++
+	return nil
+}
+
+func (f *fakeAcknowledger) Nack(_ uint64, _ bool, requeue bool) error {
+	f.nacks++
+	f.requeued = requeue
+	return nil
+}
+
+func (f *fakeAcknowledger) Reject(_ uint64, _ bool) error {
+	return nil
+}
+
+var (
+	consumerTestMetrics   = metrics.NewBackendMetrics("test_consumer")
+	consumerTestMQMetrics = metrics.NewMQMetrics("test_consumer")
+)
+
+var _ = Describe("Consumer metrics", func() {
+	var logger *slog.Logger
+
+	// malformed is not a valid protobuf encoding (truncated length-delimited field).
+	malformed := []byte{0x0a, 0xff}
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError,
+		}))
+	})
+
+	It("should record sensor unmarshal failures against the configured queue", func() {
+		c := &Consumer{
+			logger:    logger,
+			metrics:   consumerTestMetrics,
+			mqMetrics: consumerTestMQMetrics,
+			queueName: "sensor-metrics-test",
+		}
+		ack := &fakeAcknowledger{}
+
+		c.handleDelivery(context.Background(), amqp.Delivery{Acknowledger: ack, Body: malformed})
+
+		Expect(ack.acks).To(Equal(1))
+		Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerMessagesTotal.WithLabelValues("sensor-metrics-test", "error"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerErrors.WithLabelValues("sensor-metrics-test", "unmarshal_error"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(consumerTestMQMetrics.ConsumptionFailures.WithLabelValues("sensor-metrics-test", "unmarshal_error"))).To(Equal(1.0))
+		Expect(testutil.CollectAndCount(consumerTestMetrics.ProcessingDuration, "test_consumer_consumer_processing_duration_seconds")).To(BeNumerically(">=", 1))
+	})
+
+	It("should reuse buffers across deliveries and count allocations", func() {
+		c := &Consumer{
+			logger:    logger,
+			metrics:   consumerTestMetrics,
+			queueName: "sensor-buffers-test",
+		}
+		ack := &fakeAcknowledger{}
+
+		for range 100 {
+			c.handleDelivery(context.Background(), amqp.Delivery{Acknowledger: ack, Body: malformed})
+		}
+
+		// The pool may drop buffers (always under the race detector), but most are reused
+		allocations := testutil.ToFloat64(consumerTestMetrics.ConsumerBufferAllocations.WithLabelValues("sensor-buffers-test"))
+		Expect(allocations).To(BeNumerically(">=", 1))
+		Expect(allocations).To(BeNumerically("<", 100))
+	})
+
+	It("should record device unmarshal failures against the configured queue", func() {
+		c := &DeviceConsumer{
+			logger:    logger,
+			metrics:   consumerTestMetrics,
+			mqMetrics: consumerTestMQMetrics,
+			queueName: "device-metrics-test",
+		}
+		ack := &fakeAcknowledger{}
+
+		c.handleDelivery(context.Background(), amqp.Delivery{Acknowledger: ack, Body: malformed})
+
+		Expect(ack.acks).To(Equal(1))
+		Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerErrors.WithLabelValues("device-metrics-test", "unmarshal_error"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(consumerTestMQMetrics.ConsumptionFailures.WithLabelValues("device-metrics-test", "unmarshal_error"))).To(Equal(1.0))
+	})
+
+	It("should tolerate nil metrics", func() {
+		c := &Consumer{logger: logger, queueName: "no-metrics"}
+		ack := &fakeAcknowledger{}
+
+		Expect(func() {
+			c.handleDelivery(context.Background(), amqp.Delivery{Acknowledger: ack, Body: malformed})
+		}).NotTo(Panic())
+		Expect(ack.acks).To(Equal(1))
+	})
+})
@@ -1,6 +1,8 @@
 package backend_test
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"os"
 
@@ -9,6 +11,8 @@ import (
 	"gorm.io/gorm"
 
 	"procodus.dev/demo-app/internal/backend"
+	"procodus.dev/demo-app/pkg/mq"
+	"procodus.dev/demo-app/pkg/mq/mock"
 )
 
 var _ = Describe("Consumer", func() {
@@ -28,21 +32,7 @@ var _ = Describe("Consumer", func() {
 	Describe("NewConsumer", func() {
 		Context("with valid configuration", func() {
 			It("should create a consumer", func() {
-				// Create a mock DB for this test
-				dbCfg := &backend.DBConfig{
-					Host:     "localhost",
-					Port:     5432,
-					User:     "test",
-					Password: "password",
-					DBName:   "testdb",
-					SSLMode:  "disable",
-					Logger:   logger,
-				}
-				db, err := backend.NewDB(dbCfg)
-				// We expect this to fail since we don't have a real DB, but we're testing the constructor
-				if err == nil && db != nil {
-					defer backend.CloseDB(db, logger)
-				}
+				db := backend.NewTestDB(logger)
 
 				config := &backend.ConsumerConfig{
 					Logger:      logger,
@@ -53,10 +43,8 @@ var _ = Describe("Consumer", func() {
 
 				// This will create the consumer but not connect to MQ yet
 				consumer, err := backend.NewConsumer(config)
-				if db != nil {
-					Expect(err).NotTo(HaveOccurred())
-					Expect(consumer).NotTo(BeNil())
-				}
+				Expect(err).NotTo(HaveOccurred())
+				Expect(consumer).NotTo(BeNil())
 			})
 		})
 
@@ -68,6 +56,19 @@ var _ = Describe("Consumer", func() {
 				Expect(consumer).To(BeNil())
 			})
 
+			It("should return error for a malformed RabbitMQ URL", func() {
+				db := backend.NewTestDB(logger)
+
+				consumer, err := backend.NewConsumer(&backend.ConsumerConfig{
+					Logger:      logger,
+					DB:          db,
+					RabbitMQURL: "localhost:5672",
+					QueueName:   "test-queue",
+				})
+				Expect(err).To(MatchError(ContainSubstring("invalid AMQP URL")))
+				Expect(consumer).To(BeNil())
+			})
+
 			It("should return error when logger is nil", func() {
 				config := &backend.ConsumerConfig{
 					Logger:      nil,
@@ -113,4 +114,93 @@ var _ = Describe("Consumer", func() {
 			})
 		})
 	})
+
+	Describe("Start", func() {
+		var (
+			db     *gorm.DB
+			client *mock.MockClient
+		)
+
+		BeforeEach(func() {
+			db = backend.NewTestDB(logger)
+
+			client = mock.NewMockClient()
+		})
+
+		newConsumer := func() *backend.Consumer {
+			consumer, err := backend.NewConsumer(&backend.ConsumerConfig{
+				Logger:    logger,
+				DB:        db,
+				QueueName: "test-queue",
+				MQClient:  client,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			return consumer
+		}
+
+		It("should wait for the MQ client before consuming", func() {
+			ready := make(chan struct{})
+			client.WaitReadyFunc = func(ctx context.Context) error {
+				select {
+				case <-ready:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			consuming := make(chan struct{})
+			client.ConsumeQueuesFunc = func(ctx context.Context, _ map[string]mq.Handler, _ mq.ConsumeHooks) error {
+				close(consuming)
+				<-ctx.Done()
+				return ctx.Err()
+			}
+
+			started := make(chan error, 1)
+			go func() { started <- newConsumer().Start(context.Background()) }()
+
+			Consistently(started).ShouldNot(Receive())
+			Expect(consuming).NotTo(BeClosed())
+
+			close(ready)
+			Eventually(started).Should(Receive(BeNil()))
+			Eventually(consuming).Should(BeClosed())
+		})
+
+		It("should fail when the MQ client does not become ready", func() {
+			client.WaitReadyError = errors.New("not connected")
+
+			err := newConsumer().Start(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("not ready")))
+			Expect(client.ConsumeCalls).To(BeZero())
+		})
+
+		It("should stop waiting when the context is canceled", func() {
+			client.WaitReadyFunc = func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			Expect(newConsumer().Start(ctx)).To(MatchError(context.Canceled))
+		})
+
+		It("should stop consuming when the context is canceled", func() {
+			subscribed := make(chan struct{})
+			client.ConsumeQueuesFunc = func(ctx context.Context, _ map[string]mq.Handler, _ mq.ConsumeHooks) error {
+				close(subscribed)
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+
+			consumer := newConsumer()
+			Expect(consumer.Start(ctx)).To(Succeed())
+			Eventually(subscribed).Should(BeClosed())
+
+			cancel()
+			Expect(consumer.Stop()).To(Succeed())
+		})
+	})
 })
@@ -1,29 +1,86 @@
 package backend
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+// Database drivers supported by NewDB.
+const (
+	DriverPostgres = "postgres"
+	// DriverSQLite stores everything in a single file, or in memory for
+	// DBName ":memory:". It is meant for local development and tests:
+	// partitioning, retention, rollups and battery projections need PostgreSQL.
+	DriverSQLite = "sqlite"
+)
+
+const (
+	// defaultConnectBackoff is the wait after the first failed connection
+	// attempt when not configured.
+	defaultConnectBackoff = time.Second
+	// maxConnectBackoff caps the wait between connection attempts.
+	maxConnectBackoff = 30 * time.Second
 )
 
 // DBConfig holds the database configuration.
 type DBConfig struct {
 	Logger   *slog.Logger
+	Driver   string // DriverPostgres (default) or DriverSQLite
 	Host     string
 	User     string
 	Password string
 	DBName   string
 	SSLMode  string
 	Port     int
+
+	// ConnectAttempts is how often connecting is tried before giving up
+	// (optional, default 1)
+	ConnectAttempts int
+	// ConnectBackoff is the wait after the first failed attempt; it doubles
+	// after every further failure up to 30 seconds (optional, default 1 second)
+	ConnectBackoff time.Duration
+	// WaitForDB retries connecting until it succeeds or the context is done,
+	// ignoring ConnectAttempts
+	WaitForDB bool
+	// SkipSchemaCheck migrates the schema on every start instead of checking
+	// its version, as releases before schema versioning did
+	SkipSchemaCheck bool
+
+	// PrepareStmt prepares every statement once per connection and reuses
+	// it, so repeated queries skip parsing and planning
+	PrepareStmt bool
+	// SkipDefaultTransaction runs single creates, updates and deletes without
+	// the transaction GORM wraps them in by default
+	SkipDefaultTransaction bool
+	// StatementTimeout makes PostgreSQL cancel statements running longer
+	// (optional, 0 = no limit). SQLite ignores it.
+	StatementTimeout time.Duration
+	// SlowQueryThreshold logs statements taking at least this long and counts
+	// them in Metrics (optional, 0 = disabled)
+	SlowQueryThreshold time.Duration
+	// Metrics records the count and duration of statements (optional)
+	Metrics *metrics.BackendMetrics
 }
 
-// NewDB creates a new database connection and runs migrations.
+// NewDB creates a new database connection and checks its schema. Empty
+// databases are migrated; others must match SchemaVersion unless the check
+// is skipped.
 func NewDB(cfg *DBConfig) (*gorm.DB, error) {
+	return NewDBContext(context.Background(), cfg)
+}
+
+// NewDBContext is NewDB with a context that aborts connection retries.
+func NewDBContext(ctx context.Context, cfg *DBConfig) (*gorm.DB, error) {
 	if cfg == nil {
 		return nil, errors.New("database config cannot be nil")
 	}
@@ -32,26 +89,116 @@ func NewDB(cfg *DBConfig) (*gorm.DB, error) {
 		return nil, errors.New("logger cannot be nil")
 	}
 
-	// Build DSN
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode)
+	if cfg.ConnectAttempts < 0 || cfg.ConnectBackoff < 0 {
+		return nil, errors.New("database connect attempts and backoff cannot be negative")
+	}
+
+	if cfg.StatementTimeout < 0 || cfg.SlowQueryThreshold < 0 {
+		return nil, errors.New("statement timeout and slow query threshold cannot be negative")
+	}
+
+	db, err := connectDB(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Logger.Info("database connection established")
+
+	if cfg.SkipSchemaCheck {
+		cfg.Logger.Warn("schema check skipped, running migrations")
+		err = migrateSchema(db, cfg.Logger, true)
+	} else {
+		err = checkSchema(db, cfg.Logger)
+	}
+	if err != nil {
+		_ = CloseDB(db, cfg.Logger)
+		return nil, fmt.Errorf("failed to prepare schema: %w", err)
+	}
+
+	return db, nil
+}
+
+// MigrateDB connects to the database, migrates its schema to SchemaVersion
+// and closes it again.
+func MigrateDB(ctx context.Context, cfg *DBConfig) error {
+	if cfg == nil {
+		return errors.New("database config cannot be nil")
+	}
+
+	if cfg.Logger == nil {
+		return errors.New("logger cannot be nil")
+	}
+
+	db, err := connectDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = CloseDB(db, cfg.Logger) }()
+
+	return migrateSchema(db, cfg.Logger, false)
+}
+
+// connectDB opens the database, retrying with exponential backoff until it
+// answers a ping, the attempts are used up or ctx is done.
+func connectDB(ctx context.Context, cfg *DBConfig) (*gorm.DB, error) {
+	attempts := cfg.ConnectAttempts
+	if attempts == 0 {
+		attempts = 1
+	}
+
+	backoff := cfg.ConnectBackoff
+	if backoff == 0 {
+		backoff = defaultConnectBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		// Configuration errors are not retried
+		dialector, err := newDialector(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		db, err := openDB(ctx, cfg, dialector)
+		if err == nil {
+			return db, nil
+		}
+
+		if !cfg.WaitForDB && attempt >= attempts {
+			return nil, err
+		}
+
+		cfg.Logger.Warn("database not available, retrying",
+			"attempt", attempt,
+			"backoff", backoff,
+			"error", err,
+		)
 
-	cfg.Logger.Info("connecting to database",
-		"host", cfg.Host,
-		"port", cfg.Port,
-		"dbname", cfg.DBName,
-	)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped waiting for database: %w", errors.Join(ctx.Err(), err))
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, maxConnectBackoff)
+	}
+}
 
+// openDB opens the database and pings it once.
+func openDB(ctx context.Context, cfg *DBConfig, dialector gorm.Dialector) (*gorm.DB, error) {
 	// Configure GORM
 	gormConfig := &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent), // Use slog instead of GORM's logger
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
+		// The automatic ping of gorm.Open ignores ctx, so ping explicitly
+		DisableAutomaticPing:   true,
+		PrepareStmt:            cfg.PrepareStmt,
+		SkipDefaultTransaction: cfg.SkipDefaultTransaction,
 	}
 
 	// Connect to database
-	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	db, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -62,24 +209,89 @@ func NewDB(cfg *DBConfig) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to get database instance: %w", err)
 	}
 
+	if cfg.Metrics != nil || cfg.SlowQueryThreshold > 0 {
+		observer := &queryObserver{logger: cfg.Logger, metrics: cfg.Metrics, threshold: cfg.SlowQueryThreshold}
+		if err := observer.register(db); err != nil {
+			_ = sqlDB.Close()
+			return nil, fmt.Errorf("failed to register query metrics: %w", err)
+		}
+	}
+
 	// Set connection pool settings
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
+	// SQLite allows a single writer, and every connection to ":memory:" opens
+	// a separate database
+	if !isPostgres(db) {
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+	}
+
 	// Ping database to verify connection
-	if err := sqlDB.Ping(); err != nil {
+	if err := sqlDB.PingContext(ctx); err != nil {
+		_ = sqlDB.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	cfg.Logger.Info("database connection established")
+	return db, nil
+}
+
+// newDialector returns the GORM dialector for the configured driver.
+func newDialector(cfg *DBConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case "", DriverPostgres:
+		dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode)
+
+		// Unknown DSN settings are sent to the server as run-time parameters
+		if cfg.StatementTimeout > 0 {
+			dsn += fmt.Sprintf(" statement_timeout=%d", cfg.StatementTimeout.Milliseconds())
+		}
+
+		cfg.Logger.Info("connecting to database",
+			"host", cfg.Host,
+			"port", cfg.Port,
+			"dbname", cfg.DBName,
+		)
+
+		return postgres.Open(dsn), nil
+
+	case DriverSQLite:
+		if cfg.DBName == "" {
+			return nil, errors.New("sqlite database file cannot be empty")
+		}
+
+		cfg.Logger.Info("opening sqlite database", "file", cfg.DBName)
 
-	// Run migrations
-	if err := runMigrations(db, cfg.Logger); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+		// Foreign keys are off by default in SQLite; consumers rely on them
+		return sqlite.Open("file:" + cfg.DBName + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
 	}
+}
 
-	return db, nil
+// isPostgres reports whether db is backed by PostgreSQL. Features built on
+// PostgreSQL-only SQL are skipped for other drivers.
+func isPostgres(db *gorm.DB) bool {
+	return db.Name() == DriverPostgres
+}
+
+// dbServerVersion returns the product and version of the database server,
+// such as "PostgreSQL 16.4" or "SQLite 3.46.1".
+func dbServerVersion(ctx context.Context, db *gorm.DB) (string, error) {
+	product, query := "SQLite", "SELECT sqlite_version()"
+	if isPostgres(db) {
+		product, query = "PostgreSQL", "SHOW server_version"
+	}
+
+	var version string
+	if err := db.WithContext(ctx).Raw(query).Scan(&version).Error; err != nil {
+		return "", fmt.Errorf("failed to query database server version: %w", err)
+	}
+	return product + " " + version, nil
 }
 
 // runMigrations runs database migrations for all models.
@@ -92,14 +304,105 @@ func runMigrations(db *gorm.DB, logger *slog.Logger) error {
 		return fmt.Errorf("auto-migration failed for IoTDevice: %w", err)
 	}
 
+	// sensor_readings is partitioned by month, which AutoMigrate cannot create
+	if isPostgres(db) {
+		if err := migrateReadingPartitions(context.Background(), db, logger); err != nil {
+			return fmt.Errorf("partitioning failed for SensorReading: %w", err)
+		}
+	}
+
+	if err := uniqueReadingIndex(db, logger); err != nil {
+		return fmt.Errorf("deduplication failed for SensorReading: %w", err)
+	}
+
 	if err := db.AutoMigrate(&SensorReading{}); err != nil {
 		return fmt.Errorf("auto-migration failed for SensorReading: %w", err)
 	}
 
+	if err := db.AutoMigrate(&AlertRule{}); err != nil {
+		return fmt.Errorf("auto-migration failed for AlertRule: %w", err)
+	}
+
+	if err := db.AutoMigrate(&DeviceNote{}); err != nil {
+		return fmt.Errorf("auto-migration failed for DeviceNote: %w", err)
+	}
+
+	if err := db.AutoMigrate(&BatteryProjection{}); err != nil {
+		return fmt.Errorf("auto-migration failed for BatteryProjection: %w", err)
+	}
+
+	if err := db.AutoMigrate(&ReportSchedule{}); err != nil {
+		return fmt.Errorf("auto-migration failed for ReportSchedule: %w", err)
+	}
+
+	if err := db.AutoMigrate(&HourlyReadingRollup{}, &DailyReadingRollup{}, &RollupState{}); err != nil {
+		return fmt.Errorf("auto-migration failed for reading rollups: %w", err)
+	}
+
+	if err := db.AutoMigrate(&ProcessedMessage{}); err != nil {
+		return fmt.Errorf("auto-migration failed for ProcessedMessage: %w", err)
+	}
+
+	if err := db.AutoMigrate(&QuarantinedReading{}); err != nil {
+		return fmt.Errorf("auto-migration failed for QuarantinedReading: %w", err)
+	}
+
+	if err := db.AutoMigrate(&DeviceLocationHistory{}); err != nil {
+		return fmt.Errorf("auto-migration failed for DeviceLocationHistory: %w", err)
+	}
+
+	if err := db.AutoMigrate(&ComponentUptime{}); err != nil {
+		return fmt.Errorf("auto-migration failed for ComponentUptime: %w", err)
+	}
+
+	if err := db.AutoMigrate(&DeviceEvent{}); err != nil {
+		return fmt.Errorf("auto-migration failed for DeviceEvent: %w", err)
+	}
+
+	if err := createHotQueryIndexes(db, logger); err != nil {
+		return err
+	}
+
 	logger.Info("database migrations completed successfully")
 	return nil
 }
 
+// uniqueReadingIndex prepares idx_device_timestamp to become unique. Older
+// schemas created it as a plain index, which AutoMigrate does not change:
+// duplicate readings are deleted, keeping the first, and the index is dropped
+// so AutoMigrate recreates it unique.
+func uniqueReadingIndex(db *gorm.DB, logger *slog.Logger) error {
+	// Migrator().GetIndexes skips partitioned tables, so ask the catalog
+	query := `SELECT "unique" FROM pragma_index_list('sensor_readings') WHERE name = 'idx_device_timestamp'`
+	if isPostgres(db) {
+		query = `SELECT i.indisunique FROM pg_index i JOIN pg_class c ON c.oid = i.indexrelid
+			WHERE c.relname = 'idx_device_timestamp' AND pg_table_is_visible(c.oid)`
+	}
+
+	var unique []bool
+	if err := db.Raw(query).Scan(&unique).Error; err != nil {
+		return fmt.Errorf("failed to inspect sensor_readings indexes: %w", err)
+	}
+	if len(unique) == 0 || unique[0] {
+		return nil
+	}
+
+	logger.Info("making sensor readings unique per device and timestamp")
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`DELETE FROM sensor_readings WHERE id NOT IN (
+			SELECT MIN(id) FROM sensor_readings GROUP BY device_id, "timestamp")`)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete duplicate readings: %w", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			logger.Warn("deleted duplicate sensor readings", "count", result.RowsAffected)
+		}
+
+		return tx.Migrator().DropIndex(&SensorReading{}, "idx_device_timestamp")
+	})
+}
+
 // CloseDB closes the database connection.
 func CloseDB(db *gorm.DB, logger *slog.Logger) error {
 	if db == nil {
@@ -0,0 +1,107 @@
+package backend
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+// queryStartKey is the statement setting holding the start time of a query.
+const queryStartKey = "demo_app:query_start"
+
+// queryObserver records the count and duration of every statement GORM runs
+// and logs those slower than threshold.
+type queryObserver struct {
+	logger    *slog.Logger
+	metrics   *metrics.BackendMetrics // optional
+	threshold time.Duration           // optional, 0 = no slow query log
+}
+
+// register adds the observer to the callbacks of db.
+func (o *queryObserver) register(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("demo_app:before_create", o.before),
+		cb.Create().After("gorm:create").Register("demo_app:after_create", o.after),
+		cb.Query().Before("gorm:query").Register("demo_app:before_query", o.before),
+		cb.Query().After("gorm:query").Register("demo_app:after_query", o.after),
+		cb.Update().Before("gorm:update").Register("demo_app:before_update", o.before),
+		cb.Update().After("gorm:update").Register("demo_app:after_update", o.after),
+		cb.Delete().Before("gorm:delete").Register("demo_app:before_delete", o.before),
+		cb.Delete().After("gorm:delete").Register("demo_app:after_delete", o.after),
+		cb.Row().Before("gorm:row").Register("demo_app:before_row", o.before),
+		cb.Row().After("gorm:row").Register("demo_app:after_row", o.after),
+		cb.Raw().Before("gorm:raw").Register("demo_app:before_raw", o.before),
+		cb.Raw().After("gorm:raw").Register("demo_app:after_raw", o.after),
+	)
+}
+
+func (o *queryObserver) before(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func (o *queryObserver) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(queryStartKey)
+	if !ok {
+		return
+	}
+	start, ok := value.(time.Time)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(start)
+
+	sql := db.Statement.SQL.String()
+	if sql == "" {
+		// Nothing was sent, e.g. a create of an empty slice
+		return
+	}
+
+	operation := queryOperation(sql)
+	table := db.Statement.Table
+	if table == "" {
+		table = "raw"
+	}
+
+	if o.metrics != nil {
+		status := "success"
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			status = "error"
+		}
+		o.metrics.DBOperationsTotal.WithLabelValues(operation, table, status).Inc()
+		o.metrics.DBOperationDuration.WithLabelValues(operation, table).Observe(elapsed.Seconds())
+	}
+
+	if o.threshold > 0 && elapsed >= o.threshold {
+		if o.metrics != nil {
+			o.metrics.DBSlowQueriesTotal.WithLabelValues(operation, table).Inc()
+		}
+
+		// The SQL has placeholders instead of values, so it never logs
+		// readings or credentials
+		o.logger.Warn("slow database query",
+			"operation", operation,
+			"table", table,
+			"duration", elapsed,
+			"rows", db.Statement.RowsAffected,
+			"sql", sql,
+		)
+	}
+}
+
+// queryOperation returns the metric label for the kind of statement sql is:
+// insert, update, select, delete or other.
+func queryOperation(sql string) string {
+	keyword, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	switch keyword = strings.ToLower(keyword); keyword {
+	case "insert", "update", "select", "delete":
+		return keyword
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,93 @@
+package backend
+
+import (
+	"bytes"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gorm.io/gorm"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+var dbTestMetrics = metrics.NewBackendMetrics("test_db")
+
+var _ = Describe("Query metrics", func() {
+	var (
+		logs *bytes.Buffer
+		cfg  *DBConfig
+	)
+
+	open := func() *gorm.DB {
+		db, err := NewDB(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { Expect(CloseDB(db, cfg.Logger)).To(Succeed()) })
+		return db
+	}
+
+	BeforeEach(func() {
+		logs = &bytes.Buffer{}
+		cfg = &DBConfig{
+			Logger:  slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelWarn})),
+			Driver:  DriverSQLite,
+			DBName:  ":memory:",
+			Metrics: dbTestMetrics,
+		}
+	})
+
+	It("should count and time statements by operation and table", func() {
+		db := open()
+		created := testutil.ToFloat64(dbTestMetrics.DBOperationsTotal.WithLabelValues("insert", "iot_devices", "success"))
+		missing := testutil.ToFloat64(dbTestMetrics.DBOperationsTotal.WithLabelValues("select", "iot_devices", "success"))
+		failed := testutil.ToFloat64(dbTestMetrics.DBOperationsTotal.WithLabelValues("insert", "sensor_readings", "error"))
+
+		Expect(db.Create(&IoTDevice{DeviceID: "sensor-1"}).Error).To(Succeed())
+		Expect(db.Where("device_id = ?", "unknown").First(&IoTDevice{}).Error).To(MatchError(gorm.ErrRecordNotFound))
+		Expect(db.Create(&SensorReading{DeviceID: "unknown"}).Error).To(HaveOccurred())
+
+		Expect(testutil.ToFloat64(dbTestMetrics.DBOperationsTotal.WithLabelValues("insert", "iot_devices", "success"))).To(Equal(created + 1))
+		Expect(testutil.ToFloat64(dbTestMetrics.DBOperationsTotal.WithLabelValues("select", "iot_devices", "success"))).To(Equal(missing + 1))
+		Expect(testutil.ToFloat64(dbTestMetrics.DBOperationsTotal.WithLabelValues("insert", "sensor_readings", "error"))).To(Equal(failed + 1))
+		Expect(testutil.CollectAndCount(dbTestMetrics.DBOperationDuration)).To(BeNumerically(">", 0))
+		Expect(logs.String()).NotTo(ContainSubstring("slow database query"))
+	})
+
+	It("should log and count statements slower than the threshold", func() {
+		cfg.SlowQueryThreshold = time.Nanosecond
+		db := open()
+		slow := testutil.ToFloat64(dbTestMetrics.DBSlowQueriesTotal.WithLabelValues("update", "iot_devices"))
+
+		Expect(db.Model(&IoTDevice{}).Where("device_id = ?", "sensor-1").Update("location", "secret room").Error).To(Succeed())
+
+		Expect(testutil.ToFloat64(dbTestMetrics.DBSlowQueriesTotal.WithLabelValues("update", "iot_devices"))).To(Equal(slow + 1))
+		Expect(logs.String()).To(ContainSubstring("slow database query"))
+		Expect(logs.String()).To(ContainSubstring(`"table":"iot_devices"`))
+		Expect(logs.String()).NotTo(ContainSubstring("secret room"))
+	})
+
+	It("should work with prepared statements and without default transactions", func() {
+		cfg.PrepareStmt = true
+		cfg.SkipDefaultTransaction = true
+		db := open()
+
+		Expect(db.Create(&IoTDevice{DeviceID: "sensor-1"}).Error).To(Succeed())
+		var device IoTDevice
+		Expect(db.Where("device_id = ?", "sensor-1").First(&device).Error).To(Succeed())
+		Expect(db.Where("device_id = ?", "sensor-1").First(&device).Error).To(Succeed())
+	})
+
+	It("should reject negative timeouts", func() {
+		cfg.StatementTimeout = -time.Second
+		_, err := NewDB(cfg)
+		Expect(err).To(MatchError(ContainSubstring("cannot be negative")))
+	})
+
+	It("should label statements by their first keyword", func() {
+		Expect(queryOperation("SELECT * FROM iot_devices")).To(Equal("select"))
+		Expect(queryOperation("  insert INTO x")).To(Equal("insert"))
+		Expect(queryOperation("WITH x AS (SELECT 1) SELECT * FROM x")).To(Equal("other"))
+	})
+})
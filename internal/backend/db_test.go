@@ -1,11 +1,15 @@
 package backend_test
 
 import (
+	"context"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
 
 	"procodus.dev/demo-app/internal/backend"
 )
@@ -219,6 +223,180 @@ var _ = Describe("Database", func() {
 		})
 	})
 
+	Describe("NewDB with sqlite", func() {
+		It("should reject unknown drivers", func() {
+			db, err := backend.NewDB(&backend.DBConfig{Logger: logger, Driver: "mysql", DBName: "testdb"})
+			Expect(err).To(MatchError(ContainSubstring("unsupported database driver")))
+			Expect(db).To(BeNil())
+		})
+
+		It("should require a database file", func() {
+			db, err := backend.NewDB(&backend.DBConfig{Logger: logger, Driver: backend.DriverSQLite})
+			Expect(err).To(HaveOccurred())
+			Expect(db).To(BeNil())
+		})
+
+		It("should migrate an in-memory database", func() {
+			db, err := backend.NewDB(&backend.DBConfig{Logger: logger, Driver: backend.DriverSQLite, DBName: ":memory:"})
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(func() { Expect(backend.CloseDB(db, logger)).To(Succeed()) })
+
+			Expect(db.Migrator().HasTable(&backend.SensorReading{})).To(BeTrue())
+			Expect(db.Migrator().HasTable(&backend.ReportSchedule{})).To(BeTrue())
+
+			Expect(db.Create(&backend.IoTDevice{DeviceID: "sqlite-device", LastSeen: time.Now()}).Error).To(Succeed())
+			Expect(db.Create(&backend.SensorReading{DeviceID: "sqlite-device", Timestamp: time.Now()}).Error).To(Succeed())
+
+			var count int64
+			Expect(db.Model(&backend.SensorReading{}).Count(&count).Error).To(Succeed())
+			Expect(count).To(Equal(int64(1)))
+		})
+
+		It("should enforce foreign keys", func() {
+			db, err := backend.NewDB(&backend.DBConfig{Logger: logger, Driver: backend.DriverSQLite, DBName: ":memory:"})
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(func() { Expect(backend.CloseDB(db, logger)).To(Succeed()) })
+
+			err = db.Create(&backend.SensorReading{DeviceID: "missing-device", Timestamp: time.Now()}).Error
+			Expect(err).To(MatchError(ContainSubstring("FOREIGN KEY constraint failed")))
+		})
+
+		It("should keep data in a database file across connections", func() {
+			file := filepath.Join(GinkgoT().TempDir(), "demo.db")
+			cfg := &backend.DBConfig{Logger: logger, Driver: backend.DriverSQLite, DBName: file}
+
+			db, err := backend.NewDB(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(db.Create(&backend.IoTDevice{DeviceID: "sqlite-device", LastSeen: time.Now()}).Error).To(Succeed())
+			Expect(backend.CloseDB(db, logger)).To(Succeed())
+
+			db, err = backend.NewDB(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(func() { Expect(backend.CloseDB(db, logger)).To(Succeed()) })
+
+			var device backend.IoTDevice
+			Expect(db.First(&device, "device_id = ?", "sqlite-device").Error).To(Succeed())
+		})
+
+		It("should deduplicate readings when upgrading the device and timestamp index", func() {
+			file := filepath.Join(GinkgoT().TempDir(), "demo.db")
+			cfg := &backend.DBConfig{Logger: logger, Driver: backend.DriverSQLite, DBName: file}
+
+			// Recreate the schema of older versions, where the index was not unique
+			db, err := backend.NewDB(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(db.Exec(`DROP INDEX idx_device_timestamp`).Error).To(Succeed())
+			Expect(db.Exec(`CREATE INDEX idx_device_timestamp ON sensor_readings(device_id, "timestamp")`).Error).To(Succeed())
+
+			ts := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+			Expect(db.Create(&backend.IoTDevice{DeviceID: "sqlite-device", LastSeen: ts}).Error).To(Succeed())
+			Expect(db.Create(&[]backend.SensorReading{
+				{DeviceID: "sqlite-device", Timestamp: ts, Temperature: 20},
+				{DeviceID: "sqlite-device", Timestamp: ts, Temperature: 21},
+				{DeviceID: "sqlite-device", Timestamp: ts.Add(time.Minute), Temperature: 22},
+			}).Error).To(Succeed())
+			Expect(backend.CloseDB(db, logger)).To(Succeed())
+
+			Expect(backend.MigrateDB(context.Background(), cfg)).To(Succeed())
+			db, err = backend.NewDB(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(func() { Expect(backend.CloseDB(db, logger)).To(Succeed()) })
+
+			var temperatures []float64
+			Expect(db.Model(&backend.SensorReading{}).Order("timestamp").Pluck("temperature", &temperatures).Error).To(Succeed())
+			Expect(temperatures).To(Equal([]float64{20, 22}))
+
+			err = db.Create(&backend.SensorReading{DeviceID: "sqlite-device", Timestamp: ts}).Error
+			Expect(err).To(MatchError(ContainSubstring("UNIQUE constraint failed")))
+		})
+	})
+
+	Describe("schema version", func() {
+		var cfg *backend.DBConfig
+
+		// reopen changes the recorded schema state with fn and opens the
+		// database again.
+		reopen := func(fn func(db *gorm.DB) error) error {
+			setup := *cfg
+			setup.SkipSchemaCheck = true
+			db, err := backend.NewDB(&setup)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fn(db)).To(Succeed())
+			Expect(backend.CloseDB(db, logger)).To(Succeed())
+
+			db, err = backend.NewDB(cfg)
+			if err == nil {
+				Expect(backend.CloseDB(db, logger)).To(Succeed())
+			}
+			return err
+		}
+
+		setVersion := func(version, minCompatible int) func(db *gorm.DB) error {
+			return func(db *gorm.DB) error {
+				return db.Model(&backend.SchemaState{}).Where("id = 1").Updates(map[string]any{
+					"version":                version,
+					"min_compatible_version": minCompatible,
+				}).Error
+			}
+		}
+
+		dropVersion := func(db *gorm.DB) error {
+			return db.Migrator().DropTable(&backend.SchemaState{})
+		}
+
+		BeforeEach(func() {
+			cfg = &backend.DBConfig{Logger: logger, Driver: backend.DriverSQLite, DBName: filepath.Join(GinkgoT().TempDir(), "demo.db")}
+		})
+
+		It("should record the schema version of new databases", func() {
+			db, err := backend.NewDB(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(func() { Expect(backend.CloseDB(db, logger)).To(Succeed()) })
+
+			var state backend.SchemaState
+			Expect(db.First(&state).Error).To(Succeed())
+			Expect(state.Version).To(Equal(backend.SchemaVersion))
+		})
+
+		It("should accept a matching schema", func() {
+			Expect(reopen(setVersion(backend.SchemaVersion, backend.SchemaVersion))).To(Succeed())
+		})
+
+		It("should reject unversioned and older schemas until they are migrated", func() {
+			Expect(reopen(dropVersion)).To(MatchError(backend.ErrSchemaMismatch))
+			Expect(reopen(setVersion(backend.SchemaVersion-1, backend.SchemaVersion-1))).To(MatchError(ContainSubstring("demo-app db migrate")))
+
+			Expect(backend.MigrateDB(context.Background(), cfg)).To(Succeed())
+			db, err := backend.NewDB(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend.CloseDB(db, logger)).To(Succeed())
+		})
+
+		It("should accept newer schemas that are compatible with this release", func() {
+			Expect(reopen(setVersion(backend.SchemaVersion+1, backend.SchemaVersion))).To(Succeed())
+		})
+
+		It("should reject newer schemas that are incompatible with this release", func() {
+			Expect(reopen(setVersion(backend.SchemaVersion+1, backend.SchemaVersion+1))).To(MatchError(backend.ErrSchemaMismatch))
+			Expect(backend.MigrateDB(context.Background(), cfg)).To(MatchError(ContainSubstring("newer than")))
+		})
+
+		It("should migrate any schema when the check is skipped", func() {
+			Expect(reopen(dropVersion)).To(HaveOccurred())
+
+			cfg.SkipSchemaCheck = true
+			db, err := backend.NewDB(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend.CloseDB(db, logger)).To(Succeed())
+
+			// The version is recorded, so the check passes again
+			cfg.SkipSchemaCheck = false
+			db, err = backend.NewDB(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend.CloseDB(db, logger)).To(Succeed())
+		})
+	})
+
 	Describe("CloseDB", func() {
 		Context("with nil database", func() {
 			It("should handle nil database gracefully", func() {
@@ -234,4 +412,74 @@ var _ = Describe("Database", func() {
 			})
 		})
 	})
+
+	Describe("NewDBContext connection retries", func() {
+		It("should reject negative attempts and backoff", func() {
+			db, err := backend.NewDB(&backend.DBConfig{Logger: logger, Driver: backend.DriverSQLite, DBName: ":memory:", ConnectAttempts: -1})
+			Expect(err).To(MatchError("database connect attempts and backoff cannot be negative"))
+			Expect(db).To(BeNil())
+		})
+
+		It("should give up after the configured attempts", func() {
+			file := filepath.Join(GinkgoT().TempDir(), "missing", "demo.db")
+
+			start := time.Now()
+			db, err := backend.NewDB(&backend.DBConfig{
+				Logger:          logger,
+				Driver:          backend.DriverSQLite,
+				DBName:          file,
+				ConnectAttempts: 3,
+				ConnectBackoff:  20 * time.Millisecond,
+			})
+			Expect(err).To(MatchError(ContainSubstring("unable to open database file")))
+			Expect(db).To(BeNil())
+			// Waits 20ms and 40ms between the three attempts
+			Expect(time.Since(start)).To(BeNumerically(">=", 60*time.Millisecond))
+		})
+
+		It("should wait for the database to become available", func() {
+			dir := filepath.Join(GinkgoT().TempDir(), "later")
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				_ = os.Mkdir(dir, 0o750)
+			}()
+
+			db, err := backend.NewDBContext(context.Background(), &backend.DBConfig{
+				Logger:         logger,
+				Driver:         backend.DriverSQLite,
+				DBName:         filepath.Join(dir, "demo.db"),
+				ConnectBackoff: 20 * time.Millisecond,
+				WaitForDB:      true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend.CloseDB(db, logger)).To(Succeed())
+		})
+
+		It("should stop waiting when the context is canceled", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			db, err := backend.NewDBContext(ctx, &backend.DBConfig{
+				Logger:         logger,
+				Driver:         backend.DriverSQLite,
+				DBName:         filepath.Join(GinkgoT().TempDir(), "missing", "demo.db"),
+				ConnectBackoff: 20 * time.Millisecond,
+				WaitForDB:      true,
+			})
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(err).To(MatchError(ContainSubstring("stopped waiting for database")))
+			Expect(db).To(BeNil())
+		})
+
+		It("should not retry configuration errors", func() {
+			db, err := backend.NewDBContext(context.Background(), &backend.DBConfig{
+				Logger:    logger,
+				Driver:    "mysql",
+				DBName:    "testdb",
+				WaitForDB: true,
+			})
+			Expect(err).To(MatchError(ContainSubstring("unsupported database driver")))
+			Expect(db).To(BeNil())
+		})
+	})
 })
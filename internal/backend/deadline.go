@@ -0,0 +1,47 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+const (
+	// defaultRequestTimeout is used when ServerConfig.RequestTimeout is not set.
+	defaultRequestTimeout = 10 * time.Second
+	// defaultExportTimeout is used when ServerConfig.ExportTimeout is not set.
+	defaultExportTimeout = 5 * time.Minute
+)
+
+// deadlineInterceptor bounds every RPC by timeout, or exportTimeout for
+// ExportReadings, so a slow query cannot hold a handler goroutine forever.
+// Clients may set shorter deadlines but not longer ones. A handler failing
+// because the deadline passed returns DeadlineExceeded instead of its own error.
+func deadlineInterceptor(logger *slog.Logger, timeout, exportTimeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		limit := timeout
+		if _, ok := req.(*iotv1.ExportReadingsRequest); ok {
+			limit = exportTimeout
+		}
+
+		// WithTimeout keeps an earlier deadline of the client
+		ctx, cancel := context.WithTimeout(ctx, limit)
+		defer cancel()
+		deadline, _ := ctx.Deadline()
+		budget := time.Until(deadline)
+
+		resp, err := handler(ctx, req)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			logger.Warn("gRPC request timed out", "method", info.FullMethod, "budget", budget)
+			return nil, iotv1.NewError(codes.DeadlineExceeded, iotv1.ReasonDeadlineExceeded, "request timed out", nil)
+		}
+
+		return resp, err
+	}
+}
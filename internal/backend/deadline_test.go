@@ -0,0 +1,86 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Request deadlines", func() {
+	var (
+		interceptor grpc.UnaryServerInterceptor
+		info        *grpc.UnaryServerInfo
+	)
+
+	BeforeEach(func() {
+		logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+		interceptor = deadlineInterceptor(logger, time.Minute, time.Hour)
+		info = &grpc.UnaryServerInfo{FullMethod: iotv1.IoTService_GetAllDevice_FullMethodName}
+	})
+
+	// budget returns the time left for the handler of req.
+	budget := func(ctx context.Context, req any) time.Duration {
+		var left time.Duration
+		_, err := interceptor(ctx, req, info, func(ctx context.Context, _ any) (any, error) {
+			deadline, ok := ctx.Deadline()
+			Expect(ok).To(BeTrue())
+			left = time.Until(deadline)
+			return nil, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return left
+	}
+
+	It("should bound requests without a deadline", func() {
+		Expect(budget(context.Background(), &iotv1.GetAllDevicesRequest{})).To(BeNumerically("~", time.Minute, time.Second))
+	})
+
+	It("should give exports the export timeout", func() {
+		Expect(budget(context.Background(), &iotv1.ExportReadingsRequest{})).To(BeNumerically("~", time.Hour, time.Second))
+	})
+
+	It("should keep shorter client deadlines", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		Expect(budget(ctx, &iotv1.GetAllDevicesRequest{})).To(BeNumerically("<=", time.Second))
+	})
+
+	It("should cap longer client deadlines", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		Expect(budget(ctx, &iotv1.GetAllDevicesRequest{})).To(BeNumerically("~", time.Minute, time.Second))
+	})
+
+	It("should report handlers running out of time as DeadlineExceeded", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		_, err := interceptor(ctx, &iotv1.GetAllDevicesRequest{}, info, func(ctx context.Context, _ any) (any, error) {
+			<-ctx.Done()
+			return nil, databaseError("failed to fetch devices")
+		})
+
+		Expect(status.Code(err)).To(Equal(codes.DeadlineExceeded))
+		Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonDeadlineExceeded))
+	})
+
+	It("should pass through other errors", func() {
+		_, err := interceptor(context.Background(), &iotv1.GetAllDevicesRequest{}, info, func(context.Context, any) (any, error) {
+			return nil, errors.New("boom")
+		})
+
+		Expect(err).To(MatchError("boom"))
+	})
+})
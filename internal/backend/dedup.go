@@ -0,0 +1,153 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// defaultDedupTTL is how long processed message IDs are kept when not
+	// configured. It must exceed the longest time a message can wait for a
+	// redelivery, e.g. while a consumer is down.
+	defaultDedupTTL = 7 * 24 * time.Hour
+	// defaultDedupCleanupInterval is how often expired message IDs are deleted.
+	defaultDedupCleanupInterval = time.Hour
+)
+
+// markProcessed records messageID as processed from queue within tx. It
+// reports false if the message was processed before, in which case the caller
+// must skip it. Messages without an ID, e.g. from producers predating message
+// IDs, cannot be deduplicated and are always reported as new.
+func markProcessed(tx *gorm.DB, queue, messageID string) (bool, error) {
+	if messageID == "" {
+		return true, nil
+	}
+
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&ProcessedMessage{
+		MessageID:   messageID,
+		Queue:       queue,
+		ProcessedAt: time.Now().UTC(),
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to record processed message: %w", result.Error)
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// DedupCleanupJob periodically deletes processed message IDs older than the
+// dedup TTL, so the processed_messages table does not grow forever.
+type DedupCleanupJob struct {
+	logger   *slog.Logger
+	db       *gorm.DB
+	done     chan struct{}
+	cancel   context.CancelFunc
+	ttl      time.Duration
+	interval time.Duration
+}
+
+// DedupCleanupJobConfig holds the configuration for the DedupCleanupJob.
+type DedupCleanupJobConfig struct {
+	Logger   *slog.Logger
+	DB       *gorm.DB
+	TTL      time.Duration // How long message IDs are kept (optional, default 7 days)
+	Interval time.Duration // Time between cleanups (optional, default 1 hour)
+}
+
+// NewDedupCleanupJob creates a new DedupCleanupJob instance.
+func NewDedupCleanupJob(cfg *DedupCleanupJobConfig) (*DedupCleanupJob, error) {
+	if cfg == nil {
+		return nil, errors.New("dedup cleanup job config cannot be nil")
+	}
+
+	if cfg.Logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+
+	if cfg.DB == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+
+	if cfg.TTL < 0 || cfg.Interval < 0 {
+		return nil, errors.New("dedup TTL and interval cannot be negative")
+	}
+
+	ttl := cfg.TTL
+	if ttl == 0 {
+		ttl = defaultDedupTTL
+	}
+
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = defaultDedupCleanupInterval
+	}
+
+	return &DedupCleanupJob{
+		logger:   cfg.Logger,
+		db:       cfg.DB,
+		done:     make(chan struct{}),
+		ttl:      ttl,
+		interval: interval,
+	}, nil
+}
+
+// Start deletes expired message IDs immediately and then on every interval
+// until Stop is called or ctx is canceled.
+func (j *DedupCleanupJob) Start(ctx context.Context) {
+	ctx, j.cancel = context.WithCancel(ctx)
+
+	j.logger.Info("starting dedup cleanup job", "ttl", j.ttl, "interval", j.interval)
+
+	go func() {
+		defer close(j.done)
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := j.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				j.logger.Error("failed to delete expired processed messages", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the job and waits for a running cleanup to finish.
+func (j *DedupCleanupJob) Stop() {
+	if j.cancel == nil {
+		return
+	}
+
+	j.cancel()
+	<-j.done
+
+	j.logger.Info("dedup cleanup job stopped")
+}
+
+// RunOnce deletes the message IDs processed longer than the TTL ago. A message
+// redelivered after that is persisted again.
+func (j *DedupCleanupJob) RunOnce(ctx context.Context) error {
+	cutoff := time.Now().UTC().Add(-j.ttl)
+
+	result := j.db.WithContext(ctx).Where("processed_at < ?", cutoff).Delete(&ProcessedMessage{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete processed messages: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		j.logger.Info("deleted expired processed messages", "count", result.RowsAffected, "cutoff", cutoff)
+	}
+	return nil
+}
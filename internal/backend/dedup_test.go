@@ -0,0 +1,199 @@
+package backend
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Message deduplication", func() {
+	var (
+		ctx    context.Context
+		logger *slog.Logger
+		db     *gorm.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError,
+		}))
+
+		db = newTestDB(logger)
+
+		Expect(db.Create(&IoTDevice{DeviceID: "sensor-1", Location: "Lab", LastSeen: time.Now()}).Error).To(Succeed())
+	})
+
+	// deliver hands body to handle as a delivery with messageID.
+	deliver := func(handle func(context.Context, amqp.Delivery), messageID string, msg proto.Message) *fakeAcknowledger {
+		body, err := proto.Marshal(msg)
+		Expect(err).NotTo(HaveOccurred())
+
+		ack := &fakeAcknowledger{}
+		handle(ctx, amqp.Delivery{Acknowledger: ack, MessageId: messageID, Body: body})
+		return ack
+	}
+
+	countReadings := func() int64 {
+		var count int64
+		Expect(db.Model(&SensorReading{}).Count(&count).Error).To(Succeed())
+		return count
+	}
+
+	Describe("sensor readings", func() {
+		var c *Consumer
+
+		BeforeEach(func() {
+			c = &Consumer{
+				logger:    logger,
+				db:        db,
+				metrics:   consumerTestMetrics,
+				queueName: "sensor-dedup-test",
+			}
+		})
+
+		It("should save a redelivered message once", func() {
+			first := &iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: 1700000000, Temperature: 21}
+			// A redelivery may carry a different body, e.g. from a retrying producer
+			redelivery := &iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: 1700000060, Temperature: 22}
+
+			Expect(deliver(c.handleDelivery, "msg-1", first).acks).To(Equal(1))
+			Expect(deliver(c.handleDelivery, "msg-1", redelivery).acks).To(Equal(1))
+
+			Expect(countReadings()).To(Equal(int64(1)))
+			Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerMessagesTotal.WithLabelValues("sensor-dedup-test", "success"))).To(Equal(1.0))
+			Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerMessagesTotal.WithLabelValues("sensor-dedup-test", "duplicate"))).To(Equal(1.0))
+
+			var processed ProcessedMessage
+			Expect(db.First(&processed, "message_id = ?", "msg-1").Error).To(Succeed())
+			Expect(processed.Queue).To(Equal("sensor-dedup-test"))
+		})
+
+		It("should keep message IDs of different queues apart", func() {
+			other := &Consumer{logger: logger, db: db, metrics: consumerTestMetrics, queueName: "sensor-dedup-other"}
+
+			deliver(c.handleDelivery, "msg-1", &iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: 1700000000})
+			deliver(other.handleDelivery, "msg-1", &iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: 1700000060})
+
+			Expect(countReadings()).To(Equal(int64(2)))
+
+			var queues []string
+			Expect(db.Model(&ProcessedMessage{}).Where("message_id = ?", "msg-1").Order("queue").Pluck("queue", &queues).Error).To(Succeed())
+			Expect(queues).To(Equal([]string{"sensor-dedup-other", "sensor-dedup-test"}))
+		})
+
+		It("should fall back to the reading index for messages without an ID", func() {
+			reading := &iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: 1700000000}
+
+			deliver(c.handleDelivery, "", reading)
+			deliver(c.handleDelivery, "", reading)
+
+			Expect(countReadings()).To(Equal(int64(1)))
+
+			var processed int64
+			Expect(db.Model(&ProcessedMessage{}).Count(&processed).Error).To(Succeed())
+			Expect(processed).To(BeZero())
+		})
+
+		It("should not record the ID of a message whose reading was rolled back", func() {
+			ack := deliver(c.handleDelivery, "msg-unknown", &iotv1.SensorReading{DeviceId: "unknown", Timestamp: 1700000000})
+			Expect(ack.acks).To(Equal(1))
+
+			var processed int64
+			Expect(db.Model(&ProcessedMessage{}).Count(&processed).Error).To(Succeed())
+			Expect(processed).To(BeZero())
+		})
+	})
+
+	Describe("devices", func() {
+		It("should not let a redelivered message overwrite newer data", func() {
+			c := &DeviceConsumer{
+				logger:    logger,
+				db:        db,
+				queueName: "device-dedup-test",
+			}
+
+			deliver(c.handleDelivery, "dev-1", &iotv1.IoTDevice{DeviceId: "sensor-2", Location: "Office"})
+			deliver(c.handleDelivery, "dev-2", &iotv1.IoTDevice{DeviceId: "sensor-2", Location: "Warehouse"})
+			deliver(c.handleDelivery, "dev-1", &iotv1.IoTDevice{DeviceId: "sensor-2", Location: "Office"})
+
+			var device IoTDevice
+			Expect(db.First(&device, "device_id = ?", "sensor-2").Error).To(Succeed())
+			Expect(device.Location).To(Equal("Warehouse"))
+		})
+
+		It("should skip messages older than the stored device", func() {
+			c := &DeviceConsumer{
+				logger:    logger,
+				db:        db,
+				metrics:   consumerTestMetrics,
+				queueName: "device-stale-test",
+			}
+			now := time.Now().Unix()
+			stale := testutil.ToFloat64(consumerTestMetrics.StaleDeviceUpdates.WithLabelValues(deviceEventSourceQueue))
+
+			deliver(c.handleDelivery, "dev-3", &iotv1.IoTDevice{DeviceId: "sensor-2", Location: "Warehouse", Timestamp: now})
+			ack := deliver(c.handleDelivery, "dev-4", &iotv1.IoTDevice{DeviceId: "sensor-2", Location: "Office", Timestamp: now - 60})
+			Expect(ack.acks).To(Equal(1))
+			deliver(c.handleDelivery, "dev-5", &iotv1.IoTDevice{DeviceId: "sensor-2", Location: "Basement", Timestamp: now})
+
+			var device IoTDevice
+			Expect(db.First(&device, "device_id = ?", "sensor-2").Error).To(Succeed())
+			Expect(device.Location).To(Equal("Basement"))
+			Expect(device.Version).To(Equal(int64(2)))
+			Expect(testutil.ToFloat64(consumerTestMetrics.StaleDeviceUpdates.WithLabelValues(deviceEventSourceQueue))).To(Equal(stale + 1))
+			Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerMessagesTotal.WithLabelValues("device-stale-test", "stale"))).To(Equal(1.0))
+		})
+
+		It("should not apply an update to a device changed since it was read", func() {
+			var stored IoTDevice
+			Expect(db.First(&stored, "device_id = ?", "sensor-1").Error).To(Succeed())
+			Expect(writeDevice(db, &stored, &IoTDevice{DeviceID: "sensor-1", Location: "Office", LastSeen: time.Now()})).To(Succeed())
+
+			// stored still holds the version before the update
+			err := writeDevice(db, &stored, &IoTDevice{DeviceID: "sensor-1", Location: "Warehouse", LastSeen: time.Now()})
+			Expect(err).To(MatchError(errDeviceConflict))
+
+			var device IoTDevice
+			Expect(db.First(&device, "device_id = ?", "sensor-1").Error).To(Succeed())
+			Expect(device.Location).To(Equal("Office"))
+			Expect(device.Version).To(Equal(stored.Version + 1))
+		})
+	})
+
+	Describe("DedupCleanupJob", func() {
+		It("should validate its config", func() {
+			_, err := NewDedupCleanupJob(nil)
+			Expect(err).To(HaveOccurred())
+
+			_, err = NewDedupCleanupJob(&DedupCleanupJobConfig{Logger: logger, DB: db, TTL: -time.Hour})
+			Expect(err).To(MatchError(ContainSubstring("cannot be negative")))
+		})
+
+		It("should delete message IDs older than the TTL", func() {
+			now := time.Now().UTC()
+			Expect(db.Create(&[]ProcessedMessage{
+				{MessageID: "old", Queue: "sensor_data", ProcessedAt: now.Add(-2 * time.Hour)},
+				{MessageID: "recent", Queue: "sensor_data", ProcessedAt: now.Add(-time.Minute)},
+			}).Error).To(Succeed())
+
+			job, err := NewDedupCleanupJob(&DedupCleanupJobConfig{Logger: logger, DB: db, TTL: time.Hour})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.RunOnce(ctx)).To(Succeed())
+
+			var ids []string
+			Expect(db.Model(&ProcessedMessage{}).Pluck("message_id", &ids).Error).To(Succeed())
+			Expect(ids).To(ConsistOf("recent"))
+		})
+	})
+})
@@ -5,25 +5,28 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"google.golang.org/protobuf/proto"
 	"gorm.io/gorm"
 
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
 	"procodus.dev/demo-app/pkg/metrics"
 	"procodus.dev/demo-app/pkg/mq"
 )
 
 // DeviceConsumer consumes device creation messages from RabbitMQ and persists them to PostgreSQL.
 type DeviceConsumer struct {
-	logger   *slog.Logger
-	db       *gorm.DB
-	mqClient mq.ClientInterface
-	done     chan struct{}
-	metrics  *metrics.BackendMetrics // Optional metrics
+	logger    *slog.Logger
+	db        *gorm.DB
+	mqClient  mq.ClientInterface
+	ownsMQ    bool               // Whether Stop closes mqClient
+	cancel    context.CancelFunc // Stops message processing
+	done      chan struct{}
+	metrics   *metrics.BackendMetrics // Optional metrics
+	mqMetrics *metrics.MQMetrics      // Optional MQ metrics
+	queueName string
 }
 
 // DeviceConsumerConfig holds the configuration for the DeviceConsumer.
@@ -34,6 +37,7 @@ type DeviceConsumerConfig struct {
 	QueueName   string
 	Metrics     *metrics.BackendMetrics // Optional metrics
 	MQMetrics   *metrics.MQMetrics      // Optional MQ metrics
+	MQClient    mq.ClientInterface      // Optional, shared RabbitMQ client or a mock in tests; not closed by Stop
 }
 
 // NewDeviceConsumer creates a new DeviceConsumer instance.
@@ -50,7 +54,7 @@ func NewDeviceConsumer(cfg *DeviceConsumerConfig) (*DeviceConsumer, error) {
 		return nil, errors.New("database cannot be nil")
 	}
 
-	if cfg.RabbitMQURL == "" {
+	if cfg.RabbitMQURL == "" && cfg.MQClient == nil {
 		return nil, errors.New("rabbitmq URL cannot be empty")
 	}
 
@@ -58,20 +62,31 @@ func NewDeviceConsumer(cfg *DeviceConsumerConfig) (*DeviceConsumer, error) {
 		return nil, errors.New("queue name cannot be empty")
 	}
 
-	// Create MQ client
-	mqClient := mq.New(cfg.QueueName, cfg.RabbitMQURL, cfg.Logger)
+	// Create MQ client unless one is provided
+	mqClient := cfg.MQClient
+	if mqClient == nil {
+		client, err := mq.NewWithContext(context.Background(), cfg.QueueName, cfg.RabbitMQURL, cfg.Logger)
+		if err != nil {
+			return nil, err
+		}
+
+		// Enable MQ metrics if configured
+		if cfg.MQMetrics != nil {
+			client.SetMetrics(cfg.MQMetrics)
+		}
 
-	// Enable MQ metrics if configured
-	if cfg.MQMetrics != nil {
-		mqClient.SetMetrics(cfg.MQMetrics)
+		mqClient = client
 	}
 
 	return &DeviceConsumer{
-		logger:   cfg.Logger,
-		db:       cfg.DB,
-		mqClient: mqClient,
-		done:     make(chan struct{}),
-		metrics:  cfg.Metrics,
+		logger:    cfg.Logger,
+		db:        cfg.DB,
+		mqClient:  mqClient,
+		ownsMQ:    cfg.MQClient == nil,
+		done:      make(chan struct{}),
+		metrics:   cfg.Metrics,
+		mqMetrics: cfg.MQMetrics,
+		queueName: cfg.QueueName,
 	}, nil
 }
 
@@ -84,12 +99,8 @@ func (c *DeviceConsumer) Start(ctx context.Context) error {
 		c.metrics.ActiveConsumers.Inc()
 	}
 
-	// Wait for MQ client to be ready
-	time.Sleep(2 * time.Second)
-
-	// Start consuming messages
-	deliveries, err := c.mqClient.Consume()
-	if err != nil {
+	// Fail fast when RabbitMQ cannot be reached on start
+	if err := waitReady(ctx, c.mqClient); err != nil {
 		// Decrement on error
 		if c.metrics != nil {
 			c.metrics.ActiveConsumers.Dec()
@@ -99,44 +110,45 @@ func (c *DeviceConsumer) Start(ctx context.Context) error {
 
 	c.logger.Info("device consumer started, waiting for messages")
 
-	// Process messages in a goroutine
-	go c.processMessages(ctx, deliveries)
+	// Process messages in a goroutine, resubscribing after reconnects
+	ctx, c.cancel = context.WithCancel(ctx)
+	go c.processMessages(ctx)
 
 	return nil
 }
 
-// processMessages processes incoming device messages from the deliveries channel.
-func (c *DeviceConsumer) processMessages(ctx context.Context, deliveries <-chan amqp.Delivery) {
-	for {
-		select {
-		case <-ctx.Done():
-			c.logger.Info("context canceled, stopping device message processing")
-			close(c.done)
-			return
-
-		case delivery, ok := <-deliveries:
-			if !ok {
-				c.logger.Warn("device deliveries channel closed")
-				close(c.done)
-				return
-			}
-
-			c.handleDelivery(ctx, delivery)
-		}
+// processMessages handles device deliveries until the context is canceled or
+// the MQ client is closed.
+func (c *DeviceConsumer) processMessages(ctx context.Context) {
+	defer close(c.done)
+
+	if err := c.mqClient.ConsumeQueues(ctx, map[string]mq.Handler{c.queueName: c.handleDelivery}, mq.ConsumeHooks{}); err != nil && ctx.Err() == nil {
+		c.logger.Error("device message processing stopped", "error", err)
+		return
 	}
+
+	c.logger.Info("stopping device message processing")
 }
 
 // handleDelivery processes a single device message delivery.
 func (c *DeviceConsumer) handleDelivery(ctx context.Context, delivery amqp.Delivery) {
+	// A message that makes processing panic is rejected instead of crashing the process
+	delivery = trackSettlement(delivery)
+	defer recoverDelivery(c.logger, c.metrics, c.mqMetrics, c.queueName, delivery)
+
 	// Track processing duration
 	var timer *prometheus.Timer
 	if c.metrics != nil {
-		timer = prometheus.NewTimer(c.metrics.ProcessingDuration.WithLabelValues("device-data"))
+		timer = prometheus.NewTimer(c.metrics.ProcessingDuration.WithLabelValues(c.queueName))
 		defer timer.ObserveDuration()
 	}
+	if c.mqMetrics != nil {
+		mqTimer := prometheus.NewTimer(c.mqMetrics.ConsumeDuration.WithLabelValues(c.queueName))
+		defer mqTimer.ObserveDuration()
+	}
 
 	// Parse the protobuf message
-	device := &iot.IoTDevice{}
+	device := &iotv1.IoTDevice{}
 	if err := proto.Unmarshal(delivery.Body, device); err != nil {
 		c.logger.Error("failed to unmarshal device message",
 			"error", err,
@@ -144,8 +156,11 @@ func (c *DeviceConsumer) handleDelivery(ctx context.Context, delivery amqp.Deliv
 
 		// Track failure
 		if c.metrics != nil {
-			c.metrics.ConsumerMessagesTotal.WithLabelValues("device-data", "error").Inc()
-			c.metrics.ConsumerErrors.WithLabelValues("device-data", "unmarshal_error").Inc()
+			c.metrics.ConsumerMessagesTotal.WithLabelValues(c.queueName, "error").Inc()
+			c.metrics.ConsumerErrors.WithLabelValues(c.queueName, "unmarshal_error").Inc()
+		}
+		if c.mqMetrics != nil {
+			c.mqMetrics.ConsumptionFailures.WithLabelValues(c.queueName, "unmarshal_error").Inc()
 		}
 
 		// Acknowledge message even on parse error to avoid reprocessing
@@ -162,34 +177,82 @@ func (c *DeviceConsumer) handleDelivery(ctx context.Context, delivery amqp.Deliv
 	)
 
 	// Save to database
-	if err := c.saveIoTDevice(ctx, device); err != nil {
+	duplicate, stale, err := c.saveIoTDevice(ctx, delivery.MessageId, device, delivery.Body)
+	if err != nil {
 		c.logger.Error("failed to save device",
 			"device_id", device.GetDeviceId(),
 			"error", err,
 		)
 
+		// A concurrent update is not a database outage
+		errorType := "database_error"
+		if errors.Is(err, errDeviceConflict) {
+			errorType = "conflict"
+		}
+
 		// Track failure
 		if c.metrics != nil {
-			c.metrics.ConsumerMessagesTotal.WithLabelValues("device-data", "error").Inc()
-			c.metrics.ConsumerErrors.WithLabelValues("device-data", "database_error").Inc()
+			c.metrics.ConsumerMessagesTotal.WithLabelValues(c.queueName, "error").Inc()
+			c.metrics.ConsumerErrors.WithLabelValues(c.queueName, errorType).Inc()
+		}
+		if c.mqMetrics != nil {
+			c.mqMetrics.ConsumptionFailures.WithLabelValues(c.queueName, errorType).Inc()
 		}
 
 		// Nack the message so it can be reprocessed
 		if nackErr := delivery.Nack(false, true); nackErr != nil {
 			c.logger.Error("failed to nack message", "error", nackErr)
 		}
+
+		// Stop taking messages until the database is back
+		if errorType == "database_error" {
+			pauseWhileDBDown(ctx, c.db, c.logger, c.metrics, c.queueName)
+		}
 		return
 	}
 
 	// Acknowledge successful processing
 	if err := delivery.Ack(false); err != nil {
 		c.logger.Error("failed to ack message", "error", err)
+		if c.mqMetrics != nil {
+			c.mqMetrics.ConsumptionFailures.WithLabelValues(c.queueName, "ack_error").Inc()
+		}
 		return
 	}
 
 	// Track success
+	status := "success"
+	switch {
+	case duplicate:
+		status = "duplicate"
+	case stale:
+		status = "stale"
+	}
 	if c.metrics != nil {
-		c.metrics.ConsumerMessagesTotal.WithLabelValues("device-data", "success").Inc()
+		c.metrics.ConsumerMessagesTotal.WithLabelValues(c.queueName, status).Inc()
+		if stale {
+			c.metrics.StaleDeviceUpdates.WithLabelValues(deviceEventSourceQueue).Inc()
+		}
+	}
+	if c.mqMetrics != nil {
+		c.mqMetrics.MessagesConsumed.WithLabelValues(c.queueName).Inc()
+	}
+
+	if duplicate {
+		c.logger.Debug("duplicate device message skipped",
+			"device_id", device.GetDeviceId(),
+			"message_id", delivery.MessageId,
+		)
+		return
+	}
+
+	if stale {
+		c.logger.Warn("stale device message skipped",
+			"device_id", device.GetDeviceId(),
+			"message_id", delivery.MessageId,
+			"timestamp", device.GetTimestamp(),
+		)
+		return
 	}
 
 	c.logger.Debug("device saved successfully",
@@ -197,45 +260,142 @@ func (c *DeviceConsumer) handleDelivery(ctx context.Context, delivery amqp.Deliv
 	)
 }
 
-// saveIoTDevice saves an IoT device to the database using upsert logic.
-func (c *DeviceConsumer) saveIoTDevice(ctx context.Context, device *iot.IoTDevice) error {
-	// Convert protobuf timestamp to time.Time
-	timestamp := time.Unix(device.GetTimestamp(), 0).UTC()
-
-	// Create database model
-	dbDevice := &IoTDevice{
-		DeviceID:   device.GetDeviceId(),
-		Location:   device.GetLocation(),
-		MACAddress: device.GetMacAddress(),
-		IPAddress:  device.GetIpAddress(),
-		Firmware:   device.GetFirmware(),
-		LastSeen:   timestamp,
-		Latitude:   device.GetLatitude(),
-		Longitude:  device.GetLongitude(),
-	}
-
-	// Use upsert logic: create if not exists, update if exists
-	// This handles the case where a device message might be received multiple times
-	result := c.db.WithContext(ctx).
-		Where("device_id = ?", dbDevice.DeviceID).
-		Assign(map[string]interface{}{
-			"location":    dbDevice.Location,
-			"mac_address": dbDevice.MACAddress,
-			"ip_address":  dbDevice.IPAddress,
-			"firmware":    dbDevice.Firmware,
-			"last_seen":   dbDevice.LastSeen,
-			"latitude":    dbDevice.Latitude,
-			"longitude":   dbDevice.Longitude,
-		}).
-		FirstOrCreate(dbDevice)
+// errStaleDevice is returned by upsertDevice for a device message older than
+// the stored device, which must not overwrite it.
+var errStaleDevice = errors.New("device message is older than the stored device")
+
+// errDeviceConflict is returned by upsertDevice when the stored device was
+// updated concurrently; the message should be applied again.
+var errDeviceConflict = errors.New("device was updated concurrently")
+
+// saveIoTDevice appends the device message body to the device event log,
+// then saves the IoT device to the database using upsert logic and records
+// its location history when it is new or has moved. It reports whether the
+// device was skipped because messageID was processed before, and whether it
+// was skipped as stale: redelivered or reordered messages must not overwrite
+// newer device data. Stale messages are still recorded as processed and in
+// the event log.
+func (c *DeviceConsumer) saveIoTDevice(ctx context.Context, messageID string, device *iotv1.IoTDevice, body []byte) (duplicate, stale bool, err error) {
+	err = c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		first, err := markProcessed(tx, c.queueName, messageID)
+		if err != nil {
+			return err
+		}
+		if !first {
+			duplicate = true
+			return nil
+		}
+
+		if err := appendDeviceEvent(tx, deviceEventSourceQueue, messageID, device, body); err != nil {
+			return err
+		}
+
+		_, err = upsertDevice(tx, deviceFromProto(device))
+		if errors.Is(err, errStaleDevice) {
+			stale = true
+			return nil
+		}
+		return err
+	})
+
+	return duplicate, stale, err
+}
+
+// upsertDevice creates dbDevice or updates the stored device with its
+// metadata, and records its location history when it is new or has moved.
+// It reports whether the device was created. dbDevice is overwritten with
+// the stored row; tx should be a transaction.
+//
+// Updates are optimistic: a message dated before the stored last_seen fails
+// with errStaleDevice, and an update only applies if the version read is
+// still stored, failing with errDeviceConflict if another transaction
+// changed the device in between.
+func upsertDevice(tx *gorm.DB, dbDevice *IoTDevice) (bool, error) {
+	// Unscoped so devices in the trash are updated in place instead of
+	// violating the unique device_id index; they stay in the trash until restored.
+	var previous []IoTDevice
+	if err := tx.Unscoped().Where("device_id = ?", dbDevice.DeviceID).Limit(1).Find(&previous).Error; err != nil {
+		return false, fmt.Errorf("failed to fetch device: %w", err)
+	}
+	// Compared before the upsert, which overwrites dbDevice with the stored row
+	location := &DeviceLocationHistory{
+		RecordedAt: dbDevice.LastSeen,
+		DeviceID:   dbDevice.DeviceID,
+		IPAddress:  dbDevice.IPAddress,
+		Latitude:   dbDevice.Latitude,
+		Longitude:  dbDevice.Longitude,
+	}
+	created := len(previous) == 0
+
+	var stored *IoTDevice
+	if !created {
+		stored = &previous[0]
+		if staleDevice(stored, dbDevice) {
+			return false, errStaleDevice
+		}
+	}
+	record := created || moved(stored, dbDevice)
+
+	if err := writeDevice(tx, stored, dbDevice); err != nil {
+		return false, err
+	}
 
+	if !record {
+		return created, nil
+	}
+	if err := tx.Create(location).Error; err != nil {
+		return false, fmt.Errorf("failed to record device location: %w", err)
+	}
+	return created, nil
+}
+
+// writeDevice creates device if stored is nil, or updates the stored device
+// with its metadata and bumps its version. The update fails with
+// errDeviceConflict unless the row is still at stored.Version. device is
+// overwritten with the stored row.
+func writeDevice(tx *gorm.DB, stored, device *IoTDevice) error {
+	if stored == nil {
+		device.Version = 1
+		if err := tx.Create(device).Error; err != nil {
+			return fmt.Errorf("failed to create device: %w", err)
+		}
+		return nil
+	}
+
+	columns := deviceColumns(device)
+	columns["version"] = stored.Version + 1
+	result := tx.Model(&IoTDevice{}).
+		Unscoped().
+		Where("id = ? AND version = ?", stored.ID, stored.Version).
+		Updates(columns)
 	if result.Error != nil {
-		return fmt.Errorf("failed to upsert device: %w", result.Error)
+		return fmt.Errorf("failed to update device: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errDeviceConflict
 	}
 
+	if err := tx.Unscoped().First(device, stored.ID).Error; err != nil {
+		return fmt.Errorf("failed to fetch device: %w", err)
+	}
 	return nil
 }
 
+// staleDevice reports whether a device message is older than the stored
+// device. Messages with the same timestamp are applied, so redelivering the
+// latest message is harmless.
+func staleDevice(stored, received *IoTDevice) bool {
+	return received.LastSeen.Before(stored.LastSeen)
+}
+
+// moved reports whether a device message changes the coordinates or IP
+// address stored for the device.
+func moved(stored, received *IoTDevice) bool {
+	return stored.Latitude != received.Latitude ||
+		stored.Longitude != received.Longitude ||
+		stored.IPAddress != received.IPAddress
+}
+
 // Stop stops the device consumer and closes the MQ client.
 func (c *DeviceConsumer) Stop() error {
 	c.logger.Info("stopping device consumer")
@@ -245,9 +405,16 @@ func (c *DeviceConsumer) Stop() error {
 		defer c.metrics.ActiveConsumers.Dec()
 	}
 
-	// Close MQ client
-	if err := c.mqClient.Close(); err != nil {
-		return fmt.Errorf("failed to close mq client: %w", err)
+	// Stop message processing
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	// Close the MQ client unless it is shared
+	if c.ownsMQ {
+		if err := c.mqClient.Close(); err != nil {
+			return fmt.Errorf("failed to close mq client: %w", err)
+		}
 	}
 
 	// Wait for message processing to complete
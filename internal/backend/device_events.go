@@ -0,0 +1,188 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+const (
+	// deviceEventSourceQueue marks events of device queue messages.
+	deviceEventSourceQueue = "queue"
+	// deviceEventSourceAPI marks events of CreateOrUpdateDevice calls.
+	deviceEventSourceAPI = "api"
+	// deviceEventBatchSize is the number of events read at a time on replay.
+	deviceEventBatchSize = 500
+)
+
+// appendDeviceEvent appends device, received from source, to the device
+// event log within tx. payload is the message as received; if it is nil,
+// device is serialized instead.
+func appendDeviceEvent(tx *gorm.DB, source, messageID string, device *iotv1.IoTDevice, payload []byte) error {
+	if payload == nil {
+		var err error
+		if payload, err = proto.Marshal(device); err != nil {
+			return fmt.Errorf("failed to encode device event: %w", err)
+		}
+	}
+
+	event := &DeviceEvent{
+		RecordedAt: time.Unix(device.GetTimestamp(), 0).UTC(),
+		Payload:    payload,
+		DeviceID:   device.GetDeviceId(),
+		Source:     source,
+		MessageID:  messageID,
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to record device event: %w", err)
+	}
+	return nil
+}
+
+// forEachDeviceEvent calls fn with the events of a device and their decoded
+// messages, in the order they were appended, until fn returns an error.
+func forEachDeviceEvent(db *gorm.DB, deviceID string, fn func(event *DeviceEvent, device *iotv1.IoTDevice) error) error {
+	var events []DeviceEvent
+	var fnErr error
+	err := db.Where("device_id = ?", deviceID).Order("id").FindInBatches(&events, deviceEventBatchSize, func(_ *gorm.DB, _ int) error {
+		for i := range events {
+			device := &iotv1.IoTDevice{}
+			if err := proto.Unmarshal(events[i].Payload, device); err != nil {
+				fnErr = fmt.Errorf("failed to decode device event %d: %w", events[i].ID, err)
+				return fnErr
+			}
+			if err := fn(&events[i], device); err != nil {
+				fnErr = err
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read device events: %w", err)
+	}
+	return nil
+}
+
+// ProjectionReport summarizes a device projection rebuild.
+type ProjectionReport struct {
+	Devices int // Devices with events
+	Events  int // Events read, including stale ones that were skipped
+	Changed int // Devices whose row differed from the replayed state, including missing rows
+}
+
+// RebuildDeviceProjection recomputes the iot_devices rows of all devices
+// with events by replaying their events in order, recreating missing rows.
+// Devices without events, last updated before the event log existed, are
+// left as they are, as are the trash state and the location history of
+// devices. Each device is rebuilt in a transaction holding its row locked,
+// so device messages arriving meanwhile are applied after the replay.
+func RebuildDeviceProjection(ctx context.Context, db *gorm.DB, logger *slog.Logger) (*ProjectionReport, error) {
+	var deviceIDs []string
+	if err := db.WithContext(ctx).Model(&DeviceEvent{}).Distinct("device_id").Order("device_id").Pluck("device_id", &deviceIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list devices with events: %w", err)
+	}
+
+	report := &ProjectionReport{}
+	for _, deviceID := range deviceIDs {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var stored []IoTDevice
+			if err := tx.Unscoped().
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("device_id = ?", deviceID).
+				Limit(1).
+				Find(&stored).Error; err != nil {
+				return fmt.Errorf("failed to fetch device: %w", err)
+			}
+
+			// Every event sets all fields, so the last one applied holds the
+			// state; stale events were not applied
+			var state *IoTDevice
+			err := forEachDeviceEvent(tx, deviceID, func(_ *DeviceEvent, device *iotv1.IoTDevice) error {
+				report.Events++
+				if next := deviceFromProto(device); state == nil || !staleDevice(state, next) {
+					state = next
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			var current *IoTDevice
+			if len(stored) > 0 {
+				current = &stored[0]
+				if sameDeviceState(current, state) {
+					return nil
+				}
+			}
+			report.Changed++
+
+			return writeDevice(tx, current, state)
+		})
+		if err != nil {
+			return report, fmt.Errorf("failed to rebuild device %s: %w", deviceID, err)
+		}
+		report.Devices++
+	}
+
+	logger.Info("device projection rebuilt",
+		"devices", report.Devices,
+		"events", report.Events,
+		"changed", report.Changed,
+	)
+	return report, nil
+}
+
+// RebuildDeviceProjection connects to the database and rebuilds the device
+// projection from the device event log. The schema must be current.
+func (s *Server) RebuildDeviceProjection(ctx context.Context) (*ProjectionReport, error) {
+	cfg := s.dbConfig()
+
+	db, err := NewDBContext(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = CloseDB(db, cfg.Logger) }()
+
+	return RebuildDeviceProjection(ctx, db, cfg.Logger)
+}
+
+// deviceColumns returns the columns a device message sets.
+func deviceColumns(device *IoTDevice) map[string]interface{} {
+	return map[string]interface{}{
+		"location":    device.Location,
+		"mac_address": device.MACAddress,
+		"ip_address":  device.IPAddress,
+		"firmware":    device.Firmware,
+		"last_seen":   device.LastSeen,
+		"latitude":    device.Latitude,
+		"longitude":   device.Longitude,
+	}
+}
+
+// sameDeviceState reports whether two devices have the same columns of
+// deviceColumns.
+func sameDeviceState(a, b *IoTDevice) bool {
+	return a.Location == b.Location &&
+		a.MACAddress == b.MACAddress &&
+		a.IPAddress == b.IPAddress &&
+		a.Firmware == b.Firmware &&
+		a.LastSeen.Equal(b.LastSeen) &&
+		a.Latitude == b.Latitude &&
+		a.Longitude == b.Longitude
+}
@@ -0,0 +1,186 @@
+package backend
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Device event log", func() {
+	var (
+		ctx     context.Context
+		logger  *slog.Logger
+		db      *gorm.DB
+		c       *DeviceConsumer
+		service *IoTServiceImpl
+		start   time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError,
+		}))
+
+		db = newTestDB(logger)
+
+		c = &DeviceConsumer{logger: logger, db: db, queueName: "events-test"}
+		service = &IoTServiceImpl{logger: logger, db: db}
+		start = time.Now().Add(-time.Hour).Truncate(time.Second)
+	})
+
+	// deliver delivers a device message for device, sent offset after start,
+	// and returns its body.
+	deliver := func(messageID string, offset time.Duration, device *iotv1.IoTDevice) []byte {
+		device.Timestamp = start.Add(offset).Unix()
+		body, err := proto.Marshal(device)
+		Expect(err).NotTo(HaveOccurred())
+
+		c.handleDelivery(ctx, amqp.Delivery{Acknowledger: &fakeAcknowledger{}, MessageId: messageID, Body: body})
+		return body
+	}
+
+	events := func() []DeviceEvent {
+		var events []DeviceEvent
+		Expect(db.Order("id").Find(&events).Error).To(Succeed())
+		return events
+	}
+
+	stored := func(deviceID string) IoTDevice {
+		var device IoTDevice
+		Expect(db.Unscoped().Where("device_id = ?", deviceID).First(&device).Error).To(Succeed())
+		return device
+	}
+
+	It("should append device messages as received, once", func() {
+		body := deliver("msg-1", 0, &iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Lab", Firmware: "v1.0.0"})
+		deliver("msg-1", 0, &iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Lab", Firmware: "v1.0.0"})
+
+		Expect(events()).To(HaveLen(1))
+		event := events()[0]
+		Expect(event.DeviceID).To(Equal("sensor-1"))
+		Expect(event.Source).To(Equal(deviceEventSourceQueue))
+		Expect(event.MessageID).To(Equal("msg-1"))
+		Expect(event.RecordedAt.Unix()).To(Equal(start.Unix()))
+		Expect(event.Payload).To(Equal(body))
+	})
+
+	It("should append devices saved through the API with the applied timestamp", func() {
+		_, err := service.CreateOrUpdateDevice(ctx, &iotv1.CreateOrUpdateDeviceRequest{
+			Device: &iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Lab"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(events()).To(HaveLen(1))
+		Expect(events()[0].Source).To(Equal(deviceEventSourceAPI))
+		Expect(events()[0].RecordedAt.Unix()).To(Equal(stored("sensor-1").LastSeen.Unix()))
+	})
+
+	Describe("GetDeviceHistory", func() {
+		history := func(limit int32) []*iotv1.DeviceChange {
+			resp, err := service.GetDeviceHistory(ctx, &iotv1.GetDeviceHistoryRequest{DeviceId: "sensor-1", Limit: limit})
+			Expect(err).NotTo(HaveOccurred())
+			return resp.GetChanges()
+		}
+
+		It("should return the firmware and location changes, oldest first", func() {
+			deliver("", 0, &iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Lab", Firmware: "v1.0.0"})
+			deliver("", time.Minute, &iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Lab", Firmware: "v1.0.0"})
+			deliver("", 2*time.Minute, &iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Lab", Firmware: "v1.1.0"})
+			deliver("", 3*time.Minute, &iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Office", Firmware: "v1.1.0", Latitude: 52.5})
+
+			changes := history(0)
+			Expect(changes).To(HaveLen(3))
+			Expect(changes[0].GetTimestamp()).To(Equal(start.Unix()))
+			Expect(changes[0].GetSource()).To(Equal("queue"))
+			Expect(changes[0].GetChangedFields()).To(BeEmpty())
+			Expect(changes[1].GetTimestamp()).To(Equal(start.Add(2 * time.Minute).Unix()))
+			Expect(changes[1].GetFirmware()).To(Equal("v1.1.0"))
+			Expect(changes[1].GetChangedFields()).To(Equal([]string{"firmware"}))
+			Expect(changes[2].GetLocation()).To(Equal("Office"))
+			Expect(changes[2].GetChangedFields()).To(Equal([]string{"location", "latitude"}))
+		})
+
+		It("should return the most recent changes up to the limit", func() {
+			for i := range 5 {
+				deliver("", time.Duration(i)*time.Minute, &iotv1.IoTDevice{DeviceId: "sensor-1", Firmware: string(rune('a' + i))})
+			}
+
+			changes := history(2)
+			Expect(changes).To(HaveLen(2))
+			Expect(changes[0].GetFirmware()).To(Equal("d"))
+			Expect(changes[1].GetFirmware()).To(Equal("e"))
+		})
+
+		It("should report unknown devices", func() {
+			_, err := service.GetDeviceHistory(ctx, &iotv1.GetDeviceHistoryRequest{DeviceId: "missing"})
+			Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonDeviceNotFound))
+		})
+	})
+
+	Describe("RebuildDeviceProjection", func() {
+		It("should replay the events of each device", func() {
+			deliver("", 0, &iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Lab", Firmware: "v1.0.0"})
+			deliver("", time.Minute, &iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Office", Firmware: "v1.1.0"})
+			deliver("", time.Minute, &iotv1.IoTDevice{DeviceId: "sensor-2", Location: "Lab", Firmware: "v2.0.0"})
+			Expect(db.Create(&IoTDevice{DeviceID: "legacy", Location: "Basement", LastSeen: start}).Error).To(Succeed())
+
+			// Lose one device and corrupt another
+			Expect(db.Model(&IoTDevice{}).Where("device_id = ?", "sensor-1").Update("firmware", "tampered").Error).To(Succeed())
+			Expect(db.Unscoped().Where("device_id = ?", "sensor-2").Delete(&IoTDevice{}).Error).To(Succeed())
+
+			report, err := RebuildDeviceProjection(ctx, db, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*report).To(Equal(ProjectionReport{Devices: 2, Events: 3, Changed: 2}))
+
+			device := stored("sensor-1")
+			Expect(device.Location).To(Equal("Office"))
+			Expect(device.Firmware).To(Equal("v1.1.0"))
+			Expect(device.LastSeen.Unix()).To(Equal(start.Add(time.Minute).Unix()))
+			Expect(stored("sensor-2").Firmware).To(Equal("v2.0.0"))
+			Expect(stored("legacy").Location).To(Equal("Basement"))
+
+			// A rebuilt projection is left as it is
+			report, err = RebuildDeviceProjection(ctx, db, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Changed).To(BeZero())
+		})
+
+		It("should skip stale events like the consumer", func() {
+			deliver("", time.Minute, &iotv1.IoTDevice{DeviceId: "sensor-1", Firmware: "v1.1.0"})
+			deliver("", 0, &iotv1.IoTDevice{DeviceId: "sensor-1", Firmware: "v1.0.0"})
+			Expect(events()).To(HaveLen(2))
+			Expect(stored("sensor-1").Firmware).To(Equal("v1.1.0"))
+
+			report, err := RebuildDeviceProjection(ctx, db, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*report).To(Equal(ProjectionReport{Devices: 1, Events: 2}))
+
+			resp, err := service.GetDeviceHistory(ctx, &iotv1.GetDeviceHistoryRequest{DeviceId: "sensor-1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.GetChanges()).To(HaveLen(1))
+		})
+
+		It("should keep devices in the trash", func() {
+			deliver("", 0, &iotv1.IoTDevice{DeviceId: "sensor-1", Firmware: "v1.0.0"})
+			Expect(db.Where("device_id = ?", "sensor-1").Delete(&IoTDevice{}).Error).To(Succeed())
+			Expect(db.Unscoped().Model(&IoTDevice{}).Where("device_id = ?", "sensor-1").Update("firmware", "tampered").Error).To(Succeed())
+
+			_, err := RebuildDeviceProjection(ctx, db, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			device := stored("sensor-1")
+			Expect(device.Firmware).To(Equal("v1.0.0"))
+			Expect(device.DeletedAt.Valid).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,110 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+const (
+	// defaultDeviceHistoryLimit is used when GetDeviceHistory does not set limit.
+	defaultDeviceHistoryLimit = 100
+	// maxDeviceHistoryLimit bounds limit of GetDeviceHistory.
+	maxDeviceHistoryLimit = 1000
+)
+
+// GetDeviceHistory replays the event log of a device and returns the most
+// recent messages that changed its firmware, location or network address,
+// oldest first. Messages that only report the device as seen are left out,
+// as are stale ones.
+func (s *IoTServiceImpl) GetDeviceHistory(ctx context.Context, req *iotv1.GetDeviceHistoryRequest) (resp *iotv1.GetDeviceHistoryResponse, err error) {
+	done := s.trackRequest("GetDeviceHistory")
+	defer func() { done(err) }()
+
+	if req.GetDeviceId() == "" {
+		return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "device_id", "cannot be empty")
+	}
+
+	limit := int(req.GetLimit())
+	switch {
+	case limit == 0:
+		limit = defaultDeviceHistoryLimit
+	case limit < 0 || limit > maxDeviceHistoryLimit:
+		return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "limit", fmt.Sprintf("must be between 1 and %d", maxDeviceHistoryLimit))
+	}
+
+	if err := s.db.WithContext(ctx).Where("device_id = ?", req.GetDeviceId()).First(&IoTDevice{}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, iotv1.DeviceNotFoundError(req.GetDeviceId())
+		}
+		s.logger.Error("failed to fetch device", "device_id", req.GetDeviceId(), "error", err)
+		return nil, databaseError("failed to fetch device")
+	}
+
+	var (
+		changes  []*iotv1.DeviceChange
+		previous *iotv1.IoTDevice
+	)
+	err = forEachDeviceEvent(s.db.WithContext(ctx), req.GetDeviceId(), func(event *DeviceEvent, device *iotv1.IoTDevice) error {
+		var fields []string
+		if previous != nil {
+			// Stale messages were not applied to the device
+			if device.GetTimestamp() < previous.GetTimestamp() {
+				return nil
+			}
+			fields = changedDeviceFields(previous, device)
+			if len(fields) == 0 {
+				previous = device
+				return nil
+			}
+		}
+		previous = device
+
+		changes = append(changes, &iotv1.DeviceChange{
+			Timestamp:     device.GetTimestamp(),
+			Source:        event.Source,
+			Location:      device.GetLocation(),
+			MacAddress:    device.GetMacAddress(),
+			IpAddress:     device.GetIpAddress(),
+			Firmware:      device.GetFirmware(),
+			Latitude:      device.GetLatitude(),
+			Longitude:     device.GetLongitude(),
+			ChangedFields: fields,
+		})
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("failed to replay device events", "device_id", req.GetDeviceId(), "error", err)
+		return nil, databaseError("failed to fetch device history")
+	}
+
+	return &iotv1.GetDeviceHistoryResponse{
+		Changes: changes[max(len(changes)-limit, 0):],
+	}, nil
+}
+
+// changedDeviceFields returns the names of the metadata fields that differ
+// between two messages of a device.
+func changedDeviceFields(previous, device *iotv1.IoTDevice) []string {
+	var fields []string
+	for _, f := range []struct {
+		name    string
+		changed bool
+	}{
+		{"location", previous.GetLocation() != device.GetLocation()},
+		{"mac_address", previous.GetMacAddress() != device.GetMacAddress()},
+		{"ip_address", previous.GetIpAddress() != device.GetIpAddress()},
+		{"firmware", previous.GetFirmware() != device.GetFirmware()},
+		{"latitude", previous.GetLatitude() != device.GetLatitude()},
+		{"longitude", previous.GetLongitude() != device.GetLongitude()},
+	} {
+		if f.changed {
+			fields = append(fields, f.name)
+		}
+	}
+	return fields
+}
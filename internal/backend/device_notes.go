@@ -0,0 +1,208 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/codes"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+const (
+	// maxNoteAuthorLength bounds the author field of a device note.
+	maxNoteAuthorLength = 100
+	// maxNoteBodyLength bounds the free text of a device note.
+	maxNoteBodyLength = 4000
+	// maxNoteAttachmentURLLength bounds the attachment URL of a device note.
+	maxNoteAttachmentURLLength = 2048
+)
+
+// ListDeviceNotes returns the notes of a device, newest first.
+func (s *IoTServiceImpl) ListDeviceNotes(ctx context.Context, req *iotv1.ListDeviceNotesRequest) (resp *iotv1.ListDeviceNotesResponse, err error) {
+	done := s.trackRequest("ListDeviceNotes")
+	defer func() { done(err) }()
+
+	if req.GetDeviceId() == "" {
+		return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "device_id", "cannot be empty")
+	}
+
+	var notes []DeviceNote
+	if err := s.db.WithContext(ctx).
+		Where("device_id = ?", req.GetDeviceId()).
+		Order("created_at DESC").
+		Order("id DESC").
+		Find(&notes).Error; err != nil {
+		s.logger.Error("failed to fetch device notes", "device_id", req.GetDeviceId(), "error", err)
+		return nil, databaseError("failed to fetch device notes")
+	}
+
+	protoNotes := make([]*iotv1.DeviceNote, len(notes))
+	for i := range notes {
+		protoNotes[i] = deviceNoteToProto(&notes[i])
+	}
+
+	return &iotv1.ListDeviceNotesResponse{
+		Notes: protoNotes,
+	}, nil
+}
+
+// CreateDeviceNote validates and stores a new note for an existing device.
+func (s *IoTServiceImpl) CreateDeviceNote(ctx context.Context, req *iotv1.CreateDeviceNoteRequest) (resp *iotv1.CreateDeviceNoteResponse, err error) {
+	done := s.trackRequest("CreateDeviceNote")
+	defer func() { done(err) }()
+
+	if err := validateDeviceNote(req.GetNote(), true); err != nil {
+		return nil, err
+	}
+
+	deviceID := req.GetNote().GetDeviceId()
+	if err := s.db.WithContext(ctx).Where("device_id = ?", deviceID).First(&IoTDevice{}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, iotv1.DeviceNotFoundError(deviceID)
+		}
+		s.logger.Error("failed to fetch device", "device_id", deviceID, "error", err)
+		return nil, databaseError("failed to fetch device")
+	}
+
+	note := &DeviceNote{DeviceID: deviceID}
+	applyDeviceNote(note, req.GetNote())
+
+	if err := s.db.WithContext(ctx).Create(note).Error; err != nil {
+		s.logger.Error("failed to create device note", "device_id", deviceID, "error", err)
+		return nil, databaseError("failed to create device note")
+	}
+
+	s.logger.Info("created device note", "id", note.ID, "device_id", deviceID)
+
+	return &iotv1.CreateDeviceNoteResponse{
+		Note: deviceNoteToProto(note),
+	}, nil
+}
+
+// UpdateDeviceNote replaces the editable fields of an existing note.
+func (s *IoTServiceImpl) UpdateDeviceNote(ctx context.Context, req *iotv1.UpdateDeviceNoteRequest) (resp *iotv1.UpdateDeviceNoteResponse, err error) {
+	done := s.trackRequest("UpdateDeviceNote")
+	defer func() { done(err) }()
+
+	if err := validateDeviceNote(req.GetNote(), false); err != nil {
+		return nil, err
+	}
+
+	var note DeviceNote
+	if err := s.db.WithContext(ctx).First(&note, req.GetNote().GetId()).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, iotv1.DeviceNoteNotFoundError(req.GetNote().GetId())
+		}
+		s.logger.Error("failed to fetch device note", "id", req.GetNote().GetId(), "error", err)
+		return nil, databaseError("failed to fetch device note")
+	}
+
+	applyDeviceNote(&note, req.GetNote())
+
+	if err := s.db.WithContext(ctx).Save(&note).Error; err != nil {
+		s.logger.Error("failed to update device note", "id", note.ID, "error", err)
+		return nil, databaseError("failed to update device note")
+	}
+
+	s.logger.Info("updated device note", "id", note.ID, "device_id", note.DeviceID)
+
+	return &iotv1.UpdateDeviceNoteResponse{
+		Note: deviceNoteToProto(&note),
+	}, nil
+}
+
+// DeleteDeviceNote removes a note.
+func (s *IoTServiceImpl) DeleteDeviceNote(ctx context.Context, req *iotv1.DeleteDeviceNoteRequest) (resp *iotv1.DeleteDeviceNoteResponse, err error) {
+	done := s.trackRequest("DeleteDeviceNote")
+	defer func() { done(err) }()
+
+	result := s.db.WithContext(ctx).Delete(&DeviceNote{}, req.GetId())
+	if result.Error != nil {
+		s.logger.Error("failed to delete device note", "id", req.GetId(), "error", result.Error)
+		return nil, databaseError("failed to delete device note")
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, iotv1.DeviceNoteNotFoundError(req.GetId())
+	}
+
+	s.logger.Info("deleted device note", "id", req.GetId())
+
+	return &iotv1.DeleteDeviceNoteResponse{}, nil
+}
+
+// validateDeviceNote checks the user-editable fields of a device note.
+// The device ID is only required on create since notes cannot be moved.
+func validateDeviceNote(note *iotv1.DeviceNote, create bool) error {
+	if note == nil {
+		return iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "note", "cannot be empty")
+	}
+
+	var violations []iotv1.FieldViolation
+
+	if create && note.GetDeviceId() == "" {
+		violations = append(violations, iotv1.FieldViolation{Field: "device_id", Description: "cannot be empty"})
+	}
+
+	switch author := strings.TrimSpace(note.GetAuthor()); {
+	case author == "":
+		violations = append(violations, iotv1.FieldViolation{Field: "author", Description: "cannot be empty"})
+	case utf8.RuneCountInString(author) > maxNoteAuthorLength:
+		violations = append(violations, iotv1.FieldViolation{Field: "author", Description: "is too long"})
+	}
+
+	switch body := strings.TrimSpace(note.GetBody()); {
+	case body == "":
+		violations = append(violations, iotv1.FieldViolation{Field: "body", Description: "cannot be empty"})
+	case utf8.RuneCountInString(body) > maxNoteBodyLength:
+		violations = append(violations, iotv1.FieldViolation{Field: "body", Description: "is too long"})
+	}
+
+	if raw := strings.TrimSpace(note.GetAttachmentUrl()); raw != "" {
+		if len(raw) > maxNoteAttachmentURLLength || !isWebURL(raw) {
+			violations = append(violations, iotv1.FieldViolation{Field: "attachment_url", Description: "must be an http or https URL"})
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return iotv1.NewError(codes.InvalidArgument, iotv1.ReasonInvalidArgument, "invalid device note", nil, violations...)
+}
+
+// isWebURL reports whether raw is an absolute http or https URL. Other schemes
+// are rejected since attachment links are rendered as clickable links.
+func isWebURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// applyDeviceNote copies the editable fields of a proto note onto the model.
+func applyDeviceNote(note *DeviceNote, in *iotv1.DeviceNote) {
+	note.Author = strings.TrimSpace(in.GetAuthor())
+	note.Body = strings.TrimSpace(in.GetBody())
+	note.AttachmentURL = strings.TrimSpace(in.GetAttachmentUrl())
+}
+
+// deviceNoteToProto converts a device note model to its proto message.
+func deviceNoteToProto(note *DeviceNote) *iotv1.DeviceNote {
+	return &iotv1.DeviceNote{
+		Id:            uint64(note.ID),
+		DeviceId:      note.DeviceID,
+		Author:        note.Author,
+		Body:          note.Body,
+		AttachmentUrl: note.AttachmentURL,
+		CreatedAt:     note.CreatedAt.Unix(),
+		UpdatedAt:     note.UpdatedAt.Unix(),
+	}
+}
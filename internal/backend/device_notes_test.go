@@ -0,0 +1,77 @@
+package backend
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Device notes", func() {
+	validNote := func() *iotv1.DeviceNote {
+		return &iotv1.DeviceNote{
+			DeviceId:      "device-001",
+			Author:        "Sam Rivera",
+			Body:          "Replaced battery pack",
+			AttachmentUrl: "https://example.com/reports/42.pdf",
+		}
+	}
+
+	Describe("validateDeviceNote", func() {
+		It("should accept a valid note", func() {
+			Expect(validateDeviceNote(validNote(), true)).To(Succeed())
+		})
+
+		It("should report every invalid field", func() {
+			note := &iotv1.DeviceNote{Author: "  ", AttachmentUrl: "javascript:alert(1)"}
+
+			err := validateDeviceNote(note, true)
+
+			Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonInvalidArgument))
+			Expect(iotv1.FieldViolations(err)).To(ConsistOf(
+				HaveField("Field", "device_id"),
+				HaveField("Field", "author"),
+				HaveField("Field", "body"),
+				HaveField("Field", "attachment_url"),
+			))
+		})
+
+		It("should only require the device ID on create", func() {
+			note := validNote()
+			note.DeviceId = ""
+
+			Expect(validateDeviceNote(note, false)).To(Succeed())
+		})
+
+		It("should bound field lengths", func() {
+			note := validNote()
+			note.Body = strings.Repeat("x", maxNoteBodyLength+1)
+
+			Expect(iotv1.FieldViolations(validateDeviceNote(note, true))).To(ConsistOf(HaveField("Field", "body")))
+		})
+
+		It("should only accept absolute http and https attachment URLs", func() {
+			Expect(isWebURL("https://example.com/a.jpg")).To(BeTrue())
+			Expect(isWebURL("http://example.com")).To(BeTrue())
+			Expect(isWebURL("ftp://example.com/a.jpg")).To(BeFalse())
+			Expect(isWebURL("/relative/path")).To(BeFalse())
+			Expect(isWebURL("https://")).To(BeFalse())
+		})
+	})
+
+	It("should round-trip notes through the model", func() {
+		in := validNote()
+		in.Author = " Sam Rivera "
+
+		model := &DeviceNote{ID: 3, DeviceID: in.GetDeviceId()}
+		applyDeviceNote(model, in)
+		out := deviceNoteToProto(model)
+
+		Expect(out.GetId()).To(Equal(uint64(3)))
+		Expect(out.GetDeviceId()).To(Equal("device-001"))
+		Expect(out.GetAuthor()).To(Equal("Sam Rivera"))
+		Expect(out.GetAttachmentUrl()).To(Equal("https://example.com/reports/42.pdf"))
+	})
+})
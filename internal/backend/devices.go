@@ -0,0 +1,204 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// CreateOrUpdateDevice registers a device or updates its metadata, like a
+// device queue message. Producers call it before publishing readings, which
+// then never arrive for an unknown device.
+func (s *IoTServiceImpl) CreateOrUpdateDevice(ctx context.Context, req *iotv1.CreateOrUpdateDeviceRequest) (resp *iotv1.CreateOrUpdateDeviceResponse, err error) {
+	done := s.trackRequest("CreateOrUpdateDevice")
+	defer func() { done(err) }()
+
+	if err := validateDevice(req.GetDevice()); err != nil {
+		return nil, err
+	}
+
+	// The event records the message as applied, with the timestamp it got
+	event := proto.Clone(req.GetDevice()).(*iotv1.IoTDevice)
+	if event.GetTimestamp() == 0 {
+		event.Timestamp = time.Now().Unix()
+	}
+	device := deviceFromProto(event)
+
+	var created bool
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := appendDeviceEvent(tx, deviceEventSourceAPI, "", event, nil); err != nil {
+			return err
+		}
+
+		var err error
+		created, err = upsertDevice(tx, device)
+		return err
+	})
+	switch {
+	case errors.Is(err, errStaleDevice):
+		if s.metrics != nil {
+			s.metrics.StaleDeviceUpdates.WithLabelValues(deviceEventSourceAPI).Inc()
+		}
+		return nil, iotv1.NewError(codes.FailedPrecondition, iotv1.ReasonStaleDeviceUpdate,
+			"device timestamp is older than the stored last_seen",
+			map[string]string{"device_id": device.DeviceID})
+	case errors.Is(err, errDeviceConflict):
+		return nil, iotv1.NewError(codes.Aborted, iotv1.ReasonDeviceUpdateConflict,
+			"device was updated concurrently, retry the call",
+			map[string]string{"device_id": device.DeviceID})
+	case err != nil:
+		s.logger.Error("failed to save device", "device_id", device.DeviceID, "error", err)
+		return nil, databaseError("failed to save device")
+	}
+
+	s.logger.Debug("device saved", "device_id", device.DeviceID, "created", created)
+
+	return &iotv1.CreateOrUpdateDeviceResponse{Device: deviceToProto(device), Created: created}, nil
+}
+
+// validateDevice checks the device ID and the coordinates of a device. The
+// coordinates have no validation rules since the rules do not cover floats.
+func validateDevice(device *iotv1.IoTDevice) error {
+	var violations []iotv1.FieldViolation
+
+	if device.GetDeviceId() == "" {
+		violations = append(violations, iotv1.FieldViolation{Field: "device.device_id", Description: "cannot be empty"})
+	}
+
+	if lat := device.GetLatitude(); lat < -90 || lat > 90 {
+		violations = append(violations, iotv1.FieldViolation{Field: "device.latitude", Description: "must be between -90 and 90"})
+	}
+
+	if lon := device.GetLongitude(); lon < -180 || lon > 180 {
+		violations = append(violations, iotv1.FieldViolation{Field: "device.longitude", Description: "must be between -180 and 180"})
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return iotv1.NewError(codes.InvalidArgument, iotv1.ReasonInvalidArgument, "invalid device", nil, violations...)
+}
+
+// DeleteDevice moves a device to the trash. The row and its readings are kept
+// so the device can be restored with RestoreDevice.
+func (s *IoTServiceImpl) DeleteDevice(ctx context.Context, req *iotv1.DeleteDeviceRequest) (resp *iotv1.DeleteDeviceResponse, err error) {
+	done := s.trackRequest("DeleteDevice")
+	defer func() { done(err) }()
+
+	if req.GetDeviceId() == "" {
+		return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "device_id", "cannot be empty")
+	}
+
+	result := s.db.WithContext(ctx).Where("device_id = ?", req.GetDeviceId()).Delete(&IoTDevice{})
+	if result.Error != nil {
+		s.logger.Error("failed to delete device", "device_id", req.GetDeviceId(), "error", result.Error)
+		return nil, databaseError("failed to delete device")
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, iotv1.DeviceNotFoundError(req.GetDeviceId())
+	}
+
+	s.logger.Info("moved device to trash", "device_id", req.GetDeviceId())
+
+	return &iotv1.DeleteDeviceResponse{}, nil
+}
+
+// RestoreDevice takes a device out of the trash.
+func (s *IoTServiceImpl) RestoreDevice(ctx context.Context, req *iotv1.RestoreDeviceRequest) (resp *iotv1.RestoreDeviceResponse, err error) {
+	done := s.trackRequest("RestoreDevice")
+	defer func() { done(err) }()
+
+	if req.GetDeviceId() == "" {
+		return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "device_id", "cannot be empty")
+	}
+
+	result := s.db.WithContext(ctx).Unscoped().
+		Model(&IoTDevice{}).
+		Where("device_id = ? AND deleted_at IS NOT NULL", req.GetDeviceId()).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		s.logger.Error("failed to restore device", "device_id", req.GetDeviceId(), "error", result.Error)
+		return nil, databaseError("failed to restore device")
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, iotv1.DeviceNotFoundError(req.GetDeviceId())
+	}
+
+	var device IoTDevice
+	if err := s.db.WithContext(ctx).Where("device_id = ?", req.GetDeviceId()).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, iotv1.DeviceNotFoundError(req.GetDeviceId())
+		}
+		s.logger.Error("failed to fetch restored device", "device_id", req.GetDeviceId(), "error", err)
+		return nil, databaseError("failed to fetch device")
+	}
+
+	s.logger.Info("restored device from trash", "device_id", req.GetDeviceId())
+
+	return &iotv1.RestoreDeviceResponse{Device: deviceToProto(&device)}, nil
+}
+
+// ListDeletedDevices returns the devices in the trash, most recently deleted first.
+func (s *IoTServiceImpl) ListDeletedDevices(ctx context.Context, _ *iotv1.ListDeletedDevicesRequest) (resp *iotv1.ListDeletedDevicesResponse, err error) {
+	done := s.trackRequest("ListDeletedDevices")
+	defer func() { done(err) }()
+
+	var devices []IoTDevice
+	if err := s.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&devices).Error; err != nil {
+		s.logger.Error("failed to fetch deleted devices", "error", err)
+		return nil, databaseError("failed to fetch deleted devices")
+	}
+
+	protoDevices := make([]*iotv1.IoTDevice, len(devices))
+	for i := range devices {
+		protoDevices[i] = deviceToProto(&devices[i])
+	}
+
+	return &iotv1.ListDeletedDevicesResponse{Devices: protoDevices}, nil
+}
+
+// deviceFromProto converts a device message to the database model.
+func deviceFromProto(device *iotv1.IoTDevice) *IoTDevice {
+	return &IoTDevice{
+		DeviceID:   device.GetDeviceId(),
+		Location:   device.GetLocation(),
+		MACAddress: device.GetMacAddress(),
+		IPAddress:  device.GetIpAddress(),
+		Firmware:   device.GetFirmware(),
+		LastSeen:   time.Unix(device.GetTimestamp(), 0).UTC(),
+		Latitude:   device.GetLatitude(),
+		Longitude:  device.GetLongitude(),
+	}
+}
+
+// deviceToProto converts a device model to its proto message.
+func deviceToProto(device *IoTDevice) *iotv1.IoTDevice {
+	protoDevice := &iotv1.IoTDevice{
+		DeviceId:   device.DeviceID,
+		Timestamp:  device.LastSeen.Unix(),
+		Location:   device.Location,
+		MacAddress: device.MACAddress,
+		IpAddress:  device.IPAddress,
+		Firmware:   device.Firmware,
+		Latitude:   device.Latitude,
+		Longitude:  device.Longitude,
+	}
+
+	if device.DeletedAt.Valid {
+		protoDevice.DeletedAt = device.DeletedAt.Time.Unix()
+	}
+
+	return protoDevice
+}
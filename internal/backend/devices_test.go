@@ -0,0 +1,130 @@
+package backend
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("deviceToProto", func() {
+	lastSeen := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	It("should leave deleted_at unset for active devices", func() {
+		dev := deviceToProto(&IoTDevice{DeviceID: "sensor-1", LastSeen: lastSeen, Firmware: "v1.2.0"})
+
+		Expect(dev.GetDeviceId()).To(Equal("sensor-1"))
+		Expect(dev.GetTimestamp()).To(Equal(lastSeen.Unix()))
+		Expect(dev.GetFirmware()).To(Equal("v1.2.0"))
+		Expect(dev.GetDeletedAt()).To(BeZero())
+	})
+
+	It("should report when a device was deleted", func() {
+		deletedAt := lastSeen.Add(time.Hour)
+		dev := deviceToProto(&IoTDevice{
+			DeviceID:  "sensor-1",
+			DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true},
+		})
+
+		Expect(dev.GetDeletedAt()).To(Equal(deletedAt.Unix()))
+	})
+})
+
+var _ = Describe("CreateOrUpdateDevice", func() {
+	var (
+		ctx     context.Context
+		db      *gorm.DB
+		service *IoTServiceImpl
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError,
+		}))
+
+		db = newTestDB(logger)
+
+		service = &IoTServiceImpl{logger: logger, db: db}
+	})
+
+	register := func(device *iotv1.IoTDevice) *iotv1.CreateOrUpdateDeviceResponse {
+		resp, err := service.CreateOrUpdateDevice(ctx, &iotv1.CreateOrUpdateDeviceRequest{Device: device})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should create a device and then update it", func() {
+		seen := time.Now().Add(-time.Minute).Truncate(time.Second)
+		resp := register(&iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Lab", Firmware: "v1.0.0", Timestamp: seen.Unix()})
+		Expect(resp.GetCreated()).To(BeTrue())
+		Expect(resp.GetDevice().GetTimestamp()).To(Equal(seen.Unix()))
+
+		resp = register(&iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Office", Firmware: "v1.1.0", Timestamp: seen.Unix()})
+		Expect(resp.GetCreated()).To(BeFalse())
+		Expect(resp.GetDevice().GetLocation()).To(Equal("Office"))
+		Expect(resp.GetDevice().GetFirmware()).To(Equal("v1.1.0"))
+
+		var count int64
+		Expect(db.Model(&IoTDevice{}).Count(&count).Error).To(Succeed())
+		Expect(count).To(Equal(int64(1)))
+	})
+
+	It("should treat a zero timestamp as now", func() {
+		resp := register(&iotv1.IoTDevice{DeviceId: "sensor-1"})
+		Expect(time.Unix(resp.GetDevice().GetTimestamp(), 0)).To(BeTemporally("~", time.Now(), 5*time.Second))
+	})
+
+	It("should record the location of a new device", func() {
+		register(&iotv1.IoTDevice{DeviceId: "sensor-1", Latitude: 52.5, Longitude: 13.4})
+
+		var history []DeviceLocationHistory
+		Expect(db.Where("device_id = ?", "sensor-1").Find(&history).Error).To(Succeed())
+		Expect(history).To(HaveLen(1))
+		Expect(history[0].Latitude).To(BeNumerically("~", 52.5))
+	})
+
+	It("should reject devices older than the stored one", func() {
+		seen := time.Now().Truncate(time.Second)
+		register(&iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Lab", Timestamp: seen.Unix()})
+
+		_, err := service.CreateOrUpdateDevice(ctx, &iotv1.CreateOrUpdateDeviceRequest{
+			Device: &iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Office", Timestamp: seen.Add(-time.Minute).Unix()},
+		})
+		Expect(status.Code(err)).To(Equal(codes.FailedPrecondition))
+		Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonStaleDeviceUpdate))
+
+		var device IoTDevice
+		Expect(db.First(&device, "device_id = ?", "sensor-1").Error).To(Succeed())
+		Expect(device.Location).To(Equal("Lab"))
+	})
+
+	It("should reject coordinates out of range", func() {
+		_, err := service.CreateOrUpdateDevice(ctx, &iotv1.CreateOrUpdateDeviceRequest{
+			Device: &iotv1.IoTDevice{DeviceId: "sensor-1", Latitude: 91, Longitude: -181},
+		})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+
+		violations := iotv1.FieldViolations(err)
+		Expect(violations).To(ConsistOf(
+			iotv1.FieldViolation{Field: "device.latitude", Description: "must be between -90 and 90"},
+			iotv1.FieldViolation{Field: "device.longitude", Description: "must be between -180 and 180"},
+		))
+	})
+
+	It("should reject devices without an ID", func() {
+		_, err := service.CreateOrUpdateDevice(ctx, &iotv1.CreateOrUpdateDeviceRequest{Device: &iotv1.IoTDevice{}})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+
+		_, err = service.CreateOrUpdateDevice(ctx, &iotv1.CreateOrUpdateDeviceRequest{})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+	})
+})
@@ -0,0 +1,206 @@
+package backend
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/pkg/objectstore"
+)
+
+// exportBatchSize is how many readings ExportReadings fetches per query. The
+// database connection is released between batches while parts are uploaded.
+const exportBatchSize = 5000
+
+// ExportConfig configures exports of readings and reports to object storage.
+// Exports are disabled when Store is nil.
+type ExportConfig struct {
+	Store  objectstore.Store
+	Prefix string // Key prefix of exported objects, such as "exports/" (optional)
+}
+
+// exportedReading is one line of an NDJSON export.
+type exportedReading struct {
+	DeviceID     string    `json:"device_id"`
+	Timestamp    time.Time `json:"timestamp"`
+	Temperature  float64   `json:"temperature"`
+	Humidity     float64   `json:"humidity"`
+	Pressure     float64   `json:"pressure"`
+	BatteryLevel float64   `json:"battery_level"`
+}
+
+// countingWriter counts the bytes written to the underlying object.
+type countingWriter struct {
+	objectstore.Writer
+	n int64
+}
+
+// Write writes p to the object.
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// ExportReadings streams the readings of a time window as gzip-compressed
+// NDJSON to the export bucket, one reading per line in insertion order.
+// Readings of deleted devices are left out.
+func (s *IoTServiceImpl) ExportReadings(ctx context.Context, req *iotv1.ExportReadingsRequest) (resp *iotv1.ExportReadingsResponse, err error) {
+	done := s.trackRequest("ExportReadings")
+	defer func() { done(err) }()
+
+	if s.exports.Store == nil {
+		return nil, iotv1.NewError(codes.FailedPrecondition, iotv1.ReasonExportNotConfigured, "exports are not configured", nil)
+	}
+
+	start := time.Unix(req.GetStartTime(), 0)
+	end := time.Now()
+	if req.GetEndTime() != 0 {
+		end = time.Unix(req.GetEndTime(), 0)
+	}
+
+	if err := validateExportRequest(req.GetDeviceIds(), start, end); err != nil {
+		return nil, err
+	}
+
+	key, err := exportKey(s.exports.Prefix+"readings/", time.Now())
+	if err != nil {
+		s.logger.Error("failed to generate export key", "error", err)
+		return nil, iotv1.NewError(codes.Internal, iotv1.ReasonInternal, "failed to generate export key", nil)
+	}
+
+	w, err := s.exports.Store.NewWriter(ctx, key, objectstore.WriteOptions{
+		ContentType:     "application/x-ndjson",
+		ContentEncoding: "gzip",
+	})
+	if err != nil {
+		s.logger.Error("failed to start export", "key", key, "error", err)
+		return nil, exportError()
+	}
+
+	object := &countingWriter{Writer: w}
+	readings, err := s.writeReadings(ctx, object, req.GetDeviceIds(), start, end)
+	if err != nil {
+		if abortErr := object.Abort(); abortErr != nil {
+			s.logger.Warn("failed to abort export", "key", key, "error", abortErr)
+		}
+		return nil, err
+	}
+
+	// Close discards the uploaded parts itself when completing fails
+	if err := object.Close(); err != nil {
+		s.logger.Error("failed to complete export", "key", key, "error", err)
+		return nil, exportError()
+	}
+
+	s.logger.Info("exported readings", "key", key, "readings", readings, "bytes", object.n)
+
+	return &iotv1.ExportReadingsResponse{
+		ObjectUrl: s.exports.Store.URL(key),
+		Readings:  readings,
+		Bytes:     object.n,
+	}, nil
+}
+
+// writeReadings writes the matching readings to w as gzip-compressed NDJSON
+// and returns how many were written. Readings are paged by ID so the query
+// never holds a connection while data is uploaded.
+func (s *IoTServiceImpl) writeReadings(ctx context.Context, w *countingWriter, deviceIDs []string, start, end time.Time) (int64, error) {
+	zw := gzip.NewWriter(w)
+	enc := json.NewEncoder(zw)
+
+	active := s.db.Model(&IoTDevice{}).Select("device_id")
+	if len(deviceIDs) > 0 {
+		active = active.Where("device_id IN ?", deviceIDs)
+	}
+
+	var (
+		written int64
+		lastID  uint
+		batch   []SensorReading
+	)
+	for {
+		batch = batch[:0]
+		if err := s.db.WithContext(ctx).
+			Where("device_id IN (?)", active).
+			Where("timestamp >= ? AND timestamp < ?", start, end).
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(exportBatchSize).
+			Find(&batch).Error; err != nil {
+			s.logger.Error("failed to fetch readings for export", "error", err)
+			return written, databaseError("failed to fetch readings")
+		}
+
+		for i := range batch {
+			r := &batch[i]
+			if err := enc.Encode(exportedReading{
+				DeviceID:     r.DeviceID,
+				Timestamp:    r.Timestamp.UTC(),
+				Temperature:  r.Temperature,
+				Humidity:     r.Humidity,
+				Pressure:     r.Pressure,
+				BatteryLevel: r.BatteryLevel,
+			}); err != nil {
+				s.logger.Error("failed to write export", "error", err)
+				return written, exportError()
+			}
+			written++
+		}
+
+		if len(batch) < exportBatchSize {
+			break
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+
+	if err := zw.Close(); err != nil {
+		s.logger.Error("failed to write export", "error", err)
+		return written, exportError()
+	}
+
+	return written, nil
+}
+
+// validateExportRequest checks the devices and window of an export request.
+func validateExportRequest(deviceIDs []string, start, end time.Time) error {
+	var violations []iotv1.FieldViolation
+
+	if slices.Contains(deviceIDs, "") {
+		violations = append(violations, iotv1.FieldViolation{Field: "device_ids", Description: "cannot contain empty IDs"})
+	}
+
+	if !start.Before(end) {
+		violations = append(violations, iotv1.FieldViolation{Field: "start_time", Description: "must be before end_time"})
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return iotv1.NewError(codes.InvalidArgument, iotv1.ReasonInvalidArgument, "invalid export request", nil, violations...)
+}
+
+// exportKey returns a unique object key under prefix for an export started at t.
+func exportKey(prefix string, t time.Time) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s%s-%s.ndjson.gz", prefix, t.UTC().Format("20060102T150405Z"), hex.EncodeToString(suffix)), nil
+}
+
+// exportError returns an Unavailable error for a failed upload. The underlying
+// error is logged by the caller; the export can be retried.
+func exportError() error {
+	return iotv1.NewError(codes.Unavailable, iotv1.ReasonExportFailed, "failed to write export", nil)
+}
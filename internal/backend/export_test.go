@@ -0,0 +1,202 @@
+package backend
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/pkg/objectstore"
+)
+
+// memStore is an objectstore.Store that keeps completed objects in memory.
+type memStore struct {
+	objects  map[string][]byte
+	options  map[string]objectstore.WriteOptions
+	aborted  []string
+	writeErr error
+}
+
+func newMemStore() *memStore {
+	return &memStore{objects: map[string][]byte{}, options: map[string]objectstore.WriteOptions{}}
+}
+
+func (s *memStore) NewWriter(_ context.Context, key string, opts objectstore.WriteOptions) (objectstore.Writer, error) {
+	return &memWriter{store: s, key: key, opts: opts}, nil
+}
+
+func (s *memStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	object, ok := s.objects[key]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return io.NopCloser(bytes.NewReader(object)), nil
+}
+
+func (s *memStore) URL(key string) string {
+	return "mem://bucket/" + key
+}
+
+// memWriter buffers one object of a memStore.
+type memWriter struct {
+	store *memStore
+	key   string
+	opts  objectstore.WriteOptions
+	buf   bytes.Buffer
+}
+
+func (w *memWriter) Write(p []byte) (int, error) {
+	if w.store.writeErr != nil {
+		return 0, w.store.writeErr
+	}
+	return w.buf.Write(p)
+}
+
+func (w *memWriter) Close() error {
+	w.store.objects[w.key] = w.buf.Bytes()
+	w.store.options[w.key] = w.opts
+	return nil
+}
+
+func (w *memWriter) Abort() error {
+	w.store.aborted = append(w.store.aborted, w.key)
+	return nil
+}
+
+// gunzip decompresses an object written by an export.
+func gunzip(data []byte) string {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	Expect(err).NotTo(HaveOccurred())
+	out, err := io.ReadAll(zr)
+	Expect(err).NotTo(HaveOccurred())
+	return string(out)
+}
+
+var _ = Describe("ExportReadings", func() {
+	var (
+		ctx     context.Context
+		db      *gorm.DB
+		store   *memStore
+		service *IoTServiceImpl
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError,
+		}))
+
+		db = newTestDB(logger)
+
+		store = newMemStore()
+		service = &IoTServiceImpl{logger: logger, db: db, exports: ExportConfig{Store: store, Prefix: "exports/"}}
+
+		now = time.Now().UTC().Truncate(time.Second)
+		Expect(db.Create(&[]IoTDevice{
+			{DeviceID: "sensor-1", LastSeen: now},
+			{DeviceID: "sensor-2", LastSeen: now},
+			{DeviceID: "deleted", LastSeen: now},
+		}).Error).To(Succeed())
+		Expect(db.Create(&[]SensorReading{
+			{DeviceID: "sensor-1", Timestamp: now.Add(-2 * time.Hour), Temperature: 21.5, BatteryLevel: 90},
+			{DeviceID: "sensor-2", Timestamp: now.Add(-time.Hour), Temperature: 19},
+			{DeviceID: "sensor-1", Timestamp: now.Add(-time.Minute), Temperature: 22},
+			{DeviceID: "deleted", Timestamp: now.Add(-time.Minute)},
+		}).Error).To(Succeed())
+		Expect(db.Where("device_id = ?", "deleted").Delete(&IoTDevice{}).Error).To(Succeed())
+	})
+
+	It("should write the readings of the window as gzip-compressed NDJSON", func() {
+		resp, err := service.ExportReadings(ctx, &iotv1.ExportReadingsRequest{
+			StartTime: now.Add(-90 * time.Minute).Unix(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetReadings()).To(Equal(int64(2)))
+		Expect(resp.GetObjectUrl()).To(HavePrefix("mem://bucket/exports/readings/"))
+		Expect(resp.GetObjectUrl()).To(HaveSuffix(".ndjson.gz"))
+
+		key := strings.TrimPrefix(resp.GetObjectUrl(), "mem://bucket/")
+		Expect(store.objects).To(HaveKey(key))
+		Expect(resp.GetBytes()).To(Equal(int64(len(store.objects[key]))))
+		Expect(store.options[key]).To(Equal(objectstore.WriteOptions{ContentType: "application/x-ndjson", ContentEncoding: "gzip"}))
+
+		lines := strings.Split(strings.TrimSpace(gunzip(store.objects[key])), "\n")
+		Expect(lines).To(HaveLen(2))
+
+		var first exportedReading
+		Expect(json.Unmarshal([]byte(lines[0]), &first)).To(Succeed())
+		Expect(first.DeviceID).To(Equal("sensor-2"))
+		Expect(first.Timestamp).To(BeTemporally("==", now.Add(-time.Hour)))
+		Expect(lines[1]).To(ContainSubstring(`"device_id":"sensor-1"`))
+		Expect(lines[1]).To(ContainSubstring(`"battery_level":0`))
+	})
+
+	It("should only export the requested devices", func() {
+		resp, err := service.ExportReadings(ctx, &iotv1.ExportReadingsRequest{
+			DeviceIds: []string{"sensor-1", "deleted"},
+			StartTime: now.Add(-24 * time.Hour).Unix(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetReadings()).To(Equal(int64(2)))
+	})
+
+	It("should write an empty object when nothing matches", func() {
+		resp, err := service.ExportReadings(ctx, &iotv1.ExportReadingsRequest{
+			StartTime: now.Add(-48 * time.Hour).Unix(),
+			EndTime:   now.Add(-24 * time.Hour).Unix(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetReadings()).To(BeZero())
+		Expect(gunzip(store.objects[strings.TrimPrefix(resp.GetObjectUrl(), "mem://bucket/")])).To(BeEmpty())
+	})
+
+	It("should reject invalid requests", func() {
+		_, err := service.ExportReadings(ctx, &iotv1.ExportReadingsRequest{
+			DeviceIds: []string{""},
+			StartTime: now.Unix(),
+			EndTime:   now.Add(-time.Hour).Unix(),
+		})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		Expect(iotv1.FieldViolations(err)).To(ConsistOf(
+			HaveField("Field", "device_ids"),
+			HaveField("Field", "start_time"),
+		))
+	})
+
+	It("should fail when exports are not configured", func() {
+		service.exports = ExportConfig{}
+
+		_, err := service.ExportReadings(ctx, &iotv1.ExportReadingsRequest{})
+		Expect(status.Code(err)).To(Equal(codes.FailedPrecondition))
+		Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonExportNotConfigured))
+	})
+
+	It("should abort the upload when writing fails", func() {
+		store.writeErr = errors.New("connection reset")
+
+		_, err := service.ExportReadings(ctx, &iotv1.ExportReadingsRequest{StartTime: now.Add(-24 * time.Hour).Unix()})
+		Expect(status.Code(err)).To(Equal(codes.Unavailable))
+		Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonExportFailed))
+		Expect(store.aborted).To(HaveLen(1))
+		Expect(store.objects).To(BeEmpty())
+	})
+
+	It("should count exported rows against the export quota", func() {
+		Expect(isExportRequest(&iotv1.ExportReadingsRequest{})).To(BeTrue())
+		Expect(exportedRows(&iotv1.ExportReadingsResponse{Readings: 42})).To(Equal(int64(42)))
+	})
+})
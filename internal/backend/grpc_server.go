@@ -0,0 +1,149 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/proto"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// KeepaliveConfig tunes how the gRPC server keeps connections alive and ages
+// them out. A zero value keeps the gRPC default.
+type KeepaliveConfig struct {
+	// Time is how long a connection may be idle before the server pings the
+	// client, which keeps middleboxes from dropping long-lived connections.
+	Time time.Duration
+	// Timeout is how long the server waits for a ping ack before closing the connection.
+	Timeout time.Duration
+	// MaxConnectionIdle closes connections without RPCs for this long.
+	MaxConnectionIdle time.Duration
+	// MaxConnectionAge closes connections this old so clients reconnect and
+	// spread over replicas.
+	MaxConnectionAge time.Duration
+	// MaxConnectionAgeGrace is how long RPCs may finish after MaxConnectionAge.
+	MaxConnectionAgeGrace time.Duration
+	// MinPingInterval is the shortest interval at which clients may ping;
+	// clients pinging more often are disconnected.
+	MinPingInterval time.Duration
+	// PermitWithoutStream allows client pings on connections without RPCs.
+	PermitWithoutStream bool
+}
+
+// validate reports whether all durations are non-negative.
+func (c KeepaliveConfig) validate() error {
+	for _, d := range []time.Duration{c.Time, c.Timeout, c.MaxConnectionIdle, c.MaxConnectionAge, c.MaxConnectionAgeGrace, c.MinPingInterval} {
+		if d < 0 {
+			return errors.New("keepalive durations cannot be negative")
+		}
+	}
+	return nil
+}
+
+// serverOptions returns the gRPC server options applying the configuration.
+func (c KeepaliveConfig) serverOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     c.MaxConnectionIdle,
+			MaxConnectionAge:      c.MaxConnectionAge,
+			MaxConnectionAgeGrace: c.MaxConnectionAgeGrace,
+			Time:                  c.Time,
+			Timeout:               c.Timeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             c.MinPingInterval,
+			PermitWithoutStream: c.PermitWithoutStream,
+		}),
+	}
+}
+
+// legacyServiceName is the name IoTService was served under before it moved to
+// the iot.v1 package. It is still served so clients built against the old
+// package keep working until they are upgraded.
+const legacyServiceName = "iot.IoTService"
+
+// compressionInterceptor gzip-compresses responses of at least minSize bytes
+// for clients that accept gzip. Smaller responses are not worth the CPU.
+func compressionInterceptor(minSize int) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+
+		if msg, ok := resp.(proto.Message); ok && proto.Size(msg) >= minSize {
+			// Fails for clients that did not advertise gzip, which then get
+			// the response uncompressed
+			_ = grpc.SetSendCompressor(ctx, gzip.Name)
+		}
+
+		return resp, nil
+	}
+}
+
+// validationInterceptor rejects requests breaking the validation rules of
+// their proto definition before they reach the handler.
+func validationInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if msg, ok := req.(proto.Message); ok {
+			if err := iotv1.Validate(msg); err != nil {
+				return nil, err
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// newGRPCServer creates the gRPC server serving the IoT service.
+func (s *Server) newGRPCServer(service iotv1.IoTServiceServer, quotas *quotaLimiter) (*grpc.Server, error) {
+	opts := s.config.Keepalive.serverOptions()
+
+	if s.config.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(s.config.MaxRecvMsgSize))
+	}
+	if s.config.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(s.config.MaxSendMsgSize))
+	}
+
+	chain, err := s.interceptorChain(quotas)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interceptor chain: %w", err)
+	}
+	s.interceptors = interceptorNames(chain)
+
+	opts = append(opts, grpc.ChainUnaryInterceptor(unaryInterceptors(chain)...))
+
+	server := grpc.NewServer(opts...)
+	iotv1.RegisterIoTServiceServer(server, service)
+
+	// Clients built before the API was versioned call the unversioned name.
+	// Interceptors still see the iot.v1 method names.
+	legacy := iotv1.IoTService_ServiceDesc
+	legacy.ServiceName = legacyServiceName
+	server.RegisterService(&legacy, service)
+
+	// Clients balancing over replicas use the health service to skip
+	// replicas that are shutting down
+	s.health = health.NewServer()
+	s.health.SetServingStatus(iotv1.IoTService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	s.health.SetServingStatus(legacyServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, s.health)
+
+	// Reflection lets tools like grpcurl discover the API without proto files
+	if s.config.EnableReflection {
+		reflection.Register(server)
+		s.logger.Info("gRPC reflection enabled")
+	}
+
+	return server, nil
+}
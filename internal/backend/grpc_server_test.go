@@ -0,0 +1,339 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/encoding/gzip"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// devicesService answers GetAllDevice with a fixed number of devices.
+type devicesService struct {
+	iotv1.UnimplementedIoTServiceServer
+	devices int
+}
+
+func (s *devicesService) GetAllDevice(context.Context, *iotv1.GetAllDevicesRequest) (*iotv1.GetAllDevicesResponse, error) {
+	resp := &iotv1.GetAllDevicesResponse{}
+	for i := range s.devices {
+		resp.Devices = append(resp.Devices, &iotv1.IoTDevice{DeviceId: fmt.Sprintf("device-%04d", i), Location: "warehouse"})
+	}
+	return resp, nil
+}
+
+// compressionRecorder records the compression of received response headers.
+type compressionRecorder struct {
+	mu          sync.Mutex
+	compression string
+}
+
+func (r *compressionRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if h, ok := s.(*stats.InHeader); ok {
+		r.mu.Lock()
+		r.compression = h.Compression
+		r.mu.Unlock()
+	}
+}
+
+func (r *compressionRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+var _ = Describe("gRPC server", func() {
+	newServer := func(config *ServerConfig) *Server {
+		return &Server{
+			logger: slog.New(slog.NewTextHandler(os.Stdout, nil)),
+			config: config,
+		}
+	}
+
+	// newGRPCServer creates the gRPC server of backend.
+	newGRPCServer := func(backend *Server, service iotv1.IoTServiceServer, quotas *quotaLimiter) *grpc.Server {
+		server, err := backend.newGRPCServer(service, quotas)
+		Expect(err).NotTo(HaveOccurred())
+		return server
+	}
+
+	It("should serve the IoT service without reflection by default", func() {
+		server := newGRPCServer(newServer(&ServerConfig{}), iotv1.UnimplementedIoTServiceServer{}, newQuotaLimiter(QuotaConfig{}))
+
+		Expect(server.GetServiceInfo()).To(HaveKey(iotv1.IoTService_ServiceDesc.ServiceName))
+		Expect(server.GetServiceInfo()).NotTo(HaveKey("grpc.reflection.v1.ServerReflection"))
+	})
+
+	It("should report the IoT service healthy until shut down", func() {
+		backend := newServer(&ServerConfig{})
+		server := newGRPCServer(backend, iotv1.UnimplementedIoTServiceServer{}, newQuotaLimiter(QuotaConfig{}))
+		Expect(server.GetServiceInfo()).To(HaveKey(healthpb.Health_ServiceDesc.ServiceName))
+
+		check := func() healthpb.HealthCheckResponse_ServingStatus {
+			resp, err := backend.health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: iotv1.IoTService_ServiceDesc.ServiceName})
+			Expect(err).NotTo(HaveOccurred())
+			return resp.GetStatus()
+		}
+		Expect(check()).To(Equal(healthpb.HealthCheckResponse_SERVING))
+
+		backend.grpcServer = server
+		Expect(backend.stopGRPCServer(context.Background())).To(Succeed())
+		Expect(check()).To(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+	})
+
+	It("should serve the IoT service under its unversioned name", func() {
+		backend := newServer(&ServerConfig{})
+		server := newGRPCServer(backend, &devicesService{devices: 2}, newQuotaLimiter(QuotaConfig{}))
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		go func() { _ = server.Serve(lis) }()
+		DeferCleanup(server.Stop)
+
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+
+		resp := &iotv1.GetAllDevicesResponse{}
+		var header metadata.MD
+		Expect(conn.Invoke(context.Background(), "/iot.IoTService/GetAllDevice", &iotv1.GetAllDevicesRequest{}, resp, grpc.Header(&header))).To(Succeed())
+		Expect(resp.GetDevices()).To(HaveLen(2))
+		Expect(header.Get(deprecationHeader)).To(ConsistOf("use /iot.v1.IoTService/GetAllDevice"))
+
+		// Calls through the versioned name are not marked
+		header = nil
+		_, err = iotv1.NewIoTServiceClient(conn).GetAllDevice(context.Background(), &iotv1.GetAllDevicesRequest{}, grpc.Header(&header))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(header.Get(deprecationHeader)).To(BeEmpty())
+
+		health, err := backend.health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "iot.IoTService"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.GetStatus()).To(Equal(healthpb.HealthCheckResponse_SERVING))
+	})
+
+	It("should report the API version, capabilities and deprecated methods", func() {
+		resp, err := (&IoTServiceImpl{}).GetServerInfo(context.Background(), &iotv1.GetServerInfoRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetApiVersion()).To(Equal("v1"))
+		Expect(resp.GetServerVersion()).NotTo(BeEmpty())
+		Expect(resp.GetCapabilities()).To(ContainElements(iotv1.CapabilityAlertRules, iotv1.CapabilityQuotaUsage))
+		Expect(resp.GetDeprecatedMethods()).To(ContainElement("/iot.IoTService/GetServerInfo"))
+		Expect(resp.GetDeprecatedMethods()).To(HaveLen(len(iotv1.IoTService_ServiceDesc.Methods)))
+	})
+
+	It("should register reflection when enabled", func() {
+		server := newGRPCServer(newServer(&ServerConfig{EnableReflection: true}), iotv1.UnimplementedIoTServiceServer{}, newQuotaLimiter(QuotaConfig{}))
+
+		Expect(server.GetServiceInfo()).To(HaveKey("grpc.reflection.v1.ServerReflection"))
+	})
+
+	Describe("responses", func() {
+		// getAllDevices serves devices over a loopback connection and returns the
+		// compression of the response.
+		getAllDevices := func(config *ServerConfig, devices int, opts ...grpc.DialOption) (string, error) {
+			server := newGRPCServer(newServer(config), &devicesService{devices: devices}, newQuotaLimiter(QuotaConfig{}))
+
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			go func() { _ = server.Serve(lis) }()
+			DeferCleanup(server.Stop)
+
+			recorder := &compressionRecorder{}
+			opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithStatsHandler(recorder))
+			conn, err := grpc.NewClient(lis.Addr().String(), opts...)
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(conn.Close)
+
+			_, err = iotv1.NewIoTServiceClient(conn).GetAllDevice(context.Background(), &iotv1.GetAllDevicesRequest{})
+
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+			return recorder.compression, err
+		}
+
+		It("should gzip large responses", func() {
+			compression, err := getAllDevices(&ServerConfig{CompressMinSize: 1024}, 100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(compression).To(Equal("gzip"))
+		})
+
+		It("should not compress small responses", func() {
+			compression, err := getAllDevices(&ServerConfig{CompressMinSize: 1024}, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(compression).To(BeEmpty())
+		})
+
+		It("should not compress when disabled", func() {
+			compression, err := getAllDevices(&ServerConfig{}, 100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(compression).To(BeEmpty())
+		})
+
+		It("should reject responses above the max send size", func() {
+			_, err := getAllDevices(&ServerConfig{MaxSendMsgSize: 1024}, 100)
+			Expect(err).To(MatchError(ContainSubstring("larger than max")))
+		})
+	})
+
+	Describe("request validation", func() {
+		interceptor := validationInterceptor()
+		call := func(req proto.Message) error {
+			_, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{},
+				func(_ context.Context, _ any) (any, error) { return "ok", nil })
+			return err
+		}
+
+		It("should only use supported rules", func() {
+			methods := iotv1.File_iot_v1_sensor_proto.Services().ByName("IoTService").Methods()
+			for i := range methods.Len() {
+				Expect(iotv1.CheckRules(methods.Get(i).Input())).To(Succeed(), string(methods.Get(i).Name()))
+			}
+		})
+
+		It("should pass valid requests to the handler", func() {
+			Expect(call(&iotv1.GetSensorReadingByDeviceIDRequest{DeviceId: "sensor-1", PageSize: 50})).To(Succeed())
+			Expect(call(&iotv1.GetSensorReadingSeriesBatchRequest{DeviceIds: []string{"a", "b"}, StartTime: 1})).To(Succeed())
+			Expect(call(&iotv1.ExportReadingsRequest{})).To(Succeed())
+			Expect(call(&iotv1.GetQuotaUsageRequest{})).To(Succeed())
+			Expect(call(&iotv1.GetAllDevicesRequest{})).To(Succeed())
+			// IDs from the queue are stored as sent, so they must stay reachable
+			Expect(call(&iotv1.GetDeviceByIDRequest{DeviceId: "../admin/alerts"})).To(Succeed())
+		})
+
+		DescribeTable("should reject invalid requests",
+			func(req proto.Message, field, description string) {
+				err := call(req)
+				Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+				Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonInvalidArgument))
+				Expect(iotv1.FieldViolations(err)).To(ConsistOf(And(
+					HaveField("Field", field),
+					HaveField("Description", ContainSubstring(description)),
+				)))
+			},
+			Entry("empty device ID", &iotv1.GetDeviceByIDRequest{}, "device_id", "cannot be empty"),
+			Entry("overlong device ID", &iotv1.CountReadingsRequest{DeviceId: strings.Repeat("d", 129)}, "device_id", "at most 128 characters"),
+			Entry("device ID with control characters", &iotv1.DeleteDeviceRequest{DeviceId: "sensor\n1"}, "device_id", "must match"),
+			Entry("bad optional device ID", &iotv1.GetQuotaUsageRequest{DeviceId: "sensor\x00"}, "device_id", "must match"),
+			Entry("negative page size", &iotv1.GetSensorReadingByDeviceIDRequest{DeviceId: "a", PageSize: -1}, "page_size", "cannot be negative"),
+			Entry("no series devices", &iotv1.GetSensorReadingSeriesBatchRequest{}, "device_ids", "cannot be empty"),
+			Entry("too many series devices", &iotv1.GetSensorReadingSeriesBatchRequest{DeviceIds: slices.Repeat([]string{"a"}, 11)}, "device_ids", "at most 10 items"),
+			Entry("empty export device ID", &iotv1.ExportReadingsRequest{DeviceIds: []string{"a", ""}}, "device_ids[1]", "cannot be empty"),
+			Entry("negative start time", &iotv1.ExportReadingsRequest{StartTime: -1}, "start_time", "must be between"),
+			Entry("end time past year 9999", &iotv1.GetReadingsHeatmapRequest{EndTime: 253402300800}, "end_time", "must be between"),
+			Entry("too many low battery days", &iotv1.ListLowBatteryDevicesRequest{WithinDays: 366}, "within_days", "between 0 and 365"),
+			Entry("too long location history", &iotv1.GetDeviceLocationHistoryRequest{DeviceId: "a", Limit: 1001}, "limit", "between 0 and 1000"),
+			Entry("too long status history", &iotv1.GetStatusSummaryRequest{Days: 91}, "days", "between 0 and 90"),
+		)
+
+		It("should report every violated field", func() {
+			err := call(&iotv1.GetDeviceLocationHistoryRequest{Limit: -1})
+			Expect(iotv1.FieldViolations(err)).To(ConsistOf(
+				HaveField("Field", "device_id"),
+				HaveField("Field", "limit"),
+			))
+		})
+
+		Describe("in the handlers", func() {
+			var service *IoTServiceImpl
+
+			BeforeEach(func() {
+				logger := slog.New(slog.NewTextHandler(GinkgoWriter, nil))
+				service = &IoTServiceImpl{logger: logger, db: newTestDB(logger)}
+			})
+
+			// Servers may leave the validation interceptor out of their
+			// chain, so the handlers still check what they rely on
+			DescribeTable("should reject invalid requests without the interceptor",
+				func(call func(ctx context.Context, s *IoTServiceImpl) error) {
+					Expect(status.Code(call(context.Background(), service))).To(Equal(codes.InvalidArgument))
+				},
+				Entry("empty device ID", func(ctx context.Context, s *IoTServiceImpl) error {
+					_, err := s.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{})
+					return err
+				}),
+				Entry("empty counted device ID", func(ctx context.Context, s *IoTServiceImpl) error {
+					_, err := s.CountReadings(ctx, &iotv1.CountReadingsRequest{})
+					return err
+				}),
+				Entry("negative page size", func(ctx context.Context, s *IoTServiceImpl) error {
+					_, err := s.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{DeviceId: "a", PageSize: -1})
+					return err
+				}),
+				Entry("too many series devices", func(ctx context.Context, s *IoTServiceImpl) error {
+					_, err := s.GetSensorReadingSeriesBatch(ctx, &iotv1.GetSensorReadingSeriesBatchRequest{DeviceIds: slices.Repeat([]string{"a"}, 11)})
+					return err
+				}),
+				Entry("empty noted device ID", func(ctx context.Context, s *IoTServiceImpl) error {
+					_, err := s.ListDeviceNotes(ctx, &iotv1.ListDeviceNotesRequest{})
+					return err
+				}),
+				Entry("device without ID", func(ctx context.Context, s *IoTServiceImpl) error {
+					_, err := s.CreateOrUpdateDevice(ctx, &iotv1.CreateOrUpdateDeviceRequest{})
+					return err
+				}),
+				Entry("empty deleted device ID", func(ctx context.Context, s *IoTServiceImpl) error {
+					_, err := s.DeleteDevice(ctx, &iotv1.DeleteDeviceRequest{})
+					return err
+				}),
+				Entry("negative device history limit", func(ctx context.Context, s *IoTServiceImpl) error {
+					_, err := s.GetDeviceHistory(ctx, &iotv1.GetDeviceHistoryRequest{DeviceId: "a", Limit: -1})
+					return err
+				}),
+				Entry("too long location history", func(ctx context.Context, s *IoTServiceImpl) error {
+					_, err := s.GetDeviceLocationHistory(ctx, &iotv1.GetDeviceLocationHistoryRequest{DeviceId: "a", Limit: 1001})
+					return err
+				}),
+				Entry("too many low battery days", func(ctx context.Context, s *IoTServiceImpl) error {
+					_, err := s.ListLowBatteryDevices(ctx, &iotv1.ListLowBatteryDevicesRequest{WithinDays: 366})
+					return err
+				}),
+				Entry("too long status history", func(ctx context.Context, s *IoTServiceImpl) error {
+					_, err := s.GetStatusSummary(ctx, &iotv1.GetStatusSummaryRequest{Days: 91})
+					return err
+				}),
+			)
+		})
+
+		It("should run before quotas", func() {
+			server := newGRPCServer(newServer(&ServerConfig{}), &devicesService{}, newQuotaLimiter(QuotaConfig{RequestsPerMinute: 1}))
+
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			go func() { _ = server.Serve(lis) }()
+			DeferCleanup(server.Stop)
+
+			conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(conn.Close)
+
+			client := iotv1.NewIoTServiceClient(conn)
+			_, err = client.GetDevice(context.Background(), &iotv1.GetDeviceByIDRequest{})
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+
+			// The invalid request did not use up the quota
+			_, err = client.GetAllDevice(context.Background(), &iotv1.GetAllDevicesRequest{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
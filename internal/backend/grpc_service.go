@@ -3,24 +3,56 @@ package backend
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
 	"procodus.dev/demo-app/pkg/metrics"
 )
 
+const (
+	// defaultReadingsPageSize is used when a request does not set page_size.
+	defaultReadingsPageSize = 100
+	// maxReadingsPageSize caps page_size to bound query cost.
+	maxReadingsPageSize = 500
+)
+
+const (
+	// maxSeriesBatchDevices limits how many devices one series batch may request.
+	maxSeriesBatchDevices = 10
+	// maxSeriesWindow bounds the time range of a series batch.
+	maxSeriesWindow = 90 * 24 * time.Hour
+	// maxSeriesPoints caps the readings returned per series; longer series are downsampled.
+	maxSeriesPoints = 500
+)
+
+// readingSortColumns maps the order_by values accepted by GetSensorReadingByDeviceID
+// to database columns. Only these columns may be used for sorting.
+var readingSortColumns = map[string]string{
+	"":              "timestamp",
+	"timestamp":     "timestamp",
+	"temperature":   "temperature",
+	"humidity":      "humidity",
+	"pressure":      "pressure",
+	"battery_level": "battery_level",
+}
+
 // IoTServiceImpl implements the gRPC IoTService interface.
 type IoTServiceImpl struct {
-	iot.UnimplementedIoTServiceServer
+	iotv1.UnimplementedIoTServiceServer
 	logger  *slog.Logger
 	db      *gorm.DB
 	metrics *metrics.BackendMetrics // Optional metrics
+	quotas  *quotaLimiter           // Optional quota usage for GetQuotaUsage
+	exports ExportConfig            // Optional bucket for ExportReadings
 }
 
 // NewIoTService creates a new IoTServiceImpl instance.
@@ -41,7 +73,7 @@ func NewIoTService(logger *slog.Logger, db *gorm.DB, m *metrics.BackendMetrics)
 }
 
 // GetAllDevice returns all IoT devices from the database.
-func (s *IoTServiceImpl) GetAllDevice(ctx context.Context, _ *iot.GetAllDevicesRequest) (*iot.GetAllDevicesResponse, error) {
+func (s *IoTServiceImpl) GetAllDevice(ctx context.Context, _ *iotv1.GetAllDevicesRequest) (*iotv1.GetAllDevicesResponse, error) {
 	// Track in-flight requests
 	if s.metrics != nil {
 		s.metrics.GRPCRequestsInFlight.WithLabelValues("GetAllDevice").Inc()
@@ -66,13 +98,13 @@ func (s *IoTServiceImpl) GetAllDevice(ctx context.Context, _ *iot.GetAllDevicesR
 			s.metrics.GRPCRequestsTotal.WithLabelValues("GetAllDevice", "error").Inc()
 		}
 
-		return nil, status.Errorf(codes.Internal, "failed to fetch devices: %v", err)
+		return nil, databaseError("failed to fetch devices")
 	}
 
 	// Convert database models to proto messages
-	protoDevices := make([]*iot.IoTDevice, len(devices))
+	protoDevices := make([]*iotv1.IoTDevice, len(devices))
 	for i, device := range devices {
-		protoDevices[i] = &iot.IoTDevice{
+		protoDevices[i] = &iotv1.IoTDevice{
 			DeviceId:   device.DeviceID,
 			Timestamp:  device.LastSeen.Unix(),
 			Location:   device.Location,
@@ -91,13 +123,13 @@ func (s *IoTServiceImpl) GetAllDevice(ctx context.Context, _ *iot.GetAllDevicesR
 		s.metrics.GRPCRequestsTotal.WithLabelValues("GetAllDevice", "success").Inc()
 	}
 
-	return &iot.GetAllDevicesResponse{
+	return &iotv1.GetAllDevicesResponse{
 		Devices: protoDevices,
 	}, nil
 }
 
 // GetDevice returns a specific IoT device by device ID.
-func (s *IoTServiceImpl) GetDevice(ctx context.Context, req *iot.GetDeviceByIDRequest) (*iot.GetDeviceByIDResponse, error) {
+func (s *IoTServiceImpl) GetDevice(ctx context.Context, req *iotv1.GetDeviceByIDRequest) (*iotv1.GetDeviceByIDResponse, error) {
 	// Track in-flight requests
 	if s.metrics != nil {
 		s.metrics.GRPCRequestsInFlight.WithLabelValues("GetDevice").Inc()
@@ -116,7 +148,7 @@ func (s *IoTServiceImpl) GetDevice(ctx context.Context, req *iot.GetDeviceByIDRe
 		if s.metrics != nil {
 			s.metrics.GRPCRequestsTotal.WithLabelValues("GetDevice", "error").Inc()
 		}
-		return nil, status.Error(codes.InvalidArgument, "device_id cannot be empty")
+		return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "device_id", "cannot be empty")
 	}
 
 	s.logger.Info("GetDevice called", "device_id", req.GetDeviceId())
@@ -130,13 +162,13 @@ func (s *IoTServiceImpl) GetDevice(ctx context.Context, req *iot.GetDeviceByIDRe
 
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			s.logger.Warn("device not found", "device_id", req.GetDeviceId())
-			return nil, status.Errorf(codes.NotFound, "device not found: %s", req.GetDeviceId())
+			return nil, iotv1.DeviceNotFoundError(req.GetDeviceId())
 		}
 		s.logger.Error("failed to fetch device", "device_id", req.GetDeviceId(), "error", err)
-		return nil, status.Errorf(codes.Internal, "failed to fetch device: %v", err)
+		return nil, databaseError("failed to fetch device")
 	}
 
-	protoDevice := &iot.IoTDevice{
+	protoDevice := &iotv1.IoTDevice{
 		DeviceId:   device.DeviceID,
 		Timestamp:  device.LastSeen.Unix(),
 		Location:   device.Location,
@@ -147,6 +179,8 @@ func (s *IoTServiceImpl) GetDevice(ctx context.Context, req *iot.GetDeviceByIDRe
 		Longitude:  device.Longitude,
 	}
 
+	projection := s.findBatteryProjection(ctx, req.GetDeviceId())
+
 	s.logger.Info("fetched device", "device_id", req.GetDeviceId())
 
 	// Track success
@@ -154,13 +188,14 @@ func (s *IoTServiceImpl) GetDevice(ctx context.Context, req *iot.GetDeviceByIDRe
 		s.metrics.GRPCRequestsTotal.WithLabelValues("GetDevice", "success").Inc()
 	}
 
-	return &iot.GetDeviceByIDResponse{
-		Device: protoDevice,
+	return &iotv1.GetDeviceByIDResponse{
+		Device:            protoDevice,
+		BatteryProjection: projection,
 	}, nil
 }
 
 // GetSensorReadingByDeviceID returns sensor readings for a specific device with pagination.
-func (s *IoTServiceImpl) GetSensorReadingByDeviceID(ctx context.Context, req *iot.GetSensorReadingByDeviceIDRequest) (*iot.GetSensorReadingByDeviceIDResponse, error) {
+func (s *IoTServiceImpl) GetSensorReadingByDeviceID(ctx context.Context, req *iotv1.GetSensorReadingByDeviceIDRequest) (*iotv1.GetSensorReadingByDeviceIDResponse, error) {
 	// Track in-flight requests
 	if s.metrics != nil {
 		s.metrics.GRPCRequestsInFlight.WithLabelValues("GetSensorReadingByDeviceID").Inc()
@@ -179,63 +214,86 @@ func (s *IoTServiceImpl) GetSensorReadingByDeviceID(ctx context.Context, req *io
 		if s.metrics != nil {
 			s.metrics.GRPCRequestsTotal.WithLabelValues("GetSensorReadingByDeviceID", "error").Inc()
 		}
-		return nil, status.Error(codes.InvalidArgument, "device_id cannot be empty")
+		return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "device_id", "cannot be empty")
 	}
 
 	s.logger.Info("GetSensorReadingByDeviceID called", "device_id", req.GetDeviceId())
 
-	const pageSize = 100
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize < 0:
+		// Track error
+		if s.metrics != nil {
+			s.metrics.GRPCRequestsTotal.WithLabelValues("GetSensorReadingByDeviceID", "error").Inc()
+		}
+		return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "page_size", "cannot be negative")
+	case pageSize == 0:
+		pageSize = defaultReadingsPageSize
+	case pageSize > maxReadingsPageSize:
+		pageSize = maxReadingsPageSize
+	}
 
 	// Parse page token (offset)
 	offset := 0
 	if req.GetPageToken() != "" {
 		var err error
 		offset, err = strconv.Atoi(req.GetPageToken())
-		if err != nil {
+		if err != nil || offset < 0 {
 			// Track error
 			if s.metrics != nil {
 				s.metrics.GRPCRequestsTotal.WithLabelValues("GetSensorReadingByDeviceID", "error").Inc()
 			}
-			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+			return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidPageToken, "page_token", "must be a non-negative offset")
 		}
 	}
 
-	// Query sensor readings with pagination
-	var readings []SensorReading
-	query := s.db.WithContext(ctx).
-		Where("device_id = ?", req.GetDeviceId()).
-		Order("timestamp DESC").
-		Limit(pageSize + 1). // Fetch one extra to determine if there's a next page
-		Offset(offset)
-
-	if err := query.Find(&readings).Error; err != nil {
-		s.logger.Error("failed to fetch sensor readings", "device_id", req.GetDeviceId(), "error", err)
-
+	sortColumn, ok := readingSortColumns[req.GetOrderBy()]
+	if !ok {
 		// Track error
 		if s.metrics != nil {
 			s.metrics.GRPCRequestsTotal.WithLabelValues("GetSensorReadingByDeviceID", "error").Inc()
 		}
-
-		return nil, status.Errorf(codes.Internal, "failed to fetch sensor readings: %v", err)
+		return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "order_by", "unsupported sort column")
 	}
 
-	// Determine if there's a next page
-	hasNextPage := len(readings) > pageSize
-	if hasNextPage {
-		readings = readings[:pageSize]
+	// Query sensor readings with pagination
+	query := s.db.WithContext(ctx).
+		Where("device_id = ?", req.GetDeviceId()).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: sortColumn}, Desc: !req.GetAscending()})
+	if sortColumn != "timestamp" {
+		// Break ties by time so pages stay stable
+		query = query.Order("timestamp DESC")
 	}
+	query = query.
+		Limit(pageSize + 1). // Fetch one extra to determine if there's a next page
+		Offset(offset)
 
-	// Convert database models to proto messages
-	protoReadings := make([]*iot.SensorReading, len(readings))
-	for i, reading := range readings {
-		protoReadings[i] = &iot.SensorReading{
+	// Rows are converted to proto messages as they are read
+	protoReadings := make([]*iotv1.SensorReading, 0, pageSize)
+	hasNextPage := false
+	err := scanReadings(query, func(reading *SensorReading) {
+		if len(protoReadings) == pageSize {
+			hasNextPage = true
+			return
+		}
+		protoReadings = append(protoReadings, &iotv1.SensorReading{
 			DeviceId:     reading.DeviceID,
 			Timestamp:    reading.Timestamp.Unix(),
 			Temperature:  reading.Temperature,
 			Humidity:     reading.Humidity,
 			Pressure:     reading.Pressure,
 			BatteryLevel: reading.BatteryLevel,
+		})
+	})
+	if err != nil {
+		s.logger.Error("failed to fetch sensor readings", "device_id", req.GetDeviceId(), "error", err)
+
+		// Track error
+		if s.metrics != nil {
+			s.metrics.GRPCRequestsTotal.WithLabelValues("GetSensorReadingByDeviceID", "error").Inc()
 		}
+
+		return nil, databaseError("failed to fetch sensor readings")
 	}
 
 	// Generate next page token
@@ -255,8 +313,261 @@ func (s *IoTServiceImpl) GetSensorReadingByDeviceID(ctx context.Context, req *io
 		s.metrics.GRPCRequestsTotal.WithLabelValues("GetSensorReadingByDeviceID", "success").Inc()
 	}
 
-	return &iot.GetSensorReadingByDeviceIDResponse{
+	return &iotv1.GetSensorReadingByDeviceIDResponse{
 		Reading:       protoReadings,
 		NextPageToken: nextPageToken,
 	}, nil
 }
+
+// CountReadings returns the total number of sensor readings stored for a device.
+func (s *IoTServiceImpl) CountReadings(ctx context.Context, req *iotv1.CountReadingsRequest) (*iotv1.CountReadingsResponse, error) {
+	// Track in-flight requests
+	if s.metrics != nil {
+		s.metrics.GRPCRequestsInFlight.WithLabelValues("CountReadings").Inc()
+		defer s.metrics.GRPCRequestsInFlight.WithLabelValues("CountReadings").Dec()
+	}
+
+	// Track duration
+	var timer *prometheus.Timer
+	if s.metrics != nil {
+		timer = prometheus.NewTimer(s.metrics.GRPCRequestDuration.WithLabelValues("CountReadings"))
+		defer timer.ObserveDuration()
+	}
+
+	if req.GetDeviceId() == "" {
+		// Track error
+		if s.metrics != nil {
+			s.metrics.GRPCRequestsTotal.WithLabelValues("CountReadings", "error").Inc()
+		}
+		return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "device_id", "cannot be empty")
+	}
+
+	s.logger.Info("CountReadings called", "device_id", req.GetDeviceId())
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&SensorReading{}).Where("device_id = ?", req.GetDeviceId()).Count(&count).Error; err != nil {
+		s.logger.Error("failed to count sensor readings", "device_id", req.GetDeviceId(), "error", err)
+
+		// Track error
+		if s.metrics != nil {
+			s.metrics.GRPCRequestsTotal.WithLabelValues("CountReadings", "error").Inc()
+		}
+
+		return nil, databaseError("failed to count sensor readings")
+	}
+
+	// Track success
+	if s.metrics != nil {
+		s.metrics.GRPCRequestsTotal.WithLabelValues("CountReadings", "success").Inc()
+	}
+
+	return &iotv1.CountReadingsResponse{
+		Count: count,
+	}, nil
+}
+
+// GetSensorReadingSeriesBatch returns the readings of several devices within a time
+// window in a single query, so comparison views need not issue one call per device.
+// Windows longer than rawSeriesWindow are served from hourly or daily rollups.
+func (s *IoTServiceImpl) GetSensorReadingSeriesBatch(ctx context.Context, req *iotv1.GetSensorReadingSeriesBatchRequest) (*iotv1.GetSensorReadingSeriesBatchResponse, error) {
+	// Track in-flight requests
+	if s.metrics != nil {
+		s.metrics.GRPCRequestsInFlight.WithLabelValues("GetSensorReadingSeriesBatch").Inc()
+		defer s.metrics.GRPCRequestsInFlight.WithLabelValues("GetSensorReadingSeriesBatch").Dec()
+	}
+
+	// Track duration
+	var timer *prometheus.Timer
+	if s.metrics != nil {
+		timer = prometheus.NewTimer(s.metrics.GRPCRequestDuration.WithLabelValues("GetSensorReadingSeriesBatch"))
+		defer timer.ObserveDuration()
+	}
+
+	start := time.Unix(req.GetStartTime(), 0)
+	end := time.Now()
+	if req.GetEndTime() != 0 {
+		end = time.Unix(req.GetEndTime(), 0)
+	}
+
+	if err := validateSeriesBatchRequest(req.GetDeviceIds(), start, end); err != nil {
+		// Track error
+		if s.metrics != nil {
+			s.metrics.GRPCRequestsTotal.WithLabelValues("GetSensorReadingSeriesBatch", "error").Inc()
+		}
+		return nil, err
+	}
+
+	s.logger.Info("GetSensorReadingSeriesBatch called",
+		"device_ids", req.GetDeviceIds(),
+		"start", start,
+		"end", end,
+	)
+
+	resolution := seriesResolution(end.Sub(start))
+	if !isPostgres(s.db) {
+		// Rollups are only maintained on PostgreSQL
+		resolution = resolutionRaw
+	}
+
+	// Readings are folded into compact per-device points and window statistics;
+	// only the points left after downsampling become proto messages
+	points := make(map[string][]seriesPoint, len(req.GetDeviceIds()))
+	stats := make(map[string]*seriesStats, len(req.GetDeviceIds()))
+	for _, deviceID := range req.GetDeviceIds() {
+		stats[deviceID] = &seriesStats{}
+	}
+
+	var rows int
+	if resolution == resolutionRaw {
+		query := s.db.WithContext(ctx).
+			Where("device_id IN ?", req.GetDeviceIds()).
+			Where("timestamp >= ? AND timestamp < ?", start, end).
+			Order("device_id").
+			Order("timestamp ASC")
+
+		err := scanReadings(query, func(reading *SensorReading) {
+			rollup := readingRollup(reading)
+			stats[rollup.DeviceID].add(&rollup)
+			points[rollup.DeviceID] = append(points[rollup.DeviceID], rollupPoint(&rollup))
+			rows++
+		})
+		if err != nil {
+			s.logger.Error("failed to fetch sensor reading series", "device_ids", req.GetDeviceIds(), "error", err)
+
+			// Track error
+			if s.metrics != nil {
+				s.metrics.GRPCRequestsTotal.WithLabelValues("GetSensorReadingSeriesBatch", "error").Inc()
+			}
+
+			return nil, databaseError("failed to fetch sensor reading series")
+		}
+	} else {
+		rollups, err := s.fetchRollupSeries(ctx, resolution, req.GetDeviceIds(), start, end)
+		if err != nil {
+			s.logger.Error("failed to fetch sensor reading rollups", "device_ids", req.GetDeviceIds(), "resolution", resolution, "error", err)
+
+			// Track error
+			if s.metrics != nil {
+				s.metrics.GRPCRequestsTotal.WithLabelValues("GetSensorReadingSeriesBatch", "error").Inc()
+			}
+
+			return nil, databaseError("failed to fetch sensor reading series")
+		}
+
+		for i := range rollups {
+			stats[rollups[i].DeviceID].add(&rollups[i])
+			points[rollups[i].DeviceID] = append(points[rollups[i].DeviceID], rollupPoint(&rollups[i]))
+		}
+		rows = len(rollups)
+	}
+
+	// Keep the requested device order
+	series := make([]*iotv1.SensorReadingSeries, len(req.GetDeviceIds()))
+	for i, deviceID := range req.GetDeviceIds() {
+		series[i] = &iotv1.SensorReadingSeries{
+			DeviceId: deviceID,
+			Readings: seriesProto(deviceID, downsampleReadings(points[deviceID], maxSeriesPoints)),
+			Stats:    stats[deviceID].proto(),
+		}
+	}
+
+	s.logger.Info("fetched sensor reading series", "devices", len(series), "rows", rows, "resolution", resolution)
+
+	// Track success
+	if s.metrics != nil {
+		s.metrics.GRPCRequestsTotal.WithLabelValues("GetSensorReadingSeriesBatch", "success").Inc()
+	}
+
+	return &iotv1.GetSensorReadingSeriesBatchResponse{
+		Series:     series,
+		Resolution: resolution,
+	}, nil
+}
+
+// validateSeriesBatchRequest checks the device list and time window of a series batch.
+func validateSeriesBatchRequest(deviceIDs []string, start, end time.Time) error {
+	var violations []iotv1.FieldViolation
+
+	switch {
+	case len(deviceIDs) == 0:
+		violations = append(violations, iotv1.FieldViolation{Field: "device_ids", Description: "cannot be empty"})
+	case len(deviceIDs) > maxSeriesBatchDevices:
+		violations = append(violations, iotv1.FieldViolation{
+			Field:       "device_ids",
+			Description: fmt.Sprintf("at most %d devices can be requested", maxSeriesBatchDevices),
+		})
+	}
+
+	if slices.Contains(deviceIDs, "") {
+		violations = append(violations, iotv1.FieldViolation{Field: "device_ids", Description: "cannot contain empty IDs"})
+	}
+
+	switch {
+	case !start.Before(end):
+		violations = append(violations, iotv1.FieldViolation{Field: "start_time", Description: "must be before end_time"})
+	case end.Sub(start) > maxSeriesWindow:
+		violations = append(violations, iotv1.FieldViolation{
+			Field:       "start_time",
+			Description: fmt.Sprintf("window cannot exceed %s", maxSeriesWindow),
+		})
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return iotv1.NewError(codes.InvalidArgument, iotv1.ReasonInvalidArgument, "invalid series batch request", nil, violations...)
+}
+
+// downsampleReadings returns at most limit readings picked at even intervals,
+// always keeping the first and last reading.
+func downsampleReadings[T any](readings []T, limit int) []T {
+	if len(readings) <= limit || limit < 2 {
+		return readings
+	}
+
+	sampled := make([]T, limit)
+	step := float64(len(readings)-1) / float64(limit-1)
+	for i := range sampled {
+		sampled[i] = readings[int(float64(i)*step+0.5)]
+	}
+
+	return sampled
+}
+
+// readingColumns are the sensor_readings columns read by scanReadings.
+var readingColumns = []string{"device_id", "timestamp", "temperature", "humidity", "pressure", "battery_level"}
+
+// scanReadings runs a sensor_readings query and calls fn for each row as it is
+// read, so large results are never materialized as a slice of models. The
+// reading passed to fn is reused for every row; fn must copy what it keeps.
+func scanReadings(query *gorm.DB, fn func(reading *SensorReading)) error {
+	rows, err := query.Model(&SensorReading{}).Select(readingColumns).Rows()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var reading SensorReading
+	for rows.Next() {
+		if err := rows.Scan(
+			&reading.DeviceID,
+			&reading.Timestamp,
+			&reading.Temperature,
+			&reading.Humidity,
+			&reading.Pressure,
+			&reading.BatteryLevel,
+		); err != nil {
+			return err
+		}
+		fn(&reading)
+	}
+
+	return rows.Err()
+}
+
+// databaseError returns an Internal error for a failed query. The underlying
+// error is logged by the caller and deliberately not exposed to clients.
+func databaseError(msg string) error {
+	return iotv1.NewError(codes.Internal, iotv1.ReasonDatabaseError, msg, nil)
+}
@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// BenchmarkGetSensorReadingSeriesBatch measures a raw series batch over two
+// days of per-minute readings of several devices, which is downsampled to
+// maxSeriesPoints per device. Allocations should depend on the returned points
+// rather than on the rows read.
+func BenchmarkGetSensorReadingSeriesBatch(b *testing.B) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	db, err := NewDB(&DBConfig{Logger: logger, Driver: DriverSQLite, DBName: ":memory:"})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	const devices = 4
+	end := time.Now().Truncate(time.Minute)
+	start := end.Add(-rawSeriesWindow)
+
+	deviceIDs := make([]string, devices)
+	for d := range deviceIDs {
+		deviceIDs[d] = "bench-device-" + string(rune('a'+d))
+		if err := db.Create(&IoTDevice{DeviceID: deviceIDs[d], LastSeen: end}).Error; err != nil {
+			b.Fatal(err)
+		}
+
+		var readings []SensorReading
+		for ts := start; ts.Before(end); ts = ts.Add(time.Minute) {
+			readings = append(readings, SensorReading{
+				DeviceID:     deviceIDs[d],
+				Timestamp:    ts,
+				Temperature:  22.5,
+				Humidity:     60,
+				Pressure:     1013,
+				BatteryLevel: 90,
+			})
+		}
+		if err := db.CreateInBatches(readings, 500).Error; err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	s := &IoTServiceImpl{logger: logger, db: db}
+	req := &iotv1.GetSensorReadingSeriesBatchRequest{
+		DeviceIds: deviceIDs,
+		StartTime: start.Unix(),
+		EndTime:   end.Unix(),
+	}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		resp, err := s.GetSensorReadingSeriesBatch(ctx, req)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if got := len(resp.GetSeries()[0].GetReadings()); got != maxSeriesPoints {
+			b.Fatalf("expected %d points, got %d", maxSeriesPoints, got)
+		}
+	}
+}
@@ -9,7 +9,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"procodus.dev/demo-app/internal/backend"
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
 )
 
 var _ = Describe("gRPC Service", func() {
@@ -26,44 +26,17 @@ var _ = Describe("gRPC Service", func() {
 	Describe("NewIoTService", func() {
 		Context("with valid configuration", func() {
 			It("should create a service with valid logger and DB", func() {
-				// Create a mock DB
-				dbCfg := &backend.DBConfig{
-					Host:     "localhost",
-					Port:     5432,
-					User:     "test",
-					Password: "password",
-					DBName:   "testdb",
-					SSLMode:  "disable",
-					Logger:   logger,
-				}
-				db, dbErr := backend.NewDB(dbCfg)
-				// We don't expect this to succeed without a real DB
-
-				if db != nil && dbErr == nil {
-					defer backend.CloseDB(db, logger)
+				db := backend.NewTestDB(logger)
 
-					service, err := backend.NewIoTService(logger, db, nil)
-					Expect(err).NotTo(HaveOccurred())
-					Expect(service).NotTo(BeNil())
-				}
+				service, err := backend.NewIoTService(logger, db, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(service).NotTo(BeNil())
 			})
 		})
 
 		Context("with invalid configuration", func() {
 			It("should return error when logger is nil", func() {
-				dbCfg := &backend.DBConfig{
-					Host:     "localhost",
-					Port:     5432,
-					User:     "test",
-					Password: "password",
-					DBName:   "testdb",
-					SSLMode:  "disable",
-					Logger:   logger,
-				}
-				db, _ := backend.NewDB(dbCfg)
-				if db != nil {
-					defer backend.CloseDB(db, logger)
-				}
+				db := backend.NewTestDB(logger)
 
 				service, err := backend.NewIoTService(nil, db, nil)
 				Expect(err).To(HaveOccurred())
@@ -83,26 +56,13 @@ var _ = Describe("gRPC Service", func() {
 	Describe("GetDevice", func() {
 		Context("with invalid request", func() {
 			It("should return error when device_id is empty", func() {
-				dbCfg := &backend.DBConfig{
-					Host:     "localhost",
-					Port:     5432,
-					User:     "test",
-					Password: "password",
-					DBName:   "testdb",
-					SSLMode:  "disable",
-					Logger:   logger,
-				}
-				db, err := backend.NewDB(dbCfg)
-				if err != nil || db == nil {
-					Skip("skipping test: database not available")
-				}
-				defer backend.CloseDB(db, logger)
+				db := backend.NewTestDB(logger)
 
 				service, err := backend.NewIoTService(logger, db, nil)
 				Expect(err).NotTo(HaveOccurred())
 
 				ctx := context.Background()
-				req := &iot.GetDeviceByIDRequest{
+				req := &iotv1.GetDeviceByIDRequest{
 					DeviceId: "",
 				}
 
@@ -116,26 +76,13 @@ var _ = Describe("gRPC Service", func() {
 	Describe("GetSensorReadingByDeviceID", func() {
 		Context("with invalid request", func() {
 			It("should return error when device_id is empty", func() {
-				dbCfg := &backend.DBConfig{
-					Host:     "localhost",
-					Port:     5432,
-					User:     "test",
-					Password: "password",
-					DBName:   "testdb",
-					SSLMode:  "disable",
-					Logger:   logger,
-				}
-				db, err := backend.NewDB(dbCfg)
-				if err != nil || db == nil {
-					Skip("skipping test: database not available")
-				}
-				defer backend.CloseDB(db, logger)
+				db := backend.NewTestDB(logger)
 
 				service, err := backend.NewIoTService(logger, db, nil)
 				Expect(err).NotTo(HaveOccurred())
 
 				ctx := context.Background()
-				req := &iot.GetSensorReadingByDeviceIDRequest{
+				req := &iotv1.GetSensorReadingByDeviceIDRequest{
 					DeviceId: "",
 				}
 
@@ -145,26 +92,13 @@ var _ = Describe("gRPC Service", func() {
 			})
 
 			It("should return error when page_token is invalid", func() {
-				dbCfg := &backend.DBConfig{
-					Host:     "localhost",
-					Port:     5432,
-					User:     "test",
-					Password: "password",
-					DBName:   "testdb",
-					SSLMode:  "disable",
-					Logger:   logger,
-				}
-				db, err := backend.NewDB(dbCfg)
-				if err != nil || db == nil {
-					Skip("skipping test: database not available")
-				}
-				defer backend.CloseDB(db, logger)
+				db := backend.NewTestDB(logger)
 
 				service, err := backend.NewIoTService(logger, db, nil)
 				Expect(err).NotTo(HaveOccurred())
 
 				ctx := context.Background()
-				req := &iot.GetSensorReadingByDeviceIDRequest{
+				req := &iotv1.GetSensorReadingByDeviceIDRequest{
 					DeviceId:  "device-001",
 					PageToken: "invalid-token",
 				}
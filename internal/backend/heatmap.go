@@ -0,0 +1,142 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+const (
+	// defaultHeatmapCellSize is used when GetReadingsHeatmap does not set cell_size.
+	defaultHeatmapCellSize = 1.0
+	// minHeatmapCellSize and maxHeatmapCellSize bound cell_size of GetReadingsHeatmap.
+	minHeatmapCellSize = 0.01
+	maxHeatmapCellSize = 10.0
+	// defaultHeatmapWindow is used when GetReadingsHeatmap does not set start_time.
+	defaultHeatmapWindow = 24 * time.Hour
+)
+
+// heatmapQuery aggregates readings into grid cells of the location of their
+// device. The table and the per-row count and averages are filled in for raw
+// readings or hourly rollups; rollups are weighted by their reading count.
+const heatmapQuery = `
+SELECT FLOOR(d.latitude / @cell) AS lat_cell,
+	FLOOR(d.longitude / @cell) AS lon_cell,
+	SUM(%[2]s * %[3]s) / SUM(%[2]s) AS avg_temperature,
+	SUM(%[2]s * %[4]s) / SUM(%[2]s) AS avg_humidity,
+	SUM(%[2]s) AS reading_count,
+	COUNT(DISTINCT r.device_id) AS device_count
+FROM %[1]s r
+JOIN iot_devices d ON d.device_id = r.device_id AND d.deleted_at IS NULL
+WHERE r.%[5]s >= @start AND r.%[5]s < @end
+GROUP BY lat_cell, lon_cell
+ORDER BY lat_cell, lon_cell`
+
+// heatmapRow is a grid cell as returned by heatmapQuery.
+type heatmapRow struct {
+	LatCell        float64
+	LonCell        float64
+	AvgTemperature float64
+	AvgHumidity    float64
+	ReadingCount   int64
+	DeviceCount    int64
+}
+
+// GetReadingsHeatmap returns the average temperature and humidity of the fleet's
+// readings in a time window, bucketed into a latitude/longitude grid. Readings
+// are placed at the current location of their device. Windows longer than
+// rawSeriesWindow are served from hourly rollups on PostgreSQL.
+func (s *IoTServiceImpl) GetReadingsHeatmap(ctx context.Context, req *iotv1.GetReadingsHeatmapRequest) (resp *iotv1.GetReadingsHeatmapResponse, err error) {
+	done := s.trackRequest("GetReadingsHeatmap")
+	defer func() { done(err) }()
+
+	cellSize := req.GetCellSize()
+	if cellSize == 0 {
+		cellSize = defaultHeatmapCellSize
+	}
+
+	end := time.Now()
+	if req.GetEndTime() != 0 {
+		end = time.Unix(req.GetEndTime(), 0)
+	}
+	start := end.Add(-defaultHeatmapWindow)
+	if req.GetStartTime() != 0 {
+		start = time.Unix(req.GetStartTime(), 0)
+	}
+
+	if err := validateHeatmapRequest(cellSize, start, end); err != nil {
+		return nil, err
+	}
+
+	resolution := resolutionRaw
+	if isPostgres(s.db) && end.Sub(start) > rawSeriesWindow {
+		// Rollups are only maintained on PostgreSQL
+		resolution = resolutionHour
+	}
+
+	query := fmt.Sprintf(heatmapQuery, SensorReading{}.TableName(), "1", "r.temperature", "r.humidity", "timestamp")
+	if resolution == resolutionHour {
+		query = fmt.Sprintf(heatmapQuery, HourlyReadingRollup{}.TableName(), "r.count", "r.temperature_avg", "r.humidity_avg", "bucket_start")
+		start = start.Truncate(time.Hour)
+	}
+
+	var rows []heatmapRow
+	if err := s.db.WithContext(ctx).Raw(query, map[string]any{
+		"cell":  cellSize,
+		"start": start,
+		"end":   end,
+	}).Scan(&rows).Error; err != nil {
+		s.logger.Error("failed to aggregate readings heatmap", "error", err)
+		return nil, databaseError("failed to aggregate readings heatmap")
+	}
+
+	resp = &iotv1.GetReadingsHeatmapResponse{
+		Cells:      make([]*iotv1.HeatmapCell, len(rows)),
+		CellSize:   cellSize,
+		Resolution: resolution,
+	}
+	for i, row := range rows {
+		resp.Cells[i] = &iotv1.HeatmapCell{
+			Latitude:       row.LatCell * cellSize,
+			Longitude:      row.LonCell * cellSize,
+			AvgTemperature: row.AvgTemperature,
+			AvgHumidity:    row.AvgHumidity,
+			ReadingCount:   row.ReadingCount,
+			DeviceCount:    row.DeviceCount,
+		}
+	}
+
+	return resp, nil
+}
+
+// validateHeatmapRequest checks the grid and window of a heatmap request.
+func validateHeatmapRequest(cellSize float64, start, end time.Time) error {
+	var violations []iotv1.FieldViolation
+
+	if cellSize < minHeatmapCellSize || cellSize > maxHeatmapCellSize {
+		violations = append(violations, iotv1.FieldViolation{
+			Field:       "cell_size",
+			Description: fmt.Sprintf("must be between %g and %g degrees", minHeatmapCellSize, maxHeatmapCellSize),
+		})
+	}
+
+	switch {
+	case !start.Before(end):
+		violations = append(violations, iotv1.FieldViolation{Field: "start_time", Description: "must be before end_time"})
+	case end.Sub(start) > maxSeriesWindow:
+		violations = append(violations, iotv1.FieldViolation{
+			Field:       "start_time",
+			Description: fmt.Sprintf("window cannot exceed %s", maxSeriesWindow),
+		})
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return iotv1.NewError(codes.InvalidArgument, iotv1.ReasonInvalidArgument, "invalid heatmap request", nil, violations...)
+}
@@ -0,0 +1,109 @@
+package backend
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Readings heatmap", func() {
+	var (
+		ctx     context.Context
+		db      *gorm.DB
+		service *IoTServiceImpl
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError,
+		}))
+
+		db = newTestDB(logger)
+
+		service = &IoTServiceImpl{logger: logger, db: db}
+		now = time.Now().UTC().Truncate(time.Second)
+
+		Expect(db.Create(&[]IoTDevice{
+			{DeviceID: "berlin-1", Location: "Berlin", Latitude: 52.52, Longitude: 13.40, LastSeen: now},
+			{DeviceID: "berlin-2", Location: "Berlin", Latitude: 52.40, Longitude: 13.05, LastSeen: now},
+			{DeviceID: "sydney-1", Location: "Sydney", Latitude: -33.87, Longitude: 151.21, LastSeen: now},
+			{DeviceID: "trashed", Location: "Berlin", Latitude: 52.50, Longitude: 13.30, LastSeen: now},
+		}).Error).To(Succeed())
+		Expect(db.Where("device_id = ?", "trashed").Delete(&IoTDevice{}).Error).To(Succeed())
+
+		Expect(db.Create(&[]SensorReading{
+			{DeviceID: "berlin-1", Timestamp: now.Add(-time.Hour), Temperature: 20, Humidity: 40},
+			{DeviceID: "berlin-1", Timestamp: now.Add(-2 * time.Hour), Temperature: 22, Humidity: 50},
+			{DeviceID: "berlin-2", Timestamp: now.Add(-time.Hour), Temperature: 18, Humidity: 60},
+			{DeviceID: "sydney-1", Timestamp: now.Add(-time.Hour), Temperature: 30, Humidity: 70},
+			{DeviceID: "sydney-1", Timestamp: now.Add(-48 * time.Hour), Temperature: 10, Humidity: 10},
+			{DeviceID: "trashed", Timestamp: now.Add(-time.Hour), Temperature: 99, Humidity: 99},
+		}).Error).To(Succeed())
+	})
+
+	It("should average the readings of each grid cell in the window", func() {
+		resp, err := service.GetReadingsHeatmap(ctx, &iotv1.GetReadingsHeatmapRequest{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(resp.GetCellSize()).To(Equal(1.0))
+		Expect(resp.GetResolution()).To(Equal(resolutionRaw))
+		Expect(resp.GetCells()).To(HaveLen(2))
+
+		sydney, berlin := resp.GetCells()[0], resp.GetCells()[1]
+		Expect(sydney.GetLatitude()).To(Equal(-34.0))
+		Expect(sydney.GetLongitude()).To(Equal(151.0))
+		Expect(sydney.GetAvgTemperature()).To(BeNumerically("~", 30, 1e-9))
+		Expect(sydney.GetReadingCount()).To(Equal(int64(1)))
+
+		Expect(berlin.GetLatitude()).To(Equal(52.0))
+		Expect(berlin.GetLongitude()).To(Equal(13.0))
+		Expect(berlin.GetAvgTemperature()).To(BeNumerically("~", 20, 1e-9))
+		Expect(berlin.GetAvgHumidity()).To(BeNumerically("~", 50, 1e-9))
+		Expect(berlin.GetReadingCount()).To(Equal(int64(3)))
+		Expect(berlin.GetDeviceCount()).To(Equal(int64(2)))
+	})
+
+	It("should split cells with a finer grid", func() {
+		resp, err := service.GetReadingsHeatmap(ctx, &iotv1.GetReadingsHeatmapRequest{CellSize: 0.25})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(resp.GetCells()).To(HaveLen(3))
+		Expect(resp.GetCells()[1].GetLatitude()).To(Equal(52.25))
+		Expect(resp.GetCells()[1].GetLongitude()).To(Equal(13.0))
+		Expect(resp.GetCells()[2].GetLatitude()).To(Equal(52.5))
+		Expect(resp.GetCells()[2].GetLongitude()).To(Equal(13.25))
+	})
+
+	It("should honour the requested window", func() {
+		resp, err := service.GetReadingsHeatmap(ctx, &iotv1.GetReadingsHeatmapRequest{
+			StartTime: now.Add(-72 * time.Hour).Unix(),
+			EndTime:   now.Add(-24 * time.Hour).Unix(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(resp.GetCells()).To(HaveLen(1))
+		Expect(resp.GetCells()[0].GetAvgTemperature()).To(BeNumerically("~", 10, 1e-9))
+	})
+
+	It("should validate the request", func() {
+		_, err := service.GetReadingsHeatmap(ctx, &iotv1.GetReadingsHeatmapRequest{
+			CellSize:  20,
+			StartTime: now.Unix(),
+			EndTime:   now.Add(-time.Hour).Unix(),
+		})
+
+		Expect(iotv1.FieldViolations(err)).To(ConsistOf(
+			HaveField("Field", "cell_size"),
+			HaveField("Field", "start_time"),
+		))
+	})
+})
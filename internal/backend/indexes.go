@@ -0,0 +1,180 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// hotQueryIndexes serve the most frequent queries. They are created with IF
+// NOT EXISTS after AutoMigrate. The latest readings of a device are served by
+// scanning the unique idx_device_timestamp backwards.
+var hotQueryIndexes = []struct {
+	name string
+	sql  string
+}{
+	// Active device counts; AutoMigrate creates it from the model as well,
+	// but databases from before the model tag may lack it
+	{"idx_last_seen", `CREATE INDEX IF NOT EXISTS idx_last_seen ON iot_devices (last_seen)`},
+}
+
+// createHotQueryIndexes creates the missing hotQueryIndexes. On PostgreSQL,
+// an index on the partitioned sensor_readings is created on every partition.
+func createHotQueryIndexes(db *gorm.DB, logger *slog.Logger) error {
+	for _, index := range hotQueryIndexes {
+		if err := db.Exec(index.sql).Error; err != nil {
+			return fmt.Errorf("failed to create index %s: %w", index.name, err)
+		}
+	}
+
+	logger.Info("hot query indexes ready", "count", len(hotQueryIndexes))
+	return nil
+}
+
+// Defaults of AnalyzeOptions.
+const (
+	defaultAnalyzeMinCalls        = 10
+	defaultAnalyzeMinBlocksPerRow = 100
+	defaultAnalyzeLimit           = 20
+	// analyzeMinTableRows skips tables small enough to be scanned cheaply.
+	analyzeMinTableRows = 10000
+)
+
+// AnalyzeOptions tunes AnalyzeIndexes. Zero values use the defaults.
+type AnalyzeOptions struct {
+	// MinCalls skips statements run fewer times (default 10)
+	MinCalls int
+	// MinBlocksPerRow is the number of buffer blocks a statement must read
+	// per returned row to be a candidate (default 100)
+	MinBlocksPerRow float64
+	// Limit caps the number of statements and tables reported (default 20)
+	Limit int
+}
+
+// IndexCandidate is a statement that reads far more blocks than it returns
+// rows, which usually means it scans a table an index could narrow down.
+type IndexCandidate struct {
+	Query        string
+	Calls        int64
+	Rows         int64
+	TotalTime    time.Duration
+	MeanTime     time.Duration
+	BlocksPerRow float64
+}
+
+// TableScans are the sequential scan statistics of a table.
+type TableScans struct {
+	Table       string
+	LiveRows    int64
+	SeqScans    int64
+	SeqRowsRead int64
+	IndexScans  int64
+}
+
+// IndexReport lists missing-index candidates. Candidates is only filled when
+// pg_stat_statements is installed, as reported by StatementsAvailable.
+type IndexReport struct {
+	Candidates          []IndexCandidate
+	Tables              []TableScans
+	StatementsAvailable bool
+}
+
+// statementsQuery selects statements reading many blocks per returned row
+// from pg_stat_statements (PostgreSQL 13 or later). Inserts are skipped, as
+// indexes only slow them down.
+const statementsQuery = `
+SELECT query, calls, rows, total_exec_time, mean_exec_time,
+    (shared_blks_hit + shared_blks_read)::float8 / GREATEST(rows, calls) AS blocks_per_row
+FROM pg_stat_statements
+WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+    AND calls >= ?
+    AND query ~* '^\s*(select|update|delete|with)\M'
+    AND (shared_blks_hit + shared_blks_read)::float8 / GREATEST(rows, calls) >= ?
+ORDER BY total_exec_time DESC
+LIMIT ?`
+
+// tableScansQuery selects tables read by sequential scans.
+const tableScansQuery = `
+SELECT relname AS "table", n_live_tup AS live_rows, seq_scan AS seq_scans,
+    seq_tup_read AS seq_rows_read, COALESCE(idx_scan, 0) AS index_scans
+FROM pg_stat_user_tables
+WHERE seq_scan > 0 AND n_live_tup >= ?
+ORDER BY seq_tup_read DESC
+LIMIT ?`
+
+// AnalyzeIndexes reports statements and tables that may be missing an
+// index, from the statistics PostgreSQL collected since they were last reset.
+func AnalyzeIndexes(ctx context.Context, db *gorm.DB, opts AnalyzeOptions) (*IndexReport, error) {
+	if !isPostgres(db) {
+		return nil, errors.New("index analysis needs PostgreSQL")
+	}
+
+	if opts.MinCalls <= 0 {
+		opts.MinCalls = defaultAnalyzeMinCalls
+	}
+	if opts.MinBlocksPerRow <= 0 {
+		opts.MinBlocksPerRow = defaultAnalyzeMinBlocksPerRow
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultAnalyzeLimit
+	}
+
+	db = db.WithContext(ctx)
+	report := &IndexReport{}
+
+	if err := db.Raw(tableScansQuery, analyzeMinTableRows, opts.Limit).Scan(&report.Tables).Error; err != nil {
+		return nil, fmt.Errorf("failed to read table statistics: %w", err)
+	}
+
+	if err := db.Raw(`SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements')`).
+		Scan(&report.StatementsAvailable).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up pg_stat_statements: %w", err)
+	}
+	if !report.StatementsAvailable {
+		return report, nil
+	}
+
+	var rows []struct {
+		Query         string
+		Calls         int64
+		Rows          int64
+		TotalExecTime float64 // milliseconds
+		MeanExecTime  float64 // milliseconds
+		BlocksPerRow  float64
+	}
+	if err := db.Raw(statementsQuery, opts.MinCalls, opts.MinBlocksPerRow, opts.Limit).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read pg_stat_statements: %w", err)
+	}
+
+	for _, row := range rows {
+		report.Candidates = append(report.Candidates, IndexCandidate{
+			Query:        row.Query,
+			Calls:        row.Calls,
+			Rows:         row.Rows,
+			TotalTime:    time.Duration(row.TotalExecTime * float64(time.Millisecond)),
+			MeanTime:     time.Duration(row.MeanExecTime * float64(time.Millisecond)),
+			BlocksPerRow: row.BlocksPerRow,
+		})
+	}
+
+	return report, nil
+}
+
+// AnalyzeIndexes connects to the configured database and reports
+// missing-index candidates. Unlike Run, it does not check or migrate the
+// schema.
+func (s *Server) AnalyzeIndexes(ctx context.Context, opts AnalyzeOptions) (*IndexReport, error) {
+	cfg := s.dbConfig()
+
+	db, err := connectDB(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = CloseDB(db, cfg.Logger) }()
+
+	return AnalyzeIndexes(ctx, db, opts)
+}
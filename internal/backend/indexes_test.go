@@ -0,0 +1,47 @@
+package backend
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Hot query indexes", func() {
+	var (
+		logger *slog.Logger
+		db     *gorm.DB
+	)
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError,
+		}))
+
+		db = newTestDB(logger)
+	})
+
+	readingIndexColumns := func() []string {
+		var columns []string
+		Expect(db.Raw(`SELECT name FROM pragma_index_info('idx_device_timestamp') ORDER BY seqno`).
+			Scan(&columns).Error).To(Succeed())
+		return columns
+	}
+
+	It("should create the indexes during migration", func() {
+		Expect(db.Migrator().HasIndex(&IoTDevice{}, "idx_last_seen")).To(BeTrue())
+		Expect(readingIndexColumns()).To(Equal([]string{"device_id", "timestamp"}))
+	})
+
+	It("should skip indexes that already exist", func() {
+		Expect(createHotQueryIndexes(db, logger)).To(Succeed())
+	})
+
+	It("should only analyze PostgreSQL databases", func() {
+		_, err := AnalyzeIndexes(context.Background(), db, AnalyzeOptions{})
+		Expect(err).To(MatchError("index analysis needs PostgreSQL"))
+	})
+})
@@ -0,0 +1,192 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+const (
+	// defaultInfluxMeasurement is used when InfluxConfig.Measurement is not set.
+	defaultInfluxMeasurement = "sensor_reading"
+	// influxSinkName names the InfluxDB sink in metrics and logs.
+	influxSinkName = "influx"
+)
+
+// InfluxConfig configures forwarding saved sensor readings to InfluxDB, or
+// any other endpoint accepting the line protocol, next to the database.
+type InfluxConfig struct {
+	// URL is the write endpoint with its query, such as
+	// http://influxdb:8086/api/v2/write?org=demo&bucket=iot for InfluxDB 2
+	// or http://influxdb:8086/write?db=iot for InfluxDB 1 (optional, empty =
+	// disabled). The precision parameter is set to seconds.
+	URL string
+	// Token is sent as "Authorization: Token <token>" (optional)
+	Token string
+	// Measurement names the measurement of the points (optional, default
+	// "sensor_reading")
+	Measurement string
+	// BatchSize is the most points sent in one request (optional, default 5000)
+	BatchSize int
+	// FlushInterval is the longest time a point waits for its batch to fill
+	// (optional, default 5 seconds)
+	FlushInterval time.Duration
+	// MaxPending is the most points buffered while the endpoint is
+	// unavailable; newer points are dropped (optional, default 100000)
+	MaxPending int
+}
+
+func (c InfluxConfig) validate() error {
+	if c.URL == "" {
+		return nil
+	}
+
+	if err := validateSinkURL("InfluxDB", c.URL); err != nil {
+		return err
+	}
+
+	return validateSinkOptions("InfluxDB", c.BatchSize, c.FlushInterval, c.MaxPending)
+}
+
+// InfluxSinkConfig holds the configuration for the InfluxDB sink.
+type InfluxSinkConfig struct {
+	Logger  *slog.Logger
+	Influx  InfluxConfig
+	Metrics *metrics.BackendMetrics // Optional metrics
+	Client  *http.Client            // Optional, default http.DefaultClient
+}
+
+// NewInfluxSink creates a sink writing readings to a line protocol endpoint.
+func NewInfluxSink(cfg *InfluxSinkConfig) (*ReadingSink, error) {
+	if cfg == nil {
+		return nil, errors.New("InfluxDB sink config cannot be nil")
+	}
+
+	if cfg.Logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+
+	if cfg.Influx.URL == "" {
+		return nil, errors.New("InfluxDB URL cannot be empty")
+	}
+
+	if err := cfg.Influx.validate(); err != nil {
+		return nil, err
+	}
+
+	// Readings have second resolution, so points are written in seconds
+	u, err := url.Parse(cfg.Influx.URL)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	query.Set("precision", "s")
+	u.RawQuery = query.Encode()
+
+	w := &influxWriter{
+		client:      cfg.Client,
+		url:         u.String(),
+		header:      http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+		measurement: cfg.Influx.Measurement,
+	}
+	if w.client == nil {
+		w.client = http.DefaultClient
+	}
+	if cfg.Influx.Token != "" {
+		w.header.Set("Authorization", "Token "+cfg.Influx.Token)
+	}
+	if w.measurement == "" {
+		w.measurement = defaultInfluxMeasurement
+	}
+
+	return newReadingSink(influxSinkName, cfg.Logger, w, sinkOptions{
+		batchSize:     cfg.Influx.BatchSize,
+		flushInterval: cfg.Influx.FlushInterval,
+		maxPending:    cfg.Influx.MaxPending,
+	}, cfg.Metrics), nil
+}
+
+// influxWriter writes readings in the line protocol.
+type influxWriter struct {
+	client      *http.Client
+	url         string
+	header      http.Header
+	measurement string
+}
+
+func (w *influxWriter) write(ctx context.Context, readings []SensorReading) (int, error) {
+	var body []byte
+	skipped := 0
+	for i := range readings {
+		n := len(body)
+		if n > 0 {
+			body = append(body, '\n')
+		}
+		line := appendInfluxLine(body, w.measurement, &readings[i])
+		if len(line) == len(body) {
+			// Nothing to write, drop the separator again
+			body = body[:n]
+			skipped++
+			continue
+		}
+		body = line
+	}
+
+	if len(body) == 0 {
+		return skipped, nil
+	}
+	return skipped, postBatch(ctx, w.client, w.url, w.header, body)
+}
+
+// influxTagEscaper escapes tag values and the measurement of a line.
+var influxTagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+// appendInfluxLine appends the line protocol representation of r to b. Fields
+// that are not finite numbers are left out, as the line protocol has no
+// representation for them; a reading without any field adds nothing.
+func appendInfluxLine(b []byte, measurement string, r *SensorReading) []byte {
+	if strings.ContainsAny(r.DeviceID, "\n\r") {
+		return b
+	}
+
+	start := len(b)
+	b = append(b, influxTagEscaper.Replace(measurement)...)
+	b = append(b, ",device_id="...)
+	b = append(b, influxTagEscaper.Replace(r.DeviceID)...)
+
+	sep := byte(' ')
+	fields := 0
+	for _, f := range []struct {
+		key   string
+		value float64
+	}{
+		{"temperature", r.Temperature},
+		{"humidity", r.Humidity},
+		{"pressure", r.Pressure},
+		{"battery_level", r.BatteryLevel},
+	} {
+		if math.IsNaN(f.value) || math.IsInf(f.value, 0) {
+			continue
+		}
+		b = append(b, sep)
+		b = append(b, f.key...)
+		b = append(b, '=')
+		b = strconv.AppendFloat(b, f.value, 'f', -1, 64)
+		sep = ','
+		fields++
+	}
+	if fields == 0 {
+		return b[:start]
+	}
+
+	b = append(b, ' ')
+	return strconv.AppendInt(b, r.Timestamp.Unix(), 10)
+}
@@ -0,0 +1,261 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/protobuf/proto"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// lineEndpoint is a line protocol endpoint recording the requests it takes.
+type lineEndpoint struct {
+	mu       sync.Mutex
+	status   int // Status of the next responses, 0 = 204
+	requests []*http.Request
+	bodies   []string
+}
+
+func (e *lineEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.requests = append(e.requests, r)
+	e.bodies = append(e.bodies, string(body))
+	if e.status != 0 {
+		http.Error(w, "unavailable", e.status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (e *lineEndpoint) setStatus(status int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.status = status
+}
+
+// lines returns the lines of all requests taken, in order.
+func (e *lineEndpoint) lines() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var lines []string
+	for _, body := range e.bodies {
+		lines = append(lines, strings.Split(body, "\n")...)
+	}
+	return lines
+}
+
+var _ = Describe("InfluxDB sink", func() {
+	var (
+		logger   *slog.Logger
+		endpoint *lineEndpoint
+		server   *httptest.Server
+	)
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+		endpoint = &lineEndpoint{}
+		server = httptest.NewServer(endpoint)
+		DeferCleanup(server.Close)
+	})
+
+	reading := func(deviceID string, unix int64, temperature float64) SensorReading {
+		return SensorReading{DeviceID: deviceID, Timestamp: time.Unix(unix, 0), Temperature: temperature, Humidity: 40, Pressure: 1013.25, BatteryLevel: 80}
+	}
+
+	newSink := func(influx InfluxConfig) *ReadingSink {
+		if influx.URL == "" {
+			influx.URL = server.URL + "/api/v2/write?org=demo&bucket=iot"
+		}
+		sink, err := NewInfluxSink(&InfluxSinkConfig{Logger: logger, Influx: influx, Metrics: consumerTestMetrics})
+		Expect(err).NotTo(HaveOccurred())
+		return sink
+	}
+
+	Describe("line protocol", func() {
+		It("should encode readings as points tagged with the device", func() {
+			r := reading("sensor-1", 1700000000, 21.5)
+			Expect(string(appendInfluxLine(nil, "sensor_reading", &r))).To(Equal(
+				"sensor_reading,device_id=sensor-1 temperature=21.5,humidity=40,pressure=1013.25,battery_level=80 1700000000"))
+		})
+
+		It("should escape the measurement and tag values", func() {
+			r := reading("lab 1,rack=2", 1700000000, 20)
+			Expect(string(appendInfluxLine(nil, "sensor reading", &r))).To(HavePrefix(`sensor\ reading,device_id=lab\ 1\,rack\=2 `))
+		})
+
+		It("should leave out fields that are not finite", func() {
+			r := reading("sensor-1", 1700000000, math.NaN())
+			r.Pressure = math.Inf(1)
+			Expect(string(appendInfluxLine(nil, "sensor_reading", &r))).To(Equal(
+				"sensor_reading,device_id=sensor-1 humidity=40,battery_level=80 1700000000"))
+
+			r = SensorReading{DeviceID: "sensor-1", Temperature: math.NaN(), Humidity: math.NaN(), Pressure: math.NaN(), BatteryLevel: math.NaN()}
+			Expect(appendInfluxLine([]byte("kept"), "sensor_reading", &r)).To(Equal([]byte("kept")))
+		})
+	})
+
+	Describe("NewInfluxSink", func() {
+		It("should reject invalid configuration", func() {
+			_, err := NewInfluxSink(&InfluxSinkConfig{Logger: logger})
+			Expect(err).To(MatchError("InfluxDB URL cannot be empty"))
+
+			_, err = NewInfluxSink(&InfluxSinkConfig{Logger: logger, Influx: InfluxConfig{URL: "influxdb:8086/write?db=iot&p=secret"}})
+			Expect(err).To(MatchError("InfluxDB URL must be an http or https URL"))
+
+			_, err = NewInfluxSink(&InfluxSinkConfig{Logger: logger, Influx: InfluxConfig{URL: server.URL, BatchSize: -1}})
+			Expect(err).To(MatchError(ContainSubstring("cannot be negative")))
+		})
+	})
+
+	It("should write batches with the token and second precision", func() {
+		sink := newSink(InfluxConfig{Token: "secret", BatchSize: 2, FlushInterval: time.Hour})
+		sink.Start(context.Background())
+		DeferCleanup(func() { Expect(sink.Stop(context.Background())).To(Succeed()) })
+
+		sink.Add(reading("sensor-1", 1700000000, 21))
+		Consistently(endpoint.lines, 100*time.Millisecond).Should(BeEmpty())
+
+		// A full batch is written without waiting for the flush interval
+		sink.Add(reading("sensor-2", 1700000000, 19))
+		Eventually(endpoint.lines).Should(HaveLen(2))
+
+		endpoint.mu.Lock()
+		req := endpoint.requests[0]
+		endpoint.mu.Unlock()
+		Expect(req.Method).To(Equal(http.MethodPost))
+		Expect(req.URL.Path).To(Equal("/api/v2/write"))
+		Expect(req.URL.Query().Get("bucket")).To(Equal("iot"))
+		Expect(req.URL.Query().Get("precision")).To(Equal("s"))
+		Expect(req.Header.Get("Authorization")).To(Equal("Token secret"))
+		Expect(endpoint.lines()[1]).To(HavePrefix("sensor_reading,device_id=sensor-2 "))
+	})
+
+	It("should retry failed batches on the next flush", func() {
+		endpoint.setStatus(http.StatusServiceUnavailable)
+		sink := newSink(InfluxConfig{FlushInterval: 20 * time.Millisecond})
+		sink.Start(context.Background())
+		DeferCleanup(func() { Expect(sink.Stop(context.Background())).To(Succeed()) })
+
+		sink.Add(reading("sensor-1", 1700000000, 21))
+		Eventually(func() int {
+			endpoint.mu.Lock()
+			defer endpoint.mu.Unlock()
+			return len(endpoint.requests)
+		}).Should(BeNumerically(">=", 2))
+		Expect(sink.pendingCount()).To(Equal(1))
+
+		endpoint.setStatus(0)
+		Eventually(sink.pendingCount).Should(BeZero())
+		Expect(endpoint.lines()).To(ContainElement(HavePrefix("sensor_reading,device_id=sensor-1 ")))
+	})
+
+	It("should drop batches the endpoint rejects", func() {
+		endpoint.setStatus(http.StatusBadRequest)
+		sink := newSink(InfluxConfig{})
+		rejected := testutil.ToFloat64(consumerTestMetrics.SinkPointsTotal.WithLabelValues(influxSinkName, "rejected"))
+
+		sink.Add(reading("sensor-1", 1700000000, 21), reading("sensor-2", 1700000000, 19))
+		Expect(sink.flush(context.Background())).To(Succeed())
+
+		Expect(sink.pendingCount()).To(BeZero())
+		Expect(testutil.ToFloat64(consumerTestMetrics.SinkPointsTotal.WithLabelValues(influxSinkName, "rejected"))).To(Equal(rejected + 2))
+	})
+
+	It("should drop readings beyond the pending limit", func() {
+		sink := newSink(InfluxConfig{MaxPending: 2})
+		dropped := testutil.ToFloat64(consumerTestMetrics.SinkPointsTotal.WithLabelValues(influxSinkName, "dropped"))
+
+		sink.Add(reading("sensor-1", 1700000000, 21), reading("sensor-1", 1700000060, 22), reading("sensor-1", 1700000120, 23))
+
+		Expect(sink.pendingCount()).To(Equal(2))
+		Expect(testutil.ToFloat64(consumerTestMetrics.SinkPointsTotal.WithLabelValues(influxSinkName, "dropped"))).To(Equal(dropped + 1))
+	})
+
+	It("should write the buffered readings on stop", func() {
+		sink := newSink(InfluxConfig{FlushInterval: time.Hour})
+		sink.Start(context.Background())
+
+		sink.Add(reading("sensor-1", 1700000000, 21))
+		Expect(sink.Stop(context.Background())).To(Succeed())
+		Expect(endpoint.lines()).To(HaveLen(1))
+	})
+
+	It("should report readings it could not write on stop", func() {
+		endpoint.setStatus(http.StatusInternalServerError)
+		sink := newSink(InfluxConfig{FlushInterval: time.Hour})
+		sink.Start(context.Background())
+
+		sink.Add(reading("sensor-1", 1700000000, 21))
+		Expect(sink.Stop(context.Background())).To(MatchError(HavePrefix("1 readings not written: write failed with status 500")))
+	})
+
+	Describe("Consumer", func() {
+		var (
+			c    *Consumer
+			sink *ReadingSink
+		)
+
+		BeforeEach(func() {
+			db := newTestDB(logger)
+			Expect(db.Create(&IoTDevice{DeviceID: "sensor-1", Location: "Lab", LastSeen: time.Now()}).Error).To(Succeed())
+
+			sink = newSink(InfluxConfig{})
+			c = &Consumer{logger: logger, db: db, queueName: "influx-test", sinks: []*ReadingSink{sink}}
+		})
+
+		deliver := func(messageID string, batch bool, readings ...*iotv1.SensorReading) {
+			var (
+				body []byte
+				err  error
+			)
+			delivery := amqp.Delivery{Acknowledger: &fakeAcknowledger{}, MessageId: messageID}
+			if batch {
+				delivery.Type = iotv1.MessageTypeSensorReadingBatch
+				body, err = proto.Marshal(&iotv1.SensorReadingBatch{Readings: readings})
+			} else {
+				body, err = proto.Marshal(readings[0])
+			}
+			Expect(err).NotTo(HaveOccurred())
+			delivery.Body = body
+			c.handleDelivery(context.Background(), delivery)
+		}
+
+		It("should forward saved readings once", func() {
+			deliver("single-1", false, &iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: 1700000000, Temperature: 21})
+			deliver("single-1", false, &iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: 1700000000, Temperature: 21})
+			deliver("batch-1", true,
+				&iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: 1700000060, Temperature: 22},
+				&iotv1.SensorReading{DeviceId: "unknown", Timestamp: 1700000060, Temperature: 19},
+			)
+			deliver("batch-1", true, &iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: 1700000060, Temperature: 22})
+
+			Expect(sink.flush(context.Background())).To(Succeed())
+			Expect(endpoint.lines()).To(Equal([]string{
+				"sensor_reading,device_id=sensor-1 temperature=21,humidity=0,pressure=0,battery_level=0 1700000000",
+				"sensor_reading,device_id=sensor-1 temperature=22,humidity=0,pressure=0,battery_level=0 1700000060",
+			}))
+		})
+
+		It("should not forward readings of unknown devices", func() {
+			deliver("single-2", false, &iotv1.SensorReading{DeviceId: "unknown", Timestamp: 1700000000})
+
+			Expect(sink.pendingCount()).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,146 @@
+package backend
+
+import (
+	"fmt"
+	"slices"
+
+	"google.golang.org/grpc"
+)
+
+// Names of the built-in interceptors of the gRPC server, in chain order.
+const (
+	// InterceptorRecovery turns panics into Internal errors. It comes first
+	// so panics in the other interceptors are caught too.
+	InterceptorRecovery = "recovery"
+	// InterceptorDeadline bounds every call by the request timeout. It comes
+	// before fault injection so injected delays count against it.
+	InterceptorDeadline = "deadline"
+	// InterceptorFaults injects faults; only with ServerConfig.Faults.
+	InterceptorFaults = "faults"
+	// InterceptorDeprecation marks calls through deprecated method names.
+	InterceptorDeprecation = "deprecation"
+	// InterceptorValidation rejects requests breaking their proto validation
+	// rules. It comes before quotas so per-device quotas only track valid
+	// device IDs.
+	InterceptorValidation = "validation"
+	// InterceptorQuotas enforces the request quotas.
+	InterceptorQuotas = "quotas"
+	// InterceptorCompression compresses large responses; only with
+	// ServerConfig.CompressMinSize.
+	InterceptorCompression = "compression"
+)
+
+// Interceptor is a named unary interceptor in the chain of the gRPC server.
+// The first interceptor of a chain is the outermost one: it sees a call
+// first and its response last.
+type Interceptor struct {
+	// Name identifies the interceptor in the chain, e.g. for InsertBefore.
+	Name string
+	// Unary is the interceptor.
+	Unary grpc.UnaryServerInterceptor
+}
+
+// InterceptorChain customizes the interceptor chain of the gRPC server. It
+// gets the built-in chain and returns the chain to install, e.g. with
+// InsertAfter(chain, InterceptorRecovery, auth) to authenticate calls before
+// the others run, or with interceptors removed by slices.DeleteFunc.
+type InterceptorChain func(chain []Interceptor) []Interceptor
+
+// InsertBefore returns chain with interceptors inserted before the
+// interceptor named name, or at the end if the chain has no such interceptor.
+func InsertBefore(chain []Interceptor, name string, interceptors ...Interceptor) []Interceptor {
+	i := slices.IndexFunc(chain, func(ic Interceptor) bool { return ic.Name == name })
+	if i < 0 {
+		i = len(chain)
+	}
+	return slices.Insert(slices.Clone(chain), i, interceptors...)
+}
+
+// InsertAfter returns chain with interceptors inserted after the interceptor
+// named name, or at the end if the chain has no such interceptor.
+func InsertAfter(chain []Interceptor, name string, interceptors ...Interceptor) []Interceptor {
+	i := slices.IndexFunc(chain, func(ic Interceptor) bool { return ic.Name == name })
+	if i < 0 {
+		i = len(chain) - 1
+	}
+	return slices.Insert(slices.Clone(chain), i+1, interceptors...)
+}
+
+// interceptorChain returns the interceptor chain of the gRPC server: the
+// built-in interceptors, customized by ServerConfig.Interceptors.
+func (s *Server) interceptorChain(quotas *quotaLimiter) ([]Interceptor, error) {
+	requestTimeout := s.config.RequestTimeout
+	if requestTimeout == 0 {
+		requestTimeout = defaultRequestTimeout
+	}
+	exportTimeout := s.config.ExportTimeout
+	if exportTimeout == 0 {
+		exportTimeout = defaultExportTimeout
+	}
+
+	chain := []Interceptor{
+		{Name: InterceptorRecovery, Unary: recoveryInterceptor(s.logger)},
+		{Name: InterceptorDeadline, Unary: deadlineInterceptor(s.logger, requestTimeout, exportTimeout)},
+	}
+	if s.config.Faults != nil {
+		chain = append(chain, Interceptor{Name: InterceptorFaults, Unary: s.config.Faults.UnaryServerInterceptor()})
+	}
+	chain = append(chain,
+		Interceptor{Name: InterceptorDeprecation, Unary: deprecationInterceptor(s.logger)},
+		Interceptor{Name: InterceptorValidation, Unary: validationInterceptor()},
+		Interceptor{Name: InterceptorQuotas, Unary: quotas.unaryInterceptor()},
+	)
+	if s.config.CompressMinSize > 0 {
+		chain = append(chain, Interceptor{Name: InterceptorCompression, Unary: compressionInterceptor(s.config.CompressMinSize)})
+	}
+
+	if s.config.Interceptors == nil {
+		return chain, nil
+	}
+
+	chain = s.config.Interceptors(chain)
+	seen := make(map[string]bool, len(chain))
+	for i, ic := range chain {
+		switch {
+		case ic.Name == "":
+			return nil, fmt.Errorf("interceptor %d has no name", i)
+		case ic.Unary == nil:
+			return nil, fmt.Errorf("interceptor %q is nil", ic.Name)
+		case seen[ic.Name]:
+			return nil, fmt.Errorf("interceptor %q is in the chain twice", ic.Name)
+		}
+		seen[ic.Name] = true
+	}
+	return chain, nil
+}
+
+// UnaryInterceptors returns the interceptor chain the gRPC server installs
+// for cfg, with quotas enforced by cfg.Quotas. Tests serving an IoTServiceImpl
+// on their own gRPC server install it so calls pass the same checks as in
+// production. Only the interceptor settings of cfg and its logger are used.
+func UnaryInterceptors(cfg *ServerConfig) ([]grpc.UnaryServerInterceptor, error) {
+	s := &Server{logger: cfg.Logger, config: cfg}
+	chain, err := s.interceptorChain(newQuotaLimiter(cfg.Quotas))
+	if err != nil {
+		return nil, fmt.Errorf("invalid interceptor chain: %w", err)
+	}
+	return unaryInterceptors(chain), nil
+}
+
+// unaryInterceptors returns the interceptors of chain in order.
+func unaryInterceptors(chain []Interceptor) []grpc.UnaryServerInterceptor {
+	interceptors := make([]grpc.UnaryServerInterceptor, len(chain))
+	for i, ic := range chain {
+		interceptors[i] = ic.Unary
+	}
+	return interceptors
+}
+
+// interceptorNames returns the names of the interceptors of chain in order.
+func interceptorNames(chain []Interceptor) []string {
+	names := make([]string, len(chain))
+	for i, ic := range chain {
+		names[i] = ic.Name
+	}
+	return names
+}
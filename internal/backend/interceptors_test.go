@@ -0,0 +1,118 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"procodus.dev/demo-app/pkg/faults"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Interceptor chain", func() {
+	var logger *slog.Logger
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	})
+
+	// chainNames returns the interceptor names of a backend with config.
+	chainNames := func(config *ServerConfig) []string {
+		chain, err := (&Server{logger: logger, config: config}).interceptorChain(newQuotaLimiter(QuotaConfig{}))
+		Expect(err).NotTo(HaveOccurred())
+		return interceptorNames(chain)
+	}
+
+	// named returns an interceptor passing calls through.
+	named := func(name string) Interceptor {
+		return Interceptor{Name: name, Unary: func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(ctx, req)
+		}}
+	}
+
+	It("should install the built-in interceptors in order", func() {
+		Expect(chainNames(&ServerConfig{})).To(Equal([]string{
+			InterceptorRecovery, InterceptorDeadline, InterceptorDeprecation, InterceptorValidation, InterceptorQuotas,
+		}))
+
+		injector, err := faults.New(faults.Config{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(chainNames(&ServerConfig{Faults: injector, CompressMinSize: 1024})).To(Equal([]string{
+			InterceptorRecovery, InterceptorDeadline, InterceptorFaults, InterceptorDeprecation,
+			InterceptorValidation, InterceptorQuotas, InterceptorCompression,
+		}))
+	})
+
+	It("should insert interceptors relative to others", func() {
+		chain := []Interceptor{named("a"), named("b")}
+
+		Expect(interceptorNames(InsertBefore(chain, "b", named("x"), named("y")))).To(Equal([]string{"a", "x", "y", "b"}))
+		Expect(interceptorNames(InsertAfter(chain, "a", named("x")))).To(Equal([]string{"a", "x", "b"}))
+		Expect(interceptorNames(InsertBefore(chain, "missing", named("x")))).To(Equal([]string{"a", "b", "x"}))
+		Expect(interceptorNames(InsertAfter(chain, "missing", named("x")))).To(Equal([]string{"a", "b", "x"}))
+		Expect(interceptorNames(chain)).To(Equal([]string{"a", "b"}))
+	})
+
+	It("should install the chain returned by the configuration", func() {
+		names := chainNames(&ServerConfig{Interceptors: func(chain []Interceptor) []Interceptor {
+			return InsertAfter(chain, InterceptorRecovery, named("auth"), named("logging"))
+		}})
+		Expect(names).To(Equal([]string{
+			InterceptorRecovery, "auth", "logging", InterceptorDeadline, InterceptorDeprecation, InterceptorValidation, InterceptorQuotas,
+		}))
+	})
+
+	DescribeTable("should reject invalid chains",
+		func(chain []Interceptor, message string) {
+			backend := &Server{logger: logger, config: &ServerConfig{Interceptors: func([]Interceptor) []Interceptor { return chain }}}
+			_, err := backend.newGRPCServer(iotv1.UnimplementedIoTServiceServer{}, newQuotaLimiter(QuotaConfig{}))
+			Expect(err).To(MatchError("invalid interceptor chain: " + message))
+		},
+		Entry("unnamed", []Interceptor{named("")}, "interceptor 0 has no name"),
+		Entry("nil", []Interceptor{{Name: "auth"}}, `interceptor "auth" is nil`),
+		Entry("duplicate", []Interceptor{named("auth"), named("auth")}, `interceptor "auth" is in the chain twice`),
+	)
+
+	It("should run custom interceptors on calls", func() {
+		// auth rejects calls without a token, before requests are validated
+		auth := Interceptor{Name: "auth", Unary: func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			if len(md.Get("authorization")) == 0 {
+				return nil, status.Error(codes.Unauthenticated, "missing token")
+			}
+			return handler(ctx, req)
+		}}
+		backend := &Server{logger: logger, config: &ServerConfig{Interceptors: func(chain []Interceptor) []Interceptor {
+			return InsertBefore(chain, InterceptorValidation, auth)
+		}}}
+
+		server, err := backend.newGRPCServer(&devicesService{devices: 1}, newQuotaLimiter(QuotaConfig{}))
+		Expect(err).NotTo(HaveOccurred())
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		go func() { _ = server.Serve(lis) }()
+		DeferCleanup(server.Stop)
+
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		client := iotv1.NewIoTServiceClient(conn)
+
+		_, err = client.GetDevice(context.Background(), &iotv1.GetDeviceByIDRequest{})
+		Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+		resp, err := client.GetAllDevice(ctx, &iotv1.GetAllDevicesRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetDevices()).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,224 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultLeaderLockKey is the PostgreSQL advisory lock key held by the
+	// leader when not configured.
+	defaultLeaderLockKey int64 = 0x64656d6f6170 // "demoap"
+	// defaultLeaderInterval is how often followers retry the lock and the
+	// leader checks its lock connection when not configured.
+	defaultLeaderInterval = 15 * time.Second
+	// leaderReleaseTimeout bounds unlocking after leadership ends.
+	leaderReleaseTimeout = 5 * time.Second
+)
+
+// LeaderElector elects one leader among backend replicas sharing a PostgreSQL
+// database using a session-level advisory lock. The leader holds the lock on a
+// dedicated connection; if that connection dies, PostgreSQL releases the lock
+// and another replica takes over on its next attempt.
+type LeaderElector struct {
+	logger    *slog.Logger
+	db        *gorm.DB
+	done      chan struct{}
+	cancel    context.CancelFunc
+	interval  time.Duration
+	lockKey   int64
+	onElected func(ctx context.Context) error
+	onDemoted func()
+	leader    atomic.Bool
+}
+
+// LeaderElectorConfig holds the configuration for the LeaderElector.
+type LeaderElectorConfig struct {
+	Logger   *slog.Logger
+	DB       *gorm.DB      // Must be PostgreSQL
+	Interval time.Duration // Time between lock attempts and connection checks (optional, default 15 seconds)
+	// LockKey is the advisory lock key (optional). Replicas that elect a
+	// leader among each other must use the same key.
+	LockKey int64
+
+	// OnElected starts the leader's work. ctx is canceled when leadership
+	// ends. If it fails, leadership is given up and retried later.
+	OnElected func(ctx context.Context) error
+	// OnDemoted stops the leader's work. It is called once leadership ends,
+	// including after OnElected failed.
+	OnDemoted func()
+}
+
+// NewLeaderElector creates a new LeaderElector instance.
+func NewLeaderElector(cfg *LeaderElectorConfig) (*LeaderElector, error) {
+	if cfg == nil {
+		return nil, errors.New("leader elector config cannot be nil")
+	}
+
+	if cfg.Logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+
+	if cfg.DB == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+
+	if !isPostgres(cfg.DB) {
+		return nil, errors.New("leader election requires PostgreSQL")
+	}
+
+	if cfg.Interval < 0 {
+		return nil, errors.New("leader interval cannot be negative")
+	}
+
+	if cfg.OnElected == nil || cfg.OnDemoted == nil {
+		return nil, errors.New("leader callbacks cannot be nil")
+	}
+
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = defaultLeaderInterval
+	}
+
+	lockKey := cfg.LockKey
+	if lockKey == 0 {
+		lockKey = defaultLeaderLockKey
+	}
+
+	return &LeaderElector{
+		logger:    cfg.Logger,
+		db:        cfg.DB,
+		done:      make(chan struct{}),
+		interval:  interval,
+		lockKey:   lockKey,
+		onElected: cfg.OnElected,
+		onDemoted: cfg.OnDemoted,
+	}, nil
+}
+
+// IsLeader reports whether this replica currently leads.
+func (e *LeaderElector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Start campaigns for leadership immediately and then on every interval until
+// Stop is called or ctx is canceled.
+func (e *LeaderElector) Start(ctx context.Context) {
+	ctx, e.cancel = context.WithCancel(ctx)
+
+	e.logger.Info("starting leader election", "interval", e.interval)
+
+	go func() {
+		defer close(e.done)
+
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := e.campaign(ctx, ticker.C); err != nil && ctx.Err() == nil {
+				e.logger.Error("leader election failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the election, demoting this replica if it leads, and waits for
+// OnDemoted to return.
+func (e *LeaderElector) Stop() {
+	if e.cancel == nil {
+		return
+	}
+
+	e.cancel()
+	<-e.done
+
+	e.logger.Info("leader election stopped")
+}
+
+// campaign tries to take the advisory lock once. If it succeeds, it leads until
+// ctx is done or the lock connection fails.
+func (e *LeaderElector) campaign(ctx context.Context, tick <-chan time.Time) error {
+	sqlDB, err := e.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database handle: %w", err)
+	}
+
+	// Session-level advisory locks belong to a connection, so the lock is
+	// taken and held on one connection taken out of the pool.
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", e.lockKey).Scan(&acquired); err != nil {
+		return fmt.Errorf("failed to try leader lock: %w", err)
+	}
+
+	if !acquired {
+		return nil
+	}
+
+	defer e.release(conn)
+
+	return e.lead(ctx, conn, tick)
+}
+
+// lead runs the leader's work until ctx is done or the lock connection fails.
+func (e *LeaderElector) lead(ctx context.Context, conn *sql.Conn, tick <-chan time.Time) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	e.logger.Info("elected leader")
+
+	defer func() {
+		e.leader.Store(false)
+		e.onDemoted()
+		e.logger.Info("leadership ended")
+	}()
+
+	if err := e.onElected(ctx); err != nil {
+		return fmt.Errorf("failed to start leader work: %w", err)
+	}
+	e.leader.Store(true)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick:
+		}
+
+		if err := conn.PingContext(ctx); err != nil && ctx.Err() == nil {
+			return fmt.Errorf("lost leader lock connection: %w", err)
+		}
+	}
+}
+
+// release unlocks the advisory lock. If unlocking fails, the connection is
+// discarded instead of returned to the pool, which ends its session and with
+// it the lock.
+func (e *LeaderElector) release(conn *sql.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), leaderReleaseTimeout)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", e.lockKey); err != nil {
+		e.logger.Warn("failed to release leader lock, discarding connection", "error", err)
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+}
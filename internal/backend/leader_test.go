@@ -0,0 +1,40 @@
+package backend_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"procodus.dev/demo-app/internal/backend"
+)
+
+var _ = Describe("LeaderElector", func() {
+	It("should reject invalid configuration", func() {
+		logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+		_, err := backend.NewLeaderElector(nil)
+		Expect(err).To(HaveOccurred())
+
+		_, err = backend.NewLeaderElector(&backend.LeaderElectorConfig{})
+		Expect(err).To(MatchError(ContainSubstring("logger")))
+
+		_, err = backend.NewLeaderElector(&backend.LeaderElectorConfig{Logger: logger})
+		Expect(err).To(MatchError(ContainSubstring("database")))
+	})
+
+	It("should require PostgreSQL", func() {
+		logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+		db := backend.NewTestDB(logger)
+
+		_, err := backend.NewLeaderElector(&backend.LeaderElectorConfig{
+			Logger:    logger,
+			DB:        db,
+			OnElected: func(context.Context) error { return nil },
+			OnDemoted: func() {},
+		})
+		Expect(err).To(MatchError(ContainSubstring("requires PostgreSQL")))
+	})
+})
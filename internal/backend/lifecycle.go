@@ -0,0 +1,124 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultStopTimeout bounds stopping a component that sets no stop timeout.
+const defaultStopTimeout = 10 * time.Second
+
+// Component is a subsystem of the backend server, such as the database or the
+// gRPC server, that a lifecycle starts and stops.
+type Component interface {
+	// Start starts the component. Work that outlives Start runs in goroutines
+	// until Stop is called or ctx is canceled.
+	Start(ctx context.Context) error
+	// Stop stops the component, giving up when ctx is done.
+	Stop(ctx context.Context) error
+}
+
+// componentFuncs adapts a start and a stop function to a Component. Either
+// may be nil.
+type componentFuncs struct {
+	start func(ctx context.Context) error
+	stop  func(ctx context.Context) error
+}
+
+func (c componentFuncs) Start(ctx context.Context) error {
+	if c.start == nil {
+		return nil
+	}
+	return c.start(ctx)
+}
+
+func (c componentFuncs) Stop(ctx context.Context) error {
+	if c.stop == nil {
+		return nil
+	}
+	return c.stop(ctx)
+}
+
+// lifecycleComponent is a named Component with its stop timeout.
+type lifecycleComponent struct {
+	Component
+
+	name        string
+	stopTimeout time.Duration // optional, 0 = defaultStopTimeout
+}
+
+// lifecycle starts components in order and stops the started ones in reverse
+// order, so each component stops before the components it depends on.
+type lifecycle struct {
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	started []lifecycleComponent
+}
+
+func newLifecycle(logger *slog.Logger) *lifecycle {
+	return &lifecycle{logger: logger}
+}
+
+// start starts c and, if it succeeds, registers it to be stopped by stop.
+func (l *lifecycle) start(ctx context.Context, c lifecycleComponent) error {
+	if err := c.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start %s: %w", c.name, err)
+	}
+
+	l.mu.Lock()
+	l.started = append(l.started, c)
+	l.mu.Unlock()
+
+	return nil
+}
+
+// stop stops the started components in reverse start order. Each gets its
+// own stop timeout; one failing or timing out does not keep the others
+// running. The returned error joins the errors of all components.
+func (l *lifecycle) stop(ctx context.Context) error {
+	l.mu.Lock()
+	started := l.started
+	l.started = nil
+	l.mu.Unlock()
+
+	var errs []error
+	for _, c := range slices.Backward(started) {
+		l.logger.Info("stopping component", "component", c.name)
+		if err := stopComponent(ctx, c); err != nil {
+			l.logger.Error("failed to stop component", "component", c.name, "error", err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", c.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// stopComponent stops c within its stop timeout. A component that does not
+// return in time is left behind, its Stop still running.
+func stopComponent(ctx context.Context, c lifecycleComponent) error {
+	timeout := c.stopTimeout
+	if timeout == 0 {
+		timeout = defaultStopTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("did not stop within %s", timeout)
+	}
+}
@@ -0,0 +1,176 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"procodus.dev/demo-app/pkg/mq/inmem"
+)
+
+var _ = Describe("Component lifecycle", func() {
+	var (
+		logger *slog.Logger
+		l      *lifecycle
+		events []string
+	)
+
+	// recorder returns a component that records its start and stop.
+	recorder := func(name string, startErr, stopErr error) lifecycleComponent {
+		return lifecycleComponent{name: name, Component: componentFuncs{
+			start: func(context.Context) error {
+				events = append(events, "start "+name)
+				return startErr
+			},
+			stop: func(context.Context) error {
+				events = append(events, "stop "+name)
+				return stopErr
+			},
+		}}
+	}
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+		l = newLifecycle(logger)
+		events = nil
+	})
+
+	It("should stop components in reverse start order", func() {
+		for _, name := range []string{"database", "consumer", "server"} {
+			Expect(l.start(context.Background(), recorder(name, nil, nil))).To(Succeed())
+		}
+
+		Expect(l.stop(context.Background())).To(Succeed())
+		Expect(events).To(Equal([]string{
+			"start database", "start consumer", "start server",
+			"stop server", "stop consumer", "stop database",
+		}))
+	})
+
+	It("should not stop a component that failed to start", func() {
+		Expect(l.start(context.Background(), recorder("database", nil, nil))).To(Succeed())
+		err := l.start(context.Background(), recorder("consumer", errors.New("queue missing"), nil))
+		Expect(err).To(MatchError("failed to start consumer: queue missing"))
+
+		Expect(l.stop(context.Background())).To(Succeed())
+		Expect(events).To(Equal([]string{"start database", "start consumer", "stop database"}))
+	})
+
+	It("should stop every component and join their errors", func() {
+		Expect(l.start(context.Background(), recorder("database", nil, errors.New("close failed")))).To(Succeed())
+		Expect(l.start(context.Background(), recorder("consumer", nil, nil))).To(Succeed())
+		Expect(l.start(context.Background(), recorder("server", nil, errors.New("stop failed")))).To(Succeed())
+
+		err := l.stop(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("failed to stop server: stop failed")))
+		Expect(err).To(MatchError(ContainSubstring("failed to stop database: close failed")))
+		Expect(events).To(ContainElements("stop server", "stop consumer", "stop database"))
+	})
+
+	It("should give up on a component that does not stop within its timeout", func() {
+		Expect(l.start(context.Background(), recorder("database", nil, nil))).To(Succeed())
+
+		release := make(chan struct{})
+		defer close(release)
+		Expect(l.start(context.Background(), lifecycleComponent{
+			name:        "stuck",
+			stopTimeout: 50 * time.Millisecond,
+			Component: componentFuncs{stop: func(context.Context) error {
+				<-release
+				return nil
+			}},
+		})).To(Succeed())
+
+		err := l.stop(context.Background())
+		Expect(err).To(MatchError("failed to stop stuck: did not stop within 50ms"))
+		Expect(events).To(ContainElement("stop database"))
+	})
+
+	It("should pass the stop timeout to the component", func() {
+		var deadline time.Time
+		Expect(l.start(context.Background(), lifecycleComponent{
+			name:        "server",
+			stopTimeout: time.Minute,
+			Component: componentFuncs{stop: func(ctx context.Context) error {
+				deadline, _ = ctx.Deadline()
+				return nil
+			}},
+		})).To(Succeed())
+
+		Expect(l.stop(context.Background())).To(Succeed())
+		Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Minute), 5*time.Second))
+	})
+
+	It("should stop components only once", func() {
+		Expect(l.start(context.Background(), recorder("database", nil, nil))).To(Succeed())
+
+		Expect(l.stop(context.Background())).To(Succeed())
+		Expect(l.stop(context.Background())).To(Succeed())
+		Expect(events).To(Equal([]string{"start database", "stop database"}))
+	})
+
+	Describe("Server Run", func() {
+		var config *ServerConfig
+
+		BeforeEach(func() {
+			dir := GinkgoT().TempDir()
+			config = &ServerConfig{
+				Logger:          logger,
+				DBDriver:        DriverSQLite,
+				DBName:          filepath.Join(dir, "backend.db"),
+				MQBroker:        inmem.NewBroker(10),
+				QueueName:       "sensor-data",
+				DeviceQueueName: "devices",
+				GRPCBindAddress: "unix://" + filepath.Join(dir, "grpc.sock"),
+			}
+		})
+
+		It("should start all components and stop them when the context is canceled", func() {
+			server, err := NewServer(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- server.Run(ctx) }()
+
+			socket := filepath.Join(filepath.Dir(config.DBName), "grpc.sock")
+			Eventually(func() error {
+				conn, err := net.Dial("unix", socket)
+				if err == nil {
+					_ = conn.Close()
+				}
+				return err
+			}).Should(Succeed())
+
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+			Expect(server.lifecycle.started).To(BeEmpty())
+		})
+
+		It("should stop the started components when a later one fails to start", func() {
+			// A regular file in place of the socket directory makes listening fail
+			blocker := filepath.Join(filepath.Dir(config.DBName), "blocker")
+			Expect(os.WriteFile(blocker, nil, 0o600)).To(Succeed())
+			config.GRPCBindAddress = "unix://" + filepath.Join(blocker, "grpc.sock")
+
+			server, err := NewServer(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = server.Run(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("failed to start gRPC server")))
+			Expect(server.lifecycle.started).To(BeEmpty())
+
+			// The database was closed on the way out
+			sqlDB, err := server.db.DB()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sqlDB.Ping()).NotTo(Succeed())
+		})
+	})
+})
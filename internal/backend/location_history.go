@@ -0,0 +1,72 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+const (
+	// defaultLocationHistoryLimit is used when GetDeviceLocationHistory does not set limit.
+	defaultLocationHistoryLimit = 100
+	// maxLocationHistoryLimit bounds limit of GetDeviceLocationHistory.
+	maxLocationHistoryLimit = 1000
+)
+
+// GetDeviceLocationHistory returns the most recent locations of a device,
+// oldest first so they can be drawn as a path.
+func (s *IoTServiceImpl) GetDeviceLocationHistory(ctx context.Context, req *iotv1.GetDeviceLocationHistoryRequest) (resp *iotv1.GetDeviceLocationHistoryResponse, err error) {
+	done := s.trackRequest("GetDeviceLocationHistory")
+	defer func() { done(err) }()
+
+	if req.GetDeviceId() == "" {
+		return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "device_id", "cannot be empty")
+	}
+
+	limit := int(req.GetLimit())
+	switch {
+	case limit == 0:
+		limit = defaultLocationHistoryLimit
+	case limit < 0 || limit > maxLocationHistoryLimit:
+		return nil, iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "limit", fmt.Sprintf("must be between 1 and %d", maxLocationHistoryLimit))
+	}
+
+	if err := s.db.WithContext(ctx).Where("device_id = ?", req.GetDeviceId()).First(&IoTDevice{}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, iotv1.DeviceNotFoundError(req.GetDeviceId())
+		}
+		s.logger.Error("failed to fetch device", "device_id", req.GetDeviceId(), "error", err)
+		return nil, databaseError("failed to fetch device")
+	}
+
+	var history []DeviceLocationHistory
+	if err := s.db.WithContext(ctx).
+		Where("device_id = ?", req.GetDeviceId()).
+		Order("recorded_at DESC").
+		Order("id DESC").
+		Limit(limit).
+		Find(&history).Error; err != nil {
+		s.logger.Error("failed to fetch device location history", "device_id", req.GetDeviceId(), "error", err)
+		return nil, databaseError("failed to fetch device location history")
+	}
+	slices.Reverse(history)
+
+	locations := make([]*iotv1.DeviceLocation, len(history))
+	for i := range history {
+		locations[i] = &iotv1.DeviceLocation{
+			Latitude:  history[i].Latitude,
+			Longitude: history[i].Longitude,
+			IpAddress: history[i].IPAddress,
+			Timestamp: history[i].RecordedAt.Unix(),
+		}
+	}
+
+	return &iotv1.GetDeviceLocationHistoryResponse{
+		Locations: locations,
+	}, nil
+}
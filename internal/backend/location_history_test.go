@@ -0,0 +1,100 @@
+package backend
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Device location history", func() {
+	var (
+		ctx     context.Context
+		db      *gorm.DB
+		c       *DeviceConsumer
+		service *IoTServiceImpl
+		start   time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError,
+		}))
+
+		db = newTestDB(logger)
+
+		c = &DeviceConsumer{logger: logger, db: db, queueName: "location-test"}
+		service = &IoTServiceImpl{logger: logger, db: db}
+		start = time.Now().Add(-time.Hour).Truncate(time.Second)
+	})
+
+	// report delivers a device message placing sensor-1 at lat/long with ip,
+	// sent offset after start.
+	report := func(offset time.Duration, lat, long float32, ip string) {
+		body, err := proto.Marshal(&iotv1.IoTDevice{
+			DeviceId:  "sensor-1",
+			Location:  "Lab",
+			Timestamp: start.Add(offset).Unix(),
+			Latitude:  lat,
+			Longitude: long,
+			IpAddress: ip,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		c.handleDelivery(ctx, amqp.Delivery{Acknowledger: &fakeAcknowledger{}, Body: body})
+	}
+
+	history := func(limit int32) []*iotv1.DeviceLocation {
+		resp, err := service.GetDeviceLocationHistory(ctx, &iotv1.GetDeviceLocationHistoryRequest{DeviceId: "sensor-1", Limit: limit})
+		Expect(err).NotTo(HaveOccurred())
+		return resp.GetLocations()
+	}
+
+	It("should record the first location and every change, oldest first", func() {
+		report(0, 52.52, 13.40, "10.0.0.1")
+		report(time.Minute, 52.52, 13.40, "10.0.0.1")
+		report(2*time.Minute, 52.53, 13.41, "10.0.0.1")
+		report(3*time.Minute, 52.53, 13.41, "10.0.0.2")
+
+		locations := history(0)
+		Expect(locations).To(HaveLen(3))
+		Expect(locations[0].GetTimestamp()).To(Equal(start.Unix()))
+		Expect(locations[0].GetLatitude()).To(BeNumerically("~", 52.52, 1e-5))
+		Expect(locations[1].GetTimestamp()).To(Equal(start.Add(2 * time.Minute).Unix()))
+		Expect(locations[1].GetLongitude()).To(BeNumerically("~", 13.41, 1e-5))
+		Expect(locations[2].GetIpAddress()).To(Equal("10.0.0.2"))
+	})
+
+	It("should return the most recent locations up to the limit", func() {
+		for i := range 5 {
+			report(time.Duration(i)*time.Minute, float32(i), 0, "10.0.0.1")
+		}
+
+		locations := history(2)
+		Expect(locations).To(HaveLen(2))
+		Expect(locations[0].GetLatitude()).To(BeNumerically("==", 3))
+		Expect(locations[1].GetLatitude()).To(BeNumerically("==", 4))
+	})
+
+	It("should validate the request", func() {
+		_, err := service.GetDeviceLocationHistory(ctx, &iotv1.GetDeviceLocationHistoryRequest{})
+		Expect(iotv1.FieldViolations(err)).To(ConsistOf(HaveField("Field", "device_id")))
+
+		_, err = service.GetDeviceLocationHistory(ctx, &iotv1.GetDeviceLocationHistoryRequest{DeviceId: "sensor-1", Limit: maxLocationHistoryLimit + 1})
+		Expect(iotv1.FieldViolations(err)).To(ConsistOf(HaveField("Field", "limit")))
+	})
+
+	It("should report unknown devices", func() {
+		_, err := service.GetDeviceLocationHistory(ctx, &iotv1.GetDeviceLocationHistoryRequest{DeviceId: "missing"})
+		Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonDeviceNotFound))
+	})
+})
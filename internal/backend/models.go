@@ -10,11 +10,14 @@ import (
 
 // SensorReading represents a sensor reading stored in the database.
 // This model maps to the IoT sensor data received from RabbitMQ.
+// The table is range-partitioned by month on Timestamp (see partitions.go),
+// so its primary key is (id, timestamp). A device has at most one reading per
+// timestamp, which makes redelivered messages harmless.
 type SensorReading struct {
-	Timestamp    time.Time `gorm:"index:idx_device_timestamp;index:idx_timestamp;not null"`
-	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	Timestamp    time.Time `gorm:"uniqueIndex:idx_device_timestamp,priority:2;index:idx_timestamp;not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime;index:idx_created_at"`
 	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
-	DeviceID     string    `gorm:"index:idx_device_timestamp;not null"`
+	DeviceID     string    `gorm:"uniqueIndex:idx_device_timestamp,priority:1;not null"`
 	Temperature  float64   `gorm:"not null"`
 	Humidity     float64   `gorm:"not null"`
 	Pressure     float64   `gorm:"not null"`
@@ -40,6 +43,7 @@ type IoTDevice struct {
 	IPAddress      string          `gorm:"not null"`
 	Firmware       string          `gorm:"not null"`
 	ID             uint            `gorm:"primaryKey"`
+	Version        int64           `gorm:"not null;default:0"` // Bumped by every update, which only applies to the version it read
 	Latitude       float32         `gorm:"not null"`
 	Longitude      float32         `gorm:"not null"`
 }
@@ -48,3 +52,223 @@ type IoTDevice struct {
 func (IoTDevice) TableName() string {
 	return "iot_devices"
 }
+
+// AlertRule is a user-managed threshold rule evaluated against sensor readings.
+type AlertRule struct {
+	SilenceStart *time.Time
+	SilenceEnd   *time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+	Name         string    `gorm:"not null"`
+	Metric       string    `gorm:"not null"`
+	Operator     string    `gorm:"not null"`
+	DeviceID     string    `gorm:"index"`
+	Threshold    float64   `gorm:"not null"`
+	ID           uint      `gorm:"primaryKey"`
+	Enabled      bool      `gorm:"not null"`
+}
+
+// TableName specifies the table name for AlertRule model.
+func (AlertRule) TableName() string {
+	return "alert_rules"
+}
+
+// DeviceNote is a maintenance note recorded against a device.
+type DeviceNote struct {
+	CreatedAt     time.Time `gorm:"autoCreateTime;index:idx_device_notes_device_created,priority:2"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+	DeviceID      string    `gorm:"index:idx_device_notes_device_created,priority:1;not null"`
+	Author        string    `gorm:"not null"`
+	Body          string    `gorm:"type:text;not null"`
+	AttachmentURL string
+	ID            uint `gorm:"primaryKey"`
+}
+
+// TableName specifies the table name for DeviceNote model.
+func (DeviceNote) TableName() string {
+	return "device_notes"
+}
+
+// BatteryProjection is the latest battery drain estimate of a device,
+// recomputed periodically by the BatteryProjector.
+type BatteryProjection struct {
+	ProjectedEmptyAt *time.Time `gorm:"index"` // nil when the battery is not draining
+	ComputedAt       time.Time  `gorm:"not null"`
+	DeviceID         string     `gorm:"uniqueIndex;not null"`
+	DrainPerDay      float64    `gorm:"not null"` // percentage points per day; negative when charging
+	BatteryLevel     float64    `gorm:"not null"` // fitted level at the latest reading
+	SampleCount      int64      `gorm:"not null"`
+	ID               uint       `gorm:"primaryKey"`
+}
+
+// TableName specifies the table name for BatteryProjection model.
+func (BatteryProjection) TableName() string {
+	return "battery_projections"
+}
+
+// ReportSchedule is a recurring fleet summary delivered by the ReportScheduler.
+type ReportSchedule struct {
+	LastSentAt *time.Time
+	NextRunAt  time.Time `gorm:"index;not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+	Name       string    `gorm:"not null"`
+	Frequency  string    `gorm:"not null"`
+	Format     string    `gorm:"not null"`
+	WebhookURL string
+	Email      string
+	LastError  string `gorm:"type:text"`
+	ID         uint   `gorm:"primaryKey"`
+	Enabled    bool   `gorm:"not null"`
+	// ExportToBucket uploads the report to the export bucket
+	ExportToBucket bool `gorm:"not null;default:false"`
+}
+
+// TableName specifies the table name for ReportSchedule model.
+func (ReportSchedule) TableName() string {
+	return "report_schedules"
+}
+
+// ReadingRollup holds the aggregates of one device's readings within one time
+// bucket. It is stored at hourly and daily resolution by the RollupJob.
+type ReadingRollup struct {
+	BucketStart    time.Time `gorm:"primaryKey"`
+	DeviceID       string    `gorm:"primaryKey"`
+	Count          int64     `gorm:"not null"`
+	TemperatureAvg float64   `gorm:"not null"`
+	TemperatureMin float64   `gorm:"not null"`
+	TemperatureMax float64   `gorm:"not null"`
+	HumidityAvg    float64   `gorm:"not null"`
+	HumidityMin    float64   `gorm:"not null"`
+	HumidityMax    float64   `gorm:"not null"`
+	PressureAvg    float64   `gorm:"not null"`
+	PressureMin    float64   `gorm:"not null"`
+	PressureMax    float64   `gorm:"not null"`
+	BatteryAvg     float64   `gorm:"not null"`
+	BatteryMin     float64   `gorm:"not null"`
+	BatteryMax     float64   `gorm:"not null"`
+}
+
+// HourlyReadingRollup is a ReadingRollup over one UTC hour.
+type HourlyReadingRollup struct {
+	ReadingRollup
+}
+
+// TableName specifies the table name for HourlyReadingRollup model.
+func (HourlyReadingRollup) TableName() string {
+	return "sensor_reading_rollups_hourly"
+}
+
+// DailyReadingRollup is a ReadingRollup over one UTC day.
+type DailyReadingRollup struct {
+	ReadingRollup
+}
+
+// TableName specifies the table name for DailyReadingRollup model.
+func (DailyReadingRollup) TableName() string {
+	return "sensor_reading_rollups_daily"
+}
+
+// RollupState records the start of the last successful RollupJob run. The
+// next run rolls up the readings stored since then. The table holds a single
+// row.
+type RollupState struct {
+	IngestedBefore time.Time `gorm:"not null"`
+	ID             uint      `gorm:"primaryKey"`
+}
+
+// TableName specifies the table name for RollupState model.
+func (RollupState) TableName() string {
+	return "rollup_state"
+}
+
+// ProcessedMessage records a message a consumer has persisted, keyed by its
+// queue and message ID. It is written in the same transaction as the
+// message's data, so a redelivered message is skipped even after a consumer
+// restart. Rows are deleted after the dedup TTL by the DedupCleanupJob.
+type ProcessedMessage struct {
+	ProcessedAt time.Time `gorm:"index:idx_processed_at;not null"`
+	Queue       string    `gorm:"primaryKey"`
+	MessageID   string    `gorm:"primaryKey"`
+}
+
+// TableName specifies the table name for ProcessedMessage model.
+func (ProcessedMessage) TableName() string {
+	return "processed_messages"
+}
+
+// QuarantinedReading is a sensor reading rejected by the consumer's timestamp
+// policy. It is kept for inspection instead of being saved with the readings,
+// together with the reason it was rejected.
+type QuarantinedReading struct {
+	Timestamp    time.Time `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime;index:idx_quarantined_created_at"`
+	DeviceID     string    `gorm:"index:idx_quarantined_device;not null"`
+	Reason       string    `gorm:"not null"` // future or too_old
+	MessageID    string
+	Temperature  float64 `gorm:"not null"`
+	Humidity     float64 `gorm:"not null"`
+	Pressure     float64 `gorm:"not null"`
+	BatteryLevel float64 `gorm:"not null"`
+	ID           uint    `gorm:"primaryKey"`
+}
+
+// TableName specifies the table name for QuarantinedReading model.
+func (QuarantinedReading) TableName() string {
+	return "quarantined_readings"
+}
+
+// DeviceLocationHistory records where a device was. The device consumer adds
+// a row when a device is first seen and whenever its coordinates or IP address
+// change, so the rows of a device trace its movement.
+type DeviceLocationHistory struct {
+	RecordedAt time.Time `gorm:"index:idx_location_history_device_recorded,priority:2;not null"` // Timestamp of the device message
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	DeviceID   string    `gorm:"index:idx_location_history_device_recorded,priority:1;not null"`
+	IPAddress  string    `gorm:"not null"`
+	ID         uint      `gorm:"primaryKey"`
+	Latitude   float32   `gorm:"not null"`
+	Longitude  float32   `gorm:"not null"`
+}
+
+// TableName specifies the table name for DeviceLocationHistory model.
+func (DeviceLocationHistory) TableName() string {
+	return "device_location_history"
+}
+
+// DeviceEvent is a device message as it was received. Device messages and
+// CreateOrUpdateDevice calls append an event before they update iot_devices,
+// so the events of a device, in id order, replay its state; iot_devices is a
+// projection of this log that RebuildDeviceProjection recomputes.
+type DeviceEvent struct {
+	RecordedAt time.Time `gorm:"not null"` // Timestamp of the device message
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	Payload    []byte    `gorm:"not null"` // The message as a serialized iot.v1.IoTDevice
+	DeviceID   string    `gorm:"index:idx_device_events_device,priority:1;not null"`
+	Source     string    `gorm:"not null"` // queue or api
+	MessageID  string    // Message ID of a queue message, if it had one
+	ID         uint      `gorm:"primaryKey;index:idx_device_events_device,priority:2"`
+}
+
+// TableName specifies the table name for DeviceEvent model.
+func (DeviceEvent) TableName() string {
+	return "device_events"
+}
+
+// ComponentUptime counts the health checks of a backend component on one UTC
+// day. The uptime recorder updates the row of the current day after every
+// check, so the rows of a component form its uptime history.
+type ComponentUptime struct {
+	Day           time.Time `gorm:"uniqueIndex:idx_component_uptime_day,priority:2;not null"` // Start of the UTC day
+	LastCheckedAt time.Time `gorm:"not null"`
+	Component     string    `gorm:"uniqueIndex:idx_component_uptime_day,priority:1;not null"`
+	Checks        int64     `gorm:"not null"`
+	UpChecks      int64     `gorm:"not null"` // Checks that found the component healthy
+	ID            uint      `gorm:"primaryKey"`
+	LastUp        bool      `gorm:"not null"` // Result of the latest check
+}
+
+// TableName specifies the table name for ComponentUptime model.
+func (ComponentUptime) TableName() string {
+	return "component_uptime"
+}
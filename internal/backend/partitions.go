@@ -0,0 +1,386 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// readingsDefaultPartition holds readings outside all monthly partitions,
+	// e.g. from devices with a wrong clock.
+	readingsDefaultPartition = "sensor_readings_default"
+	// partitionMonthsAhead is how many months of future partitions are kept ready.
+	partitionMonthsAhead = 3
+	// defaultRetentionInterval is how often partitions are maintained when not configured.
+	defaultRetentionInterval = time.Hour
+	// partitionNameLayout formats the month of a partition name.
+	partitionNameLayout = "sensor_readings_y2006m01"
+)
+
+// createPartitionedReadingsSQL creates sensor_readings range-partitioned by
+// timestamp. Column types match what AutoMigrate derives from SensorReading;
+// the primary key includes the partition key as Postgres requires.
+const createPartitionedReadingsSQL = `
+CREATE TABLE sensor_readings (
+    "timestamp" timestamptz NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    device_id text NOT NULL,
+    temperature decimal NOT NULL,
+    humidity decimal NOT NULL,
+    pressure decimal NOT NULL,
+    battery_level decimal NOT NULL,
+    id bigserial,
+    PRIMARY KEY (id, "timestamp")
+) PARTITION BY RANGE ("timestamp")`
+
+// readingPartitionsQuery lists the partitions attached to sensor_readings.
+const readingPartitionsQuery = `
+SELECT c.relname
+FROM pg_inherits i
+JOIN pg_class c ON c.oid = i.inhrelid
+JOIN pg_class p ON p.oid = i.inhparent
+WHERE p.relname = 'sensor_readings'`
+
+// readingsRelkindQuery returns the relkind of sensor_readings: 'p' when
+// partitioned, 'r' for a plain table and no row when it does not exist.
+const readingsRelkindQuery = `
+SELECT c.relkind
+FROM pg_class c
+WHERE c.relname = 'sensor_readings' AND pg_table_is_visible(c.oid)`
+
+// migrateReadingPartitions makes sure sensor_readings is partitioned by month.
+// A missing table is created partitioned; an existing plain table is converted
+// by copying its rows into a new partitioned table. It must run before
+// AutoMigrate(&SensorReading{}), which then adds the indexes and foreign key.
+func migrateReadingPartitions(ctx context.Context, db *gorm.DB, logger *slog.Logger) error {
+	var relkind string
+	if err := db.WithContext(ctx).Raw(readingsRelkindQuery).Scan(&relkind).Error; err != nil {
+		return fmt.Errorf("failed to inspect sensor_readings: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	switch relkind {
+	case "p":
+		return ensureReadingPartitions(ctx, db, now, now.AddDate(0, partitionMonthsAhead, 0))
+	case "":
+		logger.Info("creating partitioned sensor_readings table")
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(createPartitionedReadingsSQL).Error; err != nil {
+				return fmt.Errorf("failed to create partitioned sensor_readings: %w", err)
+			}
+			return ensureReadingPartitions(ctx, tx, now, now.AddDate(0, partitionMonthsAhead, 0))
+		})
+	default:
+		logger.Info("converting sensor_readings to a partitioned table")
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return convertReadingsToPartitioned(ctx, tx, logger, now)
+		})
+	}
+}
+
+// convertReadingsToPartitioned moves the rows of a plain sensor_readings table
+// into a new partitioned one. Index names are per schema, so the old table is
+// dropped before AutoMigrate recreates the indexes on the new one. That also
+// drops the plain idx_device_timestamp that uniqueReadingIndex looks for, so
+// duplicate readings are left out here, keeping the first of each device and
+// timestamp, for the unique index to be created.
+func convertReadingsToPartitioned(ctx context.Context, tx *gorm.DB, logger *slog.Logger, now time.Time) error {
+	statements := []string{
+		`ALTER TABLE sensor_readings RENAME TO sensor_readings_unpartitioned`,
+		`ALTER INDEX IF EXISTS sensor_readings_pkey RENAME TO sensor_readings_unpartitioned_pkey`,
+		createPartitionedReadingsSQL,
+	}
+	for _, stmt := range statements {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to prepare partitioned sensor_readings: %w", err)
+		}
+	}
+
+	var oldest *time.Time
+	if err := tx.Raw(`SELECT MIN("timestamp") FROM sensor_readings_unpartitioned`).Scan(&oldest).Error; err != nil {
+		return fmt.Errorf("failed to find oldest reading: %w", err)
+	}
+
+	from := now
+	if oldest != nil && oldest.Before(now) {
+		from = *oldest
+	}
+	if err := ensureReadingPartitions(ctx, tx, from, now.AddDate(0, partitionMonthsAhead, 0)); err != nil {
+		return err
+	}
+
+	var total int64
+	if err := tx.Raw(`SELECT COUNT(*) FROM sensor_readings_unpartitioned`).Scan(&total).Error; err != nil {
+		return fmt.Errorf("failed to count readings: %w", err)
+	}
+
+	result := tx.Exec(`INSERT INTO sensor_readings ("timestamp", created_at, updated_at, device_id, temperature, humidity, pressure, battery_level, id)
+		SELECT DISTINCT ON (device_id, "timestamp") "timestamp", created_at, updated_at, device_id, temperature, humidity, pressure, battery_level, id
+		FROM sensor_readings_unpartitioned
+		ORDER BY device_id, "timestamp", id`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to copy readings into partitions: %w", result.Error)
+	}
+	if duplicates := total - result.RowsAffected; duplicates > 0 {
+		logger.Warn("deleted duplicate sensor readings", "count", duplicates)
+	}
+
+	statements = []string{
+		`SELECT setval(pg_get_serial_sequence('sensor_readings', 'id'), COALESCE((SELECT MAX(id) FROM sensor_readings), 0) + 1, false)`,
+		`DROP TABLE sensor_readings_unpartitioned`,
+	}
+	for _, stmt := range statements {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to copy readings into partitions: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureReadingPartitions creates the default partition and the monthly
+// partitions covering from through to, skipping those that already exist.
+// Readings the default partition already holds for a new month, e.g. from a
+// device with its clock ahead, are moved into the month's partition.
+func ensureReadingPartitions(ctx context.Context, db *gorm.DB, from, to time.Time) error {
+	if err := db.WithContext(ctx).Exec(
+		"CREATE TABLE IF NOT EXISTS " + readingsDefaultPartition + " PARTITION OF sensor_readings DEFAULT",
+	).Error; err != nil {
+		return fmt.Errorf("failed to create default partition: %w", err)
+	}
+
+	for month := monthStart(from); !month.After(to); month = month.AddDate(0, 1, 0) {
+		// Postgres refuses to create a partition whose range has rows in the
+		// default partition; an existing partition leaves it none
+		var stranded bool
+		if err := db.WithContext(ctx).Raw(
+			`SELECT EXISTS (SELECT 1 FROM `+readingsDefaultPartition+` WHERE "timestamp" >= ? AND "timestamp" < ?)`,
+			month, month.AddDate(0, 1, 0),
+		).Scan(&stranded).Error; err != nil {
+			return fmt.Errorf("failed to check default partition for %s: %w", readingPartitionName(month), err)
+		}
+
+		var err error
+		if stranded {
+			err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				return createPartitionFromDefault(tx, month)
+			})
+		} else {
+			err = db.WithContext(ctx).Exec(createReadingPartitionSQL(month)).Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create partition %s: %w", readingPartitionName(month), err)
+		}
+	}
+
+	return nil
+}
+
+// createReadingPartitionSQL returns the statement creating the partition of
+// the given month unless it exists.
+func createReadingPartitionSQL(month time.Time) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF sensor_readings FOR VALUES FROM ('%s') TO ('%s')",
+		readingPartitionName(month),
+		month.Format(time.RFC3339),
+		month.AddDate(0, 1, 0).Format(time.RFC3339),
+	)
+}
+
+// createPartitionFromDefault creates the partition of the given month while
+// the default partition is detached, moves the month's readings out of the
+// default partition into it and reattaches the default partition. Writes to
+// sensor_readings wait for tx to finish.
+func createPartitionFromDefault(tx *gorm.DB, month time.Time) error {
+	inMonth := fmt.Sprintf(`"timestamp" >= '%s' AND "timestamp" < '%s'`,
+		month.Format(time.RFC3339), month.AddDate(0, 1, 0).Format(time.RFC3339))
+
+	statements := []string{
+		`ALTER TABLE sensor_readings DETACH PARTITION ` + readingsDefaultPartition,
+		createReadingPartitionSQL(month),
+		`INSERT INTO sensor_readings ("timestamp", created_at, updated_at, device_id, temperature, humidity, pressure, battery_level, id)
+		 SELECT "timestamp", created_at, updated_at, device_id, temperature, humidity, pressure, battery_level, id
+		 FROM ` + readingsDefaultPartition + ` WHERE ` + inMonth,
+		`DELETE FROM ` + readingsDefaultPartition + ` WHERE ` + inMonth,
+		`ALTER TABLE sensor_readings ATTACH PARTITION ` + readingsDefaultPartition + ` DEFAULT`,
+	}
+	for _, stmt := range statements {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to move readings out of the default partition: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// readingPartitionName returns the name of the partition holding the given month.
+func readingPartitionName(month time.Time) string {
+	return month.UTC().Format(partitionNameLayout)
+}
+
+// parseReadingPartition returns the first instant of the month a partition
+// holds, or false for names that are not monthly partitions.
+func parseReadingPartition(name string) (time.Time, bool) {
+	month, err := time.Parse(partitionNameLayout, name)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return month, true
+}
+
+// monthStart returns the first instant of t's month in UTC.
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// expiredReadingPartitions returns the monthly partitions whose entire range
+// lies before cutoff.
+func expiredReadingPartitions(names []string, cutoff time.Time) []string {
+	var expired []string
+	for _, name := range names {
+		month, ok := parseReadingPartition(name)
+		if ok && !month.AddDate(0, 1, 0).After(cutoff) {
+			expired = append(expired, name)
+		}
+	}
+
+	return expired
+}
+
+// RetentionJob keeps future sensor_readings partitions ready and, when a
+// retention period is set, drops partitions that have fully expired.
+type RetentionJob struct {
+	logger    *slog.Logger
+	db        *gorm.DB
+	done      chan struct{}
+	cancel    context.CancelFunc
+	now       func() time.Time
+	retention time.Duration
+	interval  time.Duration
+}
+
+// RetentionJobConfig holds the configuration for the RetentionJob.
+type RetentionJobConfig struct {
+	Logger    *slog.Logger
+	DB        *gorm.DB
+	Retention time.Duration // How long readings are kept (optional, 0 = forever)
+	Interval  time.Duration // Time between maintenance runs (optional, default 1 hour)
+}
+
+// NewRetentionJob creates a new RetentionJob instance.
+func NewRetentionJob(cfg *RetentionJobConfig) (*RetentionJob, error) {
+	if cfg == nil {
+		return nil, errors.New("retention job config cannot be nil")
+	}
+
+	if cfg.Logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+
+	if cfg.DB == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+
+	if cfg.Retention < 0 || cfg.Interval < 0 {
+		return nil, errors.New("retention and interval cannot be negative")
+	}
+
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = defaultRetentionInterval
+	}
+
+	return &RetentionJob{
+		logger:    cfg.Logger,
+		db:        cfg.DB,
+		done:      make(chan struct{}),
+		now:       time.Now,
+		retention: cfg.Retention,
+		interval:  interval,
+	}, nil
+}
+
+// Start maintains partitions immediately and then on every interval until
+// Stop is called or ctx is canceled.
+func (j *RetentionJob) Start(ctx context.Context) {
+	ctx, j.cancel = context.WithCancel(ctx)
+
+	j.logger.Info("starting retention job", "retention", j.retention, "interval", j.interval)
+
+	go func() {
+		defer close(j.done)
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := j.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				j.logger.Error("failed to maintain reading partitions", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the job and waits for a running maintenance to finish.
+func (j *RetentionJob) Stop() {
+	if j.cancel == nil {
+		return
+	}
+
+	j.cancel()
+	<-j.done
+
+	j.logger.Info("retention job stopped")
+}
+
+// RunOnce creates upcoming partitions and drops expired ones. Expired readings
+// that landed in the default partition are deleted row by row.
+func (j *RetentionJob) RunOnce(ctx context.Context) error {
+	now := j.now().UTC()
+
+	if err := ensureReadingPartitions(ctx, j.db, now, now.AddDate(0, partitionMonthsAhead, 0)); err != nil {
+		return err
+	}
+
+	if j.retention == 0 {
+		return nil
+	}
+
+	cutoff := now.Add(-j.retention)
+
+	var names []string
+	if err := j.db.WithContext(ctx).Raw(readingPartitionsQuery).Scan(&names).Error; err != nil {
+		return fmt.Errorf("failed to list reading partitions: %w", err)
+	}
+
+	for _, name := range expiredReadingPartitions(names, cutoff) {
+		if err := j.db.WithContext(ctx).Exec("DROP TABLE IF EXISTS " + name).Error; err != nil {
+			return fmt.Errorf("failed to drop partition %s: %w", name, err)
+		}
+		j.logger.Info("dropped expired reading partition", "partition", name, "cutoff", cutoff)
+	}
+
+	result := j.db.WithContext(ctx).Exec(`DELETE FROM `+readingsDefaultPartition+` WHERE "timestamp" < ?`, cutoff)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete expired readings: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		j.logger.Info("deleted expired readings outside partitions", "count", result.RowsAffected)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package backend
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Reading partitions", func() {
+	It("should name partitions by UTC month", func() {
+		month := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+		Expect(readingPartitionName(month)).To(Equal("sensor_readings_y2026m04"))
+
+		parsed, ok := parseReadingPartition("sensor_readings_y2026m04")
+		Expect(ok).To(BeTrue())
+		Expect(parsed).To(Equal(month))
+
+		_, ok = parseReadingPartition(readingsDefaultPartition)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should truncate to the start of the UTC month", func() {
+		t := time.Date(2026, 4, 30, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60))
+		Expect(monthStart(t)).To(Equal(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	Describe("expiredReadingPartitions", func() {
+		names := []string{
+			readingsDefaultPartition,
+			"sensor_readings_y2026m01",
+			"sensor_readings_y2026m02",
+			"sensor_readings_y2026m03",
+		}
+
+		It("should only expire partitions that end before the cutoff", func() {
+			cutoff := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+			Expect(expiredReadingPartitions(names, cutoff)).To(Equal([]string{
+				"sensor_readings_y2026m01",
+				"sensor_readings_y2026m02",
+			}))
+
+			cutoff = time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC)
+			Expect(expiredReadingPartitions(names, cutoff)).To(Equal([]string{"sensor_readings_y2026m01"}))
+		})
+	})
+
+	It("should validate the retention job configuration", func() {
+		logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+		_, err := NewRetentionJob(nil)
+		Expect(err).To(HaveOccurred())
+
+		_, err = NewRetentionJob(&RetentionJobConfig{Logger: logger, DB: &gorm.DB{}, Retention: -time.Hour})
+		Expect(err).To(MatchError(ContainSubstring("negative")))
+
+		job, err := NewRetentionJob(&RetentionJobConfig{Logger: logger, DB: &gorm.DB{}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.interval).To(Equal(defaultRetentionInterval))
+	})
+})
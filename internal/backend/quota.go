@@ -0,0 +1,281 @@
+package backend
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+const (
+	// tenantMetadataKey is the gRPC metadata key that identifies the calling tenant.
+	tenantMetadataKey = "x-tenant-id"
+	// defaultTenantID is used for callers that do not send a tenant ID.
+	defaultTenantID = "default"
+	// maxTenantIDLength bounds tenant IDs since they are kept as map keys.
+	maxTenantIDLength = 64
+)
+
+// Quota names reported in QUOTA_EXCEEDED error metadata.
+const (
+	quotaRequestsPerMinute       = "requests_per_minute"
+	quotaDeviceRequestsPerMinute = "device_requests_per_minute"
+	quotaExportRowsPerDay        = "export_rows_per_day"
+)
+
+// QuotaConfig holds the per-tenant API quotas enforced by the backend.
+// A zero limit disables the corresponding quota.
+type QuotaConfig struct {
+	// RequestsPerMinute limits the RPCs a tenant may issue per minute.
+	RequestsPerMinute int64
+	// DeviceRequestsPerMinute limits the RPCs a tenant may issue per minute for a single device.
+	DeviceRequestsPerMinute int64
+	// ExportRowsPerDay limits the sensor readings a tenant may read per UTC day.
+	ExportRowsPerDay int64
+}
+
+// deviceQuotaKey identifies a per-device request window.
+type deviceQuotaKey struct {
+	tenant string
+	device string
+}
+
+// quotaLimiter tracks quota usage in memory using fixed minute and day windows.
+// Counters are per backend instance and reset on restart.
+type quotaLimiter struct {
+	config QuotaConfig
+	now    func() time.Time
+
+	mu             sync.Mutex
+	minute         time.Time
+	day            time.Time
+	requests       map[string]int64
+	deviceRequests map[deviceQuotaKey]int64
+	exportRows     map[string]int64
+}
+
+// newQuotaLimiter creates a quotaLimiter for the given limits.
+func newQuotaLimiter(cfg QuotaConfig) *quotaLimiter {
+	return &quotaLimiter{
+		config:         cfg,
+		now:            time.Now,
+		requests:       make(map[string]int64),
+		deviceRequests: make(map[deviceQuotaKey]int64),
+		exportRows:     make(map[string]int64),
+	}
+}
+
+// advance moves the limiter to the windows containing now and drops counters
+// of expired windows. Callers must hold l.mu.
+func (l *quotaLimiter) advance(now time.Time) {
+	if minute := now.Truncate(time.Minute); !minute.Equal(l.minute) {
+		l.minute = minute
+		clear(l.requests)
+		clear(l.deviceRequests)
+	}
+
+	if day := now.UTC().Truncate(24 * time.Hour); !day.Equal(l.day) {
+		l.day = day
+		clear(l.exportRows)
+	}
+}
+
+// allowRequest counts one request for the tenant and each device, or returns a
+// QUOTA_EXCEEDED error without counting anything if a limit is already reached.
+func (l *quotaLimiter) allowRequest(tenant string, deviceIDs []string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(l.now())
+
+	if l.config.RequestsPerMinute > 0 && l.requests[tenant] >= l.config.RequestsPerMinute {
+		return iotv1.QuotaExceededError(quotaRequestsPerMinute, tenant)
+	}
+
+	if l.config.DeviceRequestsPerMinute > 0 {
+		for _, id := range deviceIDs {
+			if l.deviceRequests[deviceQuotaKey{tenant: tenant, device: id}] >= l.config.DeviceRequestsPerMinute {
+				return iotv1.QuotaExceededError(quotaDeviceRequestsPerMinute, tenant)
+			}
+		}
+	}
+
+	l.requests[tenant]++
+	for _, id := range deviceIDs {
+		l.deviceRequests[deviceQuotaKey{tenant: tenant, device: id}]++
+	}
+
+	return nil
+}
+
+// allowExport returns a QUOTA_EXCEEDED error once the tenant's daily export rows are used up.
+// The check happens before the query, so the final page of a day may overshoot the limit.
+func (l *quotaLimiter) allowExport(tenant string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(l.now())
+
+	if l.config.ExportRowsPerDay > 0 && l.exportRows[tenant] >= l.config.ExportRowsPerDay {
+		return iotv1.QuotaExceededError(quotaExportRowsPerDay, tenant)
+	}
+
+	return nil
+}
+
+// addExportRows counts rows returned to the tenant against the daily export quota.
+func (l *quotaLimiter) addExportRows(tenant string, rows int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(l.now())
+
+	l.exportRows[tenant] += rows
+}
+
+// usage reports the tenant's current quota usage. The per-device fields are
+// only filled in when deviceID is set.
+func (l *quotaLimiter) usage(tenant, deviceID string) *iotv1.GetQuotaUsageResponse {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(l.now())
+
+	resp := &iotv1.GetQuotaUsageResponse{
+		TenantId:          tenant,
+		RequestsUsed:      l.requests[tenant],
+		RequestsLimit:     l.config.RequestsPerMinute,
+		ExportRowsUsed:    l.exportRows[tenant],
+		ExportRowsLimit:   l.config.ExportRowsPerDay,
+		RequestsResetAt:   l.minute.Add(time.Minute).Unix(),
+		ExportRowsResetAt: l.day.Add(24 * time.Hour).Unix(),
+	}
+
+	if deviceID != "" {
+		resp.DeviceRequestsUsed = l.deviceRequests[deviceQuotaKey{tenant: tenant, device: deviceID}]
+		resp.DeviceRequestsLimit = l.config.DeviceRequestsPerMinute
+	}
+
+	return resp
+}
+
+// unaryInterceptor enforces quotas on every RPC except GetQuotaUsage and
+// GetServerInfo, so clients can always find out why they are being throttled
+// and what the backend supports.
+func (l *quotaLimiter) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if info.FullMethod == iotv1.IoTService_GetQuotaUsage_FullMethodName || info.FullMethod == iotv1.IoTService_GetServerInfo_FullMethodName {
+			return handler(ctx, req)
+		}
+
+		tenant, err := tenantFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := l.allowRequest(tenant, requestDeviceIDs(req)); err != nil {
+			return nil, err
+		}
+
+		exports := isExportRequest(req)
+		if exports {
+			if err := l.allowExport(tenant); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := handler(ctx, req)
+		if err == nil && exports {
+			l.addExportRows(tenant, exportedRows(resp))
+		}
+
+		return resp, err
+	}
+}
+
+// tenantFromContext returns the tenant ID sent in the request metadata,
+// falling back to defaultTenantID when none is set.
+func tenantFromContext(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(tenantMetadataKey)
+	if len(values) == 0 {
+		return defaultTenantID, nil
+	}
+
+	tenant := strings.TrimSpace(values[0])
+	if tenant == "" {
+		return defaultTenantID, nil
+	}
+
+	if len(tenant) > maxTenantIDLength {
+		return "", iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, tenantMetadataKey, "is too long")
+	}
+
+	return tenant, nil
+}
+
+// requestDeviceIDs returns the devices a request targets for per-device quotas.
+func requestDeviceIDs(req any) []string {
+	switch r := req.(type) {
+	case *iotv1.GetSensorReadingSeriesBatchRequest:
+		return r.GetDeviceIds()
+	case interface{ GetDeviceId() string }:
+		if id := r.GetDeviceId(); id != "" {
+			return []string{id}
+		}
+	}
+
+	return nil
+}
+
+// isExportRequest reports whether req returns sensor readings that count
+// against the daily export quota.
+func isExportRequest(req any) bool {
+	switch req.(type) {
+	case *iotv1.GetSensorReadingByDeviceIDRequest, *iotv1.GetSensorReadingSeriesBatchRequest, *iotv1.ExportReadingsRequest:
+		return true
+	default:
+		return false
+	}
+}
+
+// exportedRows counts the sensor readings in a response.
+func exportedRows(resp any) int64 {
+	switch r := resp.(type) {
+	case *iotv1.GetSensorReadingByDeviceIDResponse:
+		return int64(len(r.GetReading()))
+	case *iotv1.GetSensorReadingSeriesBatchResponse:
+		var rows int64
+		for _, series := range r.GetSeries() {
+			rows += int64(len(series.GetReadings()))
+		}
+		return rows
+	case *iotv1.ExportReadingsResponse:
+		return r.GetReadings()
+	default:
+		return 0
+	}
+}
+
+// GetQuotaUsage returns the calling tenant's quota usage.
+func (s *IoTServiceImpl) GetQuotaUsage(ctx context.Context, req *iotv1.GetQuotaUsageRequest) (resp *iotv1.GetQuotaUsageResponse, err error) {
+	done := s.trackRequest("GetQuotaUsage")
+	defer func() { done(err) }()
+
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.quotas == nil {
+		return &iotv1.GetQuotaUsageResponse{TenantId: tenant}, nil
+	}
+
+	return s.quotas.usage(tenant, req.GetDeviceId()), nil
+}
@@ -0,0 +1,186 @@
+package backend
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Quotas", func() {
+	var (
+		limiter *quotaLimiter
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2026, 3, 10, 12, 30, 15, 0, time.UTC)
+		limiter = newQuotaLimiter(QuotaConfig{
+			RequestsPerMinute:       3,
+			DeviceRequestsPerMinute: 2,
+			ExportRowsPerDay:        5,
+		})
+		limiter.now = func() time.Time { return now }
+	})
+
+	expectQuotaExceeded := func(err error, quota string) {
+		Expect(status.Code(err)).To(Equal(codes.ResourceExhausted))
+		Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonQuotaExceeded))
+		info, ok := iotv1.ErrorInfo(err)
+		Expect(ok).To(BeTrue())
+		Expect(info.GetMetadata()).To(HaveKeyWithValue("quota", quota))
+	}
+
+	Describe("allowRequest", func() {
+		It("should enforce the per-tenant limit and reset each minute", func() {
+			for range 3 {
+				Expect(limiter.allowRequest("acme", nil)).To(Succeed())
+			}
+			expectQuotaExceeded(limiter.allowRequest("acme", nil), quotaRequestsPerMinute)
+
+			// Other tenants are counted separately
+			Expect(limiter.allowRequest("globex", nil)).To(Succeed())
+
+			now = now.Add(time.Minute)
+			Expect(limiter.allowRequest("acme", nil)).To(Succeed())
+		})
+
+		It("should enforce the per-device limit", func() {
+			Expect(limiter.allowRequest("acme", []string{"sensor-1"})).To(Succeed())
+			Expect(limiter.allowRequest("acme", []string{"sensor-1"})).To(Succeed())
+			expectQuotaExceeded(limiter.allowRequest("acme", []string{"sensor-1"}), quotaDeviceRequestsPerMinute)
+
+			// Rejected requests are not counted against the tenant
+			Expect(limiter.usage("acme", "sensor-1").GetRequestsUsed()).To(Equal(int64(2)))
+			Expect(limiter.allowRequest("acme", []string{"sensor-2"})).To(Succeed())
+		})
+
+		It("should not limit when quotas are disabled", func() {
+			limiter = newQuotaLimiter(QuotaConfig{})
+			for range 100 {
+				Expect(limiter.allowRequest("acme", []string{"sensor-1"})).To(Succeed())
+			}
+			Expect(limiter.usage("acme", "").GetRequestsUsed()).To(Equal(int64(100)))
+		})
+	})
+
+	Describe("export rows", func() {
+		It("should block exports once the daily rows are used and reset at UTC midnight", func() {
+			Expect(limiter.allowExport("acme")).To(Succeed())
+			limiter.addExportRows("acme", 5)
+			expectQuotaExceeded(limiter.allowExport("acme"), quotaExportRowsPerDay)
+
+			now = time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+			Expect(limiter.allowExport("acme")).To(Succeed())
+		})
+	})
+
+	Describe("usage", func() {
+		It("should report usage, limits and reset times", func() {
+			Expect(limiter.allowRequest("acme", []string{"sensor-1"})).To(Succeed())
+			limiter.addExportRows("acme", 4)
+
+			usage := limiter.usage("acme", "sensor-1")
+			Expect(usage.GetTenantId()).To(Equal("acme"))
+			Expect(usage.GetRequestsUsed()).To(Equal(int64(1)))
+			Expect(usage.GetRequestsLimit()).To(Equal(int64(3)))
+			Expect(usage.GetDeviceRequestsUsed()).To(Equal(int64(1)))
+			Expect(usage.GetDeviceRequestsLimit()).To(Equal(int64(2)))
+			Expect(usage.GetExportRowsUsed()).To(Equal(int64(4)))
+			Expect(usage.GetExportRowsLimit()).To(Equal(int64(5)))
+			Expect(usage.GetRequestsResetAt()).To(Equal(time.Date(2026, 3, 10, 12, 31, 0, 0, time.UTC).Unix()))
+			Expect(usage.GetExportRowsResetAt()).To(Equal(time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC).Unix()))
+
+			Expect(limiter.usage("acme", "").GetDeviceRequestsLimit()).To(BeZero())
+		})
+	})
+
+	Describe("unaryInterceptor", func() {
+		var interceptor grpc.UnaryServerInterceptor
+
+		tenantCtx := func(tenant string) context.Context {
+			return metadata.NewIncomingContext(context.Background(), metadata.Pairs(tenantMetadataKey, tenant))
+		}
+
+		readingsInfo := &grpc.UnaryServerInfo{FullMethod: iotv1.IoTService_GetSensorReadingByDeviceID_FullMethodName}
+		readingsHandler := func(_ context.Context, _ any) (any, error) {
+			return &iotv1.GetSensorReadingByDeviceIDResponse{
+				Reading: make([]*iotv1.SensorReading, 3),
+			}, nil
+		}
+
+		BeforeEach(func() {
+			interceptor = limiter.unaryInterceptor()
+		})
+
+		It("should count exported rows per tenant", func() {
+			req := &iotv1.GetSensorReadingByDeviceIDRequest{DeviceId: "sensor-1"}
+
+			_, err := interceptor(tenantCtx("acme"), req, readingsInfo, readingsHandler)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = interceptor(tenantCtx("acme"), req, readingsInfo, readingsHandler)
+			Expect(err).NotTo(HaveOccurred())
+
+			usage := limiter.usage("acme", "sensor-1")
+			Expect(usage.GetExportRowsUsed()).To(Equal(int64(6)))
+			Expect(usage.GetDeviceRequestsUsed()).To(Equal(int64(2)))
+
+			now = now.Add(time.Minute)
+			_, err = interceptor(tenantCtx("acme"), req, readingsInfo, readingsHandler)
+			expectQuotaExceeded(err, quotaExportRowsPerDay)
+		})
+
+		It("should use the default tenant without metadata", func() {
+			_, err := interceptor(context.Background(), &iotv1.GetAllDevicesRequest{},
+				&grpc.UnaryServerInfo{FullMethod: iotv1.IoTService_GetAllDevice_FullMethodName},
+				func(_ context.Context, _ any) (any, error) { return &iotv1.GetAllDevicesResponse{}, nil })
+			Expect(err).NotTo(HaveOccurred())
+			Expect(limiter.usage(defaultTenantID, "").GetRequestsUsed()).To(Equal(int64(1)))
+		})
+
+		It("should reject overlong tenant IDs", func() {
+			_, err := interceptor(tenantCtx(strings.Repeat("t", maxTenantIDLength+1)), &iotv1.GetAllDevicesRequest{},
+				&grpc.UnaryServerInfo{FullMethod: iotv1.IoTService_GetAllDevice_FullMethodName},
+				func(_ context.Context, _ any) (any, error) { return &iotv1.GetAllDevicesResponse{}, nil })
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		})
+
+		It("should never throttle GetQuotaUsage", func() {
+			for range 3 {
+				Expect(limiter.allowRequest("acme", nil)).To(Succeed())
+			}
+
+			service := &IoTServiceImpl{quotas: limiter}
+			resp, err := interceptor(tenantCtx("acme"), &iotv1.GetQuotaUsageRequest{},
+				&grpc.UnaryServerInfo{FullMethod: iotv1.IoTService_GetQuotaUsage_FullMethodName},
+				func(ctx context.Context, req any) (any, error) {
+					return service.GetQuotaUsage(ctx, req.(*iotv1.GetQuotaUsageRequest))
+				})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.(*iotv1.GetQuotaUsageResponse).GetRequestsUsed()).To(Equal(int64(3)))
+		})
+
+		It("should never throttle GetServerInfo", func() {
+			for range 3 {
+				Expect(limiter.allowRequest("acme", nil)).To(Succeed())
+			}
+
+			service := &IoTServiceImpl{}
+			resp, err := interceptor(tenantCtx("acme"), &iotv1.GetServerInfoRequest{},
+				&grpc.UnaryServerInfo{FullMethod: iotv1.IoTService_GetServerInfo_FullMethodName},
+				func(ctx context.Context, req any) (any, error) {
+					return service.GetServerInfo(ctx, req.(*iotv1.GetServerInfoRequest))
+				})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.(*iotv1.GetServerInfoResponse).GetApiVersion()).To(Equal(iotv1.APIVersion))
+		})
+	})
+})
@@ -0,0 +1,104 @@
+package backend
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+// recoveryInterceptor turns a panic in an RPC handler, or in an interceptor
+// after it in the chain, into an Internal error and logs it with its stack, so
+// one bad request cannot crash the server.
+func recoveryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				logger.Error("panic in gRPC handler",
+					"method", info.FullMethod,
+					"panic", p,
+					"stack", string(debug.Stack()),
+				)
+				resp, err = nil, iotv1.NewError(codes.Internal, iotv1.ReasonInternal, "internal error", nil)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// settlingAcknowledger records whether a delivery was acked, nacked or
+// rejected, so a panic after that does not settle it a second time.
+type settlingAcknowledger struct {
+	amqp.Acknowledger
+	settled bool
+}
+
+func (a *settlingAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.settled = true
+	return a.Acknowledger.Ack(tag, multiple)
+}
+
+func (a *settlingAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.settled = true
+	return a.Acknowledger.Nack(tag, multiple, requeue)
+}
+
+func (a *settlingAcknowledger) Reject(tag uint64, requeue bool) error {
+	a.settled = true
+	return a.Acknowledger.Reject(tag, requeue)
+}
+
+// trackSettlement returns delivery with its acknowledger wrapped to record
+// whether it was settled. Delivery handlers call it before deferring
+// recoverDelivery.
+func trackSettlement(delivery amqp.Delivery) amqp.Delivery {
+	if delivery.Acknowledger != nil {
+		delivery.Acknowledger = &settlingAcknowledger{Acknowledger: delivery.Acknowledger}
+	}
+	return delivery
+}
+
+// recoverDelivery must be deferred by delivery handlers. It recovers a panic
+// raised while processing delivery, logs it with its stack and rejects the
+// message without requeueing, since redelivering a message that crashes the
+// handler would crash it again. A delivery already settled before the panic,
+// as tracked by trackSettlement, is left as it is.
+func recoverDelivery(logger *slog.Logger, m *metrics.BackendMetrics, mqm *metrics.MQMetrics, queue string, delivery amqp.Delivery) {
+	p := recover()
+	if p == nil {
+		return
+	}
+
+	logger.Error("panic while processing message",
+		"queue", queue,
+		"panic", p,
+		"stack", string(debug.Stack()),
+	)
+
+	if m != nil {
+		m.ConsumerErrors.WithLabelValues(queue, "panic").Inc()
+	}
+
+	if ack, ok := delivery.Acknowledger.(*settlingAcknowledger); ok && ack.settled {
+		return
+	}
+
+	// Track failure
+	if m != nil {
+		m.ConsumerMessagesTotal.WithLabelValues(queue, "error").Inc()
+	}
+	if mqm != nil {
+		mqm.ConsumptionFailures.WithLabelValues(queue, "panic").Inc()
+	}
+
+	if err := delivery.Nack(false, false); err != nil {
+		logger.Error("failed to nack message", "error", err)
+	}
+}
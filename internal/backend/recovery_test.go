@@ -0,0 +1,89 @@
+package backend
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Panic recovery", func() {
+	var logger *slog.Logger
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError + 1,
+		}))
+	})
+
+	It("should turn handler panics into Internal errors", func() {
+		interceptor := recoveryInterceptor(logger)
+		info := &grpc.UnaryServerInfo{FullMethod: iotv1.IoTService_GetAllDevice_FullMethodName}
+
+		resp, err := interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			panic("nil map")
+		})
+
+		Expect(resp).To(BeNil())
+		Expect(status.Code(err)).To(Equal(codes.Internal))
+		Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonInternal))
+		Expect(err.Error()).NotTo(ContainSubstring("nil map"))
+	})
+
+	It("should pass through handler results", func() {
+		interceptor := recoveryInterceptor(logger)
+
+		resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+			return &iotv1.CountReadingsResponse{Count: 3}, nil
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(Equal(&iotv1.CountReadingsResponse{Count: 3}))
+	})
+
+	It("should reject messages that make a consumer panic without requeueing", func() {
+		c := &Consumer{
+			logger:    logger,
+			metrics:   consumerTestMetrics,
+			mqMetrics: consumerTestMQMetrics,
+			queueName: "sensor-panic-test",
+		}
+		ack := &fakeAcknowledger{}
+
+		// Without a database, saving the reading panics
+		Expect(func() {
+			c.handleDelivery(context.Background(), amqp.Delivery{Acknowledger: ack, Body: nil})
+		}).NotTo(Panic())
+
+		Expect(ack.acks).To(Equal(0))
+		Expect(ack.nacks).To(Equal(1))
+		Expect(ack.requeued).To(BeFalse())
+		Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerErrors.WithLabelValues("sensor-panic-test", "panic"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(consumerTestMQMetrics.ConsumptionFailures.WithLabelValues("sensor-panic-test", "panic"))).To(Equal(1.0))
+	})
+
+	It("should leave messages settled before the panic as they are", func() {
+		ack := &fakeAcknowledger{}
+		delivery := trackSettlement(amqp.Delivery{Acknowledger: ack})
+
+		Expect(func() {
+			defer recoverDelivery(logger, consumerTestMetrics, consumerTestMQMetrics, "sensor-panic-acked-test", delivery)
+			Expect(delivery.Ack(false)).To(Succeed())
+			panic("after ack")
+		}).NotTo(Panic())
+
+		Expect(ack.acks).To(Equal(1))
+		Expect(ack.nacks).To(BeZero())
+		Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerErrors.WithLabelValues("sensor-panic-acked-test", "panic"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(consumerTestMQMetrics.ConsumptionFailures.WithLabelValues("sensor-panic-acked-test", "panic"))).To(BeZero())
+	})
+})
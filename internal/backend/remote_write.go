@@ -0,0 +1,244 @@
+package backend
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+const (
+	// defaultRemoteWriteMaxDevices is used when RemoteWriteConfig.MaxDevices
+	// is not set.
+	defaultRemoteWriteMaxDevices = 1000
+	// remoteWriteSinkName names the remote write sink in metrics and logs.
+	remoteWriteSinkName = "remote_write"
+)
+
+// remoteWriteSeries are the series a reading is exported as: one gauge per
+// value, labeled with the device.
+var remoteWriteSeries = []struct {
+	name  string
+	value func(r *SensorReading) float64
+}{
+	{"iot_sensor_temperature_celsius", func(r *SensorReading) float64 { return r.Temperature }},
+	{"iot_sensor_humidity_percent", func(r *SensorReading) float64 { return r.Humidity }},
+	{"iot_sensor_pressure_hectopascals", func(r *SensorReading) float64 { return r.Pressure }},
+	{"iot_sensor_battery_level_percent", func(r *SensorReading) float64 { return r.BatteryLevel }},
+}
+
+// RemoteWriteConfig configures exporting saved sensor readings as Prometheus
+// remote write samples, so device values can be alerted on with an existing
+// Prometheus and Alertmanager.
+type RemoteWriteConfig struct {
+	// URL is the remote write endpoint, such as
+	// http://prometheus:9090/api/v1/write (optional, empty = disabled). User
+	// info in the URL is sent as basic auth.
+	URL string
+	// Token is sent as "Authorization: Bearer <token>" (optional)
+	Token string
+	// MaxDevices is the most devices exported. Every device adds a series per
+	// value, so readings of further devices are skipped to keep a large or
+	// misbehaving fleet from flooding Prometheus with series (optional,
+	// default 1000)
+	MaxDevices int
+	// BatchSize is the most readings sent in one request (optional, default 5000)
+	BatchSize int
+	// FlushInterval is the longest time a reading waits for its batch to fill
+	// (optional, default 5 seconds)
+	FlushInterval time.Duration
+	// MaxPending is the most readings buffered while the endpoint is
+	// unavailable; newer readings are dropped (optional, default 100000)
+	MaxPending int
+}
+
+func (c RemoteWriteConfig) validate() error {
+	if c.URL == "" {
+		return nil
+	}
+
+	if err := validateSinkURL("remote write", c.URL); err != nil {
+		return err
+	}
+
+	if c.MaxDevices < 0 {
+		return errors.New("remote write max devices cannot be negative")
+	}
+
+	return validateSinkOptions("remote write", c.BatchSize, c.FlushInterval, c.MaxPending)
+}
+
+// RemoteWriteSinkConfig holds the configuration for the remote write sink.
+type RemoteWriteSinkConfig struct {
+	Logger      *slog.Logger
+	RemoteWrite RemoteWriteConfig
+	Metrics     *metrics.BackendMetrics // Optional metrics
+	Client      *http.Client            // Optional, default http.DefaultClient
+}
+
+// NewRemoteWriteSink creates a sink writing readings to a Prometheus remote
+// write endpoint.
+func NewRemoteWriteSink(cfg *RemoteWriteSinkConfig) (*ReadingSink, error) {
+	if cfg == nil {
+		return nil, errors.New("remote write sink config cannot be nil")
+	}
+
+	if cfg.Logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+
+	if cfg.RemoteWrite.URL == "" {
+		return nil, errors.New("remote write URL cannot be empty")
+	}
+
+	if err := cfg.RemoteWrite.validate(); err != nil {
+		return nil, err
+	}
+
+	w := &remoteWriteWriter{
+		logger: cfg.Logger.With("sink", remoteWriteSinkName),
+		client: cfg.Client,
+		url:    cfg.RemoteWrite.URL,
+		header: http.Header{
+			"Content-Type":                      {"application/x-protobuf"},
+			"Content-Encoding":                  {"snappy"},
+			"X-Prometheus-Remote-Write-Version": {"0.1.0"},
+		},
+		maxDevices: cfg.RemoteWrite.MaxDevices,
+		devices:    make(map[string]struct{}),
+	}
+	if w.client == nil {
+		w.client = http.DefaultClient
+	}
+	if cfg.RemoteWrite.Token != "" {
+		w.header.Set("Authorization", "Bearer "+cfg.RemoteWrite.Token)
+	}
+	if w.maxDevices == 0 {
+		w.maxDevices = defaultRemoteWriteMaxDevices
+	}
+
+	return newReadingSink(remoteWriteSinkName, cfg.Logger, w, sinkOptions{
+		batchSize:     cfg.RemoteWrite.BatchSize,
+		flushInterval: cfg.RemoteWrite.FlushInterval,
+		maxPending:    cfg.RemoteWrite.MaxPending,
+	}, cfg.Metrics), nil
+}
+
+// remoteWriteWriter writes readings as remote write requests.
+type remoteWriteWriter struct {
+	logger     *slog.Logger
+	client     *http.Client
+	url        string
+	header     http.Header
+	maxDevices int
+
+	// devices are the devices exported so far, the first maxDevices seen.
+	// Writes do not overlap, so they are not locked.
+	devices map[string]struct{}
+	limited bool // Whether reaching maxDevices was logged
+}
+
+func (w *remoteWriteWriter) write(ctx context.Context, readings []SensorReading) (int, error) {
+	// Remote write expects the samples of a series in time order, so
+	// readings are grouped by device and sorted
+	byDevice := make(map[string][]*SensorReading)
+	var order []string
+	skipped := 0
+	for i := range readings {
+		r := &readings[i]
+		if !w.admit(r.DeviceID) {
+			skipped++
+			continue
+		}
+		if _, ok := byDevice[r.DeviceID]; !ok {
+			order = append(order, r.DeviceID)
+		}
+		byDevice[r.DeviceID] = append(byDevice[r.DeviceID], r)
+	}
+
+	if len(order) == 0 {
+		return skipped, nil
+	}
+
+	var body []byte
+	for _, deviceID := range order {
+		deviceReadings := byDevice[deviceID]
+		slices.SortStableFunc(deviceReadings, func(a, b *SensorReading) int {
+			return cmp.Compare(a.Timestamp.UnixMilli(), b.Timestamp.UnixMilli())
+		})
+		for _, series := range remoteWriteSeries {
+			body = appendRemoteWriteSeries(body, series.name, deviceID, deviceReadings, series.value)
+		}
+	}
+
+	return skipped, postBatch(ctx, w.client, w.url, w.header, s2.EncodeSnappy(nil, body))
+}
+
+// admit reports whether readings of the device are exported: devices are
+// admitted until maxDevices are, and the admitted ones stay admitted.
+func (w *remoteWriteWriter) admit(deviceID string) bool {
+	if _, ok := w.devices[deviceID]; ok {
+		return true
+	}
+	if len(w.devices) < w.maxDevices {
+		w.devices[deviceID] = struct{}{}
+		return true
+	}
+
+	if !w.limited {
+		w.limited = true
+		w.logger.Warn("remote write device limit reached, skipping readings of further devices",
+			"max_devices", w.maxDevices,
+			"device_id", deviceID,
+		)
+	}
+	return false
+}
+
+// appendRemoteWriteSeries appends a prometheus.TimeSeries message of the
+// remote write protocol to b as the timeseries field of a WriteRequest. The
+// series is named name, labeled with the device and holds value of each
+// reading as a sample.
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+func appendRemoteWriteSeries(b []byte, name, deviceID string, readings []*SensorReading, value func(*SensorReading) float64) []byte {
+	var series []byte
+
+	// Labels are sorted by name
+	for _, label := range [][2]string{{"__name__", name}, {"device_id", deviceID}} {
+		var l []byte
+		l = protowire.AppendTag(l, 1, protowire.BytesType)
+		l = protowire.AppendString(l, label[0])
+		l = protowire.AppendTag(l, 2, protowire.BytesType)
+		l = protowire.AppendString(l, label[1])
+
+		series = protowire.AppendTag(series, 1, protowire.BytesType)
+		series = protowire.AppendBytes(series, l)
+	}
+
+	for _, r := range readings {
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(value(r)))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(r.Timestamp.UnixMilli()))
+
+		series = protowire.AppendTag(series, 2, protowire.BytesType)
+		series = protowire.AppendBytes(series, sample)
+	}
+
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, series)
+}
@@ -0,0 +1,204 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// remoteSample is a decoded remote write sample with the labels of its series.
+type remoteSample struct {
+	name      string
+	deviceID  string
+	value     float64
+	timestamp int64
+}
+
+// decodeWriteRequest decodes the samples of a snappy-compressed remote write
+// request, failing the test on malformed input.
+func decodeWriteRequest(body []byte) []remoteSample {
+	data, err := s2.Decode(nil, body)
+	Expect(err).NotTo(HaveOccurred())
+
+	// fields calls fn with the number and value of each field of b
+	fields := func(b []byte, fn func(num protowire.Number, typ protowire.Type, v []byte, x uint64)) {
+		for len(b) > 0 {
+			num, typ, n := protowire.ConsumeTag(b)
+			Expect(n).To(BeNumerically(">", 0))
+			b = b[n:]
+			switch typ {
+			case protowire.BytesType:
+				v, n := protowire.ConsumeBytes(b)
+				Expect(n).To(BeNumerically(">", 0))
+				fn(num, typ, v, 0)
+				b = b[n:]
+			case protowire.Fixed64Type:
+				x, n := protowire.ConsumeFixed64(b)
+				Expect(n).To(BeNumerically(">", 0))
+				fn(num, typ, nil, x)
+				b = b[n:]
+			case protowire.VarintType:
+				x, n := protowire.ConsumeVarint(b)
+				Expect(n).To(BeNumerically(">", 0))
+				fn(num, typ, nil, x)
+				b = b[n:]
+			default:
+				Fail("unexpected wire type")
+			}
+		}
+	}
+
+	var samples []remoteSample
+	fields(data, func(_ protowire.Number, _ protowire.Type, series []byte, _ uint64) {
+		labels := map[string]string{}
+		var labelNames []string
+		var seriesSamples []remoteSample
+		fields(series, func(num protowire.Number, _ protowire.Type, v []byte, _ uint64) {
+			if num == 1 {
+				var name, value string
+				fields(v, func(num protowire.Number, _ protowire.Type, v []byte, _ uint64) {
+					if num == 1 {
+						name = string(v)
+					} else {
+						value = string(v)
+					}
+				})
+				labels[name] = value
+				labelNames = append(labelNames, name)
+				return
+			}
+			var s remoteSample
+			fields(v, func(num protowire.Number, _ protowire.Type, _ []byte, x uint64) {
+				if num == 1 {
+					s.value = math.Float64frombits(x)
+				} else {
+					s.timestamp = int64(x)
+				}
+			})
+			seriesSamples = append(seriesSamples, s)
+		})
+		Expect(labelNames).To(Equal([]string{"__name__", "device_id"}))
+
+		for _, s := range seriesSamples {
+			s.name, s.deviceID = labels["__name__"], labels["device_id"]
+			samples = append(samples, s)
+		}
+	})
+	return samples
+}
+
+var _ = Describe("Remote write sink", func() {
+	var (
+		logger  *slog.Logger
+		server  *httptest.Server
+		mu      sync.Mutex
+		headers []http.Header
+		samples []remoteSample
+	)
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+		headers, samples = nil, nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			mu.Lock()
+			defer mu.Unlock()
+			headers = append(headers, r.Header)
+			samples = append(samples, decodeWriteRequest(body)...)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	received := func() []remoteSample {
+		mu.Lock()
+		defer mu.Unlock()
+		return samples
+	}
+
+	newSink := func(remoteWrite RemoteWriteConfig) *ReadingSink {
+		remoteWrite.URL = server.URL + "/api/v1/write"
+		sink, err := NewRemoteWriteSink(&RemoteWriteSinkConfig{Logger: logger, RemoteWrite: remoteWrite, Metrics: consumerTestMetrics})
+		Expect(err).NotTo(HaveOccurred())
+		return sink
+	}
+
+	It("should export each value as a series per device", func() {
+		sink := newSink(RemoteWriteConfig{Token: "secret"})
+
+		sink.Add(
+			SensorReading{DeviceID: "sensor-1", Timestamp: time.Unix(1700000060, 0), Temperature: 22, Humidity: 41, Pressure: 1013, BatteryLevel: 79},
+			SensorReading{DeviceID: "sensor-1", Timestamp: time.Unix(1700000000, 0), Temperature: 21, Humidity: 40, Pressure: 1012, BatteryLevel: 80},
+		)
+		Expect(sink.flush(context.Background())).To(Succeed())
+
+		Expect(headers).To(HaveLen(1))
+		Expect(headers[0].Get("Content-Encoding")).To(Equal("snappy"))
+		Expect(headers[0].Get("Content-Type")).To(Equal("application/x-protobuf"))
+		Expect(headers[0].Get("X-Prometheus-Remote-Write-Version")).To(Equal("0.1.0"))
+		Expect(headers[0].Get("Authorization")).To(Equal("Bearer secret"))
+
+		// Samples of a series are in time order
+		Expect(received()).To(Equal([]remoteSample{
+			{"iot_sensor_temperature_celsius", "sensor-1", 21, 1700000000000},
+			{"iot_sensor_temperature_celsius", "sensor-1", 22, 1700000060000},
+			{"iot_sensor_humidity_percent", "sensor-1", 40, 1700000000000},
+			{"iot_sensor_humidity_percent", "sensor-1", 41, 1700000060000},
+			{"iot_sensor_pressure_hectopascals", "sensor-1", 1012, 1700000000000},
+			{"iot_sensor_pressure_hectopascals", "sensor-1", 1013, 1700000060000},
+			{"iot_sensor_battery_level_percent", "sensor-1", 80, 1700000000000},
+			{"iot_sensor_battery_level_percent", "sensor-1", 79, 1700000060000},
+		}))
+	})
+
+	It("should skip readings of devices beyond the device limit", func() {
+		sink := newSink(RemoteWriteConfig{MaxDevices: 2})
+		skipped := testutil.ToFloat64(consumerTestMetrics.SinkPointsTotal.WithLabelValues(remoteWriteSinkName, "skipped"))
+
+		sink.Add(
+			SensorReading{DeviceID: "sensor-1", Timestamp: time.Unix(1700000000, 0)},
+			SensorReading{DeviceID: "sensor-2", Timestamp: time.Unix(1700000000, 0)},
+			SensorReading{DeviceID: "sensor-3", Timestamp: time.Unix(1700000000, 0)},
+		)
+		Expect(sink.flush(context.Background())).To(Succeed())
+
+		// Admitted devices stay exported
+		sink.Add(
+			SensorReading{DeviceID: "sensor-4", Timestamp: time.Unix(1700000060, 0)},
+			SensorReading{DeviceID: "sensor-2", Timestamp: time.Unix(1700000060, 0)},
+		)
+		Expect(sink.flush(context.Background())).To(Succeed())
+
+		devices := map[string]int{}
+		for _, s := range received() {
+			devices[s.deviceID]++
+		}
+		Expect(devices).To(Equal(map[string]int{"sensor-1": 4, "sensor-2": 8}))
+		Expect(testutil.ToFloat64(consumerTestMetrics.SinkPointsTotal.WithLabelValues(remoteWriteSinkName, "skipped"))).To(Equal(skipped + 2))
+	})
+
+	It("should reject invalid configuration", func() {
+		_, err := NewRemoteWriteSink(&RemoteWriteSinkConfig{Logger: logger})
+		Expect(err).To(MatchError("remote write URL cannot be empty"))
+
+		_, err = NewRemoteWriteSink(&RemoteWriteSinkConfig{Logger: logger, RemoteWrite: RemoteWriteConfig{URL: "ftp://prometheus/api/v1/write"}})
+		Expect(err).To(MatchError("remote write URL must be an http or https URL"))
+
+		_, err = NewRemoteWriteSink(&RemoteWriteSinkConfig{Logger: logger, RemoteWrite: RemoteWriteConfig{URL: server.URL, MaxDevices: -1}})
+		Expect(err).To(MatchError("remote write max devices cannot be negative"))
+	})
+})
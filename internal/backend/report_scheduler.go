@@ -0,0 +1,453 @@
+package backend
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"procodus.dev/demo-app/pkg/objectstore"
+)
+
+// defaultReportInterval is how often due report schedules are checked when not configured.
+const defaultReportInterval = time.Minute
+
+// fleetReportQuery summarizes the readings of every active device in a period.
+// Devices without readings are included with a zero count.
+const fleetReportQuery = `
+SELECT d.device_id,
+       d.location,
+       COUNT(r.id) AS readings,
+       AVG(r.temperature) AS avg_temperature,
+       AVG(r.humidity) AS avg_humidity,
+       MIN(r.battery_level) AS min_battery_level
+FROM iot_devices d
+LEFT JOIN sensor_readings r ON r.device_id = d.device_id AND r.timestamp >= ? AND r.timestamp < ?
+WHERE d.deleted_at IS NULL
+GROUP BY d.device_id, d.location
+ORDER BY d.device_id`
+
+// fleetReportTemplate renders a fleet summary as a standalone HTML document.
+var fleetReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"optional": formatOptional,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Name}}</title></head>
+<body>
+<h1>{{.Name}}</h1>
+<p>{{.From.Format "2006-01-02 15:04"}} – {{.To.Format "2006-01-02 15:04"}} UTC</p>
+<p>{{.ActiveDevices}} of {{len .Devices}} devices reported {{.TotalReadings}} readings.</p>
+<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Device ID</th><th>Location</th><th>Readings</th><th>Avg Temperature (°C)</th><th>Avg Humidity (%)</th><th>Min Battery (%)</th></tr>
+{{range .Devices}}<tr><td>{{.DeviceID}}</td><td>{{.Location}}</td><td>{{.Readings}}</td><td>{{optional .AvgTemperature}}</td><td>{{optional .AvgHumidity}}</td><td>{{optional .MinBatteryLevel}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// fleetReport is the summary of all active devices over one report period.
+type fleetReport struct {
+	From    time.Time
+	To      time.Time
+	Name    string
+	Devices []fleetReportDevice
+}
+
+// fleetReportDevice is one device's row of a fleet report. Averages are nil
+// when the device sent no readings in the period.
+type fleetReportDevice struct {
+	AvgTemperature  *float64
+	AvgHumidity     *float64
+	MinBatteryLevel *float64
+	DeviceID        string
+	Location        string
+	Readings        int64
+}
+
+// ActiveDevices returns the number of devices that sent readings in the period.
+func (r *fleetReport) ActiveDevices() int {
+	active := 0
+	for _, d := range r.Devices {
+		if d.Readings > 0 {
+			active++
+		}
+	}
+
+	return active
+}
+
+// TotalReadings returns the number of readings received in the period.
+func (r *fleetReport) TotalReadings() int64 {
+	var total int64
+	for _, d := range r.Devices {
+		total += d.Readings
+	}
+
+	return total
+}
+
+// renderedReport is a fleet report rendered for delivery.
+type renderedReport struct {
+	Subject     string
+	ContentType string
+	Filename    string
+	Body        []byte
+}
+
+// ReportScheduler delivers the fleet reports of due report schedules.
+type ReportScheduler struct {
+	logger     *slog.Logger
+	db         *gorm.DB
+	httpClient *http.Client
+	sendMail   func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	done       chan struct{}
+	cancel     context.CancelFunc
+	now        func() time.Time
+	smtp       SMTPConfig
+	export     ExportConfig
+	interval   time.Duration
+}
+
+// SMTPConfig configures email delivery of reports. Email delivery is
+// disabled when Addr is empty.
+type SMTPConfig struct {
+	Addr     string // host:port of the SMTP server
+	From     string // Sender address
+	Username string // Optional PLAIN auth username
+	Password string // Optional PLAIN auth password
+}
+
+// ReportSchedulerConfig holds the configuration for the ReportScheduler.
+type ReportSchedulerConfig struct {
+	Logger   *slog.Logger
+	DB       *gorm.DB
+	SMTP     SMTPConfig
+	Export   ExportConfig  // Bucket that reports are uploaded to (optional)
+	Interval time.Duration // Time between checks for due schedules (optional, default 1 minute)
+}
+
+// NewReportScheduler creates a new ReportScheduler instance.
+func NewReportScheduler(cfg *ReportSchedulerConfig) (*ReportScheduler, error) {
+	if cfg == nil {
+		return nil, errors.New("report scheduler config cannot be nil")
+	}
+
+	if cfg.Logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+
+	if cfg.DB == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+
+	if cfg.Interval < 0 {
+		return nil, errors.New("report interval cannot be negative")
+	}
+
+	if cfg.SMTP.Addr != "" && cfg.SMTP.From == "" {
+		return nil, errors.New("SMTP sender address cannot be empty")
+	}
+
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = defaultReportInterval
+	}
+
+	return &ReportScheduler{
+		logger:     cfg.Logger,
+		db:         cfg.DB,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		sendMail:   smtp.SendMail,
+		done:       make(chan struct{}),
+		now:        time.Now,
+		smtp:       cfg.SMTP,
+		export:     cfg.Export,
+		interval:   interval,
+	}, nil
+}
+
+// Start delivers due reports immediately and then on every interval until Stop
+// is called or ctx is canceled.
+func (s *ReportScheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.logger.Info("starting report scheduler", "interval", s.interval, "email", s.smtp.Addr != "", "bucket", s.export.Store != nil)
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error("failed to run report schedules", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler and waits for running deliveries to finish.
+func (s *ReportScheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+	<-s.done
+
+	s.logger.Info("report scheduler stopped")
+}
+
+// RunOnce delivers the reports of all enabled schedules that are due. Delivery
+// failures are recorded on the schedule; missed periods are not caught up.
+func (s *ReportScheduler) RunOnce(ctx context.Context) error {
+	now := s.now().UTC()
+
+	var due []ReportSchedule
+	if err := s.db.WithContext(ctx).
+		Where("enabled AND next_run_at <= ?", now).
+		Order("next_run_at").
+		Find(&due).Error; err != nil {
+		return fmt.Errorf("failed to fetch due report schedules: %w", err)
+	}
+
+	for i := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err := s.run(ctx, &due[i], now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// run delivers one schedule's report for the period ending at its due time
+// and advances the schedule to the next period.
+func (s *ReportScheduler) run(ctx context.Context, schedule *ReportSchedule, now time.Time) error {
+	to := schedule.NextRunAt.UTC()
+	from := to.Add(-reportPeriod(schedule.Frequency))
+
+	deliveryErr := s.deliverReport(ctx, schedule, from, to)
+	if deliveryErr != nil {
+		s.logger.Warn("failed to deliver report", "id", schedule.ID, "name", schedule.Name, "error", deliveryErr)
+		schedule.LastError = deliveryErr.Error()
+	} else {
+		s.logger.Info("delivered report", "id", schedule.ID, "name", schedule.Name, "from", from, "to", to)
+		schedule.LastError = ""
+		schedule.LastSentAt = &now
+	}
+	schedule.NextRunAt = nextReportRun(schedule.Frequency, now)
+
+	if err := s.db.WithContext(ctx).Model(schedule).
+		Select("last_error", "last_sent_at", "next_run_at").
+		Updates(schedule).Error; err != nil {
+		return fmt.Errorf("failed to update report schedule %d: %w", schedule.ID, err)
+	}
+
+	return nil
+}
+
+// deliverReport builds, renders and sends one report to every destination of the schedule.
+func (s *ReportScheduler) deliverReport(ctx context.Context, schedule *ReportSchedule, from, to time.Time) error {
+	report := &fleetReport{Name: schedule.Name, From: from, To: to}
+	if err := s.db.WithContext(ctx).Raw(fleetReportQuery, from, to).Scan(&report.Devices).Error; err != nil {
+		return fmt.Errorf("failed to summarize readings: %w", err)
+	}
+
+	rendered, err := renderFleetReport(report, schedule.Format)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if schedule.WebhookURL != "" {
+		if err := s.postWebhook(ctx, schedule.WebhookURL, rendered); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	if schedule.Email != "" {
+		if err := s.sendEmail(schedule.Email, rendered); err != nil {
+			errs = append(errs, fmt.Errorf("email: %w", err))
+		}
+	}
+	if schedule.ExportToBucket {
+		if err := s.uploadReport(ctx, schedule, rendered); err != nil {
+			errs = append(errs, fmt.Errorf("bucket: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// postWebhook POSTs the rendered report to url.
+func (s *ReportScheduler) postWebhook(ctx context.Context, url string, report *renderedReport) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(report.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", report.ContentType)
+	req.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", report.Filename))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// uploadReport writes the gzip-compressed report to the export bucket, under
+// the ID of its schedule.
+func (s *ReportScheduler) uploadReport(ctx context.Context, schedule *ReportSchedule, report *renderedReport) error {
+	if s.export.Store == nil {
+		return errors.New("bucket export is not configured")
+	}
+
+	key := fmt.Sprintf("%sreports/%d/%s.gz", s.export.Prefix, schedule.ID, report.Filename)
+	w, err := s.export.Store.NewWriter(ctx, key, objectstore.WriteOptions{
+		ContentType:     report.ContentType,
+		ContentEncoding: "gzip",
+	})
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(report.Body); err != nil {
+		_ = w.Abort()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = w.Abort()
+		return err
+	}
+
+	return w.Close()
+}
+
+// sendEmail mails the rendered report to the recipient.
+func (s *ReportScheduler) sendEmail(to string, report *renderedReport) error {
+	if s.smtp.Addr == "" {
+		return errors.New("email delivery is not configured")
+	}
+
+	var auth smtp.Auth
+	if s.smtp.Username != "" {
+		host, _, _ := strings.Cut(s.smtp.Addr, ":")
+		auth = smtp.PlainAuth("", s.smtp.Username, s.smtp.Password, host)
+	}
+
+	return s.sendMail(s.smtp.Addr, auth, s.smtp.From, []string{to}, buildReportEmail(s.smtp.From, to, report))
+}
+
+// buildReportEmail formats a single-part email carrying the report. CSV
+// reports are marked as attachments so mail clients offer them for download.
+func buildReportEmail(from, to string, report *renderedReport) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", report.Subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: %s\r\n", report.ContentType)
+	if !strings.HasPrefix(report.ContentType, "text/html") {
+		fmt.Fprintf(&msg, "Content-Disposition: attachment; filename=%q\r\n", report.Filename)
+	}
+	msg.WriteString("\r\n")
+	msg.Write(bytes.ReplaceAll(report.Body, []byte("\n"), []byte("\r\n")))
+
+	return msg.Bytes()
+}
+
+// renderFleetReport renders a fleet report in the given format.
+func renderFleetReport(report *fleetReport, format string) (*renderedReport, error) {
+	rendered := &renderedReport{
+		Subject: fmt.Sprintf("%s: %s – %s", report.Name, report.From.Format("2006-01-02"), report.To.Format("2006-01-02")),
+	}
+	basename := "fleet-report-" + report.To.Format("2006-01-02")
+
+	var buf bytes.Buffer
+	switch format {
+	case "html":
+		if err := fleetReportTemplate.Execute(&buf, report); err != nil {
+			return nil, fmt.Errorf("failed to render HTML report: %w", err)
+		}
+		rendered.ContentType = "text/html; charset=utf-8"
+		rendered.Filename = basename + ".html"
+	case "csv":
+		w := csv.NewWriter(&buf)
+		_ = w.Write([]string{"device_id", "location", "readings", "avg_temperature", "avg_humidity", "min_battery_level"})
+		for _, d := range report.Devices {
+			_ = w.Write([]string{
+				d.DeviceID,
+				d.Location,
+				strconv.FormatInt(d.Readings, 10),
+				formatOptional(d.AvgTemperature),
+				formatOptional(d.AvgHumidity),
+				formatOptional(d.MinBatteryLevel),
+			})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, fmt.Errorf("failed to render CSV report: %w", err)
+		}
+		rendered.ContentType = "text/csv; charset=utf-8"
+		rendered.Filename = basename + ".csv"
+	case "ndjson":
+		enc := json.NewEncoder(&buf)
+		for _, d := range report.Devices {
+			if err := enc.Encode(map[string]any{
+				"device_id":         d.DeviceID,
+				"location":          d.Location,
+				"readings":          d.Readings,
+				"avg_temperature":   d.AvgTemperature,
+				"avg_humidity":      d.AvgHumidity,
+				"min_battery_level": d.MinBatteryLevel,
+			}); err != nil {
+				return nil, fmt.Errorf("failed to render NDJSON report: %w", err)
+			}
+		}
+		rendered.ContentType = "application/x-ndjson"
+		rendered.Filename = basename + ".ndjson"
+	default:
+		return nil, fmt.Errorf("unsupported report format: %s", format)
+	}
+	rendered.Body = buf.Bytes()
+
+	return rendered, nil
+}
+
+// formatOptional formats an optional value with two decimals, or empty when unset.
+func formatOptional(v *float64) string {
+	if v == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(*v, 'f', 2, 64)
+}
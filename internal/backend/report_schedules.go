@@ -0,0 +1,223 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// reportFrequencies are the supported report schedules.
+var reportFrequencies = []string{"daily", "weekly"}
+
+// reportFormats are the supported report renderings.
+var reportFormats = []string{"html", "csv", "ndjson"}
+
+// ListReportSchedules returns all report schedules ordered by name.
+func (s *IoTServiceImpl) ListReportSchedules(ctx context.Context, _ *iotv1.ListReportSchedulesRequest) (resp *iotv1.ListReportSchedulesResponse, err error) {
+	done := s.trackRequest("ListReportSchedules")
+	defer func() { done(err) }()
+
+	var schedules []ReportSchedule
+	if err := s.db.WithContext(ctx).Order("name").Order("id").Find(&schedules).Error; err != nil {
+		s.logger.Error("failed to fetch report schedules", "error", err)
+		return nil, databaseError("failed to fetch report schedules")
+	}
+
+	protoSchedules := make([]*iotv1.ReportSchedule, len(schedules))
+	for i := range schedules {
+		protoSchedules[i] = reportScheduleToProto(&schedules[i])
+	}
+
+	return &iotv1.ListReportSchedulesResponse{
+		Schedules: protoSchedules,
+	}, nil
+}
+
+// CreateReportSchedule validates and stores a new report schedule. The first
+// report is delivered at the next period boundary.
+func (s *IoTServiceImpl) CreateReportSchedule(ctx context.Context, req *iotv1.CreateReportScheduleRequest) (resp *iotv1.CreateReportScheduleResponse, err error) {
+	done := s.trackRequest("CreateReportSchedule")
+	defer func() { done(err) }()
+
+	if err := validateReportSchedule(req.GetSchedule()); err != nil {
+		return nil, err
+	}
+
+	schedule := &ReportSchedule{}
+	applyReportSchedule(schedule, req.GetSchedule())
+	schedule.NextRunAt = nextReportRun(schedule.Frequency, time.Now())
+
+	if err := s.db.WithContext(ctx).Create(schedule).Error; err != nil {
+		s.logger.Error("failed to create report schedule", "error", err)
+		return nil, databaseError("failed to create report schedule")
+	}
+
+	s.logger.Info("created report schedule", "id", schedule.ID, "name", schedule.Name)
+
+	return &iotv1.CreateReportScheduleResponse{
+		Schedule: reportScheduleToProto(schedule),
+	}, nil
+}
+
+// UpdateReportSchedule replaces the editable fields of an existing report schedule.
+func (s *IoTServiceImpl) UpdateReportSchedule(ctx context.Context, req *iotv1.UpdateReportScheduleRequest) (resp *iotv1.UpdateReportScheduleResponse, err error) {
+	done := s.trackRequest("UpdateReportSchedule")
+	defer func() { done(err) }()
+
+	if err := validateReportSchedule(req.GetSchedule()); err != nil {
+		return nil, err
+	}
+
+	id := req.GetSchedule().GetId()
+
+	var schedule ReportSchedule
+	if err := s.db.WithContext(ctx).First(&schedule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, iotv1.ReportScheduleNotFoundError(id)
+		}
+		s.logger.Error("failed to fetch report schedule", "id", id, "error", err)
+		return nil, databaseError("failed to fetch report schedule")
+	}
+
+	frequency := schedule.Frequency
+	applyReportSchedule(&schedule, req.GetSchedule())
+	if schedule.Frequency != frequency {
+		schedule.NextRunAt = nextReportRun(schedule.Frequency, time.Now())
+	}
+
+	if err := s.db.WithContext(ctx).Save(&schedule).Error; err != nil {
+		s.logger.Error("failed to update report schedule", "id", schedule.ID, "error", err)
+		return nil, databaseError("failed to update report schedule")
+	}
+
+	s.logger.Info("updated report schedule", "id", schedule.ID, "name", schedule.Name)
+
+	return &iotv1.UpdateReportScheduleResponse{
+		Schedule: reportScheduleToProto(&schedule),
+	}, nil
+}
+
+// DeleteReportSchedule removes a report schedule.
+func (s *IoTServiceImpl) DeleteReportSchedule(ctx context.Context, req *iotv1.DeleteReportScheduleRequest) (resp *iotv1.DeleteReportScheduleResponse, err error) {
+	done := s.trackRequest("DeleteReportSchedule")
+	defer func() { done(err) }()
+
+	result := s.db.WithContext(ctx).Delete(&ReportSchedule{}, req.GetId())
+	if result.Error != nil {
+		s.logger.Error("failed to delete report schedule", "id", req.GetId(), "error", result.Error)
+		return nil, databaseError("failed to delete report schedule")
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, iotv1.ReportScheduleNotFoundError(req.GetId())
+	}
+
+	s.logger.Info("deleted report schedule", "id", req.GetId())
+
+	return &iotv1.DeleteReportScheduleResponse{}, nil
+}
+
+// validateReportSchedule checks the user-editable fields of a report schedule.
+func validateReportSchedule(schedule *iotv1.ReportSchedule) error {
+	if schedule == nil {
+		return iotv1.InvalidArgumentError(iotv1.ReasonInvalidArgument, "schedule", "cannot be empty")
+	}
+
+	var violations []iotv1.FieldViolation
+
+	if strings.TrimSpace(schedule.GetName()) == "" {
+		violations = append(violations, iotv1.FieldViolation{Field: "name", Description: "cannot be empty"})
+	}
+
+	if !slices.Contains(reportFrequencies, schedule.GetFrequency()) {
+		violations = append(violations, iotv1.FieldViolation{Field: "frequency", Description: "must be daily or weekly"})
+	}
+
+	if !slices.Contains(reportFormats, schedule.GetFormat()) {
+		violations = append(violations, iotv1.FieldViolation{Field: "format", Description: "must be html, csv or ndjson"})
+	}
+
+	webhookURL := strings.TrimSpace(schedule.GetWebhookUrl())
+	email := strings.TrimSpace(schedule.GetEmail())
+
+	if webhookURL == "" && email == "" && !schedule.GetExportToBucket() {
+		violations = append(violations, iotv1.FieldViolation{Field: "webhook_url", Description: "a webhook URL, email address or bucket export is required"})
+	}
+
+	if webhookURL != "" && !isWebURL(webhookURL) {
+		violations = append(violations, iotv1.FieldViolation{Field: "webhook_url", Description: "must be an http or https URL"})
+	}
+
+	if email != "" {
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			violations = append(violations, iotv1.FieldViolation{Field: "email", Description: "must be a plain email address"})
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return iotv1.NewError(codes.InvalidArgument, iotv1.ReasonInvalidArgument, "invalid report schedule", nil, violations...)
+}
+
+// applyReportSchedule copies the editable fields of a proto schedule onto the model.
+func applyReportSchedule(schedule *ReportSchedule, in *iotv1.ReportSchedule) {
+	schedule.Name = strings.TrimSpace(in.GetName())
+	schedule.Frequency = in.GetFrequency()
+	schedule.Format = in.GetFormat()
+	schedule.WebhookURL = strings.TrimSpace(in.GetWebhookUrl())
+	schedule.Email = strings.TrimSpace(in.GetEmail())
+	schedule.ExportToBucket = in.GetExportToBucket()
+	schedule.Enabled = in.GetEnabled()
+}
+
+// reportScheduleToProto converts a report schedule model to its proto message.
+func reportScheduleToProto(schedule *ReportSchedule) *iotv1.ReportSchedule {
+	return &iotv1.ReportSchedule{
+		Id:             uint64(schedule.ID),
+		Name:           schedule.Name,
+		Frequency:      schedule.Frequency,
+		Format:         schedule.Format,
+		WebhookUrl:     schedule.WebhookURL,
+		Email:          schedule.Email,
+		ExportToBucket: schedule.ExportToBucket,
+		Enabled:        schedule.Enabled,
+		NextRunAt:      schedule.NextRunAt.Unix(),
+		LastSentAt:     timeToUnix(schedule.LastSentAt),
+		LastError:      schedule.LastError,
+		CreatedAt:      schedule.CreatedAt.Unix(),
+		UpdatedAt:      schedule.UpdatedAt.Unix(),
+	}
+}
+
+// nextReportRun returns the first period boundary after t: the next UTC
+// midnight for daily reports and the next Monday 00:00 UTC for weekly ones.
+func nextReportRun(frequency string, t time.Time) time.Time {
+	t = t.UTC()
+	next := time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+
+	if frequency == "weekly" {
+		// Days until the following Monday; Sunday is 0 in time.Weekday
+		next = next.AddDate(0, 0, (8-int(next.Weekday()))%7)
+	}
+
+	return next
+}
+
+// reportPeriod returns the time span covered by one report of the given frequency.
+func reportPeriod(frequency string) time.Duration {
+	if frequency == "weekly" {
+		return 7 * 24 * time.Hour
+	}
+
+	return 24 * time.Hour
+}
@@ -0,0 +1,249 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Report schedules", func() {
+	validSchedule := func() *iotv1.ReportSchedule {
+		return &iotv1.ReportSchedule{
+			Name:       "Daily fleet summary",
+			Frequency:  "daily",
+			Format:     "csv",
+			WebhookUrl: "https://hooks.example.com/reports",
+			Enabled:    true,
+		}
+	}
+
+	Describe("validateReportSchedule", func() {
+		It("should accept a valid schedule", func() {
+			Expect(validateReportSchedule(validSchedule())).To(Succeed())
+		})
+
+		It("should accept an email-only schedule", func() {
+			schedule := validSchedule()
+			schedule.WebhookUrl = ""
+			schedule.Email = "ops@example.com"
+
+			Expect(validateReportSchedule(schedule)).To(Succeed())
+		})
+
+		It("should report every invalid field", func() {
+			schedule := &iotv1.ReportSchedule{Frequency: "hourly", Format: "pdf", Email: "Ops <ops@example.com>"}
+
+			err := validateReportSchedule(schedule)
+
+			Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonInvalidArgument))
+			Expect(iotv1.FieldViolations(err)).To(ConsistOf(
+				HaveField("Field", "name"),
+				HaveField("Field", "frequency"),
+				HaveField("Field", "format"),
+				HaveField("Field", "email"),
+			))
+		})
+
+		It("should accept a bucket-only schedule", func() {
+			schedule := validSchedule()
+			schedule.WebhookUrl = ""
+			schedule.ExportToBucket = true
+
+			Expect(validateReportSchedule(schedule)).To(Succeed())
+		})
+
+		It("should require a destination", func() {
+			schedule := validSchedule()
+			schedule.WebhookUrl = ""
+
+			Expect(iotv1.FieldViolations(validateReportSchedule(schedule))).To(ConsistOf(HaveField("Field", "webhook_url")))
+		})
+	})
+
+	Describe("nextReportRun", func() {
+		// Wednesday
+		t := time.Date(2026, 4, 15, 13, 30, 0, 0, time.UTC)
+
+		It("should schedule daily reports at the next UTC midnight", func() {
+			Expect(nextReportRun("daily", t)).To(Equal(time.Date(2026, 4, 16, 0, 0, 0, 0, time.UTC)))
+			Expect(nextReportRun("daily", time.Date(2026, 4, 16, 0, 0, 0, 0, time.UTC))).
+				To(Equal(time.Date(2026, 4, 17, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("should schedule weekly reports on the next Monday", func() {
+			Expect(nextReportRun("weekly", t)).To(Equal(time.Date(2026, 4, 20, 0, 0, 0, 0, time.UTC)))
+			Expect(nextReportRun("weekly", time.Date(2026, 4, 19, 23, 0, 0, 0, time.UTC))).
+				To(Equal(time.Date(2026, 4, 20, 0, 0, 0, 0, time.UTC)))
+			Expect(nextReportRun("weekly", time.Date(2026, 4, 20, 0, 0, 0, 0, time.UTC))).
+				To(Equal(time.Date(2026, 4, 27, 0, 0, 0, 0, time.UTC)))
+		})
+	})
+
+	Describe("renderFleetReport", func() {
+		avg := 21.456
+		report := &fleetReport{
+			Name: "Fleet",
+			From: time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2026, 4, 16, 0, 0, 0, 0, time.UTC),
+			Devices: []fleetReportDevice{
+				{DeviceID: "sensor-1", Location: "Hall <A>", Readings: 3, AvgTemperature: &avg},
+				{DeviceID: "sensor-2", Location: "Roof"},
+			},
+		}
+
+		It("should summarize the fleet", func() {
+			Expect(report.ActiveDevices()).To(Equal(1))
+			Expect(report.TotalReadings()).To(Equal(int64(3)))
+		})
+
+		It("should render CSV", func() {
+			rendered, err := renderFleetReport(report, "csv")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rendered.ContentType).To(Equal("text/csv; charset=utf-8"))
+			Expect(rendered.Filename).To(Equal("fleet-report-2026-04-16.csv"))
+			Expect(string(rendered.Body)).To(Equal(
+				"device_id,location,readings,avg_temperature,avg_humidity,min_battery_level\n" +
+					"sensor-1,Hall <A>,3,21.46,,\n" +
+					"sensor-2,Roof,0,,,\n"))
+		})
+
+		It("should render escaped HTML", func() {
+			rendered, err := renderFleetReport(report, "html")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rendered.ContentType).To(Equal("text/html; charset=utf-8"))
+			Expect(string(rendered.Body)).To(ContainSubstring("Hall &lt;A&gt;"))
+			Expect(string(rendered.Body)).To(ContainSubstring("1 of 2 devices reported 3 readings."))
+		})
+
+		It("should render NDJSON", func() {
+			rendered, err := renderFleetReport(report, "ndjson")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rendered.ContentType).To(Equal("application/x-ndjson"))
+			Expect(rendered.Filename).To(Equal("fleet-report-2026-04-16.ndjson"))
+			Expect(strings.Split(strings.TrimSpace(string(rendered.Body)), "\n")).To(HaveLen(2))
+			Expect(string(rendered.Body)).To(HavePrefix(`{"avg_humidity":null,"avg_temperature":21.456,"device_id":"sensor-1",`))
+		})
+
+		It("should reject unknown formats", func() {
+			_, err := renderFleetReport(report, "pdf")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ReportScheduler", func() {
+		var (
+			scheduler *ReportScheduler
+			rendered  *renderedReport
+		)
+
+		BeforeEach(func() {
+			var err error
+			scheduler, err = NewReportScheduler(&ReportSchedulerConfig{
+				Logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+				DB:     &gorm.DB{},
+				SMTP:   SMTPConfig{Addr: "mail.example.com:587", From: "reports@example.com", Username: "reports"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			rendered = &renderedReport{
+				Subject:     "Fleet: 2026-04-15 – 2026-04-16",
+				ContentType: "text/csv; charset=utf-8",
+				Filename:    "fleet-report-2026-04-16.csv",
+				Body:        []byte("device_id\nsensor-1\n"),
+			}
+		})
+
+		It("should reject invalid configuration", func() {
+			_, err := NewReportScheduler(nil)
+			Expect(err).To(HaveOccurred())
+
+			_, err = NewReportScheduler(&ReportSchedulerConfig{
+				Logger: slog.Default(),
+				DB:     &gorm.DB{},
+				SMTP:   SMTPConfig{Addr: "mail.example.com:25"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("sender")))
+		})
+
+		It("should POST reports to webhooks", func() {
+			var (
+				contentType string
+				body        []byte
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+
+			Expect(scheduler.postWebhook(context.Background(), srv.URL, rendered)).To(Succeed())
+			Expect(contentType).To(Equal("text/csv; charset=utf-8"))
+			Expect(body).To(Equal(rendered.Body))
+		})
+
+		It("should fail on webhook error responses", func() {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			}))
+			defer srv.Close()
+
+			Expect(scheduler.postWebhook(context.Background(), srv.URL, rendered)).To(MatchError(ContainSubstring("502")))
+		})
+
+		It("should email reports as attachments", func() {
+			var (
+				addr string
+				auth smtp.Auth
+				to   []string
+				msg  string
+			)
+			scheduler.sendMail = func(a string, au smtp.Auth, _ string, t []string, m []byte) error {
+				addr, auth, to, msg = a, au, t, string(m)
+				return nil
+			}
+
+			Expect(scheduler.sendEmail("ops@example.com", rendered)).To(Succeed())
+			Expect(addr).To(Equal("mail.example.com:587"))
+			Expect(auth).NotTo(BeNil())
+			Expect(to).To(Equal([]string{"ops@example.com"}))
+			Expect(msg).To(ContainSubstring("Subject: Fleet: 2026-04-15 – 2026-04-16\r\n"))
+			Expect(msg).To(ContainSubstring("Content-Disposition: attachment; filename=\"fleet-report-2026-04-16.csv\"\r\n"))
+			Expect(msg).To(HaveSuffix("\r\n\r\ndevice_id\r\nsensor-1\r\n"))
+		})
+
+		It("should fail email delivery when SMTP is not configured", func() {
+			scheduler.smtp = SMTPConfig{}
+
+			Expect(scheduler.sendEmail("ops@example.com", rendered)).To(MatchError(ContainSubstring("not configured")))
+		})
+
+		It("should upload gzip-compressed reports to the bucket", func() {
+			store := newMemStore()
+			scheduler.export = ExportConfig{Store: store, Prefix: "exports/"}
+
+			Expect(scheduler.uploadReport(context.Background(), &ReportSchedule{ID: 7}, rendered)).To(Succeed())
+
+			key := "exports/reports/7/fleet-report-2026-04-16.csv.gz"
+			Expect(store.objects).To(HaveKey(key))
+			Expect(store.options[key].ContentType).To(Equal("text/csv; charset=utf-8"))
+			Expect(gunzip(store.objects[key])).To(Equal(string(rendered.Body)))
+		})
+
+		It("should fail bucket delivery when exports are not configured", func() {
+			Expect(scheduler.uploadReport(context.Background(), &ReportSchedule{ID: 7}, rendered)).To(MatchError(ContainSubstring("not configured")))
+		})
+	})
+})
@@ -0,0 +1,387 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+const (
+	// defaultRollupInterval is how often rollups are refreshed when not configured.
+	defaultRollupInterval = 5 * time.Minute
+	// rollupIngestOverlap is how long before the start of the previous run
+	// each run looks for ingested readings, so readings whose transaction
+	// committed after that run started, or stamped by a replica with a
+	// slightly late clock, are still counted.
+	rollupIngestOverlap = 5 * time.Minute
+)
+
+// Series resolutions reported in GetSensorReadingSeriesBatchResponse.
+const (
+	resolutionRaw  = "raw"
+	resolutionHour = "hour"
+	resolutionDay  = "day"
+)
+
+const (
+	// rawSeriesWindow is the longest series window served from raw readings.
+	rawSeriesWindow = 48 * time.Hour
+	// hourlySeriesWindow is the longest series window served from hourly rollups;
+	// longer windows use daily rollups.
+	hourlySeriesWindow = 30 * 24 * time.Hour
+)
+
+// hourlyRollupQuery recomputes, from the raw readings, the hourly rollups of
+// all buckets holding a reading that matches the condition formatted into it.
+const hourlyRollupQuery = `
+WITH touched AS (
+    SELECT DISTINCT device_id, date_trunc('hour', "timestamp" AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS bucket
+    FROM sensor_readings
+    WHERE %s
+)
+INSERT INTO sensor_reading_rollups_hourly (bucket_start, device_id, count,
+    temperature_avg, temperature_min, temperature_max,
+    humidity_avg, humidity_min, humidity_max,
+    pressure_avg, pressure_min, pressure_max,
+    battery_avg, battery_min, battery_max)
+SELECT t.bucket, r.device_id, COUNT(*),
+       AVG(r.temperature), MIN(r.temperature), MAX(r.temperature),
+       AVG(r.humidity), MIN(r.humidity), MAX(r.humidity),
+       AVG(r.pressure), MIN(r.pressure), MAX(r.pressure),
+       AVG(r.battery_level), MIN(r.battery_level), MAX(r.battery_level)
+FROM touched t
+JOIN sensor_readings r ON r.device_id = t.device_id
+    AND r.timestamp >= t.bucket AND r.timestamp < t.bucket + interval '1 hour'
+GROUP BY t.bucket, r.device_id
+ON CONFLICT (bucket_start, device_id) DO UPDATE SET` + rollupUpdateColumns
+
+// dailyRollupQuery recomputes, from the hourly rollups, the daily rollups of
+// all days holding a reading that matches the condition formatted into it.
+// Averages are weighted by reading count.
+const dailyRollupQuery = `
+WITH touched AS (
+    SELECT DISTINCT device_id, date_trunc('day', "timestamp" AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS bucket
+    FROM sensor_readings
+    WHERE %s
+)
+INSERT INTO sensor_reading_rollups_daily (bucket_start, device_id, count,
+    temperature_avg, temperature_min, temperature_max,
+    humidity_avg, humidity_min, humidity_max,
+    pressure_avg, pressure_min, pressure_max,
+    battery_avg, battery_min, battery_max)
+SELECT t.bucket, h.device_id, SUM(h.count),
+       SUM(h.temperature_avg * h.count) / SUM(h.count), MIN(h.temperature_min), MAX(h.temperature_max),
+       SUM(h.humidity_avg * h.count) / SUM(h.count), MIN(h.humidity_min), MAX(h.humidity_max),
+       SUM(h.pressure_avg * h.count) / SUM(h.count), MIN(h.pressure_min), MAX(h.pressure_max),
+       SUM(h.battery_avg * h.count) / SUM(h.count), MIN(h.battery_min), MAX(h.battery_max)
+FROM touched t
+JOIN sensor_reading_rollups_hourly h ON h.device_id = t.device_id
+    AND h.bucket_start >= t.bucket AND h.bucket_start < t.bucket + interval '24 hours'
+GROUP BY t.bucket, h.device_id
+ON CONFLICT (bucket_start, device_id) DO UPDATE SET` + rollupUpdateColumns
+
+const rollupUpdateColumns = `
+    count = EXCLUDED.count,
+    temperature_avg = EXCLUDED.temperature_avg, temperature_min = EXCLUDED.temperature_min, temperature_max = EXCLUDED.temperature_max,
+    humidity_avg = EXCLUDED.humidity_avg, humidity_min = EXCLUDED.humidity_min, humidity_max = EXCLUDED.humidity_max,
+    pressure_avg = EXCLUDED.pressure_avg, pressure_min = EXCLUDED.pressure_min, pressure_max = EXCLUDED.pressure_max,
+    battery_avg = EXCLUDED.battery_avg, battery_min = EXCLUDED.battery_min, battery_max = EXCLUDED.battery_max`
+
+// RollupJob periodically refreshes the hourly and daily reading rollups so
+// long series windows need not scan raw readings.
+type RollupJob struct {
+	logger   *slog.Logger
+	db       *gorm.DB
+	done     chan struct{}
+	cancel   context.CancelFunc
+	interval time.Duration
+}
+
+// RollupJobConfig holds the configuration for the RollupJob.
+type RollupJobConfig struct {
+	Logger   *slog.Logger
+	DB       *gorm.DB
+	Interval time.Duration // Time between refreshes (optional, default 5 minutes)
+}
+
+// NewRollupJob creates a new RollupJob instance.
+func NewRollupJob(cfg *RollupJobConfig) (*RollupJob, error) {
+	if cfg == nil {
+		return nil, errors.New("rollup job config cannot be nil")
+	}
+
+	if cfg.Logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+
+	if cfg.DB == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+
+	if cfg.Interval < 0 {
+		return nil, errors.New("rollup interval cannot be negative")
+	}
+
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = defaultRollupInterval
+	}
+
+	return &RollupJob{
+		logger:   cfg.Logger,
+		db:       cfg.DB,
+		done:     make(chan struct{}),
+		interval: interval,
+	}, nil
+}
+
+// Start refreshes the rollups immediately and then on every interval until
+// Stop is called or ctx is canceled.
+func (j *RollupJob) Start(ctx context.Context) {
+	ctx, j.cancel = context.WithCancel(ctx)
+
+	j.logger.Info("starting rollup job", "interval", j.interval)
+
+	go func() {
+		defer close(j.done)
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := j.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				j.logger.Error("failed to refresh reading rollups", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the job and waits for a running refresh to finish.
+func (j *RollupJob) Stop() {
+	if j.cancel == nil {
+		return
+	}
+
+	j.cancel()
+	<-j.done
+
+	j.logger.Info("rollup job stopped")
+}
+
+// RunOnce refreshes the rollups of all buckets that received readings since
+// the previous successful run, whatever their timestamps. The first run
+// backfills all existing readings.
+func (j *RollupJob) RunOnce(ctx context.Context) error {
+	started := time.Now().UTC()
+
+	var state RollupState
+	if err := j.db.WithContext(ctx).Limit(1).Find(&state).Error; err != nil {
+		return fmt.Errorf("failed to read rollup watermark: %w", err)
+	}
+
+	// Readings are selected by the time they were stored, not by their own
+	// timestamps, so late and future-dated readings are rolled up alike
+	filter, args := "TRUE", []any(nil)
+	since := rollupIngestedSince(state.IngestedBefore)
+	if !since.IsZero() {
+		filter, args = "created_at >= ?", []any{since}
+	}
+
+	hourly := j.db.WithContext(ctx).Exec(fmt.Sprintf(hourlyRollupQuery, filter), args...)
+	if hourly.Error != nil {
+		return fmt.Errorf("failed to refresh hourly rollups: %w", hourly.Error)
+	}
+
+	daily := j.db.WithContext(ctx).Exec(fmt.Sprintf(dailyRollupQuery, filter), args...)
+	if daily.Error != nil {
+		return fmt.Errorf("failed to refresh daily rollups: %w", daily.Error)
+	}
+
+	state = RollupState{ID: 1, IngestedBefore: started}
+	if err := j.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&state).Error; err != nil {
+		return fmt.Errorf("failed to record rollup watermark: %w", err)
+	}
+
+	j.logger.Info("refreshed reading rollups", "ingested_since", since, "hourly", hourly.RowsAffected, "daily", daily.RowsAffected)
+	return nil
+}
+
+// rollupIngestedSince returns the storage time from which readings are rolled
+// up given the start of the previous successful run, or the zero time to
+// backfill everything.
+func rollupIngestedSince(watermark time.Time) time.Time {
+	if watermark.IsZero() {
+		return time.Time{}
+	}
+
+	return watermark.UTC().Add(-rollupIngestOverlap)
+}
+
+// seriesResolution picks the storage a series window is read from.
+func seriesResolution(window time.Duration) string {
+	switch {
+	case window <= rawSeriesWindow:
+		return resolutionRaw
+	case window <= hourlySeriesWindow:
+		return resolutionHour
+	default:
+		return resolutionDay
+	}
+}
+
+// fetchRollupSeries loads the rollups of the given devices whose buckets overlap
+// [start, end), ordered by device and bucket.
+func (s *IoTServiceImpl) fetchRollupSeries(ctx context.Context, resolution string, deviceIDs []string, start, end time.Time) ([]ReadingRollup, error) {
+	table := HourlyReadingRollup{}.TableName()
+	bucket := time.Hour
+	if resolution == resolutionDay {
+		table = DailyReadingRollup{}.TableName()
+		bucket = 24 * time.Hour
+	}
+
+	var rollups []ReadingRollup
+	err := s.db.WithContext(ctx).Table(table).
+		Where("device_id IN ?", deviceIDs).
+		Where("bucket_start >= ? AND bucket_start < ?", start.Truncate(bucket), end).
+		Order("device_id").
+		Order("bucket_start ASC").
+		Find(&rollups).Error
+
+	return rollups, err
+}
+
+// readingRollup treats a single reading as a one-reading rollup.
+func readingRollup(r *SensorReading) ReadingRollup {
+	return ReadingRollup{
+		BucketStart:    r.Timestamp,
+		DeviceID:       r.DeviceID,
+		Count:          1,
+		TemperatureAvg: r.Temperature,
+		TemperatureMin: r.Temperature,
+		TemperatureMax: r.Temperature,
+		HumidityAvg:    r.Humidity,
+		HumidityMin:    r.Humidity,
+		HumidityMax:    r.Humidity,
+		PressureAvg:    r.Pressure,
+		PressureMin:    r.Pressure,
+		PressureMax:    r.Pressure,
+		BatteryAvg:     r.BatteryLevel,
+		BatteryMin:     r.BatteryLevel,
+		BatteryMax:     r.BatteryLevel,
+	}
+}
+
+// seriesPoint is a series point before conversion to a proto message. It holds
+// only the values sent to clients, so long series stay small until they are
+// downsampled.
+type seriesPoint struct {
+	timestamp   int64
+	temperature float64
+	humidity    float64
+	pressure    float64
+	battery     float64
+}
+
+// rollupPoint converts a rollup to a series point carrying the bucket averages.
+func rollupPoint(r *ReadingRollup) seriesPoint {
+	return seriesPoint{
+		timestamp:   r.BucketStart.Unix(),
+		temperature: r.TemperatureAvg,
+		humidity:    r.HumidityAvg,
+		pressure:    r.PressureAvg,
+		battery:     r.BatteryAvg,
+	}
+}
+
+// seriesProto converts the points of a device's series to proto messages.
+func seriesProto(deviceID string, points []seriesPoint) []*iotv1.SensorReading {
+	if len(points) == 0 {
+		return nil
+	}
+
+	// One allocation backs all messages of the series
+	messages := make([]iotv1.SensorReading, len(points))
+	readings := make([]*iotv1.SensorReading, len(points))
+	for i, p := range points {
+		messages[i].DeviceId = deviceID
+		messages[i].Timestamp = p.timestamp
+		messages[i].Temperature = p.temperature
+		messages[i].Humidity = p.humidity
+		messages[i].Pressure = p.pressure
+		messages[i].BatteryLevel = p.battery
+		readings[i] = &messages[i]
+	}
+
+	return readings
+}
+
+// metricAccumulator combines min, max and count-weighted average of one metric.
+type metricAccumulator struct {
+	min         float64
+	max         float64
+	weightedSum float64
+}
+
+func (a *metricAccumulator) add(first bool, n int64, avg, lo, hi float64) {
+	if first {
+		a.min, a.max = lo, hi
+	} else {
+		a.min = min(a.min, lo)
+		a.max = max(a.max, hi)
+	}
+	a.weightedSum += avg * float64(n)
+}
+
+func (a *metricAccumulator) proto(count int64) *iotv1.MetricStats {
+	return &iotv1.MetricStats{Min: a.min, Max: a.max, Avg: a.weightedSum / float64(count)}
+}
+
+// seriesStats accumulates rollups into the statistics of a series window.
+type seriesStats struct {
+	temperature metricAccumulator
+	humidity    metricAccumulator
+	pressure    metricAccumulator
+	battery     metricAccumulator
+	count       int64
+}
+
+func (s *seriesStats) add(r *ReadingRollup) {
+	if r.Count == 0 {
+		return
+	}
+
+	first := s.count == 0
+	s.temperature.add(first, r.Count, r.TemperatureAvg, r.TemperatureMin, r.TemperatureMax)
+	s.humidity.add(first, r.Count, r.HumidityAvg, r.HumidityMin, r.HumidityMax)
+	s.pressure.add(first, r.Count, r.PressureAvg, r.PressureMin, r.PressureMax)
+	s.battery.add(first, r.Count, r.BatteryAvg, r.BatteryMin, r.BatteryMax)
+	s.count += r.Count
+}
+
+// proto returns the accumulated statistics, or nil when nothing was added.
+func (s *seriesStats) proto() *iotv1.SeriesStats {
+	if s.count == 0 {
+		return nil
+	}
+
+	return &iotv1.SeriesStats{
+		Count:        s.count,
+		Temperature:  s.temperature.proto(s.count),
+		Humidity:     s.humidity.proto(s.count),
+		Pressure:     s.pressure.proto(s.count),
+		BatteryLevel: s.battery.proto(s.count),
+	}
+}
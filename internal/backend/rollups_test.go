@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reading rollups", func() {
+	Describe("seriesResolution", func() {
+		It("should read short windows from raw readings", func() {
+			Expect(seriesResolution(time.Hour)).To(Equal(resolutionRaw))
+			Expect(seriesResolution(rawSeriesWindow)).To(Equal(resolutionRaw))
+		})
+
+		It("should read longer windows from rollups", func() {
+			Expect(seriesResolution(7 * 24 * time.Hour)).To(Equal(resolutionHour))
+			Expect(seriesResolution(hourlySeriesWindow)).To(Equal(resolutionHour))
+			Expect(seriesResolution(90 * 24 * time.Hour)).To(Equal(resolutionDay))
+		})
+	})
+
+	Describe("rollupIngestedSince", func() {
+		It("should backfill everything before the first run", func() {
+			Expect(rollupIngestedSince(time.Time{})).To(BeZero())
+		})
+
+		It("should look back the overlap before the previous run", func() {
+			previous := time.Date(2026, 4, 15, 10, 3, 0, 0, time.UTC)
+			Expect(rollupIngestedSince(previous)).
+				To(Equal(time.Date(2026, 4, 15, 9, 58, 0, 0, time.UTC)))
+		})
+	})
+
+	Describe("seriesStats", func() {
+		It("should be unset without readings", func() {
+			stats := &seriesStats{}
+			stats.add(&ReadingRollup{})
+			Expect(stats.proto()).To(BeNil())
+		})
+
+		It("should combine rollups weighted by count", func() {
+			stats := &seriesStats{}
+			stats.add(&ReadingRollup{Count: 3, TemperatureAvg: 20, TemperatureMin: 18, TemperatureMax: 22, BatteryAvg: 80, BatteryMin: 79, BatteryMax: 81})
+			stats.add(&ReadingRollup{Count: 1, TemperatureAvg: 24, TemperatureMin: 24, TemperatureMax: 24, BatteryAvg: 60, BatteryMin: 60, BatteryMax: 60})
+
+			out := stats.proto()
+			Expect(out.GetCount()).To(Equal(int64(4)))
+			Expect(out.GetTemperature().GetMin()).To(Equal(18.0))
+			Expect(out.GetTemperature().GetMax()).To(Equal(24.0))
+			Expect(out.GetTemperature().GetAvg()).To(Equal(21.0))
+			Expect(out.GetBatteryLevel().GetMin()).To(Equal(60.0))
+			Expect(out.GetBatteryLevel().GetAvg()).To(Equal(75.0))
+		})
+
+		It("should treat raw readings as single-reading rollups", func() {
+			ts := time.Date(2026, 4, 15, 10, 5, 0, 0, time.UTC)
+			rollup := readingRollup(&SensorReading{DeviceID: "sensor-1", Timestamp: ts, Temperature: 21.5, Pressure: 1013})
+
+			Expect(rollup.Count).To(Equal(int64(1)))
+			Expect(rollup.PressureMin).To(Equal(1013.0))
+			Expect(rollup.PressureMax).To(Equal(1013.0))
+
+			point := seriesProto(rollup.DeviceID, []seriesPoint{rollupPoint(&rollup)})[0]
+			Expect(point.GetDeviceId()).To(Equal("sensor-1"))
+			Expect(point.GetTimestamp()).To(Equal(ts.Unix()))
+			Expect(point.GetTemperature()).To(Equal(21.5))
+		})
+	})
+
+	It("should reject invalid job configuration", func() {
+		_, err := NewRollupJob(nil)
+		Expect(err).To(HaveOccurred())
+
+		_, err = NewRollupJob(&RollupJobConfig{})
+		Expect(err).To(MatchError(ContainSubstring("logger")))
+	})
+})
@@ -0,0 +1,134 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SchemaVersion is the database schema version this release expects. Bump it
+// whenever runMigrations changes the schema.
+const SchemaVersion = 4
+
+// minCompatibleSchemaVersion is the oldest SchemaVersion of a release that can
+// still run against the schema migrated by this release. Raise it to
+// SchemaVersion when a migration breaks older releases, for example by
+// dropping or renaming a column they use.
+const minCompatibleSchemaVersion = 1
+
+// ErrSchemaMismatch is returned by NewDB when the schema of the database does
+// not match SchemaVersion and the check is not skipped.
+var ErrSchemaMismatch = errors.New("database schema mismatch")
+
+// SchemaState records the schema version a database was migrated to. The
+// table holds a single row.
+type SchemaState struct {
+	MigratedAt time.Time `gorm:"not null"`
+	ID         uint      `gorm:"primaryKey"`
+	Version    int       `gorm:"not null"`
+	// MinCompatibleVersion is the oldest release schema version that can
+	// use this schema
+	MinCompatibleVersion int `gorm:"not null"`
+}
+
+// TableName specifies the table name for SchemaState model.
+func (SchemaState) TableName() string {
+	return "schema_version"
+}
+
+// checkSchema compares the schema of db to SchemaVersion. A database without
+// any tables is migrated. A matching schema, or a newer one that is still
+// compatible with this release, is used as is; the latter with a warning so
+// old replicas keep running during a rollout. Older and unversioned schemas
+// are rejected so they are migrated on purpose instead of by whichever
+// replica starts first.
+func checkSchema(db *gorm.DB, logger *slog.Logger) error {
+	migrator := db.Migrator()
+
+	if !migrator.HasTable(&SchemaState{}) {
+		if migrator.HasTable(&IoTDevice{}) {
+			return fmt.Errorf("%w: the database was created before schema versioning; run \"demo-app db migrate\" to migrate it to version %d",
+				ErrSchemaMismatch, SchemaVersion)
+		}
+
+		logger.Info("database is empty, creating schema", "version", SchemaVersion)
+		return migrateSchema(db, logger, false)
+	}
+
+	var state SchemaState
+	if err := db.First(&state).Error; err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	switch {
+	case state.Version == SchemaVersion:
+		logger.Info("database schema is up to date", "version", state.Version)
+		return nil
+	case state.Version < SchemaVersion:
+		return fmt.Errorf("%w: the database has schema version %d, this release needs %d; run \"demo-app db migrate\"",
+			ErrSchemaMismatch, state.Version, SchemaVersion)
+	case state.MinCompatibleVersion <= SchemaVersion:
+		logger.Warn("database schema is newer than this release, running in compatibility mode",
+			"version", state.Version,
+			"expected", SchemaVersion,
+		)
+		return nil
+	default:
+		return fmt.Errorf("%w: the database has schema version %d, which needs releases with schema version %d or later; this release has %d",
+			ErrSchemaMismatch, state.Version, state.MinCompatibleVersion, SchemaVersion)
+	}
+}
+
+// migrateSchema runs the migrations and records SchemaVersion. A schema
+// migrated by a newer release is not downgraded: it is an error, unless
+// keepNewer is set, in which case the migrations run and the recorded version
+// is kept.
+func migrateSchema(db *gorm.DB, logger *slog.Logger, keepNewer bool) error {
+	migrator := db.Migrator()
+
+	if migrator.HasTable(&SchemaState{}) {
+		var state SchemaState
+		if err := db.First(&state).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to read schema version: %w", err)
+		}
+		if state.Version > SchemaVersion {
+			if !keepNewer {
+				return fmt.Errorf("%w: the database has schema version %d, newer than %d of this release",
+					ErrSchemaMismatch, state.Version, SchemaVersion)
+			}
+			logger.Warn("database schema is newer than this release, keeping its version", "version", state.Version, "expected", SchemaVersion)
+			return runMigrations(db, logger)
+		}
+	}
+
+	if err := runMigrations(db, logger); err != nil {
+		return err
+	}
+
+	if err := migrator.AutoMigrate(&SchemaState{}); err != nil {
+		return fmt.Errorf("auto-migration failed for SchemaState: %w", err)
+	}
+
+	state := SchemaState{
+		ID:                   1,
+		Version:              SchemaVersion,
+		MinCompatibleVersion: minCompatibleSchemaVersion,
+		MigratedAt:           time.Now().UTC(),
+	}
+	if err := db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&state).Error; err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+
+	logger.Info("database schema migrated", "version", SchemaVersion)
+	return nil
+}
+
+// Migrate migrates the schema of the configured database to SchemaVersion.
+func (s *Server) Migrate(ctx context.Context) error {
+	return MigrateDB(ctx, s.dbConfig())
+}
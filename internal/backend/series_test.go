@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Sensor reading series batch", func() {
+	Describe("validateSeriesBatchRequest", func() {
+		end := time.Now()
+		start := end.Add(-time.Hour)
+
+		It("should accept a valid request", func() {
+			Expect(validateSeriesBatchRequest([]string{"a", "b"}, start, end)).To(Succeed())
+		})
+
+		It("should reject empty and oversized device lists", func() {
+			err := validateSeriesBatchRequest(nil, start, end)
+			Expect(iotv1.FieldViolations(err)).To(ContainElement(HaveField("Field", "device_ids")))
+
+			tooMany := make([]string, maxSeriesBatchDevices+1)
+			for i := range tooMany {
+				tooMany[i] = "device"
+			}
+			err = validateSeriesBatchRequest(tooMany, start, end)
+			Expect(iotv1.FieldViolations(err)).To(ContainElement(HaveField("Field", "device_ids")))
+		})
+
+		It("should reject inverted and overlong windows", func() {
+			err := validateSeriesBatchRequest([]string{"a"}, end, start)
+			Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonInvalidArgument))
+			Expect(iotv1.FieldViolations(err)).To(ContainElement(HaveField("Field", "start_time")))
+
+			err = validateSeriesBatchRequest([]string{"a"}, end.Add(-maxSeriesWindow-time.Hour), end)
+			Expect(iotv1.FieldViolations(err)).To(ContainElement(HaveField("Field", "start_time")))
+		})
+	})
+
+	Describe("downsampleReadings", func() {
+		readings := func(n int) []*iotv1.SensorReading {
+			out := make([]*iotv1.SensorReading, n)
+			for i := range out {
+				out[i] = &iotv1.SensorReading{Timestamp: int64(i)}
+			}
+			return out
+		}
+
+		It("should keep short series unchanged", func() {
+			in := readings(5)
+			Expect(downsampleReadings(in, 10)).To(Equal(in))
+		})
+
+		It("should cap long series and keep the endpoints", func() {
+			out := downsampleReadings(readings(1000), 100)
+
+			Expect(out).To(HaveLen(100))
+			Expect(out[0].GetTimestamp()).To(Equal(int64(0)))
+			Expect(out[99].GetTimestamp()).To(Equal(int64(999)))
+		})
+	})
+})
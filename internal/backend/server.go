@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,40 +12,113 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 	"gorm.io/gorm"
 
-	"procodus.dev/demo-app/pkg/iot"
+	"procodus.dev/demo-app/pkg/faults"
+	"procodus.dev/demo-app/pkg/listener"
 	"procodus.dev/demo-app/pkg/metrics"
+	"procodus.dev/demo-app/pkg/mq"
+	"procodus.dev/demo-app/pkg/mq/inmem"
 )
 
 // Server represents the backend server that manages database, message queue, and gRPC.
 type Server struct {
-	logger         *slog.Logger
-	db             *gorm.DB
-	consumer       *Consumer
-	deviceConsumer *DeviceConsumer
-	grpcServer     *grpc.Server
-	config         *ServerConfig
+	logger           *slog.Logger
+	db               *gorm.DB
+	consumer         *Consumer
+	deviceConsumer   *DeviceConsumer
+	mqClient         mq.ClientInterface
+	sinks            []*ReadingSink
+	batteryProjector *BatteryProjector
+	reportScheduler  *ReportScheduler
+	rollupJob        *RollupJob
+	retentionJob     *RetentionJob
+	dedupCleanupJob  *DedupCleanupJob
+	uptimeRecorder   *UptimeRecorder
+	leaderElector    *LeaderElector
+	grpcServer       *grpc.Server
+	health           *health.Server
+	interceptors     []string // Names of the gRPC interceptors in chain order
+	lifecycle        *lifecycle
+	config           *ServerConfig
 }
 
 // ServerConfig holds the configuration for the Server.
 type ServerConfig struct {
 	Logger *slog.Logger
 
+	// HandleSignals makes Run shut down on SIGINT and SIGTERM. The backend
+	// command sets it; servers embedded with others in one process leave it
+	// unset and stop when the context passed to Run is canceled.
+	HandleSignals bool
+
+	// StartupConfig is the resolved configuration logged with the startup
+	// event, as key-value pairs with secrets redacted (optional)
+	StartupConfig []any
+
 	// Database configuration
+	DBDriver   string // DriverPostgres (default) or DriverSQLite, which only needs DBName
 	DBHost     string
 	DBUser     string
 	DBPassword string
 	DBName     string
 	DBSSLMode  string
-
-	// RabbitMQ configuration
+	// DBConnectAttempts is how often connecting to the database is tried at
+	// startup (optional, default 1); DBConnectBackoff is the wait after the
+	// first failure, doubling up to 30 seconds (optional, default 1 second)
+	DBConnectAttempts int
+	DBConnectBackoff  time.Duration
+	// WaitForDB retries connecting to the database until it succeeds or Run
+	// is stopped, for orchestrators that start the backend before the database
+	WaitForDB bool
+	// SkipSchemaCheck migrates the schema at startup instead of failing when
+	// it does not match SchemaVersion
+	SkipSchemaCheck bool
+	// DBPrepareStmt and DBSkipDefaultTransaction tune GORM, see DBConfig.
+	// DBStatementTimeout cancels longer PostgreSQL statements and
+	// DBSlowQueryThreshold logs slower ones (optional, 0 = disabled).
+	DBPrepareStmt            bool
+	DBSkipDefaultTransaction bool
+	DBStatementTimeout       time.Duration
+	DBSlowQueryThreshold     time.Duration
+
+	// RabbitMQ configuration. MQBroker replaces RabbitMQ with an in-process
+	// broker, e.g. when all services run in one process (optional).
 	RabbitMQURL     string
+	MQBroker        *inmem.Broker
 	QueueName       string
 	DeviceQueueName string
+	DurableQueues   bool // Durable queues and persistent messages (optional, must match the generator)
+	PriorityQueues  bool // Queues with message priorities (optional, must match the generator)
+
+	// InstanceID identifies this replica when several backends share the
+	// queues (optional). It names the RabbitMQ consumers.
+	InstanceID string
 
 	// gRPC configuration
-	GRPCPort int
+	GRPCPort         int
+	EnableReflection bool            // Register the gRPC reflection service for grpcurl (optional)
+	Keepalive        KeepaliveConfig // Connection keepalive and aging (optional, zero = gRPC defaults)
+	MaxRecvMsgSize   int             // Largest request in bytes (optional, 0 = gRPC default of 4 MiB)
+	MaxSendMsgSize   int             // Largest response in bytes (optional, 0 = unlimited)
+	// CompressMinSize gzip-compresses responses of at least this many bytes
+	// for clients that accept gzip (optional, 0 = no compression)
+	CompressMinSize int
+	// RequestTimeout bounds each RPC; clients may set shorter deadlines but
+	// not longer ones (optional, default 10 seconds)
+	RequestTimeout time.Duration
+	// ExportTimeout replaces RequestTimeout for ExportReadings, which writes
+	// whole time windows to the bucket (optional, default 5 minutes)
+	ExportTimeout time.Duration
+	// Interceptors customizes the interceptor chain of the gRPC server, e.g.
+	// to authenticate calls or log them, see InterceptorChain (optional,
+	// nil = built-in chain)
+	Interceptors InterceptorChain
+	// GRPCBindAddress is the host or IP, such as 127.0.0.1 or [::1], the gRPC
+	// server listens on, or unix:///path to serve on a Unix socket instead of
+	// GRPCPort (optional, empty = all IPv4 and IPv6 interfaces)
+	GRPCBindAddress string
 
 	// Database port
 	DBPort int
@@ -54,7 +126,59 @@ type ServerConfig struct {
 	// Metrics configuration (optional)
 	Metrics     *metrics.BackendMetrics
 	MQMetrics   *metrics.MQMetrics
-	MetricsPort int // HTTP port for Prometheus metrics endpoint (optional, 0 = disabled)
+	MetricsPort int  // HTTP port for Prometheus metrics endpoint (optional, 0 = disabled)
+	EnablePprof bool // Serve /debug/pprof on the metrics HTTP server (optional)
+	// MetricsBindAddress is the host or IP the metrics server listens on, or
+	// unix:///path to serve on a Unix socket instead of MetricsPort
+	// (optional, empty = all IPv4 and IPv6 interfaces)
+	MetricsBindAddress string
+
+	// Quotas limits API usage per tenant (optional, zero limits = unlimited)
+	Quotas QuotaConfig
+
+	// Faults injects database delays and gRPC failures for resilience tests
+	// (optional, nil = none)
+	Faults *faults.Injector
+
+	// Battery projection configuration (optional, zero = defaults)
+	BatteryWindow   time.Duration // History used to fit the battery drain rate
+	BatteryInterval time.Duration // Time between projection runs
+
+	// Retention is how long sensor readings are kept (optional, 0 = forever).
+	// Readings are dropped a whole monthly partition at a time.
+	Retention time.Duration
+
+	// RollupInterval is the time between reading rollup refreshes (optional, zero = default)
+	RollupInterval time.Duration
+
+	// Timestamps configures how consumers treat readings from devices with
+	// skewed clocks (optional, default accepts all)
+	Timestamps TimestampConfig
+
+	// DedupTTL is how long the IDs of processed messages are kept to skip
+	// redeliveries (optional, 0 = 7 days)
+	DedupTTL time.Duration
+
+	// SMTP configures email delivery of scheduled reports (optional)
+	SMTP SMTPConfig
+
+	// Export configures the bucket that ExportReadings and scheduled reports
+	// write to (optional, nil store = exports disabled)
+	Export ExportConfig
+
+	// Influx forwards saved sensor readings to InfluxDB or another line
+	// protocol endpoint (optional, empty URL = disabled)
+	Influx InfluxConfig
+
+	// RemoteWrite exports saved sensor readings as Prometheus remote write
+	// samples (optional, empty URL = disabled)
+	RemoteWrite RemoteWriteConfig
+}
+
+// ServesMetrics reports whether the configuration enables the metrics HTTP
+// server, on a port or a Unix socket.
+func (c *ServerConfig) ServesMetrics() bool {
+	return c.MetricsPort > 0 || listener.IsUnixSocket(c.MetricsBindAddress)
 }
 
 // NewServer creates a new Server instance.
@@ -67,7 +191,7 @@ func NewServer(cfg *ServerConfig) (*Server, error) {
 		return nil, errors.New("logger cannot be nil")
 	}
 
-	if cfg.RabbitMQURL == "" {
+	if cfg.RabbitMQURL == "" && cfg.MQBroker == nil {
 		return nil, errors.New("rabbitmq URL cannot be empty")
 	}
 
@@ -79,32 +203,147 @@ func NewServer(cfg *ServerConfig) (*Server, error) {
 		return nil, errors.New("device queue name cannot be empty")
 	}
 
-	if cfg.DBHost == "" {
-		return nil, errors.New("database host cannot be empty")
+	if cfg.DeviceQueueName == cfg.QueueName {
+		return nil, errors.New("device queue name must differ from queue name")
 	}
 
-	if cfg.DBPort <= 0 {
-		return nil, errors.New("database port must be positive")
-	}
+	switch cfg.DBDriver {
+	case "", DriverPostgres:
+		if cfg.DBHost == "" {
+			return nil, errors.New("database host cannot be empty")
+		}
+
+		if cfg.DBPort <= 0 {
+			return nil, errors.New("database port must be positive")
+		}
 
-	if cfg.DBUser == "" {
-		return nil, errors.New("database user cannot be empty")
+		if cfg.DBUser == "" {
+			return nil, errors.New("database user cannot be empty")
+		}
+	case DriverSQLite:
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.DBDriver)
 	}
 
 	if cfg.DBName == "" {
 		return nil, errors.New("database name cannot be empty")
 	}
 
-	if cfg.GRPCPort <= 0 {
-		return nil, errors.New("gRPC port must be positive")
+	if cfg.DBConnectAttempts < 0 || cfg.DBConnectBackoff < 0 {
+		return nil, errors.New("database connect attempts and backoff cannot be negative")
+	}
+
+	if cfg.DBStatementTimeout < 0 || cfg.DBSlowQueryThreshold < 0 {
+		return nil, errors.New("statement timeout and slow query threshold cannot be negative")
+	}
+
+	if err := listener.Validate("gRPC", cfg.GRPCBindAddress, cfg.GRPCPort); err != nil {
+		return nil, err
+	}
+
+	if cfg.MetricsBindAddress != "" {
+		if err := listener.Validate("metrics", cfg.MetricsBindAddress, cfg.MetricsPort); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.Keepalive.validate(); err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxRecvMsgSize < 0 || cfg.MaxSendMsgSize < 0 || cfg.CompressMinSize < 0 {
+		return nil, errors.New("gRPC message sizes cannot be negative")
+	}
+
+	if cfg.RequestTimeout < 0 || cfg.ExportTimeout < 0 {
+		return nil, errors.New("request and export timeouts cannot be negative")
+	}
+
+	if cfg.BatteryWindow < 0 || cfg.BatteryInterval < 0 {
+		return nil, errors.New("battery window and interval cannot be negative")
+	}
+
+	if cfg.Retention < 0 {
+		return nil, errors.New("retention cannot be negative")
+	}
+
+	if cfg.RollupInterval < 0 {
+		return nil, errors.New("rollup interval cannot be negative")
+	}
+
+	if cfg.DedupTTL < 0 {
+		return nil, errors.New("dedup TTL cannot be negative")
+	}
+
+	if err := cfg.Timestamps.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Influx.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.RemoteWrite.validate(); err != nil {
+		return nil, err
+	}
+
+	if cfg.SMTP.Addr != "" && cfg.SMTP.From == "" {
+		return nil, errors.New("SMTP sender address cannot be empty")
+	}
+
+	if cfg.Quotas.RequestsPerMinute < 0 || cfg.Quotas.DeviceRequestsPerMinute < 0 || cfg.Quotas.ExportRowsPerDay < 0 {
+		return nil, errors.New("quota limits cannot be negative")
 	}
 
 	return &Server{
-		logger: cfg.Logger,
-		config: cfg,
+		logger:    cfg.Logger,
+		lifecycle: newLifecycle(cfg.Logger),
+		config:    cfg,
 	}, nil
 }
 
+// dbConfig returns the database configuration of the server.
+func (s *Server) dbConfig() *DBConfig {
+	return &DBConfig{
+		Driver:   s.config.DBDriver,
+		Host:     s.config.DBHost,
+		Port:     s.config.DBPort,
+		User:     s.config.DBUser,
+		Password: s.config.DBPassword,
+		DBName:   s.config.DBName,
+		SSLMode:  s.config.DBSSLMode,
+		Logger:   s.logger,
+
+		ConnectAttempts: s.config.DBConnectAttempts,
+		ConnectBackoff:  s.config.DBConnectBackoff,
+		WaitForDB:       s.config.WaitForDB,
+		SkipSchemaCheck: s.config.SkipSchemaCheck,
+
+		PrepareStmt:            s.config.DBPrepareStmt,
+		SkipDefaultTransaction: s.config.DBSkipDefaultTransaction,
+		StatementTimeout:       s.config.DBStatementTimeout,
+		SlowQueryThreshold:     s.config.DBSlowQueryThreshold,
+		Metrics:                s.config.Metrics,
+	}
+}
+
+// newMQClient connects to RabbitMQ, or to the in-process broker if one is
+// configured.
+func (s *Server) newMQClient(ctx context.Context, opts mq.Options) (mq.ClientInterface, error) {
+	if s.config.MQBroker != nil {
+		return s.config.MQBroker.NewClient(ctx, opts)
+	}
+
+	client, err := mq.NewWithOptions(ctx, s.config.RabbitMQURL, opts, s.logger)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.MQMetrics != nil {
+		client.SetMetrics(s.config.MQMetrics)
+	}
+	return client, nil
+}
+
 // Run starts the backend server and blocks until shutdown.
 func (s *Server) Run(ctx context.Context) error {
 	s.logger.Info("starting backend server")
@@ -113,22 +352,110 @@ func (s *Server) Run(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// Set up signal handling
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+	// Set up signal handling unless the embedding process handles signals;
+	// receiving from the nil channel otherwise blocks forever
+	var sigChan chan os.Signal
+	if s.config.HandleSignals {
+		sigChan = make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+		defer signal.Stop(sigChan)
+	}
 
-	// Initialize database
-	dbCfg := &DBConfig{
-		Host:     s.config.DBHost,
-		Port:     s.config.DBPort,
-		User:     s.config.DBUser,
-		Password: s.config.DBPassword,
-		DBName:   s.config.DBName,
-		SSLMode:  s.config.DBSSLMode,
-		Logger:   s.logger,
+	// A shutdown signal during startup, e.g. while waiting for the database,
+	// cancels ctx, which also ends Run once startup is done
+	startupDone := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-sigChan:
+			s.logger.Info("received shutdown signal during startup", "signal", sig.String())
+			cancel()
+		case <-startupDone:
+		}
+	}()
+
+	grpcErr := make(chan error, 1)
+	for _, c := range s.components(grpcErr) {
+		if err := s.lifecycle.start(ctx, c); err != nil {
+			close(startupDone)
+			// Stop what already runs, so a failed start leaks nothing
+			if stopErr := s.lifecycle.stop(context.Background()); stopErr != nil {
+				s.logger.Error("failed to stop components after failed startup", "error", stopErr)
+			}
+			return err
+		}
 	}
+	close(startupDone)
+
+	s.logStartup(ctx)
 
-	db, err := NewDB(dbCfg)
+	// Wait for shutdown signal or server errors
+	select {
+	case sig := <-sigChan:
+		s.logger.Info("received shutdown signal", "signal", sig.String())
+		cancel()
+	case <-ctx.Done():
+		s.logger.Info("context canceled")
+	case err := <-grpcErr:
+		s.logger.Error("gRPC server error", "error", err)
+		cancel()
+		if shutdownErr := s.Shutdown(); shutdownErr != nil {
+			return errors.Join(err, shutdownErr)
+		}
+		return err
+	}
+
+	return s.Shutdown()
+}
+
+// components returns the components of the server in start order. Each may
+// use the components before it; they stop in reverse order. Errors of the
+// running gRPC server are sent to grpcErr.
+func (s *Server) components(grpcErr chan<- error) []lifecycleComponent {
+	components := []lifecycleComponent{
+		{name: "database", Component: componentFuncs{start: s.startDB, stop: s.closeDB}},
+		{name: "message queue client", Component: componentFuncs{start: s.startMQClient, stop: s.closeMQClient}},
+	}
+
+	// The consumer forwards readings to the sinks, so the sinks stop after it
+	// and write what the consumer left
+	if s.config.Influx.URL != "" || s.config.RemoteWrite.URL != "" {
+		components = append(components, lifecycleComponent{
+			name:      "reading sinks",
+			Component: componentFuncs{start: s.startSinks, stop: s.stopSinks},
+		})
+	}
+
+	components = append(components, []lifecycleComponent{
+		{name: "consumer", Component: componentFuncs{start: s.startConsumer, stop: s.stopConsumer}},
+		{name: "device consumer", Component: componentFuncs{start: s.startDeviceConsumer, stop: s.stopDeviceConsumer}},
+		{name: "background jobs", Component: componentFuncs{start: s.startBackgroundJobs, stop: s.stopBackgroundJobs}},
+		{name: "gRPC server", Component: componentFuncs{
+			start: func(ctx context.Context) error { return s.startGRPCServer(ctx, grpcErr) },
+			stop:  s.stopGRPCServer,
+		}},
+	}...)
+
+	if s.config.ServesMetrics() && s.config.Metrics != nil {
+		metricsServer := &metricsServer{logger: s.logger, pprof: s.config.EnablePprof}
+		components = append(components, lifecycleComponent{
+			name: "metrics server",
+			Component: componentFuncs{
+				start: func(context.Context) error {
+					metricsServer.start(s.config.MetricsBindAddress, s.config.MetricsPort)
+					return nil
+				},
+				stop: metricsServer.stop,
+			},
+			stopTimeout: 5 * time.Second,
+		})
+	}
+
+	return components
+}
+
+// startDB connects to the database and registers its faults.
+func (s *Server) startDB(ctx context.Context) error {
+	db, err := NewDBContext(ctx, s.dbConfig())
 	if err != nil {
 		return fmt.Errorf("failed to initialize database: %w", err)
 	}
@@ -136,178 +463,423 @@ func (s *Server) Run(ctx context.Context) error {
 
 	s.logger.Info("database initialized successfully")
 
-	// Initialize consumer
-	consumerCfg := &ConsumerConfig{
-		Logger:      s.logger,
-		DB:          s.db,
-		RabbitMQURL: s.config.RabbitMQURL,
-		QueueName:   s.config.QueueName,
-		Metrics:     s.config.Metrics,
-		MQMetrics:   s.config.MQMetrics,
+	if err := s.config.Faults.RegisterDB(db); err != nil {
+		return fmt.Errorf("failed to register database faults: %w", err)
 	}
 
-	consumer, err := NewConsumer(consumerCfg)
+	return nil
+}
+
+func (s *Server) closeDB(context.Context) error {
+	return CloseDB(s.db, s.logger)
+}
+
+// startMQClient connects the message queue client shared by the consumers.
+func (s *Server) startMQClient(ctx context.Context) error {
+	// Both consumers share one RabbitMQ connection. Replicas compete for the
+	// messages of the same queues, each delivery goes to one of them.
+	mqOptions := mq.Options{
+		Queues:  []string{s.config.QueueName, s.config.DeviceQueueName},
+		Durable: s.config.DurableQueues,
+	}
+	if s.config.PriorityQueues {
+		mqOptions.MaxPriority = mq.MaxPriority
+	}
+	if s.config.InstanceID != "" {
+		mqOptions.ConsumerTag = "demo-app-backend-" + s.config.InstanceID
+	}
+
+	mqClient, err := s.newMQClient(ctx, mqOptions)
 	if err != nil {
-		return fmt.Errorf("failed to initialize consumer: %w", err)
+		return fmt.Errorf("failed to initialize message queue client: %w", err)
+	}
+	s.mqClient = mqClient
+
+	return nil
+}
+
+func (s *Server) closeMQClient(context.Context) error {
+	return s.mqClient.Close()
+}
+
+// startSinks starts the configured sinks forwarding saved readings.
+func (s *Server) startSinks(ctx context.Context) error {
+	if s.config.Influx.URL != "" {
+		influxSink, err := NewInfluxSink(&InfluxSinkConfig{
+			Logger:  s.logger,
+			Influx:  s.config.Influx,
+			Metrics: s.config.Metrics,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to initialize InfluxDB sink: %w", err)
+		}
+		s.sinks = append(s.sinks, influxSink)
 	}
-	s.consumer = consumer
 
-	// Start consumer
-	if err := s.consumer.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start consumer: %w", err)
+	if s.config.RemoteWrite.URL != "" {
+		remoteWriteSink, err := NewRemoteWriteSink(&RemoteWriteSinkConfig{
+			Logger:      s.logger,
+			RemoteWrite: s.config.RemoteWrite,
+			Metrics:     s.config.Metrics,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to initialize remote write sink: %w", err)
+		}
+		s.sinks = append(s.sinks, remoteWriteSink)
 	}
 
-	// Initialize device consumer
-	deviceConsumerCfg := &DeviceConsumerConfig{
-		Logger:      s.logger,
-		DB:          s.db,
-		RabbitMQURL: s.config.RabbitMQURL,
-		QueueName:   s.config.DeviceQueueName,
-		Metrics:     s.config.Metrics,
-		MQMetrics:   s.config.MQMetrics,
+	for _, sink := range s.sinks {
+		sink.Start(ctx)
 	}
 
-	deviceConsumer, err := NewDeviceConsumer(deviceConsumerCfg)
+	return nil
+}
+
+// stopSinks stops the sinks, each writing its buffered readings.
+func (s *Server) stopSinks(ctx context.Context) error {
+	var errs []error
+	for _, sink := range s.sinks {
+		if err := sink.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// startConsumer starts consuming sensor readings.
+func (s *Server) startConsumer(ctx context.Context) error {
+	consumer, err := NewConsumer(&ConsumerConfig{
+		Logger:     s.logger,
+		DB:         s.db,
+		QueueName:  s.config.QueueName,
+		Metrics:    s.config.Metrics,
+		MQMetrics:  s.config.MQMetrics,
+		MQClient:   s.mqClient,
+		Timestamps: s.config.Timestamps,
+		Sinks:      s.sinks,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize consumer: %w", err)
+	}
+	s.consumer = consumer
+
+	return s.consumer.Start(ctx)
+}
+
+func (s *Server) stopConsumer(context.Context) error {
+	return s.consumer.Stop()
+}
+
+// startDeviceConsumer starts consuming device updates.
+func (s *Server) startDeviceConsumer(ctx context.Context) error {
+	deviceConsumer, err := NewDeviceConsumer(&DeviceConsumerConfig{
+		Logger:    s.logger,
+		DB:        s.db,
+		QueueName: s.config.DeviceQueueName,
+		Metrics:   s.config.Metrics,
+		MQMetrics: s.config.MQMetrics,
+		MQClient:  s.mqClient,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to initialize device consumer: %w", err)
 	}
 	s.deviceConsumer = deviceConsumer
 
-	// Start device consumer
-	if err := s.deviceConsumer.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start device consumer: %w", err)
+	return s.deviceConsumer.Start(ctx)
+}
+
+func (s *Server) stopDeviceConsumer(context.Context) error {
+	return s.deviceConsumer.Stop()
+}
+
+// startBackgroundJobs starts the singleton background jobs, or the leader
+// election that starts them on one replica.
+func (s *Server) startBackgroundJobs(ctx context.Context) error {
+	// Background jobs must run on exactly one replica. SQLite databases are
+	// not shared between replicas, so without PostgreSQL this one always leads.
+	if !isPostgres(s.db) {
+		return s.startJobs(ctx)
+	}
+
+	leaderElector, err := NewLeaderElector(&LeaderElectorConfig{
+		Logger:    s.logger,
+		DB:        s.db,
+		OnElected: s.startJobs,
+		OnDemoted: s.stopJobs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize leader election: %w", err)
+	}
+	s.leaderElector = leaderElector
+	s.leaderElector.Start(ctx)
+
+	return nil
+}
+
+// stopBackgroundJobs stops the leader election, which stops the jobs if this
+// replica leads, and the jobs started without leader election.
+func (s *Server) stopBackgroundJobs(context.Context) error {
+	if s.leaderElector != nil {
+		s.leaderElector.Stop()
 	}
+	s.stopJobs()
+
+	return nil
+}
 
-	// Initialize gRPC service
+// startGRPCServer serves the IoT service. Serving errors are sent to errs.
+func (s *Server) startGRPCServer(_ context.Context, errs chan<- error) error {
 	iotService, err := NewIoTService(s.logger, s.db, s.config.Metrics)
 	if err != nil {
 		return fmt.Errorf("failed to initialize gRPC service: %w", err)
 	}
 
-	// Create gRPC server
-	s.grpcServer = grpc.NewServer()
-	iot.RegisterIoTServiceServer(s.grpcServer, iotService)
+	// Quota usage is always tracked so GetQuotaUsage works even without limits
+	quotas := newQuotaLimiter(s.config.Quotas)
+	iotService.quotas = quotas
+	iotService.exports = s.config.Export
 
-	// Start gRPC server
-	grpcAddr := fmt.Sprintf(":%d", s.config.GRPCPort)
-	lis, err := net.Listen("tcp", grpcAddr)
+	lis, err := listener.Listen(s.config.GRPCBindAddress, s.config.GRPCPort)
 	if err != nil {
-		return fmt.Errorf("failed to listen on %s: %w", grpcAddr, err)
+		return err
 	}
 
-	s.logger.Info("starting gRPC server", "address", grpcAddr)
+	s.grpcServer, err = s.newGRPCServer(iotService, quotas)
+	if err != nil {
+		_ = lis.Close()
+		return err
+	}
 
-	// Start gRPC server in goroutine
-	grpcErr := make(chan error, 1)
+	s.logger.Info("starting gRPC server", "address", lis.Addr().String())
+
+	server := s.grpcServer
 	go func() {
-		if err := s.grpcServer.Serve(lis); err != nil {
-			grpcErr <- fmt.Errorf("gRPC server error: %w", err)
+		if err := server.Serve(lis); err != nil {
+			errs <- fmt.Errorf("gRPC server error: %w", err)
 		}
-		close(grpcErr)
 	}()
 
-	// Start metrics HTTP server if configured
-	var metricsServer *http.Server
-	if s.config.MetricsPort > 0 && s.config.Metrics != nil {
-		metricsAddr := fmt.Sprintf(":%d", s.config.MetricsPort)
-		s.logger.Info("starting metrics HTTP server", "address", metricsAddr)
+	return nil
+}
 
-		mux := http.NewServeMux()
-		mux.Handle("/metrics", metrics.Handler())
+// stopGRPCServer stops the gRPC server after the running calls are done, or
+// cancels them when ctx is done first.
+func (s *Server) stopGRPCServer(ctx context.Context) error {
+	// Report not serving first so balancing clients move calls to other replicas
+	s.health.Shutdown()
 
-		metricsServer = &http.Server{
-			Addr:              metricsAddr,
-			Handler:           mux,
-			ReadHeaderTimeout: 10 * time.Second,
-		}
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
 
-		go func() {
-			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-				s.logger.Error("metrics server error", "error", err)
-			}
-		}()
+	select {
+	case <-stopped:
+		s.logger.Info("gRPC server stopped")
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		<-stopped
+		return errors.New("running calls canceled after graceful stop timed out")
 	}
+}
 
-	s.logger.Info("backend server started successfully")
+// metricsServer serves Prometheus metrics and, optionally, pprof over HTTP.
+type metricsServer struct {
+	logger *slog.Logger
+	pprof  bool
+	server *http.Server
+}
 
-	// Wait for shutdown signal or server errors
-	select {
-	case sig := <-sigChan:
-		s.logger.Info("received shutdown signal", "signal", sig.String())
-		cancel()
-	case <-ctx.Done():
-		s.logger.Info("context canceled")
-	case err := <-grpcErr:
-		if err != nil {
-			s.logger.Error("gRPC server error", "error", err)
-			cancel()
-			return err
+// start serves metrics on the given address. The backend runs on without
+// metrics when their address is taken.
+func (m *metricsServer) start(bindAddress string, port int) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	if m.pprof {
+		metrics.RegisterPprof(mux)
+		m.logger.Info("pprof endpoints enabled", "path", "/debug/pprof/")
+	}
+
+	m.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	lis, err := listener.Listen(bindAddress, port)
+	if err != nil {
+		m.logger.Error("metrics server error", "error", err)
+		return
+	}
+
+	m.logger.Info("starting metrics HTTP server", "address", lis.Addr().String())
+	server := m.server
+	go func() {
+		if err := server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			m.logger.Error("metrics server error", "error", err)
 		}
+	}()
+}
+
+func (m *metricsServer) stop(ctx context.Context) error {
+	if m.server == nil {
+		return nil
 	}
+	return m.server.Shutdown(ctx)
+}
 
-	// Shutdown servers
-	if metricsServer != nil {
-		shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 5*time.Second)
-		defer shutdownCancel()
-		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
-			s.logger.Error("failed to shutdown metrics server", "error", err)
+// startJobs starts the singleton background jobs. It runs on the leader only.
+func (s *Server) startJobs(ctx context.Context) error {
+	// Background jobs below rely on PostgreSQL-only SQL
+	if isPostgres(s.db) {
+		if err := s.startPostgresJobs(ctx); err != nil {
+			return err
 		}
+	} else {
+		s.logger.Warn("battery projections, retention and rollups require PostgreSQL, skipping", "driver", s.config.DBDriver)
 	}
 
-	return s.Shutdown()
+	// Initialize dedup cleanup job
+	dedupCleanupJob, err := NewDedupCleanupJob(&DedupCleanupJobConfig{
+		Logger: s.logger,
+		DB:     s.db,
+		TTL:    s.config.DedupTTL,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize dedup cleanup job: %w", err)
+	}
+	s.dedupCleanupJob = dedupCleanupJob
+	s.dedupCleanupJob.Start(ctx)
+
+	// Initialize report scheduler
+	reportScheduler, err := NewReportScheduler(&ReportSchedulerConfig{
+		Logger: s.logger,
+		DB:     s.db,
+		SMTP:   s.config.SMTP,
+		Export: s.config.Export,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize report scheduler: %w", err)
+	}
+	s.reportScheduler = reportScheduler
+	s.reportScheduler.Start(ctx)
+
+	// Initialize uptime recorder
+	uptimeRecorder, err := NewUptimeRecorder(&UptimeRecorderConfig{
+		Logger: s.logger,
+		DB:     s.db,
+		Checks: s.componentChecks(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize uptime recorder: %w", err)
+	}
+	s.uptimeRecorder = uptimeRecorder
+	s.uptimeRecorder.Start(ctx)
+
+	return nil
 }
 
-// Shutdown gracefully shuts down the server.
-func (s *Server) Shutdown() error {
-	s.logger.Info("shutting down backend server")
+// componentChecks returns the health checks of the components whose uptime
+// is recorded for the status page.
+func (s *Server) componentChecks() []ComponentCheck {
+	return []ComponentCheck{
+		{Name: componentDatabase, Check: func(ctx context.Context) error { return pingDB(ctx, s.db) }},
+		{Name: componentMessageQueue, Check: s.mqClient.WaitReady},
+		{Name: componentIngestion, Check: ingestionCheck(s.db)},
+	}
+}
 
-	var shutdownErr error
+// stopJobs stops the background jobs started by startJobs, so a later
+// startJobs starts fresh ones.
+func (s *Server) stopJobs() {
+	// Stop uptime recorder
+	if s.uptimeRecorder != nil {
+		s.uptimeRecorder.Stop()
+		s.uptimeRecorder = nil
+	}
 
-	// Stop gRPC server
-	if s.grpcServer != nil {
-		s.logger.Info("stopping gRPC server")
-		s.grpcServer.GracefulStop()
-		s.logger.Info("gRPC server stopped")
+	// Stop report scheduler
+	if s.reportScheduler != nil {
+		s.reportScheduler.Stop()
+		s.reportScheduler = nil
 	}
 
-	// Stop device consumer
-	if s.deviceConsumer != nil {
-		s.logger.Info("stopping device consumer")
-		if err := s.deviceConsumer.Stop(); err != nil {
-			s.logger.Error("failed to stop device consumer", "error", err)
-			shutdownErr = fmt.Errorf("device consumer shutdown error: %w", err)
-		}
+	// Stop retention job
+	if s.retentionJob != nil {
+		s.retentionJob.Stop()
+		s.retentionJob = nil
 	}
 
-	// Stop consumer
-	if s.consumer != nil {
-		s.logger.Info("stopping consumer")
-		if err := s.consumer.Stop(); err != nil {
-			s.logger.Error("failed to stop consumer", "error", err)
-			if shutdownErr != nil {
-				shutdownErr = fmt.Errorf("%w; consumer shutdown error: %w", shutdownErr, err)
-			} else {
-				shutdownErr = fmt.Errorf("consumer shutdown error: %w", err)
-			}
-		}
+	// Stop rollup job
+	if s.rollupJob != nil {
+		s.rollupJob.Stop()
+		s.rollupJob = nil
 	}
 
-	// Close database
-	if s.db != nil {
-		s.logger.Info("closing database connection")
-		if err := CloseDB(s.db, s.logger); err != nil {
-			s.logger.Error("failed to close database", "error", err)
-			if shutdownErr != nil {
-				shutdownErr = fmt.Errorf("%w; database close error: %w", shutdownErr, err)
-			} else {
-				shutdownErr = fmt.Errorf("database close error: %w", err)
-			}
-		}
+	// Stop dedup cleanup job
+	if s.dedupCleanupJob != nil {
+		s.dedupCleanupJob.Stop()
+		s.dedupCleanupJob = nil
+	}
+
+	// Stop battery projector
+	if s.batteryProjector != nil {
+		s.batteryProjector.Stop()
+		s.batteryProjector = nil
+	}
+}
+
+// startPostgresJobs starts the background jobs that need PostgreSQL.
+func (s *Server) startPostgresJobs(ctx context.Context) error {
+	// Initialize battery projector
+	batteryProjector, err := NewBatteryProjector(&BatteryProjectorConfig{
+		Logger:   s.logger,
+		DB:       s.db,
+		Window:   s.config.BatteryWindow,
+		Interval: s.config.BatteryInterval,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize battery projector: %w", err)
+	}
+	s.batteryProjector = batteryProjector
+	s.batteryProjector.Start(ctx)
+
+	// Initialize retention job
+	retentionJob, err := NewRetentionJob(&RetentionJobConfig{
+		Logger:    s.logger,
+		DB:        s.db,
+		Retention: s.config.Retention,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize retention job: %w", err)
 	}
+	s.retentionJob = retentionJob
+	s.retentionJob.Start(ctx)
+
+	// Initialize rollup job
+	rollupJob, err := NewRollupJob(&RollupJobConfig{
+		Logger:   s.logger,
+		DB:       s.db,
+		Interval: s.config.RollupInterval,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize rollup job: %w", err)
+	}
+	s.rollupJob = rollupJob
+	s.rollupJob.Start(ctx)
+
+	return nil
+}
+
+// Shutdown gracefully shuts down the server, stopping its components in
+// reverse start order. Components stopped before are not stopped again.
+func (s *Server) Shutdown() error {
+	s.logger.Info("shutting down backend server")
 
-	if shutdownErr != nil {
-		s.logger.Error("backend server shutdown completed with errors", "error", shutdownErr)
-		return shutdownErr
+	if err := s.lifecycle.stop(context.Background()); err != nil {
+		s.logger.Error("backend server shutdown completed with errors", "error", err)
+		return err
 	}
 
 	s.logger.Info("backend server shutdown completed successfully")
@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/pkg/logger"
+)
+
+// deprecationHeader is set on responses to deprecated methods. Its value
+// names the replacement.
+const deprecationHeader = "x-deprecated"
+
+// serverCapabilities are the optional features this backend supports.
+var serverCapabilities = []string{
+	iotv1.CapabilityReadingSeries,
+	iotv1.CapabilityAlertRules,
+	iotv1.CapabilityDeviceNotes,
+	iotv1.CapabilityDeviceTrash,
+	iotv1.CapabilityQuotaUsage,
+	iotv1.CapabilityBatteryReport,
+	iotv1.CapabilityReportSchedules,
+	iotv1.CapabilityLocationHistory,
+	iotv1.CapabilityReadingsHeatmap,
+	iotv1.CapabilityStatusSummary,
+	iotv1.CapabilityBucketExport,
+	iotv1.CapabilityDeviceRegistration,
+	iotv1.CapabilityDeviceHistory,
+}
+
+// legacyMethods returns the full method names of the unversioned service.
+func legacyMethods() []string {
+	methods := make([]string, 0, len(iotv1.IoTService_ServiceDesc.Methods))
+	for _, m := range iotv1.IoTService_ServiceDesc.Methods {
+		methods = append(methods, "/"+legacyServiceName+"/"+m.MethodName)
+	}
+	return methods
+}
+
+// GetServerInfo reports the API version, capabilities and deprecated methods
+// so clients of other releases can adapt during rollouts.
+func (s *IoTServiceImpl) GetServerInfo(context.Context, *iotv1.GetServerInfoRequest) (*iotv1.GetServerInfoResponse, error) {
+	return &iotv1.GetServerInfoResponse{
+		ApiVersion:        iotv1.APIVersion,
+		ServerVersion:     logger.Version(),
+		Capabilities:      serverCapabilities,
+		DeprecatedMethods: legacyMethods(),
+	}, nil
+}
+
+// deprecationInterceptor marks responses to calls through the unversioned
+// service name with deprecationHeader, and logs the first call of each such
+// method so operators can find clients that still need upgrading.
+func deprecationInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	var logged sync.Map
+
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		method, _ := grpc.Method(ctx)
+		name, ok := strings.CutPrefix(method, "/"+legacyServiceName+"/")
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		replacement := "/" + iotv1.IoTService_ServiceDesc.ServiceName + "/" + name
+		// Only fails outside a real server stream, e.g. in tests
+		_ = grpc.SetHeader(ctx, metadata.Pairs(deprecationHeader, "use "+replacement))
+		if _, seen := logged.LoadOrStore(method, true); !seen {
+			logger.Warn("deprecated method called", "method", method, "replacement", replacement)
+		}
+
+		return handler(ctx, req)
+	}
+}
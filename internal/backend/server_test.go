@@ -1,13 +1,18 @@
 package backend_test
 
 import (
+	"context"
 	"log/slog"
+	"net"
 	"os"
+	"path/filepath"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"procodus.dev/demo-app/internal/backend"
+	"procodus.dev/demo-app/pkg/mq/inmem"
 )
 
 var _ = Describe("Backend Server", func() {
@@ -198,6 +203,26 @@ var _ = Describe("Backend Server", func() {
 				Expect(server).To(BeNil())
 			})
 
+			It("should return error when both queues have the same name", func() {
+				config := &backend.ServerConfig{
+					Logger:          logger,
+					DBHost:          "localhost",
+					DBPort:          5432,
+					DBUser:          "test",
+					DBPassword:      "password",
+					DBName:          "testdb",
+					DBSSLMode:       "disable",
+					RabbitMQURL:     "amqp://localhost:5672",
+					QueueName:       "test-queue",
+					DeviceQueueName: "test-queue",
+					GRPCPort:        9090,
+				}
+
+				server, err := backend.NewServer(config)
+				Expect(err).To(MatchError(ContainSubstring("must differ")))
+				Expect(server).To(BeNil())
+			})
+
 			It("should return error when database host is empty", func() {
 				config := &backend.ServerConfig{
 					Logger:          logger,
@@ -219,6 +244,38 @@ var _ = Describe("Backend Server", func() {
 				Expect(server).To(BeNil())
 			})
 
+			It("should not require host, port and user for sqlite", func() {
+				config := &backend.ServerConfig{
+					Logger:          logger,
+					DBDriver:        backend.DriverSQLite,
+					DBName:          "demo.db",
+					RabbitMQURL:     "amqp://localhost:5672",
+					QueueName:       "test-queue",
+					DeviceQueueName: "device-queue",
+					GRPCPort:        9090,
+				}
+
+				server, err := backend.NewServer(config)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server).NotTo(BeNil())
+			})
+
+			It("should return error for unknown database drivers", func() {
+				config := &backend.ServerConfig{
+					Logger:          logger,
+					DBDriver:        "mysql",
+					DBName:          "testdb",
+					RabbitMQURL:     "amqp://localhost:5672",
+					QueueName:       "test-queue",
+					DeviceQueueName: "device-queue",
+					GRPCPort:        9090,
+				}
+
+				server, err := backend.NewServer(config)
+				Expect(err).To(MatchError(ContainSubstring("unsupported database driver")))
+				Expect(server).To(BeNil())
+			})
+
 			It("should return error when database port is zero", func() {
 				config := &backend.ServerConfig{
 					Logger:          logger,
@@ -344,6 +401,161 @@ var _ = Describe("Backend Server", func() {
 				Expect(err.Error()).To(ContainSubstring("gRPC port"))
 				Expect(server).To(BeNil())
 			})
+
+			It("should not require a gRPC port with a Unix socket", func() {
+				config := &backend.ServerConfig{
+					Logger:             logger,
+					DBHost:             "localhost",
+					DBPort:             5432,
+					DBUser:             "test",
+					DBPassword:         "password",
+					DBName:             "testdb",
+					DBSSLMode:          "disable",
+					RabbitMQURL:        "amqp://localhost:5672",
+					QueueName:          "test-queue",
+					DeviceQueueName:    "device-queue",
+					GRPCBindAddress:    "unix:///run/demo-app/grpc.sock",
+					MetricsBindAddress: "unix:///run/demo-app/metrics.sock",
+				}
+
+				server, err := backend.NewServer(config)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server).NotTo(BeNil())
+				Expect(config.ServesMetrics()).To(BeTrue())
+			})
+
+			DescribeTable("should return error for invalid bind addresses",
+				func(grpcBind, metricsBind, message string) {
+					config := &backend.ServerConfig{
+						Logger:             logger,
+						DBHost:             "localhost",
+						DBPort:             5432,
+						DBUser:             "test",
+						DBPassword:         "password",
+						DBName:             "testdb",
+						DBSSLMode:          "disable",
+						RabbitMQURL:        "amqp://localhost:5672",
+						QueueName:          "test-queue",
+						DeviceQueueName:    "device-queue",
+						GRPCPort:           9090,
+						GRPCBindAddress:    grpcBind,
+						MetricsBindAddress: metricsBind,
+					}
+
+					server, err := backend.NewServer(config)
+					Expect(err).To(MatchError(ContainSubstring(message)))
+					Expect(server).To(BeNil())
+				},
+				Entry("gRPC address with port", "127.0.0.1:9090", "", "without port"),
+				Entry("socket without path", "unix://", "", "no socket path"),
+				Entry("metrics address without port", "", "127.0.0.1", "metrics port"),
+			)
+
+			It("should return error when a keepalive duration is negative", func() {
+				config := &backend.ServerConfig{
+					Logger:          logger,
+					DBHost:          "localhost",
+					DBPort:          5432,
+					DBUser:          "test",
+					DBPassword:      "password",
+					DBName:          "testdb",
+					DBSSLMode:       "disable",
+					RabbitMQURL:     "amqp://localhost:5672",
+					QueueName:       "test-queue",
+					DeviceQueueName: "device-queue",
+					GRPCPort:        9090,
+					Keepalive:       backend.KeepaliveConfig{MaxConnectionAge: -time.Minute},
+				}
+
+				server, err := backend.NewServer(config)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("keepalive"))
+				Expect(server).To(BeNil())
+			})
+
+			It("should return error when database connect attempts are negative", func() {
+				config := &backend.ServerConfig{
+					Logger:            logger,
+					DBHost:            "localhost",
+					DBPort:            5432,
+					DBUser:            "test",
+					DBPassword:        "password",
+					DBName:            "testdb",
+					DBSSLMode:         "disable",
+					DBConnectAttempts: -1,
+					RabbitMQURL:       "amqp://localhost:5672",
+					QueueName:         "test-queue",
+					DeviceQueueName:   "device-queue",
+					GRPCPort:          9090,
+				}
+
+				server, err := backend.NewServer(config)
+				Expect(err).To(MatchError("database connect attempts and backoff cannot be negative"))
+				Expect(server).To(BeNil())
+			})
+
+			It("should return error when the slow query threshold is negative", func() {
+				config := &backend.ServerConfig{
+					Logger:               logger,
+					DBHost:               "localhost",
+					DBPort:               5432,
+					DBUser:               "test",
+					DBPassword:           "password",
+					DBName:               "testdb",
+					DBSSLMode:            "disable",
+					DBSlowQueryThreshold: -time.Second,
+					RabbitMQURL:          "amqp://localhost:5672",
+					QueueName:            "test-queue",
+					DeviceQueueName:      "device-queue",
+					GRPCPort:             9090,
+				}
+
+				server, err := backend.NewServer(config)
+				Expect(err).To(MatchError("statement timeout and slow query threshold cannot be negative"))
+				Expect(server).To(BeNil())
+			})
+
+			It("should return error when the dedup TTL is negative", func() {
+				config := &backend.ServerConfig{
+					Logger:          logger,
+					DBHost:          "localhost",
+					DBPort:          5432,
+					DBUser:          "test",
+					DBPassword:      "password",
+					DBName:          "testdb",
+					DBSSLMode:       "disable",
+					RabbitMQURL:     "amqp://localhost:5672",
+					QueueName:       "test-queue",
+					DeviceQueueName: "device-queue",
+					GRPCPort:        9090,
+					DedupTTL:        -time.Hour,
+				}
+
+				server, err := backend.NewServer(config)
+				Expect(err).To(MatchError("dedup TTL cannot be negative"))
+				Expect(server).To(BeNil())
+			})
+
+			It("should return error for an unknown timestamp policy", func() {
+				config := &backend.ServerConfig{
+					Logger:          logger,
+					DBHost:          "localhost",
+					DBPort:          5432,
+					DBUser:          "test",
+					DBPassword:      "password",
+					DBName:          "testdb",
+					DBSSLMode:       "disable",
+					RabbitMQURL:     "amqp://localhost:5672",
+					QueueName:       "test-queue",
+					DeviceQueueName: "device-queue",
+					GRPCPort:        9090,
+					Timestamps:      backend.TimestampConfig{Policy: "ignore"},
+				}
+
+				server, err := backend.NewServer(config)
+				Expect(err).To(MatchError(`unknown timestamp policy "ignore"`))
+				Expect(server).To(BeNil())
+			})
 		})
 
 		Context("with different configurations", func() {
@@ -544,4 +756,69 @@ var _ = Describe("Backend Server", func() {
 			}
 		})
 	})
+
+	Describe("Validate", func() {
+		var closedAddr string
+
+		BeforeEach(func() {
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			closedAddr = lis.Addr().String()
+			Expect(lis.Close()).To(Succeed())
+		})
+
+		newServer := func(dbName string) *backend.Server {
+			server, err := backend.NewServer(&backend.ServerConfig{
+				Logger:          logger,
+				DBDriver:        backend.DriverSQLite,
+				DBName:          dbName,
+				RabbitMQURL:     "amqp://guest:guest@" + closedAddr + "/",
+				QueueName:       "test-queue",
+				DeviceQueueName: "device-queue",
+				GRPCPort:        9090,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			return server
+		}
+
+		It("should report an unreachable RabbitMQ", func() {
+			server := newServer(filepath.Join(GinkgoT().TempDir(), "demo.db"))
+
+			err := server.Validate(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("rabbitmq:")))
+			Expect(err.Error()).NotTo(ContainSubstring("database:"))
+		})
+
+		It("should report all failures at once", func() {
+			server := newServer(filepath.Join(GinkgoT().TempDir(), "missing", "demo.db"))
+
+			err := server.Validate(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("rabbitmq:")))
+			Expect(err.Error()).To(ContainSubstring("database:"))
+		})
+
+		It("should not check RabbitMQ with an in-process broker", func() {
+			server, err := backend.NewServer(&backend.ServerConfig{
+				Logger:          logger,
+				DBDriver:        backend.DriverSQLite,
+				DBName:          filepath.Join(GinkgoT().TempDir(), "demo.db"),
+				MQBroker:        inmem.NewBroker(0),
+				QueueName:       "test-queue",
+				DeviceQueueName: "device-queue",
+				GRPCPort:        9090,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(server.Validate(context.Background())).To(Succeed())
+		})
+
+		It("should stop checking when the context is canceled", func() {
+			server := newServer(filepath.Join(GinkgoT().TempDir(), "demo.db"))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			Expect(server.Validate(ctx)).To(MatchError(ContainSubstring("rabbitmq:")))
+		})
+	})
 })
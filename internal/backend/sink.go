@@ -0,0 +1,288 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+const (
+	// defaultSinkBatchSize is used when a sink sets no batch size.
+	defaultSinkBatchSize = 5000
+	// defaultSinkFlushInterval is used when a sink sets no flush interval.
+	defaultSinkFlushInterval = 5 * time.Second
+	// defaultSinkMaxPending is used when a sink sets no pending limit.
+	defaultSinkMaxPending = 100000
+	// sinkWriteTimeout bounds a single write request of a sink.
+	sinkWriteTimeout = 10 * time.Second
+)
+
+// sinkWriter writes batches of readings to the endpoint of a ReadingSink.
+// Only one write runs at a time.
+type sinkWriter interface {
+	// write writes readings in one request and returns how many of them it
+	// left out because the endpoint cannot take them. The error is a
+	// *sinkRejectedError if the endpoint refused the batch.
+	write(ctx context.Context, readings []SensorReading) (skipped int, err error)
+}
+
+// sinkOptions configure the batching of a ReadingSink; zero values select
+// the defaults.
+type sinkOptions struct {
+	batchSize     int
+	flushInterval time.Duration
+	maxPending    int
+}
+
+// validateSinkOptions checks the batching options of the sink named name.
+func validateSinkOptions(name string, batchSize int, flushInterval time.Duration, maxPending int) error {
+	if batchSize < 0 || flushInterval < 0 || maxPending < 0 {
+		return fmt.Errorf("%s batch size, flush interval and max pending points cannot be negative", name)
+	}
+	return nil
+}
+
+// validateSinkURL checks the endpoint URL of the sink named name. The URL is
+// left out of errors, it may hold credentials.
+func validateSinkURL(name, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s URL must be an http or https URL", name)
+	}
+	return nil
+}
+
+// ReadingSink forwards saved sensor readings to a secondary store, such as
+// InfluxDB or Prometheus, in batches. Readings are buffered in memory, so a
+// slow or unavailable endpoint never holds up the consumer; failed batches
+// are retried on the next flush until the buffer is full.
+type ReadingSink struct {
+	name          string // Sink label of metrics and logs
+	logger        *slog.Logger
+	writer        sinkWriter
+	batchSize     int
+	flushInterval time.Duration
+	maxPending    int
+	metrics       *metrics.BackendMetrics // Optional metrics
+
+	mu      sync.Mutex
+	pending []SensorReading // Readings in arrival order
+	full    chan struct{}
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// newReadingSink creates a sink writing with writer.
+func newReadingSink(name string, logger *slog.Logger, writer sinkWriter, opts sinkOptions, m *metrics.BackendMetrics) *ReadingSink {
+	s := &ReadingSink{
+		name:          name,
+		logger:        logger.With("sink", name),
+		writer:        writer,
+		batchSize:     opts.batchSize,
+		flushInterval: opts.flushInterval,
+		maxPending:    opts.maxPending,
+		metrics:       m,
+		full:          make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+	if s.batchSize == 0 {
+		s.batchSize = defaultSinkBatchSize
+	}
+	if s.flushInterval == 0 {
+		s.flushInterval = defaultSinkFlushInterval
+	}
+	if s.maxPending == 0 {
+		s.maxPending = defaultSinkMaxPending
+	}
+	return s
+}
+
+// Name returns the name of the sink, such as "influx".
+func (s *ReadingSink) Name() string {
+	return s.name
+}
+
+// Start begins flushing buffered readings in the background.
+func (s *ReadingSink) Start(ctx context.Context) {
+	s.logger.Info("starting reading sink", "batch_size", s.batchSize, "flush_interval", s.flushInterval)
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	go s.run(ctx)
+}
+
+// Stop stops the background flushing and writes the buffered readings,
+// giving up when ctx is done. It reports how many readings were not written.
+func (s *ReadingSink) Stop(ctx context.Context) error {
+	s.logger.Info("stopping reading sink")
+
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+
+	if err := s.flush(ctx); err != nil {
+		return fmt.Errorf("%d readings not written: %w", s.pendingCount(), err)
+	}
+
+	s.logger.Info("reading sink stopped")
+	return nil
+}
+
+// run flushes the buffer every flush interval and whenever a batch is full,
+// until ctx is canceled.
+func (s *ReadingSink) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.full:
+		}
+
+		if err := s.flush(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Warn("failed to write readings, retrying on next flush",
+				"pending", s.pendingCount(),
+				"error", err,
+			)
+		}
+	}
+}
+
+// Add buffers readings to be written with the next batch. Readings arriving
+// while the buffer is full are dropped.
+func (s *ReadingSink) Add(readings ...SensorReading) {
+	s.mu.Lock()
+	room := max(s.maxPending-len(s.pending), 0)
+	dropped := max(len(readings)-room, 0)
+	s.pending = append(s.pending, readings[:len(readings)-dropped]...)
+	pending := len(s.pending)
+	s.mu.Unlock()
+
+	if dropped > 0 {
+		s.logger.Warn("reading sink buffer full, dropping readings", "dropped", dropped)
+		if s.metrics != nil {
+			s.metrics.SinkPointsTotal.WithLabelValues(s.name, "dropped").Add(float64(dropped))
+		}
+	}
+	if s.metrics != nil {
+		s.metrics.SinkPendingPoints.WithLabelValues(s.name).Set(float64(pending))
+	}
+
+	if pending >= s.batchSize {
+		select {
+		case s.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// flush writes the buffered readings batch by batch until the buffer is
+// empty or a write fails. Only one flush runs at a time and Add only
+// appends, so the readings written are still the first ones when they are
+// removed.
+func (s *ReadingSink) flush(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		batch := slices.Clone(s.pending[:min(len(s.pending), s.batchSize)])
+		s.mu.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		status := "written"
+		skipped, err := s.writer.write(ctx, batch)
+		var rejected *sinkRejectedError
+		if errors.As(err, &rejected) {
+			// Sending a rejected batch again would fail again
+			s.logger.Error("sink rejected readings, dropping them", "readings", len(batch), "error", err)
+			status = "rejected"
+		} else if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		s.pending = slices.Delete(s.pending, 0, len(batch))
+		pending := len(s.pending)
+		s.mu.Unlock()
+
+		if s.metrics != nil {
+			s.metrics.SinkPointsTotal.WithLabelValues(s.name, status).Add(float64(len(batch) - skipped))
+			s.metrics.SinkPointsTotal.WithLabelValues(s.name, "skipped").Add(float64(skipped))
+			s.metrics.SinkPendingPoints.WithLabelValues(s.name).Set(float64(pending))
+		}
+	}
+}
+
+// pendingCount returns the number of buffered readings.
+func (s *ReadingSink) pendingCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+// sinkRejectedError reports a batch the endpoint refused as invalid, as
+// opposed to failing to take it.
+type sinkRejectedError struct {
+	status int
+	body   string
+}
+
+func (e *sinkRejectedError) Error() string {
+	return fmt.Sprintf("write rejected with status %d: %s", e.status, e.body)
+}
+
+// postBatch sends body to rawURL with header. Too many requests and server
+// errors are returned as plain errors to be retried; other client errors
+// mean the data or the configuration is wrong and are returned as a
+// *sinkRejectedError.
+func postBatch(ctx context.Context, client *http.Client, rawURL string, header http.Header, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, sinkWriteTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// The URL is left out of errors, it may hold credentials
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("write failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+		return &sinkRejectedError{status: resp.StatusCode, body: strings.TrimSpace(string(msg))}
+	}
+	return fmt.Errorf("write failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,55 @@
+package backend
+
+import (
+	"context"
+
+	"procodus.dev/demo-app/pkg/logger"
+	"procodus.dev/demo-app/pkg/mq"
+)
+
+// logStartup logs the startup event of the backend with the versions of the
+// database and broker it connected to.
+func (s *Server) logStartup(ctx context.Context) {
+	dbVersion, err := dbServerVersion(ctx, s.db)
+	if err != nil {
+		s.logger.Warn("failed to get database server version", "error", err)
+		dbVersion = logger.Unknown
+	}
+
+	brokerVersion := logger.Unknown
+	if v, ok := s.mqClient.(mq.ServerVersioner); ok && v.ServerVersion() != "" {
+		brokerVersion = v.ServerVersion()
+	}
+
+	logger.LogStartup(s.logger, logger.Startup{
+		Service: "backend",
+		Config:  s.config.StartupConfig,
+		Dependencies: map[string]string{
+			"database":      dbVersion,
+			"message_queue": brokerVersion,
+		},
+		Features: s.features(),
+		Attrs:    []any{"schema_version", SchemaVersion, "grpc_interceptors", s.interceptors},
+	})
+}
+
+// features returns the optional features the configuration enables.
+func (s *Server) features() map[string]bool {
+	c := s.config
+	return map[string]bool{
+		"durable_queues":   c.DurableQueues,
+		"priority_queues":  c.PriorityQueues,
+		"leader_election":  isPostgres(s.db),
+		"grpc_reflection":  c.EnableReflection,
+		"grpc_compression": c.CompressMinSize > 0,
+		"metrics":          c.ServesMetrics() && c.Metrics != nil,
+		"pprof":            c.EnablePprof,
+		"quotas":           c.Quotas.RequestsPerMinute > 0 || c.Quotas.DeviceRequestsPerMinute > 0 || c.Quotas.ExportRowsPerDay > 0,
+		"retention":        c.Retention > 0,
+		"email_reports":    c.SMTP.Addr != "",
+		"bucket_exports":   c.Export.Store != nil,
+		"fault_injection":  c.Faults != nil,
+		"influx_sink":      c.Influx.URL != "",
+		"remote_write":     c.RemoteWrite.URL != "",
+	}
+}
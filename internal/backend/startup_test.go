@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"procodus.dev/demo-app/pkg/mq"
+	"procodus.dev/demo-app/pkg/mq/inmem"
+)
+
+var _ = Describe("Startup event", func() {
+	It("should report the database version", func() {
+		db := newTestDB(slog.New(slog.DiscardHandler))
+
+		version, err := dbServerVersion(context.Background(), db)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(MatchRegexp(`^SQLite 3\.\d+\.\d+$`))
+	})
+
+	It("should log the versions, schema, features and configuration once", func() {
+		buf := &bytes.Buffer{}
+		logger := slog.New(slog.NewJSONHandler(buf, nil))
+
+		db := newTestDB(slog.New(slog.DiscardHandler))
+
+		mqClient, err := inmem.NewBroker(10).NewClient(context.Background(), mq.Options{Queues: []string{"sensor-data"}})
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = mqClient.Close() }()
+
+		s := &Server{
+			logger:   logger,
+			db:       db,
+			mqClient: mqClient,
+			config: &ServerConfig{
+				EnableReflection: true,
+				Quotas:           QuotaConfig{RequestsPerMinute: 60},
+				StartupConfig:    []any{"grpc_port", 50051},
+			},
+		}
+		s.logStartup(context.Background())
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		Expect(lines).To(HaveLen(1))
+
+		var event map[string]any
+		Expect(json.Unmarshal([]byte(lines[0]), &event)).To(Succeed())
+		Expect(event).To(HaveKeyWithValue("msg", "backend server started"))
+		Expect(event).To(HaveKeyWithValue("schema_version", float64(SchemaVersion)))
+		Expect(event).To(HaveKeyWithValue("dependencies", And(
+			HaveKeyWithValue("database", HavePrefix("SQLite ")),
+			HaveKeyWithValue("message_queue", "in-process broker"),
+		)))
+		Expect(event).To(HaveKeyWithValue("features", []any{"grpc_reflection", "quotas"}))
+		Expect(event).To(HaveKeyWithValue("config", map[string]any{"grpc_port": float64(50051)}))
+	})
+})
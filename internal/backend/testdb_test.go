@@ -0,0 +1,24 @@
+package backend
+
+import (
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+// newTestDB opens a migrated in-memory SQLite database that is closed when
+// the current spec ends.
+func newTestDB(logger *slog.Logger) *gorm.DB {
+	GinkgoHelper()
+
+	db, err := NewDB(&DBConfig{Logger: logger, Driver: DriverSQLite, DBName: ":memory:"})
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() { Expect(CloseDB(db, logger)).To(Succeed()) })
+
+	return db
+}
+
+// NewTestDB is newTestDB for the specs of package backend_test.
+var NewTestDB = newTestDB
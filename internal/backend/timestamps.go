@@ -0,0 +1,164 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// Timestamp policies for readings whose timestamp lies outside the bounds
+// around the time they are received, e.g. from devices with wrong clocks.
+const (
+	TimestampPolicyAccept  = "accept"  // Save the reading as it is
+	TimestampPolicyCorrect = "correct" // Save the reading with the time it was received
+	TimestampPolicyClamp   = "clamp"   // Save the reading with the nearest bound as its timestamp
+	TimestampPolicyReject  = "reject"  // Quarantine the reading instead of saving it
+)
+
+// Reasons a reading timestamp is out of bounds.
+const (
+	timestampReasonFuture = "future"
+	timestampReasonTooOld = "too_old"
+)
+
+// defaultMaxFuture is how far ahead of the receive time a reading timestamp
+// may be when no bound is configured.
+const defaultMaxFuture = time.Hour
+
+// TimestampConfig configures how consumers treat reading timestamps. The zero
+// value accepts all readings.
+type TimestampConfig struct {
+	Policy    string        // One of the TimestampPolicy constants (optional, default accept)
+	MaxFuture time.Duration // Largest allowed distance ahead of the receive time (optional, default 1 hour)
+	MaxAge    time.Duration // Largest allowed distance behind the receive time (optional, 0 = unlimited)
+}
+
+// validate checks the timestamp configuration.
+func (c TimestampConfig) validate() error {
+	switch c.Policy {
+	case "", TimestampPolicyAccept, TimestampPolicyCorrect, TimestampPolicyClamp, TimestampPolicyReject:
+	default:
+		return fmt.Errorf("unknown timestamp policy %q", c.Policy)
+	}
+
+	if c.MaxFuture < 0 || c.MaxAge < 0 {
+		return errors.New("timestamp bounds cannot be negative")
+	}
+
+	return nil
+}
+
+// checkTimestamp applies the timestamp policy to a reading received at now,
+// correcting or clamping its timestamp if configured to. It returns the
+// reason a rejected reading is to be quarantined, or "" if the reading is to
+// be saved.
+func (c *Consumer) checkTimestamp(reading *iotv1.SensorReading, now time.Time) string {
+	maxFuture := c.timestamps.MaxFuture
+	if maxFuture == 0 {
+		maxFuture = defaultMaxFuture
+	}
+
+	timestamp := time.Unix(reading.GetTimestamp(), 0)
+
+	var (
+		reason string
+		bound  time.Time
+	)
+	switch {
+	case timestamp.After(now.Add(maxFuture)):
+		reason, bound = timestampReasonFuture, now.Add(maxFuture)
+	case c.timestamps.MaxAge > 0 && timestamp.Before(now.Add(-c.timestamps.MaxAge)):
+		reason, bound = timestampReasonTooOld, now.Add(-c.timestamps.MaxAge)
+	default:
+		return ""
+	}
+
+	policy := c.timestamps.Policy
+	if policy == "" {
+		policy = TimestampPolicyAccept
+	}
+
+	// Track out-of-bounds reading
+	if c.metrics != nil {
+		c.metrics.ConsumerOutOfBoundsReadings.WithLabelValues(c.queueName, reason, policy).Inc()
+	}
+
+	switch policy {
+	case TimestampPolicyCorrect:
+		c.logger.Debug("correcting out-of-bounds reading timestamp",
+			"device_id", reading.GetDeviceId(),
+			"timestamp", reading.GetTimestamp(),
+			"reason", reason,
+		)
+		reading.Timestamp = now.Unix()
+	case TimestampPolicyClamp:
+		c.logger.Debug("clamping out-of-bounds reading timestamp",
+			"device_id", reading.GetDeviceId(),
+			"timestamp", reading.GetTimestamp(),
+			"reason", reason,
+		)
+		reading.Timestamp = bound.Unix()
+	case TimestampPolicyReject:
+		c.logger.Warn("quarantining reading with out-of-bounds timestamp",
+			"device_id", reading.GetDeviceId(),
+			"timestamp", reading.GetTimestamp(),
+			"reason", reason,
+		)
+		return reason
+	}
+
+	return ""
+}
+
+// quarantinedReading returns the quarantine row of a reading rejected for
+// reason.
+func quarantinedReading(messageID string, reading *iotv1.SensorReading, reason string) QuarantinedReading {
+	return QuarantinedReading{
+		Timestamp:    time.Unix(reading.GetTimestamp(), 0).UTC(),
+		DeviceID:     reading.GetDeviceId(),
+		Reason:       reason,
+		MessageID:    messageID,
+		Temperature:  reading.GetTemperature(),
+		Humidity:     reading.GetHumidity(),
+		Pressure:     reading.GetPressure(),
+		BatteryLevel: reading.GetBatteryLevel(),
+	}
+}
+
+// saveQuarantined stores the quarantined readings of a message that has no
+// readings to save. It reports whether messageID was processed before.
+func (c *Consumer) saveQuarantined(ctx context.Context, messageID string, rows []QuarantinedReading) (bool, error) {
+	var duplicate bool
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		first, err := markProcessed(tx, c.queueName, messageID)
+		if err != nil {
+			return err
+		}
+		if !first {
+			duplicate = true
+			return nil
+		}
+
+		return quarantine(tx, rows)
+	})
+
+	return duplicate, err
+}
+
+// quarantine stores readings rejected by the timestamp policy.
+func quarantine(tx *gorm.DB, rows []QuarantinedReading) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to quarantine readings: %w", err)
+	}
+
+	return nil
+}
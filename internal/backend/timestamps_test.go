@@ -0,0 +1,147 @@
+package backend
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Timestamp policies", func() {
+	var (
+		logger *slog.Logger
+		db     *gorm.DB
+	)
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError,
+		}))
+
+		db = newTestDB(logger)
+
+		Expect(db.Create(&IoTDevice{DeviceID: "sensor-1", Location: "Lab", LastSeen: time.Now()}).Error).To(Succeed())
+	})
+
+	newConsumer := func(policy, queue string) *Consumer {
+		return &Consumer{
+			logger:     logger,
+			db:         db,
+			metrics:    consumerTestMetrics,
+			queueName:  queue,
+			timestamps: TimestampConfig{Policy: policy, MaxFuture: time.Minute, MaxAge: 24 * time.Hour},
+		}
+	}
+
+	// consume hands a reading taken at timestamp to a consumer with policy
+	// and returns the stored timestamps.
+	consume := func(policy, queue, messageID string, timestamp time.Time) (*fakeAcknowledger, []time.Time) {
+		body, err := proto.Marshal(&iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: timestamp.Unix(), Temperature: 21.5})
+		Expect(err).NotTo(HaveOccurred())
+
+		ack := &fakeAcknowledger{}
+		newConsumer(policy, queue).handleDelivery(context.Background(), amqp.Delivery{Acknowledger: ack, MessageId: messageID, Body: body})
+
+		var stored []time.Time
+		Expect(db.Model(&SensorReading{}).Pluck("timestamp", &stored).Error).To(Succeed())
+		return ack, stored
+	}
+
+	quarantined := func() []QuarantinedReading {
+		var rows []QuarantinedReading
+		Expect(db.Order("id").Find(&rows).Error).To(Succeed())
+		return rows
+	}
+
+	It("should save readings within the bounds unchanged", func() {
+		timestamp := time.Now().Add(-time.Hour).Truncate(time.Second)
+		_, stored := consume(TimestampPolicyReject, "ts-within-test", "", timestamp)
+
+		Expect(stored).To(HaveLen(1))
+		Expect(stored[0]).To(BeTemporally("==", timestamp))
+		Expect(quarantined()).To(BeEmpty())
+	})
+
+	It("should accept out-of-bounds readings by default", func() {
+		timestamp := time.Now().Add(time.Hour).Truncate(time.Second)
+		_, stored := consume("", "ts-accept-test", "", timestamp)
+
+		Expect(stored).To(HaveLen(1))
+		Expect(stored[0]).To(BeTemporally("==", timestamp))
+		Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerOutOfBoundsReadings.WithLabelValues("ts-accept-test", "future", TimestampPolicyAccept))).To(Equal(1.0))
+	})
+
+	It("should correct out-of-bounds readings to the receive time", func() {
+		_, stored := consume(TimestampPolicyCorrect, "ts-correct-test", "", time.Now().Add(-48*time.Hour))
+
+		Expect(stored).To(HaveLen(1))
+		Expect(stored[0]).To(BeTemporally("~", time.Now(), 2*time.Second))
+	})
+
+	DescribeTable("should clamp out-of-bounds readings to the nearest bound",
+		func(offset, bound time.Duration, reason string) {
+			_, stored := consume(TimestampPolicyClamp, "ts-clamp-test", "", time.Now().Add(offset))
+
+			Expect(stored).To(HaveLen(1))
+			Expect(stored[0]).To(BeTemporally("~", time.Now().Add(bound), 2*time.Second))
+			Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerOutOfBoundsReadings.WithLabelValues("ts-clamp-test", reason, TimestampPolicyClamp))).To(BeNumerically(">=", 1))
+		},
+		Entry("future", time.Hour, time.Minute, "future"),
+		Entry("too old", -48*time.Hour, -24*time.Hour, "too_old"),
+	)
+
+	It("should quarantine rejected readings", func() {
+		timestamp := time.Now().Add(time.Hour).Truncate(time.Second)
+		ack, stored := consume(TimestampPolicyReject, "ts-reject-test", "msg-future", timestamp)
+
+		Expect(ack.acks).To(Equal(1))
+		Expect(stored).To(BeEmpty())
+		Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerMessagesTotal.WithLabelValues("ts-reject-test", "rejected"))).To(Equal(1.0))
+
+		rows := quarantined()
+		Expect(rows).To(HaveLen(1))
+		Expect(rows[0].DeviceID).To(Equal("sensor-1"))
+		Expect(rows[0].Timestamp).To(BeTemporally("==", timestamp))
+		Expect(rows[0].Temperature).To(Equal(21.5))
+		Expect(rows[0].Reason).To(Equal("future"))
+		Expect(rows[0].MessageID).To(Equal("msg-future"))
+	})
+
+	It("should quarantine a redelivered rejected reading once", func() {
+		timestamp := time.Now().Add(-48 * time.Hour)
+		consume(TimestampPolicyReject, "ts-redeliver-test", "msg-old", timestamp)
+		ack, _ := consume(TimestampPolicyReject, "ts-redeliver-test", "msg-old", timestamp)
+
+		Expect(ack.acks).To(Equal(1))
+		Expect(quarantined()).To(HaveLen(1))
+		Expect(quarantined()[0].Reason).To(Equal("too_old"))
+	})
+
+	It("should quarantine rejected readings of batches and save the rest", func() {
+		body, err := proto.Marshal(&iotv1.SensorReadingBatch{Readings: []*iotv1.SensorReading{
+			{DeviceId: "sensor-1", Timestamp: time.Now().Unix()},
+			{DeviceId: "sensor-1", Timestamp: time.Now().Add(-48 * time.Hour).Unix()},
+		}})
+		Expect(err).NotTo(HaveOccurred())
+
+		newConsumer(TimestampPolicyReject, "ts-batch-test").handleDelivery(context.Background(), amqp.Delivery{
+			Acknowledger: &fakeAcknowledger{},
+			Type:         iotv1.MessageTypeSensorReadingBatch,
+			Body:         body,
+		})
+
+		var count int64
+		Expect(db.Model(&SensorReading{}).Count(&count).Error).To(Succeed())
+		Expect(count).To(Equal(int64(1)))
+		Expect(quarantined()).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,57 @@
+package backend
+
+import (
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// readingUnits are the units a message declares for its readings, see
+// iotv1.HeaderTemperatureUnit and iotv1.HeaderPressureUnit.
+type readingUnits struct {
+	temperature string
+	pressure    string
+}
+
+// unitsOf returns the units declared by the headers of delivery, defaulting
+// to the canonical ones. Unknown units are an error, since guessing would
+// store wrong values.
+func unitsOf(delivery amqp.Delivery) (readingUnits, error) {
+	units := readingUnits{
+		temperature: iotv1.TemperatureUnitCelsius,
+		pressure:    iotv1.PressureUnitHectopascal,
+	}
+
+	if unit, ok := delivery.Headers[iotv1.HeaderTemperatureUnit]; ok {
+		switch unit {
+		case iotv1.TemperatureUnitCelsius, iotv1.TemperatureUnitFahrenheit:
+			units.temperature = unit.(string)
+		default:
+			return readingUnits{}, fmt.Errorf("unknown temperature unit %v", unit)
+		}
+	}
+
+	if unit, ok := delivery.Headers[iotv1.HeaderPressureUnit]; ok {
+		switch unit {
+		case iotv1.PressureUnitHectopascal, iotv1.PressureUnitInchesOfMercury:
+			units.pressure = unit.(string)
+		default:
+			return readingUnits{}, fmt.Errorf("unknown pressure unit %v", unit)
+		}
+	}
+
+	return units, nil
+}
+
+// normalize converts reading from units to the canonical units in place.
+func (u readingUnits) normalize(reading *iotv1.SensorReading) {
+	if u.temperature == iotv1.TemperatureUnitFahrenheit {
+		reading.Temperature = iotv1.FahrenheitToCelsius(reading.GetTemperature())
+	}
+
+	if u.pressure == iotv1.PressureUnitInchesOfMercury {
+		reading.Pressure = iotv1.InchesOfMercuryToHectopascals(reading.GetPressure())
+	}
+}
@@ -0,0 +1,137 @@
+package backend
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/protobuf/proto"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Unit normalization", func() {
+	imperial := amqp.Table{
+		iotv1.HeaderTemperatureUnit: iotv1.TemperatureUnitFahrenheit,
+		iotv1.HeaderPressureUnit:    iotv1.PressureUnitInchesOfMercury,
+	}
+
+	DescribeTable("should convert temperatures to Celsius",
+		func(fahrenheit, celsius float64) {
+			reading := &iotv1.SensorReading{Temperature: fahrenheit}
+			readingUnits{temperature: iotv1.TemperatureUnitFahrenheit}.normalize(reading)
+			Expect(reading.GetTemperature()).To(BeNumerically("~", celsius, 1e-9))
+		},
+		Entry("freezing point", 32.0, 0.0),
+		Entry("boiling point", 212.0, 100.0),
+		Entry("body temperature", 98.6, 37.0),
+		Entry("where the scales meet", -40.0, -40.0),
+		Entry("room temperature", 68.0, 20.0),
+		Entry("sensor maximum", 185.0, 85.0),
+	)
+
+	DescribeTable("should convert pressures to hectopascals",
+		func(inHg, hPa, tolerance float64) {
+			reading := &iotv1.SensorReading{Pressure: inHg}
+			readingUnits{pressure: iotv1.PressureUnitInchesOfMercury}.normalize(reading)
+			Expect(reading.GetPressure()).To(BeNumerically("~", hPa, tolerance))
+		},
+		Entry("one inch of mercury", 1.0, 33.8638866667, 1e-9),
+		Entry("standard atmosphere", 29.9212553, 1013.25, 1e-5),
+		Entry("weather report value", 29.92, 1013.2074891, 1e-6),
+		Entry("zero", 0.0, 0.0, 0.0),
+	)
+
+	It("should round-trip readings without losing precision", func() {
+		reading := &iotv1.SensorReading{Temperature: 21.37, Pressure: 1009.82}
+		converted := &iotv1.SensorReading{
+			Temperature: iotv1.CelsiusToFahrenheit(reading.GetTemperature()),
+			Pressure:    iotv1.HectopascalsToInchesOfMercury(reading.GetPressure()),
+		}
+
+		readingUnits{temperature: iotv1.TemperatureUnitFahrenheit, pressure: iotv1.PressureUnitInchesOfMercury}.normalize(converted)
+
+		Expect(converted.GetTemperature()).To(BeNumerically("~", 21.37, 1e-12))
+		Expect(converted.GetPressure()).To(BeNumerically("~", 1009.82, 1e-9))
+	})
+
+	It("should leave canonical readings unchanged", func() {
+		units, err := unitsOf(amqp.Delivery{})
+		Expect(err).NotTo(HaveOccurred())
+
+		reading := &iotv1.SensorReading{Temperature: 21.5, Pressure: 1013.25}
+		units.normalize(reading)
+		Expect(reading.GetTemperature()).To(Equal(21.5))
+		Expect(reading.GetPressure()).To(Equal(1013.25))
+	})
+
+	It("should reject unknown units", func() {
+		_, err := unitsOf(amqp.Delivery{Headers: amqp.Table{iotv1.HeaderTemperatureUnit: "kelvin"}})
+		Expect(err).To(MatchError("unknown temperature unit kelvin"))
+
+		_, err = unitsOf(amqp.Delivery{Headers: amqp.Table{iotv1.HeaderPressureUnit: "psi"}})
+		Expect(err).To(MatchError("unknown pressure unit psi"))
+	})
+
+	Describe("consumer", func() {
+		var c *Consumer
+
+		BeforeEach(func() {
+			logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError + 1,
+			}))
+
+			db := newTestDB(logger)
+			Expect(db.Create(&IoTDevice{DeviceID: "sensor-1", Location: "Lab", LastSeen: time.Now()}).Error).To(Succeed())
+
+			c = &Consumer{
+				logger:    logger,
+				db:        db,
+				metrics:   consumerTestMetrics,
+				queueName: "units-test",
+			}
+		})
+
+		It("should save readings declared in imperial units in canonical units", func() {
+			body, err := proto.Marshal(&iotv1.SensorReadingBatch{Readings: []*iotv1.SensorReading{
+				{DeviceId: "sensor-1", Timestamp: time.Now().Unix(), Temperature: 68, Pressure: 29.92},
+			}})
+			Expect(err).NotTo(HaveOccurred())
+
+			c.handleDelivery(context.Background(), amqp.Delivery{
+				Acknowledger: &fakeAcknowledger{},
+				Type:         iotv1.MessageTypeSensorReadingBatch,
+				Headers:      imperial,
+				Body:         body,
+			})
+
+			var saved SensorReading
+			Expect(c.db.First(&saved).Error).To(Succeed())
+			Expect(saved.Temperature).To(BeNumerically("~", 20, 1e-9))
+			Expect(saved.Pressure).To(BeNumerically("~", 1013.2074892, 1e-6))
+		})
+
+		It("should acknowledge messages in unknown units without saving them", func() {
+			body, err := proto.Marshal(&iotv1.SensorReading{DeviceId: "sensor-1", Timestamp: time.Now().Unix()})
+			Expect(err).NotTo(HaveOccurred())
+
+			ack := &fakeAcknowledger{}
+			c.handleDelivery(context.Background(), amqp.Delivery{
+				Acknowledger: ack,
+				Headers:      amqp.Table{iotv1.HeaderTemperatureUnit: "kelvin"},
+				Body:         body,
+			})
+
+			Expect(ack.acks).To(Equal(1))
+			var count int64
+			Expect(c.db.Model(&SensorReading{}).Count(&count).Error).To(Succeed())
+			Expect(count).To(BeZero())
+			Expect(testutil.ToFloat64(consumerTestMetrics.ConsumerErrors.WithLabelValues("units-test", "unit_error"))).To(Equal(1.0))
+		})
+	})
+})
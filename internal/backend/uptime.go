@@ -0,0 +1,357 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+const (
+	// defaultUptimeInterval is the time between component health checks when
+	// not configured.
+	defaultUptimeInterval = time.Minute
+	// uptimeCheckTimeout bounds a single component check.
+	uptimeCheckTimeout = 5 * time.Second
+	// maxUptimeDays is how many days of uptime history are kept and can be
+	// requested from GetStatusSummary; it is also the default.
+	maxUptimeDays = 90
+	// ingestionStaleAfter is how long without new readings the ingestion
+	// component is reported down.
+	ingestionStaleAfter = 5 * time.Minute
+	// activeDeviceWindow is how recently a device must have been seen to
+	// count as active in GetStatusSummary.
+	activeDeviceWindow = 15 * time.Minute
+	// ingestionRateWindow is the window the ingestion rate of GetStatusSummary
+	// is averaged over.
+	ingestionRateWindow = 5 * time.Minute
+)
+
+// Components checked by the uptime recorder, in the order GetStatusSummary
+// reports them.
+const (
+	componentDatabase     = "database"
+	componentMessageQueue = "message_queue"
+	componentIngestion    = "ingestion"
+)
+
+// uptimeComponents orders the components of GetStatusSummary.
+var uptimeComponents = []string{componentDatabase, componentMessageQueue, componentIngestion}
+
+// ComponentCheck checks the health of one component. Check returns nil when
+// the component is healthy.
+type ComponentCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// uptimeKey identifies a ComponentUptime row.
+type uptimeKey struct {
+	component string
+	day       time.Time
+}
+
+// uptimeCount holds checks not yet written to a ComponentUptime row.
+type uptimeCount struct {
+	lastCheckedAt time.Time
+	checks        int64
+	upChecks      int64
+	lastUp        bool
+}
+
+// UptimeRecorder periodically checks the backend's components and counts the
+// results per component and day in the database, for the uptime history of
+// the status page. Results that cannot be written, such as while the database
+// is down, are kept and written with the next check, so outages of the
+// database are recorded as well.
+type UptimeRecorder struct {
+	logger   *slog.Logger
+	db       *gorm.DB
+	done     chan struct{}
+	cancel   context.CancelFunc
+	pending  map[uptimeKey]*uptimeCount
+	checks   []ComponentCheck
+	interval time.Duration
+}
+
+// UptimeRecorderConfig holds the configuration for the UptimeRecorder.
+type UptimeRecorderConfig struct {
+	Logger   *slog.Logger
+	DB       *gorm.DB
+	Checks   []ComponentCheck
+	Interval time.Duration // Time between checks (optional, default 1 minute)
+}
+
+// NewUptimeRecorder creates a new UptimeRecorder instance.
+func NewUptimeRecorder(cfg *UptimeRecorderConfig) (*UptimeRecorder, error) {
+	if cfg == nil {
+		return nil, errors.New("uptime recorder config cannot be nil")
+	}
+
+	if cfg.Logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+
+	if cfg.DB == nil {
+		return nil, errors.New("database cannot be nil")
+	}
+
+	if len(cfg.Checks) == 0 {
+		return nil, errors.New("uptime recorder needs at least one check")
+	}
+
+	if cfg.Interval < 0 {
+		return nil, errors.New("uptime interval cannot be negative")
+	}
+
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = defaultUptimeInterval
+	}
+
+	return &UptimeRecorder{
+		logger:   cfg.Logger,
+		db:       cfg.DB,
+		done:     make(chan struct{}),
+		pending:  make(map[uptimeKey]*uptimeCount),
+		checks:   cfg.Checks,
+		interval: interval,
+	}, nil
+}
+
+// Start checks the components immediately and then on every interval until
+// Stop is called or ctx is canceled.
+func (u *UptimeRecorder) Start(ctx context.Context) {
+	ctx, u.cancel = context.WithCancel(ctx)
+
+	u.logger.Info("starting uptime recorder", "interval", u.interval, "components", len(u.checks))
+
+	go func() {
+		defer close(u.done)
+
+		ticker := time.NewTicker(u.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := u.RunOnce(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				u.logger.Warn("failed to record component uptime, retrying with the next check", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the recorder and waits for a running check to finish.
+func (u *UptimeRecorder) Stop() {
+	if u.cancel == nil {
+		return
+	}
+
+	u.cancel()
+	<-u.done
+
+	u.logger.Info("uptime recorder stopped")
+}
+
+// RunOnce checks every component at now, writes the results together with
+// any left over from earlier runs, and deletes history older than
+// maxUptimeDays.
+func (u *UptimeRecorder) RunOnce(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+	day := now.Truncate(24 * time.Hour)
+
+	for _, check := range u.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, uptimeCheckTimeout)
+		err := check.Check(checkCtx)
+		cancel()
+		if ctx.Err() != nil {
+			// Checks interrupted by shutdown say nothing about the component
+			return ctx.Err()
+		}
+		if err != nil {
+			u.logger.Warn("component check failed", "component", check.Name, "error", err)
+		}
+
+		key := uptimeKey{component: check.Name, day: day}
+		count := u.pending[key]
+		if count == nil {
+			count = &uptimeCount{}
+			u.pending[key] = count
+		}
+		count.checks++
+		if err == nil {
+			count.upChecks++
+		}
+		count.lastUp = err == nil
+		count.lastCheckedAt = now
+	}
+
+	for key, count := range u.pending {
+		if err := u.write(ctx, key, count); err != nil {
+			return err
+		}
+		delete(u.pending, key)
+	}
+
+	cutoff := day.AddDate(0, 0, -maxUptimeDays)
+	if err := u.db.WithContext(ctx).Where("day < ?", cutoff).Delete(&ComponentUptime{}).Error; err != nil {
+		return fmt.Errorf("failed to delete old uptime history: %w", err)
+	}
+
+	return nil
+}
+
+// write adds count to the row of key.
+func (u *UptimeRecorder) write(ctx context.Context, key uptimeKey, count *uptimeCount) error {
+	row := ComponentUptime{
+		Day:           key.day,
+		LastCheckedAt: count.lastCheckedAt,
+		Component:     key.component,
+		Checks:        count.checks,
+		UpChecks:      count.upChecks,
+		LastUp:        count.lastUp,
+	}
+
+	err := u.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "component"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"checks":          gorm.Expr("component_uptime.checks + ?", count.checks),
+			"up_checks":       gorm.Expr("component_uptime.up_checks + ?", count.upChecks),
+			"last_checked_at": count.lastCheckedAt,
+			"last_up":         count.lastUp,
+		}),
+	}).Create(&row).Error
+	if err != nil {
+		return fmt.Errorf("failed to record uptime of %s: %w", key.component, err)
+	}
+
+	return nil
+}
+
+// ingestionCheck reports the ingestion component down when no reading was
+// stored within ingestionStaleAfter.
+func ingestionCheck(db *gorm.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var latest []time.Time
+		if err := db.WithContext(ctx).Model(&SensorReading{}).
+			Where("timestamp >= ?", time.Now().Add(-ingestionStaleAfter)).
+			Limit(1).Pluck("timestamp", &latest).Error; err != nil {
+			return err
+		}
+		if len(latest) == 0 {
+			return fmt.Errorf("no readings stored in the last %s", ingestionStaleAfter)
+		}
+		return nil
+	}
+}
+
+// GetStatusSummary summarizes the health of the fleet and the backend for a
+// status page: device counts, the ingestion rate and the uptime history of the
+// components checked by the uptime recorder.
+func (s *IoTServiceImpl) GetStatusSummary(ctx context.Context, req *iotv1.GetStatusSummaryRequest) (resp *iotv1.GetStatusSummaryResponse, err error) {
+	done := s.trackRequest("GetStatusSummary")
+	defer func() { done(err) }()
+
+	days := int(req.GetDays())
+	if days == 0 {
+		days = maxUptimeDays
+	}
+	if days < 1 || days > maxUptimeDays {
+		return nil, iotv1.NewError(codes.InvalidArgument, iotv1.ReasonInvalidArgument, "invalid status summary request", nil, iotv1.FieldViolation{
+			Field:       "days",
+			Description: fmt.Sprintf("must be between 1 and %d", maxUptimeDays),
+		})
+	}
+
+	now := time.Now().UTC()
+	db := s.db.WithContext(ctx)
+	resp = &iotv1.GetStatusSummaryResponse{}
+
+	if err := db.Model(&IoTDevice{}).Count(&resp.DeviceCount).Error; err != nil {
+		s.logger.Error("failed to count devices", "error", err)
+		return nil, databaseError("failed to count devices")
+	}
+	if err := db.Model(&IoTDevice{}).Where("last_seen >= ?", now.Add(-activeDeviceWindow)).Count(&resp.ActiveDeviceCount).Error; err != nil {
+		s.logger.Error("failed to count active devices", "error", err)
+		return nil, databaseError("failed to count active devices")
+	}
+
+	var readings int64
+	if err := db.Model(&SensorReading{}).Where("timestamp >= ?", now.Add(-ingestionRateWindow)).Count(&readings).Error; err != nil {
+		s.logger.Error("failed to count recent readings", "error", err)
+		return nil, databaseError("failed to count recent readings")
+	}
+	resp.ReadingsPerMinute = float64(readings) / ingestionRateWindow.Minutes()
+
+	var rows []ComponentUptime
+	start := now.Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+	if err := db.Where("day >= ?", start).Order("day").Find(&rows).Error; err != nil {
+		s.logger.Error("failed to load uptime history", "error", err)
+		return nil, databaseError("failed to load uptime history")
+	}
+
+	resp.Components = componentStatuses(rows)
+	return resp, nil
+}
+
+// componentStatuses groups uptime rows, ordered by day, into the status of
+// each component.
+func componentStatuses(rows []ComponentUptime) []*iotv1.ComponentStatus {
+	byName := make(map[string]*iotv1.ComponentStatus)
+	var names []string
+	checks := make(map[string]int64)
+	upChecks := make(map[string]int64)
+
+	for _, row := range rows {
+		status := byName[row.Component]
+		if status == nil {
+			status = &iotv1.ComponentStatus{Name: row.Component}
+			byName[row.Component] = status
+			names = append(names, row.Component)
+		}
+
+		status.History = append(status.History, &iotv1.DailyUptime{
+			Day:      row.Day.Unix(),
+			Checks:   row.Checks,
+			UpChecks: row.UpChecks,
+		})
+		if row.LastCheckedAt.Unix() >= status.GetCheckedAt() {
+			status.CheckedAt = row.LastCheckedAt.Unix()
+			status.Up = row.LastUp
+		}
+
+		checks[row.Component] += row.Checks
+		upChecks[row.Component] += row.UpChecks
+	}
+
+	statuses := make([]*iotv1.ComponentStatus, 0, len(names))
+	// Known components first, in their fixed order; others as found
+	for _, name := range slices.Concat(uptimeComponents, names) {
+		status := byName[name]
+		if status == nil {
+			continue
+		}
+		delete(byName, name)
+
+		if checks[name] > 0 {
+			status.UptimePercent = math.Round(float64(upChecks[name])/float64(checks[name])*100000) / 1000
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
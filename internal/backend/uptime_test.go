@@ -0,0 +1,179 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Component uptime", func() {
+	var (
+		ctx      context.Context
+		logger   *slog.Logger
+		db       *gorm.DB
+		recorder *UptimeRecorder
+		dbErr    error
+		mqErr    error
+		day      time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelError,
+		}))
+
+		db = newTestDB(logger)
+
+		dbErr, mqErr = nil, nil
+		var err error
+		recorder, err = NewUptimeRecorder(&UptimeRecorderConfig{
+			Logger: logger,
+			DB:     db,
+			Checks: []ComponentCheck{
+				{Name: componentDatabase, Check: func(context.Context) error { return dbErr }},
+				{Name: componentMessageQueue, Check: func(context.Context) error { return mqErr }},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		day = time.Now().UTC().Truncate(24 * time.Hour)
+	})
+
+	rows := func() map[string]ComponentUptime {
+		var found []ComponentUptime
+		Expect(db.Find(&found).Error).To(Succeed())
+		byName := make(map[string]ComponentUptime)
+		for _, row := range found {
+			byName[row.Component+"@"+row.Day.UTC().Format(time.DateOnly)] = row
+		}
+		return byName
+	}
+
+	Describe("NewUptimeRecorder", func() {
+		It("should require checks", func() {
+			_, err := NewUptimeRecorder(&UptimeRecorderConfig{Logger: logger, DB: db})
+			Expect(err).To(MatchError(ContainSubstring("at least one check")))
+		})
+
+		It("should reject a negative interval", func() {
+			_, err := NewUptimeRecorder(&UptimeRecorderConfig{
+				Logger:   logger,
+				DB:       db,
+				Checks:   []ComponentCheck{{Name: componentDatabase, Check: func(context.Context) error { return nil }}},
+				Interval: -time.Second,
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("RunOnce", func() {
+		It("should count the checks of each component per day", func() {
+			now := day.Add(10 * time.Hour)
+			Expect(recorder.RunOnce(ctx, now)).To(Succeed())
+			mqErr = errors.New("connection refused")
+			Expect(recorder.RunOnce(ctx, now.Add(time.Minute))).To(Succeed())
+
+			found := rows()
+			database := found[componentDatabase+"@"+day.Format(time.DateOnly)]
+			Expect(database.Checks).To(Equal(int64(2)))
+			Expect(database.UpChecks).To(Equal(int64(2)))
+			Expect(database.LastUp).To(BeTrue())
+
+			mq := found[componentMessageQueue+"@"+day.Format(time.DateOnly)]
+			Expect(mq.Checks).To(Equal(int64(2)))
+			Expect(mq.UpChecks).To(Equal(int64(1)))
+			Expect(mq.LastUp).To(BeFalse())
+		})
+
+		It("should start a new row on the next day", func() {
+			Expect(recorder.RunOnce(ctx, day.Add(23*time.Hour))).To(Succeed())
+			Expect(recorder.RunOnce(ctx, day.Add(25*time.Hour))).To(Succeed())
+
+			found := rows()
+			Expect(found).To(HaveLen(4))
+			Expect(found[componentDatabase+"@"+day.AddDate(0, 0, 1).Format(time.DateOnly)].Checks).To(Equal(int64(1)))
+		})
+
+		It("should delete history older than the retention", func() {
+			old := day.AddDate(0, 0, -maxUptimeDays-1)
+			Expect(db.Create(&ComponentUptime{Component: componentDatabase, Day: old, LastCheckedAt: old, Checks: 1}).Error).To(Succeed())
+
+			Expect(recorder.RunOnce(ctx, day)).To(Succeed())
+
+			Expect(rows()).NotTo(HaveKey(componentDatabase + "@" + old.Format(time.DateOnly)))
+		})
+
+		It("should keep results that could not be written for the next run", func() {
+			sqlDB, err := db.DB()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sqlDB.Close()).To(Succeed())
+
+			Expect(recorder.RunOnce(ctx, day)).NotTo(Succeed())
+			Expect(recorder.pending).To(HaveLen(2))
+		})
+	})
+
+	Describe("GetStatusSummary", func() {
+		var service *IoTServiceImpl
+
+		BeforeEach(func() {
+			service = &IoTServiceImpl{logger: logger, db: db}
+			now := time.Now().UTC()
+
+			Expect(db.Create(&[]IoTDevice{
+				{DeviceID: "active", LastSeen: now.Add(-time.Minute)},
+				{DeviceID: "idle", LastSeen: now.Add(-time.Hour)},
+			}).Error).To(Succeed())
+			Expect(db.Create(&[]SensorReading{
+				{DeviceID: "active", Timestamp: now.Add(-time.Minute)},
+				{DeviceID: "active", Timestamp: now.Add(-2 * time.Minute)},
+				{DeviceID: "idle", Timestamp: now.Add(-time.Hour)},
+			}).Error).To(Succeed())
+
+			Expect(db.Create(&[]ComponentUptime{
+				{Component: componentMessageQueue, Day: day.AddDate(0, 0, -1), LastCheckedAt: day.Add(-time.Minute), Checks: 4, UpChecks: 3, LastUp: true},
+				{Component: componentMessageQueue, Day: day, LastCheckedAt: day.Add(time.Minute), Checks: 4, UpChecks: 4, LastUp: false},
+				{Component: componentDatabase, Day: day, LastCheckedAt: day.Add(time.Minute), Checks: 2, UpChecks: 2, LastUp: true},
+				{Component: componentDatabase, Day: day.AddDate(0, 0, -10), LastCheckedAt: day.AddDate(0, 0, -10), Checks: 2, UpChecks: 0},
+			}).Error).To(Succeed())
+		})
+
+		It("should summarize the fleet and the components", func() {
+			resp, err := service.GetStatusSummary(ctx, &iotv1.GetStatusSummaryRequest{Days: 7})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resp.GetDeviceCount()).To(Equal(int64(2)))
+			Expect(resp.GetActiveDeviceCount()).To(Equal(int64(1)))
+			Expect(resp.GetReadingsPerMinute()).To(BeNumerically("~", 2/ingestionRateWindow.Minutes(), 1e-9))
+
+			components := resp.GetComponents()
+			Expect(components).To(HaveLen(2))
+			Expect(components[0].GetName()).To(Equal(componentDatabase))
+			Expect(components[0].GetUptimePercent()).To(Equal(100.0))
+			Expect(components[0].GetHistory()).To(HaveLen(1))
+
+			Expect(components[1].GetName()).To(Equal(componentMessageQueue))
+			Expect(components[1].GetUp()).To(BeFalse())
+			Expect(components[1].GetCheckedAt()).To(Equal(day.Add(time.Minute).Unix()))
+			Expect(components[1].GetUptimePercent()).To(Equal(87.5))
+			Expect(components[1].GetHistory()).To(HaveLen(2))
+			Expect(components[1].GetHistory()[0].GetDay()).To(Equal(day.AddDate(0, 0, -1).Unix()))
+		})
+
+		It("should reject too long histories", func() {
+			_, err := service.GetStatusSummary(ctx, &iotv1.GetStatusSummaryRequest{Days: maxUptimeDays + 1})
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		})
+	})
+})
@@ -0,0 +1,87 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"procodus.dev/demo-app/pkg/mq"
+)
+
+// validateTimeout bounds each connectivity check of Validate.
+const validateTimeout = 5 * time.Second
+
+// Validate checks that the database and RabbitMQ are reachable with the
+// configured settings, without migrating or consuming anything. Both checks
+// run concurrently with short timeouts, and all failures are reported at once.
+// For SQLite, the database file is created if it does not exist.
+func (s *Server) Validate(ctx context.Context) error {
+	type check struct {
+		name  string
+		check func(ctx context.Context) error
+	}
+	checks := []check{
+		{name: "database", check: s.pingDB},
+	}
+	// The in-process broker is always reachable
+	if s.config.MQBroker == nil {
+		checks = append(checks, check{name: "rabbitmq", check: func(ctx context.Context) error { return mq.Ping(ctx, s.config.RabbitMQURL) }})
+	}
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, validateTimeout)
+			defer cancel()
+
+			if err := c.check(checkCtx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", c.name, err)
+				return
+			}
+			s.logger.Info("connectivity check passed", "check", c.name)
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// pingDB opens the configured database and pings it.
+func (s *Server) pingDB(ctx context.Context) error {
+	dialector, err := newDialector(s.dbConfig())
+	if err != nil {
+		return err
+	}
+
+	// The automatic ping of gorm.Open ignores ctx, so ping explicitly
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:               logger.Default.LogMode(logger.Silent),
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	defer func() { _ = sqlDB.Close() }()
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping: %w", err)
+	}
+
+	return nil
+}
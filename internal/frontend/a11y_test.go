@@ -0,0 +1,298 @@
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// a11yViolations checks rendered HTML against the WCAG 2 rules reported by
+// pa11y that can be decided from the markup alone. Fragments are checked
+// without the document-level rules.
+func a11yViolations(markup string, fragment bool) []string {
+	doc, err := html.Parse(strings.NewReader(markup))
+	Expect(err).NotTo(HaveOccurred())
+
+	var (
+		violations []string
+		ids        = map[string]int{}
+		references []string
+		labelFor   = map[string]bool{}
+		headings   []int
+		title      string
+		htmlLang   string
+	)
+	report := func(n *html.Node, format string, args ...any) {
+		violations = append(violations, fmt.Sprintf("<%s>: ", n.Data)+fmt.Sprintf(format, args...))
+	}
+
+	var elements []*html.Node
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode {
+			continue
+		}
+		elements = append(elements, n)
+
+		if id := attr(n, "id"); id != "" {
+			ids[id]++
+		}
+		for _, name := range []string{"aria-labelledby", "aria-describedby"} {
+			references = append(references, strings.Fields(attr(n, name))...)
+		}
+		switch n.DataAtom {
+		case atom.Html:
+			htmlLang = attr(n, "lang")
+		case atom.Title:
+			if n.Parent != nil && n.Parent.DataAtom == atom.Head {
+				title = textContent(n)
+			}
+		case atom.Label:
+			if f := attr(n, "for"); f != "" {
+				labelFor[f] = true
+			}
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			headings = append(headings, int(n.Data[1]-'0'))
+		}
+	}
+
+	if !fragment {
+		if htmlLang == "" {
+			violations = append(violations, "<html>: missing lang attribute")
+		}
+		if strings.TrimSpace(title) == "" {
+			violations = append(violations, "<title>: missing or empty")
+		}
+	}
+
+	for id, count := range ids {
+		if count > 1 {
+			violations = append(violations, fmt.Sprintf("id %q is used %d times", id, count))
+		}
+	}
+	for _, ref := range references {
+		if ids[ref] == 0 {
+			violations = append(violations, fmt.Sprintf("ARIA reference to missing id %q", ref))
+		}
+	}
+	for i := 1; i < len(headings); i++ {
+		if headings[i] > headings[i-1]+1 {
+			violations = append(violations, fmt.Sprintf("heading level skips from h%d to h%d", headings[i-1], headings[i]))
+		}
+	}
+
+	for _, n := range elements {
+		for _, name := range []string{"aria-pressed", "aria-invalid", "aria-hidden"} {
+			if v, ok := attrOK(n, name); ok && !slices.Contains([]string{"true", "false", "mixed"}, v) {
+				report(n, "invalid %s value %q", name, v)
+			}
+		}
+		if v, ok := attrOK(n, "aria-sort"); ok && !slices.Contains([]string{"ascending", "descending", "none", "other"}, v) {
+			report(n, "invalid aria-sort value %q", v)
+		}
+
+		switch n.DataAtom {
+		case atom.Img:
+			if _, ok := attrOK(n, "alt"); !ok {
+				report(n, "missing alt text")
+			}
+		case atom.Svg:
+			if attr(n, "role") == "img" && !hasAccessibleName(n) && !hasChild(n, "title") {
+				report(n, "image has no accessible name")
+			}
+		case atom.Input, atom.Select, atom.Textarea:
+			switch attr(n, "type") {
+			case "hidden", "submit", "button", "image", "reset":
+				continue
+			}
+			if !hasAccessibleName(n) && !labelFor[attr(n, "id")] && !hasAncestor(n, atom.Label) {
+				report(n, "form control %q has no label", attr(n, "name"))
+			}
+		case atom.A, atom.Button:
+			if _, ok := attrOK(n, "href"); n.DataAtom == atom.A && !ok {
+				continue
+			}
+			if !hasAccessibleName(n) && strings.TrimSpace(textContent(n)) == "" {
+				report(n, "no accessible name")
+			}
+		case atom.Th:
+			if strings.TrimSpace(textContent(n)) == "" {
+				report(n, "empty table header")
+			}
+		}
+	}
+
+	return violations
+}
+
+func attrOK(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func attr(n *html.Node, name string) string {
+	v, _ := attrOK(n, name)
+	return v
+}
+
+func hasAccessibleName(n *html.Node) bool {
+	return attr(n, "aria-label") != "" || attr(n, "aria-labelledby") != "" || attr(n, "title") != ""
+}
+
+func hasChild(n *html.Node, tag string) bool {
+	for c := range n.ChildNodes() {
+		if c.Type == html.ElementNode && c.Data == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAncestor(n *html.Node, a atom.Atom) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.DataAtom == a {
+			return true
+		}
+	}
+	return false
+}
+
+// textContent returns the text of n as read by screen readers, skipping
+// subtrees hidden with aria-hidden.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			return
+		}
+		if n.Type == html.ElementNode && attr(n, "aria-hidden") == "true" {
+			return
+		}
+		for c := range n.ChildNodes() {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+var _ = Describe("Accessibility", Label("a11y"), func() {
+	dev := &iotv1.IoTDevice{
+		DeviceId: "sensor-1", Location: "Berlin", MacAddress: "00:11:22:33:44:55",
+		IpAddress: "10.0.0.1", Firmware: "1.0.0", Timestamp: 1768478400,
+		Latitude: 52.52, Longitude: 13.40,
+	}
+	page := readingsPage{
+		DeviceID:      "sensor-1",
+		Rows:          []readingRow{{Timestamp: 1768478400, Temperature: 21.5, Humidity: 40, Pressure: 1013, BatteryLevel: 80}},
+		Prefs:         defaultPreferences(),
+		PageToken:     "token-1",
+		NextPageToken: "token-2",
+		PageSize:      defaultReadingsPageSize,
+		Total:         120,
+	}
+	locations := []*iotv1.DeviceLocation{
+		{Latitude: 52.52, Longitude: 13.40, IpAddress: "10.0.0.1", Timestamp: 1768478400},
+		{Latitude: 52.53, Longitude: 13.42, IpAddress: "10.0.0.2", Timestamp: 1768482000},
+	}
+	invalidRule := newAlertRuleForm()
+	invalidRule.Errors = map[string]string{"threshold": "must be a number", "silence_end": "must be a date and time"}
+
+	pages := map[string]templ.Component{
+		"index":   index(),
+		"offline": offline(),
+		"devices": devices([]*iotv1.IoTDevice{dev}),
+		"device":  device(dev, &iotv1.BatteryProjection{BatteryLevel: 80, DrainPerDay: 1.5}, page),
+		"compare": compare(comparison{
+			DeviceIDs: []string{"sensor-1"},
+			Window:    compareWindows[0],
+			Charts:    []comparisonChart{{Title: "Humidity (%)", Lines: []chartLine{{DeviceID: "sensor-1", Color: "#3498db", Points: "0,0 10,10"}}, Max: 50}},
+			Stats:     []deviceStats{{DeviceID: "sensor-1", Color: "#3498db", Count: 2, Metrics: []metricStats{{Name: "Humidity"}}}},
+		}),
+		"battery report": batteryReportPage(batteryReport{Days: 7, Devices: []*iotv1.LowBatteryDevice{
+			{Device: dev, BatteryProjection: &iotv1.BatteryProjection{BatteryLevel: 5, DrainPerDay: 2}},
+		}}),
+		"status": statusPageView(newStatusPage(&iotv1.GetStatusSummaryResponse{
+			DeviceCount: 1,
+			Components:  []*iotv1.ComponentStatus{{Name: "database", Up: true, UptimePercent: 100}},
+		}, time.Unix(1768478400, 0))),
+		"trash":           trash([]*iotv1.IoTDevice{dev}),
+		"alert rules":     alertRules([]*iotv1.AlertRule{{Id: 1, Name: "Hot room", Metric: "temperature", Operator: "gt", Enabled: true}}),
+		"alert rule form": alertRuleFormPage(invalidRule),
+		"error":           errorPage(404, "Not Found", "Device not found", "abc123"),
+	}
+	fragments := map[string]templ.Component{
+		"devices list":  devicesList([]*iotv1.IoTDevice{dev}),
+		"readings list": readingsList(page),
+		"readings rows": readingsRows(page),
+		"notes": deviceNotes(notesPanel{
+			DeviceID: "sensor-1",
+			Notes:    []*iotv1.DeviceNote{{Id: 1, Author: "Ada", Body: "Replaced battery", CreatedAt: 1768478400, AttachmentUrl: "https://example.com/a.pdf"}},
+			Form:     noteForm{Errors: map[string]string{"author": "cannot be empty"}},
+		}),
+		"locations": deviceLocations(locationPanel{DeviceID: "sensor-1", Locations: locations, Points: projectLocations(locations)}),
+		"quota":     quotaBannerView(quotaBanner{Messages: []string{"API requests: 9 of 10 per minute used"}}),
+		"error":     errorFragment(500, "Internal Server Error", genericErrorMessage, ""),
+	}
+
+	render := func(c templ.Component, lang string) string {
+		ctx := context.WithValue(context.Background(), localeKey{}, locales[negotiateLanguage(lang, "")])
+		var buf bytes.Buffer
+		Expect(c.Render(ctx, &buf)).To(Succeed())
+		return buf.String()
+	}
+
+	for _, lang := range []string{"en", "de"} {
+		for name, c := range pages {
+			It(fmt.Sprintf("should have no violations on the %s page (%s)", name, lang), func() {
+				Expect(a11yViolations(render(c, lang), false)).To(BeEmpty())
+			})
+		}
+		for name, c := range fragments {
+			It(fmt.Sprintf("should have no violations in the %s fragment (%s)", name, lang), func() {
+				Expect(a11yViolations(render(c, lang), true)).To(BeEmpty())
+			})
+		}
+	}
+
+	It("should detect violations", func() {
+		Expect(a11yViolations(`<html><head></head><body>
+			<h1>Title</h1><h3>Skipped</h3>
+			<img src="x.png">
+			<input name="q">
+			<button></button>
+			<a href="/"><span aria-hidden="true">▲</span></a>
+			<table><tr><th></th></tr></table>
+			<p id="dup"></p><p id="dup" aria-describedby="missing"></p>
+			<button aria-pressed="yes">x</button>
+		</body></html>`, false)).To(ConsistOf(
+			"<html>: missing lang attribute",
+			"<title>: missing or empty",
+			"heading level skips from h1 to h3",
+			"<img>: missing alt text",
+			`<input>: form control "q" has no label`,
+			"<button>: no accessible name",
+			"<a>: no accessible name",
+			"<th>: empty table header",
+			`id "dup" is used 2 times`,
+			`ARIA reference to missing id "missing"`,
+			`<button>: invalid aria-pressed value "yes"`,
+		))
+	})
+})
@@ -0,0 +1,306 @@
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// silenceTimeLayout is the format of datetime-local form inputs.
+const silenceTimeLayout = "2006-01-02T15:04"
+
+// alertRuleMetricOptions are the metrics selectable in the alert rule form.
+var alertRuleMetricOptions = []string{"temperature", "humidity", "pressure", "battery_level"}
+
+// alertRuleForm holds the alert rule form values as entered, so invalid
+// submissions can be re-rendered unchanged alongside their errors.
+type alertRuleForm struct {
+	Errors       map[string]string
+	Action       string
+	Title        string
+	Name         string
+	Metric       string
+	Operator     string
+	Threshold    string
+	DeviceID     string
+	SilenceStart string
+	SilenceEnd   string
+	ID           uint64
+	Enabled      bool
+}
+
+// newAlertRuleForm returns the form for creating a rule.
+func newAlertRuleForm() alertRuleForm {
+	return alertRuleForm{
+		Action:   "/admin/alerts",
+		Title:    "New Alert Rule",
+		Metric:   alertRuleMetricOptions[0],
+		Operator: "gt",
+		Enabled:  true,
+	}
+}
+
+// editAlertRuleForm returns the form for editing an existing rule, with silence
+// times in the time zone of ctx.
+func editAlertRuleForm(ctx context.Context, rule *iotv1.AlertRule) alertRuleForm {
+	return alertRuleForm{
+		Action:       fmt.Sprintf("/admin/alerts/%d", rule.GetId()),
+		Title:        "Edit Alert Rule",
+		ID:           rule.GetId(),
+		Name:         rule.GetName(),
+		Metric:       rule.GetMetric(),
+		Operator:     rule.GetOperator(),
+		Threshold:    strconv.FormatFloat(rule.GetThreshold(), 'f', -1, 64),
+		DeviceID:     rule.GetDeviceId(),
+		Enabled:      rule.GetEnabled(),
+		SilenceStart: formatSilenceTime(ctx, rule.GetSilenceStart()),
+		SilenceEnd:   formatSilenceTime(ctx, rule.GetSilenceEnd()),
+	}
+}
+
+// formatSilenceTime formats a Unix timestamp for a datetime-local input in the
+// time zone of ctx.
+func formatSilenceTime(ctx context.Context, ts int64) string {
+	if ts == 0 {
+		return ""
+	}
+
+	return inTimezone(ctx, ts).Format(silenceTimeLayout)
+}
+
+// alertOperatorSymbol returns the comparison symbol for an alert rule operator.
+func alertOperatorSymbol(op string) string {
+	if op == "lt" {
+		return "<"
+	}
+
+	return ">"
+}
+
+// parseAlertRuleForm reads the submitted form into the form model and a proto rule.
+// Parse errors are recorded per field in the form's Errors.
+func parseAlertRuleForm(r *http.Request, form alertRuleForm) (alertRuleForm, *iotv1.AlertRule) {
+	form.Errors = map[string]string{}
+	form.Name = r.PostFormValue("name")
+	form.Metric = r.PostFormValue("metric")
+	form.Operator = r.PostFormValue("operator")
+	form.Threshold = r.PostFormValue("threshold")
+	form.DeviceID = r.PostFormValue("device_id")
+	form.Enabled = r.PostFormValue("enabled") == "on"
+	form.SilenceStart = r.PostFormValue("silence_start")
+	form.SilenceEnd = r.PostFormValue("silence_end")
+
+	rule := &iotv1.AlertRule{
+		Id:       form.ID,
+		Name:     form.Name,
+		Metric:   form.Metric,
+		Operator: form.Operator,
+		DeviceId: form.DeviceID,
+		Enabled:  form.Enabled,
+	}
+
+	threshold, err := strconv.ParseFloat(strings.TrimSpace(form.Threshold), 64)
+	if err != nil {
+		form.Errors["threshold"] = "must be a number"
+	}
+	rule.Threshold = threshold
+
+	loc := timezoneFromContext(r.Context())
+	rule.SilenceStart = parseSilenceTime(form.SilenceStart, loc, "silence_start", form.Errors)
+	rule.SilenceEnd = parseSilenceTime(form.SilenceEnd, loc, "silence_end", form.Errors)
+
+	return form, rule
+}
+
+// parseSilenceTime parses a datetime-local value in loc, 0 when empty.
+func parseSilenceTime(value string, loc *time.Location, field string, errs map[string]string) int64 {
+	if value == "" {
+		return 0
+	}
+
+	t, err := time.ParseInLocation(silenceTimeLayout, value, loc)
+	if err != nil {
+		errs[field] = "must be a date and time"
+		return 0
+	}
+
+	return t.Unix()
+}
+
+// handleAlertRules lists all alert rules.
+func (s *Server) handleAlertRules(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	resp, err := trackedCall(s, ctx, "ListAlertRules", s.grpcClient.ListAlertRules, &iotv1.ListAlertRulesRequest{})
+	if err != nil {
+		s.logger.Error("failed to fetch alert rules", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to fetch alert rules"))
+		return
+	}
+
+	if err := renderAlertRules(r.Context(), w, resp.GetRules(), s.metrics); err != nil {
+		s.logger.Error("failed to render alert rules", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
+	}
+}
+
+// handleNewAlertRule serves the empty alert rule form.
+func (s *Server) handleNewAlertRule(w http.ResponseWriter, r *http.Request) {
+	s.renderAlertRuleForm(w, r, http.StatusOK, newAlertRuleForm())
+}
+
+// handleEditAlertRule serves the form for an existing alert rule.
+func (s *Server) handleEditAlertRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := alertRuleID(r)
+	if !ok {
+		s.renderError(w, r, http.StatusNotFound, "Alert rule not found")
+		return
+	}
+
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	resp, err := trackedCall(s, ctx, "GetAlertRule", s.grpcClient.GetAlertRule, &iotv1.GetAlertRuleRequest{Id: id})
+	if err != nil {
+		s.logger.Error("failed to fetch alert rule", "error", err, "id", id, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to fetch alert rule"))
+		return
+	}
+
+	s.renderAlertRuleForm(w, r, http.StatusOK, editAlertRuleForm(r.Context(), resp.GetRule()))
+}
+
+// handleCreateAlertRule creates an alert rule from the submitted form.
+func (s *Server) handleCreateAlertRule(w http.ResponseWriter, r *http.Request) {
+	form, rule := parseAlertRuleForm(r, newAlertRuleForm())
+	s.saveAlertRule(w, r, form, func(ctx context.Context) error {
+		_, err := trackedCall(s, ctx, "CreateAlertRule", s.grpcClient.CreateAlertRule, &iotv1.CreateAlertRuleRequest{Rule: rule})
+		return err
+	})
+}
+
+// handleUpdateAlertRule updates an alert rule from the submitted form.
+func (s *Server) handleUpdateAlertRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := alertRuleID(r)
+	if !ok {
+		s.renderError(w, r, http.StatusNotFound, "Alert rule not found")
+		return
+	}
+
+	form := newAlertRuleForm()
+	form.ID = id
+	form.Action = fmt.Sprintf("/admin/alerts/%d", id)
+	form.Title = "Edit Alert Rule"
+
+	form, rule := parseAlertRuleForm(r, form)
+	s.saveAlertRule(w, r, form, func(ctx context.Context) error {
+		_, err := trackedCall(s, ctx, "UpdateAlertRule", s.grpcClient.UpdateAlertRule, &iotv1.UpdateAlertRuleRequest{Rule: rule})
+		return err
+	})
+}
+
+// handleDeleteAlertRule deletes an alert rule and returns to the list.
+func (s *Server) handleDeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := alertRuleID(r)
+	if !ok {
+		s.renderError(w, r, http.StatusNotFound, "Alert rule not found")
+		return
+	}
+
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	if _, err := trackedCall(s, ctx, "DeleteAlertRule", s.grpcClient.DeleteAlertRule, &iotv1.DeleteAlertRuleRequest{Id: id}); err != nil {
+		s.logger.Error("failed to delete alert rule", "error", err, "id", id, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to delete alert rule"))
+		return
+	}
+
+	http.Redirect(w, r, "/admin/alerts", http.StatusSeeOther)
+}
+
+// saveAlertRule runs save unless the form has parse errors. Backend field
+// violations are shown next to the form fields; success returns to the list.
+func (s *Server) saveAlertRule(w http.ResponseWriter, r *http.Request, form alertRuleForm, save func(context.Context) error) {
+	if len(form.Errors) > 0 {
+		s.renderAlertRuleForm(w, r, http.StatusBadRequest, form)
+		return
+	}
+
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	if err := save(ctx); err != nil {
+		if violations := iotv1.FieldViolations(err); len(violations) > 0 {
+			for _, v := range violations {
+				form.Errors[v.Field] = v.Description
+			}
+			s.renderAlertRuleForm(w, r, http.StatusBadRequest, form)
+			return
+		}
+
+		s.logger.Error("failed to save alert rule", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to save alert rule"))
+		return
+	}
+
+	http.Redirect(w, r, "/admin/alerts", http.StatusSeeOther)
+}
+
+// renderAlertRuleForm writes the alert rule form with the given status code.
+func (s *Server) renderAlertRuleForm(w http.ResponseWriter, r *http.Request, statusCode int, form alertRuleForm) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := renderAlertRuleFormPage(r.Context(), w, form, s.metrics); err != nil {
+		s.logger.Error("failed to render alert rule form", "error", err, "request_id", requestIDFromContext(r.Context()))
+	}
+}
+
+// alertRuleID parses the {id} path value.
+func alertRuleID(r *http.Request) (uint64, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	return id, err == nil && id > 0
+}
+
+// trackedCall invokes a backend RPC, recording the same client metrics as the
+// hand-written call wrappers.
+func trackedCall[Req, Resp any](
+	s *Server,
+	ctx context.Context,
+	method string,
+	call func(context.Context, Req, ...grpc.CallOption) (Resp, error),
+	req Req,
+) (Resp, error) {
+	if s.metrics == nil {
+		return call(ctx, req)
+	}
+
+	// Track duration
+	timer := prometheus.NewTimer(s.metrics.GRPCClientDuration.WithLabelValues(method))
+	defer timer.ObserveDuration()
+
+	resp, err := call(ctx, req)
+	if err != nil {
+		s.metrics.GRPCClientCalls.WithLabelValues(method, "error").Inc()
+		// Categorize error type
+		if st, ok := status.FromError(err); ok {
+			s.metrics.GRPCClientErrors.WithLabelValues(method, st.Code().String()).Inc()
+		} else {
+			s.metrics.GRPCClientErrors.WithLabelValues(method, "unknown").Inc()
+		}
+		return resp, err
+	}
+
+	s.metrics.GRPCClientCalls.WithLabelValues(method, "success").Inc()
+	return resp, nil
+}
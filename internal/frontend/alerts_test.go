@@ -0,0 +1,176 @@
+package frontend
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// alertRulesClient is an in-memory IoTServiceClient stub for alert rule CRUD.
+type alertRulesClient struct {
+	iotv1.IoTServiceClient
+	rules  map[uint64]*iotv1.AlertRule
+	nextID uint64
+}
+
+func (c *alertRulesClient) ListAlertRules(_ context.Context, _ *iotv1.ListAlertRulesRequest, _ ...grpc.CallOption) (*iotv1.ListAlertRulesResponse, error) {
+	resp := &iotv1.ListAlertRulesResponse{}
+	for _, rule := range c.rules {
+		resp.Rules = append(resp.Rules, rule)
+	}
+	return resp, nil
+}
+
+func (c *alertRulesClient) GetAlertRule(_ context.Context, req *iotv1.GetAlertRuleRequest, _ ...grpc.CallOption) (*iotv1.GetAlertRuleResponse, error) {
+	rule, ok := c.rules[req.GetId()]
+	if !ok {
+		return nil, iotv1.AlertRuleNotFoundError(req.GetId())
+	}
+	return &iotv1.GetAlertRuleResponse{Rule: rule}, nil
+}
+
+func (c *alertRulesClient) CreateAlertRule(_ context.Context, req *iotv1.CreateAlertRuleRequest, _ ...grpc.CallOption) (*iotv1.CreateAlertRuleResponse, error) {
+	if req.GetRule().GetMetric() == "voltage" {
+		return nil, iotv1.NewError(codes.InvalidArgument, iotv1.ReasonInvalidArgument, "invalid alert rule", nil,
+			iotv1.FieldViolation{Field: "metric", Description: "unsupported metric"})
+	}
+	c.nextID++
+	rule := req.GetRule()
+	rule.Id = c.nextID
+	c.rules[rule.GetId()] = rule
+	return &iotv1.CreateAlertRuleResponse{Rule: rule}, nil
+}
+
+func (c *alertRulesClient) UpdateAlertRule(_ context.Context, req *iotv1.UpdateAlertRuleRequest, _ ...grpc.CallOption) (*iotv1.UpdateAlertRuleResponse, error) {
+	if _, ok := c.rules[req.GetRule().GetId()]; !ok {
+		return nil, iotv1.AlertRuleNotFoundError(req.GetRule().GetId())
+	}
+	c.rules[req.GetRule().GetId()] = req.GetRule()
+	return &iotv1.UpdateAlertRuleResponse{Rule: req.GetRule()}, nil
+}
+
+func (c *alertRulesClient) DeleteAlertRule(_ context.Context, req *iotv1.DeleteAlertRuleRequest, _ ...grpc.CallOption) (*iotv1.DeleteAlertRuleResponse, error) {
+	if _, ok := c.rules[req.GetId()]; !ok {
+		return nil, iotv1.AlertRuleNotFoundError(req.GetId())
+	}
+	delete(c.rules, req.GetId())
+	return &iotv1.DeleteAlertRuleResponse{}, nil
+}
+
+var _ = Describe("Alert rule admin", func() {
+	var (
+		client  *alertRulesClient
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		client = &alertRulesClient{rules: map[uint64]*iotv1.AlertRule{}}
+		server := &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: client,
+		}
+		handler = server.setupRoutes()
+	})
+
+	do := func(method, target string, form url.Values) *httptest.ResponseRecorder {
+		var req *http.Request
+		if form != nil {
+			req = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			req = httptest.NewRequest(method, target, nil)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, withCSRFToken(req))
+		return rec
+	}
+
+	validForm := func() url.Values {
+		return url.Values{
+			"name":          {"Hot room"},
+			"metric":        {"temperature"},
+			"operator":      {"gt"},
+			"threshold":     {"30.5"},
+			"enabled":       {"on"},
+			"silence_start": {"2026-01-01T00:00"},
+			"silence_end":   {"2026-01-02T06:30"},
+		}
+	}
+
+	It("should create a rule and list it", func() {
+		rec := do(http.MethodPost, "/admin/alerts", validForm())
+		Expect(rec.Code).To(Equal(http.StatusSeeOther))
+		Expect(rec.Header().Get("Location")).To(Equal("/admin/alerts"))
+
+		Expect(client.rules).To(HaveLen(1))
+		rule := client.rules[1]
+		Expect(rule.GetThreshold()).To(Equal(30.5))
+		Expect(rule.GetEnabled()).To(BeTrue())
+		Expect(formatSilenceTime(context.Background(), rule.GetSilenceEnd())).To(Equal("2026-01-02T06:30"))
+
+		list := do(http.MethodGet, "/admin/alerts", nil)
+		Expect(list.Code).To(Equal(http.StatusOK))
+		Expect(list.Body.String()).To(ContainSubstring("Hot room"))
+		Expect(list.Body.String()).To(ContainSubstring("temperature &gt; 30.5"))
+	})
+
+	It("should re-render the form with parse errors", func() {
+		form := validForm()
+		form.Set("threshold", "hot")
+
+		rec := do(http.MethodPost, "/admin/alerts", form)
+
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring("must be a number"))
+		Expect(rec.Body.String()).To(ContainSubstring(`value="Hot room"`))
+		Expect(client.rules).To(BeEmpty())
+	})
+
+	It("should show backend field violations next to the fields", func() {
+		form := validForm()
+		form.Set("metric", "voltage")
+
+		rec := do(http.MethodPost, "/admin/alerts", form)
+
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring("unsupported metric"))
+	})
+
+	It("should edit, update and delete a rule", func() {
+		Expect(do(http.MethodPost, "/admin/alerts", validForm()).Code).To(Equal(http.StatusSeeOther))
+
+		edit := do(http.MethodGet, "/admin/alerts/1/edit", nil)
+		Expect(edit.Code).To(Equal(http.StatusOK))
+		Expect(edit.Body.String()).To(ContainSubstring(`action="/admin/alerts/1"`))
+		Expect(edit.Body.String()).To(ContainSubstring(`value="2026-01-01T00:00"`))
+
+		form := validForm()
+		form.Del("enabled")
+		form.Set("threshold", "25")
+		Expect(do(http.MethodPost, "/admin/alerts/1", form).Code).To(Equal(http.StatusSeeOther))
+		Expect(client.rules[1].GetEnabled()).To(BeFalse())
+		Expect(client.rules[1].GetThreshold()).To(Equal(25.0))
+
+		Expect(do(http.MethodPost, "/admin/alerts/1/delete", nil).Code).To(Equal(http.StatusSeeOther))
+		Expect(client.rules).To(BeEmpty())
+	})
+
+	It("should return not found for unknown rules", func() {
+		Expect(do(http.MethodGet, "/admin/alerts/9/edit", nil).Code).To(Equal(http.StatusNotFound))
+		Expect(do(http.MethodGet, "/admin/alerts/abc/edit", nil).Code).To(Equal(http.StatusNotFound))
+		Expect(do(http.MethodPost, "/admin/alerts/9/delete", nil).Code).To(Equal(http.StatusNotFound))
+	})
+})
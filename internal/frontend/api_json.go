@@ -0,0 +1,164 @@
+package frontend
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// jsonContentType is the media type of JSON API responses.
+const jsonContentType = "application/json"
+
+// deviceJSON is a device in JSON API responses.
+type deviceJSON struct {
+	DeviceID   string  `json:"device_id"`
+	Timestamp  int64   `json:"timestamp"`
+	Location   string  `json:"location"`
+	MacAddress string  `json:"mac_address"`
+	IPAddress  string  `json:"ip_address"`
+	Firmware   string  `json:"firmware"`
+	Latitude   float32 `json:"latitude"`
+	Longitude  float32 `json:"longitude"`
+}
+
+// readingJSON is a sensor reading in JSON API responses, in the units given
+// alongside it.
+type readingJSON struct {
+	Timestamp    int64   `json:"timestamp"`
+	Temperature  float64 `json:"temperature"`
+	Humidity     float64 `json:"humidity"`
+	Pressure     float64 `json:"pressure"`
+	BatteryLevel float64 `json:"battery_level"`
+}
+
+// unitsJSON names the units of the readings in a response.
+type unitsJSON struct {
+	Temperature string `json:"temperature"`
+	Pressure    string `json:"pressure"`
+}
+
+// paginationJSON describes the page returned. Total is omitted when the count
+// could not be fetched or is not repeated for follow-up pages.
+type paginationJSON struct {
+	PageToken     string `json:"page_token"`
+	PageSize      int    `json:"page_size"`
+	NextPageToken string `json:"next_page_token"`
+	Total         *int64 `json:"total,omitempty"`
+}
+
+// devicesResponse is the JSON variant of /api/devices. The backend returns all
+// devices at once, so the list is always a single page.
+type devicesResponse struct {
+	Devices    []deviceJSON   `json:"devices"`
+	Pagination paginationJSON `json:"pagination"`
+}
+
+// readingsResponse is the JSON variant of /api/device/{id}/readings.
+type readingsResponse struct {
+	DeviceID   string         `json:"device_id"`
+	Readings   []readingJSON  `json:"readings"`
+	Units      unitsJSON      `json:"units"`
+	SortBy     string         `json:"sort_by"`
+	Ascending  bool           `json:"ascending"`
+	Pagination paginationJSON `json:"pagination"`
+}
+
+// errorResponse is the JSON variant of error pages.
+type errorResponse struct {
+	Error errorJSON `json:"error"`
+}
+
+type errorJSON struct {
+	Status    int    `json:"status"`
+	Title     string `json:"title"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// wantsJSON reports whether r prefers a JSON response, i.e. its Accept header
+// lists application/json before text/html. Browsers and htmx list HTML first
+// or only send wildcards, so they keep getting HTML.
+func wantsJSON(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+		switch mediaType {
+		case jsonContentType:
+			return true
+		case "text/html":
+			return false
+		}
+	}
+
+	return false
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, statusCode int, v any) {
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Error("failed to write JSON response", "error", err, "request_id", requestIDFromContext(r.Context()))
+	}
+}
+
+// newDevicesResponse converts the backend devices to the JSON response.
+func newDevicesResponse(devices []*iotv1.IoTDevice) devicesResponse {
+	resp := devicesResponse{
+		Devices: make([]deviceJSON, len(devices)),
+	}
+	for i, d := range devices {
+		resp.Devices[i] = deviceJSON{
+			DeviceID:   d.GetDeviceId(),
+			Timestamp:  d.GetTimestamp(),
+			Location:   d.GetLocation(),
+			MacAddress: d.GetMacAddress(),
+			IPAddress:  d.GetIpAddress(),
+			Firmware:   d.GetFirmware(),
+			Latitude:   d.GetLatitude(),
+			Longitude:  d.GetLongitude(),
+		}
+	}
+
+	total := int64(len(devices))
+	resp.Pagination = paginationJSON{
+		PageSize: len(devices),
+		Total:    &total,
+	}
+
+	return resp
+}
+
+// newReadingsResponse converts a readings page to the JSON response.
+func newReadingsResponse(page readingsPage) readingsResponse {
+	resp := readingsResponse{
+		DeviceID: page.DeviceID,
+		Readings: make([]readingJSON, len(page.Rows)),
+		Units: unitsJSON{
+			Temperature: page.Prefs.TempUnit,
+			Pressure:    page.Prefs.PressureUnit,
+		},
+		SortBy:    page.Prefs.SortBy,
+		Ascending: page.Prefs.SortAsc,
+		Pagination: paginationJSON{
+			PageToken:     page.PageToken,
+			PageSize:      page.PageSize,
+			NextPageToken: page.NextPageToken,
+		},
+	}
+	for i, row := range page.Rows {
+		resp.Readings[i] = readingJSON(row)
+	}
+	if page.Total != unknownTotal {
+		total := page.Total
+		resp.Pagination.Total = &total
+	}
+
+	return resp
+}
@@ -0,0 +1,127 @@
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// jsonAPIClient adds a fixed device list to the readings stub.
+type jsonAPIClient struct {
+	readingsClient
+}
+
+func (c *jsonAPIClient) GetAllDevice(_ context.Context, _ *iotv1.GetAllDevicesRequest, _ ...grpc.CallOption) (*iotv1.GetAllDevicesResponse, error) {
+	return &iotv1.GetAllDevicesResponse{
+		Devices: []*iotv1.IoTDevice{
+			{DeviceId: "device-001", Location: "Lab", Firmware: "1.2.0"},
+			{DeviceId: "device-002", Location: "Roof", Firmware: "1.3.1"},
+		},
+	}, nil
+}
+
+var _ = Describe("JSON API", func() {
+	var (
+		client  *jsonAPIClient
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		client = &jsonAPIClient{}
+		server := &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: client,
+		}
+		handler = server.setupRoutes()
+	})
+
+	get := func(url, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("should return the devices as JSON", func() {
+		rec := get("/api/devices", "application/json")
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(rec.Header().Values("Vary")).To(ContainElement("Accept"))
+
+		var resp devicesResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Devices).To(HaveLen(2))
+		Expect(resp.Devices[1].DeviceID).To(Equal("device-002"))
+		Expect(resp.Devices[1].Location).To(Equal("Roof"))
+		Expect(resp.Pagination.NextPageToken).To(BeEmpty())
+		Expect(resp.Pagination.Total).To(HaveValue(BeEquivalentTo(2)))
+	})
+
+	It("should return readings with pagination metadata", func() {
+		rec := get("/api/device/device-001/readings?page_size=25&temp_unit=f", "application/json")
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var resp readingsResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.DeviceID).To(Equal("device-001"))
+		Expect(resp.Readings).To(HaveLen(2))
+		Expect(resp.Readings[0].Timestamp).To(BeEquivalentTo(1700000000))
+		Expect(resp.Readings[0].Temperature).To(BeNumerically("~", 70.7, 0.01))
+		Expect(resp.Units.Temperature).To(Equal(unitFahrenheit))
+		Expect(resp.Pagination.PageSize).To(Equal(25))
+		Expect(resp.Pagination.NextPageToken).To(Equal("2"))
+		Expect(resp.Pagination.Total).To(HaveValue(BeEquivalentTo(42)))
+
+		// JSON clients do not change the dashboard preferences
+		for _, c := range rec.Result().Cookies() {
+			Expect(c.Name).NotTo(Equal(preferencesCookie))
+		}
+	})
+
+	It("should omit the total for follow-up pages", func() {
+		rec := get("/api/device/device-001/readings?page_token=2", "application/json")
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring(`"page_token":"2"`))
+		Expect(rec.Body.String()).NotTo(ContainSubstring(`"total"`))
+	})
+
+	It("should return errors as JSON", func() {
+		rec := get("/api/device/device-001/readings?page_size=7", "application/json")
+
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+		var resp errorResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Error.Status).To(Equal(http.StatusBadRequest))
+		Expect(resp.Error.Message).To(Equal("Invalid page size"))
+	})
+
+	DescribeTable("should keep serving HTML to browsers and htmx",
+		func(accept string) {
+			rec := get("/api/devices", accept)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("Content-Type")).NotTo(Equal("application/json"))
+			Expect(rec.Body.String()).To(ContainSubstring("device-001"))
+			Expect(rec.Body.String()).NotTo(HavePrefix("{"))
+		},
+		Entry("no Accept header", ""),
+		Entry("wildcard", "*/*"),
+		Entry("browser", "text/html,application/xhtml+xml,application/json;q=0.9,*/*;q=0.8"),
+		Entry("JSON refused", "application/json;q=0, text/html"),
+	)
+})
@@ -0,0 +1,190 @@
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	_ "google.golang.org/grpc/health" // Registers client-side health checking
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// defaultBackendStartupTimeout is how long startup waits for the backend when
+// not configured.
+const defaultBackendStartupTimeout = 10 * time.Second
+
+// Load balancing policies for backend replicas.
+const (
+	// LoadBalancingRoundRobin spreads calls over all healthy backends.
+	LoadBalancingRoundRobin = "round_robin"
+	// LoadBalancingPickFirst sends all calls to the first reachable backend.
+	LoadBalancingPickFirst = "pick_first"
+)
+
+// staticScheme is the resolver scheme of comma-separated backend lists.
+const staticScheme = "static"
+
+// backendDialOptions returns the target and dial options connecting to the
+// backends at addr, a single address or a comma-separated list. A single host
+// name is resolved through DNS, so all addresses it resolves to are used.
+// Backends are health checked and calls balanced over them with policy.
+func backendDialOptions(addr, policy string) (string, []grpc.DialOption, error) {
+	if policy == "" {
+		policy = LoadBalancingRoundRobin
+	}
+	if policy != LoadBalancingRoundRobin && policy != LoadBalancingPickFirst {
+		return "", nil, fmt.Errorf("unknown load balancing policy %q (want %s or %s)", policy, LoadBalancingRoundRobin, LoadBalancingPickFirst)
+	}
+
+	// Backends that do not serve the health service are treated as healthy
+	serviceConfig := fmt.Sprintf(`{"loadBalancingConfig": [{%q: {}}], "healthCheckConfig": {"serviceName": %q}}`,
+		policy, iotv1.IoTService_ServiceDesc.ServiceName)
+	opts := []grpc.DialOption{grpc.WithDefaultServiceConfig(serviceConfig)}
+
+	if !strings.Contains(addr, ",") {
+		return addr, opts, nil
+	}
+
+	var addresses []resolver.Address
+	for a := range strings.SplitSeq(addr, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			addresses = append(addresses, resolver.Address{Addr: a})
+		}
+	}
+	if len(addresses) == 0 {
+		return "", nil, errors.New("backend address list is empty")
+	}
+
+	r := manual.NewBuilderWithScheme(staticScheme)
+	r.InitialState(resolver.State{Addresses: addresses})
+
+	return staticScheme + ":///backend", append(opts, grpc.WithResolvers(r)), nil
+}
+
+// backendStatus tracks the state of the backend gRPC connection for
+// /debug/status.
+type backendStatus struct {
+	mu        sync.Mutex
+	state     connectivity.State
+	since     time.Time
+	lastReady time.Time
+}
+
+// set records a state change.
+func (b *backendStatus) set(state connectivity.State, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = state
+	b.since = now
+	if state == connectivity.Ready {
+		b.lastReady = now
+	}
+}
+
+// backendStatusResponse is the backend section of /debug/status.
+type backendStatusResponse struct {
+	Address   string     `json:"address"`
+	State     string     `json:"state"`
+	Since     *time.Time `json:"since,omitempty"`
+	LastReady *time.Time `json:"last_ready,omitempty"`
+	FailFast  bool       `json:"fail_fast"`
+	// Reported by the backend's GetServerInfo, omitted until it answered
+	APIVersion    string   `json:"api_version,omitempty"`
+	ServerVersion string   `json:"server_version,omitempty"`
+	Capabilities  []string `json:"capabilities,omitempty"`
+}
+
+// debugStatusResponse is the body of /debug/status.
+type debugStatusResponse struct {
+	Backend backendStatusResponse `json:"backend"`
+}
+
+// response returns the status as reported by /debug/status.
+func (b *backendStatus) response() backendStatusResponse {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	resp := backendStatusResponse{State: b.state.String()}
+	if b.since.IsZero() {
+		resp.State = "NOT_CONNECTED"
+		return resp
+	}
+
+	since := b.since
+	resp.Since = &since
+	if !b.lastReady.IsZero() {
+		lastReady := b.lastReady
+		resp.LastReady = &lastReady
+	}
+	return resp
+}
+
+// waitForBackend starts connecting to the backend and waits until the
+// connection is ready or ctx is done.
+func waitForBackend(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.Connect()
+
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return fmt.Errorf("connection %s", state)
+		}
+
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("backend is %s: %w", state, ctx.Err())
+		}
+	}
+}
+
+// watchBackend records and logs state changes of the backend connection until
+// ctx is done or the connection is closed. gRPC reconnects on its own; pages
+// show the backend as unavailable until it does.
+func (s *Server) watchBackend(ctx context.Context, conn *grpc.ClientConn) {
+	state := conn.GetState()
+	s.backend.set(state, time.Now())
+
+	for conn.WaitForStateChange(ctx, state) {
+		state = conn.GetState()
+		s.backend.set(state, time.Now())
+
+		switch state {
+		case connectivity.Ready:
+			s.logger.Info("backend connection ready", "address", s.config.BackendGRPCAddr)
+			// The backend may have been replaced by another release
+			go s.refreshBackendInfo(ctx)
+		case connectivity.TransientFailure:
+			s.logger.Warn("backend connection failed, retrying", "address", s.config.BackendGRPCAddr)
+		case connectivity.Shutdown:
+			return
+		default:
+			s.logger.Debug("backend connection state changed", "address", s.config.BackendGRPCAddr, "state", state.String())
+		}
+	}
+}
+
+// handleDebugStatus reports the state of the backend connection.
+func (s *Server) handleDebugStatus(w http.ResponseWriter, _ *http.Request) {
+	backend := s.backend.response()
+	backend.Address = s.config.BackendGRPCAddr
+	backend.FailFast = s.config.BackendFailFast
+	s.backendInfo.fill(&backend)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(debugStatusResponse{Backend: backend}); err != nil {
+		s.logger.Error("failed to write debug status response", "error", err)
+	}
+}
@@ -0,0 +1,88 @@
+package frontend
+
+import (
+	"context"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// namedBackend answers GetAllDevice with a device named after the replica.
+type namedBackend struct {
+	iotv1.UnimplementedIoTServiceServer
+	name string
+}
+
+func (b *namedBackend) GetAllDevice(context.Context, *iotv1.GetAllDevicesRequest) (*iotv1.GetAllDevicesResponse, error) {
+	return &iotv1.GetAllDevicesResponse{Devices: []*iotv1.IoTDevice{{DeviceId: b.name}}}, nil
+}
+
+var _ = Describe("Backend load balancing", func() {
+	// startBackend serves a named replica with a health service and returns
+	// its address.
+	startBackend := func(name string) (string, *health.Server) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+
+		server := grpc.NewServer()
+		iotv1.RegisterIoTServiceServer(server, &namedBackend{name: name})
+		healthServer := health.NewServer()
+		healthServer.SetServingStatus(iotv1.IoTService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+		healthpb.RegisterHealthServer(server, healthServer)
+
+		go func() { _ = server.Serve(lis) }()
+		DeferCleanup(server.Stop)
+
+		return lis.Addr().String(), healthServer
+	}
+
+	It("should spread calls over a static list and skip unhealthy backends", func() {
+		first, _ := startBackend("first")
+		second, secondHealth := startBackend("second")
+
+		target, opts, err := backendDialOptions(first+", "+second, "")
+		Expect(err).NotTo(HaveOccurred())
+		conn, err := grpc.NewClient(target, append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		client := iotv1.NewIoTServiceClient(conn)
+
+		// servedBy returns the replicas answering n calls.
+		servedBy := func(n int) map[string]int {
+			replicas := map[string]int{}
+			for range n {
+				resp, err := client.GetAllDevice(context.Background(), &iotv1.GetAllDevicesRequest{})
+				Expect(err).NotTo(HaveOccurred())
+				replicas[resp.GetDevices()[0].GetDeviceId()]++
+			}
+			return replicas
+		}
+
+		Eventually(func() map[string]int { return servedBy(10) }).Should(And(HaveKey("first"), HaveKey("second")))
+
+		secondHealth.Shutdown()
+		Eventually(func() map[string]int { return servedBy(10) }).Should(Equal(map[string]int{"first": 10}))
+	})
+
+	It("should keep single addresses for DNS resolution", func() {
+		target, opts, err := backendDialOptions("backend:50051", LoadBalancingPickFirst)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(target).To(Equal("backend:50051"))
+		Expect(opts).To(HaveLen(1))
+	})
+
+	It("should reject unknown policies and empty lists", func() {
+		_, _, err := backendDialOptions("backend:50051", "random")
+		Expect(err).To(MatchError(ContainSubstring("unknown load balancing policy")))
+
+		_, _, err = backendDialOptions(" , ", "")
+		Expect(err).To(MatchError(ContainSubstring("empty")))
+	})
+})
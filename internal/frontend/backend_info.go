@@ -0,0 +1,145 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// backendInfoRefresh is how often the backend's server info is fetched again.
+// Replicas of different releases may answer during a rollout, so the info is
+// not fetched only once.
+const backendInfoRefresh = time.Minute
+
+// backendInfo caches what the backend reported through GetServerInfo.
+// Until it is known, the backend is assumed to have the baseline capabilities.
+type backendInfo struct {
+	mu                sync.RWMutex
+	known             bool
+	apiVersion        string
+	serverVersion     string
+	capabilities      []string
+	deprecatedMethods []string
+}
+
+// supports reports whether the backend supports capability.
+func (b *backendInfo) supports(capability string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if !b.known {
+		return slices.Contains(iotv1.BaselineCapabilities, capability)
+	}
+	return slices.Contains(b.capabilities, capability)
+}
+
+// set records resp and reports whether anything changed. A nil resp records a
+// backend without GetServerInfo, which has the baseline capabilities.
+func (b *backendInfo) set(resp *iotv1.GetServerInfoResponse) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	capabilities := resp.GetCapabilities()
+	if resp == nil {
+		capabilities = iotv1.BaselineCapabilities
+	}
+
+	changed := !b.known ||
+		b.apiVersion != resp.GetApiVersion() ||
+		b.serverVersion != resp.GetServerVersion() ||
+		!slices.Equal(b.capabilities, capabilities) ||
+		!slices.Equal(b.deprecatedMethods, resp.GetDeprecatedMethods())
+
+	b.known = true
+	b.apiVersion = resp.GetApiVersion()
+	b.serverVersion = resp.GetServerVersion()
+	b.capabilities = capabilities
+	b.deprecatedMethods = resp.GetDeprecatedMethods()
+
+	return changed
+}
+
+// version returns the release the backend reported, or an empty string.
+func (b *backendInfo) version() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.serverVersion
+}
+
+// fill adds the cached info to the /debug/status backend section.
+func (b *backendInfo) fill(resp *backendStatusResponse) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	resp.APIVersion = b.apiVersion
+	resp.ServerVersion = b.serverVersion
+	resp.Capabilities = b.capabilities
+}
+
+// refreshBackendInfo fetches the backend's server info. Failures keep the
+// previous info, so a transient error does not hide features.
+func (s *Server) refreshBackendInfo(ctx context.Context) {
+	ctx, cancel := s.backendContext(ctx)
+	defer cancel()
+
+	resp, err := trackedCall(s, ctx, "GetServerInfo", s.grpcClient.GetServerInfo, &iotv1.GetServerInfoRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		// Backends released before GetServerInfo
+		resp, err = nil, nil
+	}
+	if err != nil {
+		s.logger.Warn("failed to get backend server info", "error", err)
+		return
+	}
+
+	if !s.backendInfo.set(resp) {
+		return
+	}
+	if resp == nil {
+		s.logger.Info("backend does not report server info, assuming baseline capabilities")
+		return
+	}
+	s.logger.Info("backend server info",
+		"api_version", resp.GetApiVersion(),
+		"server_version", resp.GetServerVersion(),
+		"capabilities", resp.GetCapabilities())
+	if len(resp.GetDeprecatedMethods()) > 0 {
+		s.logger.Debug("backend reports deprecated methods", "methods", resp.GetDeprecatedMethods())
+	}
+}
+
+// pollBackendInfo refreshes the backend's server info every
+// backendInfoRefresh until ctx is done.
+func (s *Server) pollBackendInfo(ctx context.Context) {
+	ticker := time.NewTicker(backendInfoRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refreshBackendInfo(ctx)
+		}
+	}
+}
+
+// requireCapability wraps a handler of a feature that needs capability.
+// Requests get 501 while the connected backend does not support it.
+func (s *Server) requireCapability(capability string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.backendInfo.supports(capability) {
+			s.renderError(w, r, http.StatusNotImplemented, unsupportedMessage)
+			return
+		}
+		next(w, r)
+	}
+}
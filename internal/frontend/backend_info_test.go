@@ -0,0 +1,119 @@
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// serverInfoClient answers GetServerInfo with info, or Unimplemented when
+// info is nil. Other calls panic.
+type serverInfoClient struct {
+	iotv1.IoTServiceClient
+	info *iotv1.GetServerInfoResponse
+}
+
+func (c *serverInfoClient) GetServerInfo(context.Context, *iotv1.GetServerInfoRequest, ...grpc.CallOption) (*iotv1.GetServerInfoResponse, error) {
+	if c.info == nil {
+		return nil, status.Error(codes.Unimplemented, "unknown method GetServerInfo for service iot.v1.IoTService")
+	}
+	return c.info, nil
+}
+
+var _ = Describe("Backend capabilities", func() {
+	var (
+		client  *serverInfoClient
+		server  *Server
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		client = &serverInfoClient{}
+		server = &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: client,
+			config:     &ServerConfig{BackendGRPCAddr: "localhost:9090"},
+		}
+		handler = server.setupRoutes()
+	})
+
+	get := func(target string, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("should assume the baseline capabilities until the backend reports its own", func() {
+		for _, capability := range iotv1.BaselineCapabilities {
+			Expect(server.backendInfo.supports(capability)).To(BeTrue(), capability)
+		}
+	})
+
+	It("should assume the baseline capabilities when GetServerInfo is unimplemented", func() {
+		server.refreshBackendInfo(context.Background())
+
+		Expect(server.backendInfo.known).To(BeTrue())
+		Expect(server.backendInfo.supports(iotv1.CapabilityAlertRules)).To(BeTrue())
+	})
+
+	Context("when the backend lacks optional features", func() {
+		BeforeEach(func() {
+			client.info = &iotv1.GetServerInfoResponse{
+				ApiVersion:    iotv1.APIVersion,
+				ServerVersion: "v1.2.3",
+				Capabilities:  []string{iotv1.CapabilityDeviceNotes},
+			}
+			server.refreshBackendInfo(context.Background())
+		})
+
+		It("should answer their pages with 501", func() {
+			rec := get("/admin/alerts", "")
+
+			Expect(rec.Code).To(Equal(http.StatusNotImplemented))
+			Expect(rec.Body.String()).To(ContainSubstring(unsupportedMessage))
+		})
+
+		It("should report 501 as JSON to JSON clients", func() {
+			rec := get("/trash", jsonContentType)
+
+			Expect(rec.Code).To(Equal(http.StatusNotImplemented))
+			var body errorResponse
+			Expect(json.Unmarshal(rec.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.Error.Message).To(Equal(unsupportedMessage))
+		})
+
+		It("should render no quota banner without calling the backend", func() {
+			rec := get("/api/quota", "")
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).NotTo(ContainSubstring("quota"))
+		})
+
+		It("should show the reported info on /debug/status", func() {
+			rec := get("/debug/status", "")
+
+			var body debugStatusResponse
+			Expect(json.Unmarshal(rec.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.Backend.APIVersion).To(Equal(iotv1.APIVersion))
+			Expect(body.Backend.ServerVersion).To(Equal("v1.2.3"))
+			Expect(body.Backend.Capabilities).To(ConsistOf(iotv1.CapabilityDeviceNotes))
+		})
+	})
+})
@@ -0,0 +1,79 @@
+package frontend
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// batteryReportDays are the horizons offered on the low-battery report.
+var batteryReportDays = []int{7, 14, 30, 90}
+
+// defaultBatteryReportDays is used when no horizon is selected.
+const defaultBatteryReportDays = 14
+
+// batteryReport is the view model of the low-battery report page.
+type batteryReport struct {
+	Devices []*iotv1.LowBatteryDevice
+	Days    int
+}
+
+// handleBatteryReport serves the list of devices projected to run out of battery.
+func (s *Server) handleBatteryReport(w http.ResponseWriter, r *http.Request) {
+	report := batteryReport{Days: defaultBatteryReportDays}
+
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || !slices.Contains(batteryReportDays, days) {
+			s.renderError(w, r, http.StatusBadRequest, "Invalid report horizon")
+			return
+		}
+		report.Days = days
+	}
+
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	resp, err := trackedCall(s, ctx, "ListLowBatteryDevices", s.grpcClient.ListLowBatteryDevices,
+		&iotv1.ListLowBatteryDevicesRequest{WithinDays: int32(report.Days)})
+	if err != nil {
+		s.logger.Error("failed to fetch low battery devices", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to fetch battery report"))
+		return
+	}
+	report.Devices = resp.GetDevices()
+
+	if err := renderBatteryReport(r.Context(), w, report, s.metrics); err != nil {
+		s.logger.Error("failed to render battery report", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
+	}
+}
+
+// projectedEmptyLabel describes when a battery is projected to be empty.
+func projectedEmptyLabel(ctx context.Context, p *iotv1.BatteryProjection, now time.Time) string {
+	if p.GetProjectedEmptyAt() == 0 {
+		return t(ctx, "Not draining")
+	}
+
+	emptyAt := time.Unix(p.GetProjectedEmptyAt(), 0)
+	if !emptyAt.After(now) {
+		return t(ctx, "Empty since %s", formatDate(ctx, emptyAt))
+	}
+
+	days := int(math.Ceil(emptyAt.Sub(now).Hours() / 24))
+	if days == 1 {
+		return t(ctx, "%s (in 1 day)", formatDate(ctx, emptyAt))
+	}
+
+	return t(ctx, "%s (in %d days)", formatDate(ctx, emptyAt), days)
+}
+
+// drainRateLabel formats a battery drain rate for display.
+func drainRateLabel(ctx context.Context, p *iotv1.BatteryProjection) string {
+	return t(ctx, "%.2f %%/day", p.GetDrainPerDay())
+}
@@ -0,0 +1,104 @@
+package frontend
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// batteryClient is an IoTServiceClient stub returning a fixed low-battery report.
+type batteryClient struct {
+	iotv1.IoTServiceClient
+	withinDays int32
+	devices    []*iotv1.LowBatteryDevice
+}
+
+func (c *batteryClient) ListLowBatteryDevices(_ context.Context, req *iotv1.ListLowBatteryDevicesRequest, _ ...grpc.CallOption) (*iotv1.ListLowBatteryDevicesResponse, error) {
+	c.withinDays = req.GetWithinDays()
+	return &iotv1.ListLowBatteryDevicesResponse{Devices: c.devices}, nil
+}
+
+var _ = Describe("Battery report", func() {
+	var (
+		client  *batteryClient
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		client = &batteryClient{devices: []*iotv1.LowBatteryDevice{{
+			Device: &iotv1.IoTDevice{DeviceId: "sensor-1", Location: "Warehouse"},
+			BatteryProjection: &iotv1.BatteryProjection{
+				DrainPerDay:      2.5,
+				BatteryLevel:     10,
+				ProjectedEmptyAt: time.Now().Add(4 * 24 * time.Hour).Unix(),
+			},
+		}}}
+		server := &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: client,
+		}
+		handler = server.setupRoutes()
+	})
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	It("should list devices with the default horizon", func() {
+		rec := get("/reports/battery")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(client.withinDays).To(Equal(int32(defaultBatteryReportDays)))
+		Expect(rec.Body.String()).To(ContainSubstring("sensor-1"))
+		Expect(rec.Body.String()).To(ContainSubstring("2.50 %/day"))
+		Expect(rec.Body.String()).To(ContainSubstring("in 4 days"))
+	})
+
+	It("should pass the selected horizon to the backend", func() {
+		rec := get("/reports/battery?days=30")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(client.withinDays).To(Equal(int32(30)))
+	})
+
+	It("should reject unknown horizons", func() {
+		rec := get("/reports/battery?days=1000")
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should show an empty state", func() {
+		client.devices = nil
+		rec := get("/reports/battery?days=7")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring("No devices are projected to run out of battery within 7 days."))
+	})
+})
+
+var _ = Describe("projectedEmptyLabel", func() {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.Local)
+
+	It("should describe non-draining batteries", func() {
+		Expect(projectedEmptyLabel(context.Background(), &iotv1.BatteryProjection{}, now)).To(Equal("Not draining"))
+	})
+
+	It("should count the days until empty", func() {
+		p := &iotv1.BatteryProjection{ProjectedEmptyAt: now.Add(36 * time.Hour).Unix()}
+		Expect(projectedEmptyLabel(context.Background(), p, now)).To(Equal("2026-05-03 (in 2 days)"))
+	})
+
+	It("should flag batteries projected to be empty already", func() {
+		p := &iotv1.BatteryProjection{ProjectedEmptyAt: now.Add(-time.Hour).Unix()}
+		Expect(projectedEmptyLabel(context.Background(), p, now)).To(Equal("Empty since 2026-05-01"))
+	})
+})
@@ -0,0 +1,58 @@
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/a-h/templ"
+)
+
+// staticTemplate memoizes the output of a template that takes no arguments,
+// so fully static pages are rendered once per language and then served from
+// memory.
+type staticTemplate struct {
+	component templ.Component
+
+	mu   sync.RWMutex
+	html map[string][]byte // Keyed by language
+}
+
+// newStaticTemplate wraps a component whose output never changes.
+func newStaticTemplate(c templ.Component) *staticTemplate {
+	return &staticTemplate{component: c, html: make(map[string][]byte)}
+}
+
+// Render writes the cached output, rendering the component on first use.
+// Failed renders are not cached and will be retried on the next call.
+func (t *staticTemplate) Render(ctx context.Context, w io.Writer) error {
+	language := lang(ctx)
+
+	t.mu.RLock()
+	html := t.html[language]
+	t.mu.RUnlock()
+
+	if html == nil {
+		var buf bytes.Buffer
+		if err := t.component.Render(ctx, &buf); err != nil {
+			return err
+		}
+
+		html = buf.Bytes()
+
+		t.mu.Lock()
+		t.html[language] = html
+		t.mu.Unlock()
+	}
+
+	_, err := w.Write(html)
+	return err
+}
+
+// indexTemplate caches the index page, which has no dynamic content besides
+// its language.
+var indexTemplate = newStaticTemplate(index())
+
+// offlineTemplate caches the offline fallback page, which is static, too.
+var offlineTemplate = newStaticTemplate(offline())
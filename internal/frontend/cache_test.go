@@ -0,0 +1,55 @@
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/a-h/templ"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Static template cache", func() {
+	It("should render the component only once", func() {
+		calls := 0
+		t := newStaticTemplate(templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			calls++
+			_, err := io.WriteString(w, "<p>static</p>")
+			return err
+		}))
+
+		for range 3 {
+			var buf bytes.Buffer
+			Expect(t.Render(context.Background(), &buf)).To(Succeed())
+			Expect(buf.String()).To(Equal("<p>static</p>"))
+		}
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should not cache failed renders", func() {
+		calls := 0
+		t := newStaticTemplate(templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			calls++
+			if calls == 1 {
+				return errors.New("render failed")
+			}
+			_, err := io.WriteString(w, "ok")
+			return err
+		}))
+
+		var buf bytes.Buffer
+		Expect(t.Render(context.Background(), &buf)).NotTo(Succeed())
+		Expect(t.Render(context.Background(), &buf)).To(Succeed())
+		Expect(buf.String()).To(Equal("ok"))
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should serve the same index page as a fresh render", func() {
+		var cached, fresh bytes.Buffer
+		Expect(indexTemplate.Render(context.Background(), &cached)).To(Succeed())
+		Expect(index().Render(context.Background(), &fresh)).To(Succeed())
+		Expect(cached.String()).To(Equal(fresh.String()))
+	})
+})
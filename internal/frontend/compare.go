@@ -0,0 +1,299 @@
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// maxCompareDevices is the number of devices that can be compared at once.
+const maxCompareDevices = 5
+
+// compareWindows are the time windows offered in the comparison view.
+var compareWindows = []string{"1h", "6h", "24h", "7d", "30d", "90d"}
+
+// defaultCompareWindow is used when no window is selected.
+const defaultCompareWindow = "24h"
+
+// compareColors are the line colors assigned to compared devices, in order.
+var compareColors = []string{"#3498db", "#e74c3c", "#27ae60", "#f39c12", "#8e44ad"}
+
+// Chart dimensions in SVG user units.
+const (
+	chartWidth  = 600
+	chartHeight = 200
+)
+
+// comparison is the view model of the device comparison page.
+type comparison struct {
+	DeviceIDs []string
+	Window    string
+	Charts    []comparisonChart
+	Stats     []deviceStats
+}
+
+// comparisonChart is one metric plotted for all compared devices.
+type comparisonChart struct {
+	Title string
+	Lines []chartLine
+	// Min and Max are the y-axis bounds.
+	Min float64
+	Max float64
+}
+
+// chartLine is one device's series as an SVG polyline.
+type chartLine struct {
+	DeviceID string
+	Color    string
+	Points   string
+}
+
+// deviceStats are the per-metric statistics of one device over the window.
+type deviceStats struct {
+	DeviceID string
+	Color    string
+	Count    int
+	Metrics  []metricStats
+}
+
+// metricStats summarizes one metric of one device.
+type metricStats struct {
+	Name string
+	Min  float64
+	Max  float64
+	Avg  float64
+	Last float64
+}
+
+// comparedMetric extracts one metric from readings and backend statistics and
+// converts it to the preferred unit.
+type comparedMetric struct {
+	Name    string
+	Field   func(*iotv1.SensorReading) float64
+	Stats   func(*iotv1.SeriesStats) *iotv1.MetricStats
+	Convert func(preferences, float64) float64
+}
+
+// Value returns the metric of a reading in the preferred unit.
+func (m comparedMetric) Value(p preferences, r *iotv1.SensorReading) float64 {
+	return m.Convert(p, m.Field(r))
+}
+
+// noConversion is the Convert func of unitless metrics.
+func noConversion(_ preferences, v float64) float64 { return v }
+
+// comparedMetrics are the metrics charted in the comparison view.
+var comparedMetrics = []comparedMetric{
+	{Name: "Temperature", Field: (*iotv1.SensorReading).GetTemperature, Stats: (*iotv1.SeriesStats).GetTemperature, Convert: preferences.convertTemperature},
+	{Name: "Humidity", Field: (*iotv1.SensorReading).GetHumidity, Stats: (*iotv1.SeriesStats).GetHumidity, Convert: noConversion},
+	{Name: "Pressure", Field: (*iotv1.SensorReading).GetPressure, Stats: (*iotv1.SeriesStats).GetPressure, Convert: preferences.convertPressure},
+	{Name: "Battery", Field: (*iotv1.SensorReading).GetBatteryLevel, Stats: (*iotv1.SeriesStats).GetBatteryLevel, Convert: noConversion},
+}
+
+// metricTitle returns the chart title of a metric including its unit.
+func metricTitle(name string, p preferences) string {
+	switch name {
+	case "Temperature":
+		return fmt.Sprintf("Temperature (%s)", p.temperatureLabel())
+	case "Pressure":
+		return fmt.Sprintf("Pressure (%s)", p.pressureLabel())
+	default:
+		return name + " (%)"
+	}
+}
+
+// parseCompareDevices splits the comma-separated devices parameter, dropping
+// blanks and duplicates while keeping order.
+func parseCompareDevices(raw string) ([]string, error) {
+	var ids []string
+	for id := range strings.SplitSeq(raw, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" || slices.Contains(ids, id) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	if len(ids) > maxCompareDevices {
+		return nil, fmt.Errorf("at most %d devices can be compared", maxCompareDevices)
+	}
+
+	return ids, nil
+}
+
+// parseCompareWindow returns the window parameter as a duration.
+func parseCompareWindow(raw string) (string, time.Duration, error) {
+	if raw == "" {
+		raw = defaultCompareWindow
+	}
+
+	if !slices.Contains(compareWindows, raw) {
+		return "", 0, errors.New("unsupported window")
+	}
+
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		d, err := time.ParseDuration(days + "h")
+		return raw, d * 24, err
+	}
+
+	d, err := time.ParseDuration(raw)
+	return raw, d, err
+}
+
+// buildComparison converts backend series into charts and statistics.
+func buildComparison(series []*iotv1.SensorReadingSeries, start, end time.Time, window string, p preferences) comparison {
+	cmp := comparison{Window: window}
+
+	for i, s := range series {
+		cmp.DeviceIDs = append(cmp.DeviceIDs, s.GetDeviceId())
+
+		stats := deviceStats{
+			DeviceID: s.GetDeviceId(),
+			Color:    compareColors[i%len(compareColors)],
+			Count:    len(s.GetReadings()),
+		}
+		if s.GetStats() != nil {
+			stats.Count = int(s.GetStats().GetCount())
+		}
+		for _, m := range comparedMetrics {
+			stats.Metrics = append(stats.Metrics, summarize(m, p, s))
+		}
+		cmp.Stats = append(cmp.Stats, stats)
+	}
+
+	for _, m := range comparedMetrics {
+		cmp.Charts = append(cmp.Charts, buildChart(m, p, series, start, end))
+	}
+
+	return cmp
+}
+
+// summarize computes min, max, average and last value of a metric. Backend
+// statistics are preferred since the readings may be downsampled or bucket averages.
+func summarize(m comparedMetric, p preferences, series *iotv1.SensorReadingSeries) metricStats {
+	stats := metricStats{Name: m.Name}
+	readings := series.GetReadings()
+	if len(readings) == 0 {
+		return stats
+	}
+
+	if backend := m.Stats(series.GetStats()); backend != nil {
+		stats.Min = m.Convert(p, backend.GetMin())
+		stats.Max = m.Convert(p, backend.GetMax())
+		stats.Avg = m.Convert(p, backend.GetAvg())
+		stats.Last = m.Value(p, readings[len(readings)-1])
+		return stats
+	}
+
+	stats.Min = math.Inf(1)
+	stats.Max = math.Inf(-1)
+	var sum float64
+	for _, r := range readings {
+		v := m.Value(p, r)
+		stats.Min = min(stats.Min, v)
+		stats.Max = max(stats.Max, v)
+		sum += v
+	}
+	stats.Avg = sum / float64(len(readings))
+	stats.Last = m.Value(p, readings[len(readings)-1])
+
+	return stats
+}
+
+// buildChart scales every device's series of one metric into a shared chart.
+// The x axis spans the window; the y axis spans all values with a small margin.
+func buildChart(m comparedMetric, p preferences, series []*iotv1.SensorReadingSeries, start, end time.Time) comparisonChart {
+	chart := comparisonChart{Title: metricTitle(m.Name, p), Min: math.Inf(1), Max: math.Inf(-1)}
+
+	for _, s := range series {
+		for _, r := range s.GetReadings() {
+			v := m.Value(p, r)
+			chart.Min = min(chart.Min, v)
+			chart.Max = max(chart.Max, v)
+		}
+	}
+
+	if math.IsInf(chart.Min, 1) {
+		chart.Min, chart.Max = 0, 0
+		return chart
+	}
+
+	margin := (chart.Max - chart.Min) * 0.05
+	if margin == 0 {
+		margin = 1
+	}
+	chart.Min -= margin
+	chart.Max += margin
+
+	span := end.Sub(start).Seconds()
+	for i, s := range series {
+		points := make([]string, 0, len(s.GetReadings()))
+		for _, r := range s.GetReadings() {
+			x := float64(r.GetTimestamp()-start.Unix()) / span * chartWidth
+			y := chartHeight - (m.Value(p, r)-chart.Min)/(chart.Max-chart.Min)*chartHeight
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+		}
+
+		chart.Lines = append(chart.Lines, chartLine{
+			DeviceID: s.GetDeviceId(),
+			Color:    compareColors[i%len(compareColors)],
+			Points:   strings.Join(points, " "),
+		})
+	}
+
+	return chart
+}
+
+// handleCompare serves the device comparison page. Without a devices parameter
+// only the selection form is shown.
+func (s *Server) handleCompare(w http.ResponseWriter, r *http.Request) {
+	s.logger.Debug("handling compare request")
+
+	deviceIDs, err := parseCompareDevices(r.URL.Query().Get("devices"))
+	if err != nil {
+		s.renderError(w, r, http.StatusBadRequest, "Too many devices: "+err.Error())
+		return
+	}
+
+	window, windowDuration, err := parseCompareWindow(r.URL.Query().Get("window"))
+	if err != nil {
+		s.renderError(w, r, http.StatusBadRequest, "Invalid comparison window")
+		return
+	}
+
+	prefs, _ := loadPreferences(r)
+	cmp := comparison{DeviceIDs: deviceIDs, Window: window}
+
+	if len(deviceIDs) > 0 {
+		end := time.Now()
+		start := end.Add(-windowDuration)
+
+		ctx, cancel := s.backendContext(r.Context())
+		defer cancel()
+
+		resp, err := s.callGetSensorReadingSeriesBatch(ctx, &iotv1.GetSensorReadingSeriesBatchRequest{
+			DeviceIds: deviceIDs,
+			StartTime: start.Unix(),
+			EndTime:   end.Unix(),
+		})
+		if err != nil {
+			s.logger.Error("failed to fetch sensor reading series", "error", err, "device_ids", deviceIDs, "request_id", requestIDFromContext(r.Context()))
+			s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to fetch sensor readings"))
+			return
+		}
+
+		cmp = buildComparison(resp.GetSeries(), start, end, window, prefs)
+	}
+
+	if err := renderCompare(r.Context(), w, cmp, s.metrics); err != nil {
+		s.logger.Error("failed to render compare", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
+		return
+	}
+}
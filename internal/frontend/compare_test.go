@@ -0,0 +1,183 @@
+package frontend
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// seriesClient is an IoTServiceClient stub returning two readings per requested device.
+type seriesClient struct {
+	iotv1.IoTServiceClient
+	requests []*iotv1.GetSensorReadingSeriesBatchRequest
+}
+
+func (c *seriesClient) GetSensorReadingSeriesBatch(_ context.Context, req *iotv1.GetSensorReadingSeriesBatchRequest, _ ...grpc.CallOption) (*iotv1.GetSensorReadingSeriesBatchResponse, error) {
+	c.requests = append(c.requests, req)
+
+	resp := &iotv1.GetSensorReadingSeriesBatchResponse{}
+	for _, id := range req.GetDeviceIds() {
+		resp.Series = append(resp.Series, &iotv1.SensorReadingSeries{
+			DeviceId: id,
+			Readings: []*iotv1.SensorReading{
+				{DeviceId: id, Timestamp: req.GetStartTime() + 60, Temperature: 20, Humidity: 40, Pressure: 1000, BatteryLevel: 90},
+				{DeviceId: id, Timestamp: req.GetEndTime() - 60, Temperature: 24, Humidity: 50, Pressure: 1010, BatteryLevel: 80},
+			},
+		})
+	}
+	return resp, nil
+}
+
+var _ = Describe("Device comparison", func() {
+	Describe("parseCompareDevices", func() {
+		It("should trim, dedupe and keep order", func() {
+			ids, err := parseCompareDevices(" b, a,,b ,c")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids).To(Equal([]string{"b", "a", "c"}))
+		})
+
+		It("should reject more than the maximum number of devices", func() {
+			_, err := parseCompareDevices("a,b,c,d,e,f")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("parseCompareWindow", func() {
+		It("should parse offered windows and reject others", func() {
+			_, d, err := parseCompareWindow("7d")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d).To(Equal(7 * 24 * time.Hour))
+
+			w, d, err := parseCompareWindow("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w).To(Equal(defaultCompareWindow))
+			Expect(d).To(Equal(24 * time.Hour))
+
+			_, d, err = parseCompareWindow("90d")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d).To(Equal(90 * 24 * time.Hour))
+
+			_, _, err = parseCompareWindow("1y")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("buildComparison", func() {
+		It("should compute statistics and keep lines inside the chart", func() {
+			end := time.Unix(1700003600, 0)
+			start := end.Add(-time.Hour)
+			series := []*iotv1.SensorReadingSeries{{
+				DeviceId: "a",
+				Readings: []*iotv1.SensorReading{
+					{Timestamp: start.Unix(), Temperature: 10},
+					{Timestamp: end.Unix(), Temperature: 30},
+				},
+			}}
+
+			cmp := buildComparison(series, start, end, "1h", defaultPreferences())
+
+			Expect(cmp.Stats).To(HaveLen(1))
+			temp := cmp.Stats[0].Metrics[0]
+			Expect(temp.Min).To(Equal(10.0))
+			Expect(temp.Max).To(Equal(30.0))
+			Expect(temp.Avg).To(Equal(20.0))
+			Expect(temp.Last).To(Equal(30.0))
+
+			Expect(cmp.Charts).To(HaveLen(len(comparedMetrics)))
+			points := strings.Fields(cmp.Charts[0].Lines[0].Points)
+			Expect(points).To(HaveLen(2))
+			Expect(points[0]).To(HavePrefix("0.0,"))
+			Expect(points[1]).To(HavePrefix("600.0,"))
+		})
+
+		It("should prefer backend statistics over the returned points", func() {
+			end := time.Unix(1700003600, 0)
+			start := end.Add(-30 * 24 * time.Hour)
+			series := []*iotv1.SensorReadingSeries{{
+				DeviceId: "device-a",
+				Readings: []*iotv1.SensorReading{
+					{Timestamp: start.Unix(), Temperature: 15},
+					{Timestamp: end.Unix(), Temperature: 25},
+				},
+				Stats: &iotv1.SeriesStats{
+					Count:       120,
+					Temperature: &iotv1.MetricStats{Min: 0, Max: 100, Avg: 20},
+				},
+			}}
+
+			prefs := defaultPreferences()
+			prefs.TempUnit = unitFahrenheit
+			cmp := buildComparison(series, start, end, "30d", prefs)
+
+			Expect(cmp.Stats[0].Count).To(Equal(120))
+			temp := cmp.Stats[0].Metrics[0]
+			Expect(temp.Min).To(Equal(32.0))
+			Expect(temp.Max).To(Equal(212.0))
+			Expect(temp.Avg).To(Equal(68.0))
+			Expect(temp.Last).To(Equal(77.0))
+		})
+	})
+
+	Describe("handleCompare", func() {
+		var (
+			client  *seriesClient
+			handler http.Handler
+		)
+
+		BeforeEach(func() {
+			client = &seriesClient{}
+			server := &Server{
+				logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+					Level: slog.LevelError,
+				})),
+				grpcClient: client,
+			}
+			handler = server.setupRoutes()
+		})
+
+		get := func(url string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+			return rec
+		}
+
+		It("should show only the form without devices", func() {
+			rec := get("/compare")
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).To(ContainSubstring("Compare Devices"))
+			Expect(rec.Body.String()).NotTo(ContainSubstring("<polyline"))
+			Expect(client.requests).To(BeEmpty())
+		})
+
+		It("should fetch all devices in a single batched call", func() {
+			rec := get("/compare?devices=device-a,device-b&window=6h")
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(client.requests).To(HaveLen(1))
+			Expect(client.requests[0].GetDeviceIds()).To(Equal([]string{"device-a", "device-b"}))
+			req := client.requests[0]
+			Expect(req.GetEndTime() - req.GetStartTime()).To(Equal(int64(6 * 3600)))
+
+			body := rec.Body.String()
+			Expect(strings.Count(body, "<polyline")).To(Equal(2 * len(comparedMetrics)))
+			Expect(body).To(ContainSubstring("20.00 / 22.00 / 24.00 / 24.00"))
+		})
+
+		It("should reject too many devices and unknown windows", func() {
+			Expect(get("/compare?devices=a,b,c,d,e,f").Code).To(Equal(http.StatusBadRequest))
+			Expect(get("/compare?devices=a&window=1y").Code).To(Equal(http.StatusBadRequest))
+			Expect(client.requests).To(BeEmpty())
+		})
+	})
+})
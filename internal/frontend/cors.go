@@ -0,0 +1,181 @@
+package frontend
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// corsPathPrefix limits CORS to the API routes; pages and form posts stay
+// same-origin.
+const corsPathPrefix = "/api/"
+
+// Defaults of the CORS configuration.
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodHead}
+	defaultCORSHeaders = []string{"Content-Type"}
+)
+
+// defaultCORSMaxAge is how long browsers cache preflight results when not
+// configured.
+const defaultCORSMaxAge = 10 * time.Minute
+
+// CORSConfig allows pages on other origins, such as a separately hosted
+// single-page app, to call the API routes. The zero value disables CORS.
+type CORSConfig struct {
+	// AllowedOrigins are the origins allowed to call the API, such as
+	// https://dashboard.example.com, or "*" for any origin
+	// (optional, empty = CORS disabled)
+	AllowedOrigins []string
+	// AllowedMethods are the methods allowed in cross-origin requests
+	// (optional, default GET and HEAD)
+	AllowedMethods []string
+	// AllowedHeaders are the request headers allowed in cross-origin
+	// requests (optional, default Content-Type)
+	AllowedHeaders []string
+	// AllowCredentials lets cross-origin requests send cookies. It cannot be
+	// combined with the "*" origin.
+	AllowCredentials bool
+	// MaxAge is how long browsers cache preflight results (optional,
+	// default 10 minutes)
+	MaxAge time.Duration
+}
+
+// enabled reports whether any origin is allowed.
+func (c *CORSConfig) enabled() bool {
+	return len(c.AllowedOrigins) > 0
+}
+
+// validate checks the origins and applies the defaults.
+func (c *CORSConfig) validate() error {
+	if !c.enabled() {
+		return nil
+	}
+
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				return errors.New("CORS credentials cannot be allowed for any origin")
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return errors.New("CORS origin " + strconv.Quote(origin) + " must be a scheme and host, such as https://example.com")
+		}
+	}
+	if c.MaxAge < 0 {
+		return errors.New("CORS max age cannot be negative")
+	}
+
+	if len(c.AllowedMethods) == 0 {
+		c.AllowedMethods = defaultCORSMethods
+	}
+	// Browsers send the requested method in upper case
+	methods := make([]string, len(c.AllowedMethods))
+	for i, m := range c.AllowedMethods {
+		methods[i] = strings.ToUpper(m)
+	}
+	c.AllowedMethods = methods
+	if len(c.AllowedHeaders) == 0 {
+		c.AllowedHeaders = defaultCORSHeaders
+	}
+	if c.MaxAge == 0 {
+		c.MaxAge = defaultCORSMaxAge
+	}
+
+	return nil
+}
+
+// allowedOrigin returns the Access-Control-Allow-Origin value for origin, or
+// "" if it is not allowed.
+func (c *CORSConfig) allowedOrigin(origin string) string {
+	for _, allowed := range c.AllowedOrigins {
+		switch {
+		case allowed == "*":
+			return "*"
+		case strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin):
+			return origin
+		}
+	}
+	return ""
+}
+
+// allowsHeaders reports whether all headers of a comma-separated
+// Access-Control-Request-Headers value are allowed.
+func (c *CORSConfig) allowsHeaders(requested string) bool {
+	for h := range strings.SplitSeq(requested, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		if !slices.ContainsFunc(c.AllowedHeaders, func(allowed string) bool { return strings.EqualFold(allowed, h) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// corsMiddleware adds CORS headers to API responses for allowed origins and
+// answers their preflight requests. Requests from other origins are served
+// without CORS headers, so browsers do not expose the responses; their
+// preflights are rejected. Mutating requests still need a CSRF token.
+func (s *Server) corsMiddleware(next http.Handler) http.Handler {
+	cors := s.cors
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !strings.HasPrefix(r.URL.Path, corsPathPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Responses differ by origin, so caches must not share them
+		w.Header().Add("Vary", "Origin")
+
+		allowed := cors.allowedOrigin(origin)
+		requestedMethod := r.Header.Get("Access-Control-Request-Method")
+		preflight := r.Method == http.MethodOptions && requestedMethod != ""
+
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+			if allowed == "" || !slices.Contains(cors.AllowedMethods, requestedMethod) ||
+				!cors.allowsHeaders(r.Header.Get("Access-Control-Request-Headers")) {
+				s.logger.Debug("rejected CORS preflight",
+					"origin", origin,
+					"method", requestedMethod,
+					"path", r.URL.Path,
+					"request_id", requestIDFromContext(r.Context()),
+				)
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			setCORSOrigin(w, allowed, cors.AllowCredentials)
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(cors.AllowedMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(cors.AllowedHeaders, ", "))
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(cors.MaxAge.Seconds())))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if allowed != "" {
+			setCORSOrigin(w, allowed, cors.AllowCredentials)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setCORSOrigin allows the origin to read the response.
+func setCORSOrigin(w http.ResponseWriter, origin string, credentials bool) {
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if credentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
@@ -0,0 +1,128 @@
+package frontend
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("CORS", func() {
+	const origin = "https://dashboard.example.com"
+
+	var cors CORSConfig
+
+	BeforeEach(func() {
+		cors = CORSConfig{AllowedOrigins: []string{origin}}
+		Expect(cors.validate()).To(Succeed())
+	})
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		server := &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+			grpcClient: &trashClient{devices: map[string]*iotv1.IoTDevice{
+				"sensor-1": {DeviceId: "sensor-1", Location: "Warehouse"},
+			}},
+			cors: &cors,
+		}
+		rec := httptest.NewRecorder()
+		server.setupRoutes().ServeHTTP(rec, req)
+		return rec
+	}
+
+	request := func(method, target, from string) *http.Request {
+		req := httptest.NewRequest(method, target, nil)
+		if from != "" {
+			req.Header.Set("Origin", from)
+		}
+		return req
+	}
+
+	preflight := func(from, method, headers string) *http.Request {
+		req := request(http.MethodOptions, "/api/devices", from)
+		req.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			req.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		return req
+	}
+
+	It("should let allowed origins read API responses", func() {
+		rec := serve(request(http.MethodGet, "/api/devices", origin))
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring("sensor-1"))
+		Expect(rec.Header().Get("Access-Control-Allow-Origin")).To(Equal(origin))
+		Expect(rec.Header().Get("Access-Control-Allow-Credentials")).To(BeEmpty())
+		Expect(rec.Header().Values("Vary")).To(ContainElement("Origin"))
+	})
+
+	It("should serve other origins without CORS headers", func() {
+		rec := serve(request(http.MethodGet, "/api/devices", "https://evil.example.com"))
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+	})
+
+	It("should not add CORS headers outside the API routes", func() {
+		rec := serve(request(http.MethodGet, "/devices", origin))
+
+		Expect(rec.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+	})
+
+	It("should answer preflights of allowed origins", func() {
+		rec := serve(preflight(origin, http.MethodGet, "content-type"))
+
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		Expect(rec.Header().Get("Access-Control-Allow-Origin")).To(Equal(origin))
+		Expect(rec.Header().Get("Access-Control-Allow-Methods")).To(Equal("GET, HEAD"))
+		Expect(rec.Header().Get("Access-Control-Allow-Headers")).To(Equal("Content-Type"))
+		Expect(rec.Header().Get("Access-Control-Max-Age")).To(Equal("600"))
+		Expect(rec.Result().Cookies()).To(BeEmpty())
+	})
+
+	DescribeTable("should reject preflights that are not allowed",
+		func(from, method, headers string) {
+			rec := serve(preflight(from, method, headers))
+
+			Expect(rec.Code).To(Equal(http.StatusForbidden))
+			Expect(rec.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+		},
+		Entry("unknown origin", "https://evil.example.com", http.MethodGet, ""),
+		Entry("method not allowed", origin, http.MethodDelete, ""),
+		Entry("header not allowed", origin, http.MethodGet, "X-Custom"),
+	)
+
+	It("should allow any origin with a wildcard and send credentials when enabled", func() {
+		cors = CORSConfig{AllowedOrigins: []string{"*"}}
+		Expect(cors.validate()).To(Succeed())
+		Expect(serve(request(http.MethodGet, "/api/devices", "https://any.example.com")).Header().Get("Access-Control-Allow-Origin")).To(Equal("*"))
+
+		cors = CORSConfig{AllowedOrigins: []string{origin + "/"}, AllowCredentials: true}
+		Expect(cors.validate()).To(Succeed())
+		rec := serve(request(http.MethodGet, "/api/devices", origin))
+		Expect(rec.Header().Get("Access-Control-Allow-Origin")).To(Equal(origin))
+		Expect(rec.Header().Get("Access-Control-Allow-Credentials")).To(Equal("true"))
+	})
+
+	It("should still require a CSRF token for mutating requests", func() {
+		cors.AllowedMethods = []string{http.MethodGet, http.MethodPost}
+
+		Expect(serve(request(http.MethodPost, "/api/devices", origin)).Code).To(Equal(http.StatusForbidden))
+	})
+
+	DescribeTable("should reject invalid configurations",
+		func(config CORSConfig, message string) {
+			Expect(config.validate()).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("credentials for any origin", CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, "credentials"),
+		Entry("origin without scheme", CORSConfig{AllowedOrigins: []string{"example.com"}}, "scheme and host"),
+		Entry("origin with path", CORSConfig{AllowedOrigins: []string{"https://example.com/app"}}, "scheme and host"),
+		Entry("negative max age", CORSConfig{AllowedOrigins: []string{"*"}, MaxAge: -1}, "max age"),
+	)
+})
@@ -0,0 +1,130 @@
+package frontend
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+
+	"procodus.dev/demo-app/pkg/session"
+)
+
+// CSRF protection uses the double-submit cookie pattern: every client gets a
+// random token in a cookie, and mutating requests must echo it in a header or
+// form field. Other sites can make a browser send the cookie but cannot read it,
+// so they cannot echo it. Forms render the token in a hidden field, so they
+// work without JavaScript; the layout script copies it into htmx requests.
+//
+// With sessions enabled, the token is also kept in the session and requests are
+// checked against that copy, so a token cookie planted by a sibling subdomain
+// is not accepted.
+const (
+	csrfCookieName = "csrf_token"
+	csrfHeader     = "X-CSRF-Token"
+	csrfFormField  = "csrf_token"
+	csrfSessionKey = "csrf_token"
+	// csrfTokenBytes is the size of a token before hex encoding.
+	csrfTokenBytes = 32
+)
+
+type csrfTokenKey struct{}
+
+// csrfFailureMessage is shown when a mutating request carries no valid token.
+const csrfFailureMessage = "Your request could not be verified. Reload the page and try again"
+
+// csrfMiddleware issues CSRF token cookies and rejects requests with unsafe
+// methods (anything but GET, HEAD, OPTIONS and TRACE) whose header or form
+// token does not match the cookie.
+func (s *Server) csrfMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var cookieToken string
+		if cookie, err := r.Cookie(csrfCookieName); err == nil && validCSRFToken(cookie.Value) {
+			cookieToken = cookie.Value
+		}
+
+		token := cookieToken
+		if sess := session.FromContext(r.Context()); sess != nil {
+			token = sess.Get(csrfSessionKey)
+			if !validCSRFToken(token) {
+				// Never adopt the cookie, which the session did not issue
+				token = newCSRFToken()
+				sess.Set(csrfSessionKey, token)
+			}
+		} else if token == "" {
+			token = newCSRFToken()
+		}
+
+		if token != cookieToken {
+			http.SetCookie(w, &http.Cookie{
+				Name:     csrfCookieName,
+				Value:    token,
+				Path:     "/",
+				Secure:   r.TLS != nil,
+				SameSite: http.SameSiteLaxMode,
+				// Readable by the layout script, which echoes it
+				HttpOnly: false,
+			})
+		}
+
+		if !safeMethod(r.Method) && !csrfTokenMatches(token, submittedCSRFToken(r)) {
+			s.logger.Warn("rejected request without valid CSRF token",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"request_id", requestIDFromContext(r.Context()),
+			)
+			s.renderError(w, r, http.StatusForbidden, csrfFailureMessage)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), csrfTokenKey{}, token)))
+	})
+}
+
+// csrfTokenFromContext returns the CSRF token of the request, or an empty
+// string outside csrfMiddleware.
+func csrfTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(csrfTokenKey{}).(string)
+	return token
+}
+
+// submittedCSRFToken returns the token echoed by the client, preferring the
+// header set for htmx requests over the form field of plain form posts.
+func submittedCSRFToken(r *http.Request) string {
+	if token := r.Header.Get(csrfHeader); token != "" {
+		return token
+	}
+	return r.PostFormValue(csrfFormField)
+}
+
+// csrfTokenMatches compares tokens in constant time.
+func csrfTokenMatches(expected, submitted string) bool {
+	return submitted != "" && subtle.ConstantTimeCompare([]byte(expected), []byte(submitted)) == 1
+}
+
+// safeMethod reports whether method must not change state and is therefore
+// exempt from CSRF checks.
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+// newCSRFToken returns a random hex-encoded token.
+func newCSRFToken() string {
+	b := make([]byte, csrfTokenBytes)
+	_, _ = rand.Read(b) // crypto/rand.Read never returns an error
+	return hex.EncodeToString(b)
+}
+
+// validCSRFToken reports whether token has the form of an issued token.
+func validCSRFToken(token string) bool {
+	if len(token) != 2*csrfTokenBytes {
+		return false
+	}
+	_, err := hex.DecodeString(token)
+	return err == nil
+}
@@ -0,0 +1,152 @@
+package frontend
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"procodus.dev/demo-app/pkg/session"
+)
+
+// testCSRFToken is a well-formed CSRF token used by specs posting to the server.
+var testCSRFToken = strings.Repeat("ab", csrfTokenBytes)
+
+// withCSRFToken adds a matching CSRF cookie and header to req, as the layout
+// script does for htmx requests.
+func withCSRFToken(req *http.Request) *http.Request {
+	req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: testCSRFToken})
+	req.Header.Set(csrfHeader, testCSRFToken)
+	return req
+}
+
+var _ = Describe("CSRF Middleware", func() {
+	var (
+		handler http.Handler
+		served  int
+	)
+
+	BeforeEach(func() {
+		served = 0
+		server := &Server{logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))}
+		handler = server.csrfMiddleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			served++
+			w.WriteHeader(http.StatusNoContent)
+		}))
+	})
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("should issue a token cookie to new clients", func() {
+		rec := serve(httptest.NewRequest(http.MethodGet, "/devices", nil))
+
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		cookies := rec.Result().Cookies()
+		Expect(cookies).To(HaveLen(1))
+		Expect(cookies[0].Name).To(Equal(csrfCookieName))
+		Expect(validCSRFToken(cookies[0].Value)).To(BeTrue())
+		Expect(cookies[0].HttpOnly).To(BeFalse())
+		Expect(cookies[0].SameSite).To(Equal(http.SameSiteLaxMode))
+	})
+
+	It("should keep a valid existing token", func() {
+		req := httptest.NewRequest(http.MethodGet, "/devices", nil)
+		req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: testCSRFToken})
+
+		Expect(serve(req).Result().Cookies()).To(BeEmpty())
+	})
+
+	It("should reject posts without a token", func() {
+		req := httptest.NewRequest(http.MethodPost, "/device/sensor-1/delete", nil)
+		req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: testCSRFToken})
+
+		rec := serve(req)
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+		Expect(rec.Body.String()).To(ContainSubstring(csrfFailureMessage))
+		Expect(served).To(BeZero())
+	})
+
+	It("should reject posts whose token does not match the cookie", func() {
+		req := httptest.NewRequest(http.MethodPost, "/device/sensor-1/delete", nil)
+		req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: testCSRFToken})
+		req.Header.Set(csrfHeader, strings.Repeat("cd", csrfTokenBytes))
+
+		Expect(serve(req).Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should reject posts without a cookie even if a token is sent", func() {
+		req := httptest.NewRequest(http.MethodPost, "/device/sensor-1/delete", nil)
+		req.Header.Set(csrfHeader, testCSRFToken)
+
+		Expect(serve(req).Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should accept the token in the htmx header", func() {
+		Expect(serve(withCSRFToken(httptest.NewRequest(http.MethodPost, "/device/sensor-1/delete", nil))).Code).To(Equal(http.StatusNoContent))
+		Expect(served).To(Equal(1))
+	})
+
+	It("should accept the token in the form field of plain form posts", func() {
+		form := url.Values{csrfFormField: {testCSRFToken}, "name": {"Hot room"}}
+		req := httptest.NewRequest(http.MethodPost, "/admin/alerts", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: testCSRFToken})
+
+		Expect(serve(req).Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("should render an htmx fragment for rejected htmx requests", func() {
+		req := httptest.NewRequest(http.MethodPost, "/device/sensor-1/notes", nil)
+		req.Header.Set("HX-Request", "true")
+
+		rec := serve(req)
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+		Expect(rec.Body.String()).NotTo(ContainSubstring("<html"))
+	})
+
+	Context("with a session", func() {
+		var sess *session.Session
+
+		BeforeEach(func() {
+			sess = session.New()
+		})
+
+		serveWithSession := func(req *http.Request) *httptest.ResponseRecorder {
+			return serve(req.WithContext(session.NewContext(req.Context(), sess)))
+		}
+
+		It("should keep the token in the session and ignore a planted cookie", func() {
+			req := httptest.NewRequest(http.MethodGet, "/devices", nil)
+			req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: testCSRFToken})
+
+			cookies := serveWithSession(req).Result().Cookies()
+			Expect(cookies).To(HaveLen(1))
+			Expect(cookies[0].Value).NotTo(Equal(testCSRFToken))
+			Expect(sess.Get(csrfSessionKey)).To(Equal(cookies[0].Value))
+		})
+
+		It("should reject posts matching the cookie but not the session token", func() {
+			sess.Set(csrfSessionKey, strings.Repeat("cd", csrfTokenBytes))
+
+			Expect(serveWithSession(withCSRFToken(httptest.NewRequest(http.MethodPost, "/device/sensor-1/delete", nil))).Code).To(Equal(http.StatusForbidden))
+			Expect(served).To(BeZero())
+		})
+
+		It("should accept posts echoing the session token", func() {
+			sess.Set(csrfSessionKey, testCSRFToken)
+
+			rec := serveWithSession(withCSRFToken(httptest.NewRequest(http.MethodPost, "/device/sensor-1/delete", nil)))
+			Expect(rec.Code).To(Equal(http.StatusNoContent))
+			Expect(rec.Result().Cookies()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,250 @@
+package frontend
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// minEmbedKeyLength is the shortest accepted embed signing key in bytes.
+const minEmbedKeyLength = 32
+
+// embedReadings is the number of latest readings drawn in the sparkline.
+const embedReadings = 48
+
+// Sparkline dimensions in SVG user units.
+const (
+	sparklineWidth  = 200
+	sparklineHeight = 40
+)
+
+// EmbedConfig lets other sites, such as wikis, frame read-only device panels
+// served at /embed/device/{id}. Each panel URL carries a token signed for its
+// device, so the panels of other devices cannot be opened by editing the URL.
+// The zero value disables embedding.
+type EmbedConfig struct {
+	// SigningKey signs and verifies the embed tokens; changing it revokes
+	// every issued token (optional, empty = embedding disabled)
+	SigningKey string
+	// FrameAncestors are the origins allowed to frame the panels, such as
+	// https://wiki.example.com (optional, empty = any origin)
+	FrameAncestors []string
+}
+
+// enabled reports whether embedding is configured.
+func (c *EmbedConfig) enabled() bool {
+	return c.SigningKey != ""
+}
+
+// validate checks the key and the frame ancestors.
+func (c *EmbedConfig) validate() error {
+	if !c.enabled() {
+		if len(c.FrameAncestors) > 0 {
+			return errors.New("embed frame ancestors require an embed signing key")
+		}
+		return nil
+	}
+
+	if len(c.SigningKey) < minEmbedKeyLength {
+		return fmt.Errorf("embed signing key must be at least %d bytes", minEmbedKeyLength)
+	}
+	for _, origin := range c.FrameAncestors {
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return errors.New("embed frame ancestor " + strconv.Quote(origin) + " must be a scheme and host, such as https://example.com")
+		}
+	}
+
+	return nil
+}
+
+// contentSecurityPolicy returns the policy of the panels. They have no
+// scripts, forms or requests of their own, and only the framing is relaxed.
+func (c *EmbedConfig) contentSecurityPolicy() string {
+	ancestors := "*"
+	if len(c.FrameAncestors) > 0 {
+		ancestors = strings.Join(c.FrameAncestors, " ")
+	}
+
+	return strings.Join([]string{
+		"default-src 'none'",
+		"style-src 'unsafe-inline'",
+		"img-src 'self' data:",
+		"base-uri 'none'",
+		"form-action 'none'",
+		"frame-ancestors " + ancestors,
+	}, "; ")
+}
+
+// SignEmbedToken returns the token of the embed URL of a device. The token
+// expires at expires, or never when expires is the zero time.
+func SignEmbedToken(key, deviceID string, expires time.Time) string {
+	var expiry int64
+	if !expires.IsZero() {
+		expiry = expires.Unix()
+	}
+	exp := strconv.FormatInt(expiry, 10)
+
+	return exp + "." + base64.RawURLEncoding.EncodeToString(embedMAC(key, deviceID, exp))
+}
+
+// EmbedURL returns the URL of the embeddable panel of a device on the
+// frontend at baseURL, such as https://iot.example.com.
+func EmbedURL(baseURL, key, deviceID string, expires time.Time) string {
+	return strings.TrimSuffix(baseURL, "/") + devicePath("/embed/device/", deviceID) +
+		"?token=" + url.QueryEscape(SignEmbedToken(key, deviceID, expires))
+}
+
+// embedMAC signs a device ID together with the expiry of the token.
+func embedMAC(key, deviceID, exp string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(exp + "\n" + deviceID))
+	return mac.Sum(nil)
+}
+
+// verifyEmbedToken reports whether token was signed with key for deviceID
+// and has not expired at now.
+func verifyEmbedToken(key, deviceID, token string, now time.Time) bool {
+	exp, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expiry, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || (expiry != 0 && now.Unix() >= expiry) {
+		return false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(mac, embedMAC(key, deviceID, exp))
+}
+
+// embedPanel is the view model of an embedded device panel.
+type embedPanel struct {
+	Device *iotv1.IoTDevice
+	// Latest is the newest reading converted to the units in Prefs, or nil
+	// when the device has not reported yet.
+	Latest *readingRow
+	Prefs  preferences
+	// Sparkline is the temperature of the latest readings as the points of an
+	// SVG polyline, oldest first.
+	Sparkline string
+}
+
+// sparklinePoints scales values into the sparkline, oldest first. Fewer than
+// two values draw no line.
+func sparklinePoints(values []float64) string {
+	if len(values) < 2 {
+		return ""
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	if hi == lo {
+		// Draw a flat series through the middle
+		lo, hi = lo-1, hi+1
+	}
+
+	points := make([]string, len(values))
+	for i, v := range values {
+		x := float64(i) / float64(len(values)-1) * sparklineWidth
+		y := sparklineHeight - (v-lo)/(hi-lo)*sparklineHeight
+		points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+
+	return strings.Join(points, " ")
+}
+
+// handleEmbedDevice serves the read-only panel of a device for framing into
+// other sites. Its headers allow the configured frame ancestors; errors are
+// rendered as plain panels rather than dashboard pages, which may not be framed.
+func (s *Server) handleEmbedDevice(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.PathValue("id")
+
+	h := w.Header()
+	h.Set("Content-Security-Policy", s.embed.contentSecurityPolicy())
+	h.Del("X-Frame-Options")
+	// Tokens are credentials; keep them out of the Referer of the dashboard link
+	h.Set("Referrer-Policy", "no-referrer")
+
+	if !verifyEmbedToken(s.embed.SigningKey, deviceID, r.URL.Query().Get("token"), time.Now()) {
+		s.renderEmbedError(w, r, http.StatusForbidden, "Invalid or expired embed token")
+		return
+	}
+
+	// Embedding sites pick the units in the URL; preferences are not saved
+	// as framed pages rarely get to keep cookies
+	prefs := defaultPreferences()
+	prefs.apply(r.URL.Query())
+
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	deviceResp, err := s.callGetDevice(ctx, &iotv1.GetDeviceByIDRequest{DeviceId: deviceID})
+	if err != nil {
+		if errorStatus(err) != http.StatusNotFound {
+			s.logger.Error("failed to fetch device", "error", err, "device_id", deviceID, "request_id", requestIDFromContext(r.Context()))
+		}
+		s.renderEmbedError(w, r, errorStatus(err), errorMessage(err, "Device not found"))
+		return
+	}
+
+	readingsResp, err := s.callGetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
+		DeviceId: deviceID,
+		PageSize: embedReadings,
+		OrderBy:  "timestamp",
+	})
+	if err != nil {
+		s.logger.Error("failed to fetch sensor readings", "error", err, "device_id", deviceID, "request_id", requestIDFromContext(r.Context()))
+		s.renderEmbedError(w, r, errorStatus(err), errorMessage(err, "Failed to fetch sensor readings"))
+		return
+	}
+
+	panel := embedPanel{Device: deviceResp.GetDevice(), Prefs: prefs}
+	readings := readingsResp.GetReading()
+	if len(readings) > 0 {
+		latest := readings[0]
+		panel.Latest = &readingRow{
+			Timestamp:    latest.GetTimestamp(),
+			Temperature:  prefs.convertTemperature(latest.GetTemperature()),
+			Humidity:     latest.GetHumidity(),
+			Pressure:     prefs.convertPressure(latest.GetPressure()),
+			BatteryLevel: latest.GetBatteryLevel(),
+		}
+	}
+	// The backend returns the newest reading first
+	temperatures := make([]float64, len(readings))
+	for i, reading := range readings {
+		temperatures[len(readings)-1-i] = prefs.convertTemperature(reading.GetTemperature())
+	}
+	panel.Sparkline = sparklinePoints(temperatures)
+
+	if err := renderEmbedDevice(r.Context(), w, panel, s.metrics); err != nil {
+		s.logger.Error("failed to render embedded device", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderEmbedError(w, r, http.StatusInternalServerError, genericErrorMessage)
+	}
+}
+
+// renderEmbedError writes an error as embedded panel.
+func (s *Server) renderEmbedError(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := renderEmbedMessage(r.Context(), w, message, s.metrics); err != nil {
+		s.logger.Error("failed to render embed error", "error", err, "request_id", requestIDFromContext(r.Context()))
+	}
+}
@@ -0,0 +1,164 @@
+package frontend
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Embedded device panels", func() {
+	const key = "0123456789abcdef0123456789abcdef"
+
+	var (
+		embed   EmbedConfig
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		embed = EmbedConfig{SigningKey: key, FrameAncestors: []string{"https://wiki.example.com"}}
+		Expect(embed.validate()).To(Succeed())
+
+		server := &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: &readingsClient{},
+			embed:      &embed,
+		}
+		handler = server.setupRoutes()
+	})
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	Describe("tokens", func() {
+		now := time.Unix(1768478400, 0)
+
+		It("should accept tokens signed for the device", func() {
+			Expect(verifyEmbedToken(key, "sensor-1", SignEmbedToken(key, "sensor-1", time.Time{}), now)).To(BeTrue())
+			Expect(verifyEmbedToken(key, "sensor-1", SignEmbedToken(key, "sensor-1", now.Add(time.Hour)), now)).To(BeTrue())
+		})
+
+		It("should reject tokens of other devices, other keys and expired tokens", func() {
+			Expect(verifyEmbedToken(key, "sensor-2", SignEmbedToken(key, "sensor-1", time.Time{}), now)).To(BeFalse())
+			Expect(verifyEmbedToken(key, "sensor-1", SignEmbedToken(strings.Repeat("x", 32), "sensor-1", time.Time{}), now)).To(BeFalse())
+			Expect(verifyEmbedToken(key, "sensor-1", SignEmbedToken(key, "sensor-1", now.Add(-time.Second)), now)).To(BeFalse())
+		})
+
+		It("should reject tokens whose expiry was changed", func() {
+			token := SignEmbedToken(key, "sensor-1", now.Add(-time.Hour))
+			_, sig, _ := strings.Cut(token, ".")
+
+			Expect(verifyEmbedToken(key, "sensor-1", "0."+sig, now)).To(BeFalse())
+			Expect(verifyEmbedToken(key, "sensor-1", "", now)).To(BeFalse())
+		})
+	})
+
+	It("should serve the latest values and a sparkline to frames on the allowed origins", func() {
+		rec := get(EmbedURL("", key, "sensor-1", time.Time{}))
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("X-Frame-Options")).To(BeEmpty())
+		Expect(rec.Header().Get("Content-Security-Policy")).To(ContainSubstring("frame-ancestors https://wiki.example.com"))
+		Expect(rec.Header().Get("Content-Security-Policy")).To(ContainSubstring("default-src 'none'"))
+		body := rec.Body.String()
+		Expect(body).To(ContainSubstring("21.5 °C"))
+		Expect(body).To(ContainSubstring(`<polyline`))
+		Expect(body).NotTo(ContainSubstring("<script"))
+	})
+
+	It("should convert the values to the units in the URL", func() {
+		rec := get(EmbedURL("", key, "sensor-1", time.Time{}) + "&temp_unit=f")
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring("70.7 °F"))
+		for _, cookie := range rec.Result().Cookies() {
+			Expect(cookie.Name).NotTo(Equal(preferencesCookie))
+		}
+	})
+
+	It("should reject invalid tokens with a frameable error panel", func() {
+		rec := get(EmbedURL("", key, "sensor-2", time.Time{}) + "x")
+
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+		Expect(rec.Header().Get("X-Frame-Options")).To(BeEmpty())
+		Expect(rec.Body.String()).To(ContainSubstring("Invalid or expired embed token"))
+	})
+
+	It("should not frame the dashboard pages", func() {
+		rec := get("/device/sensor-1")
+
+		Expect(rec.Header().Get("X-Frame-Options")).To(Equal("DENY"))
+		Expect(rec.Header().Get("Content-Security-Policy")).To(ContainSubstring("frame-ancestors 'none'"))
+	})
+
+	It("should not serve panels when embedding is disabled", func() {
+		server := &Server{
+			logger:     slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+			grpcClient: &readingsClient{},
+		}
+		rec := httptest.NewRecorder()
+		server.setupRoutes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, EmbedURL("", key, "sensor-1", time.Time{}), nil))
+
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+	})
+
+	DescribeTable("should reject invalid configuration",
+		func(config EmbedConfig, message string) {
+			Expect(config.validate()).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("short key", EmbedConfig{SigningKey: "secret"}, "at least 32 bytes"),
+		Entry("ancestor with path", EmbedConfig{SigningKey: key, FrameAncestors: []string{"https://wiki.example.com/page"}}, "scheme and host"),
+		Entry("ancestors without key", EmbedConfig{FrameAncestors: []string{"https://wiki.example.com"}}, "require an embed signing key"),
+	)
+
+	It("should draw a flat sparkline for constant values", func() {
+		Expect(sparklinePoints([]float64{20})).To(BeEmpty())
+		Expect(sparklinePoints([]float64{20, 20})).To(Equal("0.0,20.0 200.0,20.0"))
+	})
+
+	It("should have no accessibility violations", Label("a11y"), func() {
+		rec := get(EmbedURL("", key, "sensor-1", time.Time{}))
+
+		Expect(a11yViolations(rec.Body.String(), false)).To(BeEmpty())
+	})
+})
+
+// newDeviceClient is an IoTServiceClient stub of a device without readings.
+type newDeviceClient struct {
+	readingsClient
+}
+
+func (c *newDeviceClient) GetSensorReadingByDeviceID(_ context.Context, _ *iotv1.GetSensorReadingByDeviceIDRequest, _ ...grpc.CallOption) (*iotv1.GetSensorReadingByDeviceIDResponse, error) {
+	return &iotv1.GetSensorReadingByDeviceIDResponse{}, nil
+}
+
+var _ = Describe("Embedded panel of a device without readings", func() {
+	It("should say so instead of showing values", func() {
+		const key = "0123456789abcdef0123456789abcdef"
+		server := &Server{
+			logger:     slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+			grpcClient: &newDeviceClient{},
+			embed:      &EmbedConfig{SigningKey: key},
+		}
+		rec := httptest.NewRecorder()
+		server.setupRoutes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, EmbedURL("", key, "sensor-1", time.Time{}), nil))
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Security-Policy")).To(ContainSubstring("frame-ancestors *"))
+		Expect(rec.Body.String()).To(ContainSubstring("No readings yet."))
+	})
+})
@@ -0,0 +1,138 @@
+package frontend
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// genericErrorMessage is shown for unexpected failures.
+const genericErrorMessage = "Something went wrong while processing your request"
+
+// backendUnavailableMessage is shown when the backend cannot be reached.
+const backendUnavailableMessage = "The backend service is currently unavailable. Please try again shortly"
+
+// unsupportedMessage is shown for features the connected backend lacks.
+const unsupportedMessage = "This feature is not supported by the connected backend version"
+
+// errorMessages maps backend error reasons to user-facing messages.
+// Keyed by reason rather than status text so messages can be translated.
+var errorMessages = map[string]string{
+	iotv1.ReasonDeviceNotFound:     "Device not found",
+	iotv1.ReasonDeviceNoteNotFound: "Note not found",
+	iotv1.ReasonInvalidArgument:    "Invalid request",
+	iotv1.ReasonInvalidPageToken:   "Invalid page token",
+	iotv1.ReasonDatabaseError:      "The backend could not load the requested data",
+	iotv1.ReasonQuotaExceeded:      "API quota exceeded. Please try again later",
+}
+
+// errorMessage returns a user-facing message for a backend error.
+// Field violations are appended so users see which input was rejected.
+// The fallback is used when the error carries no known reason.
+func errorMessage(err error, fallback string) string {
+	msg, ok := errorMessages[iotv1.ErrorReason(err)]
+	switch {
+	case ok:
+	case errorStatus(err) == http.StatusBadGateway:
+		msg = backendUnavailableMessage
+	case errorStatus(err) == http.StatusNotImplemented:
+		msg = unsupportedMessage
+	default:
+		msg = fallback
+	}
+
+	violations := iotv1.FieldViolations(err)
+	if len(violations) == 0 {
+		return msg
+	}
+
+	parts := make([]string, 0, len(violations))
+	for _, v := range violations {
+		parts = append(parts, v.Field+" "+v.Description)
+	}
+
+	return msg + ": " + strings.Join(parts, ", ")
+}
+
+// errorStatus maps a backend error to an HTTP status code.
+func errorStatus(err error) int {
+	st, ok := status.FromError(err)
+	if !ok {
+		return http.StatusInternalServerError
+	}
+
+	switch st.Code() {
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return http.StatusBadGateway
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// errorTitle returns the heading shown on error pages for a status code.
+func errorTitle(statusCode int) string {
+	if statusCode == http.StatusBadGateway {
+		return "Backend Unavailable"
+	}
+
+	return http.StatusText(statusCode)
+}
+
+// isHTMXRequest reports whether r was issued by htmx and expects a fragment.
+func isHTMXRequest(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true"
+}
+
+// renderError writes an HTML error response. htmx requests receive an error
+// fragment that is swapped into the target, full requests a complete page.
+// Clients asking for JSON get the error as JSON.
+func (s *Server) renderError(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	requestID := requestIDFromContext(r.Context())
+
+	if wantsJSON(r) {
+		s.writeJSON(w, r, statusCode, errorResponse{Error: errorJSON{
+			Status:    statusCode,
+			Title:     errorTitle(statusCode),
+			Message:   message,
+			RequestID: requestID,
+		}})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
+
+	var err error
+	if isHTMXRequest(r) {
+		err = renderErrorFragment(r.Context(), w, statusCode, errorTitle(statusCode), message, requestID, s.metrics)
+	} else {
+		err = renderErrorPage(r.Context(), w, statusCode, errorTitle(statusCode), message, requestID, s.metrics)
+	}
+
+	if err != nil {
+		s.logger.Error("failed to render error page", "error", err, "request_id", requestID)
+	}
+}
+
+// fieldErrorAttrs marks a form control as invalid and links it to the message
+// rendered by fieldError or noteFieldError, if the field has an error.
+func fieldErrorAttrs(errs map[string]string, field string) templ.Attributes {
+	if _, ok := errs[field]; !ok {
+		return nil
+	}
+
+	return templ.Attributes{"aria-invalid": "true", "aria-describedby": field + "-error"}
+}
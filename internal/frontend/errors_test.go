@@ -0,0 +1,124 @@
+package frontend
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Backend error mapping", func() {
+	It("should render a message from the error reason", func() {
+		err := iotv1.DeviceNotFoundError("device-001")
+
+		Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonDeviceNotFound))
+		Expect(errorMessage(err, "fallback")).To(Equal("Device not found"))
+		Expect(errorStatus(err)).To(Equal(http.StatusNotFound))
+	})
+
+	It("should expose ErrorInfo metadata", func() {
+		info, ok := iotv1.ErrorInfo(iotv1.DeviceNotFoundError("device-001"))
+		Expect(ok).To(BeTrue())
+		Expect(info.GetDomain()).To(Equal(iotv1.ErrorDomain))
+		Expect(info.GetMetadata()).To(HaveKeyWithValue("device_id", "device-001"))
+	})
+
+	It("should include field violations in the message", func() {
+		err := iotv1.InvalidArgumentError(iotv1.ReasonInvalidPageToken, "page_token", "must be a non-negative offset")
+
+		Expect(iotv1.FieldViolations(err)).To(ConsistOf(iotv1.FieldViolation{
+			Field:       "page_token",
+			Description: "must be a non-negative offset",
+		}))
+		Expect(errorMessage(err, "fallback")).To(Equal("Invalid page token: page_token must be a non-negative offset"))
+		Expect(errorStatus(err)).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should fall back for errors without details", func() {
+		err := status.Error(codes.Internal, "boom")
+
+		Expect(iotv1.ErrorReason(err)).To(BeEmpty())
+		Expect(errorMessage(err, "Failed to fetch devices")).To(Equal("Failed to fetch devices"))
+		Expect(errorStatus(err)).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("should report an unreachable backend as bad gateway", func() {
+		err := status.Error(codes.Unavailable, "connection refused")
+
+		Expect(errorStatus(err)).To(Equal(http.StatusBadGateway))
+		Expect(errorMessage(err, "Failed to fetch devices")).To(Equal(backendUnavailableMessage))
+		Expect(errorTitle(http.StatusBadGateway)).To(Equal("Backend Unavailable"))
+	})
+
+	It("should report RPCs missing from the backend as not implemented", func() {
+		err := status.Error(codes.Unimplemented, "unknown method GetServerInfo")
+
+		Expect(errorStatus(err)).To(Equal(http.StatusNotImplemented))
+		Expect(errorMessage(err, "Failed to fetch devices")).To(Equal(unsupportedMessage))
+	})
+
+	It("should treat non-gRPC errors as internal", func() {
+		err := errors.New("boom")
+
+		Expect(errorMessage(err, "fallback")).To(Equal("fallback"))
+		Expect(errorStatus(err)).To(Equal(http.StatusInternalServerError))
+	})
+})
+
+var _ = Describe("Error pages", func() {
+	var handler http.Handler
+
+	BeforeEach(func() {
+		server := &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: notFoundClient{},
+		}
+		handler = server.setupRoutes()
+	})
+
+	It("should render a full error page with the request ID", func() {
+		req := httptest.NewRequest(http.MethodGet, "/device/missing", nil)
+		req.Header.Set(requestIDHeader, "req-123")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+		Expect(rec.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+		Expect(rec.Header().Get(requestIDHeader)).To(Equal("req-123"))
+		Expect(rec.Body.String()).To(ContainSubstring("<!doctype html>"))
+		Expect(rec.Body.String()).To(ContainSubstring("404 - Not Found"))
+		Expect(rec.Body.String()).To(ContainSubstring("req-123"))
+	})
+
+	It("should render only a fragment for htmx requests", func() {
+		req := httptest.NewRequest(http.MethodGet, "/static/app.js", nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+		Expect(rec.Body.String()).NotTo(ContainSubstring("<html"))
+		Expect(rec.Body.String()).To(ContainSubstring(`role="alert"`))
+	})
+
+	It("should generate a request ID when the header is missing or malformed", func() {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set(requestIDHeader, "<script>")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		id := rec.Header().Get(requestIDHeader)
+		Expect(id).To(HaveLen(32))
+		Expect(id).NotTo(Equal("<script>"))
+	})
+})
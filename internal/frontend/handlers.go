@@ -2,14 +2,9 @@
 package frontend
 
 import (
-	"context"
 	"net/http"
-	"time"
 
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
 )
 
 // handleIndex serves the main index page.
@@ -18,8 +13,8 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 
 	// Render index template
 	if err := renderIndex(r.Context(), w, s.metrics); err != nil {
-		s.logger.Error("failed to render index", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		s.logger.Error("failed to render index", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
 		return
 	}
 }
@@ -29,114 +24,154 @@ func (s *Server) handleDevices(w http.ResponseWriter, r *http.Request) {
 	s.logger.Debug("handling devices request")
 
 	// Fetch devices from backend
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := s.backendContext(r.Context())
 	defer cancel()
 
-	resp, err := s.callGetAllDevice(ctx, &iot.GetAllDevicesRequest{})
+	resp, err := s.callGetAllDevice(ctx, &iotv1.GetAllDevicesRequest{})
 	if err != nil {
-		s.logger.Error("failed to fetch devices", "error", err)
-		http.Error(w, "Failed to fetch devices", http.StatusInternalServerError)
+		s.logger.Error("failed to fetch devices", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to fetch devices"))
 		return
 	}
 
 	// Render devices page
 	if err := renderDevices(r.Context(), w, resp.GetDevices(), s.metrics); err != nil {
-		s.logger.Error("failed to render devices", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		s.logger.Error("failed to render devices", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
 		return
 	}
 }
 
-// handleDevice serves a single device detail page.
+// handleDevice serves a single device detail page. Requests with a page_token
+// show that page of readings instead of the first.
 func (s *Server) handleDevice(w http.ResponseWriter, r *http.Request) {
 	deviceID := r.PathValue("id")
 	s.logger.Debug("handling device request", "device_id", deviceID)
 
+	// The readings controls fall back to links and forms to this page without
+	// JavaScript, so it takes the same query parameters as the readings fragment
+	pageSize, ok := parsePageSize(r)
+	if !ok {
+		s.renderError(w, r, http.StatusBadRequest, "Invalid page size")
+		return
+	}
+	prefs, changed := loadPreferences(r)
+	if changed {
+		savePreferences(w, r, prefs)
+	}
+
 	// Fetch device from backend
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := s.backendContext(r.Context())
 	defer cancel()
 
-	deviceResp, err := s.callGetDevice(ctx, &iot.GetDeviceByIDRequest{
+	deviceResp, err := s.callGetDevice(ctx, &iotv1.GetDeviceByIDRequest{
 		DeviceId: deviceID,
 	})
 	if err != nil {
-		st, ok := status.FromError(err)
-		if ok && st.Code() == codes.NotFound {
-			http.Error(w, "Device not found", http.StatusNotFound)
+		if errorStatus(err) == http.StatusNotFound {
+			s.renderError(w, r, http.StatusNotFound, errorMessage(err, "Device not found"))
 			return
 		}
-		s.logger.Error("failed to fetch device", "error", err, "device_id", deviceID)
-		http.Error(w, "Failed to fetch device", http.StatusInternalServerError)
+		s.logger.Error("failed to fetch device", "error", err, "device_id", deviceID, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to fetch device"))
 		return
 	}
 
-	// Fetch sensor readings for the device
-	readingsResp, err := s.callGetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
-		DeviceId: deviceID,
-	})
+	// Fetch a page of sensor readings for the device
+	page, err := s.fetchReadingsPage(ctx, deviceID, r.URL.Query().Get("page_token"), pageSize, prefs)
 	if err != nil {
-		s.logger.Error("failed to fetch sensor readings", "error", err, "device_id", deviceID)
-		http.Error(w, "Failed to fetch sensor readings", http.StatusInternalServerError)
+		s.logger.Error("failed to fetch sensor readings", "error", err, "device_id", deviceID, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to fetch sensor readings"))
 		return
 	}
 
 	// Render device detail page
-	if err := renderDevice(r.Context(), w, deviceResp.GetDevice(), readingsResp.GetReading(), s.metrics); err != nil {
-		s.logger.Error("failed to render device", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	if err := renderDevice(r.Context(), w, deviceResp.GetDevice(), deviceResp.GetBatteryProjection(), page, s.metrics); err != nil {
+		s.logger.Error("failed to render device", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
 		return
 	}
 }
 
-// handleAPIDevices serves the devices list as HTML fragment for htmx.
+// handleAPIDevices serves the devices list as HTML fragment for htmx, or as
+// JSON when the client asks for it.
 func (s *Server) handleAPIDevices(w http.ResponseWriter, r *http.Request) {
 	s.logger.Debug("handling API devices request")
+	w.Header().Add("Vary", "Accept")
 
 	// Fetch devices from backend
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := s.backendContext(r.Context())
 	defer cancel()
 
-	resp, err := s.callGetAllDevice(ctx, &iot.GetAllDevicesRequest{})
+	resp, err := s.callGetAllDevice(ctx, &iotv1.GetAllDevicesRequest{})
 	if err != nil {
-		s.logger.Error("failed to fetch devices", "error", err)
-		http.Error(w, "Failed to fetch devices", http.StatusInternalServerError)
+		s.logger.Error("failed to fetch devices", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to fetch devices"))
+		return
+	}
+
+	if wantsJSON(r) {
+		s.writeJSON(w, r, http.StatusOK, newDevicesResponse(resp.GetDevices()))
 		return
 	}
 
 	// Render devices list fragment
 	if err := renderDevicesList(r.Context(), w, resp.GetDevices(), s.metrics); err != nil {
-		s.logger.Error("failed to render devices list", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		s.logger.Error("failed to render devices list", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
 		return
 	}
 }
 
 // handleAPIDeviceReadings serves the device readings as HTML fragment for htmx.
+// Requests with a page_token return only the additional table rows so they can be
+// appended below the rows already shown. Clients asking for JSON get the page
+// with its pagination metadata instead.
 func (s *Server) handleAPIDeviceReadings(w http.ResponseWriter, r *http.Request) {
 	deviceID := r.PathValue("id")
 	s.logger.Debug("handling API device readings request", "device_id", deviceID)
+	w.Header().Add("Vary", "Accept")
 
-	// Get page token from query params
+	// Get page token and size from query params
 	pageToken := r.URL.Query().Get("page_token")
+	pageSize, ok := parsePageSize(r)
+	if !ok {
+		s.renderError(w, r, http.StatusBadRequest, "Invalid page size")
+		return
+	}
+
+	// Sort order and units come from the saved preferences; query overrides are persisted
+	// for the dashboard but not for JSON clients
+	prefs, changed := loadPreferences(r)
+	if changed && !wantsJSON(r) {
+		savePreferences(w, r, prefs)
+	}
 
 	// Fetch sensor readings from backend
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := s.backendContext(r.Context())
 	defer cancel()
 
-	resp, err := s.callGetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
-		DeviceId:  deviceID,
-		PageToken: pageToken,
-	})
+	page, err := s.fetchReadingsPage(ctx, deviceID, pageToken, pageSize, prefs)
 	if err != nil {
-		s.logger.Error("failed to fetch sensor readings", "error", err, "device_id", deviceID)
-		http.Error(w, "Failed to fetch sensor readings", http.StatusInternalServerError)
+		s.logger.Error("failed to fetch sensor readings", "error", err, "device_id", deviceID, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to fetch sensor readings"))
 		return
 	}
 
-	// Render readings list fragment
-	if err := renderReadingsList(r.Context(), w, resp.GetReading(), resp.GetNextPageToken(), s.metrics); err != nil {
-		s.logger.Error("failed to render readings list", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	if wantsJSON(r) {
+		s.writeJSON(w, r, http.StatusOK, newReadingsResponse(page))
+		return
+	}
+
+	// Render the next rows or the whole readings list fragment
+	if pageToken != "" {
+		err = renderReadingsRows(r.Context(), w, page, s.metrics)
+	} else {
+		err = renderReadingsList(r.Context(), w, page, s.metrics)
+	}
+	if err != nil {
+		s.logger.Error("failed to render readings list", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
 		return
 	}
 }
@@ -144,14 +179,24 @@ func (s *Server) handleAPIDeviceReadings(w http.ResponseWriter, r *http.Request)
 // handleStatic serves static files.
 func (s *Server) handleStatic(w http.ResponseWriter, r *http.Request) {
 	s.logger.Debug("handling static file request", "path", r.URL.Path)
-	http.Error(w, "Not Found", http.StatusNotFound)
+
+	file, ok := staticFiles[r.URL.Path]
+	if !ok {
+		s.renderError(w, r, http.StatusNotFound, "The requested file does not exist")
+		return
+	}
+
+	w.Header().Set("Content-Type", file.contentType)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	_, _ = w.Write([]byte(file.body))
+}
+
+// healthResponse is the body of /health.
+type healthResponse struct {
+	Status string `json:"status"`
 }
 
 // handleHealth serves health check endpoint.
-func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if _, err := w.Write([]byte(`{"status":"ok"}`)); err != nil {
-		s.logger.Error("failed to write health response", "error", err)
-	}
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, r, http.StatusOK, healthResponse{Status: "ok"})
 }
@@ -0,0 +1,158 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// supportedLanguages are the languages the dashboard is translated to. The
+// first one is the fallback for clients that accept none of them.
+var supportedLanguages = []language.Tag{language.English, language.German}
+
+// languageMatcher picks the supported language closest to a client's preferences.
+var languageMatcher = language.NewMatcher(supportedLanguages)
+
+// messageCatalogs holds the translations of each language other than English,
+// keyed by the English message. English messages are their own translation.
+var messageCatalogs = map[language.Tag]map[string]string{
+	language.German: germanMessages,
+}
+
+// messages is the catalog used by message printers, built from messageCatalogs.
+var messages = newMessageCatalog()
+
+// newMessageCatalog builds the printer catalog from messageCatalogs.
+func newMessageCatalog() catalog.Catalog {
+	builder := catalog.NewBuilder(catalog.Fallback(language.English))
+	for tag, translations := range messageCatalogs {
+		for key, msg := range translations {
+			// Only fails for malformed messages, which the i18n tests catch
+			_ = builder.SetString(tag, key, msg)
+		}
+	}
+	return builder
+}
+
+// locale formats the text of a response in one language.
+type locale struct {
+	tag     language.Tag
+	printer *message.Printer
+	// Go time layouts of a timestamp with seconds, without seconds and a date
+	dateTime string
+	minutes  string
+	date     string
+}
+
+// newLocale returns the locale of tag, which must be a supported language.
+func newLocale(tag language.Tag) *locale {
+	l := &locale{
+		tag:      tag,
+		printer:  message.NewPrinter(tag, message.Catalog(messages)),
+		dateTime: "2006-01-02 15:04:05",
+		minutes:  "2006-01-02 15:04",
+		date:     "2006-01-02",
+	}
+
+	if tag == language.German {
+		l.dateTime = "02.01.2006 15:04:05"
+		l.minutes = "02.01.2006 15:04"
+		l.date = "02.01.2006"
+	}
+
+	return l
+}
+
+// locales are the locales of the supported languages.
+var locales = func() map[language.Tag]*locale {
+	m := make(map[language.Tag]*locale, len(supportedLanguages))
+	for _, tag := range supportedLanguages {
+		m[tag] = newLocale(tag)
+	}
+	return m
+}()
+
+// negotiateLanguage returns the supported language for a request: the
+// explicitly chosen one if valid, otherwise the best match of the
+// Accept-Language header.
+func negotiateLanguage(chosen, acceptLanguage string) language.Tag {
+	for _, tag := range supportedLanguages {
+		if chosen == tag.String() {
+			return tag
+		}
+	}
+
+	// Malformed headers yield no preferences and thus the fallback
+	preferred, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, index, _ := languageMatcher.Match(preferred...)
+	return supportedLanguages[index]
+}
+
+type localeKey struct{}
+
+// localeMiddleware selects the language of each response from the lang
+// preference and the Accept-Language header, and stores its locale in the
+// request context for the templates. A lang query parameter changes the
+// preference.
+func localeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefs, _ := loadPreferences(r)
+		if r.URL.Query().Has("lang") {
+			savePreferences(w, r, prefs)
+		}
+
+		tag := negotiateLanguage(prefs.Lang, r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Language", tag.String())
+		w.Header().Add("Vary", "Accept-Language")
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), localeKey{}, locales[tag])))
+	})
+}
+
+// localeFromContext returns the locale stored in ctx, or English.
+func localeFromContext(ctx context.Context) *locale {
+	if l, ok := ctx.Value(localeKey{}).(*locale); ok {
+		return l
+	}
+	return locales[language.English]
+}
+
+// t translates the English message format to the language of ctx and formats
+// args with it, using the language's number format.
+func t(ctx context.Context, format string, args ...any) string {
+	return localeFromContext(ctx).printer.Sprintf(format, args...)
+}
+
+// translate returns the translation of msg, or msg itself without one. Unlike
+// t it takes messages that are not format strings, such as error messages.
+func translate(ctx context.Context, msg string) string {
+	if translated, ok := messageCatalogs[localeFromContext(ctx).tag][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// lang returns the language of ctx for the lang attribute of pages.
+func lang(ctx context.Context) string {
+	return localeFromContext(ctx).tag.String()
+}
+
+// formatDateTime formats a Unix timestamp as date and time in the language and
+// time zone of ctx.
+func formatDateTime(ctx context.Context, unix int64) string {
+	return inTimezone(ctx, unix).Format(localeFromContext(ctx).dateTime)
+}
+
+// formatMinutes formats a Unix timestamp as date and time without seconds.
+func formatMinutes(ctx context.Context, unix int64) string {
+	return inTimezone(ctx, unix).Format(localeFromContext(ctx).minutes)
+}
+
+// formatDate formats a time as a date in the language and time zone of ctx.
+func formatDate(ctx context.Context, at time.Time) string {
+	return at.In(timezoneFromContext(ctx)).Format(localeFromContext(ctx).date)
+}
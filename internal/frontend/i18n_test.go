@@ -0,0 +1,108 @@
+package frontend
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/text/language"
+)
+
+var _ = Describe("Localization", func() {
+	Describe("negotiateLanguage", func() {
+		It("should match regional variants to the supported language", func() {
+			Expect(negotiateLanguage("", "de-AT,de;q=0.9,en;q=0.5")).To(Equal(language.German))
+		})
+
+		It("should fall back to English for unsupported languages", func() {
+			Expect(negotiateLanguage("", "fr-FR,fr;q=0.9")).To(Equal(language.English))
+			Expect(negotiateLanguage("", "")).To(Equal(language.English))
+			Expect(negotiateLanguage("", ";;;")).To(Equal(language.English))
+		})
+
+		It("should prefer the chosen language over Accept-Language", func() {
+			Expect(negotiateLanguage("en", "de-DE")).To(Equal(language.English))
+			Expect(negotiateLanguage("klingon", "de-DE")).To(Equal(language.German))
+		})
+	})
+
+	Describe("German messages", func() {
+		verbs := regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z%]`)
+
+		It("should keep the format verbs of their English message", func() {
+			for key, msg := range germanMessages {
+				Expect(verbs.FindAllString(msg, -1)).To(Equal(verbs.FindAllString(key, -1)), key)
+			}
+		})
+
+		It("should format numbers with a decimal comma", func() {
+			ctx := context.WithValue(context.Background(), localeKey{}, locales[language.German])
+
+			Expect(t(ctx, "%.2f %%/day", 1.5)).To(Equal("1,50 %/Tag"))
+			Expect(translate(ctx, "Device not found")).To(Equal("Gerät nicht gefunden"))
+			Expect(translate(ctx, "untranslated")).To(Equal("untranslated"))
+		})
+	})
+
+	Describe("localeMiddleware", func() {
+		var handler http.Handler
+
+		BeforeEach(func() {
+			server := &Server{
+				logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+					Level: slog.LevelError,
+				})),
+			}
+			handler = server.setupRoutes()
+		})
+
+		get := func(target string, header http.Header) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			for name, values := range header {
+				req.Header[name] = values
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			return rec
+		}
+
+		It("should render English by default", func() {
+			rec := get("/", nil)
+
+			Expect(rec.Header().Get("Content-Language")).To(Equal("en"))
+			Expect(rec.Body.String()).To(ContainSubstring(`<html lang="en">`))
+			Expect(rec.Body.String()).To(ContainSubstring("View Devices"))
+		})
+
+		It("should render the language of Accept-Language", func() {
+			rec := get("/", http.Header{"Accept-Language": {"de-DE,de;q=0.9"}})
+
+			Expect(rec.Header().Get("Content-Language")).To(Equal("de"))
+			Expect(rec.Header().Values("Vary")).To(ContainElement("Accept-Language"))
+			Expect(rec.Body.String()).To(ContainSubstring(`<html lang="de">`))
+			Expect(rec.Body.String()).To(ContainSubstring("Geräte anzeigen"))
+		})
+
+		It("should remember a chosen language", func() {
+			rec := get("/?lang=de", nil)
+
+			Expect(rec.Header().Get("Content-Language")).To(Equal("de"))
+			cookie := slices.IndexFunc(rec.Result().Cookies(), func(c *http.Cookie) bool {
+				return c.Name == preferencesCookie
+			})
+			Expect(cookie).NotTo(Equal(-1))
+
+			next := get("/", http.Header{
+				"Accept-Language": {"en-US"},
+				"Cookie":          {rec.Result().Cookies()[cookie].String()},
+			})
+			Expect(next.Header().Get("Content-Language")).To(Equal("de"))
+		})
+	})
+})
@@ -0,0 +1,112 @@
+package frontend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Defaults for zero TimeoutConfig fields and request size limits.
+const (
+	defaultReadHeaderTimeout  = 10 * time.Second
+	defaultReadTimeout        = 30 * time.Second
+	defaultWriteTimeout       = 30 * time.Second
+	defaultIdleTimeout        = 120 * time.Second
+	defaultBackendCallTimeout = 5 * time.Second
+	defaultMaxHeaderBytes     = http.DefaultMaxHeaderBytes
+	defaultMaxBodyBytes       = 1 << 20
+)
+
+// requestBudgetMargin is kept from the write timeout for rendering an error
+// page after backend calls ran out of time.
+const requestBudgetMargin = time.Second
+
+// TimeoutConfig tunes the timeouts of the HTTP server and of backend calls,
+// e.g. to stay below the timeouts of a reverse proxy. A zero field uses the
+// default.
+type TimeoutConfig struct {
+	// ReadHeader is how long a client may take to send the request headers
+	// (default 10 seconds).
+	ReadHeader time.Duration
+	// Read is how long a client may take to send the whole request (default
+	// 30 seconds).
+	Read time.Duration
+	// Write is how long a handler may take to write its response (default 30
+	// seconds). All backend calls of a request must finish a second before.
+	Write time.Duration
+	// Idle is how long keep-alive connections wait for the next request
+	// (default 2 minutes).
+	Idle time.Duration
+	// BackendCall bounds each backend call (default 5 seconds).
+	BackendCall time.Duration
+}
+
+// validate reports whether all timeouts are non-negative and the write
+// timeout leaves time for backend calls.
+func (c TimeoutConfig) validate() error {
+	for _, d := range []time.Duration{c.ReadHeader, c.Read, c.Write, c.Idle, c.BackendCall} {
+		if d < 0 {
+			return errors.New("HTTP and backend call timeouts cannot be negative")
+		}
+	}
+
+	if c.Write != 0 && c.Write <= requestBudgetMargin {
+		return errors.New("HTTP write timeout must be longer than 1s")
+	}
+
+	return nil
+}
+
+// withDefaults returns c with zero fields set to their defaults.
+func (c TimeoutConfig) withDefaults() TimeoutConfig {
+	for _, f := range []struct {
+		value *time.Duration
+		def   time.Duration
+	}{
+		{&c.ReadHeader, defaultReadHeaderTimeout},
+		{&c.Read, defaultReadTimeout},
+		{&c.Write, defaultWriteTimeout},
+		{&c.Idle, defaultIdleTimeout},
+		{&c.BackendCall, defaultBackendCallTimeout},
+	} {
+		if *f.value == 0 {
+			*f.value = f.def
+		}
+	}
+	return c
+}
+
+// backendContext returns the context for a backend call made while serving
+// a request with context ctx.
+func (s *Server) backendContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, s.timeouts.withDefaults().BackendCall)
+}
+
+// requestBudgetMiddleware gives the context of every request a deadline of
+// budget, so backend calls give up while the response can still be written.
+// gRPC sends the remaining budget to the backend as the call deadline, so the
+// backend stops working on requests the frontend no longer waits for.
+func requestBudgetMiddleware(budget time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), budget)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// maxBodyMiddleware answers requests announcing a body larger than limit
+// bytes with 413 and stops reading other bodies after limit bytes, so form
+// parsing fails instead of buffering them.
+func (s *Server) maxBodyMiddleware(limit int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			s.renderError(w, r, http.StatusRequestEntityTooLarge, "Request too large")
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,104 @@
+package frontend
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Request limits", func() {
+	Describe("TimeoutConfig", func() {
+		It("should fill in the defaults", func() {
+			timeouts := TimeoutConfig{Write: time.Minute}.withDefaults()
+			Expect(timeouts.Write).To(Equal(time.Minute))
+			Expect(timeouts.Read).To(Equal(defaultReadTimeout))
+			Expect(timeouts.BackendCall).To(Equal(defaultBackendCallTimeout))
+		})
+
+		It("should bound backend calls by the configured timeout", func() {
+			server := &Server{timeouts: TimeoutConfig{BackendCall: 2 * time.Second}}
+			ctx, cancel := server.backendContext(context.Background())
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(time.Until(deadline)).To(BeNumerically("~", 2*time.Second, time.Second))
+		})
+	})
+
+	Describe("request budget", func() {
+		It("should give requests a deadline of the budget", func() {
+			var left time.Duration
+			handler := requestBudgetMiddleware(20*time.Second, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				deadline, ok := r.Context().Deadline()
+				Expect(ok).To(BeTrue())
+				left = time.Until(deadline)
+			}))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/devices", nil))
+			Expect(left).To(BeNumerically("~", 20*time.Second, time.Second))
+		})
+
+		It("should shorten the timeout of backend calls once the budget is spent", func() {
+			var err error
+			server := &Server{}
+			handler := requestBudgetMiddleware(time.Millisecond, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				ctx, cancel := server.backendContext(r.Context())
+				defer cancel()
+
+				<-ctx.Done()
+				err = ctx.Err()
+			}))
+
+			start := time.Now()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/devices", nil))
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		})
+	})
+
+	Describe("body limit", func() {
+		var handler http.Handler
+
+		BeforeEach(func() {
+			server := &Server{
+				logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+			}
+			handler = server.maxBodyMiddleware(16, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+				}
+			}))
+		})
+
+		post := func(body string, chunked bool) int {
+			req := httptest.NewRequest(http.MethodPost, "/device/a/notes", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		It("should pass small bodies", func() {
+			Expect(post("body=ok", false)).To(Equal(http.StatusOK))
+		})
+
+		It("should reject announced large bodies with 413", func() {
+			Expect(post("body="+strings.Repeat("x", 32), false)).To(Equal(http.StatusRequestEntityTooLarge))
+		})
+
+		It("should stop reading unannounced large bodies", func() {
+			Expect(post("body="+strings.Repeat("x", 32), true)).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
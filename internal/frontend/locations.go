@@ -0,0 +1,113 @@
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// Map dimensions in SVG user units.
+const (
+	mapWidth   = 600
+	mapHeight  = 300
+	mapPadding = 20
+)
+
+// locationPanel is the view model of the movement panel on the device page.
+type locationPanel struct {
+	DeviceID  string
+	Locations []*iotv1.DeviceLocation
+	// Points are the locations projected onto the map, in the same order.
+	Points []mapPoint
+}
+
+// mapPoint is a location in SVG user units.
+type mapPoint struct {
+	X float64
+	Y float64
+}
+
+// Path returns the points as the points attribute of an SVG polyline.
+func (p locationPanel) Path() string {
+	coords := make([]string, len(p.Points))
+	for i, point := range p.Points {
+		coords[i] = fmt.Sprintf("%.1f,%.1f", point.X, point.Y)
+	}
+	return strings.Join(coords, " ")
+}
+
+// handleAPIDeviceLocations serves the movement panel as HTML fragment for htmx.
+func (s *Server) handleAPIDeviceLocations(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.PathValue("id")
+
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	resp, err := trackedCall(s, ctx, "GetDeviceLocationHistory", s.grpcClient.GetDeviceLocationHistory,
+		&iotv1.GetDeviceLocationHistoryRequest{DeviceId: deviceID})
+	if err != nil {
+		s.logger.Error("failed to fetch device location history", "error", err, "device_id", deviceID, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to fetch location history"))
+		return
+	}
+
+	panel := locationPanel{
+		DeviceID:  deviceID,
+		Locations: resp.GetLocations(),
+		Points:    projectLocations(resp.GetLocations()),
+	}
+
+	if err := renderDeviceLocations(r.Context(), w, panel, s.metrics); err != nil {
+		s.logger.Error("failed to render device locations", "error", err, "request_id", requestIDFromContext(r.Context()))
+	}
+}
+
+// projectLocations maps locations onto the map so that their bounding box
+// fills it. Longitudes are scaled by the cosine of the mean latitude, which
+// keeps distances in proportion over the small areas devices move in.
+func projectLocations(locations []*iotv1.DeviceLocation) []mapPoint {
+	if len(locations) == 0 {
+		return nil
+	}
+
+	var meanLat float64
+	for _, l := range locations {
+		meanLat += float64(l.GetLatitude())
+	}
+	scaleX := math.Cos(meanLat / float64(len(locations)) * math.Pi / 180)
+
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, l := range locations {
+		x, y := float64(l.GetLongitude())*scaleX, float64(l.GetLatitude())
+		minX, maxX = min(minX, x), max(maxX, x)
+		minY, maxY = min(minY, y), max(maxY, y)
+	}
+
+	// One scale for both axes so the path is not distorted; a device that
+	// never moved is drawn in the center
+	scale := 0.0
+	if spanX, spanY := maxX-minX, maxY-minY; spanX > 0 || spanY > 0 {
+		scale = min((mapWidth-2*mapPadding)/spanX, (mapHeight-2*mapPadding)/spanY)
+	}
+	centerX, centerY := (minX+maxX)/2, (minY+maxY)/2
+
+	points := make([]mapPoint, len(locations))
+	for i, l := range locations {
+		x, y := float64(l.GetLongitude())*scaleX, float64(l.GetLatitude())
+		points[i] = mapPoint{
+			X: mapWidth/2 + (x-centerX)*scale,
+			Y: mapHeight/2 - (y-centerY)*scale,
+		}
+	}
+	return points
+}
+
+// locationLabel describes a location for the tooltip of its map marker.
+func locationLabel(ctx context.Context, l *iotv1.DeviceLocation) string {
+	return formatMinutes(ctx, l.GetTimestamp()) + ": " + t(ctx, "%.4f, %.4f", l.GetLatitude(), l.GetLongitude())
+}
@@ -0,0 +1,95 @@
+package frontend
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// locationsClient is an IoTServiceClient stub returning a fixed location history.
+type locationsClient struct {
+	iotv1.IoTServiceClient
+	locations []*iotv1.DeviceLocation
+}
+
+func (c *locationsClient) GetDeviceLocationHistory(_ context.Context, req *iotv1.GetDeviceLocationHistoryRequest, _ ...grpc.CallOption) (*iotv1.GetDeviceLocationHistoryResponse, error) {
+	if req.GetDeviceId() != "sensor-1" {
+		return nil, iotv1.DeviceNotFoundError(req.GetDeviceId())
+	}
+	return &iotv1.GetDeviceLocationHistoryResponse{Locations: c.locations}, nil
+}
+
+var _ = Describe("Device movement panel", func() {
+	var (
+		server  *Server
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		server = &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: &locationsClient{locations: []*iotv1.DeviceLocation{
+				{Latitude: 52.52, Longitude: 13.40, IpAddress: "10.0.0.1", Timestamp: 1700000000},
+				{Latitude: 52.53, Longitude: 13.42, IpAddress: "10.0.0.2", Timestamp: 1700003600},
+			}},
+		}
+		server.backendInfo.set(&iotv1.GetServerInfoResponse{Capabilities: []string{iotv1.CapabilityLocationHistory}})
+		handler = server.setupRoutes()
+	})
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	It("should draw the path and list the locations", func() {
+		rec := get("/api/device/sensor-1/locations")
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring("<polyline"))
+		Expect(rec.Body.String()).To(ContainSubstring("10.0.0.2"))
+		Expect(rec.Body.String()).To(ContainSubstring("52.5300, 13.4200"))
+	})
+
+	It("should report unknown devices", func() {
+		Expect(get("/api/device/missing/locations").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should answer with 501 when the backend lacks location history", func() {
+		server.backendInfo.set(&iotv1.GetServerInfoResponse{Capabilities: []string{iotv1.CapabilityDeviceNotes}})
+
+		Expect(get("/api/device/sensor-1/locations").Code).To(Equal(http.StatusNotImplemented))
+	})
+
+	Describe("projectLocations", func() {
+		It("should fit the path into the map without distorting it", func() {
+			points := projectLocations([]*iotv1.DeviceLocation{
+				{Latitude: 0, Longitude: 0},
+				{Latitude: 1, Longitude: 1},
+			})
+
+			Expect(points).To(HaveLen(2))
+			// The path is square, so the height bounds it
+			Expect(points[0].Y).To(BeNumerically("~", mapHeight-mapPadding, 0.01))
+			Expect(points[1].Y).To(BeNumerically("~", mapPadding, 0.01))
+			Expect(points[1].X - points[0].X).To(BeNumerically("~", points[0].Y-points[1].Y, 0.1))
+		})
+
+		It("should center a device that never moved", func() {
+			points := projectLocations([]*iotv1.DeviceLocation{{Latitude: 52.52, Longitude: 13.40}})
+
+			Expect(points).To(ConsistOf(mapPoint{X: mapWidth / 2, Y: mapHeight / 2}))
+		})
+	})
+})
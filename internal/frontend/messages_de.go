@@ -0,0 +1,237 @@
+package frontend
+
+// germanMessages are the German translations of the dashboard, keyed by the
+// English message. Format strings must keep the verbs of their key.
+var germanMessages = map[string]string{
+	// Layout and navigation
+	"IoT Dashboard":                   "IoT-Dashboard",
+	"Home":                            "Start",
+	"Devices":                         "Geräte",
+	"Compare":                         "Vergleich",
+	"Battery":                         "Batterie",
+	"Alerts":                          "Alarme",
+	"Trash":                           "Papierkorb",
+	"Request ID:":                     "Anfrage-ID:",
+	"Skip to content":                 "Zum Inhalt springen",
+	"Main navigation":                 "Hauptnavigation",
+	"This panel requires JavaScript.": "Dieser Bereich benötigt JavaScript.",
+	"Actions":                         "Aktionen",
+	"Offline: showing data from %s.":  "Offline: Daten vom %s.",
+
+	// Offline page
+	"Offline":         "Offline",
+	"You are offline": "Sie sind offline",
+	"This page has not been cached yet. Pages you visited before are available offline.": "Diese Seite wurde noch nicht zwischengespeichert. Bereits besuchte Seiten sind offline verfügbar.",
+
+	// Index page
+	"Welcome to IoT Dashboard":                         "Willkommen im IoT-Dashboard",
+	"Monitor and manage your IoT devices in real-time": "Überwachen und verwalten Sie Ihre IoT-Geräte in Echtzeit",
+	"View Devices":                         "Geräte anzeigen",
+	"Features":                             "Funktionen",
+	"Real-time device monitoring":          "Geräteüberwachung in Echtzeit",
+	"Historical sensor data visualization": "Darstellung historischer Sensordaten",
+	"Device management and configuration":  "Geräteverwaltung und -konfiguration",
+	"Automatic updates with htmx":          "Automatische Aktualisierung mit htmx",
+
+	// Devices
+	"All Devices":       "Alle Geräte",
+	"Total devices: %d": "Geräte insgesamt: %d",
+	"Location:":         "Standort:",
+	"MAC Address:":      "MAC-Adresse:",
+	"IP Address:":       "IP-Adresse:",
+	"Firmware:":         "Firmware:",
+	"Last Seen:":        "Zuletzt gesehen:",
+	"Coordinates:":      "Koordinaten:",
+	"%.4f, %.4f":        "%.4f; %.4f",
+	"No devices found. Devices will appear here once they start sending data.": "Keine Geräte gefunden. Geräte erscheinen hier, sobald sie Daten senden.",
+	"Battery Drain:":                 "Batterieverbrauch:",
+	"Projected Empty:":               "Voraussichtlich leer:",
+	"Sensor Readings":                "Messwerte",
+	"Movement":                       "Bewegung",
+	"Maintenance History":            "Wartungsverlauf",
+	"Back to Devices":                "Zurück zu den Geräten",
+	"Move to Trash":                  "In den Papierkorb",
+	"Move this device to the trash?": "Dieses Gerät in den Papierkorb verschieben?",
+
+	// Readings
+	"Total readings: %d": "Messwerte insgesamt: %d",
+	"Page size":          "Seitengröße",
+	"Temperature":        "Temperatur",
+	"Humidity":           "Luftfeuchtigkeit",
+	"Pressure":           "Luftdruck",
+	"Timestamp (%s)":     "Zeitpunkt (%s)",
+	"Temperature (%s)":   "Temperatur (%s)",
+	"Temperature (°C)":   "Temperatur (°C)",
+	"Temperature (°F)":   "Temperatur (°F)",
+	"Humidity (%)":       "Luftfeuchtigkeit (%)",
+	"Pressure (%s)":      "Luftdruck (%s)",
+	"Pressure (hPa)":     "Luftdruck (hPa)",
+	"Pressure (inHg)":    "Luftdruck (inHg)",
+	"Battery (%)":        "Batterie (%)",
+	"Load more":          "Mehr laden",
+	"First page":         "Erste Seite",
+	"Apply":              "Anwenden",
+	"Temperature unit":   "Temperatureinheit",
+	"Pressure unit":      "Druckeinheit",
+	"No sensor readings found for this device.": "Für dieses Gerät wurden keine Messwerte gefunden.",
+
+	// Maintenance notes
+	"Technician":                "Techniker",
+	"Note":                      "Notiz",
+	"Attachment URL (optional)": "Anhang-URL (optional)",
+	"Add Note":                  "Notiz hinzufügen",
+	"Delete":                    "Löschen",
+	"Delete this note?":         "Diese Notiz löschen?",
+	"Attachment":                "Anhang",
+	"No maintenance notes yet.": "Noch keine Wartungsnotizen.",
+
+	// Location history
+	"Path of the device":       "Weg des Geräts",
+	"Since":                    "Seit",
+	"Coordinates":              "Koordinaten",
+	"IP Address":               "IP-Adresse",
+	"No location history yet.": "Noch kein Standortverlauf.",
+
+	// Comparison
+	"Compare Devices": "Geräte vergleichen",
+	"Window":          "Zeitraum",
+	"Enter up to %d comma-separated device IDs.": "Geben Sie bis zu %d durch Kommas getrennte Geräte-IDs ein.",
+	"Statistics":                "Statistik",
+	"Device":                    "Gerät",
+	"Readings":                  "Messwerte",
+	"%s min / avg / max / last": "%s Min. / Mittel / Max. / Letzter",
+
+	// Battery report
+	"Battery Report":     "Batteriebericht",
+	"Low Battery Report": "Bericht zu schwachen Batterien",
+	"Empty within":       "Leer innerhalb von",
+	"%d days":            "%d Tagen",
+	"Device ID":          "Geräte-ID",
+	"Location":           "Standort",
+	"Drain":              "Verbrauch",
+	"Projected Empty":    "Voraussichtlich leer",
+	"No devices are projected to run out of battery within %d days.": "Bei keinem Gerät wird die Batterie voraussichtlich innerhalb von %d Tagen leer.",
+	"Not draining":    "Kein Verbrauch",
+	"Empty since %s":  "Leer seit %s",
+	"%s (in 1 day)":   "%s (in 1 Tag)",
+	"%s (in %d days)": "%s (in %d Tagen)",
+	"%.2f %%/day":     "%.2f %%/Tag",
+
+	// Relative times
+	"just now":       "gerade eben",
+	"1 minute ago":   "vor 1 Minute",
+	"%d minutes ago": "vor %d Minuten",
+	"1 hour ago":     "vor 1 Stunde",
+	"%d hours ago":   "vor %d Stunden",
+	"1 day ago":      "vor 1 Tag",
+	"%d days ago":    "vor %d Tagen",
+
+	// Embedded panels
+	"Temperature trend": "Temperaturverlauf",
+	"No readings yet.":  "Noch keine Messwerte.",
+	"Open in dashboard": "Im Dashboard öffnen",
+
+	// Status page
+	"All systems operational":            "Alle Systeme betriebsbereit",
+	"Some systems are down":              "Einige Systeme sind ausgefallen",
+	"Active devices":                     "Aktive Geräte",
+	"Readings per minute":                "Messwerte pro Minute",
+	"Components":                         "Komponenten",
+	"Operational":                        "Betriebsbereit",
+	"Down":                               "Ausgefallen",
+	"Backend API":                        "Backend-API",
+	"Database":                           "Datenbank",
+	"Message queue":                      "Nachrichtenwarteschlange",
+	"Ingestion":                          "Datenaufnahme",
+	"Checked %s":                         "Geprüft am %s",
+	"%s: no data":                        "%s: keine Daten",
+	"%s: %.2f %% uptime":                 "%s: %.2f %% Verfügbarkeit",
+	"%.3f %% uptime in the last %d days": "%.3f %% Verfügbarkeit in den letzten %d Tagen",
+
+	// Trash
+	"Deleted devices keep their readings and can be restored at any time.": "Gelöschte Geräte behalten ihre Messwerte und können jederzeit wiederhergestellt werden.",
+	"Deleted":             "Gelöscht",
+	"Restore":             "Wiederherstellen",
+	"The trash is empty.": "Der Papierkorb ist leer.",
+
+	// Alert rules
+	"Alert Rules":                       "Alarmregeln",
+	"New Rule":                          "Neue Regel",
+	"New Alert Rule":                    "Neue Alarmregel",
+	"Edit Alert Rule":                   "Alarmregel bearbeiten",
+	"Name":                              "Name",
+	"Condition":                         "Bedingung",
+	"Status":                            "Status",
+	"Silenced":                          "Stummgeschaltet",
+	"All devices":                       "Alle Geräte",
+	"Enabled":                           "Aktiv",
+	"Disabled":                          "Inaktiv",
+	"Edit":                              "Bearbeiten",
+	"Delete this alert rule?":           "Diese Alarmregel löschen?",
+	"No alert rules defined yet.":       "Noch keine Alarmregeln definiert.",
+	"Metric":                            "Messgröße",
+	"greater than":                      "größer als",
+	"less than":                         "kleiner als",
+	"Threshold":                         "Schwellwert",
+	"Device ID (empty for all devices)": "Geräte-ID (leer für alle Geräte)",
+	"Silence from (%s)":                 "Stumm ab (%s)",
+	"Silence until (%s)":                "Stumm bis (%s)",
+	"Save":                              "Speichern",
+	"Cancel":                            "Abbrechen",
+
+	// Quota banner
+	"%s quota exhausted (%d of %d per %s)": "Kontingent für %s erschöpft (%d von %d pro %s)",
+	"%s: %d of %d per %s used":             "%s: %d von %d pro %s verbraucht",
+	"API requests":                         "API-Anfragen",
+	"Exported readings":                    "exportierte Messwerte",
+	"minute":                               "Minute",
+	"day":                                  "Tag",
+
+	// Error titles and messages
+	"Bad Request":                    "Ungültige Anfrage",
+	"Forbidden":                      "Verboten",
+	"Not Found":                      "Nicht gefunden",
+	"Too Many Requests":              "Zu viele Anfragen",
+	"Internal Server Error":          "Interner Serverfehler",
+	"Not Implemented":                "Nicht unterstützt",
+	"Backend Unavailable":            "Backend nicht erreichbar",
+	"Request Entity Too Large":       "Anfrage zu groß",
+	genericErrorMessage:              "Bei der Verarbeitung Ihrer Anfrage ist ein Fehler aufgetreten",
+	backendUnavailableMessage:        "Der Backend-Dienst ist derzeit nicht erreichbar. Bitte versuchen Sie es gleich noch einmal",
+	unsupportedMessage:               "Diese Funktion wird von der verbundenen Backend-Version nicht unterstützt",
+	"Device not found":               "Gerät nicht gefunden",
+	"Note not found":                 "Notiz nicht gefunden",
+	"Alert rule not found":           "Alarmregel nicht gefunden",
+	"Invalid request":                "Ungültige Anfrage",
+	"Request too large":              "Anfrage zu groß",
+	"Error":                          "Fehler",
+	"Invalid or expired embed token": "Ungültiges oder abgelaufenes Einbettungs-Token",
+	"Invalid page token":             "Ungültiges Seiten-Token",
+	"Invalid page size":              "Ungültige Seitengröße",
+	"Invalid form submission":        "Ungültige Formulardaten",
+	"Invalid comparison window":      "Ungültiger Vergleichszeitraum",
+	"Invalid report horizon":         "Ungültiger Berichtszeitraum",
+	"The backend could not load the requested data": "Das Backend konnte die angeforderten Daten nicht laden",
+	"API quota exceeded. Please try again later":    "API-Kontingent überschritten. Bitte versuchen Sie es später erneut",
+	"The requested file does not exist":             "Die angeforderte Datei existiert nicht",
+	"Failed to fetch devices":                       "Geräte konnten nicht geladen werden",
+	"Failed to fetch device":                        "Gerät konnte nicht geladen werden",
+	"Failed to fetch deleted devices":               "Gelöschte Geräte konnten nicht geladen werden",
+	"Failed to fetch sensor readings":               "Messwerte konnten nicht geladen werden",
+	"Failed to fetch notes":                         "Notizen konnten nicht geladen werden",
+	"Failed to fetch location history":              "Standortverlauf konnte nicht geladen werden",
+	"Failed to fetch battery report":                "Batteriebericht konnte nicht geladen werden",
+	"Failed to fetch alert rules":                   "Alarmregeln konnten nicht geladen werden",
+	"Failed to fetch alert rule":                    "Alarmregel konnte nicht geladen werden",
+	"Failed to save note":                           "Notiz konnte nicht gespeichert werden",
+	"Failed to save alert rule":                     "Alarmregel konnte nicht gespeichert werden",
+	"Failed to delete note":                         "Notiz konnte nicht gelöscht werden",
+	"Failed to delete alert rule":                   "Alarmregel konnte nicht gelöscht werden",
+	"Failed to delete device":                       "Gerät konnte nicht gelöscht werden",
+	"Failed to restore device":                      "Gerät konnte nicht wiederhergestellt werden",
+
+	// Field errors
+	"cannot be empty":         "darf nicht leer sein",
+	"must be a number":        "muss eine Zahl sein",
+	"must be a date and time": "muss ein Datum mit Uhrzeit sein",
+}
@@ -0,0 +1,146 @@
+package frontend
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// Names of the built-in middleware of the HTTP server, in chain order.
+const (
+	// MiddlewareRequestID assigns every request an ID.
+	MiddlewareRequestID = "request_id"
+	// MiddlewareSecurityHeaders sets headers hardening every response.
+	MiddlewareSecurityHeaders = "security_headers"
+	// MiddlewareMetrics records request metrics; only with ServerConfig.Metrics.
+	MiddlewareMetrics = "metrics"
+	// MiddlewareRecovery turns panics into 500 responses. It comes after
+	// metrics so recovered panics are counted as 500s.
+	MiddlewareRecovery = "recovery"
+	// MiddlewareRequestBudget gives all backend calls of a request one deadline.
+	MiddlewareRequestBudget = "request_budget"
+	// MiddlewareCORS adds CORS headers and answers preflights before a session
+	// is created for them; only with ServerConfig.CORS.
+	MiddlewareCORS = "cors"
+	// MiddlewareSessions loads the session; only with ServerConfig.Sessions.
+	MiddlewareSessions = "sessions"
+	// MiddlewareTimezone selects the time zone of each response.
+	MiddlewareTimezone = "timezone"
+	// MiddlewareLocale selects the language of each response.
+	MiddlewareLocale = "locale"
+	// MiddlewareMaxBody limits request bodies before CSRF checks parse forms.
+	MiddlewareMaxBody = "max_body"
+	// MiddlewareCSRF checks CSRF tokens of unsafe requests.
+	MiddlewareCSRF = "csrf"
+)
+
+// Middleware is a named http.Handler wrapper in the chain of the HTTP server.
+// The first middleware of a chain is the outermost one: it sees a request
+// first and its response last.
+type Middleware struct {
+	// Name identifies the middleware in the chain, e.g. for InsertBefore.
+	Name string
+	// Wrap returns a handler calling next.
+	Wrap func(next http.Handler) http.Handler
+}
+
+// MiddlewareChain customizes the middleware chain of the HTTP server. It gets
+// the built-in chain and returns the chain to install, e.g. with
+// InsertAfter(chain, MiddlewareSecurityHeaders, headers) to add company
+// headers to every response, or with middleware removed by slices.DeleteFunc.
+type MiddlewareChain func(chain []Middleware) []Middleware
+
+// InsertBefore returns chain with middleware inserted before the middleware
+// named name, or at the end if the chain has no such middleware.
+func InsertBefore(chain []Middleware, name string, middleware ...Middleware) []Middleware {
+	i := slices.IndexFunc(chain, func(m Middleware) bool { return m.Name == name })
+	if i < 0 {
+		i = len(chain)
+	}
+	return slices.Insert(slices.Clone(chain), i, middleware...)
+}
+
+// InsertAfter returns chain with middleware inserted after the middleware
+// named name, or at the end if the chain has no such middleware.
+func InsertAfter(chain []Middleware, name string, middleware ...Middleware) []Middleware {
+	i := slices.IndexFunc(chain, func(m Middleware) bool { return m.Name == name })
+	if i < 0 {
+		i = len(chain) - 1
+	}
+	return slices.Insert(slices.Clone(chain), i+1, middleware...)
+}
+
+// middlewareChain returns the middleware chain of the HTTP server in front of
+// mux: the built-in middleware, customized by ServerConfig.Middleware.
+func (s *Server) middlewareChain(mux *http.ServeMux) []Middleware {
+	chain := []Middleware{
+		{Name: MiddlewareRequestID, Wrap: requestIDMiddleware},
+		{Name: MiddlewareSecurityHeaders, Wrap: securityHeadersMiddleware},
+	}
+	if s.metrics != nil {
+		chain = append(chain, Middleware{Name: MiddlewareMetrics, Wrap: func(next http.Handler) http.Handler {
+			return s.metricsMiddleware(mux, next)
+		}})
+	}
+
+	maxBody := s.maxBody
+	if maxBody == 0 {
+		maxBody = defaultMaxBodyBytes
+	}
+	budget := s.timeouts.withDefaults().Write - requestBudgetMargin
+
+	chain = append(chain,
+		Middleware{Name: MiddlewareRecovery, Wrap: s.recoveryMiddleware},
+		Middleware{Name: MiddlewareRequestBudget, Wrap: func(next http.Handler) http.Handler {
+			return requestBudgetMiddleware(budget, next)
+		}},
+	)
+	if s.cors != nil {
+		chain = append(chain, Middleware{Name: MiddlewareCORS, Wrap: s.corsMiddleware})
+	}
+	// CSRF checks and the language preference use the session, so sessions
+	// are loaded first
+	if s.sessions != nil {
+		chain = append(chain, Middleware{Name: MiddlewareSessions, Wrap: s.sessions.Middleware})
+	}
+	chain = append(chain,
+		Middleware{Name: MiddlewareTimezone, Wrap: timezoneMiddleware},
+		Middleware{Name: MiddlewareLocale, Wrap: localeMiddleware},
+		Middleware{Name: MiddlewareMaxBody, Wrap: func(next http.Handler) http.Handler {
+			return s.maxBodyMiddleware(maxBody, next)
+		}},
+		Middleware{Name: MiddlewareCSRF, Wrap: s.csrfMiddleware},
+	)
+
+	if s.middleware == nil {
+		return chain
+	}
+	return s.middleware(chain)
+}
+
+// validateMiddleware checks that every middleware of chain has a unique name
+// and a wrapper.
+func validateMiddleware(chain []Middleware) error {
+	seen := make(map[string]bool, len(chain))
+	for i, m := range chain {
+		switch {
+		case m.Name == "":
+			return fmt.Errorf("middleware %d has no name", i)
+		case m.Wrap == nil:
+			return fmt.Errorf("middleware %q is nil", m.Name)
+		case seen[m.Name]:
+			return fmt.Errorf("middleware %q is in the chain twice", m.Name)
+		}
+		seen[m.Name] = true
+	}
+	return nil
+}
+
+// middlewareNames returns the names of the middleware of chain in order.
+func middlewareNames(chain []Middleware) []string {
+	names := make([]string, len(chain))
+	for i, m := range chain {
+		names[i] = m.Name
+	}
+	return names
+}
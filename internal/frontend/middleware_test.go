@@ -0,0 +1,204 @@
+package frontend
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+// notFoundClient is an IoTServiceClient stub that reports every device as missing.
+type notFoundClient struct {
+	iotv1.IoTServiceClient
+}
+
+func (notFoundClient) GetDevice(_ context.Context, _ *iotv1.GetDeviceByIDRequest, _ ...grpc.CallOption) (*iotv1.GetDeviceByIDResponse, error) {
+	return nil, status.Error(codes.NotFound, "device not found")
+}
+
+// collectLabelValues returns all values of the given label across a collector's series.
+func collectLabelValues(c prometheus.Collector, label string) []string {
+	ch := make(chan prometheus.Metric, 100)
+	c.Collect(ch)
+	close(ch)
+
+	var values []string
+	for m := range ch {
+		var pb dto.Metric
+		Expect(m.Write(&pb)).To(Succeed())
+		for _, lp := range pb.GetLabel() {
+			if lp.GetName() == label {
+				values = append(values, lp.GetValue())
+			}
+		}
+	}
+	return values
+}
+
+var _ = Describe("Metrics Middleware", func() {
+	var (
+		server  *Server
+		handler http.Handler
+		m       *metrics.FrontendMetrics
+	)
+
+	BeforeEach(func() {
+		if m == nil {
+			m = metrics.NewFrontendMetrics("test_middleware")
+		}
+		m.HTTPRequestsTotal.Reset()
+		m.HTTPResponseSize.Reset()
+
+		server = &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: notFoundClient{},
+			metrics:    m,
+		}
+		handler = server.setupRoutes()
+	})
+
+	It("should label requests by route pattern instead of raw path", func() {
+		for _, id := range []string{"device-a", "device-b", "device-c"} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/device/"+id, nil))
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		}
+
+		paths := collectLabelValues(m.HTTPRequestsTotal, "path")
+		Expect(paths).To(ConsistOf("/device/{id}"))
+		for _, p := range collectLabelValues(m.HTTPResponseSize, "path") {
+			Expect(p).NotTo(ContainSubstring("device-"))
+		}
+	})
+
+	It("should use a fixed label for unmatched routes", func() {
+		for _, path := range []string{"/no/such/page", "/another/missing/page"} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		}
+
+		Expect(collectLabelValues(m.HTTPRequestsTotal, "path")).To(ConsistOf(unmatchedRoute))
+	})
+
+	It("should strip the method prefix from patterns", func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+
+		paths := collectLabelValues(m.HTTPRequestsTotal, "path")
+		Expect(paths).To(ConsistOf("/health"))
+		Expect(strings.HasPrefix(paths[0], http.MethodGet)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Middleware chain", func() {
+	var logger *slog.Logger
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	})
+
+	// named returns middleware passing requests through.
+	named := func(name string) Middleware {
+		return Middleware{Name: name, Wrap: func(next http.Handler) http.Handler { return next }}
+	}
+
+	It("should install the built-in middleware in order", func() {
+		server := &Server{logger: logger}
+		Expect(middlewareNames(server.middlewareChain(http.NewServeMux()))).To(Equal([]string{
+			MiddlewareRequestID, MiddlewareSecurityHeaders, MiddlewareRecovery, MiddlewareRequestBudget,
+			MiddlewareTimezone, MiddlewareLocale, MiddlewareMaxBody, MiddlewareCSRF,
+		}))
+
+		server = &Server{logger: logger, metrics: metrics.NewFrontendMetrics("test_middleware_chain"), cors: &CORSConfig{AllowedOrigins: []string{"https://example.com"}}}
+		Expect(middlewareNames(server.middlewareChain(http.NewServeMux()))).To(Equal([]string{
+			MiddlewareRequestID, MiddlewareSecurityHeaders, MiddlewareMetrics, MiddlewareRecovery, MiddlewareRequestBudget,
+			MiddlewareCORS, MiddlewareTimezone, MiddlewareLocale, MiddlewareMaxBody, MiddlewareCSRF,
+		}))
+	})
+
+	It("should insert middleware relative to others", func() {
+		chain := []Middleware{named("a"), named("b")}
+
+		Expect(middlewareNames(InsertBefore(chain, "b", named("x"), named("y")))).To(Equal([]string{"a", "x", "y", "b"}))
+		Expect(middlewareNames(InsertAfter(chain, "a", named("x")))).To(Equal([]string{"a", "x", "b"}))
+		Expect(middlewareNames(InsertBefore(chain, "missing", named("x")))).To(Equal([]string{"a", "b", "x"}))
+		Expect(middlewareNames(InsertAfter(chain, "missing", named("x")))).To(Equal([]string{"a", "b", "x"}))
+		Expect(middlewareNames(chain)).To(Equal([]string{"a", "b"}))
+	})
+
+	It("should run user-supplied middleware in its place", func() {
+		// headers adds a company header; auth rejects requests without a
+		// token after the request ID is assigned
+		headers := Middleware{Name: "company_headers", Wrap: func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Company", "procodus")
+				next.ServeHTTP(w, r)
+			})
+		}}
+		auth := Middleware{Name: "auth", Wrap: func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") == "" {
+					http.Error(w, "unauthorized", http.StatusUnauthorized)
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		}}
+		server := &Server{logger: logger, middleware: func(chain []Middleware) []Middleware {
+			chain = InsertAfter(chain, MiddlewareSecurityHeaders, headers)
+			return InsertBefore(chain, MiddlewareCSRF, auth)
+		}}
+		handler := server.setupRoutes()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		Expect(rec.Header().Get("X-Company")).To(Equal("procodus"))
+		Expect(rec.Header().Get(requestIDHeader)).NotTo(BeEmpty())
+
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should reject invalid chains", func() {
+		_, err := NewServer(&ServerConfig{
+			Logger:          logger,
+			BackendGRPCAddr: "localhost:50051",
+			HTTPPort:        8080,
+			Middleware: func(chain []Middleware) []Middleware {
+				return append(chain, Middleware{Name: "auth"})
+			},
+		})
+		Expect(err).To(MatchError(`invalid middleware chain: middleware "auth" is nil`))
+
+		_, err = NewServer(&ServerConfig{
+			Logger:          logger,
+			BackendGRPCAddr: "localhost:50051",
+			HTTPPort:        8080,
+			Middleware: func(chain []Middleware) []Middleware {
+				return append(chain, named(MiddlewareCSRF))
+			},
+		})
+		Expect(err).To(MatchError(`invalid middleware chain: middleware "csrf" is in the chain twice`))
+	})
+})
@@ -0,0 +1,129 @@
+package frontend
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// noteForm holds the values of the add-note form. Errors are keyed by proto field name.
+type noteForm struct {
+	Errors        map[string]string
+	Author        string
+	Body          string
+	AttachmentURL string
+}
+
+// notesPanel is the view model of the maintenance history panel on the device page.
+type notesPanel struct {
+	DeviceID string
+	Notes    []*iotv1.DeviceNote
+	Form     noteForm
+}
+
+// handleAPIDeviceNotes serves the maintenance history panel as HTML fragment for htmx.
+func (s *Server) handleAPIDeviceNotes(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.PathValue("id")
+
+	s.renderNotesPanel(w, r, http.StatusOK, notesPanel{DeviceID: deviceID})
+}
+
+// handleCreateDeviceNote adds a note to a device. htmx requests get the updated
+// panel back; plain form posts are redirected to the device page.
+func (s *Server) handleCreateDeviceNote(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.PathValue("id")
+
+	if err := r.ParseForm(); err != nil {
+		s.renderError(w, r, http.StatusBadRequest, "Invalid form submission")
+		return
+	}
+
+	form := noteForm{
+		Author:        strings.TrimSpace(r.PostFormValue("author")),
+		Body:          strings.TrimSpace(r.PostFormValue("body")),
+		AttachmentURL: strings.TrimSpace(r.PostFormValue("attachment_url")),
+	}
+
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	_, err := trackedCall(s, ctx, "CreateDeviceNote", s.grpcClient.CreateDeviceNote, &iotv1.CreateDeviceNoteRequest{
+		Note: &iotv1.DeviceNote{
+			DeviceId:      deviceID,
+			Author:        form.Author,
+			Body:          form.Body,
+			AttachmentUrl: form.AttachmentURL,
+		},
+	})
+	if err != nil {
+		// Plain form posts fall through to an error page that lists the violations
+		if violations := iotv1.FieldViolations(err); len(violations) > 0 && isHTMXRequest(r) {
+			form.Errors = make(map[string]string, len(violations))
+			for _, v := range violations {
+				form.Errors[v.Field] = v.Description
+			}
+			s.renderNotesPanel(w, r, http.StatusBadRequest, notesPanel{DeviceID: deviceID, Form: form})
+			return
+		}
+
+		s.logger.Error("failed to create device note", "error", err, "device_id", deviceID, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to save note"))
+		return
+	}
+
+	s.afterNoteChange(w, r, deviceID)
+}
+
+// handleDeleteDeviceNote removes a note from a device.
+func (s *Server) handleDeleteDeviceNote(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.PathValue("id")
+
+	noteID, err := strconv.ParseUint(r.PathValue("note"), 10, 64)
+	if err != nil {
+		s.renderError(w, r, http.StatusNotFound, "Note not found")
+		return
+	}
+
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	if _, err := trackedCall(s, ctx, "DeleteDeviceNote", s.grpcClient.DeleteDeviceNote, &iotv1.DeleteDeviceNoteRequest{Id: noteID}); err != nil {
+		s.logger.Error("failed to delete device note", "error", err, "id", noteID, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to delete note"))
+		return
+	}
+
+	s.afterNoteChange(w, r, deviceID)
+}
+
+// afterNoteChange responds to a successful note change with the refreshed
+// panel for htmx, or a redirect to the device page otherwise.
+func (s *Server) afterNoteChange(w http.ResponseWriter, r *http.Request, deviceID string) {
+	if !isHTMXRequest(r) {
+		http.Redirect(w, r, "/device/"+deviceID, http.StatusSeeOther)
+		return
+	}
+
+	s.renderNotesPanel(w, r, http.StatusOK, notesPanel{DeviceID: deviceID})
+}
+
+// renderNotesPanel loads the device's notes into panel and renders it.
+func (s *Server) renderNotesPanel(w http.ResponseWriter, r *http.Request, statusCode int, panel notesPanel) {
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	resp, err := trackedCall(s, ctx, "ListDeviceNotes", s.grpcClient.ListDeviceNotes, &iotv1.ListDeviceNotesRequest{DeviceId: panel.DeviceID})
+	if err != nil {
+		s.logger.Error("failed to fetch device notes", "error", err, "device_id", panel.DeviceID, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to fetch notes"))
+		return
+	}
+	panel.Notes = resp.GetNotes()
+
+	w.WriteHeader(statusCode)
+	if err := renderDeviceNotes(r.Context(), w, panel, s.metrics); err != nil {
+		s.logger.Error("failed to render device notes", "error", err, "request_id", requestIDFromContext(r.Context()))
+	}
+}
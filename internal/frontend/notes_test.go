@@ -0,0 +1,135 @@
+package frontend
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// notesClient is an in-memory IoTServiceClient stub for device notes.
+type notesClient struct {
+	iotv1.IoTServiceClient
+	notes  []*iotv1.DeviceNote
+	nextID uint64
+}
+
+func (c *notesClient) ListDeviceNotes(_ context.Context, req *iotv1.ListDeviceNotesRequest, _ ...grpc.CallOption) (*iotv1.ListDeviceNotesResponse, error) {
+	resp := &iotv1.ListDeviceNotesResponse{}
+	for i := len(c.notes) - 1; i >= 0; i-- {
+		if c.notes[i].GetDeviceId() == req.GetDeviceId() {
+			resp.Notes = append(resp.Notes, c.notes[i])
+		}
+	}
+	return resp, nil
+}
+
+func (c *notesClient) CreateDeviceNote(_ context.Context, req *iotv1.CreateDeviceNoteRequest, _ ...grpc.CallOption) (*iotv1.CreateDeviceNoteResponse, error) {
+	if req.GetNote().GetBody() == "" {
+		return nil, iotv1.NewError(codes.InvalidArgument, iotv1.ReasonInvalidArgument, "invalid device note", nil,
+			iotv1.FieldViolation{Field: "body", Description: "cannot be empty"})
+	}
+	c.nextID++
+	note := req.GetNote()
+	note.Id = c.nextID
+	c.notes = append(c.notes, note)
+	return &iotv1.CreateDeviceNoteResponse{Note: note}, nil
+}
+
+func (c *notesClient) DeleteDeviceNote(_ context.Context, req *iotv1.DeleteDeviceNoteRequest, _ ...grpc.CallOption) (*iotv1.DeleteDeviceNoteResponse, error) {
+	for i, note := range c.notes {
+		if note.GetId() == req.GetId() {
+			c.notes = append(c.notes[:i], c.notes[i+1:]...)
+			return &iotv1.DeleteDeviceNoteResponse{}, nil
+		}
+	}
+	return nil, iotv1.DeviceNoteNotFoundError(req.GetId())
+}
+
+var _ = Describe("Device notes panel", func() {
+	var (
+		client  *notesClient
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		client = &notesClient{}
+		server := &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: client,
+		}
+		handler = server.setupRoutes()
+	})
+
+	do := func(method, target string, form url.Values, htmx bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		if form != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if htmx {
+			req.Header.Set("HX-Request", "true")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, withCSRFToken(req))
+		return rec
+	}
+
+	noteForm := url.Values{
+		"author":         {"Sam Rivera"},
+		"body":           {"Replaced battery pack"},
+		"attachment_url": {"https://example.com/r/1.pdf"},
+	}
+
+	It("should add notes and render them newest first", func() {
+		Expect(do(http.MethodPost, "/device/sensor-1/notes", noteForm, true).Code).To(Equal(http.StatusOK))
+
+		second := url.Values{"author": {"Alex Chen"}, "body": {"Recalibrated humidity sensor"}}
+		rec := do(http.MethodPost, "/device/sensor-1/notes", second, true)
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		body := rec.Body.String()
+		Expect(strings.Index(body, "Alex Chen")).To(BeNumerically("<", strings.Index(body, "Sam Rivera")))
+		Expect(body).To(ContainSubstring(`href="https://example.com/r/1.pdf"`))
+		Expect(body).To(ContainSubstring(`hx-post="/device/sensor-1/notes/1/delete"`))
+		Expect(client.notes).To(HaveLen(2))
+	})
+
+	It("should redirect plain form posts to the device page", func() {
+		rec := do(http.MethodPost, "/device/sensor-1/notes", noteForm, false)
+
+		Expect(rec.Code).To(Equal(http.StatusSeeOther))
+		Expect(rec.Header().Get("Location")).To(Equal("/device/sensor-1"))
+	})
+
+	It("should keep the form values and show field errors", func() {
+		form := url.Values{"author": {"Sam Rivera"}}
+
+		rec := do(http.MethodPost, "/device/sensor-1/notes", form, true)
+
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring("cannot be empty"))
+		Expect(rec.Body.String()).To(ContainSubstring(`value="Sam Rivera"`))
+	})
+
+	It("should delete notes", func() {
+		Expect(do(http.MethodPost, "/device/sensor-1/notes", noteForm, true).Code).To(Equal(http.StatusOK))
+
+		rec := do(http.MethodPost, "/device/sensor-1/notes/1/delete", nil, true)
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring("No maintenance notes yet."))
+		Expect(do(http.MethodPost, "/device/sensor-1/notes/1/delete", nil, true).Code).To(Equal(http.StatusNotFound))
+	})
+})
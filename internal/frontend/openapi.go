@@ -0,0 +1,414 @@
+package frontend
+
+//go:generate go run ../../cmd openapi -o ../../docs/openapi.json
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// openAPIVersion is the OpenAPI version of the generated document.
+const openAPIVersion = "3.0.3"
+
+// apiOperation describes one JSON route of the frontend. The OpenAPI document
+// and the API reference page are built from apiOperations, and a test checks
+// that every operation is registered in setupRoutes.
+type apiOperation struct {
+	Method      string
+	Path        string
+	ID          string
+	Summary     string
+	Description string
+	Params      []apiParam
+	// Response is a value of the response body type; its schema is derived
+	// from the json struct tags.
+	Response any
+	// Errors are the status codes returned with an errorResponse body.
+	Errors []int
+}
+
+// apiParam is a path or query parameter of an apiOperation.
+type apiParam struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+	Type        string
+	Enum        []any
+}
+
+// apiOperations lists the JSON routes in the order they are documented.
+var apiOperations = []apiOperation{
+	{
+		Method:   http.MethodGet,
+		Path:     "/health",
+		ID:       "getHealth",
+		Summary:  "Health check",
+		Response: healthResponse{},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/debug/status",
+		ID:          "getDebugStatus",
+		Summary:     "Backend connection state",
+		Description: "Reports the state of the gRPC connection to the backend.",
+		Response:    debugStatusResponse{},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/api/devices",
+		ID:          "listDevices",
+		Summary:     "List devices",
+		Description: "Returns every device in one page. Send `Accept: application/json`; other clients get an HTML fragment.",
+		Response:    devicesResponse{},
+		Errors:      []int{http.StatusTooManyRequests, http.StatusBadGateway},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/api/device/{id}/readings",
+		ID:          "listDeviceReadings",
+		Summary:     "List sensor readings of a device",
+		Description: "Returns one page of readings. Pass `next_page_token` as `page_token` to fetch the next page. Send `Accept: application/json`; other clients get an HTML fragment.",
+		Params: []apiParam{
+			{Name: "id", In: "path", Description: "Device ID", Required: true, Type: "string"},
+			{Name: "page_token", In: "query", Description: "Token of the page to fetch; empty for the first page", Type: "string"},
+			{Name: "page_size", In: "query", Description: "Number of readings per page", Type: "integer", Enum: enumValues(readingsPageSizes)},
+			{Name: "sort", In: "query", Description: "Column to sort by", Type: "string", Enum: enumValues(readingSortColumns)},
+			{Name: "dir", In: "query", Description: "Sort direction", Type: "string", Enum: []any{"asc", "desc"}},
+			{Name: "temp_unit", In: "query", Description: "Temperature unit", Type: "string", Enum: []any{unitCelsius, unitFahrenheit}},
+			{Name: "pressure_unit", In: "query", Description: "Pressure unit", Type: "string", Enum: []any{unitHectopa, unitInchesHg}},
+		},
+		Response: readingsResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusTooManyRequests, http.StatusBadGateway},
+	},
+}
+
+// enumValues converts allowed values for apiParam.Enum.
+func enumValues[T any](values []T) []any {
+	enum := make([]any, len(values))
+	for i, v := range values {
+		enum[i] = v
+	}
+	return enum
+}
+
+// openAPIDocument is the subset of the OpenAPI 3 document model used here.
+type openAPIDocument struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       openAPIInfo                            `json:"info"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components openAPIComponents                      `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Description string                     `json:"description,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string        `json:"name"`
+	In          string        `json:"in"`
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+	Schema      openAPISchema `json:"schema"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema openAPISchema `json:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `json:"schemas"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref,omitempty"`
+	Type       string                    `json:"type,omitempty"`
+	Format     string                    `json:"format,omitempty"`
+	Enum       []any                     `json:"enum,omitempty"`
+	Items      *openAPISchema            `json:"items,omitempty"`
+	Properties map[string]*openAPISchema `json:"properties,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+}
+
+// typeName returns the schema type as shown on the API reference page.
+func (s openAPISchema) typeName() string {
+	switch {
+	case s.Ref != "":
+		return strings.TrimPrefix(s.Ref, schemaRefPrefix)
+	case s.Items != nil:
+		return s.Items.typeName() + "[]"
+	case s.Format != "":
+		return s.Type + " (" + s.Format + ")"
+	default:
+		return s.Type
+	}
+}
+
+// enumList formats allowed values for the API reference page.
+func enumList(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// schemaRefPrefix prefixes references to component schemas.
+const schemaRefPrefix = "#/components/schemas/"
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// newOpenAPIDocument builds the OpenAPI document for apiOperations.
+func newOpenAPIDocument() (openAPIDocument, error) {
+	doc := openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info: openAPIInfo{
+			Title:       "IoT Dashboard API",
+			Description: "JSON routes of the demo-app frontend. Device data is served by the backend over gRPC; see docs/api.md.",
+			Version:     "1.0.0",
+		},
+		Paths:      map[string]map[string]openAPIOperation{},
+		Components: openAPIComponents{Schemas: map[string]*openAPISchema{}},
+	}
+
+	for _, op := range apiOperations {
+		operation := openAPIOperation{
+			OperationID: op.ID,
+			Summary:     op.Summary,
+			Description: op.Description,
+			Responses:   map[string]openAPIResponse{},
+		}
+
+		for _, p := range op.Params {
+			operation.Parameters = append(operation.Parameters, openAPIParameter{
+				Name:        p.Name,
+				In:          p.In,
+				Description: p.Description,
+				Required:    p.Required,
+				Schema:      openAPISchema{Type: p.Type, Enum: p.Enum},
+			})
+		}
+
+		schema, err := schemaFor(reflect.TypeOf(op.Response), doc.Components.Schemas)
+		if err != nil {
+			return doc, fmt.Errorf("response of %s: %w", op.ID, err)
+		}
+		operation.Responses[strconv.Itoa(http.StatusOK)] = openAPIResponse{
+			Description: http.StatusText(http.StatusOK),
+			Content:     map[string]openAPIMediaType{jsonContentType: {Schema: schema}},
+		}
+
+		if len(op.Errors) > 0 {
+			errSchema, err := schemaFor(reflect.TypeFor[errorResponse](), doc.Components.Schemas)
+			if err != nil {
+				return doc, fmt.Errorf("errors of %s: %w", op.ID, err)
+			}
+			for _, code := range op.Errors {
+				operation.Responses[strconv.Itoa(code)] = openAPIResponse{
+					Description: errorTitle(code),
+					Content:     map[string]openAPIMediaType{jsonContentType: {Schema: errSchema}},
+				}
+			}
+		}
+
+		if doc.Paths[op.Path] == nil {
+			doc.Paths[op.Path] = map[string]openAPIOperation{}
+		}
+		doc.Paths[op.Path][strings.ToLower(op.Method)] = operation
+	}
+
+	return doc, nil
+}
+
+// schemaFor returns the schema of t. Structs are added to schemas under their
+// type name, without a "JSON" suffix, and referenced.
+func schemaFor(t reflect.Type, schemas map[string]*openAPISchema) (openAPISchema, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return openAPISchema{Type: "string", Format: "date-time"}, nil
+	case t.Kind() == reflect.Struct:
+		name := strings.TrimSuffix(t.Name(), "JSON")
+		name = strings.ToUpper(name[:1]) + name[1:]
+		if _, ok := schemas[name]; !ok {
+			schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+			// Register before the fields so recursive types terminate
+			schemas[name] = schema
+			for i := range t.NumField() {
+				field := t.Field(i)
+				tag, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+				if !field.IsExported() || tag == "-" || tag == "" {
+					continue
+				}
+				fieldSchema, err := schemaFor(field.Type, schemas)
+				if err != nil {
+					return openAPISchema{}, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+				}
+				schema.Properties[tag] = &fieldSchema
+				if !slices.Contains(strings.Split(opts, ","), "omitempty") {
+					schema.Required = append(schema.Required, tag)
+				}
+			}
+		}
+		return openAPISchema{Ref: schemaRefPrefix + name}, nil
+	case t.Kind() == reflect.Slice:
+		items, err := schemaFor(t.Elem(), schemas)
+		if err != nil {
+			return openAPISchema{}, err
+		}
+		return openAPISchema{Type: "array", Items: &items}, nil
+	case t.Kind() == reflect.String:
+		return openAPISchema{Type: "string"}, nil
+	case t.Kind() == reflect.Bool:
+		return openAPISchema{Type: "boolean"}, nil
+	case t.Kind() == reflect.Int64:
+		return openAPISchema{Type: "integer", Format: "int64"}, nil
+	case t.Kind() == reflect.Int || t.Kind() == reflect.Int32:
+		return openAPISchema{Type: "integer", Format: "int32"}, nil
+	case t.Kind() == reflect.Float64:
+		return openAPISchema{Type: "number", Format: "double"}, nil
+	case t.Kind() == reflect.Float32:
+		return openAPISchema{Type: "number", Format: "float"}, nil
+	default:
+		return openAPISchema{}, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// apiDocsPage is the API reference page, in documentation order.
+type apiDocsPage struct {
+	Operations []apiDocsOperation
+	Schemas    []apiDocsSchema
+}
+
+type apiDocsOperation struct {
+	Method    string
+	Path      string
+	Operation openAPIOperation
+	Responses []apiDocsResponse
+}
+
+type apiDocsResponse struct {
+	Status      string
+	Description string
+	Type        string
+}
+
+type apiDocsSchema struct {
+	Name       string
+	Properties []apiDocsProperty
+}
+
+type apiDocsProperty struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+// newAPIDocsPage orders doc for the API reference page: operations as listed
+// in apiOperations, responses by status code and schemas and their properties
+// by name.
+func newAPIDocsPage(doc openAPIDocument) apiDocsPage {
+	var page apiDocsPage
+
+	for _, op := range apiOperations {
+		operation := doc.Paths[op.Path][strings.ToLower(op.Method)]
+		entry := apiDocsOperation{Method: op.Method, Path: op.Path, Operation: operation}
+		for _, status := range slices.Sorted(maps.Keys(operation.Responses)) {
+			resp := operation.Responses[status]
+			entry.Responses = append(entry.Responses, apiDocsResponse{
+				Status:      status,
+				Description: resp.Description,
+				Type:        resp.Content[jsonContentType].Schema.typeName(),
+			})
+		}
+		page.Operations = append(page.Operations, entry)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(doc.Components.Schemas)) {
+		schema := doc.Components.Schemas[name]
+		entry := apiDocsSchema{Name: name}
+		for _, prop := range slices.Sorted(maps.Keys(schema.Properties)) {
+			entry.Properties = append(entry.Properties, apiDocsProperty{
+				Name:     prop,
+				Type:     schema.Properties[prop].typeName(),
+				Required: slices.Contains(schema.Required, prop),
+			})
+		}
+		page.Schemas = append(page.Schemas, entry)
+	}
+
+	return page
+}
+
+// OpenAPISpec returns the OpenAPI 3 document describing the frontend's JSON
+// routes. It is served at /openapi.json and written to docs/openapi.json by
+// go generate.
+func OpenAPISpec() ([]byte, error) {
+	doc, err := newOpenAPIDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+
+	return append(data, '\n'), nil
+}
+
+// handleOpenAPISpec serves the OpenAPI document.
+func (s *Server) handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	spec, err := OpenAPISpec()
+	if err != nil {
+		s.logger.Error("failed to build OpenAPI document", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
+		return
+	}
+
+	w.Header().Set("Content-Type", jsonContentType)
+	if _, err := w.Write(spec); err != nil {
+		s.logger.Error("failed to write OpenAPI document", "error", err)
+	}
+}
+
+// handleAPIDocs serves the API reference page rendered from the OpenAPI document.
+func (s *Server) handleAPIDocs(w http.ResponseWriter, r *http.Request) {
+	doc, err := newOpenAPIDocument()
+	if err != nil {
+		s.logger.Error("failed to build OpenAPI document", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
+		return
+	}
+
+	if err := renderAPIDocs(r.Context(), w, newAPIDocsPage(doc), s.metrics); err != nil {
+		s.logger.Error("failed to render API docs", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
+		return
+	}
+}
@@ -0,0 +1,96 @@
+package frontend
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpenAPI document", func() {
+	var handler http.Handler
+
+	BeforeEach(func() {
+		server := &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: &jsonAPIClient{},
+			config:     &ServerConfig{BackendGRPCAddr: "localhost:9090"},
+		}
+		handler = server.setupRoutes()
+	})
+
+	get := func(url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("Accept", jsonContentType)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("should match docs/openapi.json", func() {
+		spec, err := OpenAPISpec()
+		Expect(err).NotTo(HaveOccurred())
+
+		committed, err := os.ReadFile("../../docs/openapi.json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(committed)).To(Equal(string(spec)), "run go generate ./internal/frontend")
+	})
+
+	It("should serve the document", func() {
+		rec := get("/openapi.json")
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal(jsonContentType))
+
+		var doc openAPIDocument
+		Expect(json.Unmarshal(rec.Body.Bytes(), &doc)).To(Succeed())
+		Expect(doc.OpenAPI).To(Equal(openAPIVersion))
+		Expect(doc.Paths).To(HaveKey("/api/device/{id}/readings"))
+		Expect(doc.Components.Schemas).To(HaveKey("ReadingsResponse"))
+		Expect(doc.Components.Schemas).To(HaveKey("ErrorResponse"))
+	})
+
+	It("should describe responses that the routes actually return", func() {
+		doc, err := newOpenAPIDocument()
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, op := range apiOperations {
+			url := strings.ReplaceAll(op.Path, "{id}", "device-001")
+			rec := get(url)
+			Expect(rec.Code).To(Equal(http.StatusOK), op.Path)
+			Expect(rec.Header().Get("Content-Type")).To(Equal(jsonContentType), op.Path)
+
+			var body map[string]any
+			Expect(json.Unmarshal(rec.Body.Bytes(), &body)).To(Succeed(), op.Path)
+
+			ref := doc.Paths[op.Path]["get"].Responses["200"].Content[jsonContentType].Schema.Ref
+			schema := doc.Components.Schemas[strings.TrimPrefix(ref, schemaRefPrefix)]
+			Expect(schema).NotTo(BeNil(), op.Path)
+			for _, field := range schema.Required {
+				Expect(body).To(HaveKey(field), op.Path)
+			}
+			for field := range body {
+				Expect(schema.Properties).To(HaveKey(field), op.Path)
+			}
+		}
+	})
+
+	It("should render the API reference page", func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/api", nil))
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		body := rec.Body.String()
+		Expect(body).To(ContainSubstring("GET /api/device/{id}/readings"))
+		Expect(body).To(ContainSubstring("25, 50, 100, 200"))
+		Expect(body).To(ContainSubstring(`id="schema-Reading"`))
+		Expect(body).To(ContainSubstring(`href="#schema-ReadingsResponse"`))
+	})
+})
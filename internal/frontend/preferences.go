@@ -0,0 +1,226 @@
+package frontend
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"procodus.dev/demo-app/pkg/session"
+)
+
+// preferencesCookie stores the user's display preferences for the dashboard
+// when sessions are not enabled.
+const preferencesCookie = "demo_app_prefs"
+
+// preferencesSessionKey stores the preferences in the session when sessions are enabled.
+const preferencesSessionKey = "prefs"
+
+// preferencesMaxAge keeps preferences for a year.
+const preferencesMaxAge = 365 * 24 * time.Hour
+
+// Temperature and pressure units supported by the readings table.
+const (
+	unitCelsius    = "c"
+	unitFahrenheit = "f"
+	unitHectopa    = "hpa"
+	unitInchesHg   = "inhg"
+)
+
+// hPaToInHg converts hectopascals to inches of mercury.
+const hPaToInHg = 0.0295299830714
+
+// readingSortColumns are the readings table columns that can be sorted on,
+// using the backend's order_by names.
+var readingSortColumns = []string{"timestamp", "temperature", "humidity", "pressure", "battery_level"}
+
+// preferences are the per-user display settings for the readings table and
+// the dashboard language.
+type preferences struct {
+	TempUnit     string
+	PressureUnit string
+	SortBy       string
+	Lang         string // Chosen language; empty to follow Accept-Language
+	SortAsc      bool
+}
+
+// defaultPreferences shows metric units, newest readings first.
+func defaultPreferences() preferences {
+	return preferences{
+		TempUnit:     unitCelsius,
+		PressureUnit: unitHectopa,
+		SortBy:       "timestamp",
+	}
+}
+
+// loadPreferences reads preferences from the session, or the cookie without
+// one, and applies any overrides from the query string. The second return value
+// reports whether the query changed anything, in which case the preferences
+// should be saved.
+func loadPreferences(r *http.Request) (preferences, bool) {
+	prefs := defaultPreferences()
+
+	stored := ""
+	if sess := session.FromContext(r.Context()); sess != nil {
+		stored = sess.Get(preferencesSessionKey)
+	}
+	if stored == "" {
+		// Also picks up preferences saved before sessions were enabled
+		if c, err := r.Cookie(preferencesCookie); err == nil {
+			stored = c.Value
+		}
+	}
+	if values, err := url.ParseQuery(stored); err == nil {
+		prefs.apply(values)
+	}
+
+	before := prefs
+	prefs.apply(r.URL.Query())
+
+	return prefs, prefs != before
+}
+
+// apply overrides preferences with the valid values present in v.
+// Unknown or malformed values are ignored.
+func (p *preferences) apply(v url.Values) {
+	switch v.Get("temp_unit") {
+	case unitCelsius, unitFahrenheit:
+		p.TempUnit = v.Get("temp_unit")
+	}
+
+	switch v.Get("pressure_unit") {
+	case unitHectopa, unitInchesHg:
+		p.PressureUnit = v.Get("pressure_unit")
+	}
+
+	if sort := v.Get("sort"); slices.Contains(readingSortColumns, sort) {
+		p.SortBy = sort
+	}
+
+	for _, tag := range supportedLanguages {
+		if v.Get("lang") == tag.String() {
+			p.Lang = tag.String()
+		}
+	}
+
+	switch v.Get("dir") {
+	case "asc":
+		p.SortAsc = true
+	case "desc":
+		p.SortAsc = false
+	}
+}
+
+// encode serializes preferences in the same format apply reads.
+func (p preferences) encode() string {
+	dir := "desc"
+	if p.SortAsc {
+		dir = "asc"
+	}
+
+	v := url.Values{
+		"temp_unit":     {p.TempUnit},
+		"pressure_unit": {p.PressureUnit},
+		"sort":          {p.SortBy},
+		"dir":           {dir},
+	}
+	if p.Lang != "" {
+		v.Set("lang", p.Lang)
+	}
+
+	return v.Encode()
+}
+
+// savePreferences persists preferences in the session, or in the preference
+// cookie without one.
+func savePreferences(w http.ResponseWriter, r *http.Request, p preferences) {
+	if sess := session.FromContext(r.Context()); sess != nil {
+		sess.Set(preferencesSessionKey, p.encode())
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     preferencesCookie,
+		Value:    p.encode(),
+		Path:     "/",
+		MaxAge:   int(preferencesMaxAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// sortDirFor returns the direction a click on column should sort by:
+// toggling the active column, descending for a newly selected one.
+func (p preferences) sortDirFor(column string) string {
+	if p.SortBy == column && !p.SortAsc {
+		return "asc"
+	}
+
+	return "desc"
+}
+
+// ariaSort returns the aria-sort value of the sorted column.
+func (p preferences) ariaSort() string {
+	if p.SortAsc {
+		return "ascending"
+	}
+
+	return "descending"
+}
+
+// sortIndicator returns the arrow shown next to the active sort column.
+func (p preferences) sortIndicator(column string) string {
+	switch {
+	case p.SortBy != column:
+		return ""
+	case p.SortAsc:
+		return " ▲"
+	default:
+		return " ▼"
+	}
+}
+
+// temperatureLabel returns the temperature column unit label.
+func (p preferences) temperatureLabel() string {
+	if p.TempUnit == unitFahrenheit {
+		return "°F"
+	}
+
+	return "°C"
+}
+
+// pressureLabel returns the pressure column unit label.
+func (p preferences) pressureLabel() string {
+	if p.PressureUnit == unitInchesHg {
+		return "inHg"
+	}
+
+	return "hPa"
+}
+
+// readingRow is a sensor reading converted to the user's preferred units.
+type readingRow struct {
+	Timestamp    int64
+	Temperature  float64
+	Humidity     float64
+	Pressure     float64
+	BatteryLevel float64
+}
+
+// convertTemperature converts a Celsius value to the preferred unit.
+func (p preferences) convertTemperature(celsius float64) float64 {
+	if p.TempUnit == unitFahrenheit {
+		return celsius*9/5 + 32
+	}
+
+	return celsius
+}
+
+// convertPressure converts a hectopascal value to the preferred unit.
+func (p preferences) convertPressure(hPa float64) float64 {
+	if p.PressureUnit == unitInchesHg {
+		return hPa * hPaToInHg
+	}
+
+	return hPa
+}
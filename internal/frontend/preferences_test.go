@@ -0,0 +1,124 @@
+package frontend
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"procodus.dev/demo-app/pkg/session"
+)
+
+var _ = Describe("Preferences", func() {
+	Describe("loadPreferences", func() {
+		It("should default to metric units sorted by newest first", func() {
+			prefs, changed := loadPreferences(httptest.NewRequest(http.MethodGet, "/", nil))
+
+			Expect(changed).To(BeFalse())
+			Expect(prefs).To(Equal(defaultPreferences()))
+		})
+
+		It("should read the cookie and apply query overrides", func() {
+			saved := preferences{TempUnit: unitFahrenheit, PressureUnit: unitInchesHg, SortBy: "humidity", SortAsc: true}
+			req := httptest.NewRequest(http.MethodGet, "/?dir=desc", nil)
+			req.AddCookie(&http.Cookie{Name: preferencesCookie, Value: saved.encode()})
+
+			prefs, changed := loadPreferences(req)
+
+			Expect(changed).To(BeTrue())
+			Expect(prefs.TempUnit).To(Equal(unitFahrenheit))
+			Expect(prefs.PressureUnit).To(Equal(unitInchesHg))
+			Expect(prefs.SortBy).To(Equal("humidity"))
+			Expect(prefs.SortAsc).To(BeFalse())
+		})
+
+		It("should ignore invalid values", func() {
+			req := httptest.NewRequest(http.MethodGet, "/?temp_unit=k&sort=id;drop&dir=sideways", nil)
+			req.AddCookie(&http.Cookie{Name: preferencesCookie, Value: "%%%"})
+
+			prefs, changed := loadPreferences(req)
+
+			Expect(changed).To(BeFalse())
+			Expect(prefs).To(Equal(defaultPreferences()))
+		})
+	})
+
+	Describe("unit conversion", func() {
+		It("should convert temperature and pressure", func() {
+			prefs := preferences{TempUnit: unitFahrenheit, PressureUnit: unitInchesHg}
+
+			Expect(prefs.convertTemperature(100)).To(BeNumerically("~", 212, 1e-9))
+			Expect(prefs.convertPressure(1013.25)).To(BeNumerically("~", 29.92, 0.01))
+			Expect(defaultPreferences().convertTemperature(21.5)).To(Equal(21.5))
+		})
+	})
+
+	Describe("readings handler", func() {
+		var (
+			client  *readingsClient
+			handler http.Handler
+		)
+
+		BeforeEach(func() {
+			client = &readingsClient{}
+			server := &Server{
+				logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+					Level: slog.LevelError,
+				})),
+				grpcClient: client,
+			}
+			handler = server.setupRoutes()
+		})
+
+		It("should convert units and persist the choice in a cookie", func() {
+			rec := httptest.NewRecorder()
+			// The CSRF cookie was issued with the page
+			handler.ServeHTTP(rec, withCSRFToken(httptest.NewRequest(http.MethodGet, "/api/device/device-001/readings?temp_unit=f", nil)))
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).To(ContainSubstring("Temperature (°F)"))
+			Expect(rec.Body.String()).To(ContainSubstring("70.70"))
+
+			cookies := rec.Result().Cookies()
+			Expect(cookies).To(HaveLen(1))
+			Expect(cookies[0].Name).To(Equal(preferencesCookie))
+			Expect(cookies[0].Value).To(ContainSubstring("temp_unit=f"))
+		})
+
+		It("should pass the sort order to the backend", func() {
+			req := httptest.NewRequest(http.MethodGet, "/api/device/device-001/readings", nil)
+			req.AddCookie(&http.Cookie{Name: preferencesCookie, Value: "sort=temperature&dir=asc"})
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, withCSRFToken(req))
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Result().Cookies()).To(BeEmpty())
+			Expect(client.requests).To(HaveLen(1))
+			Expect(client.requests[0].GetOrderBy()).To(Equal("temperature"))
+			Expect(client.requests[0].GetAscending()).To(BeTrue())
+			Expect(rec.Body.String()).To(ContainSubstring("sort=temperature&amp;dir=desc"))
+		})
+
+		It("should keep preferences in the session when one is present", func() {
+			sess := session.New()
+			sess.Set(csrfSessionKey, testCSRFToken)
+			req := httptest.NewRequest(http.MethodGet, "/api/device/device-001/readings?pressure_unit=inhg", nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, withCSRFToken(req.WithContext(session.NewContext(req.Context(), sess))))
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Result().Cookies()).To(BeEmpty())
+			Expect(sess.Get(preferencesSessionKey)).To(ContainSubstring("pressure_unit=inhg"))
+
+			// Later requests read the session rather than the cookie
+			req = httptest.NewRequest(http.MethodGet, "/api/device/device-001/readings", nil)
+			req.AddCookie(&http.Cookie{Name: preferencesCookie, Value: "pressure_unit=hpa"})
+			rec = httptest.NewRecorder()
+			handler.ServeHTTP(rec, withCSRFToken(req.WithContext(session.NewContext(req.Context(), sess))))
+			Expect(rec.Body.String()).To(ContainSubstring("Pressure (inHg)"))
+		})
+	})
+})
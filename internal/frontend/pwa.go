@@ -0,0 +1,181 @@
+package frontend
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// serviceWorkerScript makes the dashboard an installable offline app. Pages and
+// htmx fragments are fetched from the network first and fall back to the last
+// cached copy when the network fails or is slow. Cached copies carry the time
+// they were stored in X-Cached-At, which the layout script shows as freshness
+// indicator. Only same-origin GET requests are handled; the htmx script from
+// unpkg is left to the browser, as the worker's CSP only allows this origin.
+const serviceWorkerScript = `
+var CACHE = "iot-dashboard-v1";
+// The shell is cached on install and served from the cache first.
+var SHELL = ["/offline", "/manifest.webmanifest", "/static/icon.svg"];
+// Slower responses are answered from the cache; the network response still
+// refreshes the cache when it arrives.
+var NETWORK_TIMEOUT = 3000;
+
+self.addEventListener("install", function(evt) {
+	evt.waitUntil(caches.open(CACHE).then(function(cache) {
+		return cache.addAll(SHELL);
+	}).then(function() {
+		return self.skipWaiting();
+	}));
+});
+
+self.addEventListener("activate", function(evt) {
+	evt.waitUntil(caches.keys().then(function(keys) {
+		return Promise.all(keys.filter(function(key) {
+			return key !== CACHE;
+		}).map(function(key) {
+			return caches.delete(key);
+		}));
+	}).then(function() {
+		return self.clients.claim();
+	}));
+});
+
+self.addEventListener("fetch", function(evt) {
+	var url = new URL(evt.request.url);
+	if (evt.request.method !== "GET" || url.origin !== self.location.origin || /^\/(sw\.js|metrics|health|debug\/)/.test(url.pathname)) {
+		return;
+	}
+	if (SHELL.indexOf(url.pathname) !== -1) {
+		evt.respondWith(caches.match(evt.request).then(function(cached) {
+			return cached || fetch(evt.request);
+		}));
+		return;
+	}
+	evt.respondWith(networkFirst(evt.request));
+});
+
+function networkFirst(request) {
+	var network = fetch(request).then(function(response) {
+		if (response.ok) {
+			var copy = stamp(response.clone());
+			copy.then(function(stamped) {
+				return caches.open(CACHE).then(function(cache) {
+					return cache.put(request, stamped);
+				});
+			});
+		}
+		return response;
+	});
+	var timeout = new Promise(function(resolve, reject) {
+		setTimeout(reject, NETWORK_TIMEOUT);
+	});
+
+	return Promise.race([network, timeout]).catch(function() {
+		return fromCache(request).then(function(cached) {
+			return cached || network;
+		});
+	}).catch(function() {
+		if (request.mode === "navigate") {
+			return caches.match("/offline");
+		}
+		return Response.error();
+	});
+}
+
+// stamp copies a response with the time it is cached.
+function stamp(response) {
+	var headers = new Headers(response.headers);
+	headers.set("X-Cached-At", String(Math.floor(Date.now() / 1000)));
+	return response.blob().then(function(body) {
+		return new Response(body, {status: response.status, statusText: response.statusText, headers: headers});
+	});
+}
+
+// fromCache returns the cached response for a request. Pages cannot read the
+// headers of their own response, so the cache time of a page is written into
+// the freshness indicator instead.
+function fromCache(request) {
+	return caches.match(request).then(function(cached) {
+		if (!cached || request.mode !== "navigate") {
+			return cached;
+		}
+		return cached.text().then(function(html) {
+			var cachedAt = 'data-cached-at="' + cached.headers.get("X-Cached-At") + '"';
+			return new Response(html.replace('data-cached-at=""', cachedAt), {status: cached.status, statusText: cached.statusText, headers: cached.headers});
+		});
+	});
+}
+`
+
+// webManifest describes the dashboard to browsers installing it as an app.
+type webManifest struct {
+	Name            string            `json:"name"`
+	ShortName       string            `json:"short_name"`
+	StartURL        string            `json:"start_url"`
+	Scope           string            `json:"scope"`
+	Display         string            `json:"display"`
+	BackgroundColor string            `json:"background_color"`
+	ThemeColor      string            `json:"theme_color"`
+	Icons           []webManifestIcon `json:"icons"`
+}
+
+type webManifestIcon struct {
+	Src   string `json:"src"`
+	Sizes string `json:"sizes"`
+	Type  string `json:"type"`
+}
+
+// dashboardManifest is served as /manifest.webmanifest.
+var dashboardManifest = webManifest{
+	Name:            "IoT Dashboard",
+	ShortName:       "IoT",
+	StartURL:        "/devices",
+	Scope:           "/",
+	Display:         "standalone",
+	BackgroundColor: "#f5f5f5",
+	ThemeColor:      "#2c3e50",
+	Icons:           []webManifestIcon{{Src: "/static/icon.svg", Sizes: "any", Type: "image/svg+xml"}},
+}
+
+// staticFile is an asset served under /static/.
+type staticFile struct {
+	contentType string
+	body        string
+}
+
+// staticFiles are the assets served under /static/, keyed by path.
+var staticFiles = map[string]staticFile{
+	"/static/icon.svg": {
+		contentType: "image/svg+xml",
+		body: `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 64 64">` +
+			`<rect width="64" height="64" rx="12" fill="#2c3e50"/>` +
+			`<circle cx="32" cy="32" r="8" fill="#3498db"/>` +
+			`<path d="M18 18a20 20 0 0 0 0 28M46 18a20 20 0 0 1 0 28" fill="none" stroke="#ecf0f1" stroke-width="4" stroke-linecap="round"/>` +
+			`</svg>`,
+	},
+}
+
+// handleServiceWorker serves the service worker. It is served from the root so
+// its scope covers the whole dashboard, and revalidated on every load so
+// updates are picked up.
+func (s *Server) handleServiceWorker(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write([]byte(serviceWorkerScript))
+}
+
+// handleManifest serves the web app manifest.
+func (s *Server) handleManifest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/manifest+json")
+	if err := json.NewEncoder(w).Encode(dashboardManifest); err != nil {
+		s.logger.Error("failed to write web manifest", "error", err, "request_id", requestIDFromContext(r.Context()))
+	}
+}
+
+// handleOffline serves the page shown for uncached pages while offline. The
+// service worker caches it on install.
+func (s *Server) handleOffline(w http.ResponseWriter, r *http.Request) {
+	if err := renderOffline(r.Context(), w, s.metrics); err != nil {
+		s.logger.Error("failed to render offline page", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
+	}
+}
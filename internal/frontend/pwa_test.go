@@ -0,0 +1,76 @@
+package frontend
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Offline app shell", func() {
+	var handler http.Handler
+
+	BeforeEach(func() {
+		server := &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+		}
+		handler = server.setupRoutes()
+	})
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	It("should serve the service worker from the root so it controls every page", func() {
+		rec := get("/sw.js")
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(HavePrefix("text/javascript"))
+		Expect(rec.Header().Get("Cache-Control")).To(Equal("no-cache"))
+		Expect(rec.Body.String()).To(Equal(serviceWorkerScript))
+	})
+
+	It("should serve a manifest whose start page and icon exist", func() {
+		rec := get("/manifest.webmanifest")
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/manifest+json"))
+
+		var manifest webManifest
+		Expect(json.Unmarshal(rec.Body.Bytes(), &manifest)).To(Succeed())
+		Expect(manifest.Display).To(Equal("standalone"))
+		Expect(manifest.Icons).NotTo(BeEmpty())
+
+		icon := get(manifest.Icons[0].Src)
+		Expect(icon.Code).To(Equal(http.StatusOK))
+		Expect(icon.Header().Get("Content-Type")).To(Equal(manifest.Icons[0].Type))
+	})
+
+	It("should serve every shell entry the service worker precaches", func() {
+		for _, path := range []string{"/offline", "/manifest.webmanifest", "/static/icon.svg"} {
+			Expect(serviceWorkerScript).To(ContainSubstring(`"` + path + `"`))
+			Expect(get(path).Code).To(Equal(http.StatusOK), path)
+		}
+	})
+
+	It("should answer unknown static files with 404", func() {
+		Expect(get("/static/missing.js").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should link the manifest and render the freshness indicator the service worker fills in", func() {
+		body := get("/offline").Body.String()
+
+		Expect(body).To(ContainSubstring(`<link rel="manifest" href="/manifest.webmanifest">`))
+		// The service worker replaces this exact attribute in cached pages
+		Expect(body).To(ContainSubstring(`data-cached-at=""`))
+		Expect(serviceWorkerScript).To(ContainSubstring(`'data-cached-at=""'`))
+	})
+})
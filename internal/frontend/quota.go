@@ -0,0 +1,79 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// tenantMetadataKey is the gRPC metadata key the backend reads the tenant from.
+const tenantMetadataKey = "x-tenant-id"
+
+// quotaWarnRatio is the share of a quota after which the usage banner is shown.
+const quotaWarnRatio = 0.8
+
+// quotaBanner is the view model of the quota usage banner.
+// An empty banner renders nothing.
+type quotaBanner struct {
+	Exceeded bool
+	Messages []string
+}
+
+// tenantInterceptor attaches the tenant ID to every outgoing gRPC call.
+func tenantInterceptor(tenantID string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, tenantMetadataKey, tenantID)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// newQuotaBanner builds the banner for the quotas that are close to or over their limit.
+func newQuotaBanner(ctx context.Context, usage *iotv1.GetQuotaUsageResponse) quotaBanner {
+	var banner quotaBanner
+
+	add := func(label string, used, limit int64, period string) {
+		if limit <= 0 || float64(used) < quotaWarnRatio*float64(limit) {
+			return
+		}
+		if used >= limit {
+			banner.Exceeded = true
+			banner.Messages = append(banner.Messages,
+				t(ctx, "%s quota exhausted (%d of %d per %s)", translate(ctx, label), used, limit, translate(ctx, period)))
+			return
+		}
+		banner.Messages = append(banner.Messages,
+			t(ctx, "%s: %d of %d per %s used", translate(ctx, label), used, limit, translate(ctx, period)))
+	}
+
+	add("API requests", usage.GetRequestsUsed(), usage.GetRequestsLimit(), "minute")
+	add("Exported readings", usage.GetExportRowsUsed(), usage.GetExportRowsLimit(), "day")
+
+	return banner
+}
+
+// handleQuotaBanner renders the quota usage banner fragment (htmx endpoint).
+// Failures render an empty banner since the banner is purely informational.
+func (s *Server) handleQuotaBanner(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	var banner quotaBanner
+
+	// Backends without quotas get no banner
+	if s.backendInfo.supports(iotv1.CapabilityQuotaUsage) {
+		usage, err := trackedCall(s, ctx, "GetQuotaUsage", s.grpcClient.GetQuotaUsage, &iotv1.GetQuotaUsageRequest{})
+		if err != nil {
+			s.logger.Warn("failed to get quota usage", "error", err, "request_id", requestIDFromContext(r.Context()))
+		} else {
+			banner = newQuotaBanner(r.Context(), usage)
+		}
+	}
+
+	if err := renderQuotaBanner(r.Context(), w, banner, s.metrics); err != nil {
+		s.logger.Error("failed to render quota banner", "error", err, "request_id", requestIDFromContext(r.Context()))
+	}
+}
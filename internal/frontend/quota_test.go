@@ -0,0 +1,105 @@
+package frontend
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// quotaClient is an IoTServiceClient stub that returns fixed quota usage.
+type quotaClient struct {
+	iotv1.IoTServiceClient
+	usage *iotv1.GetQuotaUsageResponse
+	err   error
+}
+
+func (c *quotaClient) GetQuotaUsage(_ context.Context, _ *iotv1.GetQuotaUsageRequest, _ ...grpc.CallOption) (*iotv1.GetQuotaUsageResponse, error) {
+	return c.usage, c.err
+}
+
+var _ = Describe("Quota banner", func() {
+	Describe("newQuotaBanner", func() {
+		It("should be empty below the warning threshold or without limits", func() {
+			Expect(newQuotaBanner(context.Background(), &iotv1.GetQuotaUsageResponse{RequestsUsed: 7, RequestsLimit: 10}).Messages).To(BeEmpty())
+			Expect(newQuotaBanner(context.Background(), &iotv1.GetQuotaUsageResponse{RequestsUsed: 1000}).Messages).To(BeEmpty())
+		})
+
+		It("should warn when a quota is nearly used", func() {
+			banner := newQuotaBanner(context.Background(), &iotv1.GetQuotaUsageResponse{RequestsUsed: 8, RequestsLimit: 10})
+			Expect(banner.Exceeded).To(BeFalse())
+			Expect(banner.Messages).To(ConsistOf("API requests: 8 of 10 per minute used"))
+		})
+
+		It("should flag exhausted quotas", func() {
+			banner := newQuotaBanner(context.Background(), &iotv1.GetQuotaUsageResponse{
+				RequestsUsed: 9, RequestsLimit: 10,
+				ExportRowsUsed: 1200, ExportRowsLimit: 1000,
+			})
+			Expect(banner.Exceeded).To(BeTrue())
+			Expect(banner.Messages).To(ConsistOf(
+				"API requests: 9 of 10 per minute used",
+				"Exported readings quota exhausted (1,200 of 1,000 per day)",
+			))
+		})
+	})
+
+	Describe("handleQuotaBanner", func() {
+		serve := func(client *quotaClient) *httptest.ResponseRecorder {
+			server := &Server{
+				logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+					Level: slog.LevelError,
+				})),
+				grpcClient: client,
+			}
+			rec := httptest.NewRecorder()
+			server.setupRoutes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/quota", nil))
+			return rec
+		}
+
+		It("should render the banner", func() {
+			rec := serve(&quotaClient{usage: &iotv1.GetQuotaUsageResponse{ExportRowsUsed: 1000, ExportRowsLimit: 1000}})
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).To(ContainSubstring(`class="quota-banner exceeded"`))
+			Expect(rec.Body.String()).To(ContainSubstring("Exported readings quota exhausted"))
+		})
+
+		It("should render nothing when the backend fails", func() {
+			rec := serve(&quotaClient{err: status.Error(codes.Unavailable, "down")})
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).To(BeEmpty())
+		})
+	})
+
+	It("should map quota errors to 429", func() {
+		err := iotv1.QuotaExceededError("requests_per_minute", "acme")
+
+		Expect(errorStatus(err)).To(Equal(http.StatusTooManyRequests))
+		Expect(errorMessage(err, "fallback")).To(Equal("API quota exceeded. Please try again later"))
+	})
+
+	It("should attach the tenant ID to outgoing calls", func() {
+		var got []string
+		invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			got = md.Get(tenantMetadataKey)
+			return nil
+		}
+
+		err := tenantInterceptor("acme")(context.Background(), iotv1.IoTService_GetAllDevice_FullMethodName, nil, nil, nil, invoker)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(ConsistOf("acme"))
+	})
+})
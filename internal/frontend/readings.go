@@ -0,0 +1,99 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// readingsPageSizes are the page sizes offered in the readings table.
+var readingsPageSizes = []int{25, 50, 100, 200}
+
+// defaultReadingsPageSize matches the backend default.
+const defaultReadingsPageSize = 100
+
+// unknownTotal marks a readings page whose total count could not be fetched.
+const unknownTotal = -1
+
+// readingsPage is one page of sensor readings as shown in the readings table.
+type readingsPage struct {
+	DeviceID string
+	// Rows are the readings converted to the units in Prefs.
+	Rows          []readingRow
+	Prefs         preferences
+	NextPageToken string
+	// PageToken is the token this page was requested with; empty for the first page.
+	PageToken string
+	PageSize  int
+	// Total is the number of readings stored for the device, or unknownTotal.
+	Total int64
+}
+
+// parsePageSize returns the page_size query parameter, falling back to the default
+// when unset. Only sizes offered in the UI are accepted.
+func parsePageSize(r *http.Request) (int, bool) {
+	raw := r.URL.Query().Get("page_size")
+	if raw == "" {
+		return defaultReadingsPageSize, true
+	}
+
+	size, err := strconv.Atoi(raw)
+	if err != nil || !slices.Contains(readingsPageSizes, size) {
+		return 0, false
+	}
+
+	return size, true
+}
+
+// fetchReadingsPage loads one page of readings sorted and converted according to
+// prefs. The total count is only fetched
+// for the first page; it is shown above the table and not repeated for appended pages.
+// A failed count is logged and reported as unknownTotal rather than failing the page.
+func (s *Server) fetchReadingsPage(ctx context.Context, deviceID, pageToken string, pageSize int, prefs preferences) (readingsPage, error) {
+	page := readingsPage{
+		DeviceID:  deviceID,
+		Prefs:     prefs,
+		PageToken: pageToken,
+		PageSize:  pageSize,
+		Total:     unknownTotal,
+	}
+
+	resp, err := s.callGetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
+		DeviceId:  deviceID,
+		PageToken: pageToken,
+		PageSize:  int32(pageSize), //nolint:gosec // pageSize is one of readingsPageSizes
+		OrderBy:   prefs.SortBy,
+		Ascending: prefs.SortAsc,
+	})
+	if err != nil {
+		return page, err
+	}
+
+	page.Rows = make([]readingRow, len(resp.GetReading()))
+	for i, reading := range resp.GetReading() {
+		page.Rows[i] = readingRow{
+			Timestamp:    reading.GetTimestamp(),
+			Temperature:  prefs.convertTemperature(reading.GetTemperature()),
+			Humidity:     reading.GetHumidity(),
+			Pressure:     prefs.convertPressure(reading.GetPressure()),
+			BatteryLevel: reading.GetBatteryLevel(),
+		}
+	}
+	page.NextPageToken = resp.GetNextPageToken()
+
+	if pageToken == "" {
+		countResp, err := s.callCountReadings(ctx, &iotv1.CountReadingsRequest{
+			DeviceId: deviceID,
+		})
+		if err != nil {
+			s.logger.Warn("failed to count sensor readings", "error", err, "device_id", deviceID)
+		} else {
+			page.Total = countResp.GetCount()
+		}
+	}
+
+	return page, nil
+}
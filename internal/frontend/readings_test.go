@@ -0,0 +1,136 @@
+package frontend
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// readingsClient is an IoTServiceClient stub serving a fixed set of readings.
+type readingsClient struct {
+	iotv1.IoTServiceClient
+	requests []*iotv1.GetSensorReadingByDeviceIDRequest
+}
+
+func (c *readingsClient) GetSensorReadingByDeviceID(_ context.Context, req *iotv1.GetSensorReadingByDeviceIDRequest, _ ...grpc.CallOption) (*iotv1.GetSensorReadingByDeviceIDResponse, error) {
+	c.requests = append(c.requests, req)
+	return &iotv1.GetSensorReadingByDeviceIDResponse{
+		Reading: []*iotv1.SensorReading{
+			{DeviceId: req.GetDeviceId(), Timestamp: 1700000000, Temperature: 21.5},
+			{DeviceId: req.GetDeviceId(), Timestamp: 1700000060, Temperature: 22.5},
+		},
+		NextPageToken: "2",
+	}, nil
+}
+
+func (c *readingsClient) GetDevice(_ context.Context, req *iotv1.GetDeviceByIDRequest, _ ...grpc.CallOption) (*iotv1.GetDeviceByIDResponse, error) {
+	return &iotv1.GetDeviceByIDResponse{Device: &iotv1.IoTDevice{DeviceId: req.GetDeviceId()}}, nil
+}
+
+func (c *readingsClient) CountReadings(_ context.Context, _ *iotv1.CountReadingsRequest, _ ...grpc.CallOption) (*iotv1.CountReadingsResponse, error) {
+	return &iotv1.CountReadingsResponse{Count: 42}, nil
+}
+
+var _ = Describe("Readings pagination", func() {
+	var (
+		client  *readingsClient
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		client = &readingsClient{}
+		server := &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: client,
+		}
+		handler = server.setupRoutes()
+	})
+
+	get := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		return rec
+	}
+
+	It("should render the table with total count and page size selector", func() {
+		rec := get("/api/device/device-001/readings?page_size=25")
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		body := rec.Body.String()
+		Expect(body).To(ContainSubstring("Total readings: 42"))
+		Expect(body).To(ContainSubstring(`<option value="25" selected>`))
+		Expect(body).To(ContainSubstring("<table"))
+		Expect(body).To(ContainSubstring("page_token=2&amp;page_size=25"))
+		Expect(client.requests).To(HaveLen(1))
+		Expect(client.requests[0].GetPageSize()).To(Equal(int32(25)))
+	})
+
+	It("should return only appended rows for follow-up pages", func() {
+		rec := get("/api/device/device-001/readings?page_token=2&page_size=25")
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		body := rec.Body.String()
+		Expect(body).NotTo(ContainSubstring("<table"))
+		Expect(body).NotTo(ContainSubstring("Total readings"))
+		Expect(body).To(ContainSubstring(`<tr class="paged">`))
+		Expect(body).To(ContainSubstring(`class="load-more"`))
+	})
+
+	It("should use the default page size when none is given", func() {
+		rec := get("/api/device/device-001/readings")
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(client.requests[0].GetPageSize()).To(Equal(int32(defaultReadingsPageSize)))
+	})
+
+	It("should reject page sizes not offered in the UI", func() {
+		for _, size := range []string{"7", "-1", "abc", "100000"} {
+			rec := get("/api/device/device-001/readings?page_size=" + size)
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		}
+		Expect(client.requests).To(BeEmpty())
+	})
+
+	Describe("without JavaScript", func() {
+		It("should page, sort and convert readings on the device page", func() {
+			rec := get("/device/device-001?page_token=2&page_size=50&sort=humidity&dir=asc&temp_unit=f")
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(client.requests).To(HaveLen(1))
+			Expect(client.requests[0].GetPageToken()).To(Equal("2"))
+			Expect(client.requests[0].GetPageSize()).To(Equal(int32(50)))
+			Expect(client.requests[0].GetOrderBy()).To(Equal("humidity"))
+			Expect(client.requests[0].GetAscending()).To(BeTrue())
+
+			body := rec.Body.String()
+			Expect(body).To(ContainSubstring("70.70"))
+			Expect(body).To(ContainSubstring(`aria-sort="ascending"`))
+			Expect(body).To(ContainSubstring(`href="/device/device-001?page_size=50"`))
+			Expect(rec.Result().Cookies()).To(ContainElement(HaveField("Name", preferencesCookie)))
+		})
+
+		It("should link the controls to the device page", func() {
+			body := get("/device/device-001").Body.String()
+
+			Expect(body).To(ContainSubstring(`<form method="get" action="/device/device-001"`))
+			Expect(body).To(ContainSubstring(`href="/device/device-001?sort=timestamp&amp;dir=asc&amp;page_size=100"`))
+			Expect(body).To(ContainSubstring(`href="/device/device-001?page_token=2&amp;page_size=100"`))
+			Expect(body).To(ContainSubstring(`name="temp_unit" value="f"`))
+		})
+
+		It("should reject page sizes not offered in the UI", func() {
+			Expect(get("/device/device-001?page_size=7").Code).To(Equal(http.StatusBadRequest))
+			Expect(client.requests).To(BeEmpty())
+		})
+	})
+})
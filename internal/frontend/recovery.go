@@ -0,0 +1,42 @@
+package frontend
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+)
+
+// recoveryMiddleware turns a panic in a handler, such as a template bug, into
+// a 500 error page and logs it with its stack, so one bad request cannot crash
+// the server. If the response has already started, the error page cannot be
+// sent and the panic is only logged.
+func (s *Server) recoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			// net/http aborts the response silently on ErrAbortHandler
+			if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(p)
+			}
+
+			s.logger.Error("panic in HTTP handler",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"panic", p,
+				"request_id", requestIDFromContext(r.Context()),
+				"stack", string(debug.Stack()),
+			)
+
+			if rw.statusCode == 0 && rw.bytesWritten == 0 {
+				s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
+			}
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,78 @@
+package frontend
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+var _ = Describe("Recovery Middleware", func() {
+	var server *Server
+
+	BeforeEach(func() {
+		server = &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError + 1,
+			})),
+		}
+	})
+
+	It("should render a 500 error page when a handler panics", func() {
+		handler := server.recoveryMiddleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic("template bug")
+		}))
+
+		rec := httptest.NewRecorder()
+		Expect(func() {
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/devices", nil))
+		}).NotTo(Panic())
+
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(rec.Body.String()).To(ContainSubstring(genericErrorMessage))
+		Expect(rec.Body.String()).NotTo(ContainSubstring("template bug"))
+	})
+
+	It("should keep a response that has already started", func() {
+		handler := server.recoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("partial"))
+			panic("late failure")
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/devices", nil))
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(Equal("partial"))
+	})
+
+	It("should let net/http abort responses", func() {
+		handler := server.recoveryMiddleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+
+		Expect(func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/devices", nil))
+		}).To(PanicWith(http.ErrAbortHandler))
+	})
+
+	It("should count recovered panics as 500s in the route metrics", func() {
+		m := metrics.NewFrontendMetrics("test_recovery")
+		// Without a backend client the device list handler panics
+		server.metrics = m
+		handler := server.setupRoutes()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/devices", nil))
+
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(testutil.ToFloat64(m.HTTPRequestsTotal.WithLabelValues(http.MethodGet, "/devices", "500"))).To(Equal(1.0))
+	})
+})
@@ -6,19 +6,26 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
 	"procodus.dev/demo-app/pkg/metrics"
 )
 
-// renderIndex renders the index page.
+// renderIndex renders the index page from the static template cache.
 func renderIndex(ctx context.Context, w http.ResponseWriter, m *metrics.FrontendMetrics) error {
 	return trackTemplateRender(ctx, w, m, "index", func() error {
-		return index().Render(ctx, w)
+		return indexTemplate.Render(ctx, w)
+	})
+}
+
+// renderOffline renders the offline fallback page from the static template cache.
+func renderOffline(ctx context.Context, w http.ResponseWriter, m *metrics.FrontendMetrics) error {
+	return trackTemplateRender(ctx, w, m, "offline", func() error {
+		return offlineTemplate.Render(ctx, w)
 	})
 }
 
 // renderDevices renders the devices page.
-func renderDevices(ctx context.Context, w http.ResponseWriter, deviceList []*iot.IoTDevice, m *metrics.FrontendMetrics) error {
+func renderDevices(ctx context.Context, w http.ResponseWriter, deviceList []*iotv1.IoTDevice, m *metrics.FrontendMetrics) error {
 	//nolint:contextcheck // Context is passed to Templ's Render method
 	return trackTemplateRender(ctx, w, m, "devices", func() error {
 		return devices(deviceList).Render(ctx, w)
@@ -26,15 +33,15 @@ func renderDevices(ctx context.Context, w http.ResponseWriter, deviceList []*iot
 }
 
 // renderDevice renders a single device detail page.
-func renderDevice(ctx context.Context, w http.ResponseWriter, dev *iot.IoTDevice, readings []*iot.SensorReading, m *metrics.FrontendMetrics) error {
+func renderDevice(ctx context.Context, w http.ResponseWriter, dev *iotv1.IoTDevice, battery *iotv1.BatteryProjection, page readingsPage, m *metrics.FrontendMetrics) error {
 	//nolint:contextcheck // Context is passed to Templ's Render method
 	return trackTemplateRender(ctx, w, m, "device", func() error {
-		return device(dev, readings).Render(ctx, w)
+		return device(dev, battery, page).Render(ctx, w)
 	})
 }
 
 // renderDevicesList renders the devices list fragment.
-func renderDevicesList(ctx context.Context, w http.ResponseWriter, deviceList []*iot.IoTDevice, m *metrics.FrontendMetrics) error {
+func renderDevicesList(ctx context.Context, w http.ResponseWriter, deviceList []*iotv1.IoTDevice, m *metrics.FrontendMetrics) error {
 	//nolint:contextcheck // Context is passed to Templ's Render method
 	return trackTemplateRender(ctx, w, m, "devices_list", func() error {
 		return devicesList(deviceList).Render(ctx, w)
@@ -42,10 +49,130 @@ func renderDevicesList(ctx context.Context, w http.ResponseWriter, deviceList []
 }
 
 // renderReadingsList renders the readings list fragment.
-func renderReadingsList(ctx context.Context, w http.ResponseWriter, readings []*iot.SensorReading, nextPageToken string, m *metrics.FrontendMetrics) error {
+func renderReadingsList(ctx context.Context, w http.ResponseWriter, page readingsPage, m *metrics.FrontendMetrics) error {
 	//nolint:contextcheck // Context is passed to Templ's Render method
 	return trackTemplateRender(ctx, w, m, "readings_list", func() error {
-		return readingsList(readings, nextPageToken).Render(ctx, w)
+		return readingsList(page).Render(ctx, w)
+	})
+}
+
+// renderReadingsRows renders the table rows of a follow-up readings page.
+func renderReadingsRows(ctx context.Context, w http.ResponseWriter, page readingsPage, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "readings_rows", func() error {
+		return readingsRows(page).Render(ctx, w)
+	})
+}
+
+// renderCompare renders the device comparison page.
+func renderCompare(ctx context.Context, w http.ResponseWriter, cmp comparison, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "compare", func() error {
+		return compare(cmp).Render(ctx, w)
+	})
+}
+
+// renderDeviceNotes renders the maintenance history panel fragment.
+func renderDeviceNotes(ctx context.Context, w http.ResponseWriter, panel notesPanel, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "device_notes", func() error {
+		return deviceNotes(panel).Render(ctx, w)
+	})
+}
+
+// renderDeviceLocations renders the movement panel fragment.
+func renderDeviceLocations(ctx context.Context, w http.ResponseWriter, panel locationPanel, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "device_locations", func() error {
+		return deviceLocations(panel).Render(ctx, w)
+	})
+}
+
+// renderBatteryReport renders the low-battery report page.
+func renderBatteryReport(ctx context.Context, w http.ResponseWriter, report batteryReport, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "battery_report", func() error {
+		return batteryReportPage(report).Render(ctx, w)
+	})
+}
+
+// renderStatusPage renders the public status page.
+func renderStatusPage(ctx context.Context, w http.ResponseWriter, page statusPage, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "status_page", func() error {
+		return statusPageView(page).Render(ctx, w)
+	})
+}
+
+// renderTrash renders the deleted devices page.
+func renderTrash(ctx context.Context, w http.ResponseWriter, deviceList []*iotv1.IoTDevice, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "trash", func() error {
+		return trash(deviceList).Render(ctx, w)
+	})
+}
+
+// renderQuotaBanner renders the quota usage banner fragment.
+func renderQuotaBanner(ctx context.Context, w http.ResponseWriter, banner quotaBanner, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "quota_banner", func() error {
+		return quotaBannerView(banner).Render(ctx, w)
+	})
+}
+
+// renderAlertRules renders the alert rules list page.
+func renderAlertRules(ctx context.Context, w http.ResponseWriter, rules []*iotv1.AlertRule, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "alert_rules", func() error {
+		return alertRules(rules).Render(ctx, w)
+	})
+}
+
+// renderAlertRuleFormPage renders the alert rule create/edit form.
+func renderAlertRuleFormPage(ctx context.Context, w http.ResponseWriter, form alertRuleForm, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "alert_rule_form", func() error {
+		return alertRuleFormPage(form).Render(ctx, w)
+	})
+}
+
+// renderAPIDocs renders the API reference page.
+func renderAPIDocs(ctx context.Context, w http.ResponseWriter, page apiDocsPage, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "api_docs", func() error {
+		return apiDocs(page).Render(ctx, w)
+	})
+}
+
+// renderErrorPage renders a full error page.
+func renderErrorPage(ctx context.Context, w http.ResponseWriter, statusCode int, title, message, requestID string, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "error", func() error {
+		return errorPage(statusCode, title, message, requestID).Render(ctx, w)
+	})
+}
+
+// renderErrorFragment renders the error fragment for htmx requests.
+func renderErrorFragment(ctx context.Context, w http.ResponseWriter, statusCode int, title, message, requestID string, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "error_fragment", func() error {
+		return errorFragment(statusCode, title, message, requestID).Render(ctx, w)
+	})
+}
+
+// renderEmbedDevice renders the embeddable device panel.
+func renderEmbedDevice(ctx context.Context, w http.ResponseWriter, panel embedPanel, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "embed_device", func() error {
+		return embedDevice(panel).Render(ctx, w)
+	})
+}
+
+// renderEmbedMessage renders an error as embeddable panel.
+func renderEmbedMessage(ctx context.Context, w http.ResponseWriter, message string, m *metrics.FrontendMetrics) error {
+	//nolint:contextcheck // Context is passed to Templ's Render method
+	return trackTemplateRender(ctx, w, m, "embed_message", func() error {
+		return embedMessage(message).Render(ctx, w)
 	})
 }
 
@@ -0,0 +1,142 @@
+package frontend
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// layoutScript is the only inline script of the pages. The Content-Security-Policy
+// allows it by hash, so it must not depend on request data. Inline event handler
+// attributes are blocked by the policy; forms and controls declare their
+// behavior with data attributes instead.
+const layoutScript = `
+// Swap error fragments into the page instead of silently dropping them.
+document.addEventListener("htmx:beforeSwap", function(evt) {
+	if (evt.detail.xhr.status >= 400) {
+		evt.detail.shouldSwap = true;
+		evt.detail.isError = false;
+	}
+});
+// Echo the CSRF cookie in htmx requests and submitted forms.
+function csrfToken() {
+	var match = document.cookie.match(/(?:^|;\s*)csrf_token=([0-9a-f]+)/);
+	return match ? match[1] : "";
+}
+document.addEventListener("htmx:configRequest", function(evt) {
+	evt.detail.headers["X-CSRF-Token"] = csrfToken();
+});
+// Ask before submitting forms with a data-confirm message.
+document.addEventListener("submit", function(evt) {
+	var form = evt.target;
+	var message = form.dataset.confirm;
+	if (message && !confirm(message)) {
+		evt.preventDefault();
+		return;
+	}
+	if (form.method === "post" && !form.elements.csrf_token) {
+		var input = document.createElement("input");
+		input.type = "hidden";
+		input.name = "csrf_token";
+		input.value = csrfToken();
+		form.appendChild(input);
+	}
+});
+// Remember the browser's time zone so timestamps are rendered in it, and
+// reload once it is first known. Without cookies the page stays in UTC.
+(function() {
+	var zone = Intl.DateTimeFormat().resolvedOptions().timeZone;
+	var cookie = "demo_app_tz=" + encodeURIComponent(zone || "");
+	if (!zone || document.cookie.indexOf(cookie) !== -1) {
+		return;
+	}
+	document.cookie = cookie + "; path=/; max-age=31536000; SameSite=Lax";
+	if (document.cookie.indexOf(cookie) !== -1) {
+		location.reload();
+	}
+})();
+// Serve the dashboard from the service worker's cache when offline.
+if ("serviceWorker" in navigator) {
+	navigator.serviceWorker.register("/sw.js");
+}
+// Show when the data on the page was cached if it comes from the offline
+// cache, which marks cached fragments with X-Cached-At and cached pages with
+// data-cached-at.
+function showFreshness(cachedAt) {
+	var el = document.getElementById("freshness");
+	if (!el) {
+		return;
+	}
+	if (!cachedAt) {
+		el.hidden = true;
+		return;
+	}
+	var at = new Date(cachedAt * 1000).toLocaleString(document.documentElement.lang);
+	el.textContent = el.dataset.template.replace("%s", at);
+	el.hidden = false;
+}
+document.addEventListener("DOMContentLoaded", function() {
+	var el = document.getElementById("freshness");
+	if (el && el.dataset.cachedAt) {
+		showFreshness(Number(el.dataset.cachedAt));
+	}
+});
+document.addEventListener("htmx:afterRequest", function(evt) {
+	if (evt.detail.xhr.status) {
+		showFreshness(Number(evt.detail.xhr.getResponseHeader("X-Cached-At")));
+	}
+});
+// Submit the form of controls marked data-autosubmit when they change.
+document.addEventListener("change", function(evt) {
+	if (evt.target.dataset.autosubmit !== undefined && evt.target.form) {
+		evt.target.form.submit();
+	}
+});
+`
+
+// contentSecurityPolicy restricts pages to their own origin, the htmx script and
+// the hashed layout script. Device data is attacker-controlled, so this limits
+// the damage should it ever reach the page unescaped. htmx evaluates hx-trigger
+// filters such as [!this.querySelector(...)] with Function, which needs
+// 'unsafe-eval'; inline styles are used throughout the templates.
+var contentSecurityPolicy = strings.Join([]string{
+	"default-src 'self'",
+	"script-src 'self' https://unpkg.com 'unsafe-eval' " + scriptHash(layoutScript),
+	"style-src 'self' 'unsafe-inline'",
+	"img-src 'self' data:",
+	"connect-src 'self'",
+	"object-src 'none'",
+	"base-uri 'self'",
+	"form-action 'self'",
+	"frame-ancestors 'none'",
+}, "; ")
+
+// scriptHash returns the CSP source expression allowing an inline script.
+func scriptHash(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}
+
+// securityHeadersMiddleware sets headers that harden every response against
+// content sniffing, framing and script injection.
+func securityHeadersMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Content-Security-Policy", contentSecurityPolicy)
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+		h.Set("Permissions-Policy", "camera=(), microphone=(), geolocation=()")
+		h.Set("Cross-Origin-Opener-Policy", "same-origin")
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// devicePath returns the path of a device route, escaping the device ID so IDs
+// containing "/", "?", "#" or ".." cannot point a link at another route.
+func devicePath(prefix, deviceID string, suffix ...string) string {
+	return prefix + url.PathEscape(deviceID) + strings.Join(suffix, "")
+}
@@ -0,0 +1,75 @@
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Security", func() {
+	// hostile is device data as an attacker could publish it to the queue
+	hostile := &iotv1.IoTDevice{
+		DeviceId: "../admin/alerts",
+		Location: `<script>alert("location")</script>`,
+		Firmware: `"><img src=x onerror=alert(1)>`,
+	}
+
+	It("should set security headers on every response", func() {
+		server := &Server{logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))}
+		rec := httptest.NewRecorder()
+
+		server.setupRoutes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		Expect(rec.Header().Get("Content-Security-Policy")).To(Equal(contentSecurityPolicy))
+		Expect(rec.Header().Get("X-Content-Type-Options")).To(Equal("nosniff"))
+		Expect(rec.Header().Get("X-Frame-Options")).To(Equal("DENY"))
+		Expect(rec.Header().Get("Referrer-Policy")).NotTo(BeEmpty())
+	})
+
+	It("should allow the layout script by hash and no other inline script", func() {
+		var buf bytes.Buffer
+		Expect(index().Render(context.Background(), &buf)).To(Succeed())
+
+		Expect(buf.String()).To(ContainSubstring("<script>" + layoutScript + "</script>"))
+		Expect(contentSecurityPolicy).To(ContainSubstring(scriptHash(layoutScript)))
+		Expect(contentSecurityPolicy).NotTo(MatchRegexp(`script-src[^;]*'unsafe-inline'`))
+	})
+
+	It("should escape device-provided strings in the device list and detail page", func() {
+		var list, detail bytes.Buffer
+		Expect(devicesList([]*iotv1.IoTDevice{hostile}).Render(context.Background(), &list)).To(Succeed())
+		Expect(device(hostile, nil, readingsPage{DeviceID: hostile.GetDeviceId()}).Render(context.Background(), &detail)).To(Succeed())
+
+		for _, html := range []string{list.String(), detail.String()} {
+			Expect(html).NotTo(ContainSubstring(hostile.GetLocation()))
+			Expect(html).NotTo(ContainSubstring("<img"))
+			Expect(html).To(ContainSubstring("&lt;script&gt;"))
+			Expect(html).NotTo(ContainSubstring(`"/device/../admin/alerts"`))
+		}
+		Expect(list.String()).To(ContainSubstring(`href="/device/..%2Fadmin%2Falerts"`))
+		Expect(detail.String()).To(ContainSubstring(`/api/device/..%2Fadmin%2Falerts/readings`))
+	})
+
+	It("should not use inline event handlers, which the policy blocks", func() {
+		var buf bytes.Buffer
+		Expect(device(hostile, nil, readingsPage{DeviceID: hostile.GetDeviceId()}).Render(context.Background(), &buf)).To(Succeed())
+		Expect(batteryReportPage(batteryReport{}).Render(context.Background(), &buf)).To(Succeed())
+		Expect(alertRules([]*iotv1.AlertRule{{Id: 1, Name: "High temperature"}}).Render(context.Background(), &buf)).To(Succeed())
+
+		Expect(regexp.MustCompile(`<[^>]+\son[a-z]+=`).FindString(buf.String())).To(BeEmpty())
+	})
+
+	It("should escape device IDs in paths", func() {
+		Expect(devicePath("/device/", "a/b?c#d")).To(Equal("/device/a%2Fb%3Fc%23d"))
+		Expect(devicePath("/api/device/", "sensor-1", "/readings")).To(Equal("/api/device/sensor-1/readings"))
+	})
+})
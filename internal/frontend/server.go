@@ -8,41 +8,124 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/encoding/gzip" // Advertises gzip so the backend compresses large responses
 	"google.golang.org/grpc/status"
 
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/pkg/listener"
 	"procodus.dev/demo-app/pkg/metrics"
+	"procodus.dev/demo-app/pkg/session"
 )
 
 // Server represents the frontend HTTP server.
 type Server struct {
-	logger     *slog.Logger
-	httpServer *http.Server
-	grpcClient iot.IoTServiceClient
-	grpcConn   *grpc.ClientConn
-	config     *ServerConfig
-	metrics    *metrics.FrontendMetrics // Optional metrics
+	logger      *slog.Logger
+	httpServer  *http.Server
+	pprofServer *http.Server
+	grpcClient  iotv1.IoTServiceClient
+	grpcConn    *grpc.ClientConn
+	backend     backendStatus
+	backendInfo backendInfo
+	config      *ServerConfig
+	metrics     *metrics.FrontendMetrics // Optional metrics
+	sessions    *session.Manager         // Optional sessions
+	cors        *CORSConfig              // Optional CORS, nil = same-origin only
+	embed       *EmbedConfig             // Optional embedding, nil = disabled
+	statusPage  bool                     // Serve the public status page
+	timeouts    TimeoutConfig            // Zero fields use the defaults
+	maxBody     int64                    // Largest request body, 0 = default
+	middleware  MiddlewareChain          // Optional, nil = built-in chain
 }
 
 // ServerConfig holds the configuration for the Server.
 type ServerConfig struct {
-	// Backend gRPC configuration
+	// BackendGRPCAddr is the backend address, or a comma-separated list of
+	// backend replicas. A host name is resolved through DNS and every address
+	// it resolves to is used.
 	BackendGRPCAddr string
+	// BackendLoadBalancing is the policy spreading calls over the backends:
+	// LoadBalancingRoundRobin (default) or LoadBalancingPickFirst.
+	BackendLoadBalancing string
+	// BackendMaxRecvMsgSize is the largest backend response in bytes
+	// (optional, 0 = gRPC default of 4 MiB)
+	BackendMaxRecvMsgSize int
+	// BackendStartupTimeout is how long startup waits for the backend
+	// connection (optional, default 10 seconds)
+	BackendStartupTimeout time.Duration
+	// BackendFailFast makes Run fail when the backend is not reachable within
+	// BackendStartupTimeout. Otherwise the frontend starts anyway, pages show
+	// the backend as unavailable and the connection is retried in the background.
+	BackendFailFast bool
 
 	Logger *slog.Logger
 
 	// HTTP server configuration
 	HTTPPort int
+	// HTTPBindAddress is the host or IP, such as 127.0.0.1 or [::1], the HTTP
+	// server listens on, or unix:///path to serve on a Unix socket instead of
+	// HTTPPort (optional, empty = all IPv4 and IPv6 interfaces)
+	HTTPBindAddress string
+	// Timeouts of the HTTP server and backend calls (optional, zero fields
+	// use the defaults)
+	Timeouts TimeoutConfig
+	// MaxHeaderBytes bounds the request headers (optional, 0 = 1 MiB)
+	MaxHeaderBytes int
+	// MaxBodyBytes bounds request bodies such as form posts; larger requests
+	// get 413 (optional, 0 = 1 MiB)
+	MaxBodyBytes int64
 
 	// Metrics configuration (optional)
 	Metrics *metrics.FrontendMetrics
+
+	// PprofPort is the HTTP port for the pprof debug server (optional, 0 = disabled)
+	PprofPort int
+	// PprofBindAddress is the host or IP the pprof debug server listens on
+	// (optional, empty = all IPv4 and IPv6 interfaces)
+	PprofBindAddress string
+
+	// TenantID is sent to the backend with every call for quota accounting (optional)
+	TenantID string
+
+	// CORS lets pages on other origins call the /api/ routes (optional,
+	// zero = same-origin only)
+	CORS CORSConfig
+
+	// Embed lets other sites frame read-only device panels (optional,
+	// zero = disabled)
+	Embed EmbedConfig
+
+	// StatusPage serves a summary of the fleet and backend health with the
+	// uptime history of its components at /status. It shows no device data,
+	// so it can be exposed publicly (optional, default disabled)
+	StatusPage bool
+
+	// Sessions stores preferences and CSRF tokens server-side (optional,
+	// nil = keep them in cookies only). Use a shared store such as Redis when
+	// running several replicas.
+	Sessions *session.Manager
+
+	// Middleware customizes the middleware chain in front of the routes, e.g.
+	// to add company headers or authentication, see MiddlewareChain
+	// (optional, nil = built-in chain)
+	Middleware MiddlewareChain
+
+	// HandleSignals makes Run shut down on SIGINT and SIGTERM. The frontend
+	// command sets it; servers embedded with others in one process leave it
+	// unset and stop when the context passed to Run is canceled.
+	HandleSignals bool
+
+	// StartupConfig is the resolved configuration logged with the startup
+	// event, as key-value pairs with secrets redacted (optional)
+	StartupConfig []any
 }
 
 // NewServer creates a new frontend Server instance.
@@ -55,19 +138,72 @@ func NewServer(cfg *ServerConfig) (*Server, error) {
 		return nil, errors.New("logger cannot be nil")
 	}
 
-	if cfg.HTTPPort <= 0 {
-		return nil, errors.New("HTTP port must be positive")
+	if err := listener.Validate("HTTP", cfg.HTTPBindAddress, cfg.HTTPPort); err != nil {
+		return nil, err
+	}
+
+	if cfg.PprofPort > 0 {
+		if err := listener.Validate("pprof", cfg.PprofBindAddress, cfg.PprofPort); err != nil {
+			return nil, err
+		}
 	}
 
 	if cfg.BackendGRPCAddr == "" {
 		return nil, errors.New("backend gRPC address cannot be empty")
 	}
 
-	return &Server{
-		logger:  cfg.Logger,
-		config:  cfg,
-		metrics: cfg.Metrics,
-	}, nil
+	if err := cfg.CORS.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Embed.validate(); err != nil {
+		return nil, err
+	}
+
+	if _, _, err := backendDialOptions(cfg.BackendGRPCAddr, cfg.BackendLoadBalancing); err != nil {
+		return nil, err
+	}
+
+	if cfg.BackendMaxRecvMsgSize < 0 {
+		return nil, errors.New("backend max receive message size cannot be negative")
+	}
+
+	if cfg.BackendStartupTimeout < 0 {
+		return nil, errors.New("backend startup timeout cannot be negative")
+	}
+
+	if err := cfg.Timeouts.validate(); err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxHeaderBytes < 0 || cfg.MaxBodyBytes < 0 {
+		return nil, errors.New("max header and body bytes cannot be negative")
+	}
+
+	server := &Server{
+		logger:     cfg.Logger,
+		config:     cfg,
+		metrics:    cfg.Metrics,
+		sessions:   cfg.Sessions,
+		statusPage: cfg.StatusPage,
+		timeouts:   cfg.Timeouts,
+		maxBody:    cfg.MaxBodyBytes,
+	}
+	if cfg.CORS.enabled() {
+		server.cors = &cfg.CORS
+	}
+	if cfg.Embed.enabled() {
+		server.embed = &cfg.Embed
+	}
+
+	if cfg.Middleware != nil {
+		server.middleware = cfg.Middleware
+		if err := validateMiddleware(server.middlewareChain(http.NewServeMux())); err != nil {
+			return nil, fmt.Errorf("invalid middleware chain: %w", err)
+		}
+	}
+
+	return server, nil
 }
 
 // Run starts the frontend server and blocks until shutdown.
@@ -78,49 +214,130 @@ func (s *Server) Run(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// Set up signal handling
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+	// Set up signal handling unless the embedding process handles signals;
+	// receiving from the nil channel otherwise blocks forever
+	var sigChan chan os.Signal
+	if s.config.HandleSignals {
+		sigChan = make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+		defer signal.Stop(sigChan)
+	}
 
 	// Connect to backend gRPC server
 	s.logger.Info("connecting to backend gRPC server", "address", s.config.BackendGRPCAddr)
-	conn, err := grpc.NewClient(
-		s.config.BackendGRPCAddr,
+	dialOpts := []grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	}
+	if s.config.TenantID != "" {
+		dialOpts = append(dialOpts, grpc.WithUnaryInterceptor(tenantInterceptor(s.config.TenantID)))
+	}
+	if s.config.BackendMaxRecvMsgSize > 0 {
+		dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(s.config.BackendMaxRecvMsgSize)))
+	}
+
+	target, balancingOpts, err := backendDialOptions(s.config.BackendGRPCAddr, s.config.BackendLoadBalancing)
+	if err != nil {
+		return err
+	}
+	dialOpts = append(dialOpts, balancingOpts...)
+
+	conn, err := grpc.NewClient(target, dialOpts...)
 	if err != nil {
 		return fmt.Errorf("failed to connect to backend: %w", err)
 	}
 	s.grpcConn = conn
-	s.grpcClient = iot.NewIoTServiceClient(conn)
+	s.grpcClient = iotv1.NewIoTServiceClient(conn)
+
+	go s.watchBackend(ctx, conn)
+	go s.pollBackendInfo(ctx)
 
-	s.logger.Info("connected to backend gRPC server")
+	// NewClient connects lazily, so probe the backend to find a wrong
+	// address or an unreachable backend at startup
+	startupTimeout := s.config.BackendStartupTimeout
+	if startupTimeout == 0 {
+		startupTimeout = defaultBackendStartupTimeout
+	}
+	probeCtx, probeCancel := context.WithTimeout(ctx, startupTimeout)
+	err = waitForBackend(probeCtx, conn)
+	probeCancel()
+
+	backendReachable := err == nil
+	switch {
+	case backendReachable:
+		s.logger.Info("connected to backend gRPC server")
+	case s.config.BackendFailFast:
+		if closeErr := conn.Close(); closeErr != nil {
+			s.logger.Error("failed to close gRPC connection", "error", closeErr)
+		}
+		s.grpcConn = nil
+		return fmt.Errorf("backend not reachable at %s: %w", s.config.BackendGRPCAddr, err)
+	default:
+		s.logger.Warn("backend not reachable, retrying in the background",
+			"address", s.config.BackendGRPCAddr,
+			"error", err,
+		)
+	}
 
 	// Create HTTP router
 	mux := s.setupRoutes()
 
+	// Listen before serving so a taken address fails startup
+	lis, err := listener.Listen(s.config.HTTPBindAddress, s.config.HTTPPort)
+	if err != nil {
+		if closeErr := conn.Close(); closeErr != nil {
+			s.logger.Error("failed to close gRPC connection", "error", closeErr)
+		}
+		s.grpcConn = nil
+		return err
+	}
+
 	// Create HTTP server
+	timeouts := s.timeouts.withDefaults()
+	maxHeaderBytes := s.config.MaxHeaderBytes
+	if maxHeaderBytes == 0 {
+		maxHeaderBytes = defaultMaxHeaderBytes
+	}
 	s.httpServer = &http.Server{
-		Addr:              fmt.Sprintf(":%d", s.config.HTTPPort),
 		Handler:           mux,
-		ReadHeaderTimeout: 10 * time.Second,
-		ReadTimeout:       30 * time.Second,
-		WriteTimeout:      30 * time.Second,
-		IdleTimeout:       120 * time.Second,
+		ReadHeaderTimeout: timeouts.ReadHeader,
+		ReadTimeout:       timeouts.Read,
+		WriteTimeout:      timeouts.Write,
+		IdleTimeout:       timeouts.Idle,
+		MaxHeaderBytes:    maxHeaderBytes,
 	}
 
-	s.logger.Info("starting HTTP server", "address", s.httpServer.Addr)
+	s.logger.Info("starting HTTP server", "address", lis.Addr().String())
 
 	// Start HTTP server in goroutine
 	httpErr := make(chan error, 1)
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := s.httpServer.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			httpErr <- fmt.Errorf("HTTP server error: %w", err)
 		}
 		close(httpErr)
 	}()
 
-	s.logger.Info("frontend server started successfully")
+	// Start pprof debug server if configured
+	if s.config.PprofPort > 0 {
+		s.pprofServer = &http.Server{
+			Handler:           metrics.DebugHandler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		// The frontend runs on without pprof when its address is taken
+		if pprofLis, err := listener.Listen(s.config.PprofBindAddress, s.config.PprofPort); err != nil {
+			s.logger.Error("pprof server error", "error", err)
+		} else {
+			s.logger.Info("starting pprof debug server", "address", pprofLis.Addr().String())
+			go func() {
+				if err := s.pprofServer.Serve(pprofLis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					s.logger.Error("pprof server error", "error", err)
+				}
+			}()
+		}
+	}
+
+	s.logStartup(ctx, backendReachable)
 
 	// Wait for shutdown signal or HTTP error
 	select {
@@ -160,6 +377,13 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.logger.Info("HTTP server stopped")
 	}
 
+	// Shutdown pprof debug server
+	if s.pprofServer != nil {
+		if err := s.pprofServer.Shutdown(ctx); err != nil {
+			s.logger.Error("failed to shutdown pprof server", "error", err)
+		}
+	}
+
 	// Close gRPC connection
 	if s.grpcConn != nil {
 		s.logger.Info("closing gRPC connection")
@@ -189,6 +413,9 @@ func (s *Server) setupRoutes() http.Handler {
 	// Health check
 	mux.HandleFunc("GET /health", s.handleHealth)
 
+	// Backend connection state for debugging
+	mux.HandleFunc("GET /debug/status", s.handleDebugStatus)
+
 	// Prometheus metrics endpoint (if metrics enabled)
 	if s.metrics != nil {
 		mux.Handle("GET /metrics", metrics.Handler())
@@ -197,10 +424,51 @@ func (s *Server) setupRoutes() http.Handler {
 	// API endpoints for htmx
 	mux.HandleFunc("GET /api/devices", s.handleAPIDevices)
 	mux.HandleFunc("GET /api/device/{id}/readings", s.handleAPIDeviceReadings)
+	mux.HandleFunc("GET /api/device/{id}/notes", s.requireCapability(iotv1.CapabilityDeviceNotes, s.handleAPIDeviceNotes))
+	mux.HandleFunc("GET /api/device/{id}/locations", s.requireCapability(iotv1.CapabilityLocationHistory, s.handleAPIDeviceLocations))
+	mux.HandleFunc("GET /api/quota", s.handleQuotaBanner)
+
+	// API description
+	mux.HandleFunc("GET /openapi.json", s.handleOpenAPISpec)
+	mux.HandleFunc("GET /docs/api", s.handleAPIDocs)
 
 	// Main pages
 	mux.HandleFunc("GET /devices", s.handleDevices)
 	mux.HandleFunc("GET /device/{id}", s.handleDevice)
+	mux.HandleFunc("GET /compare", s.requireCapability(iotv1.CapabilityReadingSeries, s.handleCompare))
+	mux.HandleFunc("GET /reports/battery", s.requireCapability(iotv1.CapabilityBatteryReport, s.handleBatteryReport))
+
+	// Device maintenance notes
+	mux.HandleFunc("POST /device/{id}/notes", s.requireCapability(iotv1.CapabilityDeviceNotes, s.handleCreateDeviceNote))
+	mux.HandleFunc("POST /device/{id}/notes/{note}/delete", s.requireCapability(iotv1.CapabilityDeviceNotes, s.handleDeleteDeviceNote))
+
+	// Device trash
+	mux.HandleFunc("POST /device/{id}/delete", s.requireCapability(iotv1.CapabilityDeviceTrash, s.handleDeleteDevice))
+	mux.HandleFunc("GET /trash", s.requireCapability(iotv1.CapabilityDeviceTrash, s.handleTrash))
+	mux.HandleFunc("POST /trash/{id}/restore", s.requireCapability(iotv1.CapabilityDeviceTrash, s.handleRestoreDevice))
+
+	// Alert rule administration
+	mux.HandleFunc("GET /admin/alerts", s.requireCapability(iotv1.CapabilityAlertRules, s.handleAlertRules))
+	mux.HandleFunc("GET /admin/alerts/new", s.requireCapability(iotv1.CapabilityAlertRules, s.handleNewAlertRule))
+	mux.HandleFunc("POST /admin/alerts", s.requireCapability(iotv1.CapabilityAlertRules, s.handleCreateAlertRule))
+	mux.HandleFunc("GET /admin/alerts/{id}/edit", s.requireCapability(iotv1.CapabilityAlertRules, s.handleEditAlertRule))
+	mux.HandleFunc("POST /admin/alerts/{id}", s.requireCapability(iotv1.CapabilityAlertRules, s.handleUpdateAlertRule))
+	mux.HandleFunc("POST /admin/alerts/{id}/delete", s.requireCapability(iotv1.CapabilityAlertRules, s.handleDeleteAlertRule))
+
+	// Read-only device panels for other sites (if embedding enabled)
+	if s.embed != nil {
+		mux.HandleFunc("GET /embed/device/{id}", s.handleEmbedDevice)
+	}
+
+	// Public fleet health summary (if enabled)
+	if s.statusPage {
+		mux.HandleFunc("GET /status", s.handleStatusPage)
+	}
+
+	// Offline app shell
+	mux.HandleFunc("GET /sw.js", s.handleServiceWorker)
+	mux.HandleFunc("GET /manifest.webmanifest", s.handleManifest)
+	mux.HandleFunc("GET /offline", s.handleOffline)
 
 	// Serve static files (must be before catch-all routes)
 	mux.HandleFunc("GET /static/", s.handleStatic)
@@ -208,23 +476,29 @@ func (s *Server) setupRoutes() http.Handler {
 	// Index page (catch-all, must be last)
 	mux.HandleFunc("GET /{$}", s.handleIndex)
 
-	// Wrap with metrics middleware if metrics are enabled
-	if s.metrics != nil {
-		return s.metricsMiddleware(mux)
+	// The first middleware of the chain wraps all others
+	var handler http.Handler = mux
+	for _, m := range slices.Backward(s.middlewareChain(mux)) {
+		handler = m.Wrap(handler)
 	}
 
-	return mux
+	return handler
 }
 
 // metricsMiddleware wraps HTTP handlers with Prometheus metrics tracking.
-func (s *Server) metricsMiddleware(next http.Handler) http.Handler {
+// Requests are labeled by the matched route pattern (e.g. "/device/{id}") rather than
+// the raw URL path, keeping label cardinality bounded. mux is only used to find
+// the route; requests are served by next.
+func (s *Server) metricsMiddleware(mux *http.ServeMux, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeLabel(mux, r)
+
 		// Track in-flight requests
-		s.metrics.HTTPRequestsInFlight.WithLabelValues(r.Method, r.URL.Path).Inc()
-		defer s.metrics.HTTPRequestsInFlight.WithLabelValues(r.Method, r.URL.Path).Dec()
+		s.metrics.HTTPRequestsInFlight.WithLabelValues(r.Method, route).Inc()
+		defer s.metrics.HTTPRequestsInFlight.WithLabelValues(r.Method, route).Dec()
 
 		// Track duration
-		timer := prometheus.NewTimer(s.metrics.HTTPRequestDuration.WithLabelValues(r.Method, r.URL.Path))
+		timer := prometheus.NewTimer(s.metrics.HTTPRequestDuration.WithLabelValues(r.Method, route))
 		defer timer.ObserveDuration()
 
 		// Create response writer wrapper to capture status code and size
@@ -234,11 +508,30 @@ func (s *Server) metricsMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(rw, r)
 
 		// Track request completion
-		s.metrics.HTTPRequestsTotal.WithLabelValues(r.Method, r.URL.Path, strconv.Itoa(rw.statusCode)).Inc()
-		s.metrics.HTTPResponseSize.WithLabelValues(r.URL.Path).Observe(float64(rw.bytesWritten))
+		s.metrics.HTTPRequestsTotal.WithLabelValues(r.Method, route, strconv.Itoa(rw.statusCode)).Inc()
+		s.metrics.HTTPResponseSize.WithLabelValues(route).Observe(float64(rw.bytesWritten))
 	})
 }
 
+// unmatchedRoute is the metrics label used for requests that match no registered route.
+const unmatchedRoute = "unmatched"
+
+// routeLabel returns the path part of the route pattern the mux would dispatch r to.
+// The method prefix of patterns like "GET /device/{id}" is stripped since the method
+// is recorded as a separate label.
+func routeLabel(mux *http.ServeMux, r *http.Request) string {
+	_, pattern := mux.Handler(r)
+	if pattern == "" {
+		return unmatchedRoute
+	}
+
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		return path
+	}
+
+	return pattern
+}
+
 // responseWriter wraps http.ResponseWriter to capture status code and bytes written.
 type responseWriter struct {
 	http.ResponseWriter
@@ -260,7 +553,7 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 }
 
 // callGetAllDevice wraps gRPC GetAllDevice call with metrics.
-func (s *Server) callGetAllDevice(ctx context.Context, req *iot.GetAllDevicesRequest) (*iot.GetAllDevicesResponse, error) {
+func (s *Server) callGetAllDevice(ctx context.Context, req *iotv1.GetAllDevicesRequest) (*iotv1.GetAllDevicesResponse, error) {
 	if s.metrics == nil {
 		return s.grpcClient.GetAllDevice(ctx, req)
 	}
@@ -289,7 +582,7 @@ func (s *Server) callGetAllDevice(ctx context.Context, req *iot.GetAllDevicesReq
 }
 
 // callGetDevice wraps gRPC GetDevice call with metrics.
-func (s *Server) callGetDevice(ctx context.Context, req *iot.GetDeviceByIDRequest) (*iot.GetDeviceByIDResponse, error) {
+func (s *Server) callGetDevice(ctx context.Context, req *iotv1.GetDeviceByIDRequest) (*iotv1.GetDeviceByIDResponse, error) {
 	if s.metrics == nil {
 		return s.grpcClient.GetDevice(ctx, req)
 	}
@@ -318,7 +611,7 @@ func (s *Server) callGetDevice(ctx context.Context, req *iot.GetDeviceByIDReques
 }
 
 // callGetSensorReadingByDeviceID wraps gRPC GetSensorReadingByDeviceID call with metrics.
-func (s *Server) callGetSensorReadingByDeviceID(ctx context.Context, req *iot.GetSensorReadingByDeviceIDRequest) (*iot.GetSensorReadingByDeviceIDResponse, error) {
+func (s *Server) callGetSensorReadingByDeviceID(ctx context.Context, req *iotv1.GetSensorReadingByDeviceIDRequest) (*iotv1.GetSensorReadingByDeviceIDResponse, error) {
 	if s.metrics == nil {
 		return s.grpcClient.GetSensorReadingByDeviceID(ctx, req)
 	}
@@ -345,3 +638,61 @@ func (s *Server) callGetSensorReadingByDeviceID(ctx context.Context, req *iot.Ge
 	s.metrics.GRPCClientCalls.WithLabelValues("GetSensorReadingByDeviceID", "success").Inc()
 	return resp, nil
 }
+
+// callCountReadings wraps gRPC CountReadings call with metrics.
+func (s *Server) callCountReadings(ctx context.Context, req *iotv1.CountReadingsRequest) (*iotv1.CountReadingsResponse, error) {
+	if s.metrics == nil {
+		return s.grpcClient.CountReadings(ctx, req)
+	}
+
+	// Track duration
+	timer := prometheus.NewTimer(s.metrics.GRPCClientDuration.WithLabelValues("CountReadings"))
+	defer timer.ObserveDuration()
+
+	// Make the call
+	resp, err := s.grpcClient.CountReadings(ctx, req)
+
+	// Track result
+	if err != nil {
+		s.metrics.GRPCClientCalls.WithLabelValues("CountReadings", "error").Inc()
+		// Categorize error type
+		if st, ok := status.FromError(err); ok {
+			s.metrics.GRPCClientErrors.WithLabelValues("CountReadings", st.Code().String()).Inc()
+		} else {
+			s.metrics.GRPCClientErrors.WithLabelValues("CountReadings", "unknown").Inc()
+		}
+		return nil, err
+	}
+
+	s.metrics.GRPCClientCalls.WithLabelValues("CountReadings", "success").Inc()
+	return resp, nil
+}
+
+// callGetSensorReadingSeriesBatch wraps gRPC GetSensorReadingSeriesBatch call with metrics.
+func (s *Server) callGetSensorReadingSeriesBatch(ctx context.Context, req *iotv1.GetSensorReadingSeriesBatchRequest) (*iotv1.GetSensorReadingSeriesBatchResponse, error) {
+	if s.metrics == nil {
+		return s.grpcClient.GetSensorReadingSeriesBatch(ctx, req)
+	}
+
+	// Track duration
+	timer := prometheus.NewTimer(s.metrics.GRPCClientDuration.WithLabelValues("GetSensorReadingSeriesBatch"))
+	defer timer.ObserveDuration()
+
+	// Make the call
+	resp, err := s.grpcClient.GetSensorReadingSeriesBatch(ctx, req)
+
+	// Track result
+	if err != nil {
+		s.metrics.GRPCClientCalls.WithLabelValues("GetSensorReadingSeriesBatch", "error").Inc()
+		// Categorize error type
+		if st, ok := status.FromError(err); ok {
+			s.metrics.GRPCClientErrors.WithLabelValues("GetSensorReadingSeriesBatch", st.Code().String()).Inc()
+		} else {
+			s.metrics.GRPCClientErrors.WithLabelValues("GetSensorReadingSeriesBatch", "unknown").Inc()
+		}
+		return nil, err
+	}
+
+	s.metrics.GRPCClientCalls.WithLabelValues("GetSensorReadingSeriesBatch", "success").Inc()
+	return resp, nil
+}
@@ -2,12 +2,16 @@ package frontend_test
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
 
 	"procodus.dev/demo-app/internal/frontend"
 )
@@ -73,6 +77,18 @@ var _ = Describe("Frontend Server", func() {
 					Expect(server).NotTo(BeNil())
 				}
 			})
+			It("should accept IPv6 and Unix socket bind addresses", func() {
+				for _, bind := range []string{"::", "[::1]", "127.0.0.1", "unix:///run/demo-app/http.sock"} {
+					server, err := frontend.NewServer(&frontend.ServerConfig{
+						Logger:          logger,
+						HTTPPort:        8080,
+						HTTPBindAddress: bind,
+						BackendGRPCAddr: "localhost:9090",
+					})
+					Expect(err).NotTo(HaveOccurred(), bind)
+					Expect(server).NotTo(BeNil())
+				}
+			})
 		})
 
 		Context("with invalid configuration", func() {
@@ -134,6 +150,80 @@ var _ = Describe("Frontend Server", func() {
 				Expect(err.Error()).To(ContainSubstring("backend gRPC address"))
 				Expect(server).To(BeNil())
 			})
+
+			It("should return error when the HTTP bind address includes a port", func() {
+				config := &frontend.ServerConfig{
+					Logger:          logger,
+					HTTPPort:        8080,
+					HTTPBindAddress: "[::1]:8080",
+					BackendGRPCAddr: "localhost:9090",
+				}
+
+				server, err := frontend.NewServer(config)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("without port"))
+				Expect(server).To(BeNil())
+			})
+
+			It("should return error when backend max receive message size is negative", func() {
+				config := &frontend.ServerConfig{
+					Logger:                logger,
+					HTTPPort:              8080,
+					BackendGRPCAddr:       "localhost:9090",
+					BackendMaxRecvMsgSize: -1,
+				}
+
+				server, err := frontend.NewServer(config)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("max receive message size"))
+				Expect(server).To(BeNil())
+			})
+
+			It("should return error when the load balancing policy is unknown", func() {
+				config := &frontend.ServerConfig{
+					Logger:               logger,
+					HTTPPort:             8080,
+					BackendGRPCAddr:      "backend-1:9090,backend-2:9090",
+					BackendLoadBalancing: "random",
+				}
+
+				server, err := frontend.NewServer(config)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("load balancing policy"))
+				Expect(server).To(BeNil())
+			})
+
+			It("should return error when backend startup timeout is negative", func() {
+				config := &frontend.ServerConfig{
+					Logger:                logger,
+					HTTPPort:              8080,
+					BackendGRPCAddr:       "localhost:9090",
+					BackendStartupTimeout: -time.Second,
+				}
+
+				server, err := frontend.NewServer(config)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("startup timeout"))
+				Expect(server).To(BeNil())
+			})
+
+			It("should return error for invalid timeouts and limits", func() {
+				for _, config := range []*frontend.ServerConfig{
+					{Timeouts: frontend.TimeoutConfig{Read: -time.Second}},
+					{Timeouts: frontend.TimeoutConfig{BackendCall: -time.Second}},
+					{Timeouts: frontend.TimeoutConfig{Write: time.Second}},
+					{MaxHeaderBytes: -1},
+					{MaxBodyBytes: -1},
+				} {
+					config.Logger = logger
+					config.HTTPPort = 8080
+					config.BackendGRPCAddr = "localhost:9090"
+
+					server, err := frontend.NewServer(config)
+					Expect(err).To(HaveOccurred())
+					Expect(server).To(BeNil())
+				}
+			})
 		})
 	})
 
@@ -183,6 +273,174 @@ var _ = Describe("Frontend Server", func() {
 				Eventually(done, 1*time.Second).Should(Receive())
 			})
 		})
+
+		Context("with a bind address", func() {
+			It("should only serve on the bound IPv6 address", func() {
+				if probe, err := net.Listen("tcp", "[::1]:0"); err != nil {
+					Skip("IPv6 unavailable: " + err.Error())
+				} else {
+					Expect(probe.Close()).To(Succeed())
+				}
+
+				server, err := frontend.NewServer(&frontend.ServerConfig{
+					Logger:                logger,
+					HTTPPort:              8088,
+					HTTPBindAddress:       "[::1]",
+					BackendGRPCAddr:       "127.0.0.1:1",
+					BackendStartupTimeout: 100 * time.Millisecond,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				done := make(chan error, 1)
+				go func() {
+					done <- server.Run(ctx)
+				}()
+				DeferCleanup(func() {
+					cancel()
+					Eventually(done, 5*time.Second).Should(Receive())
+				})
+
+				client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+				Eventually(func() error {
+					resp, err := client.Get("http://[::1]:8088/health")
+					if err == nil {
+						_ = resp.Body.Close()
+					}
+					return err
+				}, 5*time.Second).Should(Succeed())
+
+				_, err = client.Get("http://127.0.0.1:8088/health")
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should fail to start when the address is taken", func() {
+				lis, err := net.Listen("tcp", "127.0.0.1:0")
+				Expect(err).NotTo(HaveOccurred())
+				DeferCleanup(lis.Close)
+
+				server, err := frontend.NewServer(&frontend.ServerConfig{
+					Logger:                logger,
+					HTTPPort:              lis.Addr().(*net.TCPAddr).Port,
+					HTTPBindAddress:       "127.0.0.1",
+					BackendGRPCAddr:       "127.0.0.1:1",
+					BackendStartupTimeout: 100 * time.Millisecond,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(server.Run(context.Background())).To(MatchError(ContainSubstring("failed to listen")))
+			})
+		})
+
+		Context("with backend startup probe", func() {
+			// Pooled connections left unused would delay the server shutdown
+			client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+			// debugStatus returns the backend section of /debug/status.
+			debugStatus := func(port string) (map[string]any, error) {
+				resp, err := client.Get("http://localhost:" + port + "/debug/status")
+				if err != nil {
+					return nil, err
+				}
+				defer func() { _ = resp.Body.Close() }()
+
+				var body struct {
+					Backend map[string]any `json:"backend"`
+				}
+				err = json.NewDecoder(resp.Body).Decode(&body)
+				return body.Backend, err
+			}
+
+			// unreachableAddr returns an address nothing listens on.
+			unreachableAddr := func() string {
+				lis, err := net.Listen("tcp", "127.0.0.1:0")
+				Expect(err).NotTo(HaveOccurred())
+				addr := lis.Addr().String()
+				Expect(lis.Close()).To(Succeed())
+				return addr
+			}
+
+			It("should fail fast when the backend is unreachable", func() {
+				server, err := frontend.NewServer(&frontend.ServerConfig{
+					Logger:                logger,
+					HTTPPort:              8085,
+					BackendGRPCAddr:       unreachableAddr(),
+					BackendStartupTimeout: 200 * time.Millisecond,
+					BackendFailFast:       true,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = server.Run(context.Background())
+				Expect(err).To(MatchError(ContainSubstring("backend not reachable")))
+			})
+
+			It("should start degraded and report the connection state otherwise", func() {
+				server, err := frontend.NewServer(&frontend.ServerConfig{
+					Logger:                logger,
+					HTTPPort:              8086,
+					BackendGRPCAddr:       unreachableAddr(),
+					BackendStartupTimeout: 200 * time.Millisecond,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				done := make(chan error, 1)
+				go func() {
+					done <- server.Run(ctx)
+				}()
+				DeferCleanup(func() {
+					cancel()
+					Eventually(done, 5*time.Second).Should(Receive())
+				})
+
+				var status map[string]any
+				Eventually(func() (err error) {
+					status, err = debugStatus("8086")
+					return err
+				}, 5*time.Second).Should(Succeed())
+
+				Expect(status["state"]).NotTo(Equal("READY"))
+				Expect(status).NotTo(HaveKey("last_ready"))
+				Expect(status["fail_fast"]).To(BeFalse())
+			})
+
+			It("should report a ready backend", func() {
+				lis, err := net.Listen("tcp", "127.0.0.1:0")
+				Expect(err).NotTo(HaveOccurred())
+				backend := grpc.NewServer()
+				go func() { _ = backend.Serve(lis) }()
+				DeferCleanup(backend.Stop)
+
+				server, err := frontend.NewServer(&frontend.ServerConfig{
+					Logger:          logger,
+					HTTPPort:        8087,
+					BackendGRPCAddr: lis.Addr().String(),
+					BackendFailFast: true,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				done := make(chan error, 1)
+				go func() {
+					done <- server.Run(ctx)
+				}()
+				DeferCleanup(func() {
+					cancel()
+					var runErr error
+					Eventually(done, 5*time.Second).Should(Receive(&runErr))
+					Expect(runErr).NotTo(HaveOccurred())
+				})
+
+				var status map[string]any
+				Eventually(func(g Gomega) {
+					var err error
+					status, err = debugStatus("8087")
+					g.Expect(err).NotTo(HaveOccurred())
+					g.Expect(status["state"]).To(Equal("READY"))
+				}, 5*time.Second).Should(Succeed())
+				Expect(status).To(HaveKey("last_ready"))
+			})
+		})
 	})
 
 	Describe("Server Shutdown", func() {
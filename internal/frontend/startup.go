@@ -0,0 +1,43 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+
+	"procodus.dev/demo-app/pkg/logger"
+)
+
+// logStartup logs the startup event of the frontend. The version of a
+// reachable backend is fetched first, so the event can report it.
+func (s *Server) logStartup(ctx context.Context, backendReachable bool) {
+	if backendReachable {
+		s.refreshBackendInfo(ctx)
+	}
+
+	backendVersion := s.backendInfo.version()
+	if backendVersion == "" {
+		backendVersion = logger.Unknown
+	}
+
+	logger.LogStartup(s.logger, logger.Startup{
+		Service:      "frontend",
+		Config:       s.config.StartupConfig,
+		Dependencies: map[string]string{"backend": backendVersion},
+		Features:     s.features(),
+		Attrs:        []any{"middleware", middlewareNames(s.middlewareChain(http.NewServeMux()))},
+	})
+}
+
+// features returns the optional features the configuration enables.
+func (s *Server) features() map[string]bool {
+	c := s.config
+	return map[string]bool{
+		"status_page":       c.StatusPage,
+		"embed":             c.Embed.SigningKey != "",
+		"cors":              len(c.CORS.AllowedOrigins) > 0,
+		"server_sessions":   c.Sessions != nil,
+		"metrics":           c.Metrics != nil,
+		"pprof":             c.PprofPort > 0,
+		"backend_fail_fast": c.BackendFailFast,
+	}
+}
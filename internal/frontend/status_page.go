@@ -0,0 +1,168 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// statusPageDays is the length of the uptime history on the status page.
+const statusPageDays = 90
+
+// componentBackendAPI is the backend as reached by the frontend. Unlike the
+// components checked by the backend it has no history.
+const componentBackendAPI = "backend_api"
+
+// componentLabels are the display names of the components on the status page.
+var componentLabels = map[string]string{
+	componentBackendAPI: "Backend API",
+	"database":          "Database",
+	"message_queue":     "Message queue",
+	"ingestion":         "Ingestion",
+}
+
+// statusPage is the view model of the public status page.
+type statusPage struct {
+	// Summary is nil when the backend could not be reached.
+	Summary    *iotv1.GetStatusSummaryResponse
+	Components []componentRow
+}
+
+// operational reports whether every component is up.
+func (p statusPage) operational() bool {
+	for _, c := range p.Components {
+		if !c.Up {
+			return false
+		}
+	}
+	return true
+}
+
+// componentRow is a component on the status page.
+type componentRow struct {
+	Name          string
+	Up            bool
+	CheckedAt     int64
+	UptimePercent float64
+	// Days is the uptime of each day of the history, oldest first, or empty
+	// for components without history.
+	Days []uptimeDay
+}
+
+// uptimeDay is one bar of the uptime history.
+type uptimeDay struct {
+	Day     time.Time
+	Checks  int64
+	Percent float64
+}
+
+// componentLabel returns the display name of a component.
+func componentLabel(ctx context.Context, name string) string {
+	if label, ok := componentLabels[name]; ok {
+		return translate(ctx, label)
+	}
+	return name
+}
+
+// uptimeDayLabel describes the uptime of a day of the history. Days are UTC
+// days, so they are not moved into the time zone of the viewer.
+func uptimeDayLabel(ctx context.Context, day uptimeDay) string {
+	date := day.Day.UTC().Format(localeFromContext(ctx).date)
+	if day.Checks == 0 {
+		return t(ctx, "%s: no data", date)
+	}
+	return t(ctx, "%s: %.2f %% uptime", date, day.Percent)
+}
+
+// uptimeDayClass returns the CSS class coloring a bar of the uptime history.
+func uptimeDayClass(day uptimeDay) string {
+	switch {
+	case day.Checks == 0:
+		return "uptime-day uptime-none"
+	case day.Percent >= 99:
+		return "uptime-day uptime-up"
+	case day.Percent >= 90:
+		return "uptime-day uptime-degraded"
+	default:
+		return "uptime-day uptime-down"
+	}
+}
+
+// newStatusPage builds the status page from the summary of the backend,
+// which is nil when the backend could not be reached.
+func newStatusPage(summary *iotv1.GetStatusSummaryResponse, now time.Time) statusPage {
+	page := statusPage{
+		Summary:    summary,
+		Components: []componentRow{{Name: componentBackendAPI, Up: summary != nil, CheckedAt: now.Unix()}},
+	}
+	if summary == nil {
+		return page
+	}
+
+	today := now.UTC().Truncate(24 * time.Hour)
+	for _, c := range summary.GetComponents() {
+		row := componentRow{
+			Name:          c.GetName(),
+			Up:            c.GetUp(),
+			CheckedAt:     c.GetCheckedAt(),
+			UptimePercent: c.GetUptimePercent(),
+			Days:          make([]uptimeDay, statusPageDays),
+		}
+		for i := range row.Days {
+			row.Days[i].Day = today.AddDate(0, 0, i+1-statusPageDays)
+		}
+		for _, h := range c.GetHistory() {
+			i := statusPageDays - 1 - int(today.Sub(time.Unix(h.GetDay(), 0))/(24*time.Hour))
+			if i < 0 || i >= statusPageDays || h.GetChecks() == 0 {
+				continue
+			}
+			row.Days[i].Checks = h.GetChecks()
+			row.Days[i].Percent = float64(h.GetUpChecks()) / float64(h.GetChecks()) * 100
+		}
+		page.Components = append(page.Components, row)
+	}
+
+	return page
+}
+
+// handleStatusPage serves the public summary of the fleet and backend
+// health. It is rendered even when the backend is unreachable, which it
+// reports with 503 for uptime monitors, so unlike other pages it does not
+// wait for the backend's capabilities to be known.
+func (s *Server) handleStatusPage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	resp, err := trackedCall(s, ctx, "GetStatusSummary", s.grpcClient.GetStatusSummary,
+		&iotv1.GetStatusSummaryRequest{Days: statusPageDays})
+	if status.Code(err) == codes.Unimplemented {
+		s.renderError(w, r, http.StatusNotImplemented, unsupportedMessage)
+		return
+	}
+	if err != nil {
+		s.logger.Warn("failed to fetch status summary", "error", err, "request_id", requestIDFromContext(r.Context()))
+		// The page reports the backend as down instead of failing
+		resp = nil
+	}
+	page := newStatusPage(resp, time.Now())
+
+	// The page is cached briefly to keep load off the backend, but only by
+	// the browser: it may set the CSRF and session cookies and follows the
+	// visitor's language and time zone
+	w.Header().Set("Cache-Control", "private, max-age=30")
+	w.Header().Add("Vary", "Cookie")
+	if page.Summary == nil {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if err := renderStatusPage(r.Context(), w, page, s.metrics); err != nil {
+		s.logger.Error("failed to render status page", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
+	}
+}
@@ -0,0 +1,134 @@
+package frontend
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// statusClient is an IoTServiceClient stub returning a fixed status summary.
+type statusClient struct {
+	iotv1.IoTServiceClient
+	summary *iotv1.GetStatusSummaryResponse
+	err     error
+}
+
+func (c *statusClient) GetStatusSummary(_ context.Context, _ *iotv1.GetStatusSummaryRequest, _ ...grpc.CallOption) (*iotv1.GetStatusSummaryResponse, error) {
+	return c.summary, c.err
+}
+
+var _ = Describe("Status page", func() {
+	var (
+		client *statusClient
+		server *Server
+		today  time.Time
+	)
+
+	BeforeEach(func() {
+		today = time.Now().UTC().Truncate(24 * time.Hour)
+		client = &statusClient{summary: &iotv1.GetStatusSummaryResponse{
+			DeviceCount:       12,
+			ActiveDeviceCount: 9,
+			ReadingsPerMinute: 42.5,
+			Components: []*iotv1.ComponentStatus{
+				{Name: "database", Up: true, CheckedAt: today.Unix(), UptimePercent: 99.5, History: []*iotv1.DailyUptime{
+					{Day: today.AddDate(0, 0, -1).Unix(), Checks: 10, UpChecks: 9},
+					{Day: today.Unix(), Checks: 10, UpChecks: 10},
+				}},
+			},
+		}}
+		server = &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: client,
+			statusPage: true,
+		}
+	})
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.setupRoutes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+		return rec
+	}
+
+	It("should not be served unless enabled", func() {
+		server.statusPage = false
+		Expect(get().Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should summarize the fleet and the components", func() {
+		rec := get()
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Cache-Control")).To(Equal("private, max-age=30"))
+		Expect(rec.Header().Values("Vary")).To(ContainElement("Cookie"))
+
+		body := rec.Body.String()
+		Expect(body).To(ContainSubstring("All systems operational"))
+		Expect(body).To(ContainSubstring("42.5"))
+		Expect(body).To(ContainSubstring("Database"))
+		Expect(body).To(ContainSubstring("99.500 % uptime in the last 90 days"))
+		Expect(body).To(ContainSubstring("uptime-day uptime-degraded"))
+	})
+
+	It("should keep responses that set cookies out of shared caches", func() {
+		rec := get()
+		Expect(rec.Header().Values("Set-Cookie")).NotTo(BeEmpty())
+		Expect(rec.Header().Get("Cache-Control")).NotTo(ContainSubstring("public"))
+	})
+
+	It("should set no cookies on the cached responses of returning visitors", func() {
+		req := httptest.NewRequest(http.MethodGet, "/status", nil)
+		req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: newCSRFToken()})
+
+		rec := httptest.NewRecorder()
+		server.setupRoutes().ServeHTTP(rec, req)
+		Expect(rec.Header().Get("Cache-Control")).To(ContainSubstring("max-age"))
+		Expect(rec.Header().Values("Set-Cookie")).To(BeEmpty())
+	})
+
+	It("should report components that are down", func() {
+		client.summary.Components[0].Up = false
+		Expect(get().Body.String()).To(ContainSubstring("Some systems are down"))
+	})
+
+	It("should report an unreachable backend with 503", func() {
+		client.summary, client.err = nil, errors.New("connection refused")
+
+		rec := get()
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(rec.Body.String()).To(ContainSubstring("Some systems are down"))
+		Expect(rec.Body.String()).To(ContainSubstring("Backend API"))
+	})
+
+	It("should report backends without status summaries as unsupported", func() {
+		client.summary, client.err = nil, status.Error(codes.Unimplemented, "unknown method")
+		Expect(get().Code).To(Equal(http.StatusNotImplemented))
+	})
+
+	Describe("newStatusPage", func() {
+		It("should place the history on the last days, oldest first", func() {
+			page := newStatusPage(client.summary, today.Add(12*time.Hour))
+
+			Expect(page.Components).To(HaveLen(2))
+			days := page.Components[1].Days
+			Expect(days).To(HaveLen(statusPageDays))
+			Expect(days[statusPageDays-1].Day).To(Equal(today))
+			Expect(days[statusPageDays-1].Percent).To(Equal(100.0))
+			Expect(days[statusPageDays-2].Percent).To(Equal(90.0))
+			Expect(days[0].Checks).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,86 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"time"
+	// Embed the time zone database, which the runtime image does not ship
+	_ "time/tzdata"
+)
+
+// timezoneCookie holds the IANA time zone of the browser. The layout script
+// sets it from Intl.DateTimeFormat, so unlike the preference cookie it is
+// readable by JavaScript.
+const timezoneCookie = "demo_app_tz"
+
+type timezoneKey struct{}
+
+// timezoneMiddleware stores the time zone of the timezone cookie in the request
+// context. Missing or unknown zones leave timestamps in UTC.
+func timezoneMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if loc := timezoneFromCookie(r); loc != nil {
+			r = r.WithContext(context.WithValue(r.Context(), timezoneKey{}, loc))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// timezoneFromCookie returns the location named by the timezone cookie, or nil.
+func timezoneFromCookie(r *http.Request) *time.Location {
+	cookie, err := r.Cookie(timezoneCookie)
+	// LoadLocation maps "" to UTC and "Local" to the server's zone; neither
+	// is a zone a browser reports
+	if err != nil || cookie.Value == "" || cookie.Value == "Local" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(cookie.Value)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// timezoneFromContext returns the time zone stored in ctx, or UTC.
+func timezoneFromContext(ctx context.Context) *time.Location {
+	if loc, ok := ctx.Value(timezoneKey{}).(*time.Location); ok {
+		return loc
+	}
+	return time.UTC
+}
+
+// timezoneName returns the name of the time zone of ctx for labels.
+func timezoneName(ctx context.Context) string {
+	return timezoneFromContext(ctx).String()
+}
+
+// inTimezone converts a Unix timestamp to the time zone of ctx.
+func inTimezone(ctx context.Context, unix int64) time.Time {
+	return time.Unix(unix, 0).In(timezoneFromContext(ctx))
+}
+
+// relativeTime describes how long before now a Unix timestamp was, such as
+// "5 minutes ago". Timestamps in the future or more than a week back are
+// formatted as date and time instead.
+func relativeTime(ctx context.Context, unix int64, now time.Time) string {
+	age := now.Sub(time.Unix(unix, 0))
+	switch {
+	case age < -time.Minute || age >= 7*24*time.Hour:
+		return formatDateTime(ctx, unix)
+	case age < time.Minute:
+		return t(ctx, "just now")
+	case age < 2*time.Minute:
+		return t(ctx, "1 minute ago")
+	case age < time.Hour:
+		return t(ctx, "%d minutes ago", int(age/time.Minute))
+	case age < 2*time.Hour:
+		return t(ctx, "1 hour ago")
+	case age < 24*time.Hour:
+		return t(ctx, "%d hours ago", int(age/time.Hour))
+	case age < 48*time.Hour:
+		return t(ctx, "1 day ago")
+	default:
+		return t(ctx, "%d days ago", int(age/(24*time.Hour)))
+	}
+}
@@ -0,0 +1,130 @@
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/text/language"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Time zones", func() {
+	requestWithZone := func(zone string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: timezoneCookie, Value: zone})
+		return req
+	}
+
+	Describe("timezoneFromCookie", func() {
+		It("should load the zone of the browser", func() {
+			Expect(timezoneFromCookie(requestWithZone("Europe/Berlin")).String()).To(Equal("Europe/Berlin"))
+		})
+
+		It("should ignore missing, unknown and server-relative zones", func() {
+			Expect(timezoneFromCookie(httptest.NewRequest(http.MethodGet, "/", nil))).To(BeNil())
+			Expect(timezoneFromCookie(requestWithZone("Mars/Olympus"))).To(BeNil())
+			Expect(timezoneFromCookie(requestWithZone("Local"))).To(BeNil())
+			Expect(timezoneFromCookie(requestWithZone(""))).To(BeNil())
+		})
+	})
+
+	Describe("formatting", func() {
+		// 2026-01-15 12:00:00 UTC
+		const noon = 1768478400
+
+		berlin := func() context.Context {
+			return context.WithValue(context.Background(), timezoneKey{}, mustLoadLocation("Europe/Berlin"))
+		}
+
+		It("should default to UTC", func() {
+			Expect(formatDateTime(context.Background(), noon)).To(Equal("2026-01-15 12:00:00"))
+		})
+
+		It("should convert timestamps to the time zone of the request", func() {
+			ctx := berlin()
+
+			Expect(formatDateTime(ctx, noon)).To(Equal("2026-01-15 13:00:00"))
+			Expect(formatSilenceTime(ctx, noon)).To(Equal("2026-01-15T13:00"))
+		})
+
+		It("should parse silence times in the time zone of the request", func() {
+			errs := map[string]string{}
+			Expect(parseSilenceTime("2026-01-15T13:00", timezoneFromContext(berlin()), "silence_start", errs)).To(BeEquivalentTo(noon))
+			Expect(errs).To(BeEmpty())
+		})
+	})
+
+	Describe("relativeTime", func() {
+		now := time.Unix(1768478400, 0)
+		ago := func(d time.Duration) int64 { return now.Add(-d).Unix() }
+
+		It("should describe recent timestamps relative to now", func() {
+			ctx := context.Background()
+
+			Expect(relativeTime(ctx, ago(10*time.Second), now)).To(Equal("just now"))
+			Expect(relativeTime(ctx, ago(90*time.Second), now)).To(Equal("1 minute ago"))
+			Expect(relativeTime(ctx, ago(5*time.Minute), now)).To(Equal("5 minutes ago"))
+			Expect(relativeTime(ctx, ago(3*time.Hour), now)).To(Equal("3 hours ago"))
+			Expect(relativeTime(ctx, ago(30*time.Hour), now)).To(Equal("1 day ago"))
+			Expect(relativeTime(ctx, ago(3*24*time.Hour), now)).To(Equal("3 days ago"))
+		})
+
+		It("should format old and future timestamps absolutely", func() {
+			ctx := context.Background()
+
+			Expect(relativeTime(ctx, ago(30*24*time.Hour), now)).To(Equal("2025-12-16 12:00:00"))
+			Expect(relativeTime(ctx, ago(-time.Hour), now)).To(Equal("2026-01-15 13:00:00"))
+		})
+
+		It("should translate relative times", func() {
+			ctx := context.WithValue(context.Background(), localeKey{}, locales[language.German])
+
+			Expect(relativeTime(ctx, ago(5*time.Minute), now)).To(Equal("vor 5 Minuten"))
+		})
+	})
+
+	It("should render last seen times relative to now with the exact time as tooltip", func() {
+		lastSeen := time.Now().Add(-5 * time.Minute)
+		ctx := context.WithValue(context.Background(), timezoneKey{}, mustLoadLocation("Asia/Tokyo"))
+
+		var buf bytes.Buffer
+		Expect(devicesList([]*iotv1.IoTDevice{{DeviceId: "sensor-1", Timestamp: lastSeen.Unix()}}).Render(ctx, &buf)).To(Succeed())
+
+		Expect(buf.String()).To(ContainSubstring("5 minutes ago"))
+		Expect(buf.String()).To(ContainSubstring(lastSeen.In(mustLoadLocation("Asia/Tokyo")).Format("2006-01-02 15:04:05") + " Asia/Tokyo"))
+	})
+
+	It("should label silence windows with the time zone of the cookie", func() {
+		client := &alertRulesClient{rules: map[uint64]*iotv1.AlertRule{
+			1: {Id: 1, Name: "Hot room", Metric: "temperature", Operator: "gt", SilenceStart: 1768478400, SilenceEnd: 1768482000},
+		}}
+		server := &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: client,
+		}
+
+		req := requestWithZone("Asia/Tokyo")
+		req.URL.Path = "/admin/alerts"
+		rec := httptest.NewRecorder()
+		server.setupRoutes().ServeHTTP(rec, req)
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring("2026-01-15T21:00 – 2026-01-15T22:00 Asia/Tokyo"))
+	})
+})
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	Expect(err).NotTo(HaveOccurred())
+	return loc
+}
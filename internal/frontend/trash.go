@@ -0,0 +1,57 @@
+package frontend
+
+import (
+	"net/http"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// handleDeleteDevice moves a device to the trash and returns to the device list.
+func (s *Server) handleDeleteDevice(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.PathValue("id")
+
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	if _, err := trackedCall(s, ctx, "DeleteDevice", s.grpcClient.DeleteDevice, &iotv1.DeleteDeviceRequest{DeviceId: deviceID}); err != nil {
+		s.logger.Error("failed to delete device", "error", err, "device_id", deviceID, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to delete device"))
+		return
+	}
+
+	http.Redirect(w, r, "/devices", http.StatusSeeOther)
+}
+
+// handleTrash serves the list of deleted devices.
+func (s *Server) handleTrash(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	resp, err := trackedCall(s, ctx, "ListDeletedDevices", s.grpcClient.ListDeletedDevices, &iotv1.ListDeletedDevicesRequest{})
+	if err != nil {
+		s.logger.Error("failed to fetch deleted devices", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to fetch deleted devices"))
+		return
+	}
+
+	if err := renderTrash(r.Context(), w, resp.GetDevices(), s.metrics); err != nil {
+		s.logger.Error("failed to render trash", "error", err, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, http.StatusInternalServerError, genericErrorMessage)
+	}
+}
+
+// handleRestoreDevice takes a device out of the trash and returns to the trash view.
+func (s *Server) handleRestoreDevice(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.PathValue("id")
+
+	ctx, cancel := s.backendContext(r.Context())
+	defer cancel()
+
+	if _, err := trackedCall(s, ctx, "RestoreDevice", s.grpcClient.RestoreDevice, &iotv1.RestoreDeviceRequest{DeviceId: deviceID}); err != nil {
+		s.logger.Error("failed to restore device", "error", err, "device_id", deviceID, "request_id", requestIDFromContext(r.Context()))
+		s.renderError(w, r, errorStatus(err), errorMessage(err, "Failed to restore device"))
+		return
+	}
+
+	http.Redirect(w, r, "/trash", http.StatusSeeOther)
+}
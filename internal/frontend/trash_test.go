@@ -0,0 +1,157 @@
+package frontend
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// trashClient is an in-memory IoTServiceClient stub for soft delete and restore.
+type trashClient struct {
+	iotv1.IoTServiceClient
+	devices map[string]*iotv1.IoTDevice
+}
+
+func (c *trashClient) DeleteDevice(_ context.Context, req *iotv1.DeleteDeviceRequest, _ ...grpc.CallOption) (*iotv1.DeleteDeviceResponse, error) {
+	dev, ok := c.devices[req.GetDeviceId()]
+	if !ok || dev.GetDeletedAt() != 0 {
+		return nil, iotv1.DeviceNotFoundError(req.GetDeviceId())
+	}
+	dev.DeletedAt = time.Date(2026, 5, 1, 9, 30, 0, 0, time.Local).Unix()
+	return &iotv1.DeleteDeviceResponse{}, nil
+}
+
+func (c *trashClient) RestoreDevice(_ context.Context, req *iotv1.RestoreDeviceRequest, _ ...grpc.CallOption) (*iotv1.RestoreDeviceResponse, error) {
+	dev, ok := c.devices[req.GetDeviceId()]
+	if !ok || dev.GetDeletedAt() == 0 {
+		return nil, iotv1.DeviceNotFoundError(req.GetDeviceId())
+	}
+	dev.DeletedAt = 0
+	return &iotv1.RestoreDeviceResponse{Device: dev}, nil
+}
+
+func (c *trashClient) ListDeletedDevices(_ context.Context, _ *iotv1.ListDeletedDevicesRequest, _ ...grpc.CallOption) (*iotv1.ListDeletedDevicesResponse, error) {
+	resp := &iotv1.ListDeletedDevicesResponse{}
+	for _, dev := range c.devices {
+		if dev.GetDeletedAt() != 0 {
+			resp.Devices = append(resp.Devices, dev)
+		}
+	}
+	return resp, nil
+}
+
+func (c *trashClient) GetAllDevice(_ context.Context, _ *iotv1.GetAllDevicesRequest, _ ...grpc.CallOption) (*iotv1.GetAllDevicesResponse, error) {
+	resp := &iotv1.GetAllDevicesResponse{}
+	for _, dev := range c.devices {
+		if dev.GetDeletedAt() == 0 {
+			resp.Devices = append(resp.Devices, dev)
+		}
+	}
+	return resp, nil
+}
+
+var _ = Describe("Device trash", func() {
+	var (
+		client  *trashClient
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		client = &trashClient{devices: map[string]*iotv1.IoTDevice{
+			"sensor-1": {DeviceId: "sensor-1", Location: "Warehouse"},
+		}}
+		server := &Server{
+			logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			})),
+			grpcClient: client,
+		}
+		handler = server.setupRoutes()
+	})
+
+	do := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, withCSRFToken(httptest.NewRequest(method, target, nil)))
+		return rec
+	}
+
+	It("should move a device to the trash and restore it", func() {
+		rec := do(http.MethodPost, "/device/sensor-1/delete")
+		Expect(rec.Code).To(Equal(http.StatusSeeOther))
+		Expect(rec.Header().Get("Location")).To(Equal("/devices"))
+
+		list := do(http.MethodGet, "/trash")
+		Expect(list.Code).To(Equal(http.StatusOK))
+		Expect(list.Body.String()).To(ContainSubstring("Warehouse"))
+		Expect(list.Body.String()).To(ContainSubstring("2026-05-01 09:30:00"))
+		Expect(list.Body.String()).To(ContainSubstring(`action="/trash/sensor-1/restore"`))
+
+		rec = do(http.MethodPost, "/trash/sensor-1/restore")
+		Expect(rec.Code).To(Equal(http.StatusSeeOther))
+		Expect(rec.Header().Get("Location")).To(Equal("/trash"))
+
+		Expect(do(http.MethodGet, "/trash").Body.String()).To(ContainSubstring("The trash is empty."))
+	})
+
+	It("should show changes made through one replica on every other", func() {
+		// Replicas share only the backend, so nothing needs invalidating
+		other := (&Server{
+			logger:     slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+			grpcClient: client,
+		}).setupRoutes()
+		list := func() string {
+			rec := httptest.NewRecorder()
+			other.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/devices", nil))
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			return rec.Body.String()
+		}
+
+		Expect(list()).To(ContainSubstring("sensor-1"))
+		Expect(do(http.MethodPost, "/device/sensor-1/delete").Code).To(Equal(http.StatusSeeOther))
+		Expect(list()).NotTo(ContainSubstring("sensor-1"))
+		Expect(do(http.MethodPost, "/trash/sensor-1/restore").Code).To(Equal(http.StatusSeeOther))
+		Expect(list()).To(ContainSubstring("sensor-1"))
+	})
+
+	It("should accept its plain form posts without JavaScript", func() {
+		client.devices["sensor-1"].DeletedAt = time.Now().Unix()
+
+		// A new visitor gets the token as a cookie and in the hidden form field
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/trash", nil))
+		var cookie *http.Cookie
+		for _, c := range rec.Result().Cookies() {
+			if c.Name == csrfCookieName {
+				cookie = c
+			}
+		}
+		Expect(cookie).NotTo(BeNil())
+		Expect(rec.Body.String()).To(ContainSubstring(`name="csrf_token" value="` + cookie.Value + `"`))
+
+		// The browser submits the form as is, without the header
+		form := url.Values{csrfFormField: {cookie.Value}}
+		req := httptest.NewRequest(http.MethodPost, "/trash/sensor-1/restore", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusSeeOther))
+		Expect(client.devices["sensor-1"].GetDeletedAt()).To(BeZero())
+	})
+
+	It("should return not found for unknown devices", func() {
+		Expect(do(http.MethodPost, "/device/missing/delete").Code).To(Equal(http.StatusNotFound))
+		Expect(do(http.MethodPost, "/trash/sensor-1/restore").Code).To(Equal(http.StatusNotFound))
+	})
+})
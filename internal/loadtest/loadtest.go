@@ -0,0 +1,336 @@
+// Package loadtest publishes sensor readings at a target rate to a running
+// stack and reports the publish latency and error rate it observed, along with
+// the ingest latency, write throughput and error rate of the backend.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"procodus.dev/demo-app/pkg/generator"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/pkg/mq"
+)
+
+const (
+	defaultMinDevices  = 10
+	defaultWorkers     = 8
+	defaultPushTimeout = 10 * time.Second
+	defaultSettle      = 5 * time.Second
+	progressInterval   = 10 * time.Second
+)
+
+// Config holds the configuration of a load test.
+type Config struct {
+	// Logger is the structured logger
+	Logger *slog.Logger
+	// SensorPublisher publishes sensor readings to the backend's sensor queue
+	SensorPublisher mq.Publisher
+	// DevicePublisher publishes the devices the readings belong to
+	DevicePublisher mq.Publisher
+	// Rate is the target number of sensor readings published per second
+	Rate float64
+	// Duration is how long readings are published
+	Duration time.Duration
+	// Devices is the number of simulated devices (optional, default one per
+	// reading per second of Rate, at least 10). Each device sends at most one
+	// reading per second of its timestamps, as the backend stores one reading
+	// per device and second.
+	Devices int
+	// Workers is the number of concurrent publishes (optional, default 8)
+	Workers int
+	// PushTimeout bounds a single publish (optional, default 10 seconds)
+	PushTimeout time.Duration
+	// MetricsURL is the backend's Prometheus endpoint, scraped before and
+	// after the test (optional, empty = no backend statistics)
+	MetricsURL string
+	// SensorQueue is the queue label of the backend's sensor consumer metrics;
+	// required with MetricsURL
+	SensorQueue string
+	// Settle is how long to wait after publishing the devices and after the
+	// last reading for the backend to catch up (optional, default 5 seconds)
+	Settle time.Duration
+}
+
+// LoadTest drives a running stack at a target rate.
+type LoadTest struct {
+	config *Config
+	logger *slog.Logger
+}
+
+// New validates the configuration and creates a load test.
+func New(cfg *Config) (*LoadTest, error) {
+	if cfg == nil {
+		return nil, errors.New("config cannot be nil")
+	}
+	if cfg.Logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	if cfg.SensorPublisher == nil || cfg.DevicePublisher == nil {
+		return nil, errors.New("sensor and device publishers cannot be nil")
+	}
+	if cfg.Rate <= 0 {
+		return nil, errors.New("rate must be positive")
+	}
+	if cfg.Duration <= 0 {
+		return nil, errors.New("duration must be positive")
+	}
+	if cfg.Devices < 0 || cfg.Workers < 0 || cfg.PushTimeout < 0 || cfg.Settle < 0 {
+		return nil, errors.New("devices, workers, push timeout and settle cannot be negative")
+	}
+	if cfg.MetricsURL != "" && cfg.SensorQueue == "" {
+		return nil, errors.New("sensor queue is required to read backend metrics")
+	}
+
+	if cfg.Devices == 0 {
+		cfg.Devices = max(defaultMinDevices, int(math.Ceil(cfg.Rate)))
+	}
+	if cfg.Workers == 0 {
+		cfg.Workers = defaultWorkers
+	}
+	if cfg.PushTimeout == 0 {
+		cfg.PushTimeout = defaultPushTimeout
+	}
+	if cfg.Settle == 0 {
+		cfg.Settle = defaultSettle
+	}
+
+	return &LoadTest{config: cfg, logger: cfg.Logger}, nil
+}
+
+// device is a simulated device and the timestamp of its next reading.
+type device struct {
+	generator *generator.IoTDataGenerator
+	next      time.Time
+}
+
+// Run publishes the devices, then sensor readings at the target rate for the
+// configured duration, and returns the report. Canceling ctx ends the test
+// early; the report covers what was published until then.
+func (l *LoadTest) Run(ctx context.Context) (*Report, error) {
+	var before *snapshot
+	if l.config.MetricsURL != "" {
+		var err error
+		if before, err = scrape(ctx, l.config.MetricsURL); err != nil {
+			return nil, fmt.Errorf("failed to read backend metrics: %w", err)
+		}
+	}
+
+	devices, err := l.publishDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l.logger.Info("published devices, waiting for the backend", "devices", len(devices), "settle", l.config.Settle)
+	if !sleep(ctx, l.config.Settle) {
+		return nil, ctx.Err()
+	}
+
+	l.logger.Info("starting load test", "rate", l.config.Rate, "duration", l.config.Duration, "workers", l.config.Workers)
+
+	stats := &publishStats{}
+	start := time.Now()
+	l.publishReadings(ctx, devices, stats)
+	elapsed := time.Since(start)
+
+	report := stats.report(l.config.Rate, elapsed)
+
+	if before != nil {
+		l.logger.Info("waiting for the backend to catch up", "settle", l.config.Settle)
+		sleep(ctx, l.config.Settle)
+
+		// The test is over; read the metrics even if it was canceled
+		after, err := scrape(context.WithoutCancel(ctx), l.config.MetricsURL)
+		if err != nil {
+			return report, fmt.Errorf("failed to read backend metrics: %w", err)
+		}
+		report.Backend = backendStats(before, after, l.config.SensorQueue, elapsed)
+	}
+
+	return report, nil
+}
+
+// publishDevices publishes the simulated devices.
+func (l *LoadTest) publishDevices(ctx context.Context) ([]*device, error) {
+	now := time.Now()
+	devices := make([]*device, 0, l.config.Devices)
+
+	for range l.config.Devices {
+		d := generator.NewIoTDevice()
+		if d == nil {
+			return nil, errors.New("failed to generate device")
+		}
+
+		msg, err := proto.Marshal(&iotv1.IoTDevice{
+			DeviceId:   d.DeviceID,
+			Timestamp:  d.Timestamp.Unix(),
+			Location:   d.Location,
+			MacAddress: d.MacAddress,
+			IpAddress:  d.IPAddress,
+			Firmware:   d.Firmware,
+			Latitude:   float32(d.Latitude),
+			Longitude:  float32(d.Longitude),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		pushCtx, cancel := context.WithTimeout(ctx, l.config.PushTimeout)
+		err = l.config.DevicePublisher.Push(pushCtx, msg)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to publish device: %w", err)
+		}
+
+		devices = append(devices, &device{generator: generator.NewIoTGenerator(d.DeviceID), next: now})
+	}
+
+	return devices, nil
+}
+
+// publishReadings publishes readings of the devices, round robin, at the
+// target rate until the duration has passed or ctx is done. Readings the
+// workers cannot keep up with are skipped rather than queued, so the achieved
+// rate shows when the stack is saturated.
+func (l *LoadTest) publishReadings(ctx context.Context, devices []*device, stats *publishStats) {
+	ctx, cancel := context.WithTimeout(ctx, l.config.Duration)
+	defer cancel()
+
+	messages := make(chan []byte, l.config.Workers)
+
+	var wg sync.WaitGroup
+	for range l.config.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for msg := range messages {
+				// Let publishes in flight at the end of the test finish
+				pushCtx, cancelPush := context.WithTimeout(context.WithoutCancel(ctx), l.config.PushTimeout)
+				start := time.Now()
+				err := l.config.SensorPublisher.Push(pushCtx, msg)
+				cancelPush()
+				stats.record(time.Since(start), err)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / l.config.Rate))
+	defer ticker.Stop()
+
+	progress := time.NewTicker(progressInterval)
+	defer progress.Stop()
+
+	for i := 0; ; i++ {
+		select {
+		case <-ctx.Done():
+			close(messages)
+			wg.Wait()
+			return
+		case <-progress.C:
+			published, failed, skipped := stats.counts()
+			l.logger.Info("load test progress", "published", published, "failed", failed, "skipped", skipped)
+			continue
+		case <-ticker.C:
+		}
+
+		// Timestamps are unique per device, as the backend requires
+		d := devices[i%len(devices)]
+		reading := d.generator.GenerateCorrelatedReading(d.next)
+		d.next = d.next.Add(time.Second)
+
+		msg, err := proto.Marshal(reading)
+		if err != nil {
+			stats.record(0, err)
+			continue
+		}
+
+		select {
+		case messages <- msg:
+		default:
+			stats.skip()
+		}
+	}
+}
+
+// publishStats collects the outcome of the publishes.
+type publishStats struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	failed    int
+	skipped   int
+}
+
+func (s *publishStats) record(latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.failed++
+		return
+	}
+	s.latencies = append(s.latencies, latency)
+}
+
+func (s *publishStats) skip() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.skipped++
+}
+
+func (s *publishStats) counts() (published, failed, skipped int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.latencies), s.failed, s.skipped
+}
+
+// report summarizes the publishes of a test that ran for elapsed.
+func (s *publishStats) report(rate float64, elapsed time.Duration) *Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	latencies := append([]time.Duration(nil), s.latencies...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	return &Report{
+		Duration:   elapsed,
+		TargetRate: rate,
+		Published:  len(latencies),
+		Failed:     s.failed,
+		Skipped:    s.skipped,
+		PublishP50: percentile(latencies, 0.50),
+		PublishP95: percentile(latencies, 0.95),
+	}
+}
+
+// percentile returns the q-th percentile of sorted latencies.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
+
+// sleep waits for d and reports whether ctx was still active afterwards.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
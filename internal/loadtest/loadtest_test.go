@@ -0,0 +1,192 @@
+package loadtest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
+
+	"procodus.dev/demo-app/internal/loadtest"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/pkg/mq/mock"
+)
+
+// backendMetrics renders the backend's sensor consumer metrics with the given
+// counts of stored readings and of readings per latency bucket.
+func backendMetrics(stored, failed int, fast, slow int) string {
+	return fmt.Sprintf(`# TYPE demo_app_backend_consumer_messages_total counter
+demo_app_backend_consumer_messages_total{queue="sensor-data",status="success"} %[1]d
+demo_app_backend_consumer_messages_total{queue="sensor-data",status="error"} %[2]d
+demo_app_backend_consumer_messages_total{queue="device-data",status="success"} 1000
+# TYPE demo_app_backend_consumer_processing_duration_seconds histogram
+demo_app_backend_consumer_processing_duration_seconds_bucket{queue="sensor-data",le="0.01"} %[3]d
+demo_app_backend_consumer_processing_duration_seconds_bucket{queue="sensor-data",le="0.1"} %[4]d
+demo_app_backend_consumer_processing_duration_seconds_bucket{queue="sensor-data",le="+Inf"} %[4]d
+demo_app_backend_consumer_processing_duration_seconds_sum{queue="sensor-data"} 1
+demo_app_backend_consumer_processing_duration_seconds_count{queue="sensor-data"} %[4]d
+`, stored, failed, fast, fast+slow)
+}
+
+var _ = Describe("LoadTest", func() {
+	var (
+		logger  *slog.Logger
+		sensors *mock.MockClient
+		devices *mock.MockClient
+		cfg     *loadtest.Config
+	)
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+		sensors = mock.NewMockClient()
+		devices = mock.NewMockClient()
+		cfg = &loadtest.Config{
+			Logger:          logger,
+			SensorPublisher: sensors,
+			DevicePublisher: devices,
+			Rate:            200,
+			Duration:        300 * time.Millisecond,
+			Devices:         3,
+			Settle:          10 * time.Millisecond,
+		}
+	})
+
+	Describe("New", func() {
+		It("should require a positive rate and duration", func() {
+			cfg.Rate = 0
+			_, err := loadtest.New(cfg)
+			Expect(err).To(MatchError("rate must be positive"))
+
+			cfg.Rate = 10
+			cfg.Duration = 0
+			_, err = loadtest.New(cfg)
+			Expect(err).To(MatchError("duration must be positive"))
+		})
+
+		It("should require the sensor queue to read backend metrics", func() {
+			cfg.MetricsURL = "http://localhost:9090/metrics"
+			_, err := loadtest.New(cfg)
+			Expect(err).To(MatchError("sensor queue is required to read backend metrics"))
+		})
+
+		It("should default the devices to the rate", func() {
+			cfg.Devices = 0
+			cfg.Rate = 50
+			_, err := loadtest.New(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Devices).To(Equal(50))
+		})
+	})
+
+	Describe("Run", func() {
+		It("should publish devices and readings at the target rate", func() {
+			lt, err := loadtest.New(cfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			report, err := lt.Run(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(devices.PushCalls).To(HaveLen(3))
+			Expect(report.Published).To(Equal(len(sensors.PushCalls)))
+			Expect(report.Published).To(BeNumerically("~", 60, 30))
+			Expect(report.Failed).To(BeZero())
+			Expect(report.Backend).To(BeNil())
+
+			// The backend stores one reading per device and second
+			seen := make(map[string]bool)
+			for _, call := range sensors.PushCalls {
+				var reading iotv1.SensorReading
+				Expect(proto.Unmarshal(call.Data, &reading)).To(Succeed())
+
+				key := fmt.Sprintf("%s/%d", reading.GetDeviceId(), reading.GetTimestamp())
+				Expect(seen).NotTo(HaveKey(key))
+				seen[key] = true
+			}
+		})
+
+		It("should count failed publishes", func() {
+			sensors.PushError = errors.New("nack")
+
+			lt, err := loadtest.New(cfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			report, err := lt.Run(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Published).To(BeZero())
+			Expect(report.Failed).To(BeNumerically(">", 0))
+			Expect(report.PublishErrorRate()).To(Equal(1.0))
+		})
+
+		It("should fail if a device cannot be published", func() {
+			devices.PushError = errors.New("broker down")
+
+			lt, err := loadtest.New(cfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = lt.Run(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("broker down")))
+		})
+
+		It("should report the backend statistics from its metrics", func() {
+			var scrapes atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if scrapes.Add(1) == 1 {
+					_, _ = io.WriteString(w, backendMetrics(100, 5, 100, 0))
+					return
+				}
+				// 60 readings stored, 10 of them slower than 10ms
+				_, _ = io.WriteString(w, backendMetrics(160, 7, 150, 10))
+			}))
+			DeferCleanup(server.Close)
+
+			cfg.MetricsURL = server.URL
+			cfg.SensorQueue = "sensor-data"
+
+			lt, err := loadtest.New(cfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			report, err := lt.Run(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scrapes.Load()).To(Equal(int32(2)))
+
+			backend := report.Backend
+			Expect(backend).NotTo(BeNil())
+			Expect(backend.Stored).To(Equal(60))
+			Expect(backend.Errors).To(Equal(2))
+			Expect(backend.WriteThroughput).To(BeNumerically("~", 200, 60))
+
+			// 50 of 60 observations are below 10ms
+			Expect(backend.IngestP50).To(BeNumerically("~", 6*time.Millisecond, time.Millisecond))
+			Expect(backend.IngestP95).To(BeNumerically("~", 73*time.Millisecond, time.Millisecond))
+
+			var out bytes.Buffer
+			Expect(report.Print(&out)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("DB write throughput"))
+			Expect(out.String()).To(ContainSubstring("Ingest errors"))
+		})
+
+		It("should fail if the backend metrics cannot be read", func() {
+			server := httptest.NewServer(http.NotFoundHandler())
+			DeferCleanup(server.Close)
+
+			cfg.MetricsURL = server.URL
+			cfg.SensorQueue = "sensor-data"
+
+			lt, err := loadtest.New(cfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = lt.Run(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("404")))
+			Expect(devices.PushCalls).To(BeEmpty())
+		})
+	})
+})
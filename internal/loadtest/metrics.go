@@ -0,0 +1,186 @@
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+// scrapeTimeout bounds a single scrape of the backend metrics.
+const scrapeTimeout = 10 * time.Second
+
+// Backend metrics read by the load test.
+var (
+	consumerMessagesMetric   = metrics.BackendNamespace + "_consumer_messages_total"
+	processingDurationMetric = metrics.BackendNamespace + "_consumer_processing_duration_seconds"
+)
+
+// snapshot holds the metric families of one scrape.
+type snapshot struct {
+	families map[string]*dto.MetricFamily
+}
+
+// scrape reads the metrics exposed at url in the Prometheus text format.
+func scrape(ctx context.Context, url string) (*snapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, scrapeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The text format is what the parser understands
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+
+	return &snapshot{families: families}, nil
+}
+
+// matching returns the series of a metric whose labels include labels.
+func (s *snapshot) matching(name string, labels map[string]string) []*dto.Metric {
+	family, ok := s.families[name]
+	if !ok {
+		return nil
+	}
+
+	var matched []*dto.Metric
+	for _, m := range family.GetMetric() {
+		values := make(map[string]string, len(m.GetLabel()))
+		for _, l := range m.GetLabel() {
+			values[l.GetName()] = l.GetValue()
+		}
+
+		ok := true
+		for k, v := range labels {
+			if values[k] != v {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, m)
+		}
+	}
+
+	return matched
+}
+
+// counter sums the counter series of a metric whose labels include labels.
+func (s *snapshot) counter(name string, labels map[string]string) float64 {
+	var sum float64
+	for _, m := range s.matching(name, labels) {
+		sum += m.GetCounter().GetValue()
+	}
+	return sum
+}
+
+// buckets sums the cumulative bucket counts of the histogram series of a
+// metric whose labels include labels, by upper bound.
+func (s *snapshot) buckets(name string, labels map[string]string) map[float64]float64 {
+	counts := make(map[float64]float64)
+	for _, m := range s.matching(name, labels) {
+		for _, b := range m.GetHistogram().GetBucket() {
+			if !math.IsInf(b.GetUpperBound(), 1) {
+				counts[b.GetUpperBound()] += float64(b.GetCumulativeCount())
+			}
+		}
+		// The +Inf bucket counts all observations
+		counts[math.Inf(1)] += float64(m.GetHistogram().GetSampleCount())
+	}
+	return counts
+}
+
+// backendStats computes the backend statistics of a test that ran for
+// elapsed from the metrics scraped before and after it.
+func backendStats(before, after *snapshot, queue string, elapsed time.Duration) *BackendStats {
+	success := map[string]string{"queue": queue, "status": "success"}
+	failure := map[string]string{"queue": queue, "status": "error"}
+	queueOnly := map[string]string{"queue": queue}
+
+	stored := after.counter(consumerMessagesMetric, success) - before.counter(consumerMessagesMetric, success)
+	errs := after.counter(consumerMessagesMetric, failure) - before.counter(consumerMessagesMetric, failure)
+
+	// Histogram buckets of the observations made during the test
+	end := after.buckets(processingDurationMetric, queueOnly)
+	start := before.buckets(processingDurationMetric, queueOnly)
+	delta := make(map[float64]float64, len(end))
+	for bound, count := range end {
+		delta[bound] = count - start[bound]
+	}
+
+	stats := &BackendStats{
+		Stored:    int(stored),
+		Errors:    int(errs),
+		IngestP50: histogramQuantile(0.50, delta),
+		IngestP95: histogramQuantile(0.95, delta),
+	}
+	if elapsed > 0 {
+		stats.WriteThroughput = stored / elapsed.Seconds()
+	}
+
+	return stats
+}
+
+// histogramQuantile estimates the q-quantile of cumulative bucket counts by
+// upper bound, interpolating linearly within a bucket like PromQL's
+// histogram_quantile. It returns 0 without observations.
+func histogramQuantile(q float64, buckets map[float64]float64) time.Duration {
+	bounds := make([]float64, 0, len(buckets))
+	for bound := range buckets {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+
+	if len(bounds) == 0 || buckets[bounds[len(bounds)-1]] <= 0 {
+		return 0
+	}
+
+	total := buckets[bounds[len(bounds)-1]]
+	rank := q * total
+
+	lowerBound, lowerCount := 0.0, 0.0
+	for _, bound := range bounds {
+		count := buckets[bound]
+		if count >= rank {
+			if math.IsInf(bound, 1) {
+				// Beyond the largest finite bucket; report that bucket's bound
+				return seconds(lowerBound)
+			}
+			if count == lowerCount {
+				return seconds(bound)
+			}
+			return seconds(lowerBound + (bound-lowerBound)*(rank-lowerCount)/(count-lowerCount))
+		}
+		lowerBound, lowerCount = bound, count
+	}
+
+	return seconds(lowerBound)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
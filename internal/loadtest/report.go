@@ -0,0 +1,101 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Report is the outcome of a load test.
+type Report struct {
+	// Duration is how long readings were published
+	Duration time.Duration
+	// TargetRate is the configured readings per second
+	TargetRate float64
+	// Published and Failed count the confirmed and failed publishes
+	Published int
+	Failed    int
+	// Skipped counts readings not published because all workers were busy
+	Skipped int
+	// PublishP50 and PublishP95 are percentiles of the publish latency,
+	// including the broker's confirmation
+	PublishP50 time.Duration
+	PublishP95 time.Duration
+	// Backend holds the backend statistics, nil without a metrics URL
+	Backend *BackendStats
+}
+
+// BackendStats are the backend's sensor consumer statistics during a test.
+type BackendStats struct {
+	// Stored and Errors count the readings the consumer stored and failed on
+	Stored int
+	Errors int
+	// WriteThroughput is the number of readings stored per second
+	WriteThroughput float64
+	// IngestP50 and IngestP95 are percentiles of the time the consumer took
+	// to process a reading, estimated from histogram buckets
+	IngestP50 time.Duration
+	IngestP95 time.Duration
+}
+
+// PublishRate returns the achieved publishes per second.
+func (r *Report) PublishRate() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Published) / r.Duration.Seconds()
+}
+
+// PublishErrorRate returns the share of publishes that failed.
+func (r *Report) PublishErrorRate() float64 {
+	return ratio(r.Failed, r.Published+r.Failed)
+}
+
+// ErrorRate returns the share of consumed readings the backend failed on.
+func (s *BackendStats) ErrorRate() float64 {
+	return ratio(s.Errors, s.Stored+s.Errors)
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+// Print writes the report as a table.
+func (r *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	rows := [][2]string{
+		{"Duration", r.Duration.Round(time.Millisecond).String()},
+		{"Target rate", fmt.Sprintf("%.1f/s", r.TargetRate)},
+		{"Publish rate", fmt.Sprintf("%.1f/s", r.PublishRate())},
+		{"Published", fmt.Sprintf("%d", r.Published)},
+		{"Publish errors", fmt.Sprintf("%d (%.2f%%)", r.Failed, 100*r.PublishErrorRate())},
+		{"Skipped (workers busy)", fmt.Sprintf("%d", r.Skipped)},
+		{"Publish latency P50", r.PublishP50.String()},
+		{"Publish latency P95", r.PublishP95.String()},
+	}
+
+	if s := r.Backend; s != nil {
+		rows = append(rows,
+			[2]string{"Stored readings", fmt.Sprintf("%d", s.Stored)},
+			[2]string{"DB write throughput", fmt.Sprintf("%.1f/s", s.WriteThroughput)},
+			[2]string{"Ingest errors", fmt.Sprintf("%d (%.2f%%)", s.Errors, 100*s.ErrorRate())},
+			[2]string{"Ingest latency P50", s.IngestP50.String()},
+			[2]string{"Ingest latency P95", s.IngestP95.String()},
+		)
+	} else {
+		rows = append(rows, [2]string{"Backend", "not measured (no metrics URL)"})
+	}
+
+	for _, row := range rows {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\n", row[0], row[1]); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
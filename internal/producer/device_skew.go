@@ -0,0 +1,47 @@
+package producer
+
+import (
+	"errors"
+	mathrand "math/rand"
+	"time"
+)
+
+var errInvalidDeviceSkew = errors.New("device skew must be 0 or greater than 1")
+
+// validateDeviceSkew checks a Zipf exponent for device selection.
+func validateDeviceSkew(s float64) error {
+	if s != 0 && s <= 1 {
+		return errInvalidDeviceSkew
+	}
+
+	return nil
+}
+
+// SetDeviceSkew makes the producer pick the devices of its readings from a
+// Zipf distribution with exponent s instead of uniformly: the first device is
+// picked most often, the second about 1/2^s as often and so on, like the few
+// hot devices dominating real traffic. s must be greater than 1; 0 restores
+// uniform selection. Call it after the devices are set.
+// Note: Uses math/rand which is acceptable for simulation data.
+func (p *Producer) SetDeviceSkew(s float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.zipf = nil
+	if s == 0 || len(p.IoTDevices) < 2 {
+		return
+	}
+
+	source := mathrand.New(mathrand.NewSource(time.Now().UnixNano())) // #nosec G404 - weak random is acceptable for simulation
+	p.zipf = mathrand.NewZipf(source, s, 1, uint64(len(p.IoTDevices)-1))
+}
+
+// pickDevice returns the index of the device of the next reading. The caller
+// must hold p.mu.
+func (p *Producer) pickDevice() int {
+	if p.zipf != nil {
+		return int(p.zipf.Uint64())
+	}
+
+	return mathrand.Intn(len(p.IoTDevices)) // #nosec G404 - weak random is acceptable for simulation
+}
@@ -0,0 +1,76 @@
+package producer_test
+
+import (
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"procodus.dev/demo-app/internal/producer"
+	"procodus.dev/demo-app/pkg/generator"
+	"procodus.dev/demo-app/pkg/mq/inmem"
+	"procodus.dev/demo-app/pkg/mq/mock"
+)
+
+var _ = Describe("Device skew", func() {
+	var prod *producer.Producer
+
+	BeforeEach(func() {
+		prod = producer.NewProducer(mock.NewMockClient(), mock.NewMockClient())
+		prod.IoTDevices = []*generator.IoTDevice{generator.NewIoTDevice(), generator.NewIoTDevice(), generator.NewIoTDevice(), generator.NewIoTDevice()}
+	})
+
+	// counts returns the number of readings per device in n generated readings.
+	counts := func(n int) []int {
+		index := map[string]int{}
+		for i, device := range prod.IoTDevices {
+			index[device.DeviceID] = i
+		}
+
+		counts := make([]int, len(prod.IoTDevices))
+		for range n {
+			counts[index[prod.GenerateReading().GetDeviceId()]]++
+		}
+		return counts
+	}
+
+	It("should pick devices uniformly by default", func() {
+		for _, count := range counts(4000) {
+			Expect(count).To(BeNumerically("~", 1000, 200))
+		}
+	})
+
+	It("should send most readings to the first devices", func() {
+		prod.SetDeviceSkew(2)
+
+		c := counts(4000)
+		// With s = 2 the first device gets about 70% of the readings
+		Expect(c[0]).To(BeNumerically(">", 2400))
+		Expect(c[0]).To(BeNumerically(">", c[1]))
+		Expect(c[1]).To(BeNumerically(">", c[3]))
+	})
+
+	It("should pick uniformly again without skew", func() {
+		prod.SetDeviceSkew(2)
+		prod.SetDeviceSkew(0)
+
+		Expect(counts(4000)[0]).To(BeNumerically("~", 1000, 200))
+	})
+
+	DescribeTable("should reject exponents that are not greater than 1",
+		func(skew float64) {
+			_, err := producer.NewServer(&producer.ServerConfig{
+				Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+				MQBroker:      inmem.NewBroker(0),
+				ProducerCount: 1,
+				Interval:      time.Second,
+				DeviceSkew:    skew,
+			})
+			Expect(err).To(MatchError("device skew must be 0 or greater than 1"))
+		},
+		Entry("1", 1.0),
+		Entry("negative", -2.0),
+	)
+})
@@ -0,0 +1,86 @@
+package producer
+
+import (
+	"context"
+	"errors"
+	mathrand "math/rand"
+	"sync"
+	"time"
+
+	"procodus.dev/demo-app/pkg/generator"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var (
+	errNegativeDevicesPerProducer = errors.New("devices per producer cannot be negative")
+	errInvalidTickJitter          = errors.New("tick jitter must be between 0 and 1")
+	errTickJitterWithoutDevices   = errors.New("tick jitter requires device tickers")
+	errDeviceSkewWithDevices      = errors.New("device skew cannot be combined with device tickers")
+)
+
+// validateDeviceTickers checks the device count and ticker settings.
+func validateDeviceTickers(cfg *ServerConfig) error {
+	if cfg.DevicesPerProducer < 0 {
+		return errNegativeDevicesPerProducer
+	}
+
+	if cfg.TickJitter < 0 || cfg.TickJitter > 1 {
+		return errInvalidTickJitter
+	}
+
+	if cfg.TickJitter > 0 && !cfg.DeviceTickers {
+		return errTickJitterWithoutDevices
+	}
+
+	// Every device ticks on its own, so there is no device to pick
+	if cfg.DeviceSkew > 0 && cfg.DeviceTickers {
+		return errDeviceSkewWithDevices
+	}
+
+	return nil
+}
+
+// startDevices starts a goroutine per device of producer, each generating a
+// reading every jittered interval and sending it to the returned channel. The
+// producer goroutine publishes them, so pushes, batching and restarts work as
+// with a producer ticker. The returned function waits for the goroutines,
+// which return once ctx is canceled.
+func (s *Server) startDevices(ctx context.Context, producer *Producer) (<-chan []*iotv1.SensorReading, func()) {
+	readings := make(chan []*iotv1.SensorReading)
+
+	var wg sync.WaitGroup
+	for device := range producer.IoTDevices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runDevice(ctx, producer, device, readings)
+		}()
+	}
+
+	return readings, wg.Wait
+}
+
+// runDevice generates the readings of one device. The first reading comes
+// after a random part of the interval, so devices started together do not
+// report in lockstep.
+// Note: Uses math/rand which is acceptable for simulation data.
+func (s *Server) runDevice(ctx context.Context, producer *Producer, device int, readings chan<- []*iotv1.SensorReading) {
+	timer := time.NewTimer(time.Duration(mathrand.Int63n(int64(s.config.Interval)))) // #nosec G404 - weak random is acceptable for simulation
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case readings <- producer.generateDeviceReadings(device):
+		}
+
+		timer.Reset(generator.JitteredInterval(s.config.Interval, s.config.TickJitter))
+	}
+}
@@ -0,0 +1,84 @@
+package producer_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
+
+	"procodus.dev/demo-app/internal/producer"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/pkg/mq"
+	"procodus.dev/demo-app/pkg/mq/inmem"
+)
+
+var _ = Describe("Device tickers", func() {
+	var logger *slog.Logger
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	})
+
+	It("should publish readings of every device", func() {
+		broker := inmem.NewBroker(0)
+		server, err := producer.NewServer(&producer.ServerConfig{
+			Logger:             logger,
+			MQBroker:           broker,
+			QueueName:          "test-queue",
+			DeviceQueueName:    "device-queue",
+			ProducerCount:      2,
+			DevicesPerProducer: 25,
+			DeviceTickers:      true,
+			TickJitter:         0.2,
+			Interval:           50 * time.Millisecond,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(broker.Len("device-queue")).To(Equal(50))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- server.Run(ctx)
+		}()
+
+		// One reading per device and interval, not one per producer
+		Eventually(func() int { return broker.Len("test-queue") }, 2*time.Second).Should(BeNumerically(">=", 200))
+		cancel()
+		Eventually(done, 2*time.Second).Should(Receive(BeNil()))
+
+		client, err := broker.NewClient(context.Background(), mq.Options{Queues: []string{"test-queue"}})
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+		deliveries, err := client.Consume()
+		Expect(err).NotTo(HaveOccurred())
+
+		devices := map[string]bool{}
+		for range broker.Len("test-queue") {
+			delivery := <-deliveries
+			var reading iotv1.SensorReading
+			Expect(proto.Unmarshal(delivery.Body, &reading)).To(Succeed())
+			devices[reading.GetDeviceId()] = true
+		}
+		Expect(devices).To(HaveLen(50))
+	})
+
+	DescribeTable("should reject invalid settings",
+		func(cfg producer.ServerConfig, message string) {
+			cfg.Logger = logger
+			cfg.MQBroker = inmem.NewBroker(0)
+			cfg.ProducerCount = 1
+			cfg.Interval = time.Second
+
+			_, err := producer.NewServer(&cfg)
+			Expect(err).To(MatchError(message))
+		},
+		Entry("negative device count", producer.ServerConfig{DevicesPerProducer: -1}, "devices per producer cannot be negative"),
+		Entry("jitter above 1", producer.ServerConfig{DeviceTickers: true, TickJitter: 1.5}, "tick jitter must be between 0 and 1"),
+		Entry("jitter without device tickers", producer.ServerConfig{TickJitter: 0.1}, "tick jitter requires device tickers"),
+		Entry("device skew", producer.ServerConfig{DeviceTickers: true, DeviceSkew: 2}, "device skew cannot be combined with device tickers"),
+	)
+})
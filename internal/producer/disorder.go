@@ -0,0 +1,109 @@
+package producer
+
+import (
+	"errors"
+	mathrand "math/rand"
+	"time"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// DisorderConfig makes producers misbehave like real devices and networks do,
+// so the backend's handling of skewed, late and duplicate readings can be
+// demonstrated. The zero value publishes readings as generated.
+type DisorderConfig struct {
+	// ClockSkew is the largest offset of a device clock from real time. Each
+	// device gets a random offset in [-ClockSkew, ClockSkew] that shifts the
+	// timestamps of all its readings.
+	ClockSkew time.Duration
+	// OutOfOrderRate is the fraction of readings held back and published
+	// after the next reading of the producer.
+	OutOfOrderRate float64
+	// DuplicateRate is the fraction of readings published twice, each time
+	// with its own message ID.
+	DuplicateRate float64
+}
+
+var (
+	errNegativeClockSkew = errors.New("clock skew cannot be negative")
+	errInvalidRate       = errors.New("out-of-order and duplicate rates must be between 0 and 1")
+)
+
+// validate checks the disorder configuration.
+func (c DisorderConfig) validate() error {
+	if c.ClockSkew < 0 {
+		return errNegativeClockSkew
+	}
+
+	if c.OutOfOrderRate < 0 || c.OutOfOrderRate > 1 || c.DuplicateRate < 0 || c.DuplicateRate > 1 {
+		return errInvalidRate
+	}
+
+	return nil
+}
+
+// SetDisorder makes the producer skew, reorder and duplicate its readings as
+// configured. Devices get new random clock offsets.
+// Note: Uses math/rand which is acceptable for simulation data.
+func (p *Producer) SetDisorder(cfg DisorderConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.disorder = cfg
+	p.clockOffsets = make(map[string]time.Duration, len(p.IoTDevices))
+	if cfg.ClockSkew == 0 {
+		return
+	}
+
+	for _, device := range p.IoTDevices {
+		p.clockOffsets[device.DeviceID] = time.Duration(mathrand.Int63n(int64(2*cfg.ClockSkew)+1)) - cfg.ClockSkew // #nosec G404 - weak random is acceptable for simulation
+	}
+}
+
+// GenerateReadings generates a sensor reading of a random device and returns
+// the readings to publish next, in order. Without disorder that is just the
+// new reading; otherwise it may be held back, follow the reading held back
+// before, or appear twice.
+func (p *Producer) GenerateReadings() []*iotv1.SensorReading {
+	return p.disorderReading(p.GenerateReading())
+}
+
+// generateDeviceReadings is GenerateReadings for the device at index device.
+func (p *Producer) generateDeviceReadings(device int) []*iotv1.SensorReading {
+	return p.disorderReading(p.generateReading(device))
+}
+
+// disorderReading returns the readings to publish after reading was
+// generated, as configured by SetDisorder.
+func (p *Producer) disorderReading(reading *iotv1.SensorReading) []*iotv1.SensorReading {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var readings []*iotv1.SensorReading
+	switch {
+	case p.held != nil:
+		// The held reading arrives after the newer one
+		readings = []*iotv1.SensorReading{reading, p.held}
+		p.held = nil
+	case p.disorder.OutOfOrderRate > 0 && mathrand.Float64() < p.disorder.OutOfOrderRate: // #nosec G404 - weak random is acceptable for simulation
+		p.held = reading
+		p.countDisorder("out_of_order")
+		return nil
+	default:
+		readings = []*iotv1.SensorReading{reading}
+	}
+
+	if p.disorder.DuplicateRate > 0 && mathrand.Float64() < p.disorder.DuplicateRate { // #nosec G404 - weak random is acceptable for simulation
+		readings = append(readings, readings[len(readings)-1])
+		p.countDisorder("duplicate")
+	}
+
+	return readings
+}
+
+// countDisorder counts a reading published out of order or twice.
+func (p *Producer) countDisorder(kind string) {
+	if p.metrics != nil {
+		p.metrics.DisorderedReadings.WithLabelValues(kind).Inc()
+	}
+}
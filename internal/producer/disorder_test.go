@@ -0,0 +1,79 @@
+package producer_test
+
+import (
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"procodus.dev/demo-app/internal/producer"
+	"procodus.dev/demo-app/pkg/mq/inmem"
+	"procodus.dev/demo-app/pkg/mq/mock"
+)
+
+var _ = Describe("Disorder", func() {
+	var prod *producer.Producer
+
+	BeforeEach(func() {
+		prod = producer.NewProducer(mock.NewMockClient(), mock.NewMockClient())
+	})
+
+	It("should publish readings as generated by default", func() {
+		for range 10 {
+			readings := prod.GenerateReadings()
+			Expect(readings).To(HaveLen(1))
+			Expect(readings[0].GetTimestamp()).To(BeNumerically("~", time.Now().Unix(), 1))
+		}
+	})
+
+	It("should give each device a fixed clock offset within the skew", func() {
+		prod.SetDisorder(producer.DisorderConfig{ClockSkew: time.Hour})
+
+		offsets := map[string]int64{}
+		for range 20 {
+			reading := prod.GenerateReading()
+			offset := reading.GetTimestamp() - time.Now().Unix()
+			Expect(offset).To(BeNumerically("~", 0, int64(time.Hour/time.Second)+1))
+
+			if previous, ok := offsets[reading.GetDeviceId()]; ok {
+				Expect(offset).To(BeNumerically("~", previous, 1))
+			}
+			offsets[reading.GetDeviceId()] = offset
+		}
+	})
+
+	It("should publish held back readings after the next one", func() {
+		prod.SetDisorder(producer.DisorderConfig{OutOfOrderRate: 1})
+
+		Expect(prod.GenerateReadings()).To(BeEmpty())
+		readings := prod.GenerateReadings()
+		Expect(readings).To(HaveLen(2))
+		Expect(readings[0]).NotTo(BeIdenticalTo(readings[1]))
+	})
+
+	It("should publish duplicates twice", func() {
+		prod.SetDisorder(producer.DisorderConfig{DuplicateRate: 1})
+
+		readings := prod.GenerateReadings()
+		Expect(readings).To(HaveLen(2))
+		Expect(readings[0]).To(BeIdenticalTo(readings[1]))
+	})
+
+	DescribeTable("should reject invalid settings",
+		func(disorder producer.DisorderConfig, message string) {
+			_, err := producer.NewServer(&producer.ServerConfig{
+				Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+				MQBroker:      inmem.NewBroker(0),
+				ProducerCount: 1,
+				Interval:      time.Second,
+				Disorder:      disorder,
+			})
+			Expect(err).To(MatchError(message))
+		},
+		Entry("negative clock skew", producer.DisorderConfig{ClockSkew: -time.Second}, "clock skew cannot be negative"),
+		Entry("rate above 1", producer.DisorderConfig{DuplicateRate: 1.5}, "out-of-order and duplicate rates must be between 0 and 1"),
+		Entry("negative rate", producer.DisorderConfig{OutOfOrderRate: -0.1}, "out-of-order and duplicate rates must be between 0 and 1"),
+	)
+})
@@ -22,6 +22,7 @@ func ExampleServerUsage() {
 		QueueName:     "iot-sensor-data",
 		ProducerCount: 5,               // 5 concurrent producers
 		Interval:      5 * time.Second, // Generate data every 5 seconds
+		HandleSignals: true,            // Stop on SIGINT/SIGTERM
 	}
 
 	// Create server
@@ -72,6 +73,7 @@ func ExampleServerProgrammaticShutdown() {
 		QueueName:     "test-queue",
 		ProducerCount: 2,
 		Interval:      1 * time.Second,
+		HandleSignals: true,
 	}
 
 	server, err := NewServer(config)
@@ -80,12 +82,17 @@ func ExampleServerProgrammaticShutdown() {
 		return
 	}
 
-	// Create context with timeout for automatic shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// Stop the server after 30 seconds; Shutdown returns once Run has
+	// stopped and the MQ clients are closed
+	go func() {
+		time.Sleep(30 * time.Second)
+		if err := server.Shutdown(); err != nil {
+			log.Error("shutdown error", "error", err)
+		}
+	}()
 
 	// Run server (will shutdown after 30 seconds or on signal)
-	if err := server.Run(ctx); err != nil {
+	if err := server.Run(context.Background()); err != nil {
 		log.Error("server error", "error", err)
 	}
 }
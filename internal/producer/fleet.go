@@ -0,0 +1,56 @@
+package producer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"procodus.dev/demo-app/pkg/generator"
+)
+
+// fleetDevices returns the devices of each producer. With a fleet file, they
+// are loaded from it, split evenly over the producers, or, while the file
+// does not exist yet, generated and saved to it; otherwise they are generated.
+func (s *Server) fleetDevices() ([][]*generator.IoTDevice, error) {
+	cfg := s.config
+
+	if cfg.FleetFile != "" {
+		devices, err := generator.LoadFleet(cfg.FleetFile)
+		switch {
+		case err == nil:
+			if len(devices) < cfg.ProducerCount {
+				return nil, fmt.Errorf("fleet file %s has %d devices, fewer than the %d producers", cfg.FleetFile, len(devices), cfg.ProducerCount)
+			}
+			s.logger.Info("loaded device fleet", "path", cfg.FleetFile, "device_count", len(devices))
+			return splitFleet(devices, cfg.ProducerCount), nil
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("failed to load fleet: %w", err)
+		}
+	}
+
+	fleet := make([][]*generator.IoTDevice, cfg.ProducerCount)
+	var all []*generator.IoTDevice
+	for i := range fleet {
+		fleet[i] = newDevices(cfg.DevicesPerProducer)
+		all = append(all, fleet[i]...)
+	}
+
+	if cfg.FleetFile != "" {
+		if err := generator.SaveFleet(cfg.FleetFile, all); err != nil {
+			return nil, fmt.Errorf("failed to save fleet: %w", err)
+		}
+		s.logger.Info("saved device fleet", "path", cfg.FleetFile, "device_count", len(all))
+	}
+
+	return fleet, nil
+}
+
+// splitFleet splits devices into n parts whose sizes differ by at most one,
+// keeping their order.
+func splitFleet(devices []*generator.IoTDevice, n int) [][]*generator.IoTDevice {
+	parts := make([][]*generator.IoTDevice, n)
+	for i := range parts {
+		parts[i] = devices[i*len(devices)/n : (i+1)*len(devices)/n]
+	}
+	return parts
+}
@@ -0,0 +1,92 @@
+package producer_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
+
+	"procodus.dev/demo-app/internal/producer"
+	"procodus.dev/demo-app/pkg/generator"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/pkg/mq"
+	"procodus.dev/demo-app/pkg/mq/inmem"
+)
+
+var _ = Describe("Fleet file", func() {
+	var config *producer.ServerConfig
+
+	BeforeEach(func() {
+		config = &producer.ServerConfig{
+			Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+			MQBroker:           inmem.NewBroker(0),
+			QueueName:          "test-queue",
+			DeviceQueueName:    "device-queue",
+			ProducerCount:      2,
+			DevicesPerProducer: 3,
+			Interval:           time.Second,
+			FleetFile:          filepath.Join(GinkgoT().TempDir(), "fleet.yaml"),
+		}
+	})
+
+	// deviceIDs creates a server and returns the IDs of the devices it
+	// announced, in producer order.
+	deviceIDs := func() []string {
+		broker := inmem.NewBroker(0)
+		config.MQBroker = broker
+		server, err := producer.NewServer(config)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(server.Shutdown)
+
+		client, err := broker.NewClient(context.Background(), mq.Options{Queues: []string{"device-queue"}})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(client.Close)
+		deliveries, err := client.Consume()
+		Expect(err).NotTo(HaveOccurred())
+
+		var ids []string
+		for range broker.Len("device-queue") {
+			var device iotv1.IoTDevice
+			Expect(proto.Unmarshal((<-deliveries).Body, &device)).To(Succeed())
+			ids = append(ids, device.GetDeviceId())
+		}
+		return ids
+	}
+
+	It("should keep the device IDs across runs", func() {
+		first := deviceIDs()
+		Expect(first).To(HaveLen(6))
+
+		saved, err := generator.LoadFleet(config.FleetFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(saved).To(HaveLen(6))
+
+		Expect(deviceIDs()).To(Equal(first))
+	})
+
+	It("should split the loaded devices over the producers", func() {
+		devices := make([]*generator.IoTDevice, 5)
+		for i := range devices {
+			devices[i] = generator.NewIoTDevice()
+		}
+		Expect(generator.SaveFleet(config.FleetFile, devices)).To(Succeed())
+
+		// Devices per producer is ignored; the first producer gets two devices
+		Expect(deviceIDs()).To(Equal([]string{
+			devices[0].DeviceID, devices[1].DeviceID,
+			devices[2].DeviceID, devices[3].DeviceID, devices[4].DeviceID,
+		}))
+	})
+
+	It("should reject fleets with fewer devices than producers", func() {
+		Expect(generator.SaveFleet(config.FleetFile, []*generator.IoTDevice{generator.NewIoTDevice()})).To(Succeed())
+
+		_, err := producer.NewServer(config)
+		Expect(err).To(MatchError(ContainSubstring("has 1 devices, fewer than the 2 producers")))
+	})
+})
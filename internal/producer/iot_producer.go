@@ -3,37 +3,58 @@ package producer
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"log/slog"
-	"math/rand"
+	mathrand "math/rand"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/protobuf/proto"
 
 	"procodus.dev/demo-app/pkg/generator"
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
 	"procodus.dev/demo-app/pkg/metrics"
 	"procodus.dev/demo-app/pkg/mq"
 )
 
+// Message priorities, honored by queues declared with priorities. Device
+// upserts outrank readings so a backlog of readings cannot hold them back.
+const (
+	devicePriority  = mq.MaxPriority
+	readingPriority = 0
+)
+
 // Producer manages IoT devices and publishes sensor data to a message queue.
 type Producer struct {
 	MQClient       mq.ClientInterface
 	DeviceMQClient mq.ClientInterface
 	IoTDevices     []*generator.IoTDevice
 	metrics        *metrics.ProducerMetrics // Optional metrics
+	imperial       bool                     // Publish in Fahrenheit and inches of mercury
+
+	mu           sync.Mutex     // Guards the selection and disorder state below
+	zipf         *mathrand.Zipf // Skewed device selection; nil = uniform
+	disorder     DisorderConfig
+	clockOffsets map[string]time.Duration // Clock offset per device ID
+	held         *iotv1.SensorReading     // Reading held back to publish out of order
 }
 
 // NewProducer creates a new producer with a random number of IoT devices.
 // It publishes device creation messages for each device.
 // Note: Uses math/rand for device generation which is acceptable for simulation data.
 func NewProducer(mqClient mq.ClientInterface, deviceMQClient mq.ClientInterface) *Producer {
-	deviceCount := rand.Intn(5) + 1 // #nosec G404 - weak random is acceptable for test data generation
-	iotDevices := make([]*generator.IoTDevice, 0, deviceCount)
-	for range deviceCount {
-		iotDevices = append(iotDevices, generator.NewIoTDevice())
-	}
+	producer := newProducer(mqClient, deviceMQClient, newDevices(0))
+	producer.publishDevices()
+
+	return producer
+}
 
+// newProducer creates a new producer simulating iotDevices without
+// announcing them.
+func newProducer(mqClient mq.ClientInterface, deviceMQClient mq.ClientInterface, iotDevices []*generator.IoTDevice) *Producer {
 	producer := &Producer{
 		MQClient:       mqClient,
 		DeviceMQClient: deviceMQClient,
@@ -42,19 +63,25 @@ func NewProducer(mqClient mq.ClientInterface, deviceMQClient mq.ClientInterface)
 
 	// Track devices generated
 	if producer.metrics != nil {
-		producer.metrics.DevicesGenerated.Add(float64(deviceCount))
+		producer.metrics.DevicesGenerated.Add(float64(len(iotDevices)))
 	}
 
-	// Publish device creation messages
-	for _, device := range iotDevices {
-		if err := producer.publishDeviceCreation(device); err != nil {
-			// Log error but continue with other devices
-			slog.Error(err.Error())
-			continue
-		}
+	return producer
+}
+
+// newDevices generates count IoT devices, or a random number of them from 1
+// to 5 if count is 0.
+// Note: Uses math/rand for device generation which is acceptable for simulation data.
+func newDevices(count int) []*generator.IoTDevice {
+	if count == 0 {
+		count = mathrand.Intn(5) + 1 // #nosec G404 - weak random is acceptable for test data generation
 	}
 
-	return producer
+	devices := make([]*generator.IoTDevice, 0, count)
+	for range count {
+		devices = append(devices, generator.NewIoTDevice())
+	}
+	return devices
 }
 
 // SetMetrics sets the metrics collector for this producer.
@@ -63,6 +90,17 @@ func (p *Producer) SetMetrics(m *metrics.ProducerMetrics) {
 	p.metrics = m
 }
 
+// publishDevices publishes a device creation message for each device.
+func (p *Producer) publishDevices() {
+	for _, device := range p.IoTDevices {
+		if err := p.publishDeviceCreation(device); err != nil {
+			// Log error but continue with other devices
+			slog.Error(err.Error())
+			continue
+		}
+	}
+}
+
 // publishDeviceCreation publishes an IoT device creation message to the device queue.
 func (p *Producer) publishDeviceCreation(device *generator.IoTDevice) error {
 	// Track duration
@@ -72,20 +110,8 @@ func (p *Producer) publishDeviceCreation(device *generator.IoTDevice) error {
 		defer timer.ObserveDuration()
 	}
 
-	// Transform generator.IoTDevice to proto iot.IoTDevice
-	protoDevice := &iot.IoTDevice{
-		DeviceId:   device.DeviceID,
-		Timestamp:  device.Timestamp.Unix(),
-		Location:   device.Location,
-		MacAddress: device.MacAddress,
-		IpAddress:  device.IPAddress,
-		Firmware:   device.Firmware,
-		Latitude:   float32(device.Latitude),
-		Longitude:  float32(device.Longitude),
-	}
-
 	// Marshal to protobuf
-	message, err := proto.Marshal(protoDevice)
+	message, err := proto.Marshal(deviceToProto(device))
 	if err != nil {
 		// Track failure
 		if p.metrics != nil {
@@ -100,7 +126,7 @@ func (p *Producer) publishDeviceCreation(device *generator.IoTDevice) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
 	defer cancel()
 
-	if err := p.DeviceMQClient.Push(ctx, message); err != nil {
+	if err := p.DeviceMQClient.PushWithOptions(ctx, message, mq.PublishOptions{MessageID: newMessageID(), Priority: devicePriority}); err != nil {
 		// Track failure
 		if p.metrics != nil {
 			p.metrics.GenerationFailures.WithLabelValues("device", "push_error").Inc()
@@ -116,9 +142,64 @@ func (p *Producer) publishDeviceCreation(device *generator.IoTDevice) error {
 	return nil
 }
 
-// RandomDataPoint generates a random sensor reading and publishes it to the message queue.
-// Note: Uses math/rand for device selection which is acceptable for simulation data.
+// RegisterDevices registers the devices of the producer with the backend
+// through the CreateOrUpdateDevice RPC, so they exist before their first
+// reading arrives.
+func (p *Producer) RegisterDevices(ctx context.Context, client iotv1.IoTServiceClient) error {
+	for _, device := range p.IoTDevices {
+		_, err := client.CreateOrUpdateDevice(ctx, &iotv1.CreateOrUpdateDeviceRequest{Device: deviceToProto(device)})
+		if err != nil {
+			// Track failure
+			if p.metrics != nil {
+				p.metrics.GenerationFailures.WithLabelValues("device", "register_error").Inc()
+			}
+			return fmt.Errorf("failed to register device %s: %w", device.DeviceID, err)
+		}
+
+		// Track success
+		if p.metrics != nil {
+			p.metrics.MessagesGenerated.WithLabelValues("device").Inc()
+		}
+	}
+
+	return nil
+}
+
+// deviceToProto transforms a generated device to its proto message.
+func deviceToProto(device *generator.IoTDevice) *iotv1.IoTDevice {
+	return &iotv1.IoTDevice{
+		DeviceId:   device.DeviceID,
+		Timestamp:  device.Timestamp.Unix(),
+		Location:   device.Location,
+		MacAddress: device.MacAddress,
+		IpAddress:  device.IPAddress,
+		Firmware:   device.Firmware,
+		Latitude:   float32(device.Latitude),
+		Longitude:  float32(device.Longitude),
+	}
+}
+
+// RandomDataPoint generates a random sensor reading and publishes it to the
+// message queue, together with readings held back or duplicated by the
+// disorder settings, see GenerateReadings.
 func (p *Producer) RandomDataPoint(ctx context.Context) error {
+	return p.publishEach(ctx, p.GenerateReadings())
+}
+
+// publishEach publishes readings one message each, stopping at the first
+// failure.
+func (p *Producer) publishEach(ctx context.Context, readings []*iotv1.SensorReading) error {
+	for _, reading := range readings {
+		if err := p.publishReading(ctx, reading); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// publishReading publishes a single sensor reading to the message queue.
+func (p *Producer) publishReading(ctx context.Context, reading *iotv1.SensorReading) error {
 	// Track duration
 	var timer *prometheus.Timer
 	if p.metrics != nil {
@@ -126,15 +207,8 @@ func (p *Producer) RandomDataPoint(ctx context.Context) error {
 		defer timer.ObserveDuration()
 	}
 
-	// Select a random device
-	deviceID := p.IoTDevices[rand.Intn(len(p.IoTDevices))].DeviceID // #nosec G404 - weak random is acceptable for simulation
-
-	// Generate sensor reading
-	iotDataGen := generator.NewIoTGenerator(deviceID)
-	reading := iotDataGen.GenerateCorrelatedReading(time.Now())
-
 	// Marshal to protobuf
-	message, err := proto.Marshal(reading)
+	message, err := proto.Marshal(p.inPublishedUnits(reading))
 	if err != nil {
 		// Track failure
 		if p.metrics != nil {
@@ -144,7 +218,12 @@ func (p *Producer) RandomDataPoint(ctx context.Context) error {
 	}
 
 	// Publish to message queue
-	if err := p.MQClient.Push(ctx, message); err != nil {
+	opts := mq.PublishOptions{
+		Headers:   p.unitHeaders(),
+		MessageID: newMessageID(),
+		Priority:  readingPriority,
+	}
+	if err := p.MQClient.PushWithOptions(ctx, message, opts); err != nil {
 		// Track failure
 		if p.metrics != nil {
 			p.metrics.GenerationFailures.WithLabelValues("sensor_reading", "push_error").Inc()
@@ -160,3 +239,86 @@ func (p *Producer) RandomDataPoint(ctx context.Context) error {
 
 	return nil
 }
+
+// GenerateReading generates a sensor reading of a random device without
+// publishing it. Devices are picked uniformly unless SetDeviceSkew weights them.
+// Note: Uses math/rand for device selection which is acceptable for simulation data.
+func (p *Producer) GenerateReading() *iotv1.SensorReading {
+	p.mu.Lock()
+	device := p.pickDevice()
+	p.mu.Unlock()
+
+	return p.generateReading(device)
+}
+
+// generateReading generates a sensor reading of the device at index device.
+func (p *Producer) generateReading(device int) *iotv1.SensorReading {
+	deviceID := p.IoTDevices[device].DeviceID
+
+	// A skewed device clock shifts the timestamp
+	p.mu.Lock()
+	offset := p.clockOffsets[deviceID]
+	p.mu.Unlock()
+
+	iotDataGen := generator.NewIoTGenerator(deviceID)
+	return iotDataGen.GenerateCorrelatedReading(time.Now().Add(offset))
+}
+
+// PublishReadings publishes readings as one SensorReadingBatch message, which
+// costs the broker and the backend far less than a message per reading.
+func (p *Producer) PublishReadings(ctx context.Context, readings []*iotv1.SensorReading) error {
+	if len(readings) == 0 {
+		return nil
+	}
+
+	// Track duration
+	var timer *prometheus.Timer
+	if p.metrics != nil {
+		timer = prometheus.NewTimer(p.metrics.GenerationDuration.WithLabelValues("sensor_reading_batch"))
+		defer timer.ObserveDuration()
+	}
+
+	batch := &iotv1.SensorReadingBatch{Readings: make([]*iotv1.SensorReading, 0, len(readings))}
+	for _, reading := range readings {
+		batch.Readings = append(batch.Readings, p.inPublishedUnits(reading))
+	}
+
+	message, err := proto.Marshal(batch)
+	if err != nil {
+		// Track failure
+		if p.metrics != nil {
+			p.metrics.GenerationFailures.WithLabelValues("sensor_reading_batch", "marshal_error").Inc()
+		}
+		return err
+	}
+
+	opts := mq.PublishOptions{
+		Headers:   p.unitHeaders(),
+		MessageID: newMessageID(),
+		Type:      iotv1.MessageTypeSensorReadingBatch,
+		Priority:  readingPriority,
+	}
+	if err := p.MQClient.PushWithOptions(ctx, message, opts); err != nil {
+		// Track failure
+		if p.metrics != nil {
+			p.metrics.GenerationFailures.WithLabelValues("sensor_reading_batch", "push_error").Inc()
+		}
+		return err
+	}
+
+	// Track success
+	if p.metrics != nil {
+		p.metrics.MessagesGenerated.WithLabelValues("sensor_reading_batch").Inc()
+		p.metrics.SensorReadingsCreated.Add(float64(len(readings)))
+	}
+
+	return nil
+}
+
+// newMessageID returns a random 128-bit hex-encoded message ID. The backend
+// records it to skip redeliveries of a message it already saved.
+func newMessageID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // crypto/rand.Read never returns an error
+	return hex.EncodeToString(b)
+}
@@ -5,8 +5,10 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
 
 	"procodus.dev/demo-app/internal/producer"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
 	"procodus.dev/demo-app/pkg/mq"
 	"procodus.dev/demo-app/pkg/mq/mock"
 )
@@ -45,6 +47,21 @@ var _ = Describe("IoT Producer", func() {
 			Expect(prod.MQClient).To(Equal(mqClient))
 		})
 
+		It("should publish device messages with the highest priority", func() {
+			prod := producer.NewProducer(mqClient, deviceMQClient)
+			Expect(prod.RandomDataPoint(context.Background())).To(Succeed())
+
+			devices := deviceMQClient.(*mock.MockClient).PushWithOptionsCalls
+			Expect(devices).To(HaveLen(len(prod.IoTDevices)))
+			for _, call := range devices {
+				Expect(call.Options.Priority).To(Equal(uint8(mq.MaxPriority)))
+			}
+
+			readings := mqClient.(*mock.MockClient).PushWithOptionsCalls
+			Expect(readings).To(HaveLen(1))
+			Expect(readings[0].Options.Priority).To(BeZero())
+		})
+
 		It("should create different device sets on multiple calls", func() {
 			prod1 := producer.NewProducer(mqClient, deviceMQClient)
 			prod2 := producer.NewProducer(mqClient, deviceMQClient)
@@ -80,9 +97,20 @@ var _ = Describe("IoT Producer", func() {
 				err := prod.RandomDataPoint(ctx)
 				Expect(err).NotTo(HaveOccurred())
 
-				// Verify Push was called
+				// Verify PushWithOptions was called
 				mockClient := mqClient.(*mock.MockClient)
-				Expect(mockClient.PushCalls).To(HaveLen(1))
+				Expect(mockClient.PushWithOptionsCalls).To(HaveLen(1))
+			})
+
+			It("should give every message its own ID", func() {
+				ctx := context.Background()
+				Expect(prod.RandomDataPoint(ctx)).To(Succeed())
+				Expect(prod.RandomDataPoint(ctx)).To(Succeed())
+
+				calls := mqClient.(*mock.MockClient).PushWithOptionsCalls
+				Expect(calls).To(HaveLen(2))
+				Expect(calls[0].Options.MessageID).To(HaveLen(32))
+				Expect(calls[1].Options.MessageID).NotTo(Equal(calls[0].Options.MessageID))
 			})
 		})
 
@@ -94,8 +122,8 @@ var _ = Describe("IoT Producer", func() {
 
 				// Verify context was passed through
 				mockClient := mqClient.(*mock.MockClient)
-				Expect(mockClient.PushCalls).To(HaveLen(1))
-				Expect(mockClient.PushCalls[0].Ctx).To(Equal(ctx))
+				Expect(mockClient.PushWithOptionsCalls).To(HaveLen(1))
+				Expect(mockClient.PushWithOptionsCalls[0].Ctx).To(Equal(ctx))
 			})
 
 			It("should accept a canceled context", func() {
@@ -107,11 +135,63 @@ var _ = Describe("IoT Producer", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				mockClient := mqClient.(*mock.MockClient)
-				Expect(mockClient.PushCalls).To(HaveLen(1))
+				Expect(mockClient.PushWithOptionsCalls).To(HaveLen(1))
 			})
 		})
 	})
 
+	Describe("PublishReadings", func() {
+		It("should publish the readings as one batch message", func() {
+			mqClient = mock.NewMockClient()
+			prod := producer.NewProducer(mqClient, mock.NewMockClient())
+			readings := []*iotv1.SensorReading{prod.GenerateReading(), prod.GenerateReading()}
+
+			Expect(prod.PublishReadings(context.Background(), readings)).To(Succeed())
+
+			calls := mqClient.(*mock.MockClient).PushWithOptionsCalls
+			Expect(calls).To(HaveLen(1))
+			Expect(calls[0].Options.Type).To(Equal(iotv1.MessageTypeSensorReadingBatch))
+			Expect(calls[0].Options.MessageID).To(HaveLen(32))
+
+			var batch iotv1.SensorReadingBatch
+			Expect(proto.Unmarshal(calls[0].Data, &batch)).To(Succeed())
+			Expect(batch.GetReadings()).To(HaveLen(2))
+			Expect(batch.GetReadings()[1].GetDeviceId()).To(Equal(readings[1].GetDeviceId()))
+		})
+	})
+
+	Describe("SetImperialUnits", func() {
+		It("should publish readings in imperial units and declare them", func() {
+			mqClient = mock.NewMockClient()
+			prod := producer.NewProducer(mqClient, mock.NewMockClient())
+			prod.SetImperialUnits(true)
+
+			reading := prod.GenerateReading()
+			Expect(prod.PublishReadings(context.Background(), []*iotv1.SensorReading{reading})).To(Succeed())
+
+			call := mqClient.(*mock.MockClient).PushWithOptionsCalls[0]
+			Expect(call.Options.Headers).To(HaveKeyWithValue(iotv1.HeaderTemperatureUnit, iotv1.TemperatureUnitFahrenheit))
+			Expect(call.Options.Headers).To(HaveKeyWithValue(iotv1.HeaderPressureUnit, iotv1.PressureUnitInchesOfMercury))
+
+			var batch iotv1.SensorReadingBatch
+			Expect(proto.Unmarshal(call.Data, &batch)).To(Succeed())
+			published := batch.GetReadings()[0]
+			Expect(published.GetTemperature()).To(BeNumerically("~", iotv1.CelsiusToFahrenheit(reading.GetTemperature()), 1e-9))
+			Expect(published.GetPressure()).To(BeNumerically("~", iotv1.HectopascalsToInchesOfMercury(reading.GetPressure()), 1e-9))
+
+			// The reading itself keeps its canonical units
+			Expect(reading.GetTemperature()).NotTo(Equal(published.GetTemperature()))
+		})
+
+		It("should not declare units by default", func() {
+			mqClient = mock.NewMockClient()
+			prod := producer.NewProducer(mqClient, mock.NewMockClient())
+			Expect(prod.RandomDataPoint(context.Background())).To(Succeed())
+
+			Expect(mqClient.(*mock.MockClient).PushWithOptionsCalls[0].Options.Headers).To(BeNil())
+		})
+	})
+
 	Describe("Producer Integration", func() {
 		It("should have valid device data structure", func() {
 			mockClient := mock.NewMockClient()
@@ -146,8 +226,8 @@ var _ = Describe("IoT Producer", func() {
 			// Device count should remain the same
 			Expect(len(prod.IoTDevices)).To(Equal(initialCount))
 
-			// Verify Push was called 5 times
-			Expect(mockClient.PushCalls).To(HaveLen(5))
+			// Verify PushWithOptions was called 5 times
+			Expect(mockClient.PushWithOptionsCalls).To(HaveLen(5))
 		})
 	})
 
@@ -175,7 +255,7 @@ var _ = Describe("IoT Producer", func() {
 			}
 
 			// Verify all 5 calls were made (MockClient is thread-safe)
-			Expect(mockClient.PushCalls).To(HaveLen(5))
+			Expect(mockClient.PushWithOptionsCalls).To(HaveLen(5))
 		})
 	})
 })
@@ -12,8 +12,14 @@ import (
 	"syscall"
 	"time"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"procodus.dev/demo-app/pkg/faults"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
 	"procodus.dev/demo-app/pkg/metrics"
 	"procodus.dev/demo-app/pkg/mq"
+	"procodus.dev/demo-app/pkg/mq/inmem"
 )
 
 // ServerConfig holds the configuration for the producer server.
@@ -22,20 +28,90 @@ type ServerConfig struct {
 	Logger *slog.Logger
 	// RabbitMQURL is the connection string for RabbitMQ
 	RabbitMQURL string
+	// MQBroker replaces RabbitMQ with an in-process broker, e.g. when all
+	// services run in one process (optional)
+	MQBroker *inmem.Broker
 	// QueueName is the name of the queue to publish sensor readings to
 	QueueName string
 	// DeviceQueueName is the name of the queue to publish device creation messages to
 	DeviceQueueName string
+	// BackendGRPCAddr makes producers register their devices with the backend
+	// at this address through the CreateOrUpdateDevice RPC before publishing
+	// readings, instead of publishing them to the device queue (optional)
+	BackendGRPCAddr string
+	// DurableQueues declares durable queues and publishes persistent messages;
+	// it must match the backend's setting
+	DurableQueues bool
+	// PriorityQueues declares the queues with message priorities, which
+	// RabbitMQ then delivers highest first; it must match the backend's setting
+	PriorityQueues bool
+	// Compression compresses large message bodies with mq.CompressionGzip or
+	// mq.CompressionZstd (optional, empty = none); the backend decompresses
+	// them whatever its own setting
+	Compression string
 	// Interval is the time between data point generation
 	Interval time.Duration
+	// DevicesPerProducer is the number of devices each producer simulates
+	// (optional, 0 = a random number from 1 to 5)
+	DevicesPerProducer int
+	// FleetFile is a JSON or YAML file holding the devices of all producers,
+	// chosen by its extension .json, .yaml or .yml. If it exists, its devices
+	// are split over the producers instead of generating new ones; otherwise
+	// the generated devices are saved to it, so later runs keep the same
+	// device IDs (optional)
+	FleetFile string
+	// DeviceTickers makes every device generate its own readings each
+	// Interval, in a goroutine of its own, instead of each producer generating
+	// a reading of a random device each Interval. Devices start at random
+	// offsets within the interval.
+	DeviceTickers bool
+	// TickJitter varies each device interval by up to this fraction in either
+	// direction, for irregular arrivals like those of real devices; it
+	// requires DeviceTickers (optional, 0 = exact interval)
+	TickJitter float64
+	// BatchInterval makes producers collect the readings generated in this
+	// time and publish them as one batch message (optional, 0 = publish every
+	// reading on its own)
+	BatchInterval time.Duration
+	// Disorder skews, reorders and duplicates readings to exercise the
+	// backend's ingestion (optional, zero = readings as generated)
+	Disorder DisorderConfig
+	// DeviceSkew is the exponent of a Zipf distribution weighting which device
+	// of a producer each reading belongs to, so a few hot devices dominate
+	// traffic; it must be greater than 1 (optional, 0 = uniform)
+	DeviceSkew float64
+	// ImperialUnits publishes temperatures in degrees Fahrenheit and
+	// pressures in inches of mercury, declared by message headers; the backend
+	// converts them to Celsius and hectopascals (optional)
+	ImperialUnits bool
 	// ProducerCount is the number of concurrent producers
 	ProducerCount int
+	// UnhealthyAfter is how long a producer may go without a successful push
+	// before it is marked unhealthy and its MQ clients are recreated
+	// (optional, default 1 minute)
+	UnhealthyAfter time.Duration
+	// PushTimeout bounds a single data point push (optional, default 10 seconds)
+	PushTimeout time.Duration
+	// DrainTimeout is how long shutdown waits for in-flight pushes to finish
+	// before canceling them and closing the MQ clients (optional, 0 = cancel immediately)
+	DrainTimeout time.Duration
 	// Metrics is the optional Prometheus metrics collector
 	Metrics *metrics.ProducerMetrics
 	// MQMetrics is the optional Prometheus metrics collector for MQ operations
 	MQMetrics *metrics.MQMetrics
 	// MetricsPort is the HTTP port for Prometheus metrics endpoint (optional, 0 = disabled)
 	MetricsPort int
+	// PprofPort is the HTTP port for the pprof debug server (optional, 0 = disabled)
+	PprofPort int
+	// Faults drops MQ connections for resilience tests (optional, nil = none)
+	Faults *faults.Injector
+	// HandleSignals makes Run shut down on SIGINT and SIGTERM. The generator
+	// command sets it; servers embedded with others in one process leave it
+	// unset and stop when the context passed to Run is canceled or on Shutdown.
+	HandleSignals bool
+	// StartupConfig is the resolved configuration logged with the startup
+	// event, as key-value pairs with secrets redacted (optional)
+	StartupConfig []any
 }
 
 // Server manages multiple producer instances.
@@ -43,16 +119,30 @@ type Server struct {
 	logger        *slog.Logger
 	config        *ServerConfig
 	producers     []*Producer
-	clients       []*mq.Client
-	deviceClients []*mq.Client
+	clients       []mq.ClientInterface
+	deviceClients []mq.ClientInterface
+	clientsMu     sync.Mutex // Guards clients and deviceClients, which restarts replace
+	supervisor    *supervisor
+	runMu         sync.Mutex         // Guards cancel and stopped
+	cancel        context.CancelFunc // Stops a running Run; nil while not running
+	stopped       chan struct{}      // Closed when a running Run returns
 	wg            sync.WaitGroup
 	metrics       *metrics.ProducerMetrics
+	backendConn   *grpc.ClientConn       // nil unless devices are registered through the backend
+	backend       iotv1.IoTServiceClient // Registers devices; nil when backendConn is
 }
 
+// registrationRetryDelay is the time between attempts to register the devices
+// of a producer while the backend is unavailable.
+const registrationRetryDelay = time.Second
+
 var (
-	errInvalidProducerCount = errors.New("producer count must be greater than 0")
-	errInvalidInterval      = errors.New("interval must be greater than 0")
-	errLoggerRequired       = errors.New("logger is required")
+	errInvalidProducerCount  = errors.New("producer count must be greater than 0")
+	errInvalidInterval       = errors.New("interval must be greater than 0")
+	errLoggerRequired        = errors.New("logger is required")
+	errNegativeTimeout       = errors.New("unhealthy-after and push timeout cannot be negative")
+	errNegativeDrainTimeout  = errors.New("drain timeout cannot be negative")
+	errNegativeBatchInterval = errors.New("batch interval cannot be negative")
 )
 
 // NewServer creates a new producer server with the given configuration.
@@ -69,46 +159,85 @@ func NewServer(cfg *ServerConfig) (*Server, error) {
 		return nil, errLoggerRequired
 	}
 
+	if cfg.UnhealthyAfter < 0 || cfg.PushTimeout < 0 {
+		return nil, errNegativeTimeout
+	}
+
+	if cfg.DrainTimeout < 0 {
+		return nil, errNegativeDrainTimeout
+	}
+
+	if cfg.BatchInterval < 0 {
+		return nil, errNegativeBatchInterval
+	}
+
+	if err := cfg.Disorder.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := validateDeviceSkew(cfg.DeviceSkew); err != nil {
+		return nil, err
+	}
+
+	if err := validateDeviceTickers(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.UnhealthyAfter == 0 {
+		cfg.UnhealthyAfter = defaultUnhealthyAfter
+	}
+
+	if cfg.PushTimeout == 0 {
+		cfg.PushTimeout = defaultPushTimeout
+	}
+
 	s := &Server{
 		config:        cfg,
 		producers:     make([]*Producer, 0, cfg.ProducerCount),
-		clients:       make([]*mq.Client, 0, cfg.ProducerCount),
-		deviceClients: make([]*mq.Client, 0, cfg.ProducerCount),
+		clients:       make([]mq.ClientInterface, 0, cfg.ProducerCount),
+		deviceClients: make([]mq.ClientInterface, 0, cfg.ProducerCount),
 		logger:        cfg.Logger,
 		metrics:       cfg.Metrics,
 	}
 
+	fleet, err := s.fleetDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.BackendGRPCAddr != "" {
+		// The connection is established lazily, so the backend may start later
+		conn, err := grpc.NewClient(cfg.BackendGRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create backend client: %w", err)
+		}
+		s.backendConn = conn
+		s.backend = iotv1.NewIoTServiceClient(conn)
+	}
+
 	// Create producer instances with their own MQ clients
 	for i := 0; i < cfg.ProducerCount; i++ {
-		// Create MQ client for sensor readings
-		client := mq.New(cfg.QueueName, cfg.RabbitMQURL, cfg.Logger.With(
-			slog.String("component", "mq-client"),
-			slog.Int("producer_id", i),
-		))
-
-		// Enable MQ metrics if configured
-		if cfg.MQMetrics != nil {
-			client.SetMetrics(cfg.MQMetrics)
+		client, deviceClient, err := s.newClients(i)
+		if err != nil {
+			s.closeClients()
+			s.closeBackend()
+			return nil, err
 		}
 
-		// Create MQ client for device creation messages
-		deviceClient := mq.New(cfg.DeviceQueueName, cfg.RabbitMQURL, cfg.Logger.With(
-			slog.String("component", "device-mq-client"),
-			slog.Int("producer_id", i),
-		))
-
-		// Enable MQ metrics if configured
-		if cfg.MQMetrics != nil {
-			deviceClient.SetMetrics(cfg.MQMetrics)
+		// Create producer with both clients; with a backend, devices are
+		// registered when the producer starts
+		producer := newProducer(client, deviceClient, fleet[i])
+		if s.backend == nil {
+			producer.publishDevices()
 		}
 
-		// Create producer with both clients
-		producer := NewProducer(client, deviceClient)
-
 		// Enable producer metrics if configured
 		if cfg.Metrics != nil {
 			producer.SetMetrics(cfg.Metrics)
 		}
+		producer.SetDisorder(cfg.Disorder)
+		producer.SetDeviceSkew(cfg.DeviceSkew)
+		producer.SetImperialUnits(cfg.ImperialUnits)
 
 		s.clients = append(s.clients, client)
 		s.deviceClients = append(s.deviceClients, deviceClient)
@@ -122,30 +251,99 @@ func NewServer(cfg *ServerConfig) (*Server, error) {
 		)
 	}
 
+	s.supervisor = newSupervisor(len(s.producers), s.config.UnhealthyAfter, s.metrics, time.Now())
+
 	return s, nil
 }
 
-// Run starts all producers and blocks until shutdown signal is received.
+// newClients creates the MQ clients for sensor readings and device creation
+// messages of one producer.
+func (s *Server) newClients(id int) (mq.ClientInterface, mq.ClientInterface, error) {
+	// Create MQ client for sensor readings
+	client, err := s.newClient(s.config.QueueName, s.config.Logger.With(
+		slog.String("component", "mq-client"),
+		slog.Int("producer_id", id),
+	))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create MQ client: %w", err)
+	}
+
+	// Create MQ client for device creation messages
+	deviceClient, err := s.newClient(s.config.DeviceQueueName, s.config.Logger.With(
+		slog.String("component", "device-mq-client"),
+		slog.Int("producer_id", id),
+	))
+	if err != nil {
+		_ = client.Close()
+		return nil, nil, fmt.Errorf("failed to create device MQ client: %w", err)
+	}
+
+	return client, deviceClient, nil
+}
+
+// newClient creates an MQ client publishing to queue, connected to RabbitMQ
+// or to the in-process broker if one is configured.
+func (s *Server) newClient(queue string, logger *slog.Logger) (mq.ClientInterface, error) {
+	cfg := s.config
+
+	opts := mq.Options{Queues: []string{queue}, Durable: cfg.DurableQueues, Compression: cfg.Compression}
+	if cfg.PriorityQueues {
+		opts.MaxPriority = mq.MaxPriority
+	}
+	if cfg.MQBroker != nil {
+		return cfg.MQBroker.NewClient(context.Background(), opts)
+	}
+	if cfg.Faults != nil {
+		opts.Faults = cfg.Faults
+	}
+
+	client, err := mq.NewWithOptions(context.Background(), cfg.RabbitMQURL, opts, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Enable MQ metrics if configured
+	if cfg.MQMetrics != nil {
+		client.SetMetrics(cfg.MQMetrics)
+	}
+
+	return client, nil
+}
+
+// Run starts all producers and blocks until the context is canceled, Shutdown
+// is called or, with HandleSignals, a shutdown signal is received.
 func (s *Server) Run(ctx context.Context) error {
-	// Create context that can be canceled
+	// Create context that can be canceled, either here or by Shutdown
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// Setup signal handling for graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+	stopped := make(chan struct{})
+	defer close(stopped)
+
+	s.runMu.Lock()
+	s.cancel, s.stopped = cancel, stopped
+	s.runMu.Unlock()
+
+	// Set up signal handling unless the embedding process handles signals;
+	// receiving from the nil channel otherwise blocks forever
+	var sigChan chan os.Signal
+	if s.config.HandleSignals {
+		sigChan = make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+		defer signal.Stop(sigChan)
+	}
+
+	// Pushes outlive ctx so shutdown can drain them; they are canceled only
+	// once the drain deadline passes
+	pushCtx, cancelPushes := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelPushes()
 
 	// Start all producers
 	for i, producer := range s.producers {
 		s.wg.Add(1)
-		go s.runProducer(ctx, i, producer)
+		go s.runProducer(ctx, pushCtx, i, producer)
 	}
 
-	s.logger.Info("producer server started",
-		"producer_count", len(s.producers),
-		"interval", s.config.Interval,
-	)
-
 	// Start metrics HTTP server if configured
 	var metricsServer *http.Server
 	if s.config.MetricsPort > 0 && s.config.Metrics != nil {
@@ -162,12 +360,33 @@ func (s *Server) Run(ctx context.Context) error {
 		}
 
 		go func() {
-			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 				s.logger.Error("metrics server error", "error", err)
 			}
 		}()
 	}
 
+	// Start pprof debug server if configured
+	var pprofServer *http.Server
+	if s.config.PprofPort > 0 {
+		pprofAddr := fmt.Sprintf(":%d", s.config.PprofPort)
+		s.logger.Info("starting pprof debug server", "address", pprofAddr)
+
+		pprofServer = &http.Server{
+			Addr:              pprofAddr,
+			Handler:           metrics.DebugHandler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		go func() {
+			if err := pprofServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Error("pprof server error", "error", err)
+			}
+		}()
+	}
+
+	s.logStartup()
+
 	// Wait for shutdown signal
 	select {
 	case sig := <-sigChan:
@@ -179,27 +398,37 @@ func (s *Server) Run(ctx context.Context) error {
 
 	// Shutdown metrics server
 	if metricsServer != nil {
-		shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 5*time.Second)
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer shutdownCancel()
 		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
 			s.logger.Error("failed to shutdown metrics server", "error", err)
 		}
 	}
 
-	// Wait for all producers to finish
-	s.logger.Info("waiting for producers to shut down...")
-	s.wg.Wait()
+	// Shutdown pprof debug server
+	if pprofServer != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		if err := pprofServer.Shutdown(shutdownCtx); err != nil {
+			s.logger.Error("failed to shutdown pprof server", "error", err)
+		}
+	}
+
+	// Wait for all producers to finish their in-flight pushes
+	s.logger.Info("draining in-flight publishes...", "drain_timeout", s.config.DrainTimeout)
+	s.drain(cancelPushes)
 
 	// Close all MQ clients
 	s.logger.Info("closing MQ clients...")
 	s.closeClients()
+	s.closeBackend()
 
 	s.logger.Info("producer server stopped")
 	return nil
 }
 
 // runProducer runs a single producer instance, generating data points at configured intervals.
-func (s *Server) runProducer(ctx context.Context, id int, producer *Producer) {
+func (s *Server) runProducer(ctx, pushCtx context.Context, id int, producer *Producer) {
 	defer s.wg.Done()
 
 	// Track active producer
@@ -208,43 +437,246 @@ func (s *Server) runProducer(ctx context.Context, id int, producer *Producer) {
 		defer s.metrics.ActiveProducers.Dec()
 	}
 
-	ticker := time.NewTicker(s.config.Interval)
-	defer ticker.Stop()
-
 	producerLogger := s.logger.With(slog.Int("producer_id", id))
+
+	if s.backend != nil && !s.registerDevices(ctx, producer, producerLogger) {
+		return
+	}
+
+	// Either the producer ticks and generates a reading of a random device,
+	// or every device ticks on its own; the other channel stays nil
+	var (
+		tick           <-chan time.Time
+		deviceReadings <-chan []*iotv1.SensorReading
+	)
+	if s.config.DeviceTickers {
+		var waitDevices func()
+		deviceReadings, waitDevices = s.startDevices(ctx, producer)
+		defer waitDevices()
+	} else {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	// With batching, readings are collected on every tick and published on
+	// every flush; without, flush stays nil and never fires
+	var (
+		flush   <-chan time.Time
+		pending []*iotv1.SensorReading
+	)
+	if s.config.BatchInterval > 0 {
+		flushTicker := time.NewTicker(s.config.BatchInterval)
+		defer flushTicker.Stop()
+		flush = flushTicker.C
+	}
+
 	producerLogger.Info("producer started")
 
 	for {
 		select {
 		case <-ctx.Done():
+			// Publish the collected readings; drain cancels pushCtx if this
+			// takes too long
+			if err := s.pushBatch(pushCtx, producer, pending); err != nil {
+				producerLogger.Error("failed to publish pending readings", "readings", len(pending), "error", err)
+			}
+
 			producerLogger.Info("producer shutting down")
 			return
 
-		case <-ticker.C:
-			if err := producer.RandomDataPoint(ctx); err != nil {
-				producerLogger.Error("failed to generate data point",
-					"error", err,
-				)
-				// Continue on error - don't stop the producer
+		case <-tick:
+			if flush != nil {
+				pending = append(pending, producer.GenerateReadings()...)
+				continue
+			}
+
+			s.handlePush(ctx, id, producer, producerLogger, s.push(pushCtx, producer, producer.GenerateReadings()))
+
+		case readings := <-deviceReadings:
+			if flush != nil {
+				pending = append(pending, readings...)
 				continue
 			}
 
-			producerLogger.Debug("data point generated and sent")
+			s.handlePush(ctx, id, producer, producerLogger, s.push(pushCtx, producer, readings))
+
+		case <-flush:
+			if len(pending) == 0 {
+				continue
+			}
+
+			// A failed batch is dropped like a failed single reading
+			err := s.pushBatch(pushCtx, producer, pending)
+			pending = nil
+			s.handlePush(ctx, id, producer, producerLogger, err)
+		}
+	}
+}
+
+// registerDevices registers the devices of producer with the backend, each
+// attempt bounded by the push timeout, and retries until it succeeds. It
+// returns false if ctx is canceled first.
+func (s *Server) registerDevices(ctx context.Context, producer *Producer, logger *slog.Logger) bool {
+	for {
+		attemptCtx, cancel := context.WithTimeout(ctx, s.config.PushTimeout)
+		err := producer.RegisterDevices(attemptCtx, s.backend)
+		cancel()
+		if err == nil {
+			logger.Info("devices registered with backend", "device_count", len(producer.IoTDevices))
+			return true
+		}
+
+		if ctx.Err() != nil {
+			return false
+		}
+		logger.Warn("failed to register devices, retrying", "retry_in", registrationRetryDelay, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(registrationRetryDelay):
+		}
+	}
+}
+
+// handlePush records the outcome of a push for the supervisor and restarts
+// the producer once it has gone without a successful push for too long.
+func (s *Server) handlePush(ctx context.Context, id int, producer *Producer, logger *slog.Logger, err error) {
+	if err == nil {
+		if s.supervisor.success(id, time.Now()) {
+			logger.Info("producer recovered")
 		}
+
+		logger.Debug("data point generated and sent")
+		return
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	logger.Error("failed to generate data point",
+		"error", err,
+	)
+
+	// Continue on error until the producer has gone without a
+	// successful push for too long, then restart it
+	backoff, unhealthy := s.supervisor.failure(id, time.Now())
+	if !unhealthy {
+		return
+	}
+
+	logger.Warn("producer unhealthy, restarting",
+		"unhealthy_after", s.config.UnhealthyAfter,
+		"backoff", backoff,
+	)
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(backoff):
 	}
+
+	s.restartProducer(id, producer, logger)
+}
+
+// drain waits for all producers to return. Pushes still in flight when the
+// drain timeout passes are canceled.
+func (s *Server) drain(cancelPushes context.CancelFunc) {
+	if s.config.DrainTimeout == 0 {
+		cancelPushes()
+		s.wg.Wait()
+		return
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(drained)
+	}()
+
+	timer := time.NewTimer(s.config.DrainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-drained:
+		return
+	case <-timer.C:
+	}
+
+	s.logger.Warn("drain timeout exceeded, canceling in-flight publishes")
+	cancelPushes()
+	<-drained
+}
+
+// push publishes the readings generated for one tick, a message each,
+// bounded by the push timeout.
+func (s *Server) push(ctx context.Context, producer *Producer, readings []*iotv1.SensorReading) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.PushTimeout)
+	defer cancel()
+
+	return producer.publishEach(ctx, readings)
+}
+
+// pushBatch publishes readings as one batch message, bounded by the push
+// timeout.
+func (s *Server) pushBatch(ctx context.Context, producer *Producer, readings []*iotv1.SensorReading) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.PushTimeout)
+	defer cancel()
+
+	return producer.PublishReadings(ctx, readings)
+}
+
+// restartProducer replaces the MQ clients of an unhealthy producer. If new
+// clients cannot be created, the old ones are kept and the restart is retried
+// after the next backoff.
+func (s *Server) restartProducer(id int, producer *Producer, logger *slog.Logger) {
+	client, deviceClient, err := s.newClients(id)
+	if err != nil {
+		logger.Error("failed to restart producer", "error", err)
+		return
+	}
+
+	producer.MQClient = client
+	producer.DeviceMQClient = deviceClient
+
+	s.clientsMu.Lock()
+	oldClient, oldDeviceClient := s.clients[id], s.deviceClients[id]
+	s.clients[id], s.deviceClients[id] = client, deviceClient
+	s.clientsMu.Unlock()
+
+	// The old clients may never have connected, so errors are expected
+	_ = oldClient.Close()
+	_ = oldDeviceClient.Close()
+
+	s.supervisor.restarted(id, time.Now())
+	logger.Info("producer restarted")
+}
+
+// ProducerStatuses returns the health of all producers.
+func (s *Server) ProducerStatuses() []ProducerStatus {
+	return s.supervisor.statuses()
 }
 
 // closeClients closes all MQ clients gracefully.
 func (s *Server) closeClients() {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+
 	var wg sync.WaitGroup
 
 	// Close sensor reading clients
 	for i, client := range s.clients {
 		wg.Add(1)
-		go func(id int, c *mq.Client) {
+		go func(id int, c mq.ClientInterface) {
 			defer wg.Done()
 
 			if err := c.Close(); err != nil {
+				if errors.Is(err, mq.ErrNeverConnected) {
+					s.logger.Warn("MQ client closed before connecting", "producer_id", id)
+					return
+				}
 				s.logger.Error("failed to close MQ client",
 					"producer_id", id,
 					"error", err,
@@ -259,10 +691,14 @@ func (s *Server) closeClients() {
 	// Close device clients
 	for i, deviceClient := range s.deviceClients {
 		wg.Add(1)
-		go func(id int, c *mq.Client) {
+		go func(id int, c mq.ClientInterface) {
 			defer wg.Done()
 
 			if err := c.Close(); err != nil {
+				if errors.Is(err, mq.ErrNeverConnected) {
+					s.logger.Warn("device MQ client closed before connecting", "producer_id", id)
+					return
+				}
 				s.logger.Error("failed to close device MQ client",
 					"producer_id", id,
 					"error", err,
@@ -277,13 +713,39 @@ func (s *Server) closeClients() {
 	wg.Wait()
 }
 
+// closeBackend closes the backend connection, if devices are registered
+// through the backend.
+func (s *Server) closeBackend() {
+	if s.backendConn == nil {
+		return
+	}
+
+	if err := s.backendConn.Close(); err != nil {
+		s.logger.Error("failed to close backend connection", "error", err)
+	}
+}
+
 // Shutdown initiates a graceful shutdown of the server.
-// This is an alternative to sending OS signals.
+// This is an alternative to sending OS signals or canceling the context
+// passed to Run. If Run is running, Shutdown stops it and waits until it has
+// drained the producers and closed the MQ clients; otherwise it closes the
+// MQ clients.
 func (s *Server) Shutdown() error {
 	s.logger.Info("shutdown requested")
 
-	// Close all MQ clients
-	s.closeClients()
+	s.runMu.Lock()
+	cancel, stopped := s.cancel, s.stopped
+	s.runMu.Unlock()
+
+	if cancel == nil {
+		// Close all MQ clients
+		s.closeClients()
+		s.closeBackend()
+		return nil
+	}
+
+	cancel()
+	<-stopped
 
 	return nil
 }
@@ -2,14 +2,28 @@ package producer_test
 
 import (
 	"context"
+	"io"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"slices"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"procodus.dev/demo-app/internal/producer"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/pkg/metrics"
+	"procodus.dev/demo-app/pkg/mq"
+	"procodus.dev/demo-app/pkg/mq/inmem"
 )
 
 var _ = Describe("Producer Server", func() {
@@ -167,6 +181,21 @@ var _ = Describe("Producer Server", func() {
 				Expect(err.Error()).To(ContainSubstring("logger"))
 				Expect(server).To(BeNil())
 			})
+
+			It("should return error for a malformed RabbitMQ URL", func() {
+				config := &producer.ServerConfig{
+					Logger:          logger,
+					RabbitMQURL:     "http://localhost:5672",
+					QueueName:       "test-queue",
+					DeviceQueueName: "device-queue",
+					ProducerCount:   2,
+					Interval:        5 * time.Second,
+				}
+
+				server, err := producer.NewServer(config)
+				Expect(err).To(MatchError(ContainSubstring("invalid AMQP URL")))
+				Expect(server).To(BeNil())
+			})
 		})
 
 		Context("with different configurations", func() {
@@ -409,6 +438,279 @@ var _ = Describe("Producer Server", func() {
 				Eventually(done, 3*time.Second).Should(Receive(BeNil()))
 			})
 		})
+
+		Context("with an in-process broker", func() {
+			It("should publish without RabbitMQ", func() {
+				broker := inmem.NewBroker(0)
+				config := &producer.ServerConfig{
+					Logger:          logger,
+					MQBroker:        broker,
+					QueueName:       "test-queue",
+					DeviceQueueName: "device-queue",
+					ProducerCount:   2,
+					Interval:        10 * time.Millisecond,
+				}
+
+				server, err := producer.NewServer(config)
+				Expect(err).NotTo(HaveOccurred())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				done := make(chan error, 1)
+				go func() {
+					done <- server.Run(ctx)
+				}()
+
+				Eventually(func() int { return broker.Len("device-queue") }, 2*time.Second).Should(BeNumerically(">=", 2))
+				Eventually(func() int { return broker.Len("test-queue") }, 2*time.Second).Should(BeNumerically(">", 0))
+
+				cancel()
+				Eventually(done, 2*time.Second).Should(Receive(BeNil()))
+			})
+
+			It("should reject unknown compression algorithms", func() {
+				server, err := producer.NewServer(&producer.ServerConfig{
+					Logger:          logger,
+					MQBroker:        inmem.NewBroker(0),
+					QueueName:       "test-queue",
+					DeviceQueueName: "device-queue",
+					ProducerCount:   1,
+					Interval:        time.Second,
+					Compression:     "brotli",
+				})
+				Expect(err).To(MatchError(ContainSubstring(`unknown compression "brotli"`)))
+				Expect(server).To(BeNil())
+			})
+		})
+
+		Context("with device registration through the backend", func() {
+			It("should register devices before publishing readings", func() {
+				lis, err := net.Listen("tcp", "127.0.0.1:0")
+				Expect(err).NotTo(HaveOccurred())
+				registry := &deviceRegistry{failures: 1}
+				grpcServer := grpc.NewServer()
+				iotv1.RegisterIoTServiceServer(grpcServer, registry)
+				go func() { _ = grpcServer.Serve(lis) }()
+				DeferCleanup(grpcServer.Stop)
+
+				broker := inmem.NewBroker(0)
+				server, err := producer.NewServer(&producer.ServerConfig{
+					Logger:          logger,
+					MQBroker:        broker,
+					QueueName:       "test-queue",
+					DeviceQueueName: "device-queue",
+					BackendGRPCAddr: lis.Addr().String(),
+					ProducerCount:   1,
+					Interval:        10 * time.Millisecond,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				done := make(chan error, 1)
+				go func() {
+					done <- server.Run(ctx)
+				}()
+
+				// The first attempt fails; no readings until the retry succeeds
+				Consistently(func() int { return broker.Len("test-queue") }, 500*time.Millisecond).Should(BeZero())
+				Eventually(registry.deviceIDs, 3*time.Second).ShouldNot(BeEmpty())
+				Eventually(func() int { return broker.Len("test-queue") }, 2*time.Second).Should(BeNumerically(">", 0))
+				Expect(broker.Len("device-queue")).To(BeZero())
+
+				cancel()
+				Eventually(done, 2*time.Second).Should(Receive(BeNil()))
+			})
+		})
+
+		Context("with batching", func() {
+			It("should publish readings in batch messages", func() {
+				broker := inmem.NewBroker(0)
+				server, err := producer.NewServer(&producer.ServerConfig{
+					Logger:          logger,
+					MQBroker:        broker,
+					QueueName:       "test-queue",
+					DeviceQueueName: "device-queue",
+					ProducerCount:   1,
+					Interval:        5 * time.Millisecond,
+					BatchInterval:   100 * time.Millisecond,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				done := make(chan error, 1)
+				go func() {
+					done <- server.Run(ctx)
+				}()
+
+				Eventually(func() int { return broker.Len("test-queue") }, 2*time.Second).Should(BeNumerically(">", 0))
+				cancel()
+				Eventually(done, 2*time.Second).Should(Receive(BeNil()))
+
+				client, err := broker.NewClient(context.Background(), mq.Options{Queues: []string{"test-queue"}})
+				Expect(err).NotTo(HaveOccurred())
+				defer client.Close()
+				deliveries, err := client.Consume()
+				Expect(err).NotTo(HaveOccurred())
+
+				var delivery amqp.Delivery
+				Eventually(deliveries).Should(Receive(&delivery))
+				Expect(delivery.Type).To(Equal(iotv1.MessageTypeSensorReadingBatch))
+
+				var batch iotv1.SensorReadingBatch
+				Expect(proto.Unmarshal(delivery.Body, &batch)).To(Succeed())
+				Expect(len(batch.GetReadings())).To(BeNumerically(">", 1))
+			})
+
+			It("should reject a negative batch interval", func() {
+				_, err := producer.NewServer(&producer.ServerConfig{
+					Logger:        logger,
+					MQBroker:      inmem.NewBroker(0),
+					ProducerCount: 1,
+					Interval:      time.Second,
+					BatchInterval: -time.Second,
+				})
+				Expect(err).To(MatchError("batch interval cannot be negative"))
+			})
+		})
+
+		Context("with metrics enabled", func() {
+			It("should serve producer metrics", func() {
+				config := &producer.ServerConfig{
+					Logger:          logger,
+					RabbitMQURL:     "amqp://invalid:5672",
+					QueueName:       "test-queue",
+					DeviceQueueName: "device-queue",
+					ProducerCount:   2,
+					Interval:        100 * time.Millisecond,
+					Metrics:         metrics.NewProducerMetrics("producer_server_test"),
+					MetricsPort:     9196,
+				}
+
+				server, err := producer.NewServer(config)
+				Expect(err).NotTo(HaveOccurred())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				done := make(chan error, 1)
+				go func() {
+					done <- server.Run(ctx)
+				}()
+
+				Eventually(func(g Gomega) {
+					resp, err := http.Get("http://localhost:9196/metrics")
+					g.Expect(err).NotTo(HaveOccurred())
+					defer func() { _ = resp.Body.Close() }()
+
+					body, err := io.ReadAll(resp.Body)
+					g.Expect(err).NotTo(HaveOccurred())
+					g.Expect(string(body)).To(ContainSubstring("producer_server_test_producer_active_producers 2"))
+				}, 2*time.Second).Should(Succeed())
+
+				cancel()
+				Eventually(done, 2*time.Second).Should(Receive(BeNil()))
+			})
+		})
+	})
+
+	Describe("Producer supervision", func() {
+		It("should reject negative supervision timeouts", func() {
+			_, err := producer.NewServer(&producer.ServerConfig{
+				Logger:         logger,
+				RabbitMQURL:    "amqp://invalid:5672",
+				ProducerCount:  1,
+				Interval:       time.Second,
+				UnhealthyAfter: -time.Second,
+			})
+			Expect(err).To(MatchError("unhealthy-after and push timeout cannot be negative"))
+		})
+
+		It("should report all producers healthy before they run", func() {
+			server, err := producer.NewServer(&producer.ServerConfig{
+				Logger:          logger,
+				RabbitMQURL:     "amqp://invalid:5672",
+				QueueName:       "test-queue",
+				DeviceQueueName: "device-queue",
+				ProducerCount:   2,
+				Interval:        time.Second,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = server.Shutdown() }()
+
+			statuses := server.ProducerStatuses()
+			Expect(statuses).To(HaveLen(2))
+			for _, status := range statuses {
+				Expect(status.Healthy).To(BeTrue())
+				Expect(status.LastSuccess).To(BeZero())
+				Expect(status.Restarts).To(BeZero())
+			}
+		})
+
+		It("should mark a failing producer unhealthy and restart it", func() {
+			server, err := producer.NewServer(&producer.ServerConfig{
+				Logger:          logger,
+				RabbitMQURL:     "amqp://invalid:5672",
+				QueueName:       "test-queue",
+				DeviceQueueName: "device-queue",
+				ProducerCount:   1,
+				Interval:        50 * time.Millisecond,
+				UnhealthyAfter:  200 * time.Millisecond,
+				PushTimeout:     50 * time.Millisecond,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- server.Run(ctx)
+			}()
+
+			Eventually(func(g Gomega) {
+				statuses := server.ProducerStatuses()
+				g.Expect(statuses).To(HaveLen(1))
+				g.Expect(statuses[0].Healthy).To(BeFalse())
+				g.Expect(statuses[0].Restarts).To(BeNumerically(">=", 1))
+			}, 5*time.Second, 50*time.Millisecond).Should(Succeed())
+
+			cancel()
+			Eventually(done, 5*time.Second).Should(Receive(BeNil()))
+		})
+	})
+
+	Describe("Shutdown drain", func() {
+		It("should reject a negative drain timeout", func() {
+			_, err := producer.NewServer(&producer.ServerConfig{
+				Logger:        logger,
+				RabbitMQURL:   "amqp://invalid:5672",
+				ProducerCount: 1,
+				Interval:      time.Second,
+				DrainTimeout:  -time.Second,
+			})
+			Expect(err).To(MatchError("drain timeout cannot be negative"))
+		})
+
+		It("should cancel in-flight publishes after the drain timeout", func() {
+			server, err := producer.NewServer(&producer.ServerConfig{
+				Logger:          logger,
+				RabbitMQURL:     "amqp://invalid:5672",
+				QueueName:       "test-queue",
+				DeviceQueueName: "device-queue",
+				ProducerCount:   2,
+				Interval:        10 * time.Millisecond,
+				PushTimeout:     time.Minute,
+				DrainTimeout:    100 * time.Millisecond,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- server.Run(ctx)
+			}()
+
+			// Let pushes start waiting for the unreachable broker
+			time.Sleep(200 * time.Millisecond)
+
+			cancel()
+			Eventually(done, time.Second).Should(Receive(BeNil()))
+		})
 	})
 
 	Describe("Server Shutdown", func() {
@@ -449,6 +751,65 @@ var _ = Describe("Producer Server", func() {
 			// Second shutdown should not panic and may return error
 			Expect(err2).To(Or(BeNil(), HaveOccurred()))
 		})
+
+		It("should stop a running server", func() {
+			config := &producer.ServerConfig{
+				Logger:          logger,
+				RabbitMQURL:     "amqp://invalid:5672",
+				QueueName:       "test-queue",
+				DeviceQueueName: "device-queue",
+				ProducerCount:   2,
+				Interval:        100 * time.Millisecond,
+			}
+
+			server, err := producer.NewServer(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			done := make(chan error, 1)
+			go func() {
+				done <- server.Run(context.Background())
+			}()
+
+			time.Sleep(200 * time.Millisecond)
+
+			shutdown := make(chan error, 1)
+			go func() {
+				shutdown <- server.Shutdown()
+			}()
+
+			Eventually(shutdown, 2*time.Second).Should(Receive(BeNil()))
+			Eventually(done, time.Second).Should(Receive(BeNil()))
+		})
+
+		It("should return after Run was stopped by its context", func() {
+			config := &producer.ServerConfig{
+				Logger:          logger,
+				RabbitMQURL:     "amqp://invalid:5672",
+				QueueName:       "test-queue",
+				DeviceQueueName: "device-queue",
+				ProducerCount:   1,
+				Interval:        100 * time.Millisecond,
+			}
+
+			server, err := producer.NewServer(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- server.Run(ctx)
+			}()
+
+			time.Sleep(200 * time.Millisecond)
+			cancel()
+			Eventually(done, 2*time.Second).Should(Receive(BeNil()))
+
+			shutdown := make(chan error, 1)
+			go func() {
+				shutdown <- server.Shutdown()
+			}()
+			Eventually(shutdown, time.Second).Should(Receive(BeNil()))
+		})
 	})
 
 	Describe("ServerConfig", func() {
@@ -511,3 +872,32 @@ var _ = Describe("Producer Server", func() {
 		})
 	})
 })
+
+// deviceRegistry is a backend recording the devices registered with it. It
+// fails the first failures calls.
+type deviceRegistry struct {
+	iotv1.UnimplementedIoTServiceServer
+
+	mu       sync.Mutex
+	failures int
+	devices  []string
+}
+
+func (r *deviceRegistry) CreateOrUpdateDevice(_ context.Context, req *iotv1.CreateOrUpdateDeviceRequest) (*iotv1.CreateOrUpdateDeviceResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failures > 0 {
+		r.failures--
+		return nil, status.Error(codes.Unavailable, "backend starting")
+	}
+
+	r.devices = append(r.devices, req.GetDevice().GetDeviceId())
+	return &iotv1.CreateOrUpdateDeviceResponse{Device: req.GetDevice(), Created: true}, nil
+}
+
+func (r *deviceRegistry) deviceIDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.devices)
+}
@@ -0,0 +1,62 @@
+package producer
+
+import (
+	"procodus.dev/demo-app/pkg/logger"
+	"procodus.dev/demo-app/pkg/mq"
+)
+
+// logStartup logs the startup event of the producer server. Producers
+// connect in the background, so the broker version is only known if one
+// connected already.
+func (s *Server) logStartup() {
+	brokerVersion := logger.Unknown
+	s.clientsMu.Lock()
+	for _, client := range s.clients {
+		if v, ok := client.(mq.ServerVersioner); ok && v.ServerVersion() != "" {
+			brokerVersion = v.ServerVersion()
+			break
+		}
+	}
+	s.clientsMu.Unlock()
+
+	logger.LogStartup(s.logger, logger.Startup{
+		Service:      "producer",
+		Config:       s.config.StartupConfig,
+		Dependencies: map[string]string{"message_queue": brokerVersion},
+		Features:     s.features(),
+		Attrs: []any{
+			"producer_count", len(s.producers),
+			"device_count", s.deviceCount(),
+			"interval", s.config.Interval,
+		},
+	})
+}
+
+// deviceCount returns the number of devices of all producers.
+func (s *Server) deviceCount() int {
+	count := 0
+	for _, producer := range s.producers {
+		count += len(producer.IoTDevices)
+	}
+	return count
+}
+
+// features returns the optional features the configuration enables.
+func (s *Server) features() map[string]bool {
+	c := s.config
+	return map[string]bool{
+		"durable_queues":      c.DurableQueues,
+		"priority_queues":     c.PriorityQueues,
+		"compression":         c.Compression != "",
+		"batching":            c.BatchInterval > 0,
+		"disorder":            c.Disorder != DisorderConfig{},
+		"device_skew":         c.DeviceSkew > 0,
+		"device_tickers":      c.DeviceTickers,
+		"fleet_file":          c.FleetFile != "",
+		"imperial_units":      c.ImperialUnits,
+		"metrics":             c.MetricsPort > 0 && c.Metrics != nil,
+		"pprof":               c.PprofPort > 0,
+		"fault_injection":     c.Faults != nil,
+		"device_registration": c.BackendGRPCAddr != "",
+	}
+}
@@ -0,0 +1,142 @@
+package producer
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+const (
+	// defaultUnhealthyAfter is how long a producer may go without a successful
+	// push before it is restarted when not configured.
+	defaultUnhealthyAfter = time.Minute
+	// defaultPushTimeout bounds a single push when not configured.
+	defaultPushTimeout = 10 * time.Second
+	// minRestartBackoff is the wait before the first restart of an unhealthy
+	// producer; it doubles with every restart that does not help.
+	minRestartBackoff = time.Second
+	// maxRestartBackoff caps the wait between restarts.
+	maxRestartBackoff = time.Minute
+)
+
+// ProducerStatus reports the health of one producer.
+type ProducerStatus struct {
+	ID          int
+	Healthy     bool
+	LastSuccess time.Time // Zero until the first successful push
+	Restarts    int
+}
+
+// producerHealth is the supervision state of one producer.
+type producerHealth struct {
+	lastSuccess time.Time
+	// since is the start of the current unhealthy window: the last success,
+	// restart or start of the producer
+	since    time.Time
+	healthy  bool
+	restarts int
+	backoff  time.Duration
+}
+
+// supervisor tracks the health of all producers of a server.
+type supervisor struct {
+	mu             sync.Mutex
+	producers      []producerHealth
+	unhealthyAfter time.Duration
+	metrics        *metrics.ProducerMetrics
+}
+
+// newSupervisor creates a supervisor for count producers started at now.
+func newSupervisor(count int, unhealthyAfter time.Duration, m *metrics.ProducerMetrics, now time.Time) *supervisor {
+	producers := make([]producerHealth, count)
+	for i := range producers {
+		producers[i] = producerHealth{since: now, healthy: true, backoff: minRestartBackoff}
+	}
+
+	return &supervisor{
+		producers:      producers,
+		unhealthyAfter: unhealthyAfter,
+		metrics:        m,
+	}
+}
+
+// success records a successful push. It reports whether the producer was
+// unhealthy before.
+func (s *supervisor) success(id int, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := &s.producers[id]
+	recovered := !p.healthy
+	p.lastSuccess = now
+	p.since = now
+	p.healthy = true
+	p.backoff = minRestartBackoff
+
+	if s.metrics != nil {
+		s.metrics.LastSuccess.WithLabelValues(strconv.Itoa(id)).Set(float64(now.Unix()))
+		if recovered {
+			s.metrics.UnhealthyProducers.Dec()
+		}
+	}
+
+	return recovered
+}
+
+// failure records a failed push. If the producer has gone without success for
+// too long, it is marked unhealthy and failure returns how long to wait before
+// restarting it; otherwise it returns false.
+func (s *supervisor) failure(id int, now time.Time) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := &s.producers[id]
+	if now.Sub(p.since) < s.unhealthyAfter {
+		return 0, false
+	}
+
+	if p.healthy {
+		p.healthy = false
+		if s.metrics != nil {
+			s.metrics.UnhealthyProducers.Inc()
+		}
+	}
+
+	backoff := p.backoff
+	p.backoff = min(2*p.backoff, maxRestartBackoff)
+
+	return backoff, true
+}
+
+// restarted records a restart, which starts a new unhealthy window.
+func (s *supervisor) restarted(id int, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := &s.producers[id]
+	p.since = now
+	p.restarts++
+
+	if s.metrics != nil {
+		s.metrics.ProducerRestarts.Inc()
+	}
+}
+
+// statuses returns the health of all producers.
+func (s *supervisor) statuses() []ProducerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]ProducerStatus, len(s.producers))
+	for i, p := range s.producers {
+		statuses[i] = ProducerStatus{
+			ID:          i,
+			Healthy:     p.healthy,
+			LastSuccess: p.lastSuccess,
+			Restarts:    p.restarts,
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,46 @@
+package producer
+
+import (
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// SetImperialUnits makes the producer publish temperatures in degrees
+// Fahrenheit and pressures in inches of mercury, as some real devices do. The
+// units are declared by message headers, so the backend converts readings
+// back before saving them.
+// This should be called before publishing readings.
+func (p *Producer) SetImperialUnits(imperial bool) {
+	p.imperial = imperial
+}
+
+// unitHeaders returns the headers declaring the units of published readings,
+// or nil for the canonical units.
+func (p *Producer) unitHeaders() amqp.Table {
+	if !p.imperial {
+		return nil
+	}
+
+	return amqp.Table{
+		iotv1.HeaderTemperatureUnit: iotv1.TemperatureUnitFahrenheit,
+		iotv1.HeaderPressureUnit:    iotv1.PressureUnitInchesOfMercury,
+	}
+}
+
+// inPublishedUnits returns reading in the units the producer publishes in.
+// The reading itself is left unchanged, since it may be published again.
+func (p *Producer) inPublishedUnits(reading *iotv1.SensorReading) *iotv1.SensorReading {
+	if !p.imperial {
+		return reading
+	}
+
+	return &iotv1.SensorReading{
+		DeviceId:     reading.GetDeviceId(),
+		Timestamp:    reading.GetTimestamp(),
+		Temperature:  iotv1.CelsiusToFahrenheit(reading.GetTemperature()),
+		Humidity:     reading.GetHumidity(),
+		Pressure:     iotv1.HectopascalsToInchesOfMercury(reading.GetPressure()),
+		BatteryLevel: reading.GetBatteryLevel(),
+	}
+}
@@ -0,0 +1,198 @@
+// Package faults injects failures into the services to exercise their
+// resilience paths, such as MQ reconnects, push retries and gRPC client
+// retries, in end-to-end tests. It is opt-in: nothing is injected unless the
+// DEMO_APP_FAULTS environment variable is set. Never enable it in production.
+package faults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// EnvVar is the environment variable holding the fault configuration, a
+// comma-separated list of key=value pairs, e.g.
+//
+//	DEMO_APP_FAULTS="mq_drop_rate=0.05,db_delay=500ms,db_delay_rate=0.5,grpc_unavailable_rate=0.1"
+const EnvVar = "DEMO_APP_FAULTS"
+
+// Config selects the faults to inject. Rates are probabilities between 0 and 1.
+type Config struct {
+	// MQDropRate closes the MQ connection before a publish at this rate,
+	// which makes the client reconnect and the publish retry.
+	MQDropRate float64
+	// DBDelay delays database writes (create, update, delete) at DBDelayRate.
+	DBDelay     time.Duration
+	DBDelayRate float64
+	// GRPCUnavailableRate fails unary gRPC calls with UNAVAILABLE at this rate.
+	GRPCUnavailableRate float64
+	// Seed makes the injected faults reproducible (optional, 0 = random).
+	Seed uint64
+}
+
+// Injector decides which operations fail. A nil Injector injects nothing, so
+// callers can use the result of FromEnv without checking it.
+type Injector struct {
+	cfg Config
+
+	mu  sync.Mutex // Guards rnd
+	rnd *rand.Rand
+}
+
+// New creates an Injector for cfg.
+func New(cfg Config) (*Injector, error) {
+	for _, rate := range []float64{cfg.MQDropRate, cfg.DBDelayRate, cfg.GRPCUnavailableRate} {
+		if rate < 0 || rate > 1 {
+			return nil, errors.New("fault rates must be between 0 and 1")
+		}
+	}
+
+	if cfg.DBDelay < 0 {
+		return nil, errors.New("database delay cannot be negative")
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+
+	return &Injector{
+		cfg: cfg,
+		rnd: rand.New(rand.NewPCG(seed, seed)), // #nosec G404 - faults need no secure randomness
+	}, nil
+}
+
+// FromEnv creates an Injector from the EnvVar environment variable. It
+// returns nil if the variable is not set.
+func FromEnv() (*Injector, error) {
+	value, ok := os.LookupEnv(EnvVar)
+	if !ok || value == "" {
+		return nil, nil
+	}
+
+	cfg, err := Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", EnvVar, err)
+	}
+
+	return New(cfg)
+}
+
+// Parse parses a fault configuration in the format of EnvVar.
+func Parse(value string) (Config, error) {
+	var cfg Config
+
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			return Config{}, fmt.Errorf("expected key=value, got %q", pair)
+		}
+
+		var err error
+		switch key {
+		case "mq_drop_rate":
+			cfg.MQDropRate, err = strconv.ParseFloat(val, 64)
+		case "db_delay":
+			cfg.DBDelay, err = time.ParseDuration(val)
+		case "db_delay_rate":
+			cfg.DBDelayRate, err = strconv.ParseFloat(val, 64)
+		case "grpc_unavailable_rate":
+			cfg.GRPCUnavailableRate, err = strconv.ParseFloat(val, 64)
+		case "seed":
+			cfg.Seed, err = strconv.ParseUint(val, 10, 64)
+		default:
+			return Config{}, fmt.Errorf("unknown fault %q", key)
+		}
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Config returns the injected faults.
+func (i *Injector) Config() Config {
+	if i == nil {
+		return Config{}
+	}
+	return i.cfg
+}
+
+// hit reports whether a fault with the given rate occurs.
+func (i *Injector) hit(rate float64) bool {
+	if i == nil || rate <= 0 {
+		return false
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.rnd.Float64() < rate
+}
+
+// DropConnection reports whether the MQ connection should be dropped before
+// the next publish. It implements mq.FaultInjector.
+func (i *Injector) DropConnection() bool {
+	return i.hit(i.Config().MQDropRate)
+}
+
+// RegisterDB delays database writes of db at the configured rate. It does
+// nothing if no database delay is configured.
+func (i *Injector) RegisterDB(db *gorm.DB) error {
+	if i == nil || i.cfg.DBDelay == 0 || i.cfg.DBDelayRate == 0 {
+		return nil
+	}
+
+	delay := func(tx *gorm.DB) {
+		if !i.hit(i.cfg.DBDelayRate) {
+			return
+		}
+
+		ctx := tx.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(i.cfg.DBDelay):
+		}
+	}
+
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("faults:delay_create", delay); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("faults:delay_update", delay); err != nil {
+		return err
+	}
+	return cb.Delete().Before("gorm:delete").Register("faults:delay_delete", delay)
+}
+
+// UnaryServerInterceptor fails unary gRPC calls with UNAVAILABLE at the
+// configured rate.
+func (i *Injector) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if i.hit(i.Config().GRPCUnavailableRate) {
+			return nil, status.Error(codes.Unavailable, "injected fault")
+		}
+		return handler(ctx, req)
+	}
+}
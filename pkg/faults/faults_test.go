@@ -0,0 +1,145 @@
+package faults_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"procodus.dev/demo-app/pkg/faults"
+)
+
+// record is a table written in the database delay tests.
+type record struct {
+	ID   uint
+	Name string
+}
+
+var _ = Describe("Faults", func() {
+	Describe("Parse", func() {
+		It("should parse all faults", func() {
+			cfg, err := faults.Parse("mq_drop_rate=0.05, db_delay=500ms,db_delay_rate=0.5,grpc_unavailable_rate=1,seed=42")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg).To(Equal(faults.Config{
+				MQDropRate:          0.05,
+				DBDelay:             500 * time.Millisecond,
+				DBDelayRate:         0.5,
+				GRPCUnavailableRate: 1,
+				Seed:                42,
+			}))
+		})
+
+		It("should reject malformed values", func() {
+			_, err := faults.Parse("mq_drop_rate")
+			Expect(err).To(MatchError(ContainSubstring("expected key=value")))
+
+			_, err = faults.Parse("db_delay=soon")
+			Expect(err).To(MatchError(ContainSubstring("invalid db_delay")))
+
+			_, err = faults.Parse("disk_full=1")
+			Expect(err).To(MatchError(`unknown fault "disk_full"`))
+		})
+	})
+
+	Describe("New", func() {
+		It("should reject rates outside 0 to 1", func() {
+			_, err := faults.New(faults.Config{MQDropRate: 1.5})
+			Expect(err).To(MatchError("fault rates must be between 0 and 1"))
+		})
+
+		It("should reject a negative database delay", func() {
+			_, err := faults.New(faults.Config{DBDelay: -time.Second})
+			Expect(err).To(MatchError("database delay cannot be negative"))
+		})
+	})
+
+	Describe("FromEnv", func() {
+		It("should return nil when the variable is not set", func() {
+			GinkgoT().Setenv(faults.EnvVar, "")
+
+			injector, err := faults.FromEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(injector).To(BeNil())
+		})
+
+		It("should create an injector from the variable", func() {
+			GinkgoT().Setenv(faults.EnvVar, "mq_drop_rate=1")
+
+			injector, err := faults.FromEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(injector.DropConnection()).To(BeTrue())
+		})
+
+		It("should name the variable in errors", func() {
+			GinkgoT().Setenv(faults.EnvVar, "mq_drop_rate=lots")
+
+			_, err := faults.FromEnv()
+			Expect(err).To(MatchError(ContainSubstring(faults.EnvVar)))
+		})
+	})
+
+	Describe("Injector", func() {
+		It("should inject nothing when nil", func() {
+			var injector *faults.Injector
+
+			Expect(injector.DropConnection()).To(BeFalse())
+			Expect(injector.RegisterDB(nil)).To(Succeed())
+
+			resp, err := injector.UnaryServerInterceptor()(context.Background(), "req", nil, func(context.Context, any) (any, error) {
+				return "resp", nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp).To(Equal("resp"))
+		})
+
+		It("should inject faults at roughly the configured rate", func() {
+			injector, err := faults.New(faults.Config{MQDropRate: 0.3, Seed: 1})
+			Expect(err).NotTo(HaveOccurred())
+
+			drops := 0
+			for range 1000 {
+				if injector.DropConnection() {
+					drops++
+				}
+			}
+			Expect(drops).To(BeNumerically("~", 300, 60))
+		})
+
+		It("should fail gRPC calls with UNAVAILABLE", func() {
+			injector, err := faults.New(faults.Config{GRPCUnavailableRate: 1})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = injector.UnaryServerInterceptor()(context.Background(), "req", nil, func(context.Context, any) (any, error) {
+				Fail("handler must not be called")
+				return nil, nil
+			})
+			Expect(status.Code(err)).To(Equal(codes.Unavailable))
+		})
+
+		It("should delay database writes", func() {
+			db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(db.AutoMigrate(&record{})).To(Succeed())
+
+			injector, err := faults.New(faults.Config{DBDelay: 100 * time.Millisecond, DBDelayRate: 1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(injector.RegisterDB(db)).To(Succeed())
+
+			start := time.Now()
+			Expect(db.Create(&record{Name: "delayed"}).Error).To(Succeed())
+			Expect(time.Since(start)).To(BeNumerically(">=", 100*time.Millisecond))
+
+			// Reads are not delayed
+			start = time.Now()
+			var count int64
+			Expect(db.Model(&record{}).Count(&count).Error).To(Succeed())
+			Expect(time.Since(start)).To(BeNumerically("<", 100*time.Millisecond))
+		})
+	})
+})
@@ -1,6 +1,10 @@
 // Package generator provides IoT device simulation and sensor data generation.
 // It includes functionality for creating synthetic IoT devices with realistic
 // sensor readings that follow environmental patterns and correlations.
+//
+// The package does not depend on a message queue, so load tools can embed
+// it: NewFleet simulates a set of devices and Fleet.Stream delivers their
+// readings on a channel.
 // Note: Uses math/rand for data generation which is acceptable for simulation purposes.
 package generator
 
@@ -11,19 +15,19 @@ import (
 
 	"github.com/brianvoe/gofakeit/v7"
 
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
 )
 
 // IoTDevice represents a simulated IoT device with metadata.
 type IoTDevice struct {
-	Timestamp  time.Time
-	DeviceID   string  `fake:"{uuid}"`
-	Location   string  `fake:"{city}, {state}"`
-	MacAddress string  `fake:"{macaddress}"`
-	IPAddress  string  `fake:"{ipv4address}"`
-	Firmware   string  `fake:"{appversion}"`
-	Latitude   float64 `fake:"{latitude}"`
-	Longitude  float64 `fake:"{longitude}"`
+	Timestamp  time.Time `json:"timestamp" yaml:"timestamp"`
+	DeviceID   string    `json:"device_id" yaml:"device_id" fake:"{uuid}"`
+	Location   string    `json:"location" yaml:"location" fake:"{city}, {state}"`
+	MacAddress string    `json:"mac_address" yaml:"mac_address" fake:"{macaddress}"`
+	IPAddress  string    `json:"ip_address" yaml:"ip_address" fake:"{ipv4address}"`
+	Firmware   string    `json:"firmware" yaml:"firmware" fake:"{appversion}"`
+	Latitude   float64   `json:"latitude" yaml:"latitude" fake:"{latitude}"`
+	Longitude  float64   `json:"longitude" yaml:"longitude" fake:"{longitude}"`
 }
 
 // IoTDataGenerator generates realistic sensor readings with environmental correlations.
@@ -157,7 +161,7 @@ func (g *IoTDataGenerator) GeneratePressure(t time.Time) float64 {
 }
 
 // GenerateCorrelatedReading - generates readings with realistic correlations.
-func (g *IoTDataGenerator) GenerateCorrelatedReading(t time.Time) *iot.SensorReading {
+func (g *IoTDataGenerator) GenerateCorrelatedReading(t time.Time) *iotv1.SensorReading {
 	// Generate temperature first
 	temperature := g.GenerateTemperature(t)
 
@@ -173,7 +177,7 @@ func (g *IoTDataGenerator) GenerateCorrelatedReading(t time.Time) *iot.SensorRea
 	battery := 100 - batteryDrain - rand.Float64()*2            // Add small random variation
 	battery = math.Max(5, math.Min(100, battery))
 
-	return &iot.SensorReading{
+	return &iotv1.SensorReading{
 		DeviceId:     g.deviceID,
 		Timestamp:    t.Unix(),
 		Temperature:  math.Round(temperature*100) / 100, // 2 decimal places
@@ -0,0 +1,25 @@
+package generator_test
+
+import (
+	"testing"
+	"time"
+
+	"procodus.dev/demo-app/pkg/generator"
+)
+
+func BenchmarkGenerateCorrelatedReading(b *testing.B) {
+	g := generator.NewIoTGenerator("bench-device")
+	t := time.Now()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		g.GenerateCorrelatedReading(t)
+	}
+}
+
+func BenchmarkNewIoTDevice(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		generator.NewIoTDevice()
+	}
+}
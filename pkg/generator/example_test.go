@@ -0,0 +1,29 @@
+package generator_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"procodus.dev/demo-app/pkg/generator"
+)
+
+func ExampleFleet_Stream() {
+	// Simulate 100 devices reporting about every 5 seconds.
+	fleet, err := generator.NewFleet(generator.FleetOptions{
+		DeviceCount: 100,
+		Interval:    5 * time.Second,
+		Jitter:      0.1,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// Send the readings wherever the load test needs them.
+	for reading := range fleet.Stream(ctx) {
+		fmt.Println(reading.GetDeviceId(), reading.GetTemperature())
+	}
+}
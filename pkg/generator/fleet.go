@@ -0,0 +1,150 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// defaultFleetSize is the number of devices NewFleet generates when
+// FleetOptions sets neither Devices nor DeviceCount.
+const defaultFleetSize = 10
+
+var (
+	errNegativeDeviceCount = errors.New("device count cannot be negative")
+	errFleetInterval       = errors.New("interval must be greater than 0")
+	errFleetJitter         = errors.New("jitter must be between 0 and 1")
+	errNegativeBuffer      = errors.New("buffer cannot be negative")
+)
+
+// FleetOptions configures a Fleet.
+type FleetOptions struct {
+	// Devices are the devices of the fleet, e.g. loaded with LoadFleet
+	// (optional, nil = generate DeviceCount devices)
+	Devices []*IoTDevice
+	// DeviceCount is the number of devices to generate when Devices is empty
+	// (optional, default 10)
+	DeviceCount int
+	// Interval is the time between two readings of a device
+	Interval time.Duration
+	// Jitter varies each interval by up to this fraction in either direction,
+	// for irregular arrivals like those of real devices (optional, 0 = exact)
+	Jitter float64
+	// Buffer is the capacity of the channel returned by Stream (optional,
+	// 0 = unbuffered)
+	Buffer int
+}
+
+// Fleet simulates a set of IoT devices, each reporting sensor readings
+// periodically. It is independent of any transport: Stream delivers the
+// readings on a channel, and what happens to them is up to the caller.
+type Fleet struct {
+	devices []*IoTDevice
+	opts    FleetOptions
+}
+
+// NewFleet creates a fleet of the given or newly generated devices.
+func NewFleet(opts FleetOptions) (*Fleet, error) {
+	if opts.DeviceCount < 0 {
+		return nil, errNegativeDeviceCount
+	}
+
+	if opts.Interval <= 0 {
+		return nil, errFleetInterval
+	}
+
+	if opts.Jitter < 0 || opts.Jitter > 1 {
+		return nil, errFleetJitter
+	}
+
+	if opts.Buffer < 0 {
+		return nil, errNegativeBuffer
+	}
+
+	devices := opts.Devices
+	if len(devices) == 0 {
+		count := opts.DeviceCount
+		if count == 0 {
+			count = defaultFleetSize
+		}
+
+		devices = make([]*IoTDevice, 0, count)
+		for range count {
+			devices = append(devices, NewIoTDevice())
+		}
+	}
+
+	return &Fleet{devices: devices, opts: opts}, nil
+}
+
+// Devices returns the devices of the fleet, e.g. to register them or save
+// them with SaveFleet. They must not be modified.
+func (f *Fleet) Devices() []*IoTDevice {
+	return f.devices
+}
+
+// Stream starts the devices and returns the channel their readings are sent
+// to. Every device runs in a goroutine of its own and reports every interval,
+// starting at a random offset within the first one, with readings correlated
+// over time by an IoTDataGenerator. A slow receiver delays the devices
+// rather than losing readings. The channel is closed once ctx is canceled
+// and all devices have stopped.
+func (f *Fleet) Stream(ctx context.Context) <-chan *iotv1.SensorReading {
+	readings := make(chan *iotv1.SensorReading, f.opts.Buffer)
+
+	var wg sync.WaitGroup
+	for _, device := range f.devices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.run(ctx, device, readings)
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(readings)
+	}()
+
+	return readings
+}
+
+// run sends the readings of device until ctx is canceled.
+// Note: Uses math/rand which is acceptable for simulation data.
+func (f *Fleet) run(ctx context.Context, device *IoTDevice, readings chan<- *iotv1.SensorReading) {
+	gen := NewIoTGenerator(device.DeviceID)
+
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(f.opts.Interval)))) // #nosec G404 - weak random is acceptable for simulation
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-timer.C:
+			select {
+			case <-ctx.Done():
+				return
+			case readings <- gen.GenerateCorrelatedReading(now):
+			}
+		}
+
+		timer.Reset(JitteredInterval(f.opts.Interval, f.opts.Jitter))
+	}
+}
+
+// JitteredInterval returns interval shifted by a random fraction of up to
+// jitter in either direction, but at least a millisecond.
+// Note: Uses math/rand which is acceptable for simulation data.
+func JitteredInterval(interval time.Duration, jitter float64) time.Duration {
+	if jitter == 0 {
+		return interval
+	}
+
+	shift := (2*rand.Float64() - 1) * jitter // #nosec G404 - weak random is acceptable for simulation
+	return max(time.Duration(float64(interval)*(1+shift)), time.Millisecond)
+}
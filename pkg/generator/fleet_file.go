@@ -0,0 +1,96 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ErrEmptyFleet is returned by LoadFleet for a fleet file without devices.
+var ErrEmptyFleet = errors.New("fleet file has no devices")
+
+// fleetFile is the file format of a saved device fleet.
+type fleetFile struct {
+	Devices []*IoTDevice `json:"devices" yaml:"devices"`
+}
+
+// LoadFleet reads the devices saved to path by SaveFleet. The format is JSON
+// or YAML, chosen by the extension .json, .yaml or .yml.
+func LoadFleet(path string) ([]*IoTDevice, error) {
+	unmarshal, _, err := fleetFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 - the path is configured by the operator
+	if err != nil {
+		return nil, err
+	}
+
+	var fleet fleetFile
+	if err := unmarshal(data, &fleet); err != nil {
+		return nil, fmt.Errorf("failed to parse fleet file %s: %w", path, err)
+	}
+
+	if len(fleet.Devices) == 0 {
+		return nil, ErrEmptyFleet
+	}
+
+	for i, device := range fleet.Devices {
+		if device == nil || device.DeviceID == "" {
+			return nil, fmt.Errorf("fleet file %s: device %d has no device_id", path, i)
+		}
+	}
+
+	return fleet.Devices, nil
+}
+
+// SaveFleet writes devices to path as JSON or YAML, chosen by the extension
+// .json, .yaml or .yml. The file is replaced atomically.
+func SaveFleet(path string, devices []*IoTDevice) error {
+	_, marshal, err := fleetFormat(path)
+	if err != nil {
+		return err
+	}
+
+	data, err := marshal(fleetFile{Devices: devices})
+	if err != nil {
+		return fmt.Errorf("failed to encode fleet: %w", err)
+	}
+
+	// Written next to the target and renamed, so a crash cannot leave a
+	// truncated fleet behind
+	f, err := os.CreateTemp(filepath.Dir(path), ".fleet-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// fleetFormat returns the decoding and encoding functions for the format of
+// a fleet file.
+func fleetFormat(path string) (func([]byte, any) error, func(any) ([]byte, error), error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return json.Unmarshal, func(v any) ([]byte, error) { return json.MarshalIndent(v, "", "  ") }, nil
+	case ".yaml", ".yml":
+		return yaml.Unmarshal, yaml.Marshal, nil
+	default:
+		return nil, nil, fmt.Errorf("fleet file %s: unknown format, use .json, .yaml or .yml", path)
+	}
+}
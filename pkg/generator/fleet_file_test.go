@@ -0,0 +1,78 @@
+package generator_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"procodus.dev/demo-app/pkg/generator"
+)
+
+var _ = Describe("Fleet file", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	DescribeTable("should load the saved devices",
+		func(name string) {
+			devices := []*generator.IoTDevice{generator.NewIoTDevice(), generator.NewIoTDevice()}
+			path := filepath.Join(dir, name)
+
+			Expect(generator.SaveFleet(path, devices)).To(Succeed())
+			loaded, err := generator.LoadFleet(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(loaded).To(HaveLen(2))
+			for i, device := range loaded {
+				Expect(device.DeviceID).To(Equal(devices[i].DeviceID))
+				Expect(device.Location).To(Equal(devices[i].Location))
+				Expect(device.Latitude).To(Equal(devices[i].Latitude))
+				Expect(device.Timestamp).To(BeTemporally("==", devices[i].Timestamp))
+			}
+		},
+		Entry("JSON", "fleet.json"),
+		Entry("YAML", "fleet.yaml"),
+		Entry("YML", "fleet.yml"),
+	)
+
+	It("should read hand-written files", func() {
+		path := filepath.Join(dir, "fleet.yaml")
+		Expect(os.WriteFile(path, []byte("devices:\n  - device_id: greenhouse-1\n    location: Greenhouse\n    latitude: 52.5\n"), 0o600)).To(Succeed())
+
+		devices, err := generator.LoadFleet(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(devices).To(HaveLen(1))
+		Expect(devices[0].DeviceID).To(Equal("greenhouse-1"))
+		Expect(devices[0].Latitude).To(Equal(52.5))
+	})
+
+	It("should reject unknown formats", func() {
+		err := generator.SaveFleet(filepath.Join(dir, "fleet.toml"), nil)
+		Expect(err).To(MatchError(ContainSubstring("unknown format")))
+	})
+
+	It("should reject devices without ID", func() {
+		path := filepath.Join(dir, "fleet.json")
+		Expect(os.WriteFile(path, []byte(`{"devices": [{"location": "Lab"}]}`), 0o600)).To(Succeed())
+
+		_, err := generator.LoadFleet(path)
+		Expect(err).To(MatchError(ContainSubstring("device 0 has no device_id")))
+	})
+
+	It("should reject empty fleets", func() {
+		path := filepath.Join(dir, "fleet.json")
+		Expect(os.WriteFile(path, []byte(`{"devices": []}`), 0o600)).To(Succeed())
+
+		_, err := generator.LoadFleet(path)
+		Expect(err).To(MatchError(generator.ErrEmptyFleet))
+	})
+
+	It("should report missing files", func() {
+		_, err := generator.LoadFleet(filepath.Join(dir, "fleet.json"))
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+})
@@ -0,0 +1,64 @@
+package generator_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"procodus.dev/demo-app/pkg/generator"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+var _ = Describe("Fleet", func() {
+	It("should generate ten devices by default", func() {
+		fleet, err := generator.NewFleet(generator.FleetOptions{Interval: time.Second})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fleet.Devices()).To(HaveLen(10))
+	})
+
+	It("should use the given devices", func() {
+		devices := []*generator.IoTDevice{generator.NewIoTDevice()}
+		fleet, err := generator.NewFleet(generator.FleetOptions{Devices: devices, DeviceCount: 5, Interval: time.Second})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fleet.Devices()).To(Equal(devices))
+	})
+
+	It("should stream readings of every device until canceled", func() {
+		fleet, err := generator.NewFleet(generator.FleetOptions{DeviceCount: 20, Interval: 20 * time.Millisecond, Jitter: 0.5})
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		readings := fleet.Stream(ctx)
+
+		perDevice := map[string]int{}
+		for len(perDevice) < 20 || perDevice[fleet.Devices()[0].DeviceID] < 3 {
+			var reading *iotv1.SensorReading
+			Eventually(readings).Should(Receive(&reading))
+			Expect(reading.GetTimestamp()).To(BeNumerically("~", time.Now().Unix(), 1))
+			perDevice[reading.GetDeviceId()]++
+		}
+
+		cancel()
+		Eventually(readings).Should(BeClosed())
+	})
+
+	DescribeTable("should reject invalid options",
+		func(opts generator.FleetOptions, message string) {
+			_, err := generator.NewFleet(opts)
+			Expect(err).To(MatchError(message))
+		},
+		Entry("no interval", generator.FleetOptions{}, "interval must be greater than 0"),
+		Entry("negative device count", generator.FleetOptions{Interval: time.Second, DeviceCount: -1}, "device count cannot be negative"),
+		Entry("jitter above 1", generator.FleetOptions{Interval: time.Second, Jitter: 2}, "jitter must be between 0 and 1"),
+		Entry("negative buffer", generator.FleetOptions{Interval: time.Second, Buffer: -1}, "buffer cannot be negative"),
+	)
+
+	It("should keep jittered intervals within the jitter", func() {
+		for range 100 {
+			Expect(generator.JitteredInterval(time.Second, 0.2)).To(BeNumerically("~", time.Second, 200*time.Millisecond))
+		}
+		Expect(generator.JitteredInterval(time.Second, 0)).To(Equal(time.Second))
+	})
+})
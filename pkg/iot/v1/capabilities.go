@@ -0,0 +1,35 @@
+package iotv1
+
+// APIVersion is the version of the protobuf package, reported by GetServerInfo.
+const APIVersion = "v1"
+
+// Capabilities reported by GetServerInfo. Each names a group of optional RPCs;
+// the device and reading RPCs are always available. Clients check them so they
+// keep working against backends of other releases during a rollout.
+const (
+	CapabilityReadingSeries      = "reading_series"      // GetSensorReadingSeriesBatch
+	CapabilityAlertRules         = "alert_rules"         // Alert rule RPCs
+	CapabilityDeviceNotes        = "device_notes"        // Device note RPCs
+	CapabilityDeviceTrash        = "device_trash"        // DeleteDevice, RestoreDevice and ListDeletedDevices
+	CapabilityQuotaUsage         = "quota_usage"         // GetQuotaUsage
+	CapabilityBatteryReport      = "battery_report"      // ListLowBatteryDevices
+	CapabilityReportSchedules    = "report_schedules"    // Report schedule RPCs
+	CapabilityLocationHistory    = "location_history"    // GetDeviceLocationHistory
+	CapabilityReadingsHeatmap    = "readings_heatmap"    // GetReadingsHeatmap
+	CapabilityStatusSummary      = "status_summary"      // GetStatusSummary
+	CapabilityBucketExport       = "bucket_export"       // ExportReadings
+	CapabilityDeviceRegistration = "device_registration" // CreateOrUpdateDevice
+	CapabilityDeviceHistory      = "device_history"      // GetDeviceHistory
+)
+
+// BaselineCapabilities are the capabilities of backends released before
+// GetServerInfo. Clients assume them when GetServerInfo is unimplemented.
+var BaselineCapabilities = []string{
+	CapabilityReadingSeries,
+	CapabilityAlertRules,
+	CapabilityDeviceNotes,
+	CapabilityDeviceTrash,
+	CapabilityQuotaUsage,
+	CapabilityBatteryReport,
+	CapabilityReportSchedules,
+}
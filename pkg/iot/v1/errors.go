@@ -0,0 +1,171 @@
+package iotv1
+
+import (
+	"fmt"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// ErrorDomain is the google.rpc.ErrorInfo domain used by IoTService errors.
+const ErrorDomain = "iot.procodus.dev"
+
+// Machine-readable error reasons carried in google.rpc.ErrorInfo.
+// Clients should switch on these instead of parsing status messages.
+const (
+	ReasonInvalidArgument        = "INVALID_ARGUMENT"
+	ReasonDeviceNotFound         = "DEVICE_NOT_FOUND"
+	ReasonAlertRuleNotFound      = "ALERT_RULE_NOT_FOUND"
+	ReasonDeviceNoteNotFound     = "DEVICE_NOTE_NOT_FOUND"
+	ReasonReportScheduleNotFound = "REPORT_SCHEDULE_NOT_FOUND"
+	ReasonInvalidPageToken       = "INVALID_PAGE_TOKEN"
+	ReasonDatabaseError          = "DATABASE_ERROR"
+	ReasonQuotaExceeded          = "QUOTA_EXCEEDED"
+	ReasonExportNotConfigured    = "EXPORT_NOT_CONFIGURED"
+	ReasonExportFailed           = "EXPORT_FAILED"
+	ReasonInternal               = "INTERNAL"
+	ReasonDeadlineExceeded       = "DEADLINE_EXCEEDED"
+	ReasonStaleDeviceUpdate      = "STALE_DEVICE_UPDATE"
+	ReasonDeviceUpdateConflict   = "DEVICE_UPDATE_CONFLICT"
+)
+
+// FieldViolation describes a single invalid request field.
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// NewError builds a gRPC status error carrying an ErrorInfo detail with the
+// given reason and metadata, plus a BadRequest detail for any field violations.
+func NewError(code codes.Code, reason, msg string, metadata map[string]string, violations ...FieldViolation) error {
+	st := status.New(code, msg)
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason:   reason,
+			Domain:   ErrorDomain,
+			Metadata: metadata,
+		},
+	}
+
+	if len(violations) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, v := range violations {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+		details = append(details, br)
+	}
+
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		// Attaching details only fails on marshalling errors; fall back to the bare status.
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
+
+// InvalidArgumentError returns an InvalidArgument error for a single bad field.
+func InvalidArgumentError(reason, field, description string) error {
+	return NewError(codes.InvalidArgument, reason,
+		fmt.Sprintf("%s: %s", field, description),
+		map[string]string{"field": field},
+		FieldViolation{Field: field, Description: description},
+	)
+}
+
+// DeviceNotFoundError returns a NotFound error for the given device ID.
+func DeviceNotFoundError(deviceID string) error {
+	return NewError(codes.NotFound, ReasonDeviceNotFound,
+		"device not found: "+deviceID,
+		map[string]string{"device_id": deviceID},
+	)
+}
+
+// AlertRuleNotFoundError returns a NotFound error for the given alert rule ID.
+func AlertRuleNotFoundError(id uint64) error {
+	return NewError(codes.NotFound, ReasonAlertRuleNotFound,
+		fmt.Sprintf("alert rule not found: %d", id),
+		map[string]string{"alert_rule_id": strconv.FormatUint(id, 10)},
+	)
+}
+
+// DeviceNoteNotFoundError returns a NotFound error for the given device note ID.
+func DeviceNoteNotFoundError(id uint64) error {
+	return NewError(codes.NotFound, ReasonDeviceNoteNotFound,
+		fmt.Sprintf("device note not found: %d", id),
+		map[string]string{"device_note_id": strconv.FormatUint(id, 10)},
+	)
+}
+
+// ReportScheduleNotFoundError returns a NotFound error for the given report schedule ID.
+func ReportScheduleNotFoundError(id uint64) error {
+	return NewError(codes.NotFound, ReasonReportScheduleNotFound,
+		fmt.Sprintf("report schedule not found: %d", id),
+		map[string]string{"report_schedule_id": strconv.FormatUint(id, 10)},
+	)
+}
+
+// QuotaExceededError returns a ResourceExhausted error for the named quota.
+func QuotaExceededError(quota, tenantID string) error {
+	return NewError(codes.ResourceExhausted, ReasonQuotaExceeded,
+		fmt.Sprintf("quota exceeded: %s", quota),
+		map[string]string{"quota": quota, "tenant_id": tenantID},
+	)
+}
+
+// ErrorInfo extracts the ErrorInfo detail from a gRPC error, if present.
+func ErrorInfo(err error) (*errdetails.ErrorInfo, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info, true
+		}
+	}
+
+	return nil, false
+}
+
+// ErrorReason returns the ErrorInfo reason of a gRPC error, or an empty string.
+func ErrorReason(err error) string {
+	info, ok := ErrorInfo(err)
+	if !ok {
+		return ""
+	}
+
+	return info.GetReason()
+}
+
+// FieldViolations returns the BadRequest field violations of a gRPC error.
+func FieldViolations(err error) []FieldViolation {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+
+	var violations []FieldViolation
+	for _, d := range st.Details() {
+		br, ok := d.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, v := range br.GetFieldViolations() {
+			violations = append(violations, FieldViolation{
+				Field:       v.GetField(),
+				Description: v.GetDescription(),
+			})
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,4 @@
+package iotv1
+
+// validate.proto is only imported; its Go code comes from protoc-gen-validate.
+//go:generate buf generate --template ../../../buf.gen.yaml --output ../../.. --exclude-path api/proto/validate ../../..
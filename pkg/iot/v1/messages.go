@@ -0,0 +1,6 @@
+package iotv1
+
+// MessageTypeSensorReadingBatch is the AMQP message type of queue messages
+// holding a SensorReadingBatch. Messages without a type hold a single
+// SensorReading or IoTDevice.
+const MessageTypeSensorReadingBatch = "iot.v1.SensorReadingBatch"
@@ -0,0 +1,4745 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: iot/v1/sensor.proto
+
+// Version 1 of the IoT API. Changes must stay backward compatible, which
+// `buf breaking` checks; incompatible changes go into a new iot.v2 package.
+
+package iotv1
+
+import (
+	_ "github.com/envoyproxy/protoc-gen-validate/validate"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SensorReading struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Unix timestamp
+	Temperature   float64                `protobuf:"fixed64,3,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	Humidity      float64                `protobuf:"fixed64,4,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	Pressure      float64                `protobuf:"fixed64,5,opt,name=pressure,proto3" json:"pressure,omitempty"`
+	BatteryLevel  float64                `protobuf:"fixed64,6,opt,name=battery_level,json=batteryLevel,proto3" json:"battery_level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SensorReading) Reset() {
+	*x = SensorReading{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SensorReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SensorReading) ProtoMessage() {}
+
+func (x *SensorReading) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SensorReading.ProtoReflect.Descriptor instead.
+func (*SensorReading) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SensorReading) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *SensorReading) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *SensorReading) GetTemperature() float64 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+func (x *SensorReading) GetHumidity() float64 {
+	if x != nil {
+		return x.Humidity
+	}
+	return 0
+}
+
+func (x *SensorReading) GetPressure() float64 {
+	if x != nil {
+		return x.Pressure
+	}
+	return 0
+}
+
+func (x *SensorReading) GetBatteryLevel() float64 {
+	if x != nil {
+		return x.BatteryLevel
+	}
+	return 0
+}
+
+// SensorReadingBatch carries several readings in one queue message. It is
+// published with the AMQP message type "iot.v1.SensorReadingBatch"; messages
+// without a type hold a single SensorReading.
+type SensorReadingBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Readings      []*SensorReading       `protobuf:"bytes,1,rep,name=readings,proto3" json:"readings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SensorReadingBatch) Reset() {
+	*x = SensorReadingBatch{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SensorReadingBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SensorReadingBatch) ProtoMessage() {}
+
+func (x *SensorReadingBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SensorReadingBatch.ProtoReflect.Descriptor instead.
+func (*SensorReadingBatch) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SensorReadingBatch) GetReadings() []*SensorReading {
+	if x != nil {
+		return x.Readings
+	}
+	return nil
+}
+
+type GetSensorReadingByDeviceIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	PageToken     string                 `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"` // 0 uses the server default
+	OrderBy       string                 `protobuf:"bytes,4,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`     // timestamp (default), temperature, humidity, pressure or battery_level
+	Ascending     bool                   `protobuf:"varint,5,opt,name=ascending,proto3" json:"ascending,omitempty"`               // sort ascending instead of newest/highest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSensorReadingByDeviceIDRequest) Reset() {
+	*x = GetSensorReadingByDeviceIDRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSensorReadingByDeviceIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSensorReadingByDeviceIDRequest) ProtoMessage() {}
+
+func (x *GetSensorReadingByDeviceIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSensorReadingByDeviceIDRequest.ProtoReflect.Descriptor instead.
+func (*GetSensorReadingByDeviceIDRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetSensorReadingByDeviceIDRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *GetSensorReadingByDeviceIDRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *GetSensorReadingByDeviceIDRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetSensorReadingByDeviceIDRequest) GetOrderBy() string {
+	if x != nil {
+		return x.OrderBy
+	}
+	return ""
+}
+
+func (x *GetSensorReadingByDeviceIDRequest) GetAscending() bool {
+	if x != nil {
+		return x.Ascending
+	}
+	return false
+}
+
+type GetSensorReadingByDeviceIDResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reading       []*SensorReading       `protobuf:"bytes,1,rep,name=reading,proto3" json:"reading,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSensorReadingByDeviceIDResponse) Reset() {
+	*x = GetSensorReadingByDeviceIDResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSensorReadingByDeviceIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSensorReadingByDeviceIDResponse) ProtoMessage() {}
+
+func (x *GetSensorReadingByDeviceIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSensorReadingByDeviceIDResponse.ProtoReflect.Descriptor instead.
+func (*GetSensorReadingByDeviceIDResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetSensorReadingByDeviceIDResponse) GetReading() []*SensorReading {
+	if x != nil {
+		return x.Reading
+	}
+	return nil
+}
+
+func (x *GetSensorReadingByDeviceIDResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type CountReadingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountReadingsRequest) Reset() {
+	*x = CountReadingsRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountReadingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountReadingsRequest) ProtoMessage() {}
+
+func (x *CountReadingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountReadingsRequest.ProtoReflect.Descriptor instead.
+func (*CountReadingsRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CountReadingsRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+type CountReadingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountReadingsResponse) Reset() {
+	*x = CountReadingsResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountReadingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountReadingsResponse) ProtoMessage() {}
+
+func (x *CountReadingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountReadingsResponse.ProtoReflect.Descriptor instead.
+func (*CountReadingsResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CountReadingsResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type GetSensorReadingSeriesBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceIds     []string               `protobuf:"bytes,1,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`
+	StartTime     int64                  `protobuf:"varint,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // Unix timestamp, inclusive
+	EndTime       int64                  `protobuf:"varint,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // Unix timestamp, exclusive; 0 means now
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSensorReadingSeriesBatchRequest) Reset() {
+	*x = GetSensorReadingSeriesBatchRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSensorReadingSeriesBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSensorReadingSeriesBatchRequest) ProtoMessage() {}
+
+func (x *GetSensorReadingSeriesBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSensorReadingSeriesBatchRequest.ProtoReflect.Descriptor instead.
+func (*GetSensorReadingSeriesBatchRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetSensorReadingSeriesBatchRequest) GetDeviceIds() []string {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+func (x *GetSensorReadingSeriesBatchRequest) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *GetSensorReadingSeriesBatchRequest) GetEndTime() int64 {
+	if x != nil {
+		return x.EndTime
+	}
+	return 0
+}
+
+type ExportReadingsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Devices to export; empty for all devices
+	DeviceIds     []string `protobuf:"bytes,1,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`
+	StartTime     int64    `protobuf:"varint,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // Unix timestamp, inclusive
+	EndTime       int64    `protobuf:"varint,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // Unix timestamp, exclusive; 0 means now
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportReadingsRequest) Reset() {
+	*x = ExportReadingsRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportReadingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportReadingsRequest) ProtoMessage() {}
+
+func (x *ExportReadingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportReadingsRequest.ProtoReflect.Descriptor instead.
+func (*ExportReadingsRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExportReadingsRequest) GetDeviceIds() []string {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+func (x *ExportReadingsRequest) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *ExportReadingsRequest) GetEndTime() int64 {
+	if x != nil {
+		return x.EndTime
+	}
+	return 0
+}
+
+type ExportReadingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectUrl     string                 `protobuf:"bytes,1,opt,name=object_url,json=objectUrl,proto3" json:"object_url,omitempty"` // location of the gzip-compressed NDJSON object
+	Readings      int64                  `protobuf:"varint,2,opt,name=readings,proto3" json:"readings,omitempty"`                   // readings written
+	Bytes         int64                  `protobuf:"varint,3,opt,name=bytes,proto3" json:"bytes,omitempty"`                         // compressed size of the object
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportReadingsResponse) Reset() {
+	*x = ExportReadingsResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportReadingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportReadingsResponse) ProtoMessage() {}
+
+func (x *ExportReadingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportReadingsResponse.ProtoReflect.Descriptor instead.
+func (*ExportReadingsResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ExportReadingsResponse) GetObjectUrl() string {
+	if x != nil {
+		return x.ObjectUrl
+	}
+	return ""
+}
+
+func (x *ExportReadingsResponse) GetReadings() int64 {
+	if x != nil {
+		return x.Readings
+	}
+	return 0
+}
+
+func (x *ExportReadingsResponse) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+type MetricStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Min           float64                `protobuf:"fixed64,1,opt,name=min,proto3" json:"min,omitempty"`
+	Max           float64                `protobuf:"fixed64,2,opt,name=max,proto3" json:"max,omitempty"`
+	Avg           float64                `protobuf:"fixed64,3,opt,name=avg,proto3" json:"avg,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricStats) Reset() {
+	*x = MetricStats{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricStats) ProtoMessage() {}
+
+func (x *MetricStats) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricStats.ProtoReflect.Descriptor instead.
+func (*MetricStats) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MetricStats) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *MetricStats) GetMax() float64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *MetricStats) GetAvg() float64 {
+	if x != nil {
+		return x.Avg
+	}
+	return 0
+}
+
+type SeriesStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"` // raw readings in the window
+	Temperature   *MetricStats           `protobuf:"bytes,2,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	Humidity      *MetricStats           `protobuf:"bytes,3,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	Pressure      *MetricStats           `protobuf:"bytes,4,opt,name=pressure,proto3" json:"pressure,omitempty"`
+	BatteryLevel  *MetricStats           `protobuf:"bytes,5,opt,name=battery_level,json=batteryLevel,proto3" json:"battery_level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SeriesStats) Reset() {
+	*x = SeriesStats{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SeriesStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeriesStats) ProtoMessage() {}
+
+func (x *SeriesStats) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeriesStats.ProtoReflect.Descriptor instead.
+func (*SeriesStats) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SeriesStats) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *SeriesStats) GetTemperature() *MetricStats {
+	if x != nil {
+		return x.Temperature
+	}
+	return nil
+}
+
+func (x *SeriesStats) GetHumidity() *MetricStats {
+	if x != nil {
+		return x.Humidity
+	}
+	return nil
+}
+
+func (x *SeriesStats) GetPressure() *MetricStats {
+	if x != nil {
+		return x.Pressure
+	}
+	return nil
+}
+
+func (x *SeriesStats) GetBatteryLevel() *MetricStats {
+	if x != nil {
+		return x.BatteryLevel
+	}
+	return nil
+}
+
+type SensorReadingSeries struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Readings      []*SensorReading       `protobuf:"bytes,2,rep,name=readings,proto3" json:"readings,omitempty"` // oldest first; bucket averages unless resolution is raw
+	Stats         *SeriesStats           `protobuf:"bytes,3,opt,name=stats,proto3" json:"stats,omitempty"`       // over the whole window, unset when there are no readings
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SensorReadingSeries) Reset() {
+	*x = SensorReadingSeries{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SensorReadingSeries) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SensorReadingSeries) ProtoMessage() {}
+
+func (x *SensorReadingSeries) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SensorReadingSeries.ProtoReflect.Descriptor instead.
+func (*SensorReadingSeries) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SensorReadingSeries) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *SensorReadingSeries) GetReadings() []*SensorReading {
+	if x != nil {
+		return x.Readings
+	}
+	return nil
+}
+
+func (x *SensorReadingSeries) GetStats() *SeriesStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+type GetSensorReadingSeriesBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Series        []*SensorReadingSeries `protobuf:"bytes,1,rep,name=series,proto3" json:"series,omitempty"`         // in request order
+	Resolution    string                 `protobuf:"bytes,2,opt,name=resolution,proto3" json:"resolution,omitempty"` // raw, hour or day
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSensorReadingSeriesBatchResponse) Reset() {
+	*x = GetSensorReadingSeriesBatchResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSensorReadingSeriesBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSensorReadingSeriesBatchResponse) ProtoMessage() {}
+
+func (x *GetSensorReadingSeriesBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSensorReadingSeriesBatchResponse.ProtoReflect.Descriptor instead.
+func (*GetSensorReadingSeriesBatchResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetSensorReadingSeriesBatchResponse) GetSeries() []*SensorReadingSeries {
+	if x != nil {
+		return x.Series
+	}
+	return nil
+}
+
+func (x *GetSensorReadingSeriesBatchResponse) GetResolution() string {
+	if x != nil {
+		return x.Resolution
+	}
+	return ""
+}
+
+type GetReadingsHeatmapRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CellSize      float64                `protobuf:"fixed64,1,opt,name=cell_size,json=cellSize,proto3" json:"cell_size,omitempty"`   // grid cell edge in degrees (0.01-10); 0 for the default of 1
+	StartTime     int64                  `protobuf:"varint,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // Unix timestamp, inclusive; 0 means 24 hours before end_time
+	EndTime       int64                  `protobuf:"varint,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // Unix timestamp, exclusive; 0 means now
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReadingsHeatmapRequest) Reset() {
+	*x = GetReadingsHeatmapRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReadingsHeatmapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReadingsHeatmapRequest) ProtoMessage() {}
+
+func (x *GetReadingsHeatmapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReadingsHeatmapRequest.ProtoReflect.Descriptor instead.
+func (*GetReadingsHeatmapRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetReadingsHeatmapRequest) GetCellSize() float64 {
+	if x != nil {
+		return x.CellSize
+	}
+	return 0
+}
+
+func (x *GetReadingsHeatmapRequest) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *GetReadingsHeatmapRequest) GetEndTime() int64 {
+	if x != nil {
+		return x.EndTime
+	}
+	return 0
+}
+
+type HeatmapCell struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Latitude       float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"` // south-west corner of the cell
+	Longitude      float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	AvgTemperature float64                `protobuf:"fixed64,3,opt,name=avg_temperature,json=avgTemperature,proto3" json:"avg_temperature,omitempty"`
+	AvgHumidity    float64                `protobuf:"fixed64,4,opt,name=avg_humidity,json=avgHumidity,proto3" json:"avg_humidity,omitempty"`
+	ReadingCount   int64                  `protobuf:"varint,5,opt,name=reading_count,json=readingCount,proto3" json:"reading_count,omitempty"`
+	DeviceCount    int64                  `protobuf:"varint,6,opt,name=device_count,json=deviceCount,proto3" json:"device_count,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *HeatmapCell) Reset() {
+	*x = HeatmapCell{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeatmapCell) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeatmapCell) ProtoMessage() {}
+
+func (x *HeatmapCell) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeatmapCell.ProtoReflect.Descriptor instead.
+func (*HeatmapCell) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *HeatmapCell) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *HeatmapCell) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *HeatmapCell) GetAvgTemperature() float64 {
+	if x != nil {
+		return x.AvgTemperature
+	}
+	return 0
+}
+
+func (x *HeatmapCell) GetAvgHumidity() float64 {
+	if x != nil {
+		return x.AvgHumidity
+	}
+	return 0
+}
+
+func (x *HeatmapCell) GetReadingCount() int64 {
+	if x != nil {
+		return x.ReadingCount
+	}
+	return 0
+}
+
+func (x *HeatmapCell) GetDeviceCount() int64 {
+	if x != nil {
+		return x.DeviceCount
+	}
+	return 0
+}
+
+type GetReadingsHeatmapResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cells         []*HeatmapCell         `protobuf:"bytes,1,rep,name=cells,proto3" json:"cells,omitempty"` // cells with readings only, south to north and west to east
+	CellSize      float64                `protobuf:"fixed64,2,opt,name=cell_size,json=cellSize,proto3" json:"cell_size,omitempty"`
+	Resolution    string                 `protobuf:"bytes,3,opt,name=resolution,proto3" json:"resolution,omitempty"` // raw or hour
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReadingsHeatmapResponse) Reset() {
+	*x = GetReadingsHeatmapResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReadingsHeatmapResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReadingsHeatmapResponse) ProtoMessage() {}
+
+func (x *GetReadingsHeatmapResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReadingsHeatmapResponse.ProtoReflect.Descriptor instead.
+func (*GetReadingsHeatmapResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetReadingsHeatmapResponse) GetCells() []*HeatmapCell {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+func (x *GetReadingsHeatmapResponse) GetCellSize() float64 {
+	if x != nil {
+		return x.CellSize
+	}
+	return 0
+}
+
+func (x *GetReadingsHeatmapResponse) GetResolution() string {
+	if x != nil {
+		return x.Resolution
+	}
+	return ""
+}
+
+type AlertRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Metric        string                 `protobuf:"bytes,3,opt,name=metric,proto3" json:"metric,omitempty"`     // temperature, humidity, pressure or battery_level
+	Operator      string                 `protobuf:"bytes,4,opt,name=operator,proto3" json:"operator,omitempty"` // gt or lt
+	Threshold     float64                `protobuf:"fixed64,5,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,6,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // empty applies the rule to all devices
+	Enabled       bool                   `protobuf:"varint,7,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	SilenceStart  int64                  `protobuf:"varint,8,opt,name=silence_start,json=silenceStart,proto3" json:"silence_start,omitempty"` // Unix timestamp; 0 when not silenced
+	SilenceEnd    int64                  `protobuf:"varint,9,opt,name=silence_end,json=silenceEnd,proto3" json:"silence_end,omitempty"`       // Unix timestamp; 0 when not silenced
+	CreatedAt     int64                  `protobuf:"varint,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     int64                  `protobuf:"varint,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertRule) Reset() {
+	*x = AlertRule{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertRule) ProtoMessage() {}
+
+func (x *AlertRule) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertRule.ProtoReflect.Descriptor instead.
+func (*AlertRule) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *AlertRule) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AlertRule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AlertRule) GetMetric() string {
+	if x != nil {
+		return x.Metric
+	}
+	return ""
+}
+
+func (x *AlertRule) GetOperator() string {
+	if x != nil {
+		return x.Operator
+	}
+	return ""
+}
+
+func (x *AlertRule) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *AlertRule) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *AlertRule) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *AlertRule) GetSilenceStart() int64 {
+	if x != nil {
+		return x.SilenceStart
+	}
+	return 0
+}
+
+func (x *AlertRule) GetSilenceEnd() int64 {
+	if x != nil {
+		return x.SilenceEnd
+	}
+	return 0
+}
+
+func (x *AlertRule) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *AlertRule) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type ListAlertRulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAlertRulesRequest) Reset() {
+	*x = ListAlertRulesRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAlertRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAlertRulesRequest) ProtoMessage() {}
+
+func (x *ListAlertRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAlertRulesRequest.ProtoReflect.Descriptor instead.
+func (*ListAlertRulesRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{17}
+}
+
+type ListAlertRulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []*AlertRule           `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAlertRulesResponse) Reset() {
+	*x = ListAlertRulesResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAlertRulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAlertRulesResponse) ProtoMessage() {}
+
+func (x *ListAlertRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAlertRulesResponse.ProtoReflect.Descriptor instead.
+func (*ListAlertRulesResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListAlertRulesResponse) GetRules() []*AlertRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type GetAlertRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAlertRuleRequest) Reset() {
+	*x = GetAlertRuleRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAlertRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAlertRuleRequest) ProtoMessage() {}
+
+func (x *GetAlertRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAlertRuleRequest.ProtoReflect.Descriptor instead.
+func (*GetAlertRuleRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetAlertRuleRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetAlertRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          *AlertRule             `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAlertRuleResponse) Reset() {
+	*x = GetAlertRuleResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAlertRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAlertRuleResponse) ProtoMessage() {}
+
+func (x *GetAlertRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAlertRuleResponse.ProtoReflect.Descriptor instead.
+func (*GetAlertRuleResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetAlertRuleResponse) GetRule() *AlertRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type CreateAlertRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          *AlertRule             `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAlertRuleRequest) Reset() {
+	*x = CreateAlertRuleRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAlertRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAlertRuleRequest) ProtoMessage() {}
+
+func (x *CreateAlertRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAlertRuleRequest.ProtoReflect.Descriptor instead.
+func (*CreateAlertRuleRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CreateAlertRuleRequest) GetRule() *AlertRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type CreateAlertRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          *AlertRule             `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAlertRuleResponse) Reset() {
+	*x = CreateAlertRuleResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAlertRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAlertRuleResponse) ProtoMessage() {}
+
+func (x *CreateAlertRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAlertRuleResponse.ProtoReflect.Descriptor instead.
+func (*CreateAlertRuleResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CreateAlertRuleResponse) GetRule() *AlertRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type UpdateAlertRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          *AlertRule             `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAlertRuleRequest) Reset() {
+	*x = UpdateAlertRuleRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAlertRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAlertRuleRequest) ProtoMessage() {}
+
+func (x *UpdateAlertRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAlertRuleRequest.ProtoReflect.Descriptor instead.
+func (*UpdateAlertRuleRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *UpdateAlertRuleRequest) GetRule() *AlertRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type UpdateAlertRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          *AlertRule             `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAlertRuleResponse) Reset() {
+	*x = UpdateAlertRuleResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAlertRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAlertRuleResponse) ProtoMessage() {}
+
+func (x *UpdateAlertRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAlertRuleResponse.ProtoReflect.Descriptor instead.
+func (*UpdateAlertRuleResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UpdateAlertRuleResponse) GetRule() *AlertRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type DeleteAlertRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAlertRuleRequest) Reset() {
+	*x = DeleteAlertRuleRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAlertRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAlertRuleRequest) ProtoMessage() {}
+
+func (x *DeleteAlertRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAlertRuleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteAlertRuleRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DeleteAlertRuleRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteAlertRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAlertRuleResponse) Reset() {
+	*x = DeleteAlertRuleResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAlertRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAlertRuleResponse) ProtoMessage() {}
+
+func (x *DeleteAlertRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAlertRuleResponse.ProtoReflect.Descriptor instead.
+func (*DeleteAlertRuleResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{26}
+}
+
+type DeviceNote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Author        string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`                                    // who performed or recorded the intervention
+	Body          string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`                                        // free text
+	AttachmentUrl string                 `protobuf:"bytes,5,opt,name=attachment_url,json=attachmentUrl,proto3" json:"attachment_url,omitempty"` // optional http(s) link, e.g. to a photo or report
+	CreatedAt     int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`            // Unix timestamp
+	UpdatedAt     int64                  `protobuf:"varint,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`            // Unix timestamp
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceNote) Reset() {
+	*x = DeviceNote{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceNote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceNote) ProtoMessage() {}
+
+func (x *DeviceNote) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceNote.ProtoReflect.Descriptor instead.
+func (*DeviceNote) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DeviceNote) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeviceNote) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *DeviceNote) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *DeviceNote) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *DeviceNote) GetAttachmentUrl() string {
+	if x != nil {
+		return x.AttachmentUrl
+	}
+	return ""
+}
+
+func (x *DeviceNote) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *DeviceNote) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type ListDeviceNotesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeviceNotesRequest) Reset() {
+	*x = ListDeviceNotesRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeviceNotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeviceNotesRequest) ProtoMessage() {}
+
+func (x *ListDeviceNotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeviceNotesRequest.ProtoReflect.Descriptor instead.
+func (*ListDeviceNotesRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListDeviceNotesRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+type ListDeviceNotesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Notes         []*DeviceNote          `protobuf:"bytes,1,rep,name=notes,proto3" json:"notes,omitempty"` // newest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeviceNotesResponse) Reset() {
+	*x = ListDeviceNotesResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeviceNotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeviceNotesResponse) ProtoMessage() {}
+
+func (x *ListDeviceNotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeviceNotesResponse.ProtoReflect.Descriptor instead.
+func (*ListDeviceNotesResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ListDeviceNotesResponse) GetNotes() []*DeviceNote {
+	if x != nil {
+		return x.Notes
+	}
+	return nil
+}
+
+type CreateDeviceNoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *DeviceNote            `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDeviceNoteRequest) Reset() {
+	*x = CreateDeviceNoteRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDeviceNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDeviceNoteRequest) ProtoMessage() {}
+
+func (x *CreateDeviceNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDeviceNoteRequest.ProtoReflect.Descriptor instead.
+func (*CreateDeviceNoteRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CreateDeviceNoteRequest) GetNote() *DeviceNote {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+type CreateDeviceNoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *DeviceNote            `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDeviceNoteResponse) Reset() {
+	*x = CreateDeviceNoteResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDeviceNoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDeviceNoteResponse) ProtoMessage() {}
+
+func (x *CreateDeviceNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDeviceNoteResponse.ProtoReflect.Descriptor instead.
+func (*CreateDeviceNoteResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *CreateDeviceNoteResponse) GetNote() *DeviceNote {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+type UpdateDeviceNoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *DeviceNote            `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"` // device_id cannot be changed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateDeviceNoteRequest) Reset() {
+	*x = UpdateDeviceNoteRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDeviceNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDeviceNoteRequest) ProtoMessage() {}
+
+func (x *UpdateDeviceNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateDeviceNoteRequest.ProtoReflect.Descriptor instead.
+func (*UpdateDeviceNoteRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *UpdateDeviceNoteRequest) GetNote() *DeviceNote {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+type UpdateDeviceNoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *DeviceNote            `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateDeviceNoteResponse) Reset() {
+	*x = UpdateDeviceNoteResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDeviceNoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDeviceNoteResponse) ProtoMessage() {}
+
+func (x *UpdateDeviceNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateDeviceNoteResponse.ProtoReflect.Descriptor instead.
+func (*UpdateDeviceNoteResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *UpdateDeviceNoteResponse) GetNote() *DeviceNote {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+type DeleteDeviceNoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDeviceNoteRequest) Reset() {
+	*x = DeleteDeviceNoteRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDeviceNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDeviceNoteRequest) ProtoMessage() {}
+
+func (x *DeleteDeviceNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDeviceNoteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDeviceNoteRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *DeleteDeviceNoteRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteDeviceNoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDeviceNoteResponse) Reset() {
+	*x = DeleteDeviceNoteResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDeviceNoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDeviceNoteResponse) ProtoMessage() {}
+
+func (x *DeleteDeviceNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDeviceNoteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDeviceNoteResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{35}
+}
+
+type GetQuotaUsageRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional; reports the per-device request quota too
+	DeviceId      string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotaUsageRequest) Reset() {
+	*x = GetQuotaUsageRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaUsageRequest) ProtoMessage() {}
+
+func (x *GetQuotaUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetQuotaUsageRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetQuotaUsageRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+type GetQuotaUsageResponse struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	TenantId            string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	RequestsUsed        int64                  `protobuf:"varint,2,opt,name=requests_used,json=requestsUsed,proto3" json:"requests_used,omitempty"`                        // in the current minute
+	RequestsLimit       int64                  `protobuf:"varint,3,opt,name=requests_limit,json=requestsLimit,proto3" json:"requests_limit,omitempty"`                     // 0 means unlimited
+	DeviceRequestsUsed  int64                  `protobuf:"varint,4,opt,name=device_requests_used,json=deviceRequestsUsed,proto3" json:"device_requests_used,omitempty"`    // for device_id in the current minute
+	DeviceRequestsLimit int64                  `protobuf:"varint,5,opt,name=device_requests_limit,json=deviceRequestsLimit,proto3" json:"device_requests_limit,omitempty"` // 0 means unlimited
+	ExportRowsUsed      int64                  `protobuf:"varint,6,opt,name=export_rows_used,json=exportRowsUsed,proto3" json:"export_rows_used,omitempty"`                // readings returned today (UTC)
+	ExportRowsLimit     int64                  `protobuf:"varint,7,opt,name=export_rows_limit,json=exportRowsLimit,proto3" json:"export_rows_limit,omitempty"`             // 0 means unlimited
+	RequestsResetAt     int64                  `protobuf:"varint,8,opt,name=requests_reset_at,json=requestsResetAt,proto3" json:"requests_reset_at,omitempty"`             // Unix timestamp
+	ExportRowsResetAt   int64                  `protobuf:"varint,9,opt,name=export_rows_reset_at,json=exportRowsResetAt,proto3" json:"export_rows_reset_at,omitempty"`     // Unix timestamp
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *GetQuotaUsageResponse) Reset() {
+	*x = GetQuotaUsageResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaUsageResponse) ProtoMessage() {}
+
+func (x *GetQuotaUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetQuotaUsageResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetQuotaUsageResponse) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *GetQuotaUsageResponse) GetRequestsUsed() int64 {
+	if x != nil {
+		return x.RequestsUsed
+	}
+	return 0
+}
+
+func (x *GetQuotaUsageResponse) GetRequestsLimit() int64 {
+	if x != nil {
+		return x.RequestsLimit
+	}
+	return 0
+}
+
+func (x *GetQuotaUsageResponse) GetDeviceRequestsUsed() int64 {
+	if x != nil {
+		return x.DeviceRequestsUsed
+	}
+	return 0
+}
+
+func (x *GetQuotaUsageResponse) GetDeviceRequestsLimit() int64 {
+	if x != nil {
+		return x.DeviceRequestsLimit
+	}
+	return 0
+}
+
+func (x *GetQuotaUsageResponse) GetExportRowsUsed() int64 {
+	if x != nil {
+		return x.ExportRowsUsed
+	}
+	return 0
+}
+
+func (x *GetQuotaUsageResponse) GetExportRowsLimit() int64 {
+	if x != nil {
+		return x.ExportRowsLimit
+	}
+	return 0
+}
+
+func (x *GetQuotaUsageResponse) GetRequestsResetAt() int64 {
+	if x != nil {
+		return x.RequestsResetAt
+	}
+	return 0
+}
+
+func (x *GetQuotaUsageResponse) GetExportRowsResetAt() int64 {
+	if x != nil {
+		return x.ExportRowsResetAt
+	}
+	return 0
+}
+
+type IoTDevice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Unix timestamp of the device message; 0 means now in CreateOrUpdateDevice
+	Location      string                 `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`
+	MacAddress    string                 `protobuf:"bytes,4,opt,name=mac_address,json=macAddress,proto3" json:"mac_address,omitempty"`
+	IpAddress     string                 `protobuf:"bytes,5,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Firmware      string                 `protobuf:"bytes,6,opt,name=firmware,proto3" json:"firmware,omitempty"`
+	Latitude      float32                `protobuf:"fixed32,7,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float32                `protobuf:"fixed32,8,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	DeletedAt     int64                  `protobuf:"varint,9,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"` // Unix timestamp; 0 unless the device is in the trash
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IoTDevice) Reset() {
+	*x = IoTDevice{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IoTDevice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IoTDevice) ProtoMessage() {}
+
+func (x *IoTDevice) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IoTDevice.ProtoReflect.Descriptor instead.
+func (*IoTDevice) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *IoTDevice) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *IoTDevice) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *IoTDevice) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *IoTDevice) GetMacAddress() string {
+	if x != nil {
+		return x.MacAddress
+	}
+	return ""
+}
+
+func (x *IoTDevice) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *IoTDevice) GetFirmware() string {
+	if x != nil {
+		return x.Firmware
+	}
+	return ""
+}
+
+func (x *IoTDevice) GetLatitude() float32 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *IoTDevice) GetLongitude() float32 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *IoTDevice) GetDeletedAt() int64 {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return 0
+}
+
+type CreateOrUpdateDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Device        *IoTDevice             `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"` // deleted_at is ignored
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateOrUpdateDeviceRequest) Reset() {
+	*x = CreateOrUpdateDeviceRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrUpdateDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrUpdateDeviceRequest) ProtoMessage() {}
+
+func (x *CreateOrUpdateDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrUpdateDeviceRequest.ProtoReflect.Descriptor instead.
+func (*CreateOrUpdateDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *CreateOrUpdateDeviceRequest) GetDevice() *IoTDevice {
+	if x != nil {
+		return x.Device
+	}
+	return nil
+}
+
+type CreateOrUpdateDeviceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Device        *IoTDevice             `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	Created       bool                   `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"` // false when an existing device was updated
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateOrUpdateDeviceResponse) Reset() {
+	*x = CreateOrUpdateDeviceResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrUpdateDeviceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrUpdateDeviceResponse) ProtoMessage() {}
+
+func (x *CreateOrUpdateDeviceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrUpdateDeviceResponse.ProtoReflect.Descriptor instead.
+func (*CreateOrUpdateDeviceResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *CreateOrUpdateDeviceResponse) GetDevice() *IoTDevice {
+	if x != nil {
+		return x.Device
+	}
+	return nil
+}
+
+func (x *CreateOrUpdateDeviceResponse) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+type GetAllDevicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Devices       []*IoTDevice           `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAllDevicesResponse) Reset() {
+	*x = GetAllDevicesResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllDevicesResponse) ProtoMessage() {}
+
+func (x *GetAllDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllDevicesResponse.ProtoReflect.Descriptor instead.
+func (*GetAllDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *GetAllDevicesResponse) GetDevices() []*IoTDevice {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+type GetAllDevicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAllDevicesRequest) Reset() {
+	*x = GetAllDevicesRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllDevicesRequest) ProtoMessage() {}
+
+func (x *GetAllDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllDevicesRequest.ProtoReflect.Descriptor instead.
+func (*GetAllDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{42}
+}
+
+type GetDeviceByIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeviceByIDRequest) Reset() {
+	*x = GetDeviceByIDRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceByIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceByIDRequest) ProtoMessage() {}
+
+func (x *GetDeviceByIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceByIDRequest.ProtoReflect.Descriptor instead.
+func (*GetDeviceByIDRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *GetDeviceByIDRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+type BatteryProjection struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	DrainPerDay      float64                `protobuf:"fixed64,1,opt,name=drain_per_day,json=drainPerDay,proto3" json:"drain_per_day,omitempty"`               // percentage points per day; negative when charging
+	BatteryLevel     float64                `protobuf:"fixed64,2,opt,name=battery_level,json=batteryLevel,proto3" json:"battery_level,omitempty"`              // fitted level at the latest reading
+	ProjectedEmptyAt int64                  `protobuf:"varint,3,opt,name=projected_empty_at,json=projectedEmptyAt,proto3" json:"projected_empty_at,omitempty"` // Unix timestamp; 0 when the battery is not draining
+	ComputedAt       int64                  `protobuf:"varint,4,opt,name=computed_at,json=computedAt,proto3" json:"computed_at,omitempty"`                     // Unix timestamp
+	SampleCount      int64                  `protobuf:"varint,5,opt,name=sample_count,json=sampleCount,proto3" json:"sample_count,omitempty"`                  // readings used for the fit
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BatteryProjection) Reset() {
+	*x = BatteryProjection{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatteryProjection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatteryProjection) ProtoMessage() {}
+
+func (x *BatteryProjection) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatteryProjection.ProtoReflect.Descriptor instead.
+func (*BatteryProjection) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *BatteryProjection) GetDrainPerDay() float64 {
+	if x != nil {
+		return x.DrainPerDay
+	}
+	return 0
+}
+
+func (x *BatteryProjection) GetBatteryLevel() float64 {
+	if x != nil {
+		return x.BatteryLevel
+	}
+	return 0
+}
+
+func (x *BatteryProjection) GetProjectedEmptyAt() int64 {
+	if x != nil {
+		return x.ProjectedEmptyAt
+	}
+	return 0
+}
+
+func (x *BatteryProjection) GetComputedAt() int64 {
+	if x != nil {
+		return x.ComputedAt
+	}
+	return 0
+}
+
+func (x *BatteryProjection) GetSampleCount() int64 {
+	if x != nil {
+		return x.SampleCount
+	}
+	return 0
+}
+
+type GetDeviceByIDResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Device            *IoTDevice             `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	BatteryProjection *BatteryProjection     `protobuf:"bytes,2,opt,name=battery_projection,json=batteryProjection,proto3" json:"battery_projection,omitempty"` // unset until enough readings exist
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *GetDeviceByIDResponse) Reset() {
+	*x = GetDeviceByIDResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceByIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceByIDResponse) ProtoMessage() {}
+
+func (x *GetDeviceByIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceByIDResponse.ProtoReflect.Descriptor instead.
+func (*GetDeviceByIDResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *GetDeviceByIDResponse) GetDevice() *IoTDevice {
+	if x != nil {
+		return x.Device
+	}
+	return nil
+}
+
+func (x *GetDeviceByIDResponse) GetBatteryProjection() *BatteryProjection {
+	if x != nil {
+		return x.BatteryProjection
+	}
+	return nil
+}
+
+type ListLowBatteryDevicesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Devices projected to be empty within this many days (1-365); 0 for the default
+	WithinDays    int32 `protobuf:"varint,1,opt,name=within_days,json=withinDays,proto3" json:"within_days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLowBatteryDevicesRequest) Reset() {
+	*x = ListLowBatteryDevicesRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLowBatteryDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLowBatteryDevicesRequest) ProtoMessage() {}
+
+func (x *ListLowBatteryDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLowBatteryDevicesRequest.ProtoReflect.Descriptor instead.
+func (*ListLowBatteryDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ListLowBatteryDevicesRequest) GetWithinDays() int32 {
+	if x != nil {
+		return x.WithinDays
+	}
+	return 0
+}
+
+type LowBatteryDevice struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Device            *IoTDevice             `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	BatteryProjection *BatteryProjection     `protobuf:"bytes,2,opt,name=battery_projection,json=batteryProjection,proto3" json:"battery_projection,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *LowBatteryDevice) Reset() {
+	*x = LowBatteryDevice{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LowBatteryDevice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LowBatteryDevice) ProtoMessage() {}
+
+func (x *LowBatteryDevice) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LowBatteryDevice.ProtoReflect.Descriptor instead.
+func (*LowBatteryDevice) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *LowBatteryDevice) GetDevice() *IoTDevice {
+	if x != nil {
+		return x.Device
+	}
+	return nil
+}
+
+func (x *LowBatteryDevice) GetBatteryProjection() *BatteryProjection {
+	if x != nil {
+		return x.BatteryProjection
+	}
+	return nil
+}
+
+type ListLowBatteryDevicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Devices       []*LowBatteryDevice    `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"` // soonest empty first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLowBatteryDevicesResponse) Reset() {
+	*x = ListLowBatteryDevicesResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLowBatteryDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLowBatteryDevicesResponse) ProtoMessage() {}
+
+func (x *ListLowBatteryDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLowBatteryDevicesResponse.ProtoReflect.Descriptor instead.
+func (*ListLowBatteryDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ListLowBatteryDevicesResponse) GetDevices() []*LowBatteryDevice {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+type DeviceLocation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Latitude      float32                `protobuf:"fixed32,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float32                `protobuf:"fixed32,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	IpAddress     string                 `protobuf:"bytes,3,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Unix timestamp of the device message that reported the location
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceLocation) Reset() {
+	*x = DeviceLocation{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceLocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceLocation) ProtoMessage() {}
+
+func (x *DeviceLocation) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceLocation.ProtoReflect.Descriptor instead.
+func (*DeviceLocation) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *DeviceLocation) GetLatitude() float32 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *DeviceLocation) GetLongitude() float32 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *DeviceLocation) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *DeviceLocation) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type GetDeviceLocationHistoryRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	// Most recent locations to return (1-1000); 0 for the default of 100
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeviceLocationHistoryRequest) Reset() {
+	*x = GetDeviceLocationHistoryRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceLocationHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceLocationHistoryRequest) ProtoMessage() {}
+
+func (x *GetDeviceLocationHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceLocationHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetDeviceLocationHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *GetDeviceLocationHistoryRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *GetDeviceLocationHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetDeviceLocationHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Locations     []*DeviceLocation      `protobuf:"bytes,1,rep,name=locations,proto3" json:"locations,omitempty"` // oldest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeviceLocationHistoryResponse) Reset() {
+	*x = GetDeviceLocationHistoryResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceLocationHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceLocationHistoryResponse) ProtoMessage() {}
+
+func (x *GetDeviceLocationHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceLocationHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetDeviceLocationHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *GetDeviceLocationHistoryResponse) GetLocations() []*DeviceLocation {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+// DeviceChange is a recorded device message that changed the firmware,
+// location or network address of a device, with the values it set.
+type DeviceChange struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp  int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Unix timestamp of the device message
+	Source     string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`        // queue or api
+	Location   string                 `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`
+	MacAddress string                 `protobuf:"bytes,4,opt,name=mac_address,json=macAddress,proto3" json:"mac_address,omitempty"`
+	IpAddress  string                 `protobuf:"bytes,5,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Firmware   string                 `protobuf:"bytes,6,opt,name=firmware,proto3" json:"firmware,omitempty"`
+	Latitude   float32                `protobuf:"fixed32,7,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude  float32                `protobuf:"fixed32,8,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	// Names of the fields that differ from the previous message; empty for the
+	// first recorded message of the device
+	ChangedFields []string `protobuf:"bytes,9,rep,name=changed_fields,json=changedFields,proto3" json:"changed_fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceChange) Reset() {
+	*x = DeviceChange{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceChange) ProtoMessage() {}
+
+func (x *DeviceChange) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceChange.ProtoReflect.Descriptor instead.
+func (*DeviceChange) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *DeviceChange) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *DeviceChange) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *DeviceChange) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *DeviceChange) GetMacAddress() string {
+	if x != nil {
+		return x.MacAddress
+	}
+	return ""
+}
+
+func (x *DeviceChange) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *DeviceChange) GetFirmware() string {
+	if x != nil {
+		return x.Firmware
+	}
+	return ""
+}
+
+func (x *DeviceChange) GetLatitude() float32 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *DeviceChange) GetLongitude() float32 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *DeviceChange) GetChangedFields() []string {
+	if x != nil {
+		return x.ChangedFields
+	}
+	return nil
+}
+
+type GetDeviceHistoryRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	// Most recent changes to return (1-1000); 0 for the default of 100
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeviceHistoryRequest) Reset() {
+	*x = GetDeviceHistoryRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceHistoryRequest) ProtoMessage() {}
+
+func (x *GetDeviceHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetDeviceHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *GetDeviceHistoryRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *GetDeviceHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetDeviceHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changes       []*DeviceChange        `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"` // oldest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeviceHistoryResponse) Reset() {
+	*x = GetDeviceHistoryResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceHistoryResponse) ProtoMessage() {}
+
+func (x *GetDeviceHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetDeviceHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *GetDeviceHistoryResponse) GetChanges() []*DeviceChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type ReportSchedule struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Frequency      string                 `protobuf:"bytes,3,opt,name=frequency,proto3" json:"frequency,omitempty"`                     // daily or weekly
+	Format         string                 `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"`                           // html, csv or ndjson
+	WebhookUrl     string                 `protobuf:"bytes,5,opt,name=webhook_url,json=webhookUrl,proto3" json:"webhook_url,omitempty"` // optional; report is POSTed here
+	Email          string                 `protobuf:"bytes,6,opt,name=email,proto3" json:"email,omitempty"`                             // optional; report is mailed here
+	Enabled        bool                   `protobuf:"varint,7,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	NextRunAt      int64                  `protobuf:"varint,8,opt,name=next_run_at,json=nextRunAt,proto3" json:"next_run_at,omitempty"`    // assigned by the server
+	LastSentAt     int64                  `protobuf:"varint,9,opt,name=last_sent_at,json=lastSentAt,proto3" json:"last_sent_at,omitempty"` // 0 if never delivered
+	LastError      string                 `protobuf:"bytes,10,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`      // error of the last delivery attempt, empty on success
+	CreatedAt      int64                  `protobuf:"varint,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt      int64                  `protobuf:"varint,12,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ExportToBucket bool                   `protobuf:"varint,13,opt,name=export_to_bucket,json=exportToBucket,proto3" json:"export_to_bucket,omitempty"` // optional; report is uploaded to the export bucket
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ReportSchedule) Reset() {
+	*x = ReportSchedule{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportSchedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportSchedule) ProtoMessage() {}
+
+func (x *ReportSchedule) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportSchedule.ProtoReflect.Descriptor instead.
+func (*ReportSchedule) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *ReportSchedule) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ReportSchedule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ReportSchedule) GetFrequency() string {
+	if x != nil {
+		return x.Frequency
+	}
+	return ""
+}
+
+func (x *ReportSchedule) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ReportSchedule) GetWebhookUrl() string {
+	if x != nil {
+		return x.WebhookUrl
+	}
+	return ""
+}
+
+func (x *ReportSchedule) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *ReportSchedule) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *ReportSchedule) GetNextRunAt() int64 {
+	if x != nil {
+		return x.NextRunAt
+	}
+	return 0
+}
+
+func (x *ReportSchedule) GetLastSentAt() int64 {
+	if x != nil {
+		return x.LastSentAt
+	}
+	return 0
+}
+
+func (x *ReportSchedule) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *ReportSchedule) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *ReportSchedule) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *ReportSchedule) GetExportToBucket() bool {
+	if x != nil {
+		return x.ExportToBucket
+	}
+	return false
+}
+
+type ListReportSchedulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReportSchedulesRequest) Reset() {
+	*x = ListReportSchedulesRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReportSchedulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReportSchedulesRequest) ProtoMessage() {}
+
+func (x *ListReportSchedulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReportSchedulesRequest.ProtoReflect.Descriptor instead.
+func (*ListReportSchedulesRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{56}
+}
+
+type ListReportSchedulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedules     []*ReportSchedule      `protobuf:"bytes,1,rep,name=schedules,proto3" json:"schedules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReportSchedulesResponse) Reset() {
+	*x = ListReportSchedulesResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReportSchedulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReportSchedulesResponse) ProtoMessage() {}
+
+func (x *ListReportSchedulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReportSchedulesResponse.ProtoReflect.Descriptor instead.
+func (*ListReportSchedulesResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *ListReportSchedulesResponse) GetSchedules() []*ReportSchedule {
+	if x != nil {
+		return x.Schedules
+	}
+	return nil
+}
+
+type CreateReportScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedule      *ReportSchedule        `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateReportScheduleRequest) Reset() {
+	*x = CreateReportScheduleRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateReportScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateReportScheduleRequest) ProtoMessage() {}
+
+func (x *CreateReportScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateReportScheduleRequest.ProtoReflect.Descriptor instead.
+func (*CreateReportScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *CreateReportScheduleRequest) GetSchedule() *ReportSchedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+type CreateReportScheduleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedule      *ReportSchedule        `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateReportScheduleResponse) Reset() {
+	*x = CreateReportScheduleResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateReportScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateReportScheduleResponse) ProtoMessage() {}
+
+func (x *CreateReportScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateReportScheduleResponse.ProtoReflect.Descriptor instead.
+func (*CreateReportScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *CreateReportScheduleResponse) GetSchedule() *ReportSchedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+type UpdateReportScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedule      *ReportSchedule        `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateReportScheduleRequest) Reset() {
+	*x = UpdateReportScheduleRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateReportScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateReportScheduleRequest) ProtoMessage() {}
+
+func (x *UpdateReportScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateReportScheduleRequest.ProtoReflect.Descriptor instead.
+func (*UpdateReportScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *UpdateReportScheduleRequest) GetSchedule() *ReportSchedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+type UpdateReportScheduleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedule      *ReportSchedule        `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateReportScheduleResponse) Reset() {
+	*x = UpdateReportScheduleResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateReportScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateReportScheduleResponse) ProtoMessage() {}
+
+func (x *UpdateReportScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateReportScheduleResponse.ProtoReflect.Descriptor instead.
+func (*UpdateReportScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *UpdateReportScheduleResponse) GetSchedule() *ReportSchedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+type DeleteReportScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteReportScheduleRequest) Reset() {
+	*x = DeleteReportScheduleRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteReportScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteReportScheduleRequest) ProtoMessage() {}
+
+func (x *DeleteReportScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteReportScheduleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteReportScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *DeleteReportScheduleRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteReportScheduleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteReportScheduleResponse) Reset() {
+	*x = DeleteReportScheduleResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteReportScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteReportScheduleResponse) ProtoMessage() {}
+
+func (x *DeleteReportScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteReportScheduleResponse.ProtoReflect.Descriptor instead.
+func (*DeleteReportScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{63}
+}
+
+type DeleteDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDeviceRequest) Reset() {
+	*x = DeleteDeviceRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDeviceRequest) ProtoMessage() {}
+
+func (x *DeleteDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDeviceRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *DeleteDeviceRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+type DeleteDeviceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDeviceResponse) Reset() {
+	*x = DeleteDeviceResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDeviceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDeviceResponse) ProtoMessage() {}
+
+func (x *DeleteDeviceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDeviceResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDeviceResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{65}
+}
+
+type RestoreDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreDeviceRequest) Reset() {
+	*x = RestoreDeviceRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreDeviceRequest) ProtoMessage() {}
+
+func (x *RestoreDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreDeviceRequest.ProtoReflect.Descriptor instead.
+func (*RestoreDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *RestoreDeviceRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+type RestoreDeviceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Device        *IoTDevice             `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreDeviceResponse) Reset() {
+	*x = RestoreDeviceResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreDeviceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreDeviceResponse) ProtoMessage() {}
+
+func (x *RestoreDeviceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreDeviceResponse.ProtoReflect.Descriptor instead.
+func (*RestoreDeviceResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *RestoreDeviceResponse) GetDevice() *IoTDevice {
+	if x != nil {
+		return x.Device
+	}
+	return nil
+}
+
+type ListDeletedDevicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeletedDevicesRequest) Reset() {
+	*x = ListDeletedDevicesRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeletedDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeletedDevicesRequest) ProtoMessage() {}
+
+func (x *ListDeletedDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeletedDevicesRequest.ProtoReflect.Descriptor instead.
+func (*ListDeletedDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{68}
+}
+
+type ListDeletedDevicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Devices       []*IoTDevice           `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"` // most recently deleted first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeletedDevicesResponse) Reset() {
+	*x = ListDeletedDevicesResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeletedDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeletedDevicesResponse) ProtoMessage() {}
+
+func (x *ListDeletedDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeletedDevicesResponse.ProtoReflect.Descriptor instead.
+func (*ListDeletedDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *ListDeletedDevicesResponse) GetDevices() []*IoTDevice {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+type GetStatusSummaryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Days of uptime history (1-90); 0 for the default of 90
+	Days          int32 `protobuf:"varint,1,opt,name=days,proto3" json:"days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusSummaryRequest) Reset() {
+	*x = GetStatusSummaryRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusSummaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusSummaryRequest) ProtoMessage() {}
+
+func (x *GetStatusSummaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusSummaryRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusSummaryRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *GetStatusSummaryRequest) GetDays() int32 {
+	if x != nil {
+		return x.Days
+	}
+	return 0
+}
+
+type DailyUptime struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Day           int64                  `protobuf:"varint,1,opt,name=day,proto3" json:"day,omitempty"` // Unix timestamp of the start of the UTC day
+	Checks        int64                  `protobuf:"varint,2,opt,name=checks,proto3" json:"checks,omitempty"`
+	UpChecks      int64                  `protobuf:"varint,3,opt,name=up_checks,json=upChecks,proto3" json:"up_checks,omitempty"` // checks that found the component healthy
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DailyUptime) Reset() {
+	*x = DailyUptime{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DailyUptime) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DailyUptime) ProtoMessage() {}
+
+func (x *DailyUptime) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DailyUptime.ProtoReflect.Descriptor instead.
+func (*DailyUptime) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *DailyUptime) GetDay() int64 {
+	if x != nil {
+		return x.Day
+	}
+	return 0
+}
+
+func (x *DailyUptime) GetChecks() int64 {
+	if x != nil {
+		return x.Checks
+	}
+	return 0
+}
+
+func (x *DailyUptime) GetUpChecks() int64 {
+	if x != nil {
+		return x.UpChecks
+	}
+	return 0
+}
+
+type ComponentStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                          // database, message_queue or ingestion
+	Up            bool                   `protobuf:"varint,2,opt,name=up,proto3" json:"up,omitempty"`                                             // result of the latest check
+	CheckedAt     int64                  `protobuf:"varint,3,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`              // Unix timestamp of the latest check
+	UptimePercent float64                `protobuf:"fixed64,4,opt,name=uptime_percent,json=uptimePercent,proto3" json:"uptime_percent,omitempty"` // share of healthy checks over the history
+	History       []*DailyUptime         `protobuf:"bytes,5,rep,name=history,proto3" json:"history,omitempty"`                                    // oldest first; days without checks are omitted
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComponentStatus) Reset() {
+	*x = ComponentStatus{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComponentStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComponentStatus) ProtoMessage() {}
+
+func (x *ComponentStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComponentStatus.ProtoReflect.Descriptor instead.
+func (*ComponentStatus) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *ComponentStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ComponentStatus) GetUp() bool {
+	if x != nil {
+		return x.Up
+	}
+	return false
+}
+
+func (x *ComponentStatus) GetCheckedAt() int64 {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return 0
+}
+
+func (x *ComponentStatus) GetUptimePercent() float64 {
+	if x != nil {
+		return x.UptimePercent
+	}
+	return 0
+}
+
+func (x *ComponentStatus) GetHistory() []*DailyUptime {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+type GetStatusSummaryResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	DeviceCount       int64                  `protobuf:"varint,1,opt,name=device_count,json=deviceCount,proto3" json:"device_count,omitempty"`
+	ActiveDeviceCount int64                  `protobuf:"varint,2,opt,name=active_device_count,json=activeDeviceCount,proto3" json:"active_device_count,omitempty"`  // devices seen in the last 15 minutes
+	ReadingsPerMinute float64                `protobuf:"fixed64,3,opt,name=readings_per_minute,json=readingsPerMinute,proto3" json:"readings_per_minute,omitempty"` // over the last 5 minutes
+	Components        []*ComponentStatus     `protobuf:"bytes,4,rep,name=components,proto3" json:"components,omitempty"`                                            // components checked in the history window
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *GetStatusSummaryResponse) Reset() {
+	*x = GetStatusSummaryResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusSummaryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusSummaryResponse) ProtoMessage() {}
+
+func (x *GetStatusSummaryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusSummaryResponse.ProtoReflect.Descriptor instead.
+func (*GetStatusSummaryResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *GetStatusSummaryResponse) GetDeviceCount() int64 {
+	if x != nil {
+		return x.DeviceCount
+	}
+	return 0
+}
+
+func (x *GetStatusSummaryResponse) GetActiveDeviceCount() int64 {
+	if x != nil {
+		return x.ActiveDeviceCount
+	}
+	return 0
+}
+
+func (x *GetStatusSummaryResponse) GetReadingsPerMinute() float64 {
+	if x != nil {
+		return x.ReadingsPerMinute
+	}
+	return 0
+}
+
+func (x *GetStatusSummaryResponse) GetComponents() []*ComponentStatus {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+type GetServerInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServerInfoRequest) Reset() {
+	*x = GetServerInfoRequest{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServerInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServerInfoRequest) ProtoMessage() {}
+
+func (x *GetServerInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServerInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetServerInfoRequest) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{74}
+}
+
+type GetServerInfoResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ApiVersion        string                 `protobuf:"bytes,1,opt,name=api_version,json=apiVersion,proto3" json:"api_version,omitempty"`                      // protobuf package version, e.g. "v1"
+	ServerVersion     string                 `protobuf:"bytes,2,opt,name=server_version,json=serverVersion,proto3" json:"server_version,omitempty"`             // backend release
+	Capabilities      []string               `protobuf:"bytes,3,rep,name=capabilities,proto3" json:"capabilities,omitempty"`                                    // optional features the backend supports
+	DeprecatedMethods []string               `protobuf:"bytes,4,rep,name=deprecated_methods,json=deprecatedMethods,proto3" json:"deprecated_methods,omitempty"` // full method names that still work but will be removed
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *GetServerInfoResponse) Reset() {
+	*x = GetServerInfoResponse{}
+	mi := &file_iot_v1_sensor_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServerInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServerInfoResponse) ProtoMessage() {}
+
+func (x *GetServerInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_iot_v1_sensor_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServerInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetServerInfoResponse) Descriptor() ([]byte, []int) {
+	return file_iot_v1_sensor_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *GetServerInfoResponse) GetApiVersion() string {
+	if x != nil {
+		return x.ApiVersion
+	}
+	return ""
+}
+
+func (x *GetServerInfoResponse) GetServerVersion() string {
+	if x != nil {
+		return x.ServerVersion
+	}
+	return ""
+}
+
+func (x *GetServerInfoResponse) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *GetServerInfoResponse) GetDeprecatedMethods() []string {
+	if x != nil {
+		return x.DeprecatedMethods
+	}
+	return nil
+}
+
+var File_iot_v1_sensor_proto protoreflect.FileDescriptor
+
+const file_iot_v1_sensor_proto_rawDesc = "" +
+	"\n" +
+	"\x13iot/v1/sensor.proto\x12\x06iot.v1\x1a\x17validate/validate.proto\"\xc9\x01\n" +
+	"\rSensorReading\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12 \n" +
+	"\vtemperature\x18\x03 \x01(\x01R\vtemperature\x12\x1a\n" +
+	"\bhumidity\x18\x04 \x01(\x01R\bhumidity\x12\x1a\n" +
+	"\bpressure\x18\x05 \x01(\x01R\bpressure\x12#\n" +
+	"\rbattery_level\x18\x06 \x01(\x01R\fbatteryLevel\"G\n" +
+	"\x12SensorReadingBatch\x121\n" +
+	"\breadings\x18\x01 \x03(\v2\x15.iot.v1.SensorReadingR\breadings\"\xdf\x01\n" +
+	"!GetSensorReadingByDeviceIDRequest\x12<\n" +
+	"\tdevice_id\x18\x01 \x01(\tB\x1f\xfaB\x1cr\x1a\x10\x01\x18\x80\x012\x13^[^\\x00-\\x1f\\x7f]*$R\bdeviceId\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12$\n" +
+	"\tpage_size\x18\x03 \x01(\x05B\a\xfaB\x04\x1a\x02(\x00R\bpageSize\x12\x19\n" +
+	"\border_by\x18\x04 \x01(\tR\aorderBy\x12\x1c\n" +
+	"\tascending\x18\x05 \x01(\bR\tascending\"}\n" +
+	"\"GetSensorReadingByDeviceIDResponse\x12/\n" +
+	"\areading\x18\x01 \x03(\v2\x15.iot.v1.SensorReadingR\areading\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"T\n" +
+	"\x14CountReadingsRequest\x12<\n" +
+	"\tdevice_id\x18\x01 \x01(\tB\x1f\xfaB\x1cr\x1a\x10\x01\x18\x80\x012\x13^[^\\x00-\\x1f\\x7f]*$R\bdeviceId\"-\n" +
+	"\x15CountReadingsResponse\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\"\xc7\x01\n" +
+	"\"GetSensorReadingSeriesBatchRequest\x12G\n" +
+	"\n" +
+	"device_ids\x18\x01 \x03(\tB(\xfaB%\x92\x01\"\b\x01\x10\n" +
+	"\"\x1cr\x1a\x10\x01\x18\x80\x012\x13^[^\\x00-\\x1f\\x7f]*$R\tdeviceIds\x12-\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\x03B\x0e\xfaB\v\"\t\x18\xff\x82\xd1\xff\xaf\a(\x00R\tstartTime\x12)\n" +
+	"\bend_time\x18\x03 \x01(\x03B\x0e\xfaB\v\"\t\x18\xff\x82\xd1\xff\xaf\a(\x00R\aendTime\"\xb6\x01\n" +
+	"\x15ExportReadingsRequest\x12C\n" +
+	"\n" +
+	"device_ids\x18\x01 \x03(\tB$\xfaB!\x92\x01\x1e\"\x1cr\x1a\x10\x01\x18\x80\x012\x13^[^\\x00-\\x1f\\x7f]*$R\tdeviceIds\x12-\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\x03B\x0e\xfaB\v\"\t\x18\xff\x82\xd1\xff\xaf\a(\x00R\tstartTime\x12)\n" +
+	"\bend_time\x18\x03 \x01(\x03B\x0e\xfaB\v\"\t\x18\xff\x82\xd1\xff\xaf\a(\x00R\aendTime\"i\n" +
+	"\x16ExportReadingsResponse\x12\x1d\n" +
+	"\n" +
+	"object_url\x18\x01 \x01(\tR\tobjectUrl\x12\x1a\n" +
+	"\breadings\x18\x02 \x01(\x03R\breadings\x12\x14\n" +
+	"\x05bytes\x18\x03 \x01(\x03R\x05bytes\"C\n" +
+	"\vMetricStats\x12\x10\n" +
+	"\x03min\x18\x01 \x01(\x01R\x03min\x12\x10\n" +
+	"\x03max\x18\x02 \x01(\x01R\x03max\x12\x10\n" +
+	"\x03avg\x18\x03 \x01(\x01R\x03avg\"\xf6\x01\n" +
+	"\vSeriesStats\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\x125\n" +
+	"\vtemperature\x18\x02 \x01(\v2\x13.iot.v1.MetricStatsR\vtemperature\x12/\n" +
+	"\bhumidity\x18\x03 \x01(\v2\x13.iot.v1.MetricStatsR\bhumidity\x12/\n" +
+	"\bpressure\x18\x04 \x01(\v2\x13.iot.v1.MetricStatsR\bpressure\x128\n" +
+	"\rbattery_level\x18\x05 \x01(\v2\x13.iot.v1.MetricStatsR\fbatteryLevel\"\x90\x01\n" +
+	"\x13SensorReadingSeries\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x121\n" +
+	"\breadings\x18\x02 \x03(\v2\x15.iot.v1.SensorReadingR\breadings\x12)\n" +
+	"\x05stats\x18\x03 \x01(\v2\x13.iot.v1.SeriesStatsR\x05stats\"z\n" +
+	"#GetSensorReadingSeriesBatchResponse\x123\n" +
+	"\x06series\x18\x01 \x03(\v2\x1b.iot.v1.SensorReadingSeriesR\x06series\x12\x1e\n" +
+	"\n" +
+	"resolution\x18\x02 \x01(\tR\n" +
+	"resolution\"\x92\x01\n" +
+	"\x19GetReadingsHeatmapRequest\x12\x1b\n" +
+	"\tcell_size\x18\x01 \x01(\x01R\bcellSize\x12-\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\x03B\x0e\xfaB\v\"\t\x18\xff\x82\xd1\xff\xaf\a(\x00R\tstartTime\x12)\n" +
+	"\bend_time\x18\x03 \x01(\x03B\x0e\xfaB\v\"\t\x18\xff\x82\xd1\xff\xaf\a(\x00R\aendTime\"\xdb\x01\n" +
+	"\vHeatmapCell\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\x12'\n" +
+	"\x0favg_temperature\x18\x03 \x01(\x01R\x0eavgTemperature\x12!\n" +
+	"\favg_humidity\x18\x04 \x01(\x01R\vavgHumidity\x12#\n" +
+	"\rreading_count\x18\x05 \x01(\x03R\freadingCount\x12!\n" +
+	"\fdevice_count\x18\x06 \x01(\x03R\vdeviceCount\"\x84\x01\n" +
+	"\x1aGetReadingsHeatmapResponse\x12)\n" +
+	"\x05cells\x18\x01 \x03(\v2\x13.iot.v1.HeatmapCellR\x05cells\x12\x1b\n" +
+	"\tcell_size\x18\x02 \x01(\x01R\bcellSize\x12\x1e\n" +
+	"\n" +
+	"resolution\x18\x03 \x01(\tR\n" +
+	"resolution\"\xbc\x02\n" +
+	"\tAlertRule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06metric\x18\x03 \x01(\tR\x06metric\x12\x1a\n" +
+	"\boperator\x18\x04 \x01(\tR\boperator\x12\x1c\n" +
+	"\tthreshold\x18\x05 \x01(\x01R\tthreshold\x12\x1b\n" +
+	"\tdevice_id\x18\x06 \x01(\tR\bdeviceId\x12\x18\n" +
+	"\aenabled\x18\a \x01(\bR\aenabled\x12#\n" +
+	"\rsilence_start\x18\b \x01(\x03R\fsilenceStart\x12\x1f\n" +
+	"\vsilence_end\x18\t \x01(\x03R\n" +
+	"silenceEnd\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\x03R\tupdatedAt\"\x17\n" +
+	"\x15ListAlertRulesRequest\"A\n" +
+	"\x16ListAlertRulesResponse\x12'\n" +
+	"\x05rules\x18\x01 \x03(\v2\x11.iot.v1.AlertRuleR\x05rules\"%\n" +
+	"\x13GetAlertRuleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"=\n" +
+	"\x14GetAlertRuleResponse\x12%\n" +
+	"\x04rule\x18\x01 \x01(\v2\x11.iot.v1.AlertRuleR\x04rule\"?\n" +
+	"\x16CreateAlertRuleRequest\x12%\n" +
+	"\x04rule\x18\x01 \x01(\v2\x11.iot.v1.AlertRuleR\x04rule\"@\n" +
+	"\x17CreateAlertRuleResponse\x12%\n" +
+	"\x04rule\x18\x01 \x01(\v2\x11.iot.v1.AlertRuleR\x04rule\"?\n" +
+	"\x16UpdateAlertRuleRequest\x12%\n" +
+	"\x04rule\x18\x01 \x01(\v2\x11.iot.v1.AlertRuleR\x04rule\"@\n" +
+	"\x17UpdateAlertRuleResponse\x12%\n" +
+	"\x04rule\x18\x01 \x01(\v2\x11.iot.v1.AlertRuleR\x04rule\"(\n" +
+	"\x16DeleteAlertRuleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\x19\n" +
+	"\x17DeleteAlertRuleResponse\"\xca\x01\n" +
+	"\n" +
+	"DeviceNote\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\tR\x04body\x12%\n" +
+	"\x0eattachment_url\x18\x05 \x01(\tR\rattachmentUrl\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\x03R\tupdatedAt\"V\n" +
+	"\x16ListDeviceNotesRequest\x12<\n" +
+	"\tdevice_id\x18\x01 \x01(\tB\x1f\xfaB\x1cr\x1a\x10\x01\x18\x80\x012\x13^[^\\x00-\\x1f\\x7f]*$R\bdeviceId\"C\n" +
+	"\x17ListDeviceNotesResponse\x12(\n" +
+	"\x05notes\x18\x01 \x03(\v2\x12.iot.v1.DeviceNoteR\x05notes\"A\n" +
+	"\x17CreateDeviceNoteRequest\x12&\n" +
+	"\x04note\x18\x01 \x01(\v2\x12.iot.v1.DeviceNoteR\x04note\"B\n" +
+	"\x18CreateDeviceNoteResponse\x12&\n" +
+	"\x04note\x18\x01 \x01(\v2\x12.iot.v1.DeviceNoteR\x04note\"A\n" +
+	"\x17UpdateDeviceNoteRequest\x12&\n" +
+	"\x04note\x18\x01 \x01(\v2\x12.iot.v1.DeviceNoteR\x04note\"B\n" +
+	"\x18UpdateDeviceNoteResponse\x12&\n" +
+	"\x04note\x18\x01 \x01(\v2\x12.iot.v1.DeviceNoteR\x04note\")\n" +
+	"\x17DeleteDeviceNoteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\x1a\n" +
+	"\x18DeleteDeviceNoteResponse\"U\n" +
+	"\x14GetQuotaUsageRequest\x12=\n" +
+	"\tdevice_id\x18\x01 \x01(\tB \xfaB\x1dr\x1b\x18\x80\x012\x13^[^\\x00-\\x1f\\x7f]*$\xd0\x01\x01R\bdeviceId\"\x99\x03\n" +
+	"\x15GetQuotaUsageResponse\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12#\n" +
+	"\rrequests_used\x18\x02 \x01(\x03R\frequestsUsed\x12%\n" +
+	"\x0erequests_limit\x18\x03 \x01(\x03R\rrequestsLimit\x120\n" +
+	"\x14device_requests_used\x18\x04 \x01(\x03R\x12deviceRequestsUsed\x122\n" +
+	"\x15device_requests_limit\x18\x05 \x01(\x03R\x13deviceRequestsLimit\x12(\n" +
+	"\x10export_rows_used\x18\x06 \x01(\x03R\x0eexportRowsUsed\x12*\n" +
+	"\x11export_rows_limit\x18\a \x01(\x03R\x0fexportRowsLimit\x12*\n" +
+	"\x11requests_reset_at\x18\b \x01(\x03R\x0frequestsResetAt\x12/\n" +
+	"\x14export_rows_reset_at\x18\t \x01(\x03R\x11exportRowsResetAt\"\xc8\x02\n" +
+	"\tIoTDevice\x12<\n" +
+	"\tdevice_id\x18\x01 \x01(\tB\x1f\xfaB\x1cr\x1a\x10\x01\x18\x80\x012\x13^[^\\x00-\\x1f\\x7f]*$R\bdeviceId\x12,\n" +
+	"\ttimestamp\x18\x02 \x01(\x03B\x0e\xfaB\v\"\t\x18\xff\x82\xd1\xff\xaf\a(\x00R\ttimestamp\x12\x1a\n" +
+	"\blocation\x18\x03 \x01(\tR\blocation\x12\x1f\n" +
+	"\vmac_address\x18\x04 \x01(\tR\n" +
+	"macAddress\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x05 \x01(\tR\tipAddress\x12\x1a\n" +
+	"\bfirmware\x18\x06 \x01(\tR\bfirmware\x12\x1a\n" +
+	"\blatitude\x18\a \x01(\x02R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\b \x01(\x02R\tlongitude\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\t \x01(\x03R\tdeletedAt\"R\n" +
+	"\x1bCreateOrUpdateDeviceRequest\x123\n" +
+	"\x06device\x18\x01 \x01(\v2\x11.iot.v1.IoTDeviceB\b\xfaB\x05\x8a\x01\x02\x10\x01R\x06device\"c\n" +
+	"\x1cCreateOrUpdateDeviceResponse\x12)\n" +
+	"\x06device\x18\x01 \x01(\v2\x11.iot.v1.IoTDeviceR\x06device\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\bR\acreated\"D\n" +
+	"\x15GetAllDevicesResponse\x12+\n" +
+	"\adevices\x18\x01 \x03(\v2\x11.iot.v1.IoTDeviceR\adevices\"\x16\n" +
+	"\x14GetAllDevicesRequest\"T\n" +
+	"\x14GetDeviceByIDRequest\x12<\n" +
+	"\tdevice_id\x18\x01 \x01(\tB\x1f\xfaB\x1cr\x1a\x10\x01\x18\x80\x012\x13^[^\\x00-\\x1f\\x7f]*$R\bdeviceId\"\xce\x01\n" +
+	"\x11BatteryProjection\x12\"\n" +
+	"\rdrain_per_day\x18\x01 \x01(\x01R\vdrainPerDay\x12#\n" +
+	"\rbattery_level\x18\x02 \x01(\x01R\fbatteryLevel\x12,\n" +
+	"\x12projected_empty_at\x18\x03 \x01(\x03R\x10projectedEmptyAt\x12\x1f\n" +
+	"\vcomputed_at\x18\x04 \x01(\x03R\n" +
+	"computedAt\x12!\n" +
+	"\fsample_count\x18\x05 \x01(\x03R\vsampleCount\"\x8c\x01\n" +
+	"\x15GetDeviceByIDResponse\x12)\n" +
+	"\x06device\x18\x01 \x01(\v2\x11.iot.v1.IoTDeviceR\x06device\x12H\n" +
+	"\x12battery_projection\x18\x02 \x01(\v2\x19.iot.v1.BatteryProjectionR\x11batteryProjection\"K\n" +
+	"\x1cListLowBatteryDevicesRequest\x12+\n" +
+	"\vwithin_days\x18\x01 \x01(\x05B\n" +
+	"\xfaB\a\x1a\x05\x18\xed\x02(\x00R\n" +
+	"withinDays\"\x87\x01\n" +
+	"\x10LowBatteryDevice\x12)\n" +
+	"\x06device\x18\x01 \x01(\v2\x11.iot.v1.IoTDeviceR\x06device\x12H\n" +
+	"\x12battery_projection\x18\x02 \x01(\v2\x19.iot.v1.BatteryProjectionR\x11batteryProjection\"S\n" +
+	"\x1dListLowBatteryDevicesResponse\x122\n" +
+	"\adevices\x18\x01 \x03(\v2\x18.iot.v1.LowBatteryDeviceR\adevices\"\x87\x01\n" +
+	"\x0eDeviceLocation\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x02R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x02R\tlongitude\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x03 \x01(\tR\tipAddress\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\"\x81\x01\n" +
+	"\x1fGetDeviceLocationHistoryRequest\x12<\n" +
+	"\tdevice_id\x18\x01 \x01(\tB\x1f\xfaB\x1cr\x1a\x10\x01\x18\x80\x012\x13^[^\\x00-\\x1f\\x7f]*$R\bdeviceId\x12 \n" +
+	"\x05limit\x18\x02 \x01(\x05B\n" +
+	"\xfaB\a\x1a\x05\x18\xe8\a(\x00R\x05limit\"X\n" +
+	" GetDeviceLocationHistoryResponse\x124\n" +
+	"\tlocations\x18\x01 \x03(\v2\x16.iot.v1.DeviceLocationR\tlocations\"\x9d\x02\n" +
+	"\fDeviceChange\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x1a\n" +
+	"\blocation\x18\x03 \x01(\tR\blocation\x12\x1f\n" +
+	"\vmac_address\x18\x04 \x01(\tR\n" +
+	"macAddress\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x05 \x01(\tR\tipAddress\x12\x1a\n" +
+	"\bfirmware\x18\x06 \x01(\tR\bfirmware\x12\x1a\n" +
+	"\blatitude\x18\a \x01(\x02R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\b \x01(\x02R\tlongitude\x12%\n" +
+	"\x0echanged_fields\x18\t \x03(\tR\rchangedFields\"y\n" +
+	"\x17GetDeviceHistoryRequest\x12<\n" +
+	"\tdevice_id\x18\x01 \x01(\tB\x1f\xfaB\x1cr\x1a\x10\x01\x18\x80\x012\x13^[^\\x00-\\x1f\\x7f]*$R\bdeviceId\x12 \n" +
+	"\x05limit\x18\x02 \x01(\x05B\n" +
+	"\xfaB\a\x1a\x05\x18\xe8\a(\x00R\x05limit\"J\n" +
+	"\x18GetDeviceHistoryResponse\x12.\n" +
+	"\achanges\x18\x01 \x03(\v2\x14.iot.v1.DeviceChangeR\achanges\"\x84\x03\n" +
+	"\x0eReportSchedule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1c\n" +
+	"\tfrequency\x18\x03 \x01(\tR\tfrequency\x12\x16\n" +
+	"\x06format\x18\x04 \x01(\tR\x06format\x12\x1f\n" +
+	"\vwebhook_url\x18\x05 \x01(\tR\n" +
+	"webhookUrl\x12\x14\n" +
+	"\x05email\x18\x06 \x01(\tR\x05email\x12\x18\n" +
+	"\aenabled\x18\a \x01(\bR\aenabled\x12\x1e\n" +
+	"\vnext_run_at\x18\b \x01(\x03R\tnextRunAt\x12 \n" +
+	"\flast_sent_at\x18\t \x01(\x03R\n" +
+	"lastSentAt\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\n" +
+	" \x01(\tR\tlastError\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\v \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\f \x01(\x03R\tupdatedAt\x12(\n" +
+	"\x10export_to_bucket\x18\r \x01(\bR\x0eexportToBucket\"\x1c\n" +
+	"\x1aListReportSchedulesRequest\"S\n" +
+	"\x1bListReportSchedulesResponse\x124\n" +
+	"\tschedules\x18\x01 \x03(\v2\x16.iot.v1.ReportScheduleR\tschedules\"Q\n" +
+	"\x1bCreateReportScheduleRequest\x122\n" +
+	"\bschedule\x18\x01 \x01(\v2\x16.iot.v1.ReportScheduleR\bschedule\"R\n" +
+	"\x1cCreateReportScheduleResponse\x122\n" +
+	"\bschedule\x18\x01 \x01(\v2\x16.iot.v1.ReportScheduleR\bschedule\"Q\n" +
+	"\x1bUpdateReportScheduleRequest\x122\n" +
+	"\bschedule\x18\x01 \x01(\v2\x16.iot.v1.ReportScheduleR\bschedule\"R\n" +
+	"\x1cUpdateReportScheduleResponse\x122\n" +
+	"\bschedule\x18\x01 \x01(\v2\x16.iot.v1.ReportScheduleR\bschedule\"-\n" +
+	"\x1bDeleteReportScheduleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\x1e\n" +
+	"\x1cDeleteReportScheduleResponse\"S\n" +
+	"\x13DeleteDeviceRequest\x12<\n" +
+	"\tdevice_id\x18\x01 \x01(\tB\x1f\xfaB\x1cr\x1a\x10\x01\x18\x80\x012\x13^[^\\x00-\\x1f\\x7f]*$R\bdeviceId\"\x16\n" +
+	"\x14DeleteDeviceResponse\"T\n" +
+	"\x14RestoreDeviceRequest\x12<\n" +
+	"\tdevice_id\x18\x01 \x01(\tB\x1f\xfaB\x1cr\x1a\x10\x01\x18\x80\x012\x13^[^\\x00-\\x1f\\x7f]*$R\bdeviceId\"B\n" +
+	"\x15RestoreDeviceResponse\x12)\n" +
+	"\x06device\x18\x01 \x01(\v2\x11.iot.v1.IoTDeviceR\x06device\"\x1b\n" +
+	"\x19ListDeletedDevicesRequest\"I\n" +
+	"\x1aListDeletedDevicesResponse\x12+\n" +
+	"\adevices\x18\x01 \x03(\v2\x11.iot.v1.IoTDeviceR\adevices\"8\n" +
+	"\x17GetStatusSummaryRequest\x12\x1d\n" +
+	"\x04days\x18\x01 \x01(\x05B\t\xfaB\x06\x1a\x04\x18Z(\x00R\x04days\"T\n" +
+	"\vDailyUptime\x12\x10\n" +
+	"\x03day\x18\x01 \x01(\x03R\x03day\x12\x16\n" +
+	"\x06checks\x18\x02 \x01(\x03R\x06checks\x12\x1b\n" +
+	"\tup_checks\x18\x03 \x01(\x03R\bupChecks\"\xaa\x01\n" +
+	"\x0fComponentStatus\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x0e\n" +
+	"\x02up\x18\x02 \x01(\bR\x02up\x12\x1d\n" +
+	"\n" +
+	"checked_at\x18\x03 \x01(\x03R\tcheckedAt\x12%\n" +
+	"\x0euptime_percent\x18\x04 \x01(\x01R\ruptimePercent\x12-\n" +
+	"\ahistory\x18\x05 \x03(\v2\x13.iot.v1.DailyUptimeR\ahistory\"\xd6\x01\n" +
+	"\x18GetStatusSummaryResponse\x12!\n" +
+	"\fdevice_count\x18\x01 \x01(\x03R\vdeviceCount\x12.\n" +
+	"\x13active_device_count\x18\x02 \x01(\x03R\x11activeDeviceCount\x12.\n" +
+	"\x13readings_per_minute\x18\x03 \x01(\x01R\x11readingsPerMinute\x127\n" +
+	"\n" +
+	"components\x18\x04 \x03(\v2\x17.iot.v1.ComponentStatusR\n" +
+	"components\"\x16\n" +
+	"\x14GetServerInfoRequest\"\xb2\x01\n" +
+	"\x15GetServerInfoResponse\x12\x1f\n" +
+	"\vapi_version\x18\x01 \x01(\tR\n" +
+	"apiVersion\x12%\n" +
+	"\x0eserver_version\x18\x02 \x01(\tR\rserverVersion\x12\"\n" +
+	"\fcapabilities\x18\x03 \x03(\tR\fcapabilities\x12-\n" +
+	"\x12deprecated_methods\x18\x04 \x03(\tR\x11deprecatedMethods2\xba\x15\n" +
+	"\n" +
+	"IoTService\x12M\n" +
+	"\fGetAllDevice\x12\x1c.iot.v1.GetAllDevicesRequest\x1a\x1d.iot.v1.GetAllDevicesResponse\"\x00\x12J\n" +
+	"\tGetDevice\x12\x1c.iot.v1.GetDeviceByIDRequest\x1a\x1d.iot.v1.GetDeviceByIDResponse\"\x00\x12u\n" +
+	"\x1aGetSensorReadingByDeviceID\x12).iot.v1.GetSensorReadingByDeviceIDRequest\x1a*.iot.v1.GetSensorReadingByDeviceIDResponse\"\x00\x12N\n" +
+	"\rCountReadings\x12\x1c.iot.v1.CountReadingsRequest\x1a\x1d.iot.v1.CountReadingsResponse\"\x00\x12x\n" +
+	"\x1bGetSensorReadingSeriesBatch\x12*.iot.v1.GetSensorReadingSeriesBatchRequest\x1a+.iot.v1.GetSensorReadingSeriesBatchResponse\"\x00\x12Q\n" +
+	"\x0eListAlertRules\x12\x1d.iot.v1.ListAlertRulesRequest\x1a\x1e.iot.v1.ListAlertRulesResponse\"\x00\x12K\n" +
+	"\fGetAlertRule\x12\x1b.iot.v1.GetAlertRuleRequest\x1a\x1c.iot.v1.GetAlertRuleResponse\"\x00\x12T\n" +
+	"\x0fCreateAlertRule\x12\x1e.iot.v1.CreateAlertRuleRequest\x1a\x1f.iot.v1.CreateAlertRuleResponse\"\x00\x12T\n" +
+	"\x0fUpdateAlertRule\x12\x1e.iot.v1.UpdateAlertRuleRequest\x1a\x1f.iot.v1.UpdateAlertRuleResponse\"\x00\x12T\n" +
+	"\x0fDeleteAlertRule\x12\x1e.iot.v1.DeleteAlertRuleRequest\x1a\x1f.iot.v1.DeleteAlertRuleResponse\"\x00\x12N\n" +
+	"\rGetQuotaUsage\x12\x1c.iot.v1.GetQuotaUsageRequest\x1a\x1d.iot.v1.GetQuotaUsageResponse\"\x00\x12K\n" +
+	"\fDeleteDevice\x12\x1b.iot.v1.DeleteDeviceRequest\x1a\x1c.iot.v1.DeleteDeviceResponse\"\x00\x12N\n" +
+	"\rRestoreDevice\x12\x1c.iot.v1.RestoreDeviceRequest\x1a\x1d.iot.v1.RestoreDeviceResponse\"\x00\x12]\n" +
+	"\x12ListDeletedDevices\x12!.iot.v1.ListDeletedDevicesRequest\x1a\".iot.v1.ListDeletedDevicesResponse\"\x00\x12T\n" +
+	"\x0fListDeviceNotes\x12\x1e.iot.v1.ListDeviceNotesRequest\x1a\x1f.iot.v1.ListDeviceNotesResponse\"\x00\x12W\n" +
+	"\x10CreateDeviceNote\x12\x1f.iot.v1.CreateDeviceNoteRequest\x1a .iot.v1.CreateDeviceNoteResponse\"\x00\x12W\n" +
+	"\x10UpdateDeviceNote\x12\x1f.iot.v1.UpdateDeviceNoteRequest\x1a .iot.v1.UpdateDeviceNoteResponse\"\x00\x12W\n" +
+	"\x10DeleteDeviceNote\x12\x1f.iot.v1.DeleteDeviceNoteRequest\x1a .iot.v1.DeleteDeviceNoteResponse\"\x00\x12f\n" +
+	"\x15ListLowBatteryDevices\x12$.iot.v1.ListLowBatteryDevicesRequest\x1a%.iot.v1.ListLowBatteryDevicesResponse\"\x00\x12`\n" +
+	"\x13ListReportSchedules\x12\".iot.v1.ListReportSchedulesRequest\x1a#.iot.v1.ListReportSchedulesResponse\"\x00\x12c\n" +
+	"\x14CreateReportSchedule\x12#.iot.v1.CreateReportScheduleRequest\x1a$.iot.v1.CreateReportScheduleResponse\"\x00\x12c\n" +
+	"\x14UpdateReportSchedule\x12#.iot.v1.UpdateReportScheduleRequest\x1a$.iot.v1.UpdateReportScheduleResponse\"\x00\x12c\n" +
+	"\x14DeleteReportSchedule\x12#.iot.v1.DeleteReportScheduleRequest\x1a$.iot.v1.DeleteReportScheduleResponse\"\x00\x12o\n" +
+	"\x18GetDeviceLocationHistory\x12'.iot.v1.GetDeviceLocationHistoryRequest\x1a(.iot.v1.GetDeviceLocationHistoryResponse\"\x00\x12]\n" +
+	"\x12GetReadingsHeatmap\x12!.iot.v1.GetReadingsHeatmapRequest\x1a\".iot.v1.GetReadingsHeatmapResponse\"\x00\x12W\n" +
+	"\x10GetStatusSummary\x12\x1f.iot.v1.GetStatusSummaryRequest\x1a .iot.v1.GetStatusSummaryResponse\"\x00\x12Q\n" +
+	"\x0eExportReadings\x12\x1d.iot.v1.ExportReadingsRequest\x1a\x1e.iot.v1.ExportReadingsResponse\"\x00\x12N\n" +
+	"\rGetServerInfo\x12\x1c.iot.v1.GetServerInfoRequest\x1a\x1d.iot.v1.GetServerInfoResponse\"\x00\x12c\n" +
+	"\x14CreateOrUpdateDevice\x12#.iot.v1.CreateOrUpdateDeviceRequest\x1a$.iot.v1.CreateOrUpdateDeviceResponse\"\x00\x12W\n" +
+	"\x10GetDeviceHistory\x12\x1f.iot.v1.GetDeviceHistoryRequest\x1a .iot.v1.GetDeviceHistoryResponse\"\x00B(Z&procodus.dev/demo-app/pkg/iot/v1;iotv1b\x06proto3"
+
+var (
+	file_iot_v1_sensor_proto_rawDescOnce sync.Once
+	file_iot_v1_sensor_proto_rawDescData []byte
+)
+
+func file_iot_v1_sensor_proto_rawDescGZIP() []byte {
+	file_iot_v1_sensor_proto_rawDescOnce.Do(func() {
+		file_iot_v1_sensor_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_iot_v1_sensor_proto_rawDesc), len(file_iot_v1_sensor_proto_rawDesc)))
+	})
+	return file_iot_v1_sensor_proto_rawDescData
+}
+
+var file_iot_v1_sensor_proto_msgTypes = make([]protoimpl.MessageInfo, 76)
+var file_iot_v1_sensor_proto_goTypes = []any{
+	(*SensorReading)(nil),                       // 0: iot.v1.SensorReading
+	(*SensorReadingBatch)(nil),                  // 1: iot.v1.SensorReadingBatch
+	(*GetSensorReadingByDeviceIDRequest)(nil),   // 2: iot.v1.GetSensorReadingByDeviceIDRequest
+	(*GetSensorReadingByDeviceIDResponse)(nil),  // 3: iot.v1.GetSensorReadingByDeviceIDResponse
+	(*CountReadingsRequest)(nil),                // 4: iot.v1.CountReadingsRequest
+	(*CountReadingsResponse)(nil),               // 5: iot.v1.CountReadingsResponse
+	(*GetSensorReadingSeriesBatchRequest)(nil),  // 6: iot.v1.GetSensorReadingSeriesBatchRequest
+	(*ExportReadingsRequest)(nil),               // 7: iot.v1.ExportReadingsRequest
+	(*ExportReadingsResponse)(nil),              // 8: iot.v1.ExportReadingsResponse
+	(*MetricStats)(nil),                         // 9: iot.v1.MetricStats
+	(*SeriesStats)(nil),                         // 10: iot.v1.SeriesStats
+	(*SensorReadingSeries)(nil),                 // 11: iot.v1.SensorReadingSeries
+	(*GetSensorReadingSeriesBatchResponse)(nil), // 12: iot.v1.GetSensorReadingSeriesBatchResponse
+	(*GetReadingsHeatmapRequest)(nil),           // 13: iot.v1.GetReadingsHeatmapRequest
+	(*HeatmapCell)(nil),                         // 14: iot.v1.HeatmapCell
+	(*GetReadingsHeatmapResponse)(nil),          // 15: iot.v1.GetReadingsHeatmapResponse
+	(*AlertRule)(nil),                           // 16: iot.v1.AlertRule
+	(*ListAlertRulesRequest)(nil),               // 17: iot.v1.ListAlertRulesRequest
+	(*ListAlertRulesResponse)(nil),              // 18: iot.v1.ListAlertRulesResponse
+	(*GetAlertRuleRequest)(nil),                 // 19: iot.v1.GetAlertRuleRequest
+	(*GetAlertRuleResponse)(nil),                // 20: iot.v1.GetAlertRuleResponse
+	(*CreateAlertRuleRequest)(nil),              // 21: iot.v1.CreateAlertRuleRequest
+	(*CreateAlertRuleResponse)(nil),             // 22: iot.v1.CreateAlertRuleResponse
+	(*UpdateAlertRuleRequest)(nil),              // 23: iot.v1.UpdateAlertRuleRequest
+	(*UpdateAlertRuleResponse)(nil),             // 24: iot.v1.UpdateAlertRuleResponse
+	(*DeleteAlertRuleRequest)(nil),              // 25: iot.v1.DeleteAlertRuleRequest
+	(*DeleteAlertRuleResponse)(nil),             // 26: iot.v1.DeleteAlertRuleResponse
+	(*DeviceNote)(nil),                          // 27: iot.v1.DeviceNote
+	(*ListDeviceNotesRequest)(nil),              // 28: iot.v1.ListDeviceNotesRequest
+	(*ListDeviceNotesResponse)(nil),             // 29: iot.v1.ListDeviceNotesResponse
+	(*CreateDeviceNoteRequest)(nil),             // 30: iot.v1.CreateDeviceNoteRequest
+	(*CreateDeviceNoteResponse)(nil),            // 31: iot.v1.CreateDeviceNoteResponse
+	(*UpdateDeviceNoteRequest)(nil),             // 32: iot.v1.UpdateDeviceNoteRequest
+	(*UpdateDeviceNoteResponse)(nil),            // 33: iot.v1.UpdateDeviceNoteResponse
+	(*DeleteDeviceNoteRequest)(nil),             // 34: iot.v1.DeleteDeviceNoteRequest
+	(*DeleteDeviceNoteResponse)(nil),            // 35: iot.v1.DeleteDeviceNoteResponse
+	(*GetQuotaUsageRequest)(nil),                // 36: iot.v1.GetQuotaUsageRequest
+	(*GetQuotaUsageResponse)(nil),               // 37: iot.v1.GetQuotaUsageResponse
+	(*IoTDevice)(nil),                           // 38: iot.v1.IoTDevice
+	(*CreateOrUpdateDeviceRequest)(nil),         // 39: iot.v1.CreateOrUpdateDeviceRequest
+	(*CreateOrUpdateDeviceResponse)(nil),        // 40: iot.v1.CreateOrUpdateDeviceResponse
+	(*GetAllDevicesResponse)(nil),               // 41: iot.v1.GetAllDevicesResponse
+	(*GetAllDevicesRequest)(nil),                // 42: iot.v1.GetAllDevicesRequest
+	(*GetDeviceByIDRequest)(nil),                // 43: iot.v1.GetDeviceByIDRequest
+	(*BatteryProjection)(nil),                   // 44: iot.v1.BatteryProjection
+	(*GetDeviceByIDResponse)(nil),               // 45: iot.v1.GetDeviceByIDResponse
+	(*ListLowBatteryDevicesRequest)(nil),        // 46: iot.v1.ListLowBatteryDevicesRequest
+	(*LowBatteryDevice)(nil),                    // 47: iot.v1.LowBatteryDevice
+	(*ListLowBatteryDevicesResponse)(nil),       // 48: iot.v1.ListLowBatteryDevicesResponse
+	(*DeviceLocation)(nil),                      // 49: iot.v1.DeviceLocation
+	(*GetDeviceLocationHistoryRequest)(nil),     // 50: iot.v1.GetDeviceLocationHistoryRequest
+	(*GetDeviceLocationHistoryResponse)(nil),    // 51: iot.v1.GetDeviceLocationHistoryResponse
+	(*DeviceChange)(nil),                        // 52: iot.v1.DeviceChange
+	(*GetDeviceHistoryRequest)(nil),             // 53: iot.v1.GetDeviceHistoryRequest
+	(*GetDeviceHistoryResponse)(nil),            // 54: iot.v1.GetDeviceHistoryResponse
+	(*ReportSchedule)(nil),                      // 55: iot.v1.ReportSchedule
+	(*ListReportSchedulesRequest)(nil),          // 56: iot.v1.ListReportSchedulesRequest
+	(*ListReportSchedulesResponse)(nil),         // 57: iot.v1.ListReportSchedulesResponse
+	(*CreateReportScheduleRequest)(nil),         // 58: iot.v1.CreateReportScheduleRequest
+	(*CreateReportScheduleResponse)(nil),        // 59: iot.v1.CreateReportScheduleResponse
+	(*UpdateReportScheduleRequest)(nil),         // 60: iot.v1.UpdateReportScheduleRequest
+	(*UpdateReportScheduleResponse)(nil),        // 61: iot.v1.UpdateReportScheduleResponse
+	(*DeleteReportScheduleRequest)(nil),         // 62: iot.v1.DeleteReportScheduleRequest
+	(*DeleteReportScheduleResponse)(nil),        // 63: iot.v1.DeleteReportScheduleResponse
+	(*DeleteDeviceRequest)(nil),                 // 64: iot.v1.DeleteDeviceRequest
+	(*DeleteDeviceResponse)(nil),                // 65: iot.v1.DeleteDeviceResponse
+	(*RestoreDeviceRequest)(nil),                // 66: iot.v1.RestoreDeviceRequest
+	(*RestoreDeviceResponse)(nil),               // 67: iot.v1.RestoreDeviceResponse
+	(*ListDeletedDevicesRequest)(nil),           // 68: iot.v1.ListDeletedDevicesRequest
+	(*ListDeletedDevicesResponse)(nil),          // 69: iot.v1.ListDeletedDevicesResponse
+	(*GetStatusSummaryRequest)(nil),             // 70: iot.v1.GetStatusSummaryRequest
+	(*DailyUptime)(nil),                         // 71: iot.v1.DailyUptime
+	(*ComponentStatus)(nil),                     // 72: iot.v1.ComponentStatus
+	(*GetStatusSummaryResponse)(nil),            // 73: iot.v1.GetStatusSummaryResponse
+	(*GetServerInfoRequest)(nil),                // 74: iot.v1.GetServerInfoRequest
+	(*GetServerInfoResponse)(nil),               // 75: iot.v1.GetServerInfoResponse
+}
+var file_iot_v1_sensor_proto_depIdxs = []int32{
+	0,  // 0: iot.v1.SensorReadingBatch.readings:type_name -> iot.v1.SensorReading
+	0,  // 1: iot.v1.GetSensorReadingByDeviceIDResponse.reading:type_name -> iot.v1.SensorReading
+	9,  // 2: iot.v1.SeriesStats.temperature:type_name -> iot.v1.MetricStats
+	9,  // 3: iot.v1.SeriesStats.humidity:type_name -> iot.v1.MetricStats
+	9,  // 4: iot.v1.SeriesStats.pressure:type_name -> iot.v1.MetricStats
+	9,  // 5: iot.v1.SeriesStats.battery_level:type_name -> iot.v1.MetricStats
+	0,  // 6: iot.v1.SensorReadingSeries.readings:type_name -> iot.v1.SensorReading
+	10, // 7: iot.v1.SensorReadingSeries.stats:type_name -> iot.v1.SeriesStats
+	11, // 8: iot.v1.GetSensorReadingSeriesBatchResponse.series:type_name -> iot.v1.SensorReadingSeries
+	14, // 9: iot.v1.GetReadingsHeatmapResponse.cells:type_name -> iot.v1.HeatmapCell
+	16, // 10: iot.v1.ListAlertRulesResponse.rules:type_name -> iot.v1.AlertRule
+	16, // 11: iot.v1.GetAlertRuleResponse.rule:type_name -> iot.v1.AlertRule
+	16, // 12: iot.v1.CreateAlertRuleRequest.rule:type_name -> iot.v1.AlertRule
+	16, // 13: iot.v1.CreateAlertRuleResponse.rule:type_name -> iot.v1.AlertRule
+	16, // 14: iot.v1.UpdateAlertRuleRequest.rule:type_name -> iot.v1.AlertRule
+	16, // 15: iot.v1.UpdateAlertRuleResponse.rule:type_name -> iot.v1.AlertRule
+	27, // 16: iot.v1.ListDeviceNotesResponse.notes:type_name -> iot.v1.DeviceNote
+	27, // 17: iot.v1.CreateDeviceNoteRequest.note:type_name -> iot.v1.DeviceNote
+	27, // 18: iot.v1.CreateDeviceNoteResponse.note:type_name -> iot.v1.DeviceNote
+	27, // 19: iot.v1.UpdateDeviceNoteRequest.note:type_name -> iot.v1.DeviceNote
+	27, // 20: iot.v1.UpdateDeviceNoteResponse.note:type_name -> iot.v1.DeviceNote
+	38, // 21: iot.v1.CreateOrUpdateDeviceRequest.device:type_name -> iot.v1.IoTDevice
+	38, // 22: iot.v1.CreateOrUpdateDeviceResponse.device:type_name -> iot.v1.IoTDevice
+	38, // 23: iot.v1.GetAllDevicesResponse.devices:type_name -> iot.v1.IoTDevice
+	38, // 24: iot.v1.GetDeviceByIDResponse.device:type_name -> iot.v1.IoTDevice
+	44, // 25: iot.v1.GetDeviceByIDResponse.battery_projection:type_name -> iot.v1.BatteryProjection
+	38, // 26: iot.v1.LowBatteryDevice.device:type_name -> iot.v1.IoTDevice
+	44, // 27: iot.v1.LowBatteryDevice.battery_projection:type_name -> iot.v1.BatteryProjection
+	47, // 28: iot.v1.ListLowBatteryDevicesResponse.devices:type_name -> iot.v1.LowBatteryDevice
+	49, // 29: iot.v1.GetDeviceLocationHistoryResponse.locations:type_name -> iot.v1.DeviceLocation
+	52, // 30: iot.v1.GetDeviceHistoryResponse.changes:type_name -> iot.v1.DeviceChange
+	55, // 31: iot.v1.ListReportSchedulesResponse.schedules:type_name -> iot.v1.ReportSchedule
+	55, // 32: iot.v1.CreateReportScheduleRequest.schedule:type_name -> iot.v1.ReportSchedule
+	55, // 33: iot.v1.CreateReportScheduleResponse.schedule:type_name -> iot.v1.ReportSchedule
+	55, // 34: iot.v1.UpdateReportScheduleRequest.schedule:type_name -> iot.v1.ReportSchedule
+	55, // 35: iot.v1.UpdateReportScheduleResponse.schedule:type_name -> iot.v1.ReportSchedule
+	38, // 36: iot.v1.RestoreDeviceResponse.device:type_name -> iot.v1.IoTDevice
+	38, // 37: iot.v1.ListDeletedDevicesResponse.devices:type_name -> iot.v1.IoTDevice
+	71, // 38: iot.v1.ComponentStatus.history:type_name -> iot.v1.DailyUptime
+	72, // 39: iot.v1.GetStatusSummaryResponse.components:type_name -> iot.v1.ComponentStatus
+	42, // 40: iot.v1.IoTService.GetAllDevice:input_type -> iot.v1.GetAllDevicesRequest
+	43, // 41: iot.v1.IoTService.GetDevice:input_type -> iot.v1.GetDeviceByIDRequest
+	2,  // 42: iot.v1.IoTService.GetSensorReadingByDeviceID:input_type -> iot.v1.GetSensorReadingByDeviceIDRequest
+	4,  // 43: iot.v1.IoTService.CountReadings:input_type -> iot.v1.CountReadingsRequest
+	6,  // 44: iot.v1.IoTService.GetSensorReadingSeriesBatch:input_type -> iot.v1.GetSensorReadingSeriesBatchRequest
+	17, // 45: iot.v1.IoTService.ListAlertRules:input_type -> iot.v1.ListAlertRulesRequest
+	19, // 46: iot.v1.IoTService.GetAlertRule:input_type -> iot.v1.GetAlertRuleRequest
+	21, // 47: iot.v1.IoTService.CreateAlertRule:input_type -> iot.v1.CreateAlertRuleRequest
+	23, // 48: iot.v1.IoTService.UpdateAlertRule:input_type -> iot.v1.UpdateAlertRuleRequest
+	25, // 49: iot.v1.IoTService.DeleteAlertRule:input_type -> iot.v1.DeleteAlertRuleRequest
+	36, // 50: iot.v1.IoTService.GetQuotaUsage:input_type -> iot.v1.GetQuotaUsageRequest
+	64, // 51: iot.v1.IoTService.DeleteDevice:input_type -> iot.v1.DeleteDeviceRequest
+	66, // 52: iot.v1.IoTService.RestoreDevice:input_type -> iot.v1.RestoreDeviceRequest
+	68, // 53: iot.v1.IoTService.ListDeletedDevices:input_type -> iot.v1.ListDeletedDevicesRequest
+	28, // 54: iot.v1.IoTService.ListDeviceNotes:input_type -> iot.v1.ListDeviceNotesRequest
+	30, // 55: iot.v1.IoTService.CreateDeviceNote:input_type -> iot.v1.CreateDeviceNoteRequest
+	32, // 56: iot.v1.IoTService.UpdateDeviceNote:input_type -> iot.v1.UpdateDeviceNoteRequest
+	34, // 57: iot.v1.IoTService.DeleteDeviceNote:input_type -> iot.v1.DeleteDeviceNoteRequest
+	46, // 58: iot.v1.IoTService.ListLowBatteryDevices:input_type -> iot.v1.ListLowBatteryDevicesRequest
+	56, // 59: iot.v1.IoTService.ListReportSchedules:input_type -> iot.v1.ListReportSchedulesRequest
+	58, // 60: iot.v1.IoTService.CreateReportSchedule:input_type -> iot.v1.CreateReportScheduleRequest
+	60, // 61: iot.v1.IoTService.UpdateReportSchedule:input_type -> iot.v1.UpdateReportScheduleRequest
+	62, // 62: iot.v1.IoTService.DeleteReportSchedule:input_type -> iot.v1.DeleteReportScheduleRequest
+	50, // 63: iot.v1.IoTService.GetDeviceLocationHistory:input_type -> iot.v1.GetDeviceLocationHistoryRequest
+	13, // 64: iot.v1.IoTService.GetReadingsHeatmap:input_type -> iot.v1.GetReadingsHeatmapRequest
+	70, // 65: iot.v1.IoTService.GetStatusSummary:input_type -> iot.v1.GetStatusSummaryRequest
+	7,  // 66: iot.v1.IoTService.ExportReadings:input_type -> iot.v1.ExportReadingsRequest
+	74, // 67: iot.v1.IoTService.GetServerInfo:input_type -> iot.v1.GetServerInfoRequest
+	39, // 68: iot.v1.IoTService.CreateOrUpdateDevice:input_type -> iot.v1.CreateOrUpdateDeviceRequest
+	53, // 69: iot.v1.IoTService.GetDeviceHistory:input_type -> iot.v1.GetDeviceHistoryRequest
+	41, // 70: iot.v1.IoTService.GetAllDevice:output_type -> iot.v1.GetAllDevicesResponse
+	45, // 71: iot.v1.IoTService.GetDevice:output_type -> iot.v1.GetDeviceByIDResponse
+	3,  // 72: iot.v1.IoTService.GetSensorReadingByDeviceID:output_type -> iot.v1.GetSensorReadingByDeviceIDResponse
+	5,  // 73: iot.v1.IoTService.CountReadings:output_type -> iot.v1.CountReadingsResponse
+	12, // 74: iot.v1.IoTService.GetSensorReadingSeriesBatch:output_type -> iot.v1.GetSensorReadingSeriesBatchResponse
+	18, // 75: iot.v1.IoTService.ListAlertRules:output_type -> iot.v1.ListAlertRulesResponse
+	20, // 76: iot.v1.IoTService.GetAlertRule:output_type -> iot.v1.GetAlertRuleResponse
+	22, // 77: iot.v1.IoTService.CreateAlertRule:output_type -> iot.v1.CreateAlertRuleResponse
+	24, // 78: iot.v1.IoTService.UpdateAlertRule:output_type -> iot.v1.UpdateAlertRuleResponse
+	26, // 79: iot.v1.IoTService.DeleteAlertRule:output_type -> iot.v1.DeleteAlertRuleResponse
+	37, // 80: iot.v1.IoTService.GetQuotaUsage:output_type -> iot.v1.GetQuotaUsageResponse
+	65, // 81: iot.v1.IoTService.DeleteDevice:output_type -> iot.v1.DeleteDeviceResponse
+	67, // 82: iot.v1.IoTService.RestoreDevice:output_type -> iot.v1.RestoreDeviceResponse
+	69, // 83: iot.v1.IoTService.ListDeletedDevices:output_type -> iot.v1.ListDeletedDevicesResponse
+	29, // 84: iot.v1.IoTService.ListDeviceNotes:output_type -> iot.v1.ListDeviceNotesResponse
+	31, // 85: iot.v1.IoTService.CreateDeviceNote:output_type -> iot.v1.CreateDeviceNoteResponse
+	33, // 86: iot.v1.IoTService.UpdateDeviceNote:output_type -> iot.v1.UpdateDeviceNoteResponse
+	35, // 87: iot.v1.IoTService.DeleteDeviceNote:output_type -> iot.v1.DeleteDeviceNoteResponse
+	48, // 88: iot.v1.IoTService.ListLowBatteryDevices:output_type -> iot.v1.ListLowBatteryDevicesResponse
+	57, // 89: iot.v1.IoTService.ListReportSchedules:output_type -> iot.v1.ListReportSchedulesResponse
+	59, // 90: iot.v1.IoTService.CreateReportSchedule:output_type -> iot.v1.CreateReportScheduleResponse
+	61, // 91: iot.v1.IoTService.UpdateReportSchedule:output_type -> iot.v1.UpdateReportScheduleResponse
+	63, // 92: iot.v1.IoTService.DeleteReportSchedule:output_type -> iot.v1.DeleteReportScheduleResponse
+	51, // 93: iot.v1.IoTService.GetDeviceLocationHistory:output_type -> iot.v1.GetDeviceLocationHistoryResponse
+	15, // 94: iot.v1.IoTService.GetReadingsHeatmap:output_type -> iot.v1.GetReadingsHeatmapResponse
+	73, // 95: iot.v1.IoTService.GetStatusSummary:output_type -> iot.v1.GetStatusSummaryResponse
+	8,  // 96: iot.v1.IoTService.ExportReadings:output_type -> iot.v1.ExportReadingsResponse
+	75, // 97: iot.v1.IoTService.GetServerInfo:output_type -> iot.v1.GetServerInfoResponse
+	40, // 98: iot.v1.IoTService.CreateOrUpdateDevice:output_type -> iot.v1.CreateOrUpdateDeviceResponse
+	54, // 99: iot.v1.IoTService.GetDeviceHistory:output_type -> iot.v1.GetDeviceHistoryResponse
+	70, // [70:100] is the sub-list for method output_type
+	40, // [40:70] is the sub-list for method input_type
+	40, // [40:40] is the sub-list for extension type_name
+	40, // [40:40] is the sub-list for extension extendee
+	0,  // [0:40] is the sub-list for field type_name
+}
+
+func init() { file_iot_v1_sensor_proto_init() }
+func file_iot_v1_sensor_proto_init() {
+	if File_iot_v1_sensor_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_iot_v1_sensor_proto_rawDesc), len(file_iot_v1_sensor_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   76,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_iot_v1_sensor_proto_goTypes,
+		DependencyIndexes: file_iot_v1_sensor_proto_depIdxs,
+		MessageInfos:      file_iot_v1_sensor_proto_msgTypes,
+	}.Build()
+	File_iot_v1_sensor_proto = out.File
+	file_iot_v1_sensor_proto_goTypes = nil
+	file_iot_v1_sensor_proto_depIdxs = nil
+}
@@ -0,0 +1,44 @@
+package iotv1_test
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// benchReading is a typical reading as published by the generator.
+var benchReading = &iotv1.SensorReading{
+	DeviceId:     "3f2b9c1e-8d4a-4e6b-9a7c-1d2e3f4a5b6c",
+	Timestamp:    time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC).Unix(),
+	Temperature:  22.57,
+	Humidity:     61.23,
+	Pressure:     1013.42,
+	BatteryLevel: 87.5,
+}
+
+func BenchmarkSensorReadingMarshal(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := proto.Marshal(benchReading); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSensorReadingUnmarshal(b *testing.B) {
+	data, err := proto.Marshal(benchReading)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		var reading iotv1.SensorReading
+		if err := proto.Unmarshal(data, &reading); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,1207 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: iot/v1/sensor.proto
+
+// Version 1 of the IoT API. Changes must stay backward compatible, which
+// `buf breaking` checks; incompatible changes go into a new iot.v2 package.
+
+package iotv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	IoTService_GetAllDevice_FullMethodName                = "/iot.v1.IoTService/GetAllDevice"
+	IoTService_GetDevice_FullMethodName                   = "/iot.v1.IoTService/GetDevice"
+	IoTService_GetSensorReadingByDeviceID_FullMethodName  = "/iot.v1.IoTService/GetSensorReadingByDeviceID"
+	IoTService_CountReadings_FullMethodName               = "/iot.v1.IoTService/CountReadings"
+	IoTService_GetSensorReadingSeriesBatch_FullMethodName = "/iot.v1.IoTService/GetSensorReadingSeriesBatch"
+	IoTService_ListAlertRules_FullMethodName              = "/iot.v1.IoTService/ListAlertRules"
+	IoTService_GetAlertRule_FullMethodName                = "/iot.v1.IoTService/GetAlertRule"
+	IoTService_CreateAlertRule_FullMethodName             = "/iot.v1.IoTService/CreateAlertRule"
+	IoTService_UpdateAlertRule_FullMethodName             = "/iot.v1.IoTService/UpdateAlertRule"
+	IoTService_DeleteAlertRule_FullMethodName             = "/iot.v1.IoTService/DeleteAlertRule"
+	IoTService_GetQuotaUsage_FullMethodName               = "/iot.v1.IoTService/GetQuotaUsage"
+	IoTService_DeleteDevice_FullMethodName                = "/iot.v1.IoTService/DeleteDevice"
+	IoTService_RestoreDevice_FullMethodName               = "/iot.v1.IoTService/RestoreDevice"
+	IoTService_ListDeletedDevices_FullMethodName          = "/iot.v1.IoTService/ListDeletedDevices"
+	IoTService_ListDeviceNotes_FullMethodName             = "/iot.v1.IoTService/ListDeviceNotes"
+	IoTService_CreateDeviceNote_FullMethodName            = "/iot.v1.IoTService/CreateDeviceNote"
+	IoTService_UpdateDeviceNote_FullMethodName            = "/iot.v1.IoTService/UpdateDeviceNote"
+	IoTService_DeleteDeviceNote_FullMethodName            = "/iot.v1.IoTService/DeleteDeviceNote"
+	IoTService_ListLowBatteryDevices_FullMethodName       = "/iot.v1.IoTService/ListLowBatteryDevices"
+	IoTService_ListReportSchedules_FullMethodName         = "/iot.v1.IoTService/ListReportSchedules"
+	IoTService_CreateReportSchedule_FullMethodName        = "/iot.v1.IoTService/CreateReportSchedule"
+	IoTService_UpdateReportSchedule_FullMethodName        = "/iot.v1.IoTService/UpdateReportSchedule"
+	IoTService_DeleteReportSchedule_FullMethodName        = "/iot.v1.IoTService/DeleteReportSchedule"
+	IoTService_GetDeviceLocationHistory_FullMethodName    = "/iot.v1.IoTService/GetDeviceLocationHistory"
+	IoTService_GetReadingsHeatmap_FullMethodName          = "/iot.v1.IoTService/GetReadingsHeatmap"
+	IoTService_GetStatusSummary_FullMethodName            = "/iot.v1.IoTService/GetStatusSummary"
+	IoTService_ExportReadings_FullMethodName              = "/iot.v1.IoTService/ExportReadings"
+	IoTService_GetServerInfo_FullMethodName               = "/iot.v1.IoTService/GetServerInfo"
+	IoTService_CreateOrUpdateDevice_FullMethodName        = "/iot.v1.IoTService/CreateOrUpdateDevice"
+	IoTService_GetDeviceHistory_FullMethodName            = "/iot.v1.IoTService/GetDeviceHistory"
+)
+
+// IoTServiceClient is the client API for IoTService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IoTServiceClient interface {
+	// Request and response names predate the lint rules and are kept for compatibility.
+	// buf:lint:ignore RPC_REQUEST_STANDARD_NAME
+	// buf:lint:ignore RPC_RESPONSE_STANDARD_NAME
+	GetAllDevice(ctx context.Context, in *GetAllDevicesRequest, opts ...grpc.CallOption) (*GetAllDevicesResponse, error)
+	// buf:lint:ignore RPC_REQUEST_STANDARD_NAME
+	// buf:lint:ignore RPC_RESPONSE_STANDARD_NAME
+	GetDevice(ctx context.Context, in *GetDeviceByIDRequest, opts ...grpc.CallOption) (*GetDeviceByIDResponse, error)
+	GetSensorReadingByDeviceID(ctx context.Context, in *GetSensorReadingByDeviceIDRequest, opts ...grpc.CallOption) (*GetSensorReadingByDeviceIDResponse, error)
+	CountReadings(ctx context.Context, in *CountReadingsRequest, opts ...grpc.CallOption) (*CountReadingsResponse, error)
+	GetSensorReadingSeriesBatch(ctx context.Context, in *GetSensorReadingSeriesBatchRequest, opts ...grpc.CallOption) (*GetSensorReadingSeriesBatchResponse, error)
+	ListAlertRules(ctx context.Context, in *ListAlertRulesRequest, opts ...grpc.CallOption) (*ListAlertRulesResponse, error)
+	GetAlertRule(ctx context.Context, in *GetAlertRuleRequest, opts ...grpc.CallOption) (*GetAlertRuleResponse, error)
+	CreateAlertRule(ctx context.Context, in *CreateAlertRuleRequest, opts ...grpc.CallOption) (*CreateAlertRuleResponse, error)
+	UpdateAlertRule(ctx context.Context, in *UpdateAlertRuleRequest, opts ...grpc.CallOption) (*UpdateAlertRuleResponse, error)
+	DeleteAlertRule(ctx context.Context, in *DeleteAlertRuleRequest, opts ...grpc.CallOption) (*DeleteAlertRuleResponse, error)
+	GetQuotaUsage(ctx context.Context, in *GetQuotaUsageRequest, opts ...grpc.CallOption) (*GetQuotaUsageResponse, error)
+	DeleteDevice(ctx context.Context, in *DeleteDeviceRequest, opts ...grpc.CallOption) (*DeleteDeviceResponse, error)
+	RestoreDevice(ctx context.Context, in *RestoreDeviceRequest, opts ...grpc.CallOption) (*RestoreDeviceResponse, error)
+	ListDeletedDevices(ctx context.Context, in *ListDeletedDevicesRequest, opts ...grpc.CallOption) (*ListDeletedDevicesResponse, error)
+	ListDeviceNotes(ctx context.Context, in *ListDeviceNotesRequest, opts ...grpc.CallOption) (*ListDeviceNotesResponse, error)
+	CreateDeviceNote(ctx context.Context, in *CreateDeviceNoteRequest, opts ...grpc.CallOption) (*CreateDeviceNoteResponse, error)
+	UpdateDeviceNote(ctx context.Context, in *UpdateDeviceNoteRequest, opts ...grpc.CallOption) (*UpdateDeviceNoteResponse, error)
+	DeleteDeviceNote(ctx context.Context, in *DeleteDeviceNoteRequest, opts ...grpc.CallOption) (*DeleteDeviceNoteResponse, error)
+	ListLowBatteryDevices(ctx context.Context, in *ListLowBatteryDevicesRequest, opts ...grpc.CallOption) (*ListLowBatteryDevicesResponse, error)
+	ListReportSchedules(ctx context.Context, in *ListReportSchedulesRequest, opts ...grpc.CallOption) (*ListReportSchedulesResponse, error)
+	CreateReportSchedule(ctx context.Context, in *CreateReportScheduleRequest, opts ...grpc.CallOption) (*CreateReportScheduleResponse, error)
+	UpdateReportSchedule(ctx context.Context, in *UpdateReportScheduleRequest, opts ...grpc.CallOption) (*UpdateReportScheduleResponse, error)
+	DeleteReportSchedule(ctx context.Context, in *DeleteReportScheduleRequest, opts ...grpc.CallOption) (*DeleteReportScheduleResponse, error)
+	GetDeviceLocationHistory(ctx context.Context, in *GetDeviceLocationHistoryRequest, opts ...grpc.CallOption) (*GetDeviceLocationHistoryResponse, error)
+	GetReadingsHeatmap(ctx context.Context, in *GetReadingsHeatmapRequest, opts ...grpc.CallOption) (*GetReadingsHeatmapResponse, error)
+	GetStatusSummary(ctx context.Context, in *GetStatusSummaryRequest, opts ...grpc.CallOption) (*GetStatusSummaryResponse, error)
+	ExportReadings(ctx context.Context, in *ExportReadingsRequest, opts ...grpc.CallOption) (*ExportReadingsResponse, error)
+	// Reports the API version and capabilities so clients can adapt to backends
+	// of other releases. Backends older than this RPC return UNIMPLEMENTED.
+	GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error)
+	// Registers a device or updates its metadata, like a message on the device
+	// queue but synchronously, so readings sent afterwards find the device.
+	CreateOrUpdateDevice(ctx context.Context, in *CreateOrUpdateDeviceRequest, opts ...grpc.CallOption) (*CreateOrUpdateDeviceResponse, error)
+	// Returns how the firmware, location and network address of a device
+	// changed over time, replayed from the device event log.
+	GetDeviceHistory(ctx context.Context, in *GetDeviceHistoryRequest, opts ...grpc.CallOption) (*GetDeviceHistoryResponse, error)
+}
+
+type ioTServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIoTServiceClient(cc grpc.ClientConnInterface) IoTServiceClient {
+	return &ioTServiceClient{cc}
+}
+
+func (c *ioTServiceClient) GetAllDevice(ctx context.Context, in *GetAllDevicesRequest, opts ...grpc.CallOption) (*GetAllDevicesResponse, error) {
+	out := new(GetAllDevicesResponse)
+	err := c.cc.Invoke(ctx, IoTService_GetAllDevice_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) GetDevice(ctx context.Context, in *GetDeviceByIDRequest, opts ...grpc.CallOption) (*GetDeviceByIDResponse, error) {
+	out := new(GetDeviceByIDResponse)
+	err := c.cc.Invoke(ctx, IoTService_GetDevice_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) GetSensorReadingByDeviceID(ctx context.Context, in *GetSensorReadingByDeviceIDRequest, opts ...grpc.CallOption) (*GetSensorReadingByDeviceIDResponse, error) {
+	out := new(GetSensorReadingByDeviceIDResponse)
+	err := c.cc.Invoke(ctx, IoTService_GetSensorReadingByDeviceID_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) CountReadings(ctx context.Context, in *CountReadingsRequest, opts ...grpc.CallOption) (*CountReadingsResponse, error) {
+	out := new(CountReadingsResponse)
+	err := c.cc.Invoke(ctx, IoTService_CountReadings_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) GetSensorReadingSeriesBatch(ctx context.Context, in *GetSensorReadingSeriesBatchRequest, opts ...grpc.CallOption) (*GetSensorReadingSeriesBatchResponse, error) {
+	out := new(GetSensorReadingSeriesBatchResponse)
+	err := c.cc.Invoke(ctx, IoTService_GetSensorReadingSeriesBatch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) ListAlertRules(ctx context.Context, in *ListAlertRulesRequest, opts ...grpc.CallOption) (*ListAlertRulesResponse, error) {
+	out := new(ListAlertRulesResponse)
+	err := c.cc.Invoke(ctx, IoTService_ListAlertRules_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) GetAlertRule(ctx context.Context, in *GetAlertRuleRequest, opts ...grpc.CallOption) (*GetAlertRuleResponse, error) {
+	out := new(GetAlertRuleResponse)
+	err := c.cc.Invoke(ctx, IoTService_GetAlertRule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) CreateAlertRule(ctx context.Context, in *CreateAlertRuleRequest, opts ...grpc.CallOption) (*CreateAlertRuleResponse, error) {
+	out := new(CreateAlertRuleResponse)
+	err := c.cc.Invoke(ctx, IoTService_CreateAlertRule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) UpdateAlertRule(ctx context.Context, in *UpdateAlertRuleRequest, opts ...grpc.CallOption) (*UpdateAlertRuleResponse, error) {
+	out := new(UpdateAlertRuleResponse)
+	err := c.cc.Invoke(ctx, IoTService_UpdateAlertRule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) DeleteAlertRule(ctx context.Context, in *DeleteAlertRuleRequest, opts ...grpc.CallOption) (*DeleteAlertRuleResponse, error) {
+	out := new(DeleteAlertRuleResponse)
+	err := c.cc.Invoke(ctx, IoTService_DeleteAlertRule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) GetQuotaUsage(ctx context.Context, in *GetQuotaUsageRequest, opts ...grpc.CallOption) (*GetQuotaUsageResponse, error) {
+	out := new(GetQuotaUsageResponse)
+	err := c.cc.Invoke(ctx, IoTService_GetQuotaUsage_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) DeleteDevice(ctx context.Context, in *DeleteDeviceRequest, opts ...grpc.CallOption) (*DeleteDeviceResponse, error) {
+	out := new(DeleteDeviceResponse)
+	err := c.cc.Invoke(ctx, IoTService_DeleteDevice_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) RestoreDevice(ctx context.Context, in *RestoreDeviceRequest, opts ...grpc.CallOption) (*RestoreDeviceResponse, error) {
+	out := new(RestoreDeviceResponse)
+	err := c.cc.Invoke(ctx, IoTService_RestoreDevice_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) ListDeletedDevices(ctx context.Context, in *ListDeletedDevicesRequest, opts ...grpc.CallOption) (*ListDeletedDevicesResponse, error) {
+	out := new(ListDeletedDevicesResponse)
+	err := c.cc.Invoke(ctx, IoTService_ListDeletedDevices_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) ListDeviceNotes(ctx context.Context, in *ListDeviceNotesRequest, opts ...grpc.CallOption) (*ListDeviceNotesResponse, error) {
+	out := new(ListDeviceNotesResponse)
+	err := c.cc.Invoke(ctx, IoTService_ListDeviceNotes_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) CreateDeviceNote(ctx context.Context, in *CreateDeviceNoteRequest, opts ...grpc.CallOption) (*CreateDeviceNoteResponse, error) {
+	out := new(CreateDeviceNoteResponse)
+	err := c.cc.Invoke(ctx, IoTService_CreateDeviceNote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) UpdateDeviceNote(ctx context.Context, in *UpdateDeviceNoteRequest, opts ...grpc.CallOption) (*UpdateDeviceNoteResponse, error) {
+	out := new(UpdateDeviceNoteResponse)
+	err := c.cc.Invoke(ctx, IoTService_UpdateDeviceNote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) DeleteDeviceNote(ctx context.Context, in *DeleteDeviceNoteRequest, opts ...grpc.CallOption) (*DeleteDeviceNoteResponse, error) {
+	out := new(DeleteDeviceNoteResponse)
+	err := c.cc.Invoke(ctx, IoTService_DeleteDeviceNote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) ListLowBatteryDevices(ctx context.Context, in *ListLowBatteryDevicesRequest, opts ...grpc.CallOption) (*ListLowBatteryDevicesResponse, error) {
+	out := new(ListLowBatteryDevicesResponse)
+	err := c.cc.Invoke(ctx, IoTService_ListLowBatteryDevices_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) ListReportSchedules(ctx context.Context, in *ListReportSchedulesRequest, opts ...grpc.CallOption) (*ListReportSchedulesResponse, error) {
+	out := new(ListReportSchedulesResponse)
+	err := c.cc.Invoke(ctx, IoTService_ListReportSchedules_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) CreateReportSchedule(ctx context.Context, in *CreateReportScheduleRequest, opts ...grpc.CallOption) (*CreateReportScheduleResponse, error) {
+	out := new(CreateReportScheduleResponse)
+	err := c.cc.Invoke(ctx, IoTService_CreateReportSchedule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) UpdateReportSchedule(ctx context.Context, in *UpdateReportScheduleRequest, opts ...grpc.CallOption) (*UpdateReportScheduleResponse, error) {
+	out := new(UpdateReportScheduleResponse)
+	err := c.cc.Invoke(ctx, IoTService_UpdateReportSchedule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) DeleteReportSchedule(ctx context.Context, in *DeleteReportScheduleRequest, opts ...grpc.CallOption) (*DeleteReportScheduleResponse, error) {
+	out := new(DeleteReportScheduleResponse)
+	err := c.cc.Invoke(ctx, IoTService_DeleteReportSchedule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) GetDeviceLocationHistory(ctx context.Context, in *GetDeviceLocationHistoryRequest, opts ...grpc.CallOption) (*GetDeviceLocationHistoryResponse, error) {
+	out := new(GetDeviceLocationHistoryResponse)
+	err := c.cc.Invoke(ctx, IoTService_GetDeviceLocationHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) GetReadingsHeatmap(ctx context.Context, in *GetReadingsHeatmapRequest, opts ...grpc.CallOption) (*GetReadingsHeatmapResponse, error) {
+	out := new(GetReadingsHeatmapResponse)
+	err := c.cc.Invoke(ctx, IoTService_GetReadingsHeatmap_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) GetStatusSummary(ctx context.Context, in *GetStatusSummaryRequest, opts ...grpc.CallOption) (*GetStatusSummaryResponse, error) {
+	out := new(GetStatusSummaryResponse)
+	err := c.cc.Invoke(ctx, IoTService_GetStatusSummary_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) ExportReadings(ctx context.Context, in *ExportReadingsRequest, opts ...grpc.CallOption) (*ExportReadingsResponse, error) {
+	out := new(ExportReadingsResponse)
+	err := c.cc.Invoke(ctx, IoTService_ExportReadings_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error) {
+	out := new(GetServerInfoResponse)
+	err := c.cc.Invoke(ctx, IoTService_GetServerInfo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) CreateOrUpdateDevice(ctx context.Context, in *CreateOrUpdateDeviceRequest, opts ...grpc.CallOption) (*CreateOrUpdateDeviceResponse, error) {
+	out := new(CreateOrUpdateDeviceResponse)
+	err := c.cc.Invoke(ctx, IoTService_CreateOrUpdateDevice_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ioTServiceClient) GetDeviceHistory(ctx context.Context, in *GetDeviceHistoryRequest, opts ...grpc.CallOption) (*GetDeviceHistoryResponse, error) {
+	out := new(GetDeviceHistoryResponse)
+	err := c.cc.Invoke(ctx, IoTService_GetDeviceHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IoTServiceServer is the server API for IoTService service.
+// All implementations must embed UnimplementedIoTServiceServer
+// for forward compatibility
+type IoTServiceServer interface {
+	// Request and response names predate the lint rules and are kept for compatibility.
+	// buf:lint:ignore RPC_REQUEST_STANDARD_NAME
+	// buf:lint:ignore RPC_RESPONSE_STANDARD_NAME
+	GetAllDevice(context.Context, *GetAllDevicesRequest) (*GetAllDevicesResponse, error)
+	// buf:lint:ignore RPC_REQUEST_STANDARD_NAME
+	// buf:lint:ignore RPC_RESPONSE_STANDARD_NAME
+	GetDevice(context.Context, *GetDeviceByIDRequest) (*GetDeviceByIDResponse, error)
+	GetSensorReadingByDeviceID(context.Context, *GetSensorReadingByDeviceIDRequest) (*GetSensorReadingByDeviceIDResponse, error)
+	CountReadings(context.Context, *CountReadingsRequest) (*CountReadingsResponse, error)
+	GetSensorReadingSeriesBatch(context.Context, *GetSensorReadingSeriesBatchRequest) (*GetSensorReadingSeriesBatchResponse, error)
+	ListAlertRules(context.Context, *ListAlertRulesRequest) (*ListAlertRulesResponse, error)
+	GetAlertRule(context.Context, *GetAlertRuleRequest) (*GetAlertRuleResponse, error)
+	CreateAlertRule(context.Context, *CreateAlertRuleRequest) (*CreateAlertRuleResponse, error)
+	UpdateAlertRule(context.Context, *UpdateAlertRuleRequest) (*UpdateAlertRuleResponse, error)
+	DeleteAlertRule(context.Context, *DeleteAlertRuleRequest) (*DeleteAlertRuleResponse, error)
+	GetQuotaUsage(context.Context, *GetQuotaUsageRequest) (*GetQuotaUsageResponse, error)
+	DeleteDevice(context.Context, *DeleteDeviceRequest) (*DeleteDeviceResponse, error)
+	RestoreDevice(context.Context, *RestoreDeviceRequest) (*RestoreDeviceResponse, error)
+	ListDeletedDevices(context.Context, *ListDeletedDevicesRequest) (*ListDeletedDevicesResponse, error)
+	ListDeviceNotes(context.Context, *ListDeviceNotesRequest) (*ListDeviceNotesResponse, error)
+	CreateDeviceNote(context.Context, *CreateDeviceNoteRequest) (*CreateDeviceNoteResponse, error)
+	UpdateDeviceNote(context.Context, *UpdateDeviceNoteRequest) (*UpdateDeviceNoteResponse, error)
+	DeleteDeviceNote(context.Context, *DeleteDeviceNoteRequest) (*DeleteDeviceNoteResponse, error)
+	ListLowBatteryDevices(context.Context, *ListLowBatteryDevicesRequest) (*ListLowBatteryDevicesResponse, error)
+	ListReportSchedules(context.Context, *ListReportSchedulesRequest) (*ListReportSchedulesResponse, error)
+	CreateReportSchedule(context.Context, *CreateReportScheduleRequest) (*CreateReportScheduleResponse, error)
+	UpdateReportSchedule(context.Context, *UpdateReportScheduleRequest) (*UpdateReportScheduleResponse, error)
+	DeleteReportSchedule(context.Context, *DeleteReportScheduleRequest) (*DeleteReportScheduleResponse, error)
+	GetDeviceLocationHistory(context.Context, *GetDeviceLocationHistoryRequest) (*GetDeviceLocationHistoryResponse, error)
+	GetReadingsHeatmap(context.Context, *GetReadingsHeatmapRequest) (*GetReadingsHeatmapResponse, error)
+	GetStatusSummary(context.Context, *GetStatusSummaryRequest) (*GetStatusSummaryResponse, error)
+	ExportReadings(context.Context, *ExportReadingsRequest) (*ExportReadingsResponse, error)
+	// Reports the API version and capabilities so clients can adapt to backends
+	// of other releases. Backends older than this RPC return UNIMPLEMENTED.
+	GetServerInfo(context.Context, *GetServerInfoRequest) (*GetServerInfoResponse, error)
+	// Registers a device or updates its metadata, like a message on the device
+	// queue but synchronously, so readings sent afterwards find the device.
+	CreateOrUpdateDevice(context.Context, *CreateOrUpdateDeviceRequest) (*CreateOrUpdateDeviceResponse, error)
+	// Returns how the firmware, location and network address of a device
+	// changed over time, replayed from the device event log.
+	GetDeviceHistory(context.Context, *GetDeviceHistoryRequest) (*GetDeviceHistoryResponse, error)
+	mustEmbedUnimplementedIoTServiceServer()
+}
+
+// UnimplementedIoTServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedIoTServiceServer struct {
+}
+
+func (UnimplementedIoTServiceServer) GetAllDevice(context.Context, *GetAllDevicesRequest) (*GetAllDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllDevice not implemented")
+}
+func (UnimplementedIoTServiceServer) GetDevice(context.Context, *GetDeviceByIDRequest) (*GetDeviceByIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDevice not implemented")
+}
+func (UnimplementedIoTServiceServer) GetSensorReadingByDeviceID(context.Context, *GetSensorReadingByDeviceIDRequest) (*GetSensorReadingByDeviceIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSensorReadingByDeviceID not implemented")
+}
+func (UnimplementedIoTServiceServer) CountReadings(context.Context, *CountReadingsRequest) (*CountReadingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountReadings not implemented")
+}
+func (UnimplementedIoTServiceServer) GetSensorReadingSeriesBatch(context.Context, *GetSensorReadingSeriesBatchRequest) (*GetSensorReadingSeriesBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSensorReadingSeriesBatch not implemented")
+}
+func (UnimplementedIoTServiceServer) ListAlertRules(context.Context, *ListAlertRulesRequest) (*ListAlertRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAlertRules not implemented")
+}
+func (UnimplementedIoTServiceServer) GetAlertRule(context.Context, *GetAlertRuleRequest) (*GetAlertRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAlertRule not implemented")
+}
+func (UnimplementedIoTServiceServer) CreateAlertRule(context.Context, *CreateAlertRuleRequest) (*CreateAlertRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAlertRule not implemented")
+}
+func (UnimplementedIoTServiceServer) UpdateAlertRule(context.Context, *UpdateAlertRuleRequest) (*UpdateAlertRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAlertRule not implemented")
+}
+func (UnimplementedIoTServiceServer) DeleteAlertRule(context.Context, *DeleteAlertRuleRequest) (*DeleteAlertRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAlertRule not implemented")
+}
+func (UnimplementedIoTServiceServer) GetQuotaUsage(context.Context, *GetQuotaUsageRequest) (*GetQuotaUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuotaUsage not implemented")
+}
+func (UnimplementedIoTServiceServer) DeleteDevice(context.Context, *DeleteDeviceRequest) (*DeleteDeviceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDevice not implemented")
+}
+func (UnimplementedIoTServiceServer) RestoreDevice(context.Context, *RestoreDeviceRequest) (*RestoreDeviceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreDevice not implemented")
+}
+func (UnimplementedIoTServiceServer) ListDeletedDevices(context.Context, *ListDeletedDevicesRequest) (*ListDeletedDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeletedDevices not implemented")
+}
+func (UnimplementedIoTServiceServer) ListDeviceNotes(context.Context, *ListDeviceNotesRequest) (*ListDeviceNotesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeviceNotes not implemented")
+}
+func (UnimplementedIoTServiceServer) CreateDeviceNote(context.Context, *CreateDeviceNoteRequest) (*CreateDeviceNoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDeviceNote not implemented")
+}
+func (UnimplementedIoTServiceServer) UpdateDeviceNote(context.Context, *UpdateDeviceNoteRequest) (*UpdateDeviceNoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateDeviceNote not implemented")
+}
+func (UnimplementedIoTServiceServer) DeleteDeviceNote(context.Context, *DeleteDeviceNoteRequest) (*DeleteDeviceNoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDeviceNote not implemented")
+}
+func (UnimplementedIoTServiceServer) ListLowBatteryDevices(context.Context, *ListLowBatteryDevicesRequest) (*ListLowBatteryDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLowBatteryDevices not implemented")
+}
+func (UnimplementedIoTServiceServer) ListReportSchedules(context.Context, *ListReportSchedulesRequest) (*ListReportSchedulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReportSchedules not implemented")
+}
+func (UnimplementedIoTServiceServer) CreateReportSchedule(context.Context, *CreateReportScheduleRequest) (*CreateReportScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReportSchedule not implemented")
+}
+func (UnimplementedIoTServiceServer) UpdateReportSchedule(context.Context, *UpdateReportScheduleRequest) (*UpdateReportScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateReportSchedule not implemented")
+}
+func (UnimplementedIoTServiceServer) DeleteReportSchedule(context.Context, *DeleteReportScheduleRequest) (*DeleteReportScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteReportSchedule not implemented")
+}
+func (UnimplementedIoTServiceServer) GetDeviceLocationHistory(context.Context, *GetDeviceLocationHistoryRequest) (*GetDeviceLocationHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeviceLocationHistory not implemented")
+}
+func (UnimplementedIoTServiceServer) GetReadingsHeatmap(context.Context, *GetReadingsHeatmapRequest) (*GetReadingsHeatmapResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReadingsHeatmap not implemented")
+}
+func (UnimplementedIoTServiceServer) GetStatusSummary(context.Context, *GetStatusSummaryRequest) (*GetStatusSummaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatusSummary not implemented")
+}
+func (UnimplementedIoTServiceServer) ExportReadings(context.Context, *ExportReadingsRequest) (*ExportReadingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportReadings not implemented")
+}
+func (UnimplementedIoTServiceServer) GetServerInfo(context.Context, *GetServerInfoRequest) (*GetServerInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerInfo not implemented")
+}
+func (UnimplementedIoTServiceServer) CreateOrUpdateDevice(context.Context, *CreateOrUpdateDeviceRequest) (*CreateOrUpdateDeviceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOrUpdateDevice not implemented")
+}
+func (UnimplementedIoTServiceServer) GetDeviceHistory(context.Context, *GetDeviceHistoryRequest) (*GetDeviceHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeviceHistory not implemented")
+}
+func (UnimplementedIoTServiceServer) mustEmbedUnimplementedIoTServiceServer() {}
+
+// UnsafeIoTServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IoTServiceServer will
+// result in compilation errors.
+type UnsafeIoTServiceServer interface {
+	mustEmbedUnimplementedIoTServiceServer()
+}
+
+func RegisterIoTServiceServer(s grpc.ServiceRegistrar, srv IoTServiceServer) {
+	s.RegisterService(&IoTService_ServiceDesc, srv)
+}
+
+func _IoTService_GetAllDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAllDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).GetAllDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_GetAllDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).GetAllDevice(ctx, req.(*GetAllDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_GetDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeviceByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).GetDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_GetDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).GetDevice(ctx, req.(*GetDeviceByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_GetSensorReadingByDeviceID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSensorReadingByDeviceIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).GetSensorReadingByDeviceID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_GetSensorReadingByDeviceID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).GetSensorReadingByDeviceID(ctx, req.(*GetSensorReadingByDeviceIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_CountReadings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountReadingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).CountReadings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_CountReadings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).CountReadings(ctx, req.(*CountReadingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_GetSensorReadingSeriesBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSensorReadingSeriesBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).GetSensorReadingSeriesBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_GetSensorReadingSeriesBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).GetSensorReadingSeriesBatch(ctx, req.(*GetSensorReadingSeriesBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_ListAlertRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAlertRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).ListAlertRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_ListAlertRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).ListAlertRules(ctx, req.(*ListAlertRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_GetAlertRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAlertRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).GetAlertRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_GetAlertRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).GetAlertRule(ctx, req.(*GetAlertRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_CreateAlertRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAlertRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).CreateAlertRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_CreateAlertRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).CreateAlertRule(ctx, req.(*CreateAlertRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_UpdateAlertRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAlertRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).UpdateAlertRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_UpdateAlertRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).UpdateAlertRule(ctx, req.(*UpdateAlertRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_DeleteAlertRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAlertRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).DeleteAlertRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_DeleteAlertRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).DeleteAlertRule(ctx, req.(*DeleteAlertRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_GetQuotaUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuotaUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).GetQuotaUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_GetQuotaUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).GetQuotaUsage(ctx, req.(*GetQuotaUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_DeleteDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).DeleteDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_DeleteDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).DeleteDevice(ctx, req.(*DeleteDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_RestoreDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).RestoreDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_RestoreDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).RestoreDevice(ctx, req.(*RestoreDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_ListDeletedDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeletedDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).ListDeletedDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_ListDeletedDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).ListDeletedDevices(ctx, req.(*ListDeletedDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_ListDeviceNotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeviceNotesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).ListDeviceNotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_ListDeviceNotes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).ListDeviceNotes(ctx, req.(*ListDeviceNotesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_CreateDeviceNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDeviceNoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).CreateDeviceNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_CreateDeviceNote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).CreateDeviceNote(ctx, req.(*CreateDeviceNoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_UpdateDeviceNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateDeviceNoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).UpdateDeviceNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_UpdateDeviceNote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).UpdateDeviceNote(ctx, req.(*UpdateDeviceNoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_DeleteDeviceNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDeviceNoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).DeleteDeviceNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_DeleteDeviceNote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).DeleteDeviceNote(ctx, req.(*DeleteDeviceNoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_ListLowBatteryDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLowBatteryDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).ListLowBatteryDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_ListLowBatteryDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).ListLowBatteryDevices(ctx, req.(*ListLowBatteryDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_ListReportSchedules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReportSchedulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).ListReportSchedules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_ListReportSchedules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).ListReportSchedules(ctx, req.(*ListReportSchedulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_CreateReportSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateReportScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).CreateReportSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_CreateReportSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).CreateReportSchedule(ctx, req.(*CreateReportScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_UpdateReportSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateReportScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).UpdateReportSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_UpdateReportSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).UpdateReportSchedule(ctx, req.(*UpdateReportScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_DeleteReportSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteReportScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).DeleteReportSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_DeleteReportSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).DeleteReportSchedule(ctx, req.(*DeleteReportScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_GetDeviceLocationHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeviceLocationHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).GetDeviceLocationHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_GetDeviceLocationHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).GetDeviceLocationHistory(ctx, req.(*GetDeviceLocationHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_GetReadingsHeatmap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReadingsHeatmapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).GetReadingsHeatmap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_GetReadingsHeatmap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).GetReadingsHeatmap(ctx, req.(*GetReadingsHeatmapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_GetStatusSummary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusSummaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).GetStatusSummary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_GetStatusSummary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).GetStatusSummary(ctx, req.(*GetStatusSummaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_ExportReadings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportReadingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).ExportReadings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_ExportReadings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).ExportReadings(ctx, req.(*ExportReadingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_GetServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServerInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).GetServerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_GetServerInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).GetServerInfo(ctx, req.(*GetServerInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_CreateOrUpdateDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateOrUpdateDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).CreateOrUpdateDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_CreateOrUpdateDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).CreateOrUpdateDevice(ctx, req.(*CreateOrUpdateDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IoTService_GetDeviceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeviceHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IoTServiceServer).GetDeviceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IoTService_GetDeviceHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IoTServiceServer).GetDeviceHistory(ctx, req.(*GetDeviceHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IoTService_ServiceDesc is the grpc.ServiceDesc for IoTService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IoTService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "iot.v1.IoTService",
+	HandlerType: (*IoTServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAllDevice",
+			Handler:    _IoTService_GetAllDevice_Handler,
+		},
+		{
+			MethodName: "GetDevice",
+			Handler:    _IoTService_GetDevice_Handler,
+		},
+		{
+			MethodName: "GetSensorReadingByDeviceID",
+			Handler:    _IoTService_GetSensorReadingByDeviceID_Handler,
+		},
+		{
+			MethodName: "CountReadings",
+			Handler:    _IoTService_CountReadings_Handler,
+		},
+		{
+			MethodName: "GetSensorReadingSeriesBatch",
+			Handler:    _IoTService_GetSensorReadingSeriesBatch_Handler,
+		},
+		{
+			MethodName: "ListAlertRules",
+			Handler:    _IoTService_ListAlertRules_Handler,
+		},
+		{
+			MethodName: "GetAlertRule",
+			Handler:    _IoTService_GetAlertRule_Handler,
+		},
+		{
+			MethodName: "CreateAlertRule",
+			Handler:    _IoTService_CreateAlertRule_Handler,
+		},
+		{
+			MethodName: "UpdateAlertRule",
+			Handler:    _IoTService_UpdateAlertRule_Handler,
+		},
+		{
+			MethodName: "DeleteAlertRule",
+			Handler:    _IoTService_DeleteAlertRule_Handler,
+		},
+		{
+			MethodName: "GetQuotaUsage",
+			Handler:    _IoTService_GetQuotaUsage_Handler,
+		},
+		{
+			MethodName: "DeleteDevice",
+			Handler:    _IoTService_DeleteDevice_Handler,
+		},
+		{
+			MethodName: "RestoreDevice",
+			Handler:    _IoTService_RestoreDevice_Handler,
+		},
+		{
+			MethodName: "ListDeletedDevices",
+			Handler:    _IoTService_ListDeletedDevices_Handler,
+		},
+		{
+			MethodName: "ListDeviceNotes",
+			Handler:    _IoTService_ListDeviceNotes_Handler,
+		},
+		{
+			MethodName: "CreateDeviceNote",
+			Handler:    _IoTService_CreateDeviceNote_Handler,
+		},
+		{
+			MethodName: "UpdateDeviceNote",
+			Handler:    _IoTService_UpdateDeviceNote_Handler,
+		},
+		{
+			MethodName: "DeleteDeviceNote",
+			Handler:    _IoTService_DeleteDeviceNote_Handler,
+		},
+		{
+			MethodName: "ListLowBatteryDevices",
+			Handler:    _IoTService_ListLowBatteryDevices_Handler,
+		},
+		{
+			MethodName: "ListReportSchedules",
+			Handler:    _IoTService_ListReportSchedules_Handler,
+		},
+		{
+			MethodName: "CreateReportSchedule",
+			Handler:    _IoTService_CreateReportSchedule_Handler,
+		},
+		{
+			MethodName: "UpdateReportSchedule",
+			Handler:    _IoTService_UpdateReportSchedule_Handler,
+		},
+		{
+			MethodName: "DeleteReportSchedule",
+			Handler:    _IoTService_DeleteReportSchedule_Handler,
+		},
+		{
+			MethodName: "GetDeviceLocationHistory",
+			Handler:    _IoTService_GetDeviceLocationHistory_Handler,
+		},
+		{
+			MethodName: "GetReadingsHeatmap",
+			Handler:    _IoTService_GetReadingsHeatmap_Handler,
+		},
+		{
+			MethodName: "GetStatusSummary",
+			Handler:    _IoTService_GetStatusSummary_Handler,
+		},
+		{
+			MethodName: "ExportReadings",
+			Handler:    _IoTService_ExportReadings_Handler,
+		},
+		{
+			MethodName: "GetServerInfo",
+			Handler:    _IoTService_GetServerInfo_Handler,
+		},
+		{
+			MethodName: "CreateOrUpdateDevice",
+			Handler:    _IoTService_CreateOrUpdateDevice_Handler,
+		},
+		{
+			MethodName: "GetDeviceHistory",
+			Handler:    _IoTService_GetDeviceHistory_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "iot/v1/sensor.proto",
+}
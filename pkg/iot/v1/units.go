@@ -0,0 +1,44 @@
+package iotv1
+
+// Queue message headers declaring the units of the readings in a message.
+// Readings of messages without them are in the canonical units, degrees
+// Celsius and hectopascals, which is what the backend stores.
+const (
+	HeaderTemperatureUnit = "x-temperature-unit"
+	HeaderPressureUnit    = "x-pressure-unit"
+)
+
+// Units of the temperature and pressure of readings.
+const (
+	TemperatureUnitCelsius      = "celsius"
+	TemperatureUnitFahrenheit   = "fahrenheit"
+	PressureUnitHectopascal     = "hPa"
+	PressureUnitInchesOfMercury = "inHg"
+)
+
+// hectopascalsPerInchOfMercury is the pressure of one inch of mercury at 0 °C.
+const hectopascalsPerInchOfMercury = 33.8638866667
+
+// FahrenheitToCelsius converts a temperature from degrees Fahrenheit to
+// degrees Celsius.
+func FahrenheitToCelsius(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
+
+// CelsiusToFahrenheit converts a temperature from degrees Celsius to degrees
+// Fahrenheit.
+func CelsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+// InchesOfMercuryToHectopascals converts a pressure from inches of mercury to
+// hectopascals.
+func InchesOfMercuryToHectopascals(inHg float64) float64 {
+	return inHg * hectopascalsPerInchOfMercury
+}
+
+// HectopascalsToInchesOfMercury converts a pressure from hectopascals to
+// inches of mercury.
+func HectopascalsToInchesOfMercury(hPa float64) float64 {
+	return hPa / hectopascalsPerInchOfMercury
+}
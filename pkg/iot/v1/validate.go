@@ -0,0 +1,271 @@
+package iotv1
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/envoyproxy/protoc-gen-validate/validate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Validate checks msg against the protoc-gen-validate rules annotated in
+// sensor.proto and returns an InvalidArgument error listing every violated
+// field, or nil. Nested messages are checked too; their fields are reported
+// with a dotted path, e.g. "rule.name", and list items by index, e.g.
+// "device_ids[2]".
+//
+// Only the rules the API uses are supported: min_len, max_len, pattern, in
+// and ignore_empty for strings; gte, lte and ignore_empty for integers;
+// min_items, max_items and items for repeated fields; and required and skip
+// for messages. Any other rule is reported by CheckRules.
+func Validate(msg proto.Message) error {
+	v := &validator{}
+	v.message("", msg.ProtoReflect())
+	if len(v.violations) == 0 {
+		return nil
+	}
+
+	return NewError(codes.InvalidArgument, ReasonInvalidArgument, "invalid request", nil, v.violations...)
+}
+
+// CheckRules returns an error if desc, or a message nested in it, uses a
+// validation rule Validate does not support.
+func CheckRules(desc protoreflect.MessageDescriptor) error {
+	return checkMessageRules(desc, map[protoreflect.FullName]bool{})
+}
+
+// supportedRules lists the supported fields of each rules message.
+var supportedRules = map[protoreflect.Name][]protoreflect.Name{
+	"string":   {"min_len", "max_len", "pattern", "in", "ignore_empty"},
+	"int32":    {"gte", "lte", "ignore_empty"},
+	"int64":    {"gte", "lte", "ignore_empty"},
+	"uint32":   {"gte", "lte", "ignore_empty"},
+	"uint64":   {"gte", "lte", "ignore_empty"},
+	"repeated": {"min_items", "max_items", "items", "ignore_empty"},
+	"message":  {"required", "skip"},
+}
+
+func checkMessageRules(desc protoreflect.MessageDescriptor, seen map[protoreflect.FullName]bool) error {
+	if seen[desc.FullName()] {
+		return nil
+	}
+	seen[desc.FullName()] = true
+
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if err := checkFieldRules(fd.FullName(), fieldRules(fd)); err != nil {
+			return err
+		}
+		if fd.Message() != nil && !fd.IsMap() {
+			if err := checkMessageRules(fd.Message(), seen); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkFieldRules(field protoreflect.FullName, rules *validate.FieldRules) error {
+	if rules == nil {
+		return nil
+	}
+
+	var err error
+	rules.ProtoReflect().Range(func(kind protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		supported, ok := supportedRules[kind.Name()]
+		if !ok {
+			err = fmt.Errorf("%s: unsupported %s rules", field, kind.Name())
+			return false
+		}
+
+		value.Message().Range(func(rule protoreflect.FieldDescriptor, ruleValue protoreflect.Value) bool {
+			if !slices.Contains(supported, rule.Name()) {
+				err = fmt.Errorf("%s: unsupported rule %s.%s", field, kind.Name(), rule.Name())
+				return false
+			}
+			if rule.Name() == "items" {
+				err = checkFieldRules(field, ruleValue.Message().Interface().(*validate.FieldRules))
+			}
+			return err == nil
+		})
+		return err == nil
+	})
+
+	return err
+}
+
+// fieldRules returns the rules annotated on fd, or nil.
+func fieldRules(fd protoreflect.FieldDescriptor) *validate.FieldRules {
+	opts := fd.Options()
+	if opts == nil || !proto.HasExtension(opts, validate.E_Rules) {
+		return nil
+	}
+
+	rules, _ := proto.GetExtension(opts, validate.E_Rules).(*validate.FieldRules)
+	return rules
+}
+
+// patterns caches compiled rule patterns by their source.
+var patterns sync.Map
+
+func compilePattern(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+
+	// Patterns are part of the API definition, so an invalid one is a bug
+	re := regexp.MustCompile(pattern)
+	patterns.Store(pattern, re)
+	return re
+}
+
+// validator collects the violations of one message.
+type validator struct {
+	violations []FieldViolation
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.violations = append(v.violations, FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) message(prefix string, msg protoreflect.Message) {
+	fields := msg.Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		path := prefix + string(fd.Name())
+		rules := fieldRules(fd)
+
+		switch {
+		case fd.IsList():
+			list := msg.Get(fd).List()
+			if rules != nil {
+				v.repeated(path, list, rules.GetRepeated())
+			}
+			if fd.Message() != nil {
+				for j := range list.Len() {
+					v.message(fmt.Sprintf("%s[%d].", path, j), list.Get(j).Message())
+				}
+			}
+		case fd.IsMap():
+			// No map rules are used
+		case fd.Message() != nil:
+			if rules.GetMessage().GetSkip() {
+				continue
+			}
+			if !msg.Has(fd) {
+				if rules.GetMessage().GetRequired() {
+					v.add(path, "is required")
+				}
+				continue
+			}
+			v.message(path+".", msg.Get(fd).Message())
+		case rules != nil:
+			v.scalar(path, msg.Get(fd), rules)
+		}
+	}
+}
+
+func (v *validator) repeated(path string, list protoreflect.List, rules *validate.RepeatedRules) {
+	if rules == nil || (rules.GetIgnoreEmpty() && list.Len() == 0) {
+		return
+	}
+
+	n := uint64(list.Len())
+	switch {
+	case rules.MinItems != nil && n < rules.GetMinItems():
+		if rules.GetMinItems() == 1 {
+			v.add(path, "cannot be empty")
+		} else {
+			v.add(path, "must have at least %d items", rules.GetMinItems())
+		}
+	case rules.MaxItems != nil && n > rules.GetMaxItems():
+		v.add(path, "must have at most %d items", rules.GetMaxItems())
+	}
+
+	if items := rules.GetItems(); items != nil {
+		for i := range list.Len() {
+			v.scalar(fmt.Sprintf("%s[%d]", path, i), list.Get(i), items)
+		}
+	}
+}
+
+func (v *validator) scalar(path string, value protoreflect.Value, rules *validate.FieldRules) {
+	switch r := rules.GetType().(type) {
+	case *validate.FieldRules_String_:
+		v.string(path, value.String(), r.String_)
+	case *validate.FieldRules_Int32:
+		toInt64 := func(n int32) int64 { return int64(n) }
+		numberRules(v, path, value.Int(), r.Int32.GetIgnoreEmpty(), bound64(r.Int32.Gte, toInt64), bound64(r.Int32.Lte, toInt64))
+	case *validate.FieldRules_Int64:
+		numberRules(v, path, value.Int(), r.Int64.GetIgnoreEmpty(), r.Int64.Gte, r.Int64.Lte)
+	case *validate.FieldRules_Uint32:
+		toUint64 := func(n uint32) uint64 { return uint64(n) }
+		numberRules(v, path, value.Uint(), r.Uint32.GetIgnoreEmpty(), bound64(r.Uint32.Gte, toUint64), bound64(r.Uint32.Lte, toUint64))
+	case *validate.FieldRules_Uint64:
+		numberRules(v, path, value.Uint(), r.Uint64.GetIgnoreEmpty(), r.Uint64.Gte, r.Uint64.Lte)
+	}
+}
+
+func (v *validator) string(path, s string, rules *validate.StringRules) {
+	if rules.GetIgnoreEmpty() && s == "" {
+		return
+	}
+
+	n := uint64(utf8.RuneCountInString(s))
+	switch {
+	case rules.MinLen != nil && n < rules.GetMinLen():
+		if rules.GetMinLen() == 1 {
+			v.add(path, "cannot be empty")
+		} else {
+			v.add(path, "must be at least %d characters", rules.GetMinLen())
+		}
+		return
+	case rules.MaxLen != nil && n > rules.GetMaxLen():
+		v.add(path, "must be at most %d characters", rules.GetMaxLen())
+		return
+	}
+
+	if len(rules.GetIn()) > 0 && !slices.Contains(rules.GetIn(), s) {
+		v.add(path, "must be one of %s", strings.Join(rules.GetIn(), ", "))
+		return
+	}
+
+	if rules.Pattern != nil && !compilePattern(rules.GetPattern()).MatchString(s) {
+		v.add(path, "must match the pattern %s", rules.GetPattern())
+	}
+}
+
+// bound64 widens an optional 32-bit bound.
+func bound64[T int32 | uint32, W int64 | uint64](bound *T, convert func(T) W) *W {
+	if bound == nil {
+		return nil
+	}
+	w := convert(*bound)
+	return &w
+}
+
+// numberRules checks n against the optional bounds of an integer rule.
+func numberRules[T int64 | uint64](v *validator, path string, n T, ignoreEmpty bool, gte, lte *T) {
+	if (ignoreEmpty && n == 0) || ((gte == nil || n >= *gte) && (lte == nil || n <= *lte)) {
+		return
+	}
+
+	switch {
+	case gte != nil && lte != nil:
+		v.add(path, "must be between %d and %d", *gte, *lte)
+	case gte != nil && *gte == 0:
+		v.add(path, "cannot be negative")
+	case gte != nil:
+		v.add(path, "must be at least %d", *gte)
+	default:
+		v.add(path, "must be at most %d", *lte)
+	}
+}
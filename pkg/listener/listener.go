@@ -0,0 +1,109 @@
+// Package listener opens the listeners of the HTTP and gRPC servers from a
+// bind address and port: a TCP listener on one host or all interfaces, or a
+// Unix socket.
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unixScheme prefixes bind addresses naming a Unix socket, as in
+// unix:///run/demo-app/grpc.sock.
+const unixScheme = "unix://"
+
+// Address returns the network and address to listen on for a bind address
+// and port. An empty bind address listens on all IPv4 and IPv6 interfaces;
+// IPv6 addresses may be given with or without brackets, as in [::1]. A
+// unix:// bind address is a socket path and the port is ignored.
+func Address(bindAddress string, port int) (network, address string) {
+	if path, ok := strings.CutPrefix(bindAddress, unixScheme); ok {
+		return "unix", path
+	}
+	return "tcp", net.JoinHostPort(host(bindAddress), strconv.Itoa(port))
+}
+
+// IsUnixSocket reports whether bindAddress names a Unix socket.
+func IsUnixSocket(bindAddress string) bool {
+	return strings.HasPrefix(bindAddress, unixScheme)
+}
+
+// Validate checks a bind address and the port it is used with. name names
+// the server in errors.
+func Validate(name, bindAddress string, port int) error {
+	if IsUnixSocket(bindAddress) {
+		if strings.TrimPrefix(bindAddress, unixScheme) == "" {
+			return fmt.Errorf("%s bind address %q has no socket path", name, bindAddress)
+		}
+		return nil
+	}
+
+	// Host names are allowed; a colon outside an IPv6 address means a port
+	if h := host(bindAddress); strings.Contains(h, ":") {
+		if _, err := netip.ParseAddr(h); err != nil {
+			return fmt.Errorf("%s bind address %q must be a host or IP without port, or unix:///path", name, bindAddress)
+		}
+	}
+	if port <= 0 {
+		return fmt.Errorf("%s port must be positive", name)
+	}
+	return nil
+}
+
+// Listen listens on the bind address and port.
+func Listen(bindAddress string, port int) (net.Listener, error) {
+	network, address := Address(bindAddress, port)
+
+	if network == "unix" {
+		if err := removeStaleSocket(address); err != nil {
+			return nil, err
+		}
+	}
+
+	lis, err := net.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	return lis, nil
+}
+
+// host strips the brackets of an IPv6 bind address.
+func host(bindAddress string) string {
+	if strings.HasPrefix(bindAddress, "[") && strings.HasSuffix(bindAddress, "]") {
+		return bindAddress[1 : len(bindAddress)-1]
+	}
+	return bindAddress
+}
+
+// removeStaleSocket removes a socket file left behind by a process that did
+// not shut down cleanly. Sockets still accepting connections and files that
+// are not sockets are kept, so listening on them fails.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return nil
+	}
+
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("socket %s is in use by another process", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package listener_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"procodus.dev/demo-app/pkg/listener"
+)
+
+var _ = Describe("Listener", func() {
+	DescribeTable("Address",
+		func(bindAddress string, port int, network, address string) {
+			n, a := listener.Address(bindAddress, port)
+			Expect(n).To(Equal(network))
+			Expect(a).To(Equal(address))
+		},
+		Entry("all interfaces", "", 9090, "tcp", ":9090"),
+		Entry("IPv4 address", "127.0.0.1", 9090, "tcp", "127.0.0.1:9090"),
+		Entry("IPv6 address", "::1", 9090, "tcp", "[::1]:9090"),
+		Entry("bracketed IPv6 address", "[::1]", 9090, "tcp", "[::1]:9090"),
+		Entry("host name", "localhost", 9090, "tcp", "localhost:9090"),
+		Entry("Unix socket", "unix:///run/demo-app/grpc.sock", 9090, "unix", "/run/demo-app/grpc.sock"),
+	)
+
+	DescribeTable("Validate",
+		func(bindAddress string, port int, message string) {
+			err := listener.Validate("test", bindAddress, port)
+			if message == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			}
+		},
+		Entry("all interfaces", "", 9090, ""),
+		Entry("IPv6 address", "::", 9090, ""),
+		Entry("bracketed IPv6 address", "[::1]", 9090, ""),
+		Entry("IPv6 address with zone", "fe80::1%eth0", 9090, ""),
+		Entry("Unix socket without port", "unix:///run/demo-app/grpc.sock", 0, ""),
+		Entry("address with port", "127.0.0.1:9090", 9090, "without port"),
+		Entry("bracketed IPv6 address with port", "[::1]:9090", 9090, "without port"),
+		Entry("socket without path", "unix://", 0, "no socket path"),
+		Entry("missing port", "127.0.0.1", 0, "test port must be positive"),
+	)
+
+	Describe("TCP", func() {
+		// dial connects to host on the listener's port.
+		dial := func(lis net.Listener, host string) error {
+			port := lis.Addr().(*net.TCPAddr).Port
+			conn, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+			if err == nil {
+				_ = conn.Close()
+			}
+			return err
+		}
+
+		// listen listens on a free port of bindAddress, skipping the spec
+		// when the host has no IPv6.
+		listen := func(bindAddress string) net.Listener {
+			lis, err := listener.Listen(bindAddress, 0)
+			if err != nil {
+				Skip("listening failed, IPv6 may be unavailable: " + err.Error())
+			}
+			DeferCleanup(lis.Close)
+			return lis
+		}
+
+		It("should accept IPv4 and IPv6 connections on all interfaces", func() {
+			lis := listen("")
+			Expect(dial(lis, "127.0.0.1")).To(Succeed())
+
+			if probe, err := net.Listen("tcp", "[::1]:0"); err != nil {
+				Skip("IPv6 unavailable: " + err.Error())
+			} else {
+				_ = probe.Close()
+			}
+			Expect(dial(lis, "::1")).To(Succeed())
+		})
+
+		It("should only accept connections on the bound IPv6 address", func() {
+			lis := listen("[::1]")
+			Expect(dial(lis, "::1")).To(Succeed())
+			Expect(dial(lis, "127.0.0.1")).NotTo(Succeed())
+		})
+
+		It("should only accept connections on the bound IPv4 address", func() {
+			lis := listen("127.0.0.1")
+			Expect(dial(lis, "127.0.0.1")).To(Succeed())
+			Expect(dial(lis, "::1")).NotTo(Succeed())
+		})
+	})
+
+	Describe("Unix sockets", func() {
+		var path string
+
+		BeforeEach(func() {
+			// Socket paths are limited to about 100 bytes, too short for
+			// the default temporary directory on some systems
+			dir, err := os.MkdirTemp("", "sock")
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(os.RemoveAll, dir)
+			path = filepath.Join(dir, "grpc.sock")
+		})
+
+		It("should listen and remove the socket on close", func() {
+			lis, err := listener.Listen("unix://"+path, 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			conn, err := net.Dial("unix", path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conn.Close()).To(Succeed())
+
+			Expect(lis.Close()).To(Succeed())
+			Expect(path).NotTo(BeAnExistingFile())
+		})
+
+		It("should replace a stale socket", func() {
+			lis, err := net.Listen("unix", path)
+			Expect(err).NotTo(HaveOccurred())
+			// Leave the file behind as a crashed process would
+			lis.(*net.UnixListener).SetUnlinkOnClose(false)
+			Expect(lis.Close()).To(Succeed())
+
+			lis, err = listener.Listen("unix://"+path, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lis.Close()).To(Succeed())
+		})
+
+		It("should not take over a socket in use", func() {
+			lis, err := net.Listen("unix", path)
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(lis.Close)
+			go func() {
+				for {
+					conn, err := lis.Accept()
+					if err != nil {
+						return
+					}
+					_ = conn.Close()
+				}
+			}()
+
+			_, err = listener.Listen("unix://"+path, 0)
+			Expect(err).To(MatchError(ContainSubstring("in use")))
+		})
+
+		It("should not remove other files", func() {
+			Expect(os.WriteFile(path, []byte("data"), 0o600)).To(Succeed())
+
+			_, err := listener.Listen("unix://"+path, 0)
+			Expect(err).To(HaveOccurred())
+			Expect(path).To(BeAnExistingFile())
+		})
+	})
+})
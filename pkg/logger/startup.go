@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"log/slog"
+	"maps"
+	"net/url"
+	"runtime/debug"
+	"slices"
+)
+
+// Unknown is reported for versions that could not be determined.
+const Unknown = "unknown"
+
+// Startup describes a service in its startup event.
+type Startup struct {
+	// Service names the service, such as "backend".
+	Service string
+	// Config is the resolved configuration as key-value pairs. Secrets must be
+	// left out or redacted, e.g. with RedactURL.
+	Config []any
+	// Dependencies maps the servers the service connected to, such as
+	// "database", to their reported versions.
+	Dependencies map[string]string
+	// Features maps optional features to whether they are enabled. Only the
+	// enabled ones are logged.
+	Features map[string]bool
+	// Attrs are further key-value pairs, such as the schema version.
+	Attrs []any
+}
+
+// LogStartup logs the startup event of a service: a single record with the
+// release of the binary and everything in s, so a log excerpt alone tells
+// what was running and how it was configured.
+func LogStartup(l *slog.Logger, s Startup) {
+	args := []any{"version", Version()}
+	args = append(args, s.Attrs...)
+
+	deps := make([]any, 0, 2*len(s.Dependencies))
+	for _, name := range slices.Sorted(maps.Keys(s.Dependencies)) {
+		deps = append(deps, name, s.Dependencies[name])
+	}
+
+	features := []string{}
+	for _, name := range slices.Sorted(maps.Keys(s.Features)) {
+		if s.Features[name] {
+			features = append(features, name)
+		}
+	}
+
+	args = append(args,
+		slog.Group("dependencies", deps...),
+		"features", features,
+		slog.Group("config", s.Config...),
+	)
+	l.Info(s.Service+" server started", args...)
+}
+
+// Version returns the release of the binary from its build information.
+func Version() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return Unknown
+}
+
+// RedactURL returns rawURL with the password of its user info replaced, so
+// the URL can be logged. URLs that do not parse are not returned at all.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	return u.Redacted()
+}
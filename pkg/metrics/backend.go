@@ -6,20 +6,35 @@ import (
 
 // BackendMetrics contains Prometheus metrics for the backend service.
 type BackendMetrics struct {
-	GRPCRequestsTotal     *prometheus.CounterVec
-	GRPCRequestDuration   *prometheus.HistogramVec
-	GRPCRequestsInFlight  *prometheus.GaugeVec
-	ConsumerMessagesTotal *prometheus.CounterVec
-	ConsumerErrors        *prometheus.CounterVec
-	ProcessingDuration    *prometheus.HistogramVec
-	DBOperationsTotal     *prometheus.CounterVec
-	DBOperationDuration   *prometheus.HistogramVec
-	DBConnectionsActive   prometheus.Gauge
-	ActiveConsumers       prometheus.Gauge
+	GRPCRequestsTotal           *prometheus.CounterVec
+	GRPCRequestDuration         *prometheus.HistogramVec
+	GRPCRequestsInFlight        *prometheus.GaugeVec
+	ConsumerMessagesTotal       *prometheus.CounterVec
+	ConsumerErrors              *prometheus.CounterVec
+	ProcessingDuration          *prometheus.HistogramVec
+	ConsumerBufferAllocations   *prometheus.CounterVec
+	ConsumerPaused              *prometheus.GaugeVec
+	ConsumerPausedSeconds       *prometheus.CounterVec
+	ConsumerOutOfBoundsReadings *prometheus.CounterVec
+	StaleDeviceUpdates          *prometheus.CounterVec
+	DBOperationsTotal           *prometheus.CounterVec
+	DBOperationDuration         *prometheus.HistogramVec
+	DBSlowQueriesTotal          *prometheus.CounterVec
+	DBConnectionsActive         prometheus.Gauge
+	ActiveConsumers             prometheus.Gauge
+	SinkPointsTotal             *prometheus.CounterVec
+	SinkPendingPoints           *prometheus.GaugeVec
 }
 
 // NewBackendMetrics creates and registers backend service metrics.
 func NewBackendMetrics(namespace string) *BackendMetrics {
+	m := newBackendMetrics(namespace)
+	MustRegister(m.collectors()...)
+	return m
+}
+
+// newBackendMetrics creates backend service metrics without registering them.
+func newBackendMetrics(namespace string) *BackendMetrics {
 	m := &BackendMetrics{
 		GRPCRequestsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -56,7 +71,7 @@ func NewBackendMetrics(namespace string) *BackendMetrics {
 				Name:      "messages_total",
 				Help:      "Total number of messages consumed",
 			},
-			[]string{"queue", "status"}, // status: success, error
+			[]string{"queue", "status"}, // status: success, duplicate, rejected, stale, error
 		),
 		ConsumerErrors: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -77,6 +92,51 @@ func NewBackendMetrics(namespace string) *BackendMetrics {
 			},
 			[]string{"queue"},
 		),
+		ConsumerBufferAllocations: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "consumer",
+				Name:      "buffer_allocations_total",
+				Help:      "Number of message buffers allocated because none could be reused",
+			},
+			[]string{"queue"},
+		),
+		ConsumerPaused: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "consumer",
+				Name:      "paused",
+				Help:      "Whether consumption is paused because the database is unavailable (1) or not (0)",
+			},
+			[]string{"queue"},
+		),
+		ConsumerPausedSeconds: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "consumer",
+				Name:      "paused_seconds_total",
+				Help:      "Total time consumption was paused because the database was unavailable",
+			},
+			[]string{"queue"},
+		),
+		ConsumerOutOfBoundsReadings: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "consumer",
+				Name:      "out_of_bounds_readings_total",
+				Help:      "Total number of readings with a timestamp outside the allowed bounds",
+			},
+			[]string{"queue", "reason", "action"}, // reason: future, too_old; action: accept, correct, clamp, reject
+		),
+		StaleDeviceUpdates: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "device",
+				Name:      "stale_updates_total",
+				Help:      "Total number of device updates rejected because they are older than the stored device",
+			},
+			[]string{"source"}, // source: queue, api
+		),
 		DBOperationsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
@@ -96,6 +156,15 @@ func NewBackendMetrics(namespace string) *BackendMetrics {
 			},
 			[]string{"operation", "table"},
 		),
+		DBSlowQueriesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "db",
+				Name:      "slow_queries_total",
+				Help:      "Total number of database operations slower than the slow query threshold",
+			},
+			[]string{"operation", "table"},
+		),
 		DBConnectionsActive: prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -112,20 +181,49 @@ func NewBackendMetrics(namespace string) *BackendMetrics {
 				Help:      "Number of active message consumers",
 			},
 		),
+		SinkPointsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "sink",
+				Name:      "points_total",
+				Help:      "Total number of readings handled by secondary sinks",
+			},
+			[]string{"sink", "status"}, // status: written, skipped, rejected, dropped
+		),
+		SinkPendingPoints: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "sink",
+				Name:      "pending_points",
+				Help:      "Number of readings buffered for secondary sinks",
+			},
+			[]string{"sink"},
+		),
 	}
 
-	MustRegister(
+	return m
+}
+
+// collectors returns all collectors owned by BackendMetrics.
+func (m *BackendMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
 		m.GRPCRequestsTotal,
 		m.GRPCRequestDuration,
 		m.GRPCRequestsInFlight,
 		m.ConsumerMessagesTotal,
 		m.ConsumerErrors,
 		m.ProcessingDuration,
+		m.ConsumerBufferAllocations,
+		m.ConsumerPaused,
+		m.ConsumerPausedSeconds,
+		m.ConsumerOutOfBoundsReadings,
+		m.StaleDeviceUpdates,
 		m.DBOperationsTotal,
 		m.DBOperationDuration,
+		m.DBSlowQueriesTotal,
 		m.DBConnectionsActive,
 		m.ActiveConsumers,
-	)
-
-	return m
+		m.SinkPointsTotal,
+		m.SinkPendingPoints,
+	}
 }
@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metric namespaces used by the services when creating their metrics.
+const (
+	BackendNamespace   = "demo_app_backend"
+	FrontendNamespace  = "demo_app_frontend"
+	GeneratorNamespace = "demo_app"
+)
+
+// Metric types reported in a Definition.
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// Definition describes a single metric family as defined in this package.
+type Definition struct {
+	Name   string
+	Help   string
+	Type   string
+	Labels []string
+}
+
+// Service groups the metric definitions exposed by one component.
+type Service struct {
+	Name        string
+	Definitions []Definition
+}
+
+// Catalog returns the metric definitions of every service, built from the same
+// constructors the services use. Nothing is registered with the global registry.
+func Catalog() ([]Service, error) {
+	groups := []struct {
+		name       string
+		collectors []prometheus.Collector
+	}{
+		{"generator", append(newProducerMetrics(GeneratorNamespace).collectors(), newMQMetrics(GeneratorNamespace).collectors()...)},
+		{"backend", append(newBackendMetrics(BackendNamespace).collectors(), newMQMetrics(BackendNamespace).collectors()...)},
+		{"frontend", newFrontendMetrics(FrontendNamespace).collectors()},
+	}
+
+	services := make([]Service, 0, len(groups))
+	for _, g := range groups {
+		defs, err := describe(g.collectors)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe %s metrics: %w", g.name, err)
+		}
+		services = append(services, Service{Name: g.name, Definitions: defs})
+	}
+
+	return services, nil
+}
+
+// descPattern matches the output of prometheus.Desc.String.
+var descPattern = regexp.MustCompile(`^Desc\{fqName: ("(?:[^"\\]|\\.)*"), help: ("(?:[^"\\]|\\.)*"), constLabels: \{.*\}, variableLabels: \{(.*)\}\}$`)
+
+// describe converts collectors into definitions.
+func describe(collectors []prometheus.Collector) ([]Definition, error) {
+	defs := make([]Definition, 0, len(collectors))
+	for _, c := range collectors {
+		typ, err := collectorType(c)
+		if err != nil {
+			return nil, err
+		}
+
+		ch := make(chan *prometheus.Desc, 1)
+		go func() {
+			c.Describe(ch)
+			close(ch)
+		}()
+
+		for desc := range ch {
+			def, err := parseDesc(desc)
+			if err != nil {
+				return nil, err
+			}
+			def.Type = typ
+			defs = append(defs, def)
+		}
+	}
+	return defs, nil
+}
+
+// collectorType returns the metric type of a collector created by this package.
+func collectorType(c prometheus.Collector) (string, error) {
+	// Gauge must be checked before Counter since a Gauge also satisfies the Counter interface.
+	switch c.(type) {
+	case *prometheus.GaugeVec, prometheus.Gauge:
+		return TypeGauge, nil
+	case *prometheus.CounterVec, prometheus.Counter:
+		return TypeCounter, nil
+	case *prometheus.HistogramVec, prometheus.Histogram:
+		return TypeHistogram, nil
+	default:
+		return "", fmt.Errorf("unsupported collector type %T", c)
+	}
+}
+
+// parseDesc extracts name, help and variable labels from a descriptor.
+func parseDesc(desc *prometheus.Desc) (Definition, error) {
+	match := descPattern.FindStringSubmatch(desc.String())
+	if match == nil {
+		return Definition{}, fmt.Errorf("unrecognized descriptor %s", desc)
+	}
+
+	name, err := strconv.Unquote(match[1])
+	if err != nil {
+		return Definition{}, fmt.Errorf("invalid metric name in %s: %w", desc, err)
+	}
+	help, err := strconv.Unquote(match[2])
+	if err != nil {
+		return Definition{}, fmt.Errorf("invalid help text in %s: %w", desc, err)
+	}
+
+	var labels []string
+	if match[3] != "" {
+		labels = strings.Split(match[3], ",")
+	}
+
+	return Definition{Name: name, Help: help, Labels: labels}, nil
+}
@@ -0,0 +1,199 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Grafana panel layout constants (Grafana uses a 24 column grid).
+const (
+	panelWidth  = 12
+	panelHeight = 8
+)
+
+// rateWindow is the range used for rate() and histogram_quantile() expressions.
+const rateWindow = "5m"
+
+// GrafanaDashboard renders a Grafana dashboard JSON document for a service,
+// with one panel per metric definition.
+func GrafanaDashboard(svc Service) ([]byte, error) {
+	panels := make([]map[string]any, 0, len(svc.Definitions))
+	for i, def := range svc.Definitions {
+		panels = append(panels, map[string]any{
+			"id":          i + 1,
+			"type":        "timeseries",
+			"title":       def.Name,
+			"description": def.Help,
+			"datasource":  map[string]any{"type": "prometheus", "uid": "${datasource}"},
+			"gridPos": map[string]any{
+				"h": panelHeight,
+				"w": panelWidth,
+				"x": (i % 2) * panelWidth,
+				"y": (i / 2) * panelHeight,
+			},
+			"fieldConfig": map[string]any{
+				"defaults": map[string]any{"unit": panelUnit(def)},
+			},
+			"targets": []map[string]any{{
+				"refId":        "A",
+				"expr":         panelQuery(def),
+				"legendFormat": legendFormat(def),
+			}},
+		})
+	}
+
+	dashboard := map[string]any{
+		"uid":           "demo-app-" + svc.Name,
+		"title":         "Demo App / " + svc.Name,
+		"tags":          []string{"demo-app", svc.Name, "generated"},
+		"timezone":      "browser",
+		"schemaVersion": 39,
+		"refresh":       "30s",
+		"time":          map[string]any{"from": "now-1h", "to": "now"},
+		"templating": map[string]any{
+			"list": []map[string]any{{
+				"name":  "datasource",
+				"label": "Data source",
+				"type":  "datasource",
+				"query": "prometheus",
+			}},
+		},
+		"panels": panels,
+	}
+
+	return json.MarshalIndent(dashboard, "", "  ")
+}
+
+// panelQuery returns the PromQL expression plotted for a metric.
+func panelQuery(def Definition) string {
+	switch def.Type {
+	case TypeCounter:
+		return fmt.Sprintf("sum%s (rate(%s[%s]))", byClause(def.Labels), def.Name, rateWindow)
+	case TypeHistogram:
+		return fmt.Sprintf("histogram_quantile(0.95, sum%s (rate(%s_bucket[%s])))",
+			byClause(append([]string{"le"}, def.Labels...)), def.Name, rateWindow)
+	default:
+		return fmt.Sprintf("sum%s (%s)", byClause(def.Labels), def.Name)
+	}
+}
+
+// panelUnit returns the Grafana unit for a metric based on its name and type.
+func panelUnit(def Definition) string {
+	switch {
+	case strings.HasSuffix(def.Name, "_seconds"):
+		return "s"
+	case strings.HasSuffix(def.Name, "_bytes"):
+		return "bytes"
+	case def.Type == TypeCounter:
+		return "ops"
+	default:
+		return "short"
+	}
+}
+
+// legendFormat builds a legend template from the metric labels.
+func legendFormat(def Definition) string {
+	if len(def.Labels) == 0 {
+		return def.Name
+	}
+	parts := make([]string, len(def.Labels))
+	for i, l := range def.Labels {
+		parts[i] = "{{" + l + "}}"
+	}
+	return strings.Join(parts, " ")
+}
+
+// byClause renders a PromQL "by (...)" clause, or nothing when there are no labels.
+func byClause(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return " by (" + strings.Join(labels, ", ") + ")"
+}
+
+// AlertRule is a Prometheus alerting rule.
+type AlertRule struct {
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for"`
+}
+
+// AlertRuleGroup is a named group of alerting rules.
+type AlertRuleGroup struct {
+	Name  string      `yaml:"name"`
+	Rules []AlertRule `yaml:"rules"`
+}
+
+// AlertRules renders a Prometheus rule file with alerts derived from the metric
+// definitions: error/failure counters, latency histograms, connection status and
+// active worker gauges.
+func AlertRules(services []Service) ([]byte, error) {
+	groups := make([]AlertRuleGroup, 0, len(services))
+	for _, svc := range services {
+		var rules []AlertRule
+		for _, def := range svc.Definitions {
+			if rule, ok := alertFor(svc.Name, def); ok {
+				rules = append(rules, rule)
+			}
+		}
+		if len(rules) > 0 {
+			groups = append(groups, AlertRuleGroup{Name: "demo-app-" + svc.Name, Rules: rules})
+		}
+	}
+
+	return yaml.Marshal(map[string]any{"groups": groups})
+}
+
+// alertFor derives an alerting rule for a metric definition, if one applies.
+func alertFor(service string, def Definition) (AlertRule, bool) {
+	rule := AlertRule{
+		Alert:  alertName(def.Name),
+		Labels: map[string]string{"service": service, "severity": "warning"},
+		Annotations: map[string]string{
+			"summary":     fmt.Sprintf("%s: %s", service, def.Help),
+			"description": fmt.Sprintf("Generated from metric %s.", def.Name),
+		},
+	}
+
+	switch {
+	case def.Type == TypeCounter && (strings.HasSuffix(def.Name, "_errors_total") || strings.HasSuffix(def.Name, "_failures_total")):
+		rule.Expr = fmt.Sprintf("sum%s (rate(%s[%s])) > 0.1", byClause(def.Labels), def.Name, rateWindow)
+		rule.For = "10m"
+	case def.Type == TypeHistogram && strings.HasSuffix(def.Name, "_duration_seconds"):
+		rule.Alert += "P95High"
+		rule.Expr = fmt.Sprintf("histogram_quantile(0.95, sum%s (rate(%s_bucket[%s]))) > 1",
+			byClause(append([]string{"le"}, def.Labels...)), def.Name, rateWindow)
+		rule.For = "10m"
+	case def.Type == TypeGauge && strings.HasSuffix(def.Name, "_connection_status"):
+		rule.Alert += "Down"
+		rule.Expr = def.Name + " == 0"
+		rule.For = "2m"
+		rule.Labels["severity"] = "critical"
+	case def.Type == TypeGauge && strings.Contains(def.Name, "_active_"):
+		rule.Alert += "Zero"
+		rule.Expr = def.Name + " == 0"
+		rule.For = "5m"
+		rule.Labels["severity"] = "critical"
+	default:
+		return AlertRule{}, false
+	}
+
+	return rule, true
+}
+
+// alertName converts a snake_case metric name into a CamelCase alert name.
+func alertName(metric string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(metric, "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
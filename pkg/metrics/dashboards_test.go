@@ -0,0 +1,133 @@
+package metrics_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.yaml.in/yaml/v3"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+var _ = Describe("Dashboards", func() {
+	var services []metrics.Service
+
+	BeforeEach(func() {
+		var err error
+		services, err = metrics.Catalog()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("Catalog", func() {
+		It("should describe every service", func() {
+			names := make([]string, 0, len(services))
+			for _, svc := range services {
+				names = append(names, svc.Name)
+				Expect(svc.Definitions).NotTo(BeEmpty())
+			}
+			Expect(names).To(ConsistOf("generator", "backend", "frontend"))
+		})
+
+		It("should carry names, types, help and labels from the metric constructors", func() {
+			var found bool
+			for _, svc := range services {
+				for _, def := range svc.Definitions {
+					Expect(def.Name).NotTo(BeEmpty())
+					Expect(def.Help).NotTo(BeEmpty())
+					Expect(def.Type).To(BeElementOf(metrics.TypeCounter, metrics.TypeGauge, metrics.TypeHistogram))
+
+					if def.Name == "demo_app_backend_consumer_errors_total" {
+						found = true
+						Expect(def.Type).To(Equal(metrics.TypeCounter))
+						Expect(def.Labels).To(Equal([]string{"queue", "error_type"}))
+					}
+				}
+			}
+			Expect(found).To(BeTrue())
+		})
+
+		It("should classify gauges as gauges", func() {
+			for _, svc := range services {
+				for _, def := range svc.Definitions {
+					if strings.HasSuffix(def.Name, "_connection_status") {
+						Expect(def.Type).To(Equal(metrics.TypeGauge))
+					}
+				}
+			}
+		})
+
+		It("should not register metrics with the global registry", func() {
+			families, err := metrics.Registry.Gather()
+			Expect(err).NotTo(HaveOccurred())
+			for _, mf := range families {
+				Expect(mf.GetName()).NotTo(HavePrefix("demo_app"))
+			}
+		})
+	})
+
+	Describe("GrafanaDashboard", func() {
+		It("should render one panel per metric definition", func() {
+			for _, svc := range services {
+				data, err := metrics.GrafanaDashboard(svc)
+				Expect(err).NotTo(HaveOccurred())
+
+				var dashboard struct {
+					UID    string `json:"uid"`
+					Panels []struct {
+						Title   string `json:"title"`
+						Targets []struct {
+							Expr string `json:"expr"`
+						} `json:"targets"`
+					} `json:"panels"`
+				}
+				Expect(json.Unmarshal(data, &dashboard)).To(Succeed())
+				Expect(dashboard.UID).To(Equal("demo-app-" + svc.Name))
+				Expect(dashboard.Panels).To(HaveLen(len(svc.Definitions)))
+				for _, p := range dashboard.Panels {
+					Expect(p.Targets).To(HaveLen(1))
+					Expect(p.Targets[0].Expr).To(ContainSubstring(p.Title))
+				}
+			}
+		})
+
+		It("should use histogram_quantile for histograms", func() {
+			for _, svc := range services {
+				data, err := metrics.GrafanaDashboard(svc)
+				Expect(err).NotTo(HaveOccurred())
+				for _, def := range svc.Definitions {
+					if def.Type == metrics.TypeHistogram {
+						Expect(string(data)).To(ContainSubstring("rate(" + def.Name + "_bucket[5m])"))
+					}
+				}
+			}
+		})
+	})
+
+	Describe("AlertRules", func() {
+		It("should render a valid rule file with one group per service", func() {
+			data, err := metrics.AlertRules(services)
+			Expect(err).NotTo(HaveOccurred())
+
+			var file struct {
+				Groups []metrics.AlertRuleGroup `yaml:"groups"`
+			}
+			Expect(yaml.Unmarshal(data, &file)).To(Succeed())
+			Expect(file.Groups).To(HaveLen(len(services)))
+
+			alerts := map[string]metrics.AlertRule{}
+			for _, g := range file.Groups {
+				for _, r := range g.Rules {
+					Expect(r.Expr).NotTo(BeEmpty())
+					Expect(r.For).NotTo(BeEmpty())
+					alerts[r.Alert] = r
+				}
+			}
+
+			Expect(alerts).To(HaveKey("DemoAppBackendMqConnectionStatusDown"))
+			Expect(alerts["DemoAppBackendMqConnectionStatusDown"].Labels).To(HaveKeyWithValue("severity", "critical"))
+			Expect(alerts).To(HaveKey("DemoAppBackendConsumerErrorsTotal"))
+		})
+	})
+})
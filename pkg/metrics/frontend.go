@@ -19,6 +19,13 @@ type FrontendMetrics struct {
 
 // NewFrontendMetrics creates and registers frontend service metrics.
 func NewFrontendMetrics(namespace string) *FrontendMetrics {
+	m := newFrontendMetrics(namespace)
+	MustRegister(m.collectors()...)
+	return m
+}
+
+// newFrontendMetrics creates frontend service metrics without registering them.
+func newFrontendMetrics(namespace string) *FrontendMetrics {
 	m := &FrontendMetrics{
 		HTTPRequestsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -107,7 +114,12 @@ func NewFrontendMetrics(namespace string) *FrontendMetrics {
 		),
 	}
 
-	MustRegister(
+	return m
+}
+
+// collectors returns all collectors owned by FrontendMetrics.
+func (m *FrontendMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
 		m.HTTPRequestsTotal,
 		m.HTTPRequestDuration,
 		m.HTTPRequestsInFlight,
@@ -117,7 +129,5 @@ func NewFrontendMetrics(namespace string) *FrontendMetrics {
 		m.GRPCClientErrors,
 		m.TemplateRenderTime,
 		m.TemplateRenderErrors,
-	)
-
-	return m
+	}
 }
@@ -12,9 +12,19 @@ import (
 // Registry is the global Prometheus registry for all metrics.
 var Registry = prometheus.NewRegistry()
 
+// registerer registers service metrics with Registry, see SetInstanceID.
+var registerer prometheus.Registerer = Registry
+
 func init() {
-	// Register default Go metrics collectors
-	Registry.MustRegister(collectors.NewGoCollector())
+	// Register default Go metrics collectors, including runtime/metrics based
+	// GC, memory and scheduler series for profiling during load tests
+	Registry.MustRegister(collectors.NewGoCollector(
+		collectors.WithGoCollectorRuntimeMetrics(
+			collectors.MetricsGC,
+			collectors.MetricsMemory,
+			collectors.MetricsScheduler,
+		),
+	))
 	Registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
 }
 
@@ -28,5 +38,21 @@ func Handler() http.Handler {
 // MustRegister registers collectors with the global registry.
 // Panics if registration fails.
 func MustRegister(collectors ...prometheus.Collector) {
-	Registry.MustRegister(collectors...)
+	registerer.MustRegister(collectors...)
+}
+
+// SetInstanceID labels every metric registered afterwards with instance_id,
+// which tells apart replicas of a service behind one scrape target. It must
+// be called before the metrics are created. An empty id adds no label.
+func SetInstanceID(id string) {
+	registerer = instanceRegisterer(Registry, id)
+}
+
+// instanceRegisterer wraps reg to add the instance_id label.
+func instanceRegisterer(reg prometheus.Registerer, id string) prometheus.Registerer {
+	if id == "" {
+		return reg
+	}
+
+	return prometheus.WrapRegistererWith(prometheus.Labels{"instance_id": id}, reg)
 }
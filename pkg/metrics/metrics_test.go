@@ -0,0 +1,36 @@
+package metrics_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+
+	"procodus.dev/demo-app/pkg/metrics"
+)
+
+var _ = Describe("SetInstanceID", func() {
+	It("should label metrics registered afterwards with the instance ID", func() {
+		metrics.SetInstanceID("backend-1")
+		DeferCleanup(metrics.SetInstanceID, "")
+
+		m := metrics.NewMQMetrics("instance_test")
+		m.ReconnectAttempts.Inc()
+
+		families, err := metrics.Registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+
+		var family *dto.MetricFamily
+		for _, f := range families {
+			if f.GetName() == "instance_test_mq_reconnect_attempts_total" {
+				family = f
+			}
+		}
+		Expect(family).NotTo(BeNil())
+		Expect(family.GetMetric()).To(HaveLen(1))
+
+		labels := family.GetMetric()[0].GetLabel()
+		Expect(labels).To(HaveLen(1))
+		Expect(labels[0].GetName()).To(Equal("instance_id"))
+		Expect(labels[0].GetValue()).To(Equal("backend-1"))
+	})
+})
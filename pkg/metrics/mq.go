@@ -14,10 +14,19 @@ type MQMetrics struct {
 	MessagesConsumed    *prometheus.CounterVec
 	ConsumptionFailures *prometheus.CounterVec
 	ConsumeDuration     *prometheus.HistogramVec
+	ConsumeSubscribes   *prometheus.CounterVec
+	ConsumeInterrupts   *prometheus.CounterVec
 }
 
 // NewMQMetrics creates and registers MQ client metrics.
 func NewMQMetrics(namespace string) *MQMetrics {
+	m := newMQMetrics(namespace)
+	MustRegister(m.collectors()...)
+	return m
+}
+
+// newMQMetrics creates MQ client metrics without registering them.
+func newMQMetrics(namespace string) *MQMetrics {
 	m := &MQMetrics{
 		MessagesPushed: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -91,9 +100,32 @@ func NewMQMetrics(namespace string) *MQMetrics {
 			},
 			[]string{"queue"},
 		),
+		ConsumeSubscribes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "mq",
+				Name:      "consume_subscribes_total",
+				Help:      "Total number of times a consume loop subscribed to its queue",
+			},
+			[]string{"queue"},
+		),
+		ConsumeInterrupts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "mq",
+				Name:      "consume_interrupts_total",
+				Help:      "Total number of times a consume loop lost or failed to get its subscription",
+			},
+			[]string{"queue"},
+		),
 	}
 
-	MustRegister(
+	return m
+}
+
+// collectors returns all collectors owned by MQMetrics.
+func (m *MQMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
 		m.MessagesPushed,
 		m.PushFailures,
 		m.ReconnectAttempts,
@@ -102,7 +134,7 @@ func NewMQMetrics(namespace string) *MQMetrics {
 		m.MessagesConsumed,
 		m.ConsumptionFailures,
 		m.ConsumeDuration,
-	)
-
-	return m
+		m.ConsumeSubscribes,
+		m.ConsumeInterrupts,
+	}
 }
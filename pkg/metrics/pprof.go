@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/pprof"
+)
+
+// RegisterPprof registers the net/http/pprof handlers under /debug/pprof/ on the given mux.
+// Profiles can then be grabbed with `go tool pprof http://host:port/debug/pprof/profile`.
+func RegisterPprof(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+// DebugHandler returns an HTTP handler serving both the Prometheus metrics endpoint
+// and the pprof endpoints. It is intended for opt-in debug ports.
+func DebugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	RegisterPprof(mux)
+	return mux
+}
@@ -12,10 +12,21 @@ type ProducerMetrics struct {
 	ActiveProducers       prometheus.Gauge
 	DevicesGenerated      prometheus.Counter
 	SensorReadingsCreated prometheus.Counter
+	UnhealthyProducers    prometheus.Gauge
+	ProducerRestarts      prometheus.Counter
+	LastSuccess           *prometheus.GaugeVec
+	DisorderedReadings    *prometheus.CounterVec
 }
 
 // NewProducerMetrics creates and registers producer metrics.
 func NewProducerMetrics(namespace string) *ProducerMetrics {
+	m := newProducerMetrics(namespace)
+	MustRegister(m.collectors()...)
+	return m
+}
+
+// newProducerMetrics creates producer metrics without registering them.
+func newProducerMetrics(namespace string) *ProducerMetrics {
 	m := &ProducerMetrics{
 		MessagesGenerated: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -24,7 +35,7 @@ func NewProducerMetrics(namespace string) *ProducerMetrics {
 				Name:      "messages_generated_total",
 				Help:      "Total number of messages generated",
 			},
-			[]string{"type"}, // type: device, sensor_reading
+			[]string{"type"}, // type: device, sensor_reading, sensor_reading_batch
 		),
 		GenerationFailures: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -69,16 +80,57 @@ func NewProducerMetrics(namespace string) *ProducerMetrics {
 				Help:      "Total number of sensor readings created",
 			},
 		),
+		UnhealthyProducers: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "producer",
+				Name:      "unhealthy_producers",
+				Help:      "Number of producers without a recent successful push",
+			},
+		),
+		ProducerRestarts: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "producer",
+				Name:      "restarts_total",
+				Help:      "Total number of producer restarts after being unhealthy",
+			},
+		),
+		LastSuccess: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "producer",
+				Name:      "last_success_timestamp_seconds",
+				Help:      "Unix time of the last successful push per producer",
+			},
+			[]string{"producer_id"},
+		),
+		DisorderedReadings: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "producer",
+				Name:      "disordered_readings_total",
+				Help:      "Total number of readings deliberately published out of order or twice",
+			},
+			[]string{"kind"}, // out_of_order, duplicate
+		),
 	}
 
-	MustRegister(
+	return m
+}
+
+// collectors returns all collectors owned by ProducerMetrics.
+func (m *ProducerMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
 		m.MessagesGenerated,
 		m.GenerationFailures,
 		m.GenerationDuration,
 		m.ActiveProducers,
 		m.DevicesGenerated,
 		m.SensorReadingsCreated,
-	)
-
-	return m
+		m.UnhealthyProducers,
+		m.ProducerRestarts,
+		m.LastSuccess,
+		m.DisorderedReadings,
+	}
 }
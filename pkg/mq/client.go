@@ -4,7 +4,10 @@ package mq
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"net"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,20 +21,39 @@ import (
 // automatic reconnection, and provides methods for publishing and consuming messages.
 type Client struct {
 	m               *sync.Mutex
+	publishMu       sync.Mutex // Serializes publishes waiting for confirmations
 	infolog         *slog.Logger
 	errlog          *slog.Logger
 	connection      *amqp.Connection
 	channel         *amqp.Channel
-	done            chan bool
+	ctx             context.Context // Done when the parent context ends or Close is called
+	cancel          context.CancelFunc
+	ready           chan struct{} // Closed while the client is ready
+	stopped         chan struct{} // Closed when the reconnect goroutine exits
+	closeErr        error         // Error closing the channel or connection
 	notifyConnClose chan *amqp.Error
 	notifyChanClose chan *amqp.Error
 	notifyConfirm   chan amqp.Confirmation
-	queueName       string
+	queueName       string   // Queue pushed to and consumed by Consume
+	queues          []string // All queues declared on the channel
+	durable         bool     // Durable queues and persistent messages
+	maxPriority     uint8    // x-max-priority of the queues, 0 for none
+	compression     string   // Algorithm compressing published bodies, empty for none
+	consumerTag     string   // Prefix of consumer tags, empty for server-generated ones
 	isReady         bool
+	connected       bool               // Whether a connection was ever established
+	closed          bool               // Whether Close was called
 	metrics         *metrics.MQMetrics // Optional metrics
+	faults          FaultInjector      // Optional fault injection for tests
 }
 
 const (
+	// Timeout for establishing a connection, including the AMQP handshake.
+	dialTimeout = 30 * time.Second
+
+	// Heartbeat interval negotiated with the server, as amqp.Dial uses.
+	heartbeat = 10 * time.Second
+
 	// When reconnecting to the server after connection failure.
 	reconnectDelay = 5 * time.Second
 
@@ -52,24 +74,137 @@ const (
 )
 
 var (
+	// ErrAlreadyClosed is returned by Close when the client was closed before.
+	ErrAlreadyClosed = errors.New("already closed")
+	// ErrNeverConnected is returned by Close when the client never connected
+	// to the server. The client is stopped regardless.
+	ErrNeverConnected = errors.New("closed before connecting to the server")
+)
+
+var (
+	errNoQueues           = errors.New("at least one queue is required")
 	errNotConnected       = errors.New("not connected to a server")
-	errAlreadyClosed      = errors.New("already closed: not connected to the server")
 	errShutdown           = errors.New("client is shutting down")
 	errMaxRetriesExceeded = errors.New("maximum retry attempts exceeded")
 )
 
 // New creates a new consumer state instance, and automatically
-// attempts to connect to the server.
+// attempts to connect to the server. It keeps reconnecting until Close is
+// called; use NewWithContext to bind the client to a context.
 func New(queueName, addr string, l *slog.Logger) *Client {
-	client := Client{
+	client := newClient(context.Background(), []string{queueName}, l)
+	go client.handleReconnect(addr)
+	return client
+}
+
+// NewWithContext creates a new client and automatically attempts to connect
+// to the server. The client reconnects until ctx is done or Close is called,
+// after which the channel and connection are closed. Malformed URLs are
+// rejected immediately.
+func NewWithContext(ctx context.Context, queueName, addr string, l *slog.Logger) (*Client, error) {
+	if _, err := amqp.ParseURI(addr); err != nil {
+		return nil, fmt.Errorf("invalid AMQP URL: %w", err)
+	}
+
+	client := newClient(ctx, []string{queueName}, l)
+	go client.handleReconnect(addr)
+	return client, nil
+}
+
+// NewWithQueues is NewWithContext for a client that declares several queues
+// on one connection, e.g. to consume them all with ConsumeQueues. The first
+// queue is the one Push, UnsafePush, Consume and ConsumeLoop use.
+func NewWithQueues(ctx context.Context, queues []string, addr string, l *slog.Logger) (*Client, error) {
+	return NewWithOptions(ctx, addr, Options{Queues: queues}, l)
+}
+
+// Options configures a client created with NewWithOptions.
+type Options struct {
+	// Queues are declared on the channel. The first one is the queue Push,
+	// UnsafePush, Consume and ConsumeLoop use.
+	Queues []string
+	// Durable declares the queues durable and publishes persistent messages,
+	// so both survive a broker restart. All clients of a queue must agree, as
+	// RabbitMQ refuses to redeclare a queue with different settings.
+	Durable bool
+	// MaxPriority declares the queues as priority queues (x-max-priority)
+	// whose consumers get messages of a higher priority first (0-9, 0 = no
+	// priorities). Like Durable, all clients of a queue must agree.
+	MaxPriority uint8
+	// Compression compresses the bodies of published messages of at least
+	// 512 bytes with CompressionGzip or CompressionZstd (empty = none).
+	// Consume loops decompress deliveries whatever the setting, so consumers
+	// need not agree.
+	Compression string
+	// ConsumerTag identifies the client's consumers on the broker, e.g. the
+	// instance consuming a queue. The queue name is appended per consumer.
+	// Empty lets the server generate tags.
+	ConsumerTag string
+	// Faults injects failures to test reconnects and push retries (optional).
+	Faults FaultInjector
+}
+
+// FaultInjector decides when the client fails on purpose, for testing.
+type FaultInjector interface {
+	// DropConnection reports whether to close the connection before the
+	// next publish, as if the broker went away.
+	DropConnection() bool
+}
+
+// NewWithOptions is NewWithContext with the queues and their settings given
+// by opts.
+func NewWithOptions(ctx context.Context, addr string, opts Options, l *slog.Logger) (*Client, error) {
+	if len(opts.Queues) == 0 {
+		return nil, errNoQueues
+	}
+
+	seen := make(map[string]bool, len(opts.Queues))
+	for _, queue := range opts.Queues {
+		if queue == "" {
+			return nil, errors.New("queue name cannot be empty")
+		}
+		if seen[queue] {
+			return nil, fmt.Errorf("duplicate queue %q", queue)
+		}
+		seen[queue] = true
+	}
+
+	if opts.MaxPriority > MaxPriority {
+		return nil, errInvalidPriority
+	}
+
+	if !validCompression(opts.Compression) {
+		return nil, errUnknownCompression
+	}
+
+	if _, err := amqp.ParseURI(addr); err != nil {
+		return nil, fmt.Errorf("invalid AMQP URL: %w", err)
+	}
+
+	client := newClient(ctx, opts.Queues, l)
+	client.durable = opts.Durable
+	client.maxPriority = opts.MaxPriority
+	client.compression = opts.Compression
+	client.consumerTag = opts.ConsumerTag
+	client.faults = opts.Faults
+	go client.handleReconnect(addr)
+	return client, nil
+}
+
+func newClient(ctx context.Context, queues []string, l *slog.Logger) *Client {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &Client{
 		m:         &sync.Mutex{},
 		infolog:   l,
 		errlog:    l,
-		queueName: queueName,
-		done:      make(chan bool),
+		queueName: queues[0],
+		queues:    queues,
+		ctx:       ctx,
+		cancel:    cancel,
+		ready:     make(chan struct{}),
+		stopped:   make(chan struct{}),
 	}
-	go client.handleReconnect(addr)
-	return &client
 }
 
 // SetMetrics sets the metrics collector for this client.
@@ -80,11 +215,19 @@ func (client *Client) SetMetrics(m *metrics.MQMetrics) {
 
 // handleReconnect will wait for a connection error on
 // notifyConnClose, and then continuously attempt to reconnect.
+// It returns once the client's context is done, closing the connection.
 func (client *Client) handleReconnect(addr string) {
+	defer close(client.stopped)
+	defer client.shutdown()
+
+	// A malformed URL never connects, so there is no point in retrying
+	if _, err := amqp.ParseURI(addr); err != nil {
+		client.errlog.Error("invalid AMQP URL, not connecting", "error", err)
+		return
+	}
+
 	for {
-		client.m.Lock()
-		client.isReady = false
-		client.m.Unlock()
+		client.setReady(false)
 
 		client.infolog.Info("attempting to connect")
 
@@ -98,7 +241,7 @@ func (client *Client) handleReconnect(addr string) {
 			client.errlog.Error("failed to connect. Retrying...", "error", err)
 
 			select {
-			case <-client.done:
+			case <-client.ctx.Done():
 				return
 			case <-time.After(reconnectDelay):
 			}
@@ -113,7 +256,11 @@ func (client *Client) handleReconnect(addr string) {
 
 // connect will create a new AMQP connection.
 func (client *Client) connect(addr string) (*amqp.Connection, error) {
-	conn, err := amqp.Dial(addr)
+	conn, err := amqp.DialConfig(addr, amqp.Config{
+		Heartbeat: heartbeat,
+		Locale:    "en_US",
+		Dial:      client.dial,
+	})
 	if err != nil {
 		// Update connection status metric
 		if client.metrics != nil {
@@ -123,6 +270,9 @@ func (client *Client) connect(addr string) (*amqp.Connection, error) {
 	}
 
 	client.changeConnection(conn)
+	client.m.Lock()
+	client.connected = true
+	client.m.Unlock()
 	client.infolog.Info("connected")
 
 	// Update connection status metric
@@ -133,20 +283,84 @@ func (client *Client) connect(addr string) (*amqp.Connection, error) {
 	return conn, nil
 }
 
+// dial opens the TCP connection for amqp.DialConfig. Unlike amqp.DefaultDial
+// it is aborted when the client's context is done, so Close does not wait for
+// a hanging dial.
+func (client *Client) dial(network, addr string) (net.Conn, error) {
+	conn, err := (&net.Dialer{Timeout: dialTimeout}).DialContext(client.ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bound the AMQP handshake like amqp.DefaultDial; the library clears the
+	// deadline once the connection is open
+	if err := conn.SetDeadline(time.Now().Add(dialTimeout)); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// Ping opens and closes an AMQP connection to addr to check that the broker is
+// reachable and accepts the credentials. The dial and handshake are bounded
+// by ctx and the dial timeout.
+func Ping(ctx context.Context, addr string) error {
+	conn, err := amqp.DialConfig(addr, amqp.Config{
+		Heartbeat: heartbeat,
+		Locale:    "en_US",
+		Dial: func(network, addr string) (net.Conn, error) {
+			conn, err := (&net.Dialer{Timeout: dialTimeout}).DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+
+			deadline := time.Now().Add(dialTimeout)
+			if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+				deadline = d
+			}
+			if err := conn.SetDeadline(deadline); err != nil {
+				_ = conn.Close()
+				return nil, err
+			}
+
+			return conn, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// dropConnection closes the current connection like a broker failure would,
+// which makes the client reconnect.
+func (client *Client) dropConnection() {
+	client.m.Lock()
+	conn := client.connection
+	client.m.Unlock()
+
+	if conn == nil {
+		return
+	}
+
+	client.errlog.Warn("injected fault: dropping connection")
+	_ = conn.Close()
+}
+
 // handleReInit will wait for a channel error
 // and then continuously attempt to re-initialize both channels.
 func (client *Client) handleReInit(conn *amqp.Connection) bool {
 	for {
-		client.m.Lock()
-		client.isReady = false
-		client.m.Unlock()
+		client.setReady(false)
 
 		err := client.init(conn)
 		if err != nil {
 			client.errlog.Error("failed to initialize channel, retrying...", "error", err)
 
 			select {
-			case <-client.done:
+			case <-client.ctx.Done():
 				return true
 			case <-client.notifyConnClose:
 				client.infolog.Info("connection closed, reconnecting...")
@@ -157,7 +371,7 @@ func (client *Client) handleReInit(conn *amqp.Connection) bool {
 		}
 
 		select {
-		case <-client.done:
+		case <-client.ctx.Done():
 			return true
 		case <-client.notifyConnClose:
 			client.infolog.Info("connection closed, reconnecting...")
@@ -168,7 +382,7 @@ func (client *Client) handleReInit(conn *amqp.Connection) bool {
 	}
 }
 
-// init will initialize channel & declare queue.
+// init will initialize channel & declare the queues.
 func (client *Client) init(conn *amqp.Connection) error {
 	ch, err := conn.Channel()
 	if err != nil {
@@ -179,31 +393,113 @@ func (client *Client) init(conn *amqp.Connection) error {
 	if err != nil {
 		return err
 	}
-	_, err = ch.QueueDeclare(
-		client.queueName,
-		false, // Durable
-		false, // Delete when unused
-		false, // Exclusive
-		false, // No-wait
-		nil,   // Arguments
-	)
-	if err != nil {
-		return err
+	for _, queue := range client.queues {
+		_, err = ch.QueueDeclare(
+			queue,
+			client.durable, // Durable
+			false,          // Delete when unused
+			false,          // Exclusive
+			false,          // No-wait
+			client.queueArgs(),
+		)
+		if err != nil {
+			return err
+		}
 	}
 
 	client.changeChannel(ch)
-	client.m.Lock()
-	client.isReady = true
-	client.m.Unlock()
+	client.setReady(true)
 	client.infolog.Info("client init done")
 
 	return nil
 }
 
+// queueArgs returns the arguments the client declares its queues with.
+func (client *Client) queueArgs() amqp.Table {
+	if client.maxPriority == 0 {
+		return nil
+	}
+
+	return amqp.Table{"x-max-priority": int32(client.maxPriority)}
+}
+
+// setReady updates the readiness and wakes up WaitReady callers once ready.
+func (client *Client) setReady(ready bool) {
+	client.m.Lock()
+	defer client.m.Unlock()
+
+	client.setReadyLocked(ready)
+}
+
+// setReadyLocked is setReady for callers holding client.m.
+func (client *Client) setReadyLocked(ready bool) {
+	switch {
+	case ready && !client.isReady:
+		close(client.ready)
+	case !ready && client.isReady:
+		client.ready = make(chan struct{})
+	}
+	client.isReady = ready
+}
+
+// ServerVersion implements ServerVersioner with the product and version the
+// server announced on the current connection.
+func (client *Client) ServerVersion() string {
+	client.m.Lock()
+	defer client.m.Unlock()
+
+	if client.connection == nil {
+		return ""
+	}
+
+	product, _ := client.connection.Properties["product"].(string)
+	version, _ := client.connection.Properties["version"].(string)
+	return strings.TrimSpace(product + " " + version)
+}
+
+// WaitReady blocks until the client is connected and its channel is set up,
+// the context is done, or the client is closed.
+func (client *Client) WaitReady(ctx context.Context) error {
+	client.m.Lock()
+	ready := client.ready
+	client.m.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-client.ctx.Done():
+		return errShutdown
+	}
+}
+
+// shutdown closes whatever channel and connection are left once the
+// reconnect loop ends.
+func (client *Client) shutdown() {
+	client.m.Lock()
+	defer client.m.Unlock()
+
+	if client.channel != nil && !client.channel.IsClosed() {
+		client.closeErr = client.channel.Close()
+	}
+	if client.connection != nil && !client.connection.IsClosed() {
+		client.closeErr = errors.Join(client.closeErr, client.connection.Close())
+	}
+	client.setReadyLocked(false)
+
+	// Update connection status metric
+	if client.metrics != nil {
+		client.metrics.ConnectionStatus.Set(0)
+	}
+}
+
 // changeConnection takes a new connection to the queue,
 // and updates the close listener to reflect this.
 func (client *Client) changeConnection(connection *amqp.Connection) {
+	client.m.Lock()
 	client.connection = connection
+	client.m.Unlock()
 	client.notifyConnClose = make(chan *amqp.Error, 1)
 	client.connection.NotifyClose(client.notifyConnClose)
 }
@@ -226,6 +522,31 @@ func (client *Client) changeChannel(channel *amqp.Channel) {
 // allowing time for automatic reconnection to succeed.
 // After maxRetryAttempts (5) failed attempts, returns a fatal error.
 func (client *Client) Push(ctx context.Context, data []byte) error {
+	msg := defaultPublishing(data)
+	if err := Compress(&msg, client.compression); err != nil {
+		return err
+	}
+
+	return client.push(ctx, msg)
+}
+
+// PushWithOptions is Push with per-message properties such as content type,
+// headers and message ID. Invalid options are rejected before publishing.
+func (client *Client) PushWithOptions(ctx context.Context, data []byte, opts PublishOptions) error {
+	msg, err := opts.Publishing(data)
+	if err != nil {
+		return err
+	}
+
+	if err := Compress(&msg, client.compression); err != nil {
+		return err
+	}
+
+	return client.push(ctx, msg)
+}
+
+// push publishes msg and waits for a confirmation, retrying with backoff.
+func (client *Client) push(ctx context.Context, msg amqp.Publishing) error {
 	// Track duration
 	var timer *prometheus.Timer
 	if client.metrics != nil {
@@ -254,10 +575,11 @@ func (client *Client) Push(ctx context.Context, data []byte) error {
 		// Check if connected
 		client.m.Lock()
 		isReady := client.isReady
+		ready := client.ready
 		client.m.Unlock()
 
 		if !isReady {
-			// Not connected - use exponential backoff to wait for reconnection
+			// Not connected - wait for reconnection, up to an exponential backoff
 			client.infolog.Info("not connected, waiting for reconnection",
 				"backoff", backoff,
 				"retry_count", retryCount)
@@ -265,8 +587,10 @@ func (client *Client) Push(ctx context.Context, data []byte) error {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			case <-client.done:
+			case <-client.ctx.Done():
 				return errShutdown
+			case <-ready:
+				continue
 			case <-time.After(backoff):
 				// Increase backoff exponentially
 				backoff *= backoffMultiplier
@@ -278,8 +602,19 @@ func (client *Client) Push(ctx context.Context, data []byte) error {
 			}
 		}
 
-		// Attempt to push
-		err := client.UnsafePush(ctx, data)
+		if client.faults != nil && client.faults.DropConnection() {
+			client.dropConnection()
+		}
+
+		// Attempt to push and wait for the confirmation
+		confirm, err := client.publishConfirmed(ctx, msg)
+		if ctx.Err() != nil {
+			// Track failure
+			if client.metrics != nil {
+				client.metrics.PushFailures.WithLabelValues(client.queueName, "context_canceled").Inc()
+			}
+			return ctx.Err()
+		}
 		if err != nil {
 			client.errlog.Error("push failed, retrying with backoff",
 				"error", err,
@@ -289,7 +624,7 @@ func (client *Client) Push(ctx context.Context, data []byte) error {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			case <-client.done:
+			case <-client.ctx.Done():
 				return errShutdown
 			case <-time.After(backoff):
 				// Increase backoff exponentially
@@ -302,51 +637,227 @@ func (client *Client) Push(ctx context.Context, data []byte) error {
 			}
 		}
 
-		// Wait for confirmation
+		if confirm.Ack {
+			// Track success
+			if client.metrics != nil {
+				client.metrics.MessagesPushed.WithLabelValues(client.queueName).Inc()
+			}
+
+			if retryCount > 0 {
+				client.infolog.Info("push confirmed after retries",
+					"delivery_tag", confirm.DeliveryTag,
+					"retry_count", retryCount)
+			} else {
+				client.infolog.Info("push confirmed", "delivery_tag", confirm.DeliveryTag)
+			}
+			return nil
+		}
+		// Negative acknowledgment - retry with backoff
+		client.errlog.Warn("push not acknowledged, retrying",
+			"delivery_tag", confirm.DeliveryTag,
+			"backoff", backoff)
+
 		select {
 		case <-ctx.Done():
+			return ctx.Err()
+		case <-client.ctx.Done():
+			return errShutdown
+		case <-time.After(backoff):
+			// Increase backoff exponentially
+			backoff *= backoffMultiplier
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+			retryCount++
+			continue
+		}
+	}
+}
+
+// publishConfirmed publishes msg and waits for its confirmation. Push and
+// PushBatch share the confirmations of the channel, so publishes waiting for
+// them are serialized, and confirmations of earlier publishes whose callers
+// gave up waiting are skipped. A channel closing before the confirmation
+// arrives counts as a negative acknowledgment.
+func (client *Client) publishConfirmed(ctx context.Context, msg amqp.Publishing) (amqp.Confirmation, error) {
+	client.publishMu.Lock()
+	defer client.publishMu.Unlock()
+
+	client.m.Lock()
+	if !client.isReady {
+		client.m.Unlock()
+		return amqp.Confirmation{}, errNotConnected
+	}
+	channel := client.channel
+	confirms := client.notifyConfirm
+	client.m.Unlock()
+
+	if client.durable {
+		msg.DeliveryMode = amqp.Persistent
+	}
+
+	tag := channel.GetNextPublishSeqNo()
+	if err := channel.PublishWithContext(ctx, "", client.queueName, false, false, msg); err != nil {
+		return amqp.Confirmation{}, err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return amqp.Confirmation{}, ctx.Err()
+		case <-client.ctx.Done():
+			return amqp.Confirmation{}, errShutdown
+		case confirm, ok := <-confirms:
+			if !ok {
+				return amqp.Confirmation{DeliveryTag: tag}, nil
+			}
+			if confirm.DeliveryTag == tag {
+				return confirm, nil
+			}
+		}
+	}
+}
+
+// PushBatch pushes all messages and waits until the server confirmed each of
+// them. Unlike calling Push in a loop, the messages are published back to
+// back and confirmed together. Messages that are rejected or lost to a
+// reconnect are pushed again with backoff; after maxRetryAttempts (5) failed
+// attempts, returns a fatal error. Messages may be delivered more than once.
+// Push and PushBatch are safe for concurrent use; their publishes take turns.
+func (client *Client) PushBatch(ctx context.Context, data [][]byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	// Track duration
+	var timer *prometheus.Timer
+	if client.metrics != nil {
+		timer = prometheus.NewTimer(client.metrics.PushDuration.WithLabelValues(client.queueName))
+		defer timer.ObserveDuration()
+	}
+
+	pending := data
+	backoff := initialBackoff
+
+	for retryCount := 0; ; retryCount++ {
+		if retryCount >= maxRetryAttempts {
+			client.errlog.Error("maximum retry attempts exceeded",
+				"retry_count", retryCount,
+				"max_attempts", maxRetryAttempts,
+				"unconfirmed", len(pending))
+
 			// Track failure
 			if client.metrics != nil {
-				client.metrics.PushFailures.WithLabelValues(client.queueName, "context_canceled").Inc()
+				client.metrics.PushFailures.WithLabelValues(client.queueName, "max_retries_exceeded").Add(float64(len(pending)))
 			}
+
+			return errMaxRetriesExceeded
+		}
+
+		unconfirmed, err := client.publishBatch(ctx, pending)
+		if ctx.Err() != nil {
 			return ctx.Err()
-		case confirm := <-client.notifyConfirm:
-			if confirm.Ack {
-				// Track success
-				if client.metrics != nil {
-					client.metrics.MessagesPushed.WithLabelValues(client.queueName).Inc()
-				}
+		}
 
-				if retryCount > 0 {
-					client.infolog.Info("push confirmed after retries",
-						"delivery_tag", confirm.DeliveryTag,
-						"retry_count", retryCount)
-				} else {
-					client.infolog.Info("push confirmed", "delivery_tag", confirm.DeliveryTag)
-				}
-				return nil
+		if len(unconfirmed) == 0 {
+			// Track success
+			if client.metrics != nil {
+				client.metrics.MessagesPushed.WithLabelValues(client.queueName).Add(float64(len(data)))
 			}
-			// Negative acknowledgment - retry with backoff
-			client.errlog.Warn("push not acknowledged, retrying",
-				"delivery_tag", confirm.DeliveryTag,
-				"backoff", backoff)
 
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-client.done:
-				return errShutdown
-			case <-time.After(backoff):
-				// Increase backoff exponentially
-				backoff *= backoffMultiplier
-				if backoff > maxBackoff {
-					backoff = maxBackoff
-				}
-				retryCount++
+			client.infolog.Info("batch confirmed", "messages", len(data), "retry_count", retryCount)
+			return nil
+		}
+
+		client.errlog.Warn("batch not fully confirmed, retrying with backoff",
+			"error", err,
+			"unconfirmed", len(unconfirmed),
+			"backoff", backoff,
+			"retry_count", retryCount)
+		pending = unconfirmed
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-client.ctx.Done():
+			return errShutdown
+		case <-time.After(backoff):
+			// Increase backoff exponentially
+			backoff *= backoffMultiplier
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+	}
+}
+
+// publishBatch publishes data and waits for the confirmations. It returns the
+// messages that were not confirmed, and the error that stopped publishing.
+func (client *Client) publishBatch(ctx context.Context, data [][]byte) ([][]byte, error) {
+	// Confirmations are shared with Push, see publishConfirmed
+	client.publishMu.Lock()
+	defer client.publishMu.Unlock()
+
+	client.m.Lock()
+	if !client.isReady {
+		client.m.Unlock()
+		return data, errNotConnected
+	}
+	channel := client.channel
+	confirms := client.notifyConfirm
+	client.m.Unlock()
+
+	// Delivery tags count up from here, one per published message
+	firstTag := channel.GetNextPublishSeqNo()
+
+	var err error
+	published := 0
+	for _, body := range data {
+		msg := defaultPublishing(body)
+		if err = Compress(&msg, client.compression); err != nil {
+			break
+		}
+		if client.durable {
+			msg.DeliveryMode = amqp.Persistent
+		}
+
+		if err = channel.PublishWithContext(ctx, "", client.queueName, false, false, msg); err != nil {
+			break
+		}
+		published++
+	}
+
+	acked := make([]bool, len(data))
+
+wait:
+	for received := 0; received < published; {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break wait
+		case confirm, ok := <-confirms:
+			if !ok {
+				// The channel closed before confirming everything
+				break wait
+			}
+
+			// Confirmations of earlier publishes are skipped
+			if confirm.DeliveryTag < firstTag || confirm.DeliveryTag-firstTag >= uint64(published) {
 				continue
 			}
+			acked[confirm.DeliveryTag-firstTag] = confirm.Ack
+			received++
 		}
 	}
+
+	var unconfirmed [][]byte
+	for i, body := range data {
+		if !acked[i] {
+			unconfirmed = append(unconfirmed, body)
+		}
+	}
+
+	return unconfirmed, err
 }
 
 // UnsafePush will push to the queue without checking for
@@ -354,6 +865,16 @@ func (client *Client) Push(ctx context.Context, data []byte) error {
 // No guarantees are provided for whether the server will
 // receive the message. The context is used for cancellation and timeout.
 func (client *Client) UnsafePush(ctx context.Context, data []byte) error {
+	msg := defaultPublishing(data)
+	if err := Compress(&msg, client.compression); err != nil {
+		return err
+	}
+
+	return client.publish(ctx, msg)
+}
+
+// publish sends msg to the queue without waiting for a confirmation.
+func (client *Client) publish(ctx context.Context, msg amqp.Publishing) error {
 	client.m.Lock()
 	if !client.isReady {
 		client.m.Unlock()
@@ -361,16 +882,17 @@ func (client *Client) UnsafePush(ctx context.Context, data []byte) error {
 	}
 	client.m.Unlock()
 
+	if client.durable {
+		msg.DeliveryMode = amqp.Persistent
+	}
+
 	return client.channel.PublishWithContext(
 		ctx,
 		"",               // Exchange
 		client.queueName, // Routing key
 		false,            // Mandatory
 		false,            // Immediate
-		amqp.Publishing{
-			ContentType: "text/plain",
-			Body:        data,
-		},
+		msg,
 	)
 }
 
@@ -379,6 +901,11 @@ func (client *Client) UnsafePush(ctx context.Context, data []byte) error {
 // successfully processed, or delivery.Nack when it fails.
 // Ignoring this will cause data to build up on the server.
 func (client *Client) Consume() (<-chan amqp.Delivery, error) {
+	return client.consume(client.queueName)
+}
+
+// consume starts consuming queue on the current channel.
+func (client *Client) consume(queue string) (<-chan amqp.Delivery, error) {
 	client.m.Lock()
 	if !client.isReady {
 		client.m.Unlock()
@@ -394,9 +921,14 @@ func (client *Client) Consume() (<-chan amqp.Delivery, error) {
 		return nil, err
 	}
 
+	tag := ""
+	if client.consumerTag != "" {
+		tag = client.consumerTag + "-" + queue
+	}
+
 	return client.channel.Consume(
-		client.queueName,
-		"",    // Consumer
+		queue,
+		tag,   // Consumer
 		false, // Auto-Ack
 		false, // Exclusive
 		false, // No-local
@@ -405,32 +937,28 @@ func (client *Client) Consume() (<-chan amqp.Delivery, error) {
 	)
 }
 
-// Close will cleanly shut down the channel and connection.
+// Close stops reconnecting, closes the channel and connection and waits for
+// the reconnect goroutine to exit. It is safe to call more than once: later
+// calls return ErrAlreadyClosed. Closing a client that never connected
+// returns ErrNeverConnected after stopping it.
 func (client *Client) Close() error {
 	client.m.Lock()
-	// we read and write isReady in two locations, so we grab the lock and hold onto
-	// it until we are finished
-	defer client.m.Unlock()
-
-	if !client.isReady {
-		return errAlreadyClosed
-	}
-	close(client.done)
-	err := client.channel.Close()
-	if err != nil {
-		return err
-	}
-	err = client.connection.Close()
-	if err != nil {
-		return err
+	if client.closed {
+		client.m.Unlock()
+		return ErrAlreadyClosed
 	}
+	client.closed = true
+	client.m.Unlock()
 
-	client.isReady = false
+	client.cancel()
+	<-client.stopped
 
-	// Update connection status metric
-	if client.metrics != nil {
-		client.metrics.ConnectionStatus.Set(0)
+	client.m.Lock()
+	defer client.m.Unlock()
+
+	if !client.connected {
+		return ErrNeverConnected
 	}
 
-	return nil
+	return client.closeErr
 }
@@ -8,6 +8,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	amqp "github.com/rabbitmq/amqp091-go"
 
 	"procodus.dev/demo-app/pkg/mq"
 )
@@ -42,6 +43,99 @@ var _ = Describe("MQ Client", func() {
 		})
 	})
 
+	Describe("NewWithContext", func() {
+		It("should reject malformed URLs", func() {
+			client, err := mq.NewWithContext(context.Background(), "test-queue", "http://localhost:5672", logger)
+			Expect(err).To(MatchError(ContainSubstring("invalid AMQP URL")))
+			Expect(client).To(BeNil())
+		})
+
+		It("should stop reconnecting when the context is canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			client, err := mq.NewWithContext(ctx, "test-queue", "amqp://invalid:5672", logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			cancel()
+
+			Expect(client.WaitReady(context.Background())).To(MatchError(ContainSubstring("shutting down")))
+			Expect(client.Push(context.Background(), []byte("test"))).To(MatchError(ContainSubstring("shutting down")))
+		})
+	})
+
+	Describe("NewWithQueues", func() {
+		DescribeTable("should reject invalid queue lists",
+			func(queues []string, message string) {
+				client, err := mq.NewWithQueues(context.Background(), queues, "amqp://invalid:5672", logger)
+				Expect(err).To(MatchError(ContainSubstring(message)))
+				Expect(client).To(BeNil())
+			},
+			Entry("no queues", nil, "at least one queue"),
+			Entry("empty name", []string{"sensor-data", ""}, "cannot be empty"),
+			Entry("duplicate", []string{"sensor-data", "sensor-data"}, "duplicate queue"),
+		)
+
+		It("should reject malformed URLs", func() {
+			client, err := mq.NewWithQueues(context.Background(), []string{"test-queue"}, "http://localhost:5672", logger)
+			Expect(err).To(MatchError(ContainSubstring("invalid AMQP URL")))
+			Expect(client).To(BeNil())
+		})
+
+		It("should reject priorities above the maximum", func() {
+			client, err := mq.NewWithOptions(context.Background(), "amqp://invalid:5672", mq.Options{
+				Queues:      []string{"device-data"},
+				MaxPriority: mq.MaxPriority + 1,
+			}, logger)
+			Expect(err).To(MatchError(ContainSubstring("priority must be between 0 and 9")))
+			Expect(client).To(BeNil())
+		})
+
+		It("should reject unknown compression algorithms", func() {
+			client, err := mq.NewWithOptions(context.Background(), "amqp://invalid:5672", mq.Options{
+				Queues:      []string{"sensor-data"},
+				Compression: "brotli",
+			}, logger)
+			Expect(err).To(MatchError(ContainSubstring("compression must be empty, gzip or zstd")))
+			Expect(client).To(BeNil())
+		})
+
+		It("should not report a server version before connecting", func() {
+			client, err := mq.NewWithQueues(context.Background(), []string{"sensor-data"}, "amqp://invalid:5672", logger)
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = client.Close() }()
+
+			Expect(client.ServerVersion()).To(BeEmpty())
+		})
+
+		It("should consume all queues until the client is closed", func() {
+			client, err := mq.NewWithQueues(context.Background(), []string{"sensor-data", "device-data"}, "amqp://invalid:5672", logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			result := make(chan error, 1)
+			go func() {
+				result <- client.ConsumeQueues(context.Background(), map[string]mq.Handler{
+					"sensor-data": func(context.Context, amqp.Delivery) {},
+					"device-data": func(context.Context, amqp.Delivery) {},
+				}, mq.ConsumeHooks{})
+			}()
+
+			Consistently(result).ShouldNot(Receive())
+			Expect(client.Close()).To(MatchError(mq.ErrNeverConnected))
+			Eventually(result).Should(Receive(BeNil()))
+		})
+	})
+
+	Describe("WaitReady", func() {
+		It("should wait until the context is done while not connected", func() {
+			client := mq.New("test-queue", "amqp://invalid:5672", logger)
+			defer func() { _ = client.Close() }()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			Expect(client.WaitReady(ctx)).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+
 	Describe("Push", func() {
 		Context("when not connected", func() {
 			It("should retry with backoff and timeout", func() {
@@ -96,6 +190,34 @@ var _ = Describe("MQ Client", func() {
 				_ = client.Close()
 			})
 
+			It("should reject invalid options without retrying", func() {
+				client := mq.New("test-queue", "amqp://invalid:5672", logger)
+				defer func() { _ = client.Close() }()
+
+				start := time.Now()
+				err := client.PushWithOptions(context.Background(), []byte("test message"), mq.PublishOptions{Priority: 42})
+				Expect(err).To(MatchError(ContainSubstring("priority")))
+				Expect(time.Since(start)).To(BeNumerically("<", 100*time.Millisecond))
+			})
+
+			It("should return immediately for an empty batch", func() {
+				client := mq.New("test-queue", "amqp://invalid:5672", logger)
+				defer func() { _ = client.Close() }()
+
+				Expect(client.PushBatch(context.Background(), nil)).To(Succeed())
+			})
+
+			It("should retry a batch until the context is done", func() {
+				client := mq.New("test-queue", "amqp://invalid:5672", logger)
+				defer func() { _ = client.Close() }()
+
+				ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+				defer cancel()
+
+				err := client.PushBatch(ctx, [][]byte{[]byte("one"), []byte("two")})
+				Expect(err).To(MatchError(context.DeadlineExceeded))
+			})
+
 			It("should return error for UnsafePush", func() {
 				client := mq.New("test-queue", "amqp://invalid:5672", logger)
 
@@ -129,34 +251,64 @@ var _ = Describe("MQ Client", func() {
 	})
 
 	Describe("Close", func() {
-		Context("when not connected", func() {
-			It("should return already closed error", func() {
+		Context("when never connected", func() {
+			It("should return never connected error", func() {
 				client := mq.New("test-queue", "amqp://invalid:5672", logger)
 
 				// Give client time to attempt connection and fail
 				time.Sleep(100 * time.Millisecond)
 
 				err := client.Close()
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("already closed"))
+				Expect(err).To(MatchError(mq.ErrNeverConnected))
+			})
+
+			It("should stop the reconnect goroutine", func() {
+				client := mq.New("test-queue", "amqp://invalid:5672", logger)
+
+				Expect(client.Close()).To(MatchError(mq.ErrNeverConnected))
+
+				// Waiters are released instead of waiting for a reconnect
+				Expect(client.WaitReady(context.Background())).To(MatchError(ContainSubstring("shutting down")))
+			})
+
+			It("should not wait for a dial to time out", func() {
+				// A non-routable address makes the dial hang until its timeout
+				client := mq.New("test-queue", "amqp://10.255.255.1:5672", logger)
+				time.Sleep(100 * time.Millisecond)
+
+				start := time.Now()
+				Expect(client.Close()).To(MatchError(mq.ErrNeverConnected))
+				Expect(time.Since(start)).To(BeNumerically("<", time.Second))
 			})
 		})
 
 		Context("when closing twice", func() {
-			It("should return error on second close", func() {
+			It("should return already closed error on second close", func() {
 				client := mq.New("test-queue", "amqp://invalid:5672", logger)
 
 				// Give client time to attempt connection and fail
 				time.Sleep(100 * time.Millisecond)
 
-				// First close
-				err1 := client.Close()
-				Expect(err1).To(HaveOccurred()) // Will error because not connected
+				// First close stops the client
+				Expect(client.Close()).To(MatchError(mq.ErrNeverConnected))
+
+				// Second close is a no-op
+				err := client.Close()
+				Expect(err).To(MatchError(mq.ErrAlreadyClosed))
+				Expect(err.Error()).To(ContainSubstring("already closed"))
+			})
+		})
+
+		Context("when the context was canceled", func() {
+			It("should still report the client as never connected", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				client, err := mq.NewWithContext(ctx, "test-queue", "amqp://invalid:5672", logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				cancel()
 
-				// Second close should also error
-				err2 := client.Close()
-				Expect(err2).To(HaveOccurred())
-				Expect(err2.Error()).To(ContainSubstring("already closed"))
+				Expect(client.Close()).To(MatchError(mq.ErrNeverConnected))
+				Expect(client.Close()).To(MatchError(mq.ErrAlreadyClosed))
 			})
 		})
 	})
@@ -0,0 +1,116 @@
+package mq
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Compression algorithms for message bodies, see Options.Compression. The
+// algorithm is recorded as the content encoding of a message.
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+const (
+	// compressionMinSize is the smallest body that is compressed; smaller
+	// ones gain little and may even grow.
+	compressionMinSize = 512
+
+	// maxDecompressedSize bounds a decompressed body, so a small malicious
+	// message cannot exhaust memory.
+	maxDecompressedSize = 64 << 20
+)
+
+var (
+	errUnknownCompression = errors.New("compression must be empty, gzip or zstd")
+	errBodyTooLarge       = fmt.Errorf("decompressed body exceeds %d bytes", maxDecompressedSize)
+)
+
+// The zstd encoder and decoder are safe for concurrent EncodeAll and
+// DecodeAll calls and expensive to create, so they are shared.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+)
+
+// validCompression reports whether algorithm is empty or supported.
+func validCompression(algorithm string) bool {
+	switch algorithm {
+	case "", CompressionGzip, CompressionZstd:
+		return true
+	}
+	return false
+}
+
+// Compress compresses the body of msg with algorithm and sets its content
+// encoding, so consumers can decompress it, see Decompress. An empty
+// algorithm and bodies below 512 bytes leave msg unchanged.
+func Compress(msg *amqp.Publishing, algorithm string) error {
+	if algorithm == "" || len(msg.Body) < compressionMinSize {
+		return nil
+	}
+
+	var body []byte
+	switch algorithm {
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(msg.Body); err != nil {
+			return fmt.Errorf("failed to compress message: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("failed to compress message: %w", err)
+		}
+		body = buf.Bytes()
+	case CompressionZstd:
+		body = zstdEncoder.EncodeAll(msg.Body, nil)
+	default:
+		return errUnknownCompression
+	}
+
+	msg.Body = body
+	msg.ContentEncoding = algorithm
+	return nil
+}
+
+// Decompress restores the body of a delivery compressed by Compress and
+// clears its content encoding. Deliveries without a content encoding are
+// left as they are; an unknown encoding is an error.
+func Decompress(delivery *amqp.Delivery) error {
+	var body []byte
+	switch delivery.ContentEncoding {
+	case "":
+		return nil
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(delivery.Body))
+		if err != nil {
+			return fmt.Errorf("failed to decompress message: %w", err)
+		}
+		body, err = io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+		if err != nil {
+			return fmt.Errorf("failed to decompress message: %w", err)
+		}
+		if len(body) > maxDecompressedSize {
+			return errBodyTooLarge
+		}
+	case CompressionZstd:
+		var err error
+		body, err = zstdDecoder.DecodeAll(delivery.Body, nil)
+		if err != nil {
+			return fmt.Errorf("failed to decompress message: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported content encoding %q", delivery.ContentEncoding)
+	}
+
+	delivery.Body = body
+	delivery.ContentEncoding = ""
+	return nil
+}
@@ -0,0 +1,103 @@
+package mq
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// rejectRecorder records the deliveries rejected through it.
+type rejectRecorder struct {
+	rejected int
+	requeued bool
+}
+
+func (r *rejectRecorder) Ack(uint64, bool) error        { return nil }
+func (r *rejectRecorder) Nack(uint64, bool, bool) error { return nil }
+func (r *rejectRecorder) Reject(_ uint64, requeue bool) error {
+	r.rejected++
+	r.requeued = requeue
+	return nil
+}
+
+var _ = Describe("Compression", func() {
+	large := bytes.Repeat([]byte("sensor-reading "), 100)
+
+	DescribeTable("should restore compressed bodies",
+		func(algorithm string) {
+			msg := defaultPublishing(large)
+			Expect(Compress(&msg, algorithm)).To(Succeed())
+			Expect(msg.ContentEncoding).To(Equal(algorithm))
+			Expect(len(msg.Body)).To(BeNumerically("<", len(large)))
+
+			delivery := amqp.Delivery{ContentEncoding: msg.ContentEncoding, Body: msg.Body}
+			Expect(Decompress(&delivery)).To(Succeed())
+			Expect(delivery.Body).To(Equal(large))
+			Expect(delivery.ContentEncoding).To(BeEmpty())
+		},
+		Entry("gzip", CompressionGzip),
+		Entry("zstd", CompressionZstd),
+	)
+
+	It("should leave small bodies and disabled compression alone", func() {
+		small := defaultPublishing([]byte("reading"))
+		Expect(Compress(&small, CompressionZstd)).To(Succeed())
+		Expect(small).To(Equal(defaultPublishing([]byte("reading"))))
+
+		msg := defaultPublishing(large)
+		Expect(Compress(&msg, "")).To(Succeed())
+		Expect(msg.ContentEncoding).To(BeEmpty())
+	})
+
+	It("should reject unknown algorithms and encodings", func() {
+		msg := defaultPublishing(large)
+		Expect(Compress(&msg, "brotli")).To(MatchError(errUnknownCompression))
+
+		delivery := amqp.Delivery{ContentEncoding: "brotli", Body: large}
+		Expect(Decompress(&delivery)).To(MatchError(ContainSubstring(`unsupported content encoding "brotli"`)))
+	})
+
+	It("should refuse bodies that decompress beyond the limit", func() {
+		msg := defaultPublishing(make([]byte, maxDecompressedSize+1))
+		Expect(Compress(&msg, CompressionGzip)).To(Succeed())
+
+		delivery := amqp.Delivery{ContentEncoding: msg.ContentEncoding, Body: msg.Body}
+		Expect(Decompress(&delivery)).To(MatchError(errBodyTooLarge))
+	})
+
+	Describe("decompressing handler", func() {
+		var client *Client
+
+		BeforeEach(func() {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			client = &Client{infolog: logger, errlog: logger}
+		})
+
+		It("should hand decompressed deliveries to the handler", func() {
+			msg := defaultPublishing(large)
+			Expect(Compress(&msg, CompressionZstd)).To(Succeed())
+
+			var body []byte
+			handler := client.decompressing("test-queue", func(_ context.Context, d amqp.Delivery) { body = d.Body })
+			handler(context.Background(), amqp.Delivery{ContentEncoding: msg.ContentEncoding, Body: msg.Body})
+
+			Expect(body).To(Equal(large))
+		})
+
+		It("should reject deliveries that cannot be decompressed", func() {
+			ack := &rejectRecorder{}
+			called := false
+			handler := client.decompressing("test-queue", func(context.Context, amqp.Delivery) { called = true })
+			handler(context.Background(), amqp.Delivery{Acknowledger: ack, ContentEncoding: CompressionGzip, Body: []byte("not gzip")})
+
+			Expect(called).To(BeFalse())
+			Expect(ack.rejected).To(Equal(1))
+			Expect(ack.requeued).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,212 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// errSubscriptionLost is reported when the deliveries channel is closed,
+// i.e. the channel or connection of the subscription went away.
+var errSubscriptionLost = errors.New("deliveries channel closed")
+
+// Handler processes a single delivery. It must ack or nack the delivery.
+type Handler func(ctx context.Context, delivery amqp.Delivery)
+
+// ConsumeHooks are optional callbacks for the lifecycle of a consume loop.
+type ConsumeHooks struct {
+	// OnSubscribe is called whenever consumption of queue starts, first and
+	// after every reconnect.
+	OnSubscribe func(queue string)
+	// OnInterrupt is called when subscribing to queue fails or the
+	// subscription is lost, before the loop resubscribes.
+	OnInterrupt func(queue string, err error)
+}
+
+// subscriber is the part of the client a consume loop needs.
+type subscriber interface {
+	WaitReady(ctx context.Context) error
+	Consume() (<-chan amqp.Delivery, error)
+}
+
+// queueSubscriber subscribes to one of the queues declared by a client.
+type queueSubscriber struct {
+	client *Client
+	queue  string
+}
+
+func (s queueSubscriber) WaitReady(ctx context.Context) error {
+	return s.client.WaitReady(ctx)
+}
+
+func (s queueSubscriber) Consume() (<-chan amqp.Delivery, error) {
+	return s.client.consume(s.queue)
+}
+
+// ConsumeLoop consumes the client's queue with handler until ctx is done or
+// the client is closed. Unlike Consume, it survives reconnects: once the
+// client is ready again it subscribes anew. It returns ctx.Err() if ctx ended
+// the loop and nil if the client was closed.
+func (client *Client) ConsumeLoop(ctx context.Context, handler Handler, hooks ConsumeHooks) error {
+	return client.ConsumeQueues(ctx, map[string]Handler{client.queueName: handler}, hooks)
+}
+
+// ConsumeQueues is ConsumeLoop for several queues on the client's connection,
+// each consumed by its own handler concurrently. All queues must have been
+// declared by the client, see NewWithQueues. It returns once every loop has
+// ended.
+func (client *Client) ConsumeQueues(ctx context.Context, handlers map[string]Handler, hooks ConsumeHooks) error {
+	for queue := range handlers {
+		if !slices.Contains(client.queues, queue) {
+			return fmt.Errorf("queue %q is not declared by this client", queue)
+		}
+	}
+
+	hooks = client.instrument(hooks)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for queue, handler := range handlers {
+		wg.Go(func() {
+			sub := queueSubscriber{client: client, queue: queue}
+			if err := consumeLoop(ctx, queue, sub, client.ctx.Done(), client.decompressing(queue, handler), hooks, reInitDelay); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	// All loops end for the same reason, so the first error tells it
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// instrument wraps hooks to log the events and count them in the metrics.
+func (client *Client) instrument(hooks ConsumeHooks) ConsumeHooks {
+	onSubscribe, onInterrupt := hooks.OnSubscribe, hooks.OnInterrupt
+
+	return ConsumeHooks{
+		OnSubscribe: func(queue string) {
+			if client.metrics != nil {
+				client.metrics.ConsumeSubscribes.WithLabelValues(queue).Inc()
+			}
+			client.infolog.Info("subscribed to queue", "queue", queue)
+
+			if onSubscribe != nil {
+				onSubscribe(queue)
+			}
+		},
+		OnInterrupt: func(queue string, err error) {
+			if client.metrics != nil {
+				client.metrics.ConsumeInterrupts.WithLabelValues(queue).Inc()
+			}
+			client.errlog.Warn("consumption interrupted, resubscribing", "queue", queue, "error", err)
+
+			if onInterrupt != nil {
+				onInterrupt(queue, err)
+			}
+		},
+	}
+}
+
+// decompressing wraps handler to decompress deliveries first, see
+// Decompress. A delivery that cannot be decompressed would fail again, so it
+// is rejected without requeueing.
+func (client *Client) decompressing(queue string, handler Handler) Handler {
+	return func(ctx context.Context, delivery amqp.Delivery) {
+		if err := Decompress(&delivery); err != nil {
+			client.errlog.Error("failed to decompress message, rejecting it", "queue", queue, "error", err)
+			if client.metrics != nil {
+				client.metrics.ConsumptionFailures.WithLabelValues(queue, "decompress_error").Inc()
+			}
+
+			if err := delivery.Reject(false); err != nil {
+				client.errlog.Error("failed to reject message", "queue", queue, "error", err)
+			}
+			return
+		}
+
+		handler(ctx, delivery)
+	}
+}
+
+// consumeLoop consumes queue through sub. closed is done once the client is
+// closed; retryDelay is the pause after a failed subscription.
+func consumeLoop(ctx context.Context, queue string, sub subscriber, closed <-chan struct{}, handler Handler, hooks ConsumeHooks, retryDelay time.Duration) error {
+	interrupted := func(err error) {
+		if hooks.OnInterrupt != nil {
+			hooks.OnInterrupt(queue, err)
+		}
+	}
+
+	for {
+		if err := sub.WaitReady(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// The client is closed
+			return nil
+		}
+
+		deliveries, err := sub.Consume()
+		if err != nil {
+			// The connection dropped again between WaitReady and Consume
+			interrupted(err)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-closed:
+				return nil
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+
+		if hooks.OnSubscribe != nil {
+			hooks.OnSubscribe(queue)
+		}
+
+		if err := drain(ctx, deliveries, handler); err != nil {
+			return err
+		}
+
+		// Closing the client closes the deliveries channel as well
+		select {
+		case <-closed:
+			return nil
+		default:
+		}
+
+		interrupted(errSubscriptionLost)
+	}
+}
+
+// drain hands deliveries to handler until the channel is closed (nil) or ctx
+// is done (ctx.Err()).
+func drain(ctx context.Context, deliveries <-chan amqp.Delivery, handler Handler) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case delivery, ok := <-deliveries:
+			if !ok {
+				return nil
+			}
+			handler(ctx, delivery)
+		}
+	}
+}
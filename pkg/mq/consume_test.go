@@ -0,0 +1,189 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// fakeSubscriber hands out the queued subscriptions in order; a nil channel
+// fails the Consume call.
+type fakeSubscriber struct {
+	mu            sync.Mutex
+	subscriptions []chan amqp.Delivery
+	consumeCalls  int
+}
+
+func (f *fakeSubscriber) WaitReady(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (f *fakeSubscriber) Consume() (<-chan amqp.Delivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.consumeCalls++
+	if len(f.subscriptions) == 0 {
+		return nil, errNotConnected
+	}
+
+	sub := f.subscriptions[0]
+	f.subscriptions = f.subscriptions[1:]
+	if sub == nil {
+		return nil, errNotConnected
+	}
+
+	return sub, nil
+}
+
+func (f *fakeSubscriber) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.consumeCalls
+}
+
+var _ = Describe("consumeLoop", func() {
+	var (
+		first, second chan amqp.Delivery
+		sub           *fakeSubscriber
+		closed        chan struct{}
+		handled       chan string
+		subscribes    chan string
+		interrupts    chan error
+		hooks         ConsumeHooks
+	)
+
+	handler := func(_ context.Context, d amqp.Delivery) {
+		handled <- string(d.Body)
+	}
+
+	BeforeEach(func() {
+		first = make(chan amqp.Delivery)
+		second = make(chan amqp.Delivery)
+		sub = &fakeSubscriber{subscriptions: []chan amqp.Delivery{first, nil, second}}
+		closed = make(chan struct{})
+		handled = make(chan string, 10)
+		subscribes = make(chan string, 10)
+		interrupts = make(chan error, 10)
+		hooks = ConsumeHooks{
+			OnSubscribe: func(queue string) { subscribes <- queue },
+			OnInterrupt: func(queue string, err error) {
+				Expect(queue).To(Equal("test-queue"))
+				interrupts <- err
+			},
+		}
+	})
+
+	run := func(ctx context.Context) chan error {
+		result := make(chan error, 1)
+		go func() { result <- consumeLoop(ctx, "test-queue", sub, closed, handler, hooks, time.Millisecond) }()
+		return result
+	}
+
+	It("should resubscribe after the deliveries channel is closed", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		result := run(ctx)
+
+		first <- amqp.Delivery{Body: []byte("one")}
+		Eventually(handled).Should(Receive(Equal("one")))
+		Expect(subscribes).To(Receive(Equal("test-queue")))
+
+		// Connection lost, then one failed Consume before the second subscription
+		close(first)
+		second <- amqp.Delivery{Body: []byte("two")}
+		Eventually(handled).Should(Receive(Equal("two")))
+
+		Expect(subscribes).To(Receive(Equal("test-queue")))
+		Expect(interrupts).To(Receive(MatchError(errSubscriptionLost)))
+		Expect(interrupts).To(Receive(MatchError(errNotConnected)))
+		Expect(sub.calls()).To(Equal(3))
+
+		cancel()
+		Eventually(result).Should(Receive(MatchError(context.Canceled)))
+	})
+
+	It("should return nil without reporting an interruption when the client is closed", func() {
+		result := run(context.Background())
+		Eventually(subscribes).Should(Receive())
+
+		close(closed)
+		close(first)
+
+		Eventually(result).Should(Receive(BeNil()))
+		Expect(interrupts).To(BeEmpty())
+	})
+
+	It("should stop retrying when the context is canceled", func() {
+		sub.subscriptions = nil
+		ctx, cancel := context.WithCancel(context.Background())
+		result := run(ctx)
+
+		Eventually(interrupts).Should(Receive(MatchError(errNotConnected)))
+		cancel()
+
+		Eventually(result).Should(Receive(MatchError(context.Canceled)))
+		Expect(subscribes).To(BeEmpty())
+	})
+
+	It("should work without hooks", func() {
+		hooks = ConsumeHooks{}
+		ctx, cancel := context.WithCancel(context.Background())
+		result := run(ctx)
+
+		close(first)
+		second <- amqp.Delivery{Body: []byte("two")}
+		Eventually(handled).Should(Receive(Equal("two")))
+
+		cancel()
+		Eventually(result).Should(Receive(MatchError(context.Canceled)))
+	})
+})
+
+var _ = Describe("Client consume loops", func() {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	It("should return nil once the client is closed", func() {
+		client := newClient(context.Background(), []string{"test-queue"}, logger)
+		client.cancel()
+
+		Expect(client.ConsumeLoop(context.Background(), func(context.Context, amqp.Delivery) {}, ConsumeHooks{})).To(Succeed())
+	})
+
+	It("should return the context error when the context is canceled", func() {
+		client := newClient(context.Background(), []string{"test-queue"}, logger)
+		DeferCleanup(client.cancel)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := client.ConsumeLoop(ctx, func(context.Context, amqp.Delivery) {}, ConsumeHooks{})
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+	})
+	It("should reject queues the client did not declare", func() {
+		client := newClient(context.Background(), []string{"sensor-data", "device-data"}, logger)
+		DeferCleanup(client.cancel)
+
+		err := client.ConsumeQueues(context.Background(), map[string]Handler{
+			"device-data": func(context.Context, amqp.Delivery) {},
+			"other-queue": func(context.Context, amqp.Delivery) {},
+		}, ConsumeHooks{})
+		Expect(err).To(MatchError(ContainSubstring(`queue "other-queue" is not declared`)))
+	})
+
+	It("should return once every queue loop has ended", func() {
+		client := newClient(context.Background(), []string{"sensor-data", "device-data"}, logger)
+		client.cancel()
+
+		Expect(client.ConsumeQueues(context.Background(), map[string]Handler{
+			"sensor-data": func(context.Context, amqp.Delivery) {},
+			"device-data": func(context.Context, amqp.Delivery) {},
+		}, ConsumeHooks{})).To(Succeed())
+	})
+})
@@ -0,0 +1,209 @@
+// Package inmem provides an in-process message broker with clients that
+// implement mq.ClientInterface, so the services can run and be tested without
+// RabbitMQ. Messages only live as long as the process.
+package inmem
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Scheme selects the in-process broker in place of a RabbitMQ URL, e.g.
+// "inmem://".
+const Scheme = "inmem"
+
+// defaultQueueSize is how many ready messages a queue holds before pushes
+// block, like a publisher waiting for confirms from a busy broker.
+const defaultQueueSize = 10000
+
+// IsURL reports whether url selects the in-process broker.
+func IsURL(url string) bool {
+	return strings.HasPrefix(url, Scheme+"://")
+}
+
+// Broker holds named queues. Queues are declared when a client first uses
+// them; every client of the broker sees the same queues, and consumers of a
+// queue compete for its messages as on RabbitMQ.
+type Broker struct {
+	mu        sync.Mutex
+	queues    map[string]*queue
+	queueSize int
+}
+
+// NewBroker creates a broker whose queues hold up to queueSize ready messages
+// (0 = 10000).
+func NewBroker(queueSize int) *Broker {
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	return &Broker{
+		queues:    make(map[string]*queue),
+		queueSize: queueSize,
+	}
+}
+
+// Len returns the number of ready messages in queue, excluding messages
+// delivered but not yet acknowledged.
+func (b *Broker) Len(queue string) int {
+	return len(b.queue(queue).ready)
+}
+
+// Unacked returns the number of messages of queue delivered to a consumer
+// and not yet acknowledged.
+func (b *Broker) Unacked(queue string) int {
+	q := b.queue(queue)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.unacked)
+}
+
+// queue returns the named queue, declaring it on first use.
+func (b *Broker) queue(name string) *queue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[name]
+	if !ok {
+		q = &queue{
+			name:    name,
+			ready:   make(chan message, b.queueSize),
+			unacked: make(map[uint64]message),
+		}
+		b.queues[name] = q
+	}
+	return q
+}
+
+// message is a published message waiting in a queue.
+type message struct {
+	amqp.Publishing
+	enqueued    time.Time
+	redelivered bool
+	owner       *Client // Client the message is delivered to, while unacknowledged
+}
+
+// expired reports whether the message outlived its expiration at now.
+func (m message) expired(now time.Time) bool {
+	if m.Expiration == "" {
+		return false
+	}
+
+	ttl, err := time.ParseDuration(m.Expiration + "ms")
+	return err == nil && now.Sub(m.enqueued) >= ttl
+}
+
+// queue is a channel of ready messages plus the messages delivered and not
+// yet acknowledged. It acknowledges the deliveries made from it.
+type queue struct {
+	name    string
+	ready   chan message
+	mu      sync.Mutex
+	nextTag uint64
+	unacked map[uint64]message
+}
+
+// deliver records m as unacknowledged by owner and returns it as a delivery
+// to consumerTag.
+func (q *queue) deliver(m message, consumerTag string, owner *Client) amqp.Delivery {
+	m.owner = owner
+
+	q.mu.Lock()
+	q.nextTag++
+	tag := q.nextTag
+	q.unacked[tag] = m
+	q.mu.Unlock()
+
+	return amqp.Delivery{
+		Acknowledger:    q,
+		Headers:         m.Headers,
+		ContentType:     m.ContentType,
+		ContentEncoding: m.ContentEncoding,
+		DeliveryMode:    m.DeliveryMode,
+		Priority:        m.Priority,
+		CorrelationId:   m.CorrelationId,
+		ReplyTo:         m.ReplyTo,
+		Expiration:      m.Expiration,
+		MessageId:       m.MessageId,
+		Timestamp:       m.Timestamp,
+		Type:            m.Type,
+		UserId:          m.UserId,
+		AppId:           m.AppId,
+		ConsumerTag:     consumerTag,
+		DeliveryTag:     tag,
+		Redelivered:     m.redelivered,
+		RoutingKey:      q.name,
+		Body:            m.Body,
+	}
+}
+
+// requeue puts m back as ready. A full queue is waited for in the background
+// so a consumer nacking a message never blocks.
+func (q *queue) requeue(m message) {
+	m.redelivered = true
+	m.owner = nil
+
+	select {
+	case q.ready <- m:
+	default:
+		go func() { q.ready <- m }()
+	}
+}
+
+// release requeues the unacknowledged messages delivered to owner.
+func (q *queue) release(owner *Client) {
+	q.mu.Lock()
+	var released []message
+	for tag, m := range q.unacked {
+		if m.owner == owner {
+			released = append(released, m)
+			delete(q.unacked, tag)
+		}
+	}
+	q.mu.Unlock()
+
+	for _, m := range released {
+		q.requeue(m)
+	}
+}
+
+// settle removes the delivery tag, or with multiple every tag up to it, from
+// the unacknowledged messages and returns them.
+func (q *queue) settle(tag uint64, multiple bool) []message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var settled []message
+	for t, m := range q.unacked {
+		if t == tag || (multiple && t < tag) {
+			settled = append(settled, m)
+			delete(q.unacked, t)
+		}
+	}
+	return settled
+}
+
+// Ack implements amqp.Acknowledger.
+func (q *queue) Ack(tag uint64, multiple bool) error {
+	q.settle(tag, multiple)
+	return nil
+}
+
+// Nack implements amqp.Acknowledger.
+func (q *queue) Nack(tag uint64, multiple, requeue bool) error {
+	for _, m := range q.settle(tag, multiple) {
+		if requeue {
+			q.requeue(m)
+		}
+	}
+	return nil
+}
+
+// Reject implements amqp.Acknowledger.
+func (q *queue) Reject(tag uint64, requeue bool) error {
+	return q.Nack(tag, false, requeue)
+}
@@ -0,0 +1,293 @@
+package inmem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"procodus.dev/demo-app/pkg/mq"
+)
+
+var (
+	errNoQueues = errors.New("at least one queue is required")
+	errClosed   = errors.New("client is closed")
+)
+
+// Client is a client of a Broker. It is always ready until it is closed;
+// there is no connection to lose, so consume loops never resubscribe.
+type Client struct {
+	broker      *Broker
+	queues      []string // The first one is used by Push and Consume
+	consumerTag string
+	compression string          // Algorithm compressing published bodies, empty for none
+	ctx         context.Context // Done when the parent context ends or Close is called
+	cancel      context.CancelFunc
+	mu          sync.Mutex
+	closed      bool
+	consumers   sync.WaitGroup // Subscriptions still forwarding deliveries
+}
+
+// NewClient creates a client of the broker, as mq.NewWithOptions does for
+// RabbitMQ. The client is closed when ctx is done or Close is called.
+// opts.Durable, opts.MaxPriority and opts.Faults have no effect.
+func (b *Broker) NewClient(ctx context.Context, opts mq.Options) (*Client, error) {
+	if len(opts.Queues) == 0 {
+		return nil, errNoQueues
+	}
+
+	switch opts.Compression {
+	case "", mq.CompressionGzip, mq.CompressionZstd:
+	default:
+		return nil, fmt.Errorf("unknown compression %q", opts.Compression)
+	}
+
+	seen := make(map[string]bool, len(opts.Queues))
+	for _, queue := range opts.Queues {
+		if queue == "" {
+			return nil, errors.New("queue name cannot be empty")
+		}
+		if seen[queue] {
+			return nil, fmt.Errorf("duplicate queue %q", queue)
+		}
+		seen[queue] = true
+		b.queue(queue)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &Client{
+		broker:      b,
+		queues:      opts.Queues,
+		consumerTag: opts.ConsumerTag,
+		compression: opts.Compression,
+		ctx:         ctx,
+		cancel:      cancel,
+	}, nil
+}
+
+// Push implements mq.ClientInterface. It blocks while the queue is full.
+func (c *Client) Push(ctx context.Context, data []byte) error {
+	return c.PushWithOptions(ctx, data, mq.PublishOptions{})
+}
+
+// PushWithOptions implements mq.ClientInterface. Priorities are accepted but
+// messages are delivered in order; expired messages are dropped on delivery.
+func (c *Client) PushWithOptions(ctx context.Context, data []byte, opts mq.PublishOptions) error {
+	msg, err := opts.Publishing(data)
+	if err != nil {
+		return err
+	}
+
+	return c.publish(ctx, msg)
+}
+
+// PushBatch implements mq.ClientInterface.
+func (c *Client) PushBatch(ctx context.Context, data [][]byte) error {
+	for _, body := range data {
+		if err := c.Push(ctx, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnsafePush implements mq.ClientInterface. It is Push, as the broker cannot
+// lose a message it accepted.
+func (c *Client) UnsafePush(ctx context.Context, data []byte) error {
+	return c.Push(ctx, data)
+}
+
+// publish compresses msg as configured and enqueues it on the client's queue.
+func (c *Client) publish(ctx context.Context, msg amqp.Publishing) error {
+	if c.ctx.Err() != nil {
+		return errClosed
+	}
+
+	if err := mq.Compress(&msg, c.compression); err != nil {
+		return err
+	}
+
+	m := message{Publishing: msg, enqueued: time.Now()}
+
+	select {
+	case c.broker.queue(c.queues[0]).ready <- m:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.ctx.Done():
+		return errClosed
+	}
+}
+
+// Consume implements mq.ClientInterface. The channel is closed when the
+// client is closed.
+func (c *Client) Consume() (<-chan amqp.Delivery, error) {
+	return c.subscribe(context.Background(), c.queues[0])
+}
+
+// ConsumeLoop implements mq.ClientInterface.
+func (c *Client) ConsumeLoop(ctx context.Context, handler mq.Handler, hooks mq.ConsumeHooks) error {
+	return c.ConsumeQueues(ctx, map[string]mq.Handler{c.queues[0]: handler}, hooks)
+}
+
+// ConsumeQueues implements mq.ClientInterface. It returns ctx.Err() if ctx
+// ended the loops and nil if the client was closed.
+func (c *Client) ConsumeQueues(ctx context.Context, handlers map[string]mq.Handler, hooks mq.ConsumeHooks) error {
+	for queue := range handlers {
+		if !slices.Contains(c.queues, queue) {
+			return fmt.Errorf("queue %q is not declared by this client", queue)
+		}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for queue, handler := range handlers {
+		wg.Go(func() {
+			if err := c.consumeLoop(ctx, queue, handler, hooks); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	// All loops end for the same reason, so the first error tells it
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// consumeLoop hands the deliveries of queue to handler until ctx is done or
+// the client is closed.
+func (c *Client) consumeLoop(ctx context.Context, queue string, handler mq.Handler, hooks mq.ConsumeHooks) error {
+	deliveries, err := c.subscribe(ctx, queue)
+	if err != nil {
+		// The client is closed
+		return nil
+	}
+
+	if hooks.OnSubscribe != nil {
+		hooks.OnSubscribe(queue)
+	}
+
+	for delivery := range deliveries {
+		// Like RabbitMQ consume loops, reject what cannot be decompressed
+		if err := mq.Decompress(&delivery); err != nil {
+			_ = delivery.Reject(false)
+			continue
+		}
+
+		handler(ctx, delivery)
+	}
+
+	return ctx.Err()
+}
+
+// subscribe forwards the messages of queue to the returned channel until ctx
+// is done or the client is closed, then closes it. Like a RabbitMQ consumer
+// with a prefetch count of 1, it takes the next message only once the
+// previous one was received.
+func (c *Client) subscribe(ctx context.Context, queue string) (<-chan amqp.Delivery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, errClosed
+	}
+
+	tag := ""
+	if c.consumerTag != "" {
+		tag = c.consumerTag + "-" + queue
+	}
+
+	q := c.broker.queue(queue)
+	deliveries := make(chan amqp.Delivery)
+
+	c.consumers.Go(func() {
+		defer close(deliveries)
+
+		for {
+			var m message
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.ctx.Done():
+				return
+			case m = <-q.ready:
+			}
+
+			if m.expired(time.Now()) {
+				continue
+			}
+
+			delivery := q.deliver(m, tag, c)
+			select {
+			case deliveries <- delivery:
+			case <-ctx.Done():
+				_ = q.Nack(delivery.DeliveryTag, false, true)
+				return
+			case <-c.ctx.Done():
+				_ = q.Nack(delivery.DeliveryTag, false, true)
+				return
+			}
+		}
+	})
+
+	return deliveries, nil
+}
+
+// ServerVersion implements mq.ServerVersioner.
+func (c *Client) ServerVersion() string {
+	return "in-process broker"
+}
+
+// WaitReady implements mq.ClientInterface. The client is ready right away.
+func (c *Client) WaitReady(ctx context.Context) error {
+	select {
+	case <-c.ctx.Done():
+		return errClosed
+	default:
+	}
+
+	return ctx.Err()
+}
+
+// Close implements mq.ClientInterface. It stops the subscriptions, waits
+// until their channels are closed and requeues the messages delivered to the
+// client and not yet acknowledged, as RabbitMQ does when a channel closes.
+// Later calls return mq.ErrAlreadyClosed.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return mq.ErrAlreadyClosed
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	c.cancel()
+	c.consumers.Wait()
+
+	for _, queue := range c.queues {
+		c.broker.queue(queue).release(c)
+	}
+
+	return nil
+}
+
+// Ensure Client implements mq.ClientInterface and mq.ServerVersioner.
+var (
+	_ mq.ClientInterface = (*Client)(nil)
+	_ mq.ServerVersioner = (*Client)(nil)
+)
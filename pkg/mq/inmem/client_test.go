@@ -0,0 +1,275 @@
+package inmem_test
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"procodus.dev/demo-app/pkg/mq"
+	"procodus.dev/demo-app/pkg/mq/inmem"
+)
+
+var _ = Describe("In-process broker", func() {
+	var (
+		ctx    context.Context
+		broker *inmem.Broker
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		broker = inmem.NewBroker(0)
+	})
+
+	newClient := func(queues ...string) *inmem.Client {
+		client, err := broker.NewClient(ctx, mq.Options{Queues: queues, ConsumerTag: "test"})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = client.Close() })
+		return client
+	}
+
+	// receive returns the next delivery of deliveries.
+	receive := func(deliveries <-chan amqp.Delivery) amqp.Delivery {
+		var delivery amqp.Delivery
+		Eventually(deliveries).Should(Receive(&delivery))
+		return delivery
+	}
+
+	It("should select the broker with inmem URLs only", func() {
+		Expect(inmem.IsURL("inmem://")).To(BeTrue())
+		Expect(inmem.IsURL("amqp://localhost:5672")).To(BeFalse())
+	})
+
+	It("should validate the queues like the RabbitMQ client", func() {
+		_, err := broker.NewClient(ctx, mq.Options{})
+		Expect(err).To(HaveOccurred())
+
+		_, err = broker.NewClient(ctx, mq.Options{Queues: []string{"a", "a"}})
+		Expect(err).To(MatchError(ContainSubstring("duplicate queue")))
+	})
+
+	It("should deliver messages from another client with their properties", func() {
+		publisher := newClient("readings")
+		consumer := newClient("readings")
+
+		Expect(publisher.PushWithOptions(ctx, []byte("hello"), mq.PublishOptions{
+			ContentType:   "application/json",
+			MessageID:     "msg-1",
+			CorrelationID: "req-1",
+			Headers:       amqp.Table{"attempt": int32(1)},
+		})).To(Succeed())
+		Expect(broker.Len("readings")).To(Equal(1))
+
+		deliveries, err := consumer.Consume()
+		Expect(err).NotTo(HaveOccurred())
+
+		delivery := receive(deliveries)
+		Expect(delivery.Body).To(Equal([]byte("hello")))
+		Expect(delivery.ContentType).To(Equal("application/json"))
+		Expect(delivery.MessageId).To(Equal("msg-1"))
+		Expect(delivery.CorrelationId).To(Equal("req-1"))
+		Expect(delivery.Headers).To(HaveKeyWithValue("attempt", int32(1)))
+		Expect(delivery.RoutingKey).To(Equal("readings"))
+		Expect(delivery.ConsumerTag).To(Equal("test-readings"))
+		Expect(delivery.Redelivered).To(BeFalse())
+
+		Expect(broker.Unacked("readings")).To(Equal(1))
+		Expect(delivery.Ack(false)).To(Succeed())
+		Expect(broker.Unacked("readings")).To(BeZero())
+	})
+
+	It("should reject invalid publish options", func() {
+		client := newClient("readings")
+
+		Expect(client.PushWithOptions(ctx, []byte("x"), mq.PublishOptions{Priority: 10})).NotTo(Succeed())
+		Expect(broker.Len("readings")).To(BeZero())
+	})
+
+	It("should decompress compressed messages for consume loops", func() {
+		publisher, err := broker.NewClient(ctx, mq.Options{Queues: []string{"readings"}, Compression: mq.CompressionGzip})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = publisher.Close() })
+
+		body := []byte(strings.Repeat("reading ", 100))
+		Expect(publisher.Push(ctx, body)).To(Succeed())
+
+		received := make(chan amqp.Delivery, 1)
+		consumeCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			_ = newClient("readings").ConsumeLoop(consumeCtx, func(_ context.Context, delivery amqp.Delivery) {
+				_ = delivery.Ack(false)
+				received <- delivery
+			}, mq.ConsumeHooks{})
+		}()
+
+		delivery := receive(received)
+		Expect(delivery.Body).To(Equal(body))
+		Expect(delivery.ContentEncoding).To(BeEmpty())
+	})
+
+	It("should redeliver nacked messages that are requeued", func() {
+		client := newClient("readings")
+		Expect(client.Push(ctx, []byte("retry me"))).To(Succeed())
+
+		deliveries, err := client.Consume()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(receive(deliveries).Nack(false, true)).To(Succeed())
+
+		delivery := receive(deliveries)
+		Expect(delivery.Body).To(Equal([]byte("retry me")))
+		Expect(delivery.Redelivered).To(BeTrue())
+
+		Expect(delivery.Reject(false)).To(Succeed())
+		Consistently(deliveries, 100*time.Millisecond).ShouldNot(Receive())
+		Expect(broker.Len("readings")).To(BeZero())
+	})
+
+	It("should drop expired messages", func() {
+		client := newClient("readings")
+		Expect(client.PushWithOptions(ctx, []byte("stale"), mq.PublishOptions{Expiration: time.Millisecond})).To(Succeed())
+		Expect(client.Push(ctx, []byte("fresh"))).To(Succeed())
+		time.Sleep(5 * time.Millisecond)
+
+		deliveries, err := client.Consume()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(receive(deliveries).Body).To(Equal([]byte("fresh")))
+	})
+
+	It("should block pushes to a full queue until ctx is done", func() {
+		broker = inmem.NewBroker(1)
+		client := newClient("readings")
+		Expect(client.Push(ctx, []byte("first"))).To(Succeed())
+
+		pushCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		Expect(client.Push(pushCtx, []byte("second"))).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("should consume several queues with their own handlers", func() {
+		client := newClient("readings", "devices")
+		Expect(client.Push(ctx, []byte("reading"))).To(Succeed())
+
+		devicePublisher := newClient("devices")
+		Expect(devicePublisher.Push(ctx, []byte("device"))).To(Succeed())
+
+		var (
+			mu         sync.Mutex
+			received   = map[string]string{}
+			subscribed []string
+		)
+		handler := func(queue string) mq.Handler {
+			return func(_ context.Context, delivery amqp.Delivery) {
+				mu.Lock()
+				received[queue] = string(delivery.Body)
+				mu.Unlock()
+				_ = delivery.Ack(false)
+			}
+		}
+		hooks := mq.ConsumeHooks{OnSubscribe: func(queue string) {
+			mu.Lock()
+			subscribed = append(subscribed, queue)
+			mu.Unlock()
+		}}
+
+		consumeCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() {
+			done <- client.ConsumeQueues(consumeCtx, map[string]mq.Handler{
+				"readings": handler("readings"),
+				"devices":  handler("devices"),
+			}, hooks)
+		}()
+
+		Eventually(func() map[string]string {
+			mu.Lock()
+			defer mu.Unlock()
+			return maps.Clone(received)
+		}).Should(Equal(map[string]string{"readings": "reading", "devices": "device"}))
+
+		cancel()
+		Eventually(done).Should(Receive(MatchError(context.Canceled)))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(subscribed).To(ConsistOf("readings", "devices"))
+	})
+
+	It("should refuse queues the client did not declare", func() {
+		client := newClient("readings")
+
+		err := client.ConsumeQueues(ctx, map[string]mq.Handler{"devices": func(context.Context, amqp.Delivery) {}}, mq.ConsumeHooks{})
+		Expect(err).To(MatchError(ContainSubstring(`queue "devices" is not declared`)))
+	})
+
+	It("should share the messages of a queue between competing consumers", func() {
+		publisher := newClient("readings")
+		first, err := newClient("readings").Consume()
+		Expect(err).NotTo(HaveOccurred())
+		second, err := newClient("readings").Consume()
+		Expect(err).NotTo(HaveOccurred())
+
+		for range 2 {
+			Expect(publisher.Push(ctx, []byte("reading"))).To(Succeed())
+		}
+
+		receive(first)
+		receive(second)
+	})
+
+	Describe("Close", func() {
+		It("should end consume loops and requeue unacknowledged messages", func() {
+			client, err := broker.NewClient(ctx, mq.Options{Queues: []string{"readings"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.Push(ctx, []byte("unacked"))).To(Succeed())
+
+			received := make(chan amqp.Delivery, 1)
+			done := make(chan error, 1)
+			go func() {
+				done <- client.ConsumeLoop(ctx, func(_ context.Context, delivery amqp.Delivery) {
+					received <- delivery
+				}, mq.ConsumeHooks{})
+			}()
+			Eventually(received).Should(Receive())
+
+			Expect(client.Close()).To(Succeed())
+			Eventually(done).Should(Receive(BeNil()))
+			Expect(broker.Len("readings")).To(Equal(1))
+
+			Expect(client.Close()).To(MatchError(mq.ErrAlreadyClosed))
+			Expect(client.Push(ctx, []byte("late"))).NotTo(Succeed())
+			Expect(client.WaitReady(ctx)).NotTo(Succeed())
+
+			redelivered := receive(must(newClient("readings").Consume()))
+			Expect(redelivered.Body).To(Equal([]byte("unacked")))
+			Expect(redelivered.Redelivered).To(BeTrue())
+		})
+
+		It("should close the client when its context is done", func() {
+			clientCtx, cancel := context.WithCancel(ctx)
+			client, err := broker.NewClient(clientCtx, mq.Options{Queues: []string{"readings"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.WaitReady(ctx)).To(Succeed())
+
+			deliveries, err := client.Consume()
+			Expect(err).NotTo(HaveOccurred())
+
+			cancel()
+			Eventually(deliveries).Should(BeClosed())
+			Expect(client.WaitReady(ctx)).NotTo(Succeed())
+		})
+	})
+})
+
+// must returns v and fails the spec on err.
+func must[T any](v T, err error) T {
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	return v
+}
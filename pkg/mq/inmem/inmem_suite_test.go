@@ -0,0 +1,13 @@
+package inmem_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestInmem(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "In-Process Broker Suite")
+}
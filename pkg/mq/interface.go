@@ -6,28 +6,69 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
-// ClientInterface defines the interface for message queue operations.
-// This interface enables easier testing through mocking and dependency injection.
-type ClientInterface interface {
+// Publisher defines the message queue operations for publishing messages.
+type Publisher interface {
 	// Push will push data onto the queue, and wait for a confirmation.
 	// This will block until the server sends a confirmation.
 	// The context is used for cancellation and timeout.
 	Push(ctx context.Context, data []byte) error
 
+	// PushWithOptions is Push with per-message properties such as content
+	// type, headers, priority, expiration, message ID and correlation ID.
+	PushWithOptions(ctx context.Context, data []byte, opts PublishOptions) error
+
+	// PushBatch pushes all messages and waits until each one is confirmed.
+	PushBatch(ctx context.Context, data [][]byte) error
+
 	// UnsafePush will push to the queue without checking for confirmation.
 	// It returns an error if it fails to connect.
 	// No guarantees are provided for whether the server will receive the message.
 	// The context is used for cancellation and timeout.
 	UnsafePush(ctx context.Context, data []byte) error
+}
 
+// Consumer defines the message queue operations for consuming messages.
+type Consumer interface {
 	// Consume will continuously put queue items on the channel.
 	// It is required to call delivery.Ack when it has been successfully processed,
 	// or delivery.Nack when it fails.
 	Consume() (<-chan amqp.Delivery, error)
 
+	// ConsumeLoop consumes the queue with handler until ctx is done or the
+	// client is closed, subscribing again after every reconnect.
+	ConsumeLoop(ctx context.Context, handler Handler, hooks ConsumeHooks) error
+
+	// ConsumeQueues is ConsumeLoop for several queues declared by the client,
+	// each consumed by its own handler.
+	ConsumeQueues(ctx context.Context, handlers map[string]Handler, hooks ConsumeHooks) error
+}
+
+// ClientInterface defines the interface for message queue operations.
+// This interface enables easier testing through mocking and dependency injection.
+// Code that only publishes or only consumes can depend on Publisher or
+// Consumer instead.
+type ClientInterface interface {
+	Publisher
+	Consumer
+
+	// WaitReady blocks until the client is connected and ready to push or
+	// consume, the context is done, or the client is closed.
+	WaitReady(ctx context.Context) error
+
 	// Close will cleanly shut down the channel and connection.
 	Close() error
 }
 
-// Ensure Client implements ClientInterface.
-var _ ClientInterface = (*Client)(nil)
+// ServerVersioner is implemented by clients that know the broker they are
+// connected to. Startup events report its version.
+type ServerVersioner interface {
+	// ServerVersion returns the product and version of the broker, such as
+	// "RabbitMQ 4.1.0", or an empty string before the client connected.
+	ServerVersion() string
+}
+
+// Ensure Client implements ClientInterface and ServerVersioner.
+var (
+	_ ClientInterface = (*Client)(nil)
+	_ ServerVersioner = (*Client)(nil)
+)
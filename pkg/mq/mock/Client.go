@@ -22,6 +22,20 @@ type MockClient struct {
 	// PushCalls tracks all calls to Push with their arguments.
 	PushCalls []PushCall
 
+	// PushWithOptionsFunc is called when PushWithOptions is invoked. If nil, returns PushWithOptionsError.
+	PushWithOptionsFunc func(ctx context.Context, data []byte, opts mq.PublishOptions) error
+	// PushWithOptionsError is returned by PushWithOptions if PushWithOptionsFunc is nil.
+	PushWithOptionsError error
+	// PushWithOptionsCalls tracks all calls to PushWithOptions with their arguments.
+	PushWithOptionsCalls []PushWithOptionsCall
+
+	// PushBatchFunc is called when PushBatch is invoked. If nil, returns PushBatchError.
+	PushBatchFunc func(ctx context.Context, data [][]byte) error
+	// PushBatchError is returned by PushBatch if PushBatchFunc is nil.
+	PushBatchError error
+	// PushBatchCalls tracks all calls to PushBatch with their arguments.
+	PushBatchCalls []PushBatchCall
+
 	// UnsafePushFunc is called when UnsafePush is invoked. If nil, returns UnsafePushError.
 	UnsafePushFunc func(ctx context.Context, data []byte) error
 	// UnsafePushError is returned by UnsafePush if UnsafePushFunc is nil.
@@ -38,6 +52,32 @@ type MockClient struct {
 	// ConsumeCalls tracks the number of times Consume was called.
 	ConsumeCalls int
 
+	// QueueName is the queue the default ConsumeLoop reports to its hooks.
+	QueueName string
+
+	// ConsumeLoopFunc is called when ConsumeLoop is invoked. If nil, ConsumeLoop
+	// subscribes once via Consume and handles deliveries until the channel is
+	// closed or ctx is done.
+	ConsumeLoopFunc func(ctx context.Context, handler mq.Handler, hooks mq.ConsumeHooks) error
+	// ConsumeLoopCalls tracks the number of times ConsumeLoop was called.
+	ConsumeLoopCalls int
+
+	// ConsumeQueuesFunc is called when ConsumeQueues is invoked. If nil,
+	// ConsumeQueues subscribes once per queue, to QueueChannels or else via
+	// Consume, and handles deliveries until the channels are closed or ctx is done.
+	ConsumeQueuesFunc func(ctx context.Context, handlers map[string]mq.Handler, hooks mq.ConsumeHooks) error
+	// QueueChannels are the deliveries per queue for the default ConsumeQueues.
+	QueueChannels map[string]<-chan amqp.Delivery
+	// ConsumeQueuesCalls tracks the number of times ConsumeQueues was called.
+	ConsumeQueuesCalls int
+
+	// WaitReadyFunc is called when WaitReady is invoked. If nil, returns WaitReadyError.
+	WaitReadyFunc func(ctx context.Context) error
+	// WaitReadyError is returned by WaitReady if WaitReadyFunc is nil.
+	WaitReadyError error
+	// WaitReadyCalls tracks the number of times WaitReady was called.
+	WaitReadyCalls int
+
 	// CloseFunc is called when Close is invoked. If nil, returns CloseError.
 	CloseFunc func() error
 	// CloseError is returned by Close if CloseFunc is nil.
@@ -52,6 +92,19 @@ type PushCall struct {
 	Data []byte
 }
 
+// PushWithOptionsCall records the arguments to a PushWithOptions call.
+type PushWithOptionsCall struct {
+	Ctx     context.Context
+	Data    []byte
+	Options mq.PublishOptions
+}
+
+// PushBatchCall records the arguments to a PushBatch call.
+type PushBatchCall struct {
+	Ctx  context.Context
+	Data [][]byte
+}
+
 // UnsafePushCall records the arguments to an UnsafePush call.
 type UnsafePushCall struct {
 	Ctx  context.Context
@@ -61,9 +114,11 @@ type UnsafePushCall struct {
 // NewMockClient creates a new MockClient with default behavior (no errors).
 func NewMockClient() *MockClient {
 	return &MockClient{
-		PushCalls:       make([]PushCall, 0),
-		UnsafePushCalls: make([]UnsafePushCall, 0),
-		ConsumeChannel:  make(chan amqp.Delivery),
+		PushCalls:            make([]PushCall, 0),
+		PushWithOptionsCalls: make([]PushWithOptionsCall, 0),
+		PushBatchCalls:       make([]PushBatchCall, 0),
+		UnsafePushCalls:      make([]UnsafePushCall, 0),
+		ConsumeChannel:       make(chan amqp.Delivery),
 	}
 }
 
@@ -83,6 +138,39 @@ func (m *MockClient) Push(ctx context.Context, data []byte) error {
 	return m.PushError
 }
 
+// PushWithOptions implements ClientInterface.
+func (m *MockClient) PushWithOptions(ctx context.Context, data []byte, opts mq.PublishOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.PushWithOptionsCalls = append(m.PushWithOptionsCalls, PushWithOptionsCall{
+		Ctx:     ctx,
+		Data:    data,
+		Options: opts,
+	})
+
+	if m.PushWithOptionsFunc != nil {
+		return m.PushWithOptionsFunc(ctx, data, opts)
+	}
+	return m.PushWithOptionsError
+}
+
+// PushBatch implements ClientInterface.
+func (m *MockClient) PushBatch(ctx context.Context, data [][]byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.PushBatchCalls = append(m.PushBatchCalls, PushBatchCall{
+		Ctx:  ctx,
+		Data: data,
+	})
+
+	if m.PushBatchFunc != nil {
+		return m.PushBatchFunc(ctx, data)
+	}
+	return m.PushBatchError
+}
+
 // UnsafePush implements ClientInterface.
 func (m *MockClient) UnsafePush(ctx context.Context, data []byte) error {
 	m.mu.Lock()
@@ -112,6 +200,119 @@ func (m *MockClient) Consume() (<-chan amqp.Delivery, error) {
 	return m.ConsumeChannel, m.ConsumeError
 }
 
+// ConsumeLoop implements ClientInterface.
+func (m *MockClient) ConsumeLoop(ctx context.Context, handler mq.Handler, hooks mq.ConsumeHooks) error {
+	m.mu.Lock()
+	m.ConsumeLoopCalls++
+	consumeLoop := m.ConsumeLoopFunc
+	m.mu.Unlock()
+
+	// Called without the lock as the loop runs until it is stopped
+	if consumeLoop != nil {
+		return consumeLoop(ctx, handler, hooks)
+	}
+
+	if err := m.WaitReady(ctx); err != nil {
+		return err
+	}
+
+	deliveries, err := m.Consume()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	queue := m.QueueName
+	m.mu.Unlock()
+
+	return consume(ctx, queue, deliveries, handler, hooks)
+}
+
+// ConsumeQueues implements ClientInterface.
+func (m *MockClient) ConsumeQueues(ctx context.Context, handlers map[string]mq.Handler, hooks mq.ConsumeHooks) error {
+	m.mu.Lock()
+	m.ConsumeQueuesCalls++
+	consumeQueues := m.ConsumeQueuesFunc
+	m.mu.Unlock()
+
+	// Called without the lock as the loops run until they are stopped
+	if consumeQueues != nil {
+		return consumeQueues(ctx, handlers, hooks)
+	}
+
+	if err := m.WaitReady(ctx); err != nil {
+		return err
+	}
+
+	subscriptions := make(map[string]<-chan amqp.Delivery, len(handlers))
+	for queue := range handlers {
+		m.mu.Lock()
+		deliveries, ok := m.QueueChannels[queue]
+		m.mu.Unlock()
+
+		if !ok {
+			var err error
+			if deliveries, err = m.Consume(); err != nil {
+				return err
+			}
+		}
+		subscriptions[queue] = deliveries
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, len(handlers))
+	)
+	for queue, handler := range handlers {
+		wg.Go(func() {
+			if err := consume(ctx, queue, subscriptions[queue], handler, hooks); err != nil {
+				errs <- err
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// consume reports the subscription to hooks and hands deliveries to handler
+// until the channel is closed or ctx is done.
+func consume(ctx context.Context, queue string, deliveries <-chan amqp.Delivery, handler mq.Handler, hooks mq.ConsumeHooks) error {
+	if hooks.OnSubscribe != nil {
+		hooks.OnSubscribe(queue)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case delivery, ok := <-deliveries:
+			if !ok {
+				return nil
+			}
+			handler(ctx, delivery)
+		}
+	}
+}
+
+// WaitReady implements ClientInterface.
+func (m *MockClient) WaitReady(ctx context.Context) error {
+	m.mu.Lock()
+	m.WaitReadyCalls++
+	waitReady := m.WaitReadyFunc
+	m.mu.Unlock()
+
+	// Called without the lock so a blocking WaitReadyFunc does not block other calls
+	if waitReady != nil {
+		return waitReady(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.WaitReadyError
+}
+
 // Close implements ClientInterface.
 func (m *MockClient) Close() error {
 	m.mu.Lock()
@@ -131,8 +332,13 @@ func (m *MockClient) Reset() {
 	defer m.mu.Unlock()
 
 	m.PushCalls = make([]PushCall, 0)
+	m.PushWithOptionsCalls = make([]PushWithOptionsCall, 0)
+	m.PushBatchCalls = make([]PushBatchCall, 0)
 	m.UnsafePushCalls = make([]UnsafePushCall, 0)
 	m.ConsumeCalls = 0
+	m.ConsumeLoopCalls = 0
+	m.ConsumeQueuesCalls = 0
+	m.WaitReadyCalls = 0
 	m.CloseCalls = 0
 }
 
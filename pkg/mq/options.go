@@ -0,0 +1,86 @@
+package mq
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	// defaultContentType is the content type of messages pushed without options.
+	defaultContentType = "text/plain"
+
+	// MaxPriority is the highest message and queue priority the client
+	// supports. RabbitMQ allows more, but recommends at most 10 levels.
+	MaxPriority = 9
+)
+
+var (
+	errInvalidPriority   = errors.New("priority must be between 0 and 9")
+	errInvalidExpiration = errors.New("expiration cannot be negative")
+)
+
+// PublishOptions holds per-message properties for PushWithOptions.
+// Zero values leave the property unset.
+type PublishOptions struct {
+	// Headers are arbitrary application headers.
+	Headers amqp.Table
+	// ContentType of the body (default "text/plain").
+	ContentType string
+	// MessageID identifies the message, e.g. for deduplication.
+	MessageID string
+	// Type names the kind of message, so consumers of a queue carrying
+	// several kinds know how to decode the body.
+	Type string
+	// CorrelationID links the message to a request or another message.
+	CorrelationID string
+	// Expiration discards the message if it is not consumed in time (0 = never).
+	// RabbitMQ works in milliseconds; shorter durations are rounded down.
+	Expiration time.Duration
+	// Priority of the message (0-9); only honored by queues declared with
+	// the x-max-priority argument, see Options.MaxPriority.
+	Priority uint8
+}
+
+// Publishing validates the options and builds the message for data, as
+// PushWithOptions sends it.
+func (o PublishOptions) Publishing(data []byte) (amqp.Publishing, error) {
+	if o.Priority > MaxPriority {
+		return amqp.Publishing{}, errInvalidPriority
+	}
+
+	if o.Expiration < 0 {
+		return amqp.Publishing{}, errInvalidExpiration
+	}
+
+	if err := o.Headers.Validate(); err != nil {
+		return amqp.Publishing{}, err
+	}
+
+	msg := defaultPublishing(data)
+	msg.Headers = o.Headers
+	msg.MessageId = o.MessageID
+	msg.Type = o.Type
+	msg.CorrelationId = o.CorrelationID
+	msg.Priority = o.Priority
+
+	if o.ContentType != "" {
+		msg.ContentType = o.ContentType
+	}
+
+	if o.Expiration > 0 {
+		msg.Expiration = strconv.FormatInt(o.Expiration.Milliseconds(), 10)
+	}
+
+	return msg, nil
+}
+
+// defaultPublishing returns the message Push and UnsafePush send for data.
+func defaultPublishing(data []byte) amqp.Publishing {
+	return amqp.Publishing{
+		ContentType: defaultContentType,
+		Body:        data,
+	}
+}
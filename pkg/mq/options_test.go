@@ -0,0 +1,63 @@
+package mq
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var _ = Describe("PublishOptions", func() {
+	body := []byte("payload")
+
+	It("should default to a plain text message", func() {
+		msg, err := PublishOptions{}.Publishing(body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg).To(Equal(defaultPublishing(body)))
+		Expect(msg.ContentType).To(Equal("text/plain"))
+	})
+
+	It("should set all message properties", func() {
+		msg, err := PublishOptions{
+			Headers:       amqp.Table{"schema-version": int32(2)},
+			ContentType:   "application/x-protobuf",
+			MessageID:     "msg-1",
+			Type:          "reading-batch",
+			CorrelationID: "req-1",
+			Expiration:    90 * time.Second,
+			Priority:      5,
+		}.Publishing(body)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(msg.Body).To(Equal(body))
+		Expect(msg.Headers).To(HaveKeyWithValue("schema-version", int32(2)))
+		Expect(msg.ContentType).To(Equal("application/x-protobuf"))
+		Expect(msg.MessageId).To(Equal("msg-1"))
+		Expect(msg.Type).To(Equal("reading-batch"))
+		Expect(msg.CorrelationId).To(Equal("req-1"))
+		Expect(msg.Expiration).To(Equal("90000"))
+		Expect(msg.Priority).To(Equal(uint8(5)))
+	})
+
+	It("should reject invalid options", func() {
+		_, err := PublishOptions{Priority: 10}.Publishing(body)
+		Expect(err).To(MatchError(errInvalidPriority))
+
+		_, err = PublishOptions{Expiration: -time.Second}.Publishing(body)
+		Expect(err).To(MatchError(errInvalidExpiration))
+
+		_, err = PublishOptions{Headers: amqp.Table{"bad": struct{}{}}}.Publishing(body)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Queue arguments", func() {
+	It("should declare plain queues without priorities", func() {
+		Expect((&Client{}).queueArgs()).To(BeNil())
+	})
+
+	It("should declare priority queues with x-max-priority", func() {
+		Expect((&Client{maxPriority: MaxPriority}).queueArgs()).To(Equal(amqp.Table{"x-max-priority": int32(9)}))
+	})
+})
@@ -0,0 +1,42 @@
+// Package objectstore writes objects, such as exports and reports, to a
+// bucket of an object storage service. The S3 store speaks the S3 API, which
+// AWS S3, MinIO and Google Cloud Storage (through its XML API with HMAC keys)
+// all serve.
+package objectstore
+
+import (
+	"context"
+	"io"
+)
+
+// WriteOptions describe an object being written.
+type WriteOptions struct {
+	// ContentType is the media type of the object (optional, default
+	// application/octet-stream)
+	ContentType string
+	// ContentEncoding is the encoding applied to the content, such as gzip
+	// (optional)
+	ContentEncoding string
+}
+
+// Store reads and writes objects of a bucket.
+type Store interface {
+	// NewWriter starts writing the object at key. Written data is uploaded
+	// in parts while writing, so objects of any size can be streamed. The
+	// object becomes visible once Close succeeds; Abort discards it.
+	NewWriter(ctx context.Context, key string, opts WriteOptions) (Writer, error)
+	// Open reads the object at key; the caller must close it
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// URL returns the location of the object at key, for logs and responses
+	URL(key string) string
+}
+
+// Writer writes one object. It is not safe for concurrent use.
+type Writer interface {
+	io.Writer
+	// Close uploads the remaining data and completes the object
+	Close() error
+	// Abort discards the uploaded data; it must be called instead of Close
+	// when writing fails halfway
+	Abort() error
+}
@@ -0,0 +1,282 @@
+// Package runner runs several services in one process with ordered startup,
+// shared signal handling and staged shutdown.
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	// defaultStartTimeout bounds waiting for a service to become ready when not configured.
+	defaultStartTimeout = 30 * time.Second
+	// defaultStopTimeout bounds waiting for a service to stop when not configured.
+	defaultStopTimeout = 30 * time.Second
+	// readyPollInterval is the time between attempts of WaitTCP.
+	readyPollInterval = 100 * time.Millisecond
+)
+
+// ErrExited is reported for a service whose Run returned nil before shutdown.
+var ErrExited = errors.New("service exited unexpectedly")
+
+// errStartupAborted ends startup without an error of its own, when the runner
+// is stopped or a service returned, which stop reports.
+var errStartupAborted = errors.New("startup aborted")
+
+// Service is one component run by the Runner.
+type Service struct {
+	// Name identifies the service in logs and errors
+	Name string
+	// Run runs the service until ctx is canceled. It must not handle signals
+	// itself; the Runner does.
+	Run func(ctx context.Context) error
+	// Ready blocks until the service is ready for the services started after
+	// it (optional). Without it, the next service starts right away.
+	Ready func(ctx context.Context) error
+}
+
+// Config holds the configuration for the Runner.
+type Config struct {
+	Logger   *slog.Logger
+	Services []Service // Started in order and stopped in reverse order
+	// HandleSignals stops all services on SIGINT and SIGTERM
+	HandleSignals bool
+	// StartTimeout bounds waiting for each service to become ready (optional, default 30 seconds)
+	StartTimeout time.Duration
+	// StopTimeout bounds waiting for each service to stop (optional, default 30 seconds)
+	StopTimeout time.Duration
+}
+
+// Runner runs services together. If one fails or exits, all are stopped.
+type Runner struct {
+	logger        *slog.Logger
+	services      []Service
+	handleSignals bool
+	startTimeout  time.Duration
+	stopTimeout   time.Duration
+}
+
+// running is a started service.
+type running struct {
+	name   string
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error // Set before done is closed
+}
+
+// New creates a new Runner instance.
+func New(cfg *Config) (*Runner, error) {
+	if cfg == nil {
+		return nil, errors.New("runner config cannot be nil")
+	}
+
+	if cfg.Logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+
+	if len(cfg.Services) == 0 {
+		return nil, errors.New("at least one service is required")
+	}
+
+	names := make(map[string]bool, len(cfg.Services))
+	for _, svc := range cfg.Services {
+		if svc.Name == "" || svc.Run == nil {
+			return nil, errors.New("services need a name and a run function")
+		}
+		if names[svc.Name] {
+			return nil, fmt.Errorf("duplicate service %q", svc.Name)
+		}
+		names[svc.Name] = true
+	}
+
+	if cfg.StartTimeout < 0 || cfg.StopTimeout < 0 {
+		return nil, errors.New("timeouts cannot be negative")
+	}
+
+	startTimeout := cfg.StartTimeout
+	if startTimeout == 0 {
+		startTimeout = defaultStartTimeout
+	}
+
+	stopTimeout := cfg.StopTimeout
+	if stopTimeout == 0 {
+		stopTimeout = defaultStopTimeout
+	}
+
+	return &Runner{
+		logger:        cfg.Logger,
+		services:      cfg.Services,
+		handleSignals: cfg.HandleSignals,
+		startTimeout:  startTimeout,
+		stopTimeout:   stopTimeout,
+	}, nil
+}
+
+// Run starts the services in order, waiting for each to become ready, and
+// blocks until ctx is canceled, a signal is received or a service returns.
+// It then stops the services in reverse order and returns the errors of all
+// services that failed, joined.
+func (r *Runner) Run(ctx context.Context) error {
+	// Set up signal handling unless the embedding process handles signals;
+	// receiving from the nil channel otherwise blocks forever
+	var sigChan chan os.Signal
+	if r.handleSignals {
+		sigChan = make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+		defer signal.Stop(sigChan)
+	}
+
+	// exited receives every service whose Run returned
+	exited := make(chan *running, len(r.services))
+	started := make([]*running, 0, len(r.services))
+
+	for _, svc := range r.services {
+		rs := r.start(ctx, svc, exited)
+		started = append(started, rs)
+
+		if err := r.waitReady(ctx, svc, rs, sigChan); err != nil {
+			errs := r.stop(started)
+			if !errors.Is(err, errStartupAborted) {
+				errs = append([]error{err}, errs...)
+			}
+			return errors.Join(errs...)
+		}
+	}
+
+	r.logger.Info("all services started", "count", len(started))
+
+	select {
+	case sig := <-sigChan:
+		r.logger.Info("received shutdown signal", "signal", sig.String())
+	case <-ctx.Done():
+		r.logger.Info("context canceled, shutting down")
+	case rs := <-exited:
+		r.logger.Error("service stopped, shutting down", "service", rs.name, "error", rs.err)
+	}
+
+	return errors.Join(r.stop(started)...)
+}
+
+// start runs svc in a goroutine. Each service gets its own context, which is
+// not canceled with ctx, so stop can stop services one at a time.
+func (r *Runner) start(ctx context.Context, svc Service, exited chan<- *running) *running {
+	svcCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	rs := &running{name: svc.Name, cancel: cancel, done: make(chan struct{})}
+
+	r.logger.Info("starting service", "service", svc.Name)
+
+	go func() {
+		defer close(rs.done)
+
+		err := svc.Run(svcCtx)
+		switch {
+		case err != nil && !errors.Is(err, context.Canceled):
+			rs.err = fmt.Errorf("%s: %w", svc.Name, err)
+		case err == nil && svcCtx.Err() == nil:
+			rs.err = fmt.Errorf("%s: %w", svc.Name, ErrExited)
+		}
+		exited <- rs
+	}()
+
+	return rs
+}
+
+// waitReady waits until svc is ready. It fails if the service returns, its
+// readiness check fails or times out, or the runner is stopped meanwhile.
+func (r *Runner) waitReady(ctx context.Context, svc Service, rs *running, sigChan <-chan os.Signal) error {
+	if svc.Ready == nil {
+		return nil
+	}
+
+	readyCtx, cancel := context.WithTimeout(ctx, r.startTimeout)
+	defer cancel()
+
+	ready := make(chan error, 1)
+	go func() {
+		ready <- svc.Ready(readyCtx)
+	}()
+
+	select {
+	case err := <-ready:
+		if err != nil {
+			if ctx.Err() != nil {
+				r.logger.Info("context canceled during startup")
+				return errStartupAborted
+			}
+			return fmt.Errorf("%s: not ready: %w", svc.Name, err)
+		}
+		r.logger.Info("service ready", "service", svc.Name)
+		return nil
+	case <-rs.done:
+		r.logger.Error("service stopped during startup", "service", svc.Name, "error", rs.err)
+		return errStartupAborted
+	case sig := <-sigChan:
+		r.logger.Info("received shutdown signal during startup", "signal", sig.String())
+		return errStartupAborted
+	}
+}
+
+// stop stops the services in reverse order, each after the ones started
+// after it have returned, and collects their errors.
+func (r *Runner) stop(started []*running) []error {
+	var errs []error
+
+	for i := len(started) - 1; i >= 0; i-- {
+		rs := started[i]
+
+		r.logger.Info("stopping service", "service", rs.name)
+		rs.cancel()
+
+		timer := time.NewTimer(r.stopTimeout)
+		select {
+		case <-rs.done:
+			if rs.err != nil {
+				errs = append(errs, rs.err)
+			}
+			r.logger.Info("service stopped", "service", rs.name)
+		case <-timer.C:
+			errs = append(errs, fmt.Errorf("%s: did not stop within %s", rs.name, r.stopTimeout))
+			r.logger.Error("service did not stop in time", "service", rs.name, "timeout", r.stopTimeout)
+		}
+		timer.Stop()
+	}
+
+	return errs
+}
+
+// WaitTCP returns a Ready function that waits until addr accepts TCP
+// connections, for services that are ready once they listen.
+func WaitTCP(addr string) func(ctx context.Context) error {
+	return WaitListening("tcp", addr)
+}
+
+// WaitListening returns a Ready function that waits until addr accepts
+// connections on network, such as "tcp" or "unix".
+func WaitListening(network, addr string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+
+		ticker := time.NewTicker(readyPollInterval)
+		defer ticker.Stop()
+
+		for {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err == nil {
+				return conn.Close()
+			}
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%s not reachable: %w", addr, err)
+			case <-ticker.C:
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"procodus.dev/demo-app/pkg/runner"
+)
+
+// recorder records service events in order.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// service returns a service that records its start and stop and runs until
+// its context is canceled.
+func (r *recorder) service(name string) runner.Service {
+	return runner.Service{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			r.add("start " + name)
+			<-ctx.Done()
+			r.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+var _ = Describe("Runner", func() {
+	var (
+		logger *slog.Logger
+		rec    *recorder
+	)
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+		rec = &recorder{}
+	})
+
+	Describe("New", func() {
+		It("should reject invalid configurations", func() {
+			noop := func(context.Context) error { return nil }
+
+			_, err := runner.New(nil)
+			Expect(err).To(MatchError("runner config cannot be nil"))
+
+			_, err = runner.New(&runner.Config{Services: []runner.Service{{Name: "a", Run: noop}}})
+			Expect(err).To(MatchError("logger cannot be nil"))
+
+			_, err = runner.New(&runner.Config{Logger: logger})
+			Expect(err).To(MatchError("at least one service is required"))
+
+			_, err = runner.New(&runner.Config{Logger: logger, Services: []runner.Service{{Name: "a"}}})
+			Expect(err).To(MatchError("services need a name and a run function"))
+
+			_, err = runner.New(&runner.Config{Logger: logger, Services: []runner.Service{{Name: "a", Run: noop}, {Name: "a", Run: noop}}})
+			Expect(err).To(MatchError(`duplicate service "a"`))
+
+			_, err = runner.New(&runner.Config{Logger: logger, Services: []runner.Service{{Name: "a", Run: noop}}, StopTimeout: -time.Second})
+			Expect(err).To(MatchError("timeouts cannot be negative"))
+		})
+	})
+
+	Describe("Run", func() {
+		It("should start services in order and stop them in reverse order", func() {
+			first := rec.service("first")
+			// Ready once Run has recorded its start, so second starts after it
+			first.Ready = func(ctx context.Context) error {
+				for len(rec.get()) == 0 {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(10 * time.Millisecond):
+					}
+				}
+				return nil
+			}
+
+			r, err := runner.New(&runner.Config{
+				Logger:   logger,
+				Services: []runner.Service{first, rec.service("second")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- r.Run(ctx)
+			}()
+
+			Eventually(rec.get).Should(HaveLen(2))
+
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+			Expect(rec.get()).To(Equal([]string{"start first", "start second", "stop second", "stop first"}))
+		})
+
+		It("should stop all services when one fails", func() {
+			errBoom := errors.New("boom")
+
+			r, err := runner.New(&runner.Config{
+				Logger: logger,
+				Services: []runner.Service{
+					rec.service("first"),
+					{Name: "failing", Run: func(context.Context) error {
+						time.Sleep(50 * time.Millisecond)
+						return errBoom
+					}},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = r.Run(context.Background())
+			Expect(err).To(MatchError(errBoom))
+			Expect(err.Error()).To(ContainSubstring("failing: boom"))
+			Expect(rec.get()).To(Equal([]string{"start first", "stop first"}))
+		})
+
+		It("should report a service that exits on its own", func() {
+			r, err := runner.New(&runner.Config{
+				Logger: logger,
+				Services: []runner.Service{
+					rec.service("first"),
+					{Name: "short", Run: func(context.Context) error { return nil }},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = r.Run(context.Background())
+			Expect(err).To(MatchError(runner.ErrExited))
+			Expect(err.Error()).To(ContainSubstring("short"))
+		})
+
+		It("should not start later services when one does not become ready", func() {
+			first := rec.service("first")
+			first.Ready = func(context.Context) error { return errors.New("not listening") }
+
+			r, err := runner.New(&runner.Config{
+				Logger:   logger,
+				Services: []runner.Service{first, rec.service("second")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = r.Run(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("first: not ready: not listening")))
+			Expect(rec.get()).NotTo(ContainElement("start second"))
+		})
+
+		It("should give up on services that do not stop in time", func() {
+			release := make(chan struct{})
+			defer close(release)
+
+			r, err := runner.New(&runner.Config{
+				Logger: logger,
+				Services: []runner.Service{{Name: "stuck", Run: func(context.Context) error {
+					<-release
+					return nil
+				}}},
+				StopTimeout: 50 * time.Millisecond,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err = r.Run(ctx)
+			Expect(err).To(MatchError(ContainSubstring("stuck: did not stop within 50ms")))
+		})
+	})
+
+	Describe("WaitTCP", func() {
+		It("should return once the address accepts connections", func() {
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = lis.Close() }()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			Expect(runner.WaitTCP(lis.Addr().String())(ctx)).To(Succeed())
+		})
+
+		It("should fail when the context ends first", func() {
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			addr := lis.Addr().String()
+			Expect(lis.Close()).To(Succeed())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+
+			Expect(runner.WaitTCP(addr)(ctx)).To(MatchError(ContainSubstring("not reachable")))
+		})
+	})
+
+	Describe("WaitListening", func() {
+		It("should wait for Unix sockets", func() {
+			dir, err := os.MkdirTemp("", "sock")
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = os.RemoveAll(dir) }()
+			path := filepath.Join(dir, "ready.sock")
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				lis, err := net.Listen("unix", path)
+				if err == nil {
+					<-ctx.Done()
+					_ = lis.Close()
+				}
+			}()
+
+			Expect(runner.WaitListening("unix", path)(ctx)).To(Succeed())
+		})
+	})
+})
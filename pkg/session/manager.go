@@ -0,0 +1,169 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultCookieName is the session cookie used when none is configured.
+	DefaultCookieName = "demo_app_session"
+	// DefaultTTL is how long idle sessions are kept when not configured.
+	DefaultTTL = 30 * 24 * time.Hour
+	// storeTimeout bounds a single load or save.
+	storeTimeout = 2 * time.Second
+)
+
+// ManagerConfig holds the configuration of a Manager.
+type ManagerConfig struct {
+	// Store persists the sessions
+	Store Store
+	// Logger is the structured logger
+	Logger *slog.Logger
+	// CookieName is the name of the session cookie (optional, default demo_app_session)
+	CookieName string
+	// TTL is how long a session is kept after its last use (optional, default 30 days)
+	TTL time.Duration
+}
+
+// Manager loads the session of each request from a Store and saves it after
+// the request when it changed.
+type Manager struct {
+	store      Store
+	logger     *slog.Logger
+	cookieName string
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+// NewManager validates the configuration and creates a Manager.
+func NewManager(cfg *ManagerConfig) (*Manager, error) {
+	if cfg == nil {
+		return nil, errors.New("session manager config cannot be nil")
+	}
+	if cfg.Store == nil {
+		return nil, errors.New("session store cannot be nil")
+	}
+	if cfg.Logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	if cfg.TTL < 0 {
+		return nil, errors.New("session TTL cannot be negative")
+	}
+
+	m := &Manager{
+		store:      cfg.Store,
+		logger:     cfg.Logger,
+		cookieName: cfg.CookieName,
+		ttl:        cfg.TTL,
+		now:        time.Now,
+	}
+	if m.cookieName == "" {
+		m.cookieName = DefaultCookieName
+	}
+	if m.ttl == 0 {
+		m.ttl = DefaultTTL
+	}
+
+	return m, nil
+}
+
+// Close closes the store.
+func (m *Manager) Close() error {
+	return m.store.Close()
+}
+
+// Middleware makes the session of each request available through FromContext.
+// Clients without a valid session get a new one. Sessions are saved after the
+// request when they changed or when half their TTL has passed since the last
+// save, so active sessions do not expire.
+//
+// If the store cannot be reached, the request gets a new session that is not
+// saved and no cookie is sent, so the stored session survives the outage.
+func (m *Manager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, available := m.load(r)
+		refresh := available && (s.savedAt.IsZero() || m.now().Sub(s.savedAt) > m.ttl/2)
+		if refresh {
+			// The cookie must be set before the handler writes the response
+			m.setCookie(w, r, s.id)
+		}
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), s)))
+
+		if available {
+			m.persist(r.Context(), s, refresh)
+		}
+	})
+}
+
+// load returns the session of r and whether the store was available.
+func (m *Manager) load(r *http.Request) (*Session, bool) {
+	cookie, err := r.Cookie(m.cookieName)
+	if err != nil || !validID(cookie.Value) {
+		return New(), true
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), storeTimeout)
+	defer cancel()
+
+	record, err := m.store.Load(ctx, cookie.Value)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		// Expired; start over with a new ID so IDs are never reused
+		return New(), true
+	case err != nil:
+		m.logger.Error("failed to load session", "error", err)
+		return New(), false
+	}
+
+	values := record.Values
+	if values == nil {
+		values = make(map[string]string)
+	}
+
+	return &Session{id: cookie.Value, values: values, savedAt: record.SavedAt}, true
+}
+
+// persist saves or deletes s after a request as needed.
+func (m *Manager) persist(ctx context.Context, s *Session, refresh bool) {
+	// The response is written; finish even if the client went away
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), storeTimeout)
+	defer cancel()
+
+	s.mu.Lock()
+	deleted, dirty := s.deleted, s.dirty
+	s.mu.Unlock()
+
+	if deleted {
+		if err := m.store.Delete(ctx, s.id); err != nil {
+			m.logger.Error("failed to delete session", "error", err)
+		}
+		return
+	}
+
+	// New sessions are only stored once something is put in them
+	if !dirty && (!refresh || s.savedAt.IsZero()) {
+		return
+	}
+
+	if err := m.store.Save(ctx, s.id, s.record(m.now()), m.ttl); err != nil {
+		m.logger.Error("failed to save session", "error", err)
+	}
+}
+
+// setCookie sends the session cookie.
+func (m *Manager) setCookie(w http.ResponseWriter, r *http.Request, id string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     m.cookieName,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(m.ttl.Seconds()),
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,189 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// failingStore is a Store whose backend is unreachable.
+type failingStore struct{ saves int }
+
+func (s *failingStore) Load(context.Context, string) (*Record, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (s *failingStore) Save(context.Context, string, *Record, time.Duration) error {
+	s.saves++
+	return errors.New("connection refused")
+}
+
+func (s *failingStore) Delete(context.Context, string) error { return nil }
+func (s *failingStore) Close() error                         { return nil }
+
+var _ = Describe("Manager", func() {
+	var (
+		store   *MemoryStore
+		manager *Manager
+		now     time.Time
+		handler func(*Session)
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+		store = NewMemoryStore()
+		store.now = func() time.Time { return now }
+
+		var err error
+		manager, err = NewManager(&ManagerConfig{
+			Store:  store,
+			Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+			TTL:    time.Hour,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		manager.now = func() time.Time { return now }
+
+		handler = func(*Session) {}
+	})
+
+	serve := func(cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		manager.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			s := FromContext(r.Context())
+			Expect(s).NotTo(BeNil())
+			handler(s)
+		})).ServeHTTP(rec, req)
+		return rec
+	}
+
+	sessionCookie := func(rec *httptest.ResponseRecorder) *http.Cookie {
+		for _, c := range rec.Result().Cookies() {
+			if c.Name == DefaultCookieName {
+				return c
+			}
+		}
+		return nil
+	}
+
+	It("should validate its configuration", func() {
+		_, err := NewManager(nil)
+		Expect(err).To(HaveOccurred())
+		_, err = NewManager(&ManagerConfig{Logger: slog.Default()})
+		Expect(err).To(MatchError(ContainSubstring("store")))
+		_, err = NewManager(&ManagerConfig{Store: store, Logger: slog.Default(), TTL: -time.Second})
+		Expect(err).To(MatchError(ContainSubstring("TTL")))
+	})
+
+	It("should issue a secure session cookie and store sessions once written", func() {
+		rec := serve(nil)
+		cookie := sessionCookie(rec)
+		Expect(cookie).NotTo(BeNil())
+		Expect(cookie.HttpOnly).To(BeTrue())
+		Expect(cookie.SameSite).To(Equal(http.SameSiteLaxMode))
+		Expect(cookie.MaxAge).To(Equal(3600))
+		Expect(store.Len()).To(BeZero())
+
+		handler = func(s *Session) { s.Set("theme", "dark") }
+		cookie = sessionCookie(serve(nil))
+		Expect(store.Len()).To(Equal(1))
+
+		var theme string
+		handler = func(s *Session) { theme = s.Get("theme") }
+		Expect(sessionCookie(serve(cookie))).To(BeNil())
+		Expect(theme).To(Equal("dark"))
+	})
+
+	It("should replace unknown and malformed session IDs", func() {
+		var id string
+		handler = func(s *Session) { id = s.ID() }
+
+		serve(&http.Cookie{Name: DefaultCookieName, Value: "not-a-session"})
+		Expect(validID(id)).To(BeTrue())
+
+		unknown := newID()
+		serve(&http.Cookie{Name: DefaultCookieName, Value: unknown})
+		Expect(id).NotTo(Equal(unknown))
+	})
+
+	It("should extend active sessions and expire idle ones", func() {
+		handler = func(s *Session) { s.Set("theme", "dark") }
+		cookie := sessionCookie(serve(nil))
+
+		// Past half the TTL the session is saved again with a fresh cookie
+		now = now.Add(40 * time.Minute)
+		handler = func(*Session) {}
+		Expect(sessionCookie(serve(cookie))).NotTo(BeNil())
+
+		now = now.Add(50 * time.Minute)
+		var theme string
+		handler = func(s *Session) { theme = s.Get("theme") }
+		serve(cookie)
+		Expect(theme).To(Equal("dark"))
+
+		now = now.Add(2 * time.Hour)
+		serve(cookie)
+		Expect(theme).To(BeEmpty())
+	})
+
+	It("should delete destroyed sessions", func() {
+		handler = func(s *Session) { s.Set("user", "sam") }
+		cookie := sessionCookie(serve(nil))
+		Expect(store.Len()).To(Equal(1))
+
+		handler = func(s *Session) { s.Destroy() }
+		serve(cookie)
+		Expect(store.Len()).To(BeZero())
+	})
+
+	It("should serve requests without saving when the store is unreachable", func() {
+		failing := &failingStore{}
+		manager.store = failing
+		handler = func(s *Session) { s.Set("theme", "dark") }
+
+		rec := serve(&http.Cookie{Name: DefaultCookieName, Value: newID()})
+		Expect(sessionCookie(rec)).To(BeNil())
+		Expect(failing.saves).To(BeZero())
+	})
+
+	It("should return no session outside the middleware", func() {
+		Expect(FromContext(context.Background())).To(BeNil())
+	})
+})
+
+var _ = Describe("MemoryStore", func() {
+	It("should copy records so callers cannot change stored sessions", func() {
+		store := NewMemoryStore()
+		record := &Record{Values: map[string]string{"a": "1"}}
+		Expect(store.Save(context.Background(), "id", record, time.Hour)).To(Succeed())
+		record.Values["a"] = "2"
+
+		loaded, err := store.Load(context.Background(), "id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Values).To(HaveKeyWithValue("a", "1"))
+	})
+
+	It("should sweep expired sessions", func() {
+		now := time.Now()
+		store := NewMemoryStore()
+		store.now = func() time.Time { return now }
+
+		Expect(store.Save(context.Background(), "old", &Record{}, time.Minute)).To(Succeed())
+		now = now.Add(2 * time.Minute)
+		Expect(store.Save(context.Background(), "new", &Record{}, time.Minute)).To(Succeed())
+
+		Expect(store.Len()).To(Equal(1))
+		_, err := store.Load(context.Background(), "old")
+		Expect(err).To(MatchError(ErrNotFound))
+	})
+})
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often MemoryStore removes expired sessions.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps sessions in process memory. Sessions are lost on restart
+// and not shared between replicas.
+type MemoryStore struct {
+	mu        sync.Mutex
+	sessions  map[string]memoryEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryEntry struct {
+	record  Record
+	expires time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]memoryEntry),
+		now:      time.Now,
+	}
+}
+
+// Load returns a copy of the record of a session, or ErrNotFound.
+func (s *MemoryStore) Load(_ context.Context, id string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.sessions[id]
+	if !ok || !s.now().Before(entry.expires) {
+		return nil, ErrNotFound
+	}
+
+	return &Record{Values: maps.Clone(entry.record.Values), SavedAt: entry.record.SavedAt}, nil
+}
+
+// Save stores a copy of the record of a session.
+func (s *MemoryStore) Save(_ context.Context, id string, record *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sessions[id] = memoryEntry{
+		record:  Record{Values: maps.Clone(record.Values), SavedAt: record.SavedAt},
+		expires: now.Add(ttl),
+	}
+
+	// Abandoned sessions are never loaded again; remove them periodically
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for id, entry := range s.sessions {
+			if !now.Before(entry.expires) {
+				delete(s.sessions, id)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	return nil
+}
+
+// Delete removes a session.
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+	return nil
+}
+
+// Close does nothing; it exists to satisfy Store.
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// Len returns the number of stored sessions, including expired ones not yet removed.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.sessions)
+}
@@ -0,0 +1,95 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultKeyPrefix is prepended to session IDs to form Redis keys.
+const DefaultKeyPrefix = "demo_app:session:"
+
+// RedisConfig holds the configuration of a RedisStore.
+type RedisConfig struct {
+	// URL is the Redis URL, e.g. redis://:password@localhost:6379/0
+	URL string
+	// KeyPrefix is prepended to session IDs (optional, default demo_app:session:)
+	KeyPrefix string
+}
+
+// RedisStore keeps sessions in Redis as JSON strings that expire with the
+// session, so every replica sees the same sessions.
+type RedisStore struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisStore creates a store using the Redis server at cfg.URL. It does not
+// connect until the first command; see Ping.
+func NewRedisStore(cfg *RedisConfig) (*RedisStore, error) {
+	if cfg == nil {
+		return nil, errors.New("redis config cannot be nil")
+	}
+	if cfg.URL == "" {
+		return nil, errors.New("redis URL cannot be empty")
+	}
+
+	opts, err := redis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	prefix := cfg.KeyPrefix
+	if prefix == "" {
+		prefix = DefaultKeyPrefix
+	}
+
+	return &RedisStore{client: redis.NewClient(opts), keyPrefix: prefix}, nil
+}
+
+// Ping checks that Redis is reachable.
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+// Load returns the record of a session, or ErrNotFound.
+func (s *RedisStore) Load(ctx context.Context, id string) (*Record, error) {
+	data, err := s.client.Get(ctx, s.keyPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("invalid session record: %w", err)
+	}
+
+	return &record, nil
+}
+
+// Save stores the record of a session, expiring it after ttl.
+func (s *RedisStore) Save(ctx context.Context, id string, record *Record, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(ctx, s.keyPrefix+id, data, ttl).Err()
+}
+
+// Delete removes a session.
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	return s.client.Del(ctx, s.keyPrefix+id).Err()
+}
+
+// Close closes the Redis client.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,147 @@
+// Package session provides server-side HTTP sessions backed by a pluggable
+// store. The in-memory store suits a single frontend replica; the Redis store
+// lets several replicas behind a load balancer share sessions.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by Store.Load for unknown or expired sessions.
+var ErrNotFound = errors.New("session not found")
+
+// Record is the persisted state of a session.
+type Record struct {
+	// Values are the session's key/value pairs
+	Values map[string]string `json:"values"`
+	// SavedAt is when the record was last saved; the Manager uses it to
+	// extend the expiry of active sessions
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// Store persists session records by ID.
+type Store interface {
+	// Load returns the record of a session, or ErrNotFound
+	Load(ctx context.Context, id string) (*Record, error)
+	// Save stores the record of a session, expiring it after ttl
+	Save(ctx context.Context, id string, record *Record, ttl time.Duration) error
+	// Delete removes a session; deleting an unknown session is not an error
+	Delete(ctx context.Context, id string) error
+	// Close releases the store's resources
+	Close() error
+}
+
+// Session is the session of the current request. It is safe for concurrent use.
+type Session struct {
+	mu      sync.Mutex
+	id      string
+	values  map[string]string
+	savedAt time.Time
+	dirty   bool
+	// deleted is set when the session was destroyed during the request
+	deleted bool
+}
+
+// ID returns the session ID.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Get returns the value of key, or an empty string.
+func (s *Session) Get(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.values[key]
+}
+
+// Set stores value under key. The session is saved after the request.
+func (s *Session) Set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, ok := s.values[key]; ok && current == value {
+		return
+	}
+	s.values[key] = value
+	s.dirty = true
+}
+
+// Remove deletes key. The session is saved after the request.
+func (s *Session) Remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[key]; !ok {
+		return
+	}
+	delete(s.values, key)
+	s.dirty = true
+}
+
+// Destroy deletes the session from the store after the request, for example
+// on logout. The client gets a new session on its next request.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleted = true
+}
+
+// record returns a copy of the session's values for saving at now.
+func (s *Session) record(now time.Time) *Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := make(map[string]string, len(s.values))
+	for k, v := range s.values {
+		values[k] = v
+	}
+
+	return &Record{Values: values, SavedAt: now}
+}
+
+type sessionKey struct{}
+
+// FromContext returns the session of a request handled by Manager.Middleware,
+// or nil when sessions are not enabled.
+func FromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionKey{}).(*Session)
+	return s
+}
+
+// NewContext returns a copy of ctx carrying s. Handlers normally get their
+// session from Manager.Middleware; this is for tests and background work.
+func NewContext(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, s)
+}
+
+// New returns an unsaved session with a random ID, for use with NewContext.
+func New() *Session {
+	return &Session{id: newID(), values: make(map[string]string)}
+}
+
+// idBytes is the size of a session ID before hex encoding.
+const idBytes = 32
+
+// newID returns a random hex-encoded session ID.
+func newID() string {
+	b := make([]byte, idBytes)
+	_, _ = rand.Read(b) // crypto/rand.Read never returns an error
+	return hex.EncodeToString(b)
+}
+
+// validID reports whether id has the form of an issued session ID, so that
+// arbitrary cookie values never reach the store.
+func validID(id string) bool {
+	if len(id) != 2*idBytes {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
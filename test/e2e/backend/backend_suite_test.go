@@ -16,7 +16,8 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	"procodus.dev/demo-app/internal/backend"
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/test/e2e/fixtures"
 	e2econtainers "procodus.dev/demo-app/test/e2e/testcontainers"
 )
 
@@ -38,7 +39,7 @@ var (
 
 	// gRPC client.
 	grpcConn   *grpc.ClientConn
-	grpcClient iot.IoTServiceClient
+	grpcClient iotv1.IoTServiceClient
 
 	// RabbitMQ client for publishing test messages.
 	mqConn    *amqp.Connection
@@ -170,7 +171,7 @@ var _ = BeforeSuite(func() {
 		Fail(fmt.Sprintf("Failed to create gRPC client: %v", err))
 	}
 
-	grpcClient = iot.NewIoTServiceClient(grpcConn)
+	grpcClient = iotv1.NewIoTServiceClient(grpcConn)
 
 	testLogger.Info("gRPC client connected", "address", grpcAddr)
 
@@ -189,6 +190,14 @@ var _ = BeforeSuite(func() {
 	// No need to declare them here as it would conflict with consumer declarations
 
 	testLogger.Info("RabbitMQ client ready")
+
+	fixtures.Use(&fixtures.Env{
+		Channel:     mqChannel,
+		DeviceQueue: deviceQueueName,
+		SensorQueue: sensorQueueName,
+		Client:      grpcClient,
+	})
+
 	testLogger.Info("backend E2E test environment ready")
 })
 
@@ -0,0 +1,118 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gmeasure"
+	"gorm.io/gorm"
+
+	"procodus.dev/demo-app/internal/backend"
+	e2econtainers "procodus.dev/demo-app/test/e2e/testcontainers"
+)
+
+var _ = Describe("Backend Bulk Reading Load E2E", func() {
+	const (
+		deviceID  = "bulk-e2e-device"
+		batchSize = 20000
+	)
+
+	var db *gorm.DB
+
+	BeforeEach(func() {
+		ctx := context.Background()
+
+		host, port, user, password, dbname, err := e2econtainers.GetPostgresConnectionInfo(ctx, postgresContainer, &e2econtainers.PostgresConfig{
+			User:     "testuser",
+			Password: "testpass",
+			Database: "testdb",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		db, err = backend.NewDB(&backend.DBConfig{
+			Host:     host,
+			Port:     port,
+			User:     user,
+			Password: password,
+			DBName:   dbname,
+			SSLMode:  "disable",
+			Logger:   testLogger,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(db.Where("device_id = ?", deviceID).FirstOrCreate(&backend.IoTDevice{DeviceID: deviceID, LastSeen: time.Now()}).Error).To(Succeed())
+
+		DeferCleanup(func() {
+			Expect(db.Exec("DELETE FROM sensor_readings WHERE device_id = ?", deviceID).Error).To(Succeed())
+			Expect(backend.CloseDB(db, testLogger)).To(Succeed())
+		})
+	})
+
+	readings := func(n int) []backend.SensorReading {
+		start := time.Now().UTC().Add(-time.Duration(n) * time.Second)
+		out := make([]backend.SensorReading, n)
+		for i := range out {
+			out[i] = backend.SensorReading{
+				DeviceID:     deviceID,
+				Timestamp:    start.Add(time.Duration(i) * time.Second),
+				Temperature:  20 + float64(i%10),
+				Humidity:     50,
+				Pressure:     1013,
+				BatteryLevel: 90,
+			}
+		}
+		return out
+	}
+
+	count := func() int64 {
+		var n int64
+		Expect(db.Model(&backend.SensorReading{}).Where("device_id = ?", deviceID).Count(&n).Error).To(Succeed())
+		return n
+	}
+
+	It("should copy readings into sensor_readings", func() {
+		n, err := backend.NewReadingWriter(db, true).WriteReadings(context.Background(), readings(100))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(int64(100)))
+		Expect(count()).To(Equal(int64(100)))
+
+		// IDs come from the sequence and stay usable for regular inserts
+		Expect(db.Create(&backend.SensorReading{DeviceID: deviceID, Timestamp: time.Now(), Temperature: 20, Humidity: 50, Pressure: 1013, BatteryLevel: 90}).Error).To(Succeed())
+		Expect(count()).To(Equal(int64(101)))
+	})
+
+	It("should write nothing when a reading is rejected", func() {
+		batch := readings(10)
+		batch[5].DeviceID = "bulk-e2e-unknown-device"
+
+		_, err := backend.NewReadingWriter(db, true).WriteReadings(context.Background(), batch)
+		Expect(err).To(HaveOccurred())
+		Expect(count()).To(BeZero())
+	})
+
+	It("should load historical readings faster with COPY than with batch inserts", func() {
+		experiment := gmeasure.NewExperiment("bulk reading load")
+		AddReportEntry(experiment.Name, experiment)
+
+		for name, useCopy := range map[string]bool{"copy": true, "insert": false} {
+			writer := backend.NewReadingWriter(db, useCopy)
+			experiment.Sample(func(_ int) {
+				batch := readings(batchSize)
+				experiment.MeasureDuration(name, func() {
+					n, err := writer.WriteReadings(context.Background(), batch)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(n).To(Equal(int64(batchSize)))
+				})
+				Expect(db.Exec("DELETE FROM sensor_readings WHERE device_id = ?", deviceID).Error).To(Succeed())
+			}, gmeasure.SamplingConfig{N: 3})
+		}
+
+		copyMedian := experiment.GetStats("copy").DurationFor(gmeasure.StatMedian)
+		insertMedian := experiment.GetStats("insert").DurationFor(gmeasure.StatMedian)
+		AddReportEntry("speedup", fmt.Sprintf("%.1fx", float64(insertMedian)/float64(copyMedian)))
+		Expect(copyMedian).To(BeNumerically("<", insertMedian))
+	})
+})
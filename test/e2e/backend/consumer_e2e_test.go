@@ -10,7 +10,7 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 	"google.golang.org/protobuf/proto"
 
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
 )
 
 var _ = Describe("Backend Consumer E2E", func() {
@@ -21,7 +21,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 			deviceID := "device-001"
 
 			// Step 1: Create the device first (required due to foreign key constraint).
-			device := &iot.IoTDevice{
+			device := &iotv1.IoTDevice{
 				DeviceId:   deviceID,
 				Timestamp:  time.Now().Unix(),
 				Location:   "Test Location",
@@ -53,7 +53,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 
 			// Poll until device exists in database.
 			Eventually(func() error {
-				resp, err := grpcClient.GetDevice(ctx, &iot.GetDeviceByIDRequest{
+				resp, err := grpcClient.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{
 					DeviceId: deviceID,
 				})
 				if err != nil {
@@ -68,7 +68,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 			testLogger.Info("device created, now publishing sensor reading")
 
 			// Step 2: Create a sensor reading message for the device.
-			sensorReading := &iot.SensorReading{
+			sensorReading := &iotv1.SensorReading{
 				DeviceId:     deviceID,
 				Timestamp:    time.Now().Unix(),
 				Temperature:  25.5,
@@ -100,7 +100,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 
 			// Poll until sensor reading appears in database.
 			Eventually(func() int {
-				resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
+				resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
 					DeviceId: deviceID,
 				})
 				if err != nil {
@@ -110,7 +110,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 			}, 30*time.Second, 500*time.Millisecond).Should(BeNumerically(">=", 1))
 
 			// Verify the data.
-			resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
+			resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
 				DeviceId: deviceID,
 			})
 			Expect(err).NotTo(HaveOccurred())
@@ -133,7 +133,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 			numReadings := 5
 
 			// Step 1: Create the device first.
-			device := &iot.IoTDevice{
+			device := &iotv1.IoTDevice{
 				DeviceId:   deviceID,
 				Timestamp:  time.Now().Unix(),
 				Location:   "Test Location 2",
@@ -165,7 +165,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 
 			// Poll until device exists in database.
 			Eventually(func() error {
-				resp, err := grpcClient.GetDevice(ctx, &iot.GetDeviceByIDRequest{
+				resp, err := grpcClient.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{
 					DeviceId: deviceID,
 				})
 				if err != nil {
@@ -181,7 +181,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 
 			// Step 2: Publish multiple sensor readings.
 			for i := 0; i < numReadings; i++ {
-				sensorReading := &iot.SensorReading{
+				sensorReading := &iotv1.SensorReading{
 					DeviceId:     deviceID,
 					Timestamp:    time.Now().Add(time.Duration(i) * time.Second).Unix(),
 					Temperature:  20.0 + float64(i),
@@ -212,7 +212,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 
 			// Poll until all sensor readings appear in database.
 			Eventually(func() int {
-				resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
+				resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
 					DeviceId: deviceID,
 				})
 				if err != nil {
@@ -231,7 +231,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 
 			// Step 1: Create all devices first.
 			for i, deviceID := range devices {
-				device := &iot.IoTDevice{
+				device := &iotv1.IoTDevice{
 					DeviceId:   deviceID,
 					Timestamp:  time.Now().Unix(),
 					Location:   fmt.Sprintf("Location %d", i+3),
@@ -265,7 +265,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 			// Poll until all devices exist in database.
 			for _, deviceID := range devices {
 				Eventually(func() error {
-					resp, err := grpcClient.GetDevice(ctx, &iot.GetDeviceByIDRequest{
+					resp, err := grpcClient.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{
 						DeviceId: deviceID,
 					})
 					if err != nil {
@@ -282,7 +282,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 
 			// Step 2: Publish sensor readings from different devices.
 			for _, deviceID := range devices {
-				sensorReading := &iot.SensorReading{
+				sensorReading := &iotv1.SensorReading{
 					DeviceId:     deviceID,
 					Timestamp:    time.Now().Unix(),
 					Temperature:  25.0,
@@ -314,7 +314,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 			// Poll until each device has readings.
 			for _, deviceID := range devices {
 				Eventually(func() int {
-					resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
+					resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
 						DeviceId: deviceID,
 					})
 					if err != nil {
@@ -335,7 +335,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 			ctx := context.Background()
 
 			// Create a device message
-			device := &iot.IoTDevice{
+			device := &iotv1.IoTDevice{
 				DeviceId:   "device-101",
 				Timestamp:  time.Now().Unix(),
 				Location:   "Office A",
@@ -371,7 +371,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 			time.Sleep(3 * time.Second)
 
 			// Verify device was saved via gRPC API
-			resp, err := grpcClient.GetDevice(ctx, &iot.GetDeviceByIDRequest{
+			resp, err := grpcClient.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{
 				DeviceId: "device-101",
 			})
 			Expect(err).NotTo(HaveOccurred())
@@ -396,7 +396,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 			deviceID := "device-102"
 
 			// Create initial device
-			device1 := &iot.IoTDevice{
+			device1 := &iotv1.IoTDevice{
 				DeviceId:   deviceID,
 				Timestamp:  time.Now().Unix(),
 				Location:   "Office B",
@@ -428,7 +428,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 			time.Sleep(3 * time.Second)
 
 			// Create updated device (same ID, different firmware and location)
-			device2 := &iot.IoTDevice{
+			device2 := &iotv1.IoTDevice{
 				DeviceId:   deviceID,
 				Timestamp:  time.Now().Unix(),
 				Location:   "Office C",
@@ -460,7 +460,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 			time.Sleep(3 * time.Second)
 
 			// Verify device was updated
-			resp, err := grpcClient.GetDevice(ctx, &iot.GetDeviceByIDRequest{
+			resp, err := grpcClient.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{
 				DeviceId: deviceID,
 			})
 			Expect(err).NotTo(HaveOccurred())
@@ -483,7 +483,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 
 			// Publish multiple devices
 			for i := 0; i < numDevices; i++ {
-				device := &iot.IoTDevice{
+				device := &iotv1.IoTDevice{
 					DeviceId:   fmt.Sprintf("device-20%d", i),
 					Timestamp:  time.Now().Unix(),
 					Location:   fmt.Sprintf("Location %d", i),
@@ -518,7 +518,7 @@ var _ = Describe("Backend Consumer E2E", func() {
 			time.Sleep(3 * time.Second)
 
 			// Verify all devices were saved
-			resp, err := grpcClient.GetAllDevice(ctx, &iot.GetAllDevicesRequest{})
+			resp, err := grpcClient.GetAllDevice(ctx, &iotv1.GetAllDevicesRequest{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(resp.GetDevices()).NotTo(BeEmpty())
 			Expect(len(resp.GetDevices())).To(BeNumerically(">=", numDevices))
@@ -2,15 +2,13 @@ package backend
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	amqp "github.com/rabbitmq/amqp091-go"
-	"google.golang.org/protobuf/proto"
 
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/test/e2e/fixtures"
 )
 
 var _ = Describe("Backend Database Relationships E2E", func() {
@@ -18,83 +16,26 @@ var _ = Describe("Backend Database Relationships E2E", func() {
 		It("should maintain one-to-many relationship (device has many sensor readings)", func() {
 			ctx := context.Background()
 
-			deviceID := "db-device-001"
-
-			// Step 1: Create a device
-			device := &iot.IoTDevice{
-				DeviceId:   deviceID,
-				Timestamp:  time.Now().Unix(),
-				Location:   "Database Test Location",
-				MacAddress: "AA:BB:CC:DD:EE:01",
-				IpAddress:  "192.168.10.1",
-				Firmware:   "v1.0.0",
-				Latitude:   40.7128,
-				Longitude:  -74.0060,
-			}
-
-			msgBytes, err := proto.Marshal(device)
-			Expect(err).NotTo(HaveOccurred())
-
-			err = mqChannel.PublishWithContext(
-				ctx,
-				"",
-				deviceQueueName,
-				false,
-				false,
-				amqp.Publishing{
-					ContentType:  "application/protobuf",
-					Body:         msgBytes,
-					DeliveryMode: amqp.Persistent,
-				},
-			)
-			Expect(err).NotTo(HaveOccurred())
-
-			testLogger.Info("published device for relationship test", "device_id", deviceID)
-			time.Sleep(3 * time.Second)
-
-			// Step 2: Create multiple sensor readings for this device
+			// Create a device with multiple sensor readings
 			numReadings := 10
-			for i := 0; i < numReadings; i++ {
-				reading := &iot.SensorReading{
-					DeviceId:     deviceID,
-					Timestamp:    time.Now().Add(time.Duration(i) * time.Minute).Unix(),
-					Temperature:  20.0 + float64(i)*0.5,
-					Humidity:     50.0 + float64(i)*2.0,
-					Pressure:     1000.0 + float64(i)*5.0,
-					BatteryLevel: 100.0 - float64(i)*5.0,
-				}
-
-				msgBytes, err := proto.Marshal(reading)
-				Expect(err).NotTo(HaveOccurred())
+			device := fixtures.NewDevice().
+				WithLocation("Database Test Location").
+				WithReadings(numReadings).
+				Create(ctx)
+			deviceID := device.ID()
 
-				err = mqChannel.PublishWithContext(
-					ctx,
-					"",
-					sensorQueueName,
-					false,
-					false,
-					amqp.Publishing{
-						ContentType:  "application/protobuf",
-						Body:         msgBytes,
-						DeliveryMode: amqp.Persistent,
-					},
-				)
-				Expect(err).NotTo(HaveOccurred())
-			}
+			testLogger.Info("created device with sensor readings", "device_id", deviceID, "count", numReadings)
 
-			testLogger.Info("published sensor readings for device", "device_id", deviceID, "count", numReadings)
-			time.Sleep(3 * time.Second)
-
-			// Step 3: Verify device exists
-			deviceResp, err := grpcClient.GetDevice(ctx, &iot.GetDeviceByIDRequest{
+			// Verify device exists
+			deviceResp, err := grpcClient.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{
 				DeviceId: deviceID,
 			})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(deviceResp.GetDevice()).NotTo(BeNil())
 			Expect(deviceResp.GetDevice().GetDeviceId()).To(Equal(deviceID))
 
-			// Step 4: Verify all sensor readings belong to this device
-			readingsResp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
+			// Verify all sensor readings belong to this device
+			readingsResp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
 				DeviceId: deviceID,
 			})
 			Expect(err).NotTo(HaveOccurred())
@@ -112,50 +53,6 @@ var _ = Describe("Backend Database Relationships E2E", func() {
 		It("should handle sensor readings for multiple devices independently", func() {
 			ctx := context.Background()
 
-			// Create multiple devices
-			devices := []struct {
-				ID       string
-				Location string
-			}{
-				{"db-device-101", "Location A"},
-				{"db-device-102", "Location B"},
-				{"db-device-103", "Location C"},
-			}
-
-			// Create each device
-			for i, d := range devices {
-				device := &iot.IoTDevice{
-					DeviceId:   d.ID,
-					Timestamp:  time.Now().Unix(),
-					Location:   d.Location,
-					MacAddress: fmt.Sprintf("AA:BB:CC:DD:EE:%02d", 10+i),
-					IpAddress:  fmt.Sprintf("192.168.20.%d", 10+i),
-					Firmware:   "v1.0.0",
-					Latitude:   float32(40.0 + i),
-					Longitude:  float32(-74.0 + i),
-				}
-
-				msgBytes, err := proto.Marshal(device)
-				Expect(err).NotTo(HaveOccurred())
-
-				err = mqChannel.PublishWithContext(
-					ctx,
-					"",
-					deviceQueueName,
-					false,
-					false,
-					amqp.Publishing{
-						ContentType:  "application/protobuf",
-						Body:         msgBytes,
-						DeliveryMode: amqp.Persistent,
-					},
-				)
-				Expect(err).NotTo(HaveOccurred())
-			}
-
-			testLogger.Info("published multiple devices", "count", len(devices))
-			time.Sleep(3 * time.Second)
-
 			// Create different numbers of sensor readings for each device
 			readingCounts := map[string]int{
 				"db-device-101": 5,
@@ -164,42 +61,13 @@ var _ = Describe("Backend Database Relationships E2E", func() {
 			}
 
 			for deviceID, count := range readingCounts {
-				for i := 0; i < count; i++ {
-					reading := &iot.SensorReading{
-						DeviceId:     deviceID,
-						Timestamp:    time.Now().Add(time.Duration(i) * time.Minute).Unix(),
-						Temperature:  25.0,
-						Humidity:     60.0,
-						Pressure:     1015.0,
-						BatteryLevel: 90.0,
-					}
-
-					msgBytes, err := proto.Marshal(reading)
-					Expect(err).NotTo(HaveOccurred())
-
-					err = mqChannel.PublishWithContext(
-						ctx,
-						"",
-						sensorQueueName,
-						false,
-						false,
-						amqp.Publishing{
-							ContentType:  "application/protobuf",
-							Body:         msgBytes,
-							DeliveryMode: amqp.Persistent,
-						},
-					)
-					Expect(err).NotTo(HaveOccurred())
-				}
-
-				testLogger.Info("published sensor readings for device", "device_id", deviceID, "count", count)
+				fixtures.NewDevice().WithID(deviceID).WithReadings(count).Create(ctx)
+				testLogger.Info("created sensor readings for device", "device_id", deviceID, "count", count)
 			}
 
-			time.Sleep(3 * time.Second)
-
 			// Verify each device has the correct number of readings
 			for deviceID, expectedCount := range readingCounts {
-				resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
+				resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
 					DeviceId: deviceID,
 				})
 				Expect(err).NotTo(HaveOccurred())
@@ -225,37 +93,20 @@ var _ = Describe("Backend Database Relationships E2E", func() {
 			// or if the system creates a device implicitly
 			orphanDeviceID := "db-orphan-device-999"
 
-			reading := &iot.SensorReading{
+			fixtures.PublishReading(ctx, &iotv1.SensorReading{
 				DeviceId:     orphanDeviceID,
 				Timestamp:    time.Now().Unix(),
 				Temperature:  22.0,
 				Humidity:     55.0,
 				Pressure:     1012.0,
 				BatteryLevel: 88.0,
-			}
-
-			msgBytes, err := proto.Marshal(reading)
-			Expect(err).NotTo(HaveOccurred())
-
-			err = mqChannel.PublishWithContext(
-				ctx,
-				"",
-				sensorQueueName,
-				false,
-				false,
-				amqp.Publishing{
-					ContentType:  "application/protobuf",
-					Body:         msgBytes,
-					DeliveryMode: amqp.Persistent,
-				},
-			)
-			Expect(err).NotTo(HaveOccurred())
+			})
 
 			testLogger.Info("published sensor reading for orphan device", "device_id", orphanDeviceID)
 			time.Sleep(3 * time.Second)
 
 			// Try to get readings for orphan device
-			resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
+			resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
 				DeviceId: orphanDeviceID,
 			})
 
@@ -272,42 +123,17 @@ var _ = Describe("Backend Database Relationships E2E", func() {
 		It("should preserve device data integrity when sensor readings are added", func() {
 			ctx := context.Background()
 
-			deviceID := "db-device-201"
-
 			// Create device with specific attributes
-			originalDevice := &iot.IoTDevice{
-				DeviceId:   deviceID,
-				Timestamp:  time.Now().Unix(),
-				Location:   "Original Location",
-				MacAddress: "BB:BB:BB:BB:BB:01",
-				IpAddress:  "192.168.30.1",
-				Firmware:   "v1.0.0",
-				Latitude:   35.0,
-				Longitude:  -115.0,
-			}
+			builder := fixtures.NewDevice().
+				WithLocation("Original Location").
+				WithFirmware("v1.0.0")
+			device := builder.Create(ctx)
+			deviceID := device.ID()
 
-			msgBytes, err := proto.Marshal(originalDevice)
-			Expect(err).NotTo(HaveOccurred())
-
-			err = mqChannel.PublishWithContext(
-				ctx,
-				"",
-				deviceQueueName,
-				false,
-				false,
-				amqp.Publishing{
-					ContentType:  "application/protobuf",
-					Body:         msgBytes,
-					DeliveryMode: amqp.Persistent,
-				},
-			)
-			Expect(err).NotTo(HaveOccurred())
-
-			testLogger.Info("published device for integrity test", "device_id", deviceID)
-			time.Sleep(3 * time.Second)
+			testLogger.Info("created device for integrity test", "device_id", deviceID)
 
 			// Verify device initial state
-			deviceResp1, err := grpcClient.GetDevice(ctx, &iot.GetDeviceByIDRequest{
+			deviceResp1, err := grpcClient.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{
 				DeviceId: deviceID,
 			})
 			Expect(err).NotTo(HaveOccurred())
@@ -317,38 +143,13 @@ var _ = Describe("Backend Database Relationships E2E", func() {
 			originalFirmware := deviceResp1.GetDevice().GetFirmware()
 
 			// Add sensor readings
-			for i := 0; i < 5; i++ {
-				reading := &iot.SensorReading{
-					DeviceId:     deviceID,
-					Timestamp:    time.Now().Add(time.Duration(i) * time.Second).Unix(),
-					Temperature:  20.0,
-					Humidity:     50.0,
-					Pressure:     1000.0,
-					BatteryLevel: 85.0,
-				}
-
-				msgBytes, err := proto.Marshal(reading)
-				Expect(err).NotTo(HaveOccurred())
-
-				err = mqChannel.PublishWithContext(
-					ctx,
-					"",
-					sensorQueueName,
-					false,
-					false,
-					amqp.Publishing{
-						ContentType:  "application/protobuf",
-						Body:         msgBytes,
-						DeliveryMode: amqp.Persistent,
-					},
-				)
-				Expect(err).NotTo(HaveOccurred())
+			for _, reading := range builder.WithReadings(5).Build().Readings {
+				fixtures.PublishReading(ctx, reading)
 			}
-
-			time.Sleep(3 * time.Second)
+			fixtures.WaitForReadings(ctx, deviceID, 5)
 
 			// Verify device attributes haven't changed after adding sensor readings
-			deviceResp2, err := grpcClient.GetDevice(ctx, &iot.GetDeviceByIDRequest{
+			deviceResp2, err := grpcClient.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{
 				DeviceId: deviceID,
 			})
 			Expect(err).NotTo(HaveOccurred())
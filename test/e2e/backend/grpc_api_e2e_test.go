@@ -7,10 +7,9 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	amqp "github.com/rabbitmq/amqp091-go"
-	"google.golang.org/protobuf/proto"
 
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	"procodus.dev/demo-app/test/e2e/fixtures"
 )
 
 var _ = Describe("Backend gRPC API E2E", func() {
@@ -18,46 +17,20 @@ var _ = Describe("Backend gRPC API E2E", func() {
 		It("should return all devices", func() {
 			ctx := context.Background()
 
-			// First, publish some devices
+			// First, create some devices
 			deviceIDs := []string{"api-device-001", "api-device-002", "api-device-003"}
 
 			for i, deviceID := range deviceIDs {
-				device := &iot.IoTDevice{
-					DeviceId:   deviceID,
-					Timestamp:  time.Now().Unix(),
-					Location:   fmt.Sprintf("Location %d", i),
-					MacAddress: fmt.Sprintf("AA:BB:CC:DD:EE:%02d", i),
-					IpAddress:  fmt.Sprintf("10.0.0.%d", i+1),
-					Firmware:   "v1.0.0",
-					Latitude:   float32(40.0 + i),
-					Longitude:  float32(-120.0 + i),
-				}
-
-				msgBytes, err := proto.Marshal(device)
-				Expect(err).NotTo(HaveOccurred())
-
-				err = mqChannel.PublishWithContext(
-					ctx,
-					"",
-					deviceQueueName,
-					false,
-					false,
-					amqp.Publishing{
-						ContentType:  "application/protobuf",
-						Body:         msgBytes,
-						DeliveryMode: amqp.Persistent,
-					},
-				)
-				Expect(err).NotTo(HaveOccurred())
+				fixtures.NewDevice().
+					WithID(deviceID).
+					WithLocation(fmt.Sprintf("Location %d", i)).
+					Create(ctx)
 			}
 
-			testLogger.Info("published test devices for GetAllDevice", "count", len(deviceIDs))
-
-			// Wait for devices to be processed
-			time.Sleep(3 * time.Second)
+			testLogger.Info("created test devices for GetAllDevice", "count", len(deviceIDs))
 
 			// Call GetAllDevice
-			resp, err := grpcClient.GetAllDevice(ctx, &iot.GetAllDevicesRequest{})
+			resp, err := grpcClient.GetAllDevice(ctx, &iotv1.GetAllDevicesRequest{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(resp).NotTo(BeNil())
 			Expect(resp.GetDevices()).NotTo(BeEmpty())
@@ -66,7 +39,7 @@ var _ = Describe("Backend gRPC API E2E", func() {
 			Expect(len(resp.GetDevices())).To(BeNumerically(">=", len(deviceIDs)))
 
 			// Verify our devices are in the response
-			deviceMap := make(map[string]*iot.IoTDevice)
+			deviceMap := make(map[string]*iotv1.IoTDevice)
 			for _, device := range resp.GetDevices() {
 				deviceMap[device.GetDeviceId()] = device
 			}
@@ -85,7 +58,7 @@ var _ = Describe("Backend gRPC API E2E", func() {
 			// In a real scenario, we'd reset the database or use isolated test data
 			ctx := context.Background()
 
-			resp, err := grpcClient.GetAllDevice(ctx, &iot.GetAllDevicesRequest{})
+			resp, err := grpcClient.GetAllDevice(ctx, &iotv1.GetAllDevicesRequest{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(resp).NotTo(BeNil())
 			// Devices list may not be empty due to previous tests
@@ -98,40 +71,18 @@ var _ = Describe("Backend gRPC API E2E", func() {
 
 			deviceID := "api-device-101"
 
-			// Publish a device
-			device := &iot.IoTDevice{
-				DeviceId:   deviceID,
-				Timestamp:  time.Now().Unix(),
-				Location:   "Test Location",
-				MacAddress: "11:22:33:44:55:66",
-				IpAddress:  "192.168.100.1",
-				Firmware:   "v2.0.0",
-				Latitude:   45.5,
-				Longitude:  -122.6,
-			}
-
-			msgBytes, err := proto.Marshal(device)
-			Expect(err).NotTo(HaveOccurred())
+			fixtures.NewDevice().
+				WithID(deviceID).
+				WithLocation("Test Location").
+				WithNetwork("11:22:33:44:55:66", "192.168.100.1").
+				WithFirmware("v2.0.0").
+				WithCoordinates(45.5, -122.6).
+				Create(ctx)
 
-			err = mqChannel.PublishWithContext(
-				ctx,
-				"",
-				deviceQueueName,
-				false,
-				false,
-				amqp.Publishing{
-					ContentType:  "application/protobuf",
-					Body:         msgBytes,
-					DeliveryMode: amqp.Persistent,
-				},
-			)
-			Expect(err).NotTo(HaveOccurred())
-
-			testLogger.Info("published device for GetDevice test", "device_id", deviceID)
-			time.Sleep(3 * time.Second)
+			testLogger.Info("created device for GetDevice test", "device_id", deviceID)
 
 			// Call GetDevice
-			resp, err := grpcClient.GetDevice(ctx, &iot.GetDeviceByIDRequest{
+			resp, err := grpcClient.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{
 				DeviceId: deviceID,
 			})
 			Expect(err).NotTo(HaveOccurred())
@@ -155,7 +106,7 @@ var _ = Describe("Backend gRPC API E2E", func() {
 			ctx := context.Background()
 
 			// Call GetDevice with non-existent ID
-			resp, err := grpcClient.GetDevice(ctx, &iot.GetDeviceByIDRequest{
+			resp, err := grpcClient.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{
 				DeviceId: "non-existent-device-999",
 			})
 
@@ -172,97 +123,14 @@ var _ = Describe("Backend gRPC API E2E", func() {
 		It("should return sensor readings for a specific device", func() {
 			ctx := context.Background()
 
-			deviceID := "api-device-201"
-
-			// First, create the device.
-			device := &iot.IoTDevice{
-				DeviceId:   deviceID,
-				Timestamp:  time.Now().Unix(),
-				Location:   "Sensor Test Location",
-				MacAddress: "AA:BB:CC:DD:EE:FF",
-				IpAddress:  "192.168.200.1",
-				Firmware:   "v1.0.0",
-				Latitude:   50.0,
-				Longitude:  -100.0,
-			}
-
-			msgBytes, err := proto.Marshal(device)
-			Expect(err).NotTo(HaveOccurred())
-
-			err = mqChannel.PublishWithContext(
-				ctx,
-				"",
-				deviceQueueName,
-				false,
-				false,
-				amqp.Publishing{
-					ContentType:  "application/protobuf",
-					Body:         msgBytes,
-					DeliveryMode: amqp.Persistent,
-				},
-			)
-			Expect(err).NotTo(HaveOccurred())
-
-			// Poll until device exists in database.
-			Eventually(func() error {
-				resp, err := grpcClient.GetDevice(ctx, &iot.GetDeviceByIDRequest{
-					DeviceId: deviceID,
-				})
-				if err != nil {
-					return err
-				}
-				if resp.GetDevice() == nil {
-					return fmt.Errorf("device not yet created")
-				}
-				return nil
-			}, 10*time.Second, 500*time.Millisecond).Should(Succeed())
-
-			// Publish multiple sensor readings for this device.
 			numReadings := 3
-			for i := 0; i < numReadings; i++ {
-				reading := &iot.SensorReading{
-					DeviceId:     deviceID,
-					Timestamp:    time.Now().Add(time.Duration(i) * time.Second).Unix(),
-					Temperature:  22.0 + float64(i),
-					Humidity:     55.0 + float64(i),
-					Pressure:     1010.0 + float64(i),
-					BatteryLevel: 90.0 - float64(i),
-				}
-
-				msgBytes, err := proto.Marshal(reading)
-				Expect(err).NotTo(HaveOccurred())
-
-				err = mqChannel.PublishWithContext(
-					ctx,
-					"",
-					sensorQueueName,
-					false,
-					false,
-					amqp.Publishing{
-						ContentType:  "application/protobuf",
-						Body:         msgBytes,
-						DeliveryMode: amqp.Persistent,
-					},
-				)
-				Expect(err).NotTo(HaveOccurred())
-			}
+			device := fixtures.NewDevice().WithReadings(numReadings).Create(ctx)
 
-			testLogger.Info("published sensor readings for device", "device_id", deviceID, "count", numReadings)
-
-			// Poll until sensor readings appear in database.
-			Eventually(func() int {
-				resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
-					DeviceId: deviceID,
-				})
-				if err != nil {
-					return 0
-				}
-				return len(resp.GetReading())
-			}, 30*time.Second, 500*time.Millisecond).Should(BeNumerically(">=", numReadings))
+			testLogger.Info("created sensor readings for device", "device_id", device.ID(), "count", numReadings)
 
 			// Call GetSensorReadingByDeviceID to verify data.
-			resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
-				DeviceId: deviceID,
+			resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
+				DeviceId: device.ID(),
 			})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(resp).NotTo(BeNil())
@@ -273,7 +141,7 @@ var _ = Describe("Backend gRPC API E2E", func() {
 
 			// Verify all readings belong to the correct device.
 			for _, reading := range resp.GetReading() {
-				Expect(reading.GetDeviceId()).To(Equal(deviceID))
+				Expect(reading.GetDeviceId()).To(Equal(device.ID()))
 			}
 
 			testLogger.Info("GetSensorReadingByDeviceID returned correct readings")
@@ -282,103 +150,17 @@ var _ = Describe("Backend gRPC API E2E", func() {
 		It("should return readings in descending order by timestamp", func() {
 			ctx := context.Background()
 
-			deviceID := "api-device-202"
-
-			// Create device.
-			device := &iot.IoTDevice{
-				DeviceId:   deviceID,
-				Timestamp:  time.Now().Unix(),
-				Location:   "Order Test Location",
-				MacAddress: "11:11:11:11:11:11",
-				IpAddress:  "192.168.202.1",
-				Firmware:   "v1.0.0",
-				Latitude:   51.0,
-				Longitude:  -101.0,
-			}
-
-			msgBytes, err := proto.Marshal(device)
-			Expect(err).NotTo(HaveOccurred())
-
-			err = mqChannel.PublishWithContext(
-				ctx,
-				"",
-				deviceQueueName,
-				false,
-				false,
-				amqp.Publishing{
-					ContentType:  "application/protobuf",
-					Body:         msgBytes,
-					DeliveryMode: amqp.Persistent,
-				},
-			)
-			Expect(err).NotTo(HaveOccurred())
+			// Create readings with known timestamps.
+			now := time.Now()
+			device := fixtures.NewDevice().
+				WithReadingsAt(now.Add(-3*time.Hour), now.Add(-2*time.Hour), now.Add(-1*time.Hour), now).
+				Create(ctx)
 
-			// Poll until device exists in database.
-			Eventually(func() error {
-				resp, err := grpcClient.GetDevice(ctx, &iot.GetDeviceByIDRequest{
-					DeviceId: deviceID,
-				})
-				if err != nil {
-					return err
-				}
-				if resp.GetDevice() == nil {
-					return fmt.Errorf("device not yet created")
-				}
-				return nil
-			}, 10*time.Second, 500*time.Millisecond).Should(Succeed())
-
-			// Publish readings with known timestamps.
-			timestamps := []int64{
-				time.Now().Add(-3 * time.Hour).Unix(),
-				time.Now().Add(-2 * time.Hour).Unix(),
-				time.Now().Add(-1 * time.Hour).Unix(),
-				time.Now().Unix(),
-			}
-
-			for _, ts := range timestamps {
-				reading := &iot.SensorReading{
-					DeviceId:     deviceID,
-					Timestamp:    ts,
-					Temperature:  20.0,
-					Humidity:     50.0,
-					Pressure:     1000.0,
-					BatteryLevel: 85.0,
-				}
-
-				msgBytes, err := proto.Marshal(reading)
-				Expect(err).NotTo(HaveOccurred())
-
-				err = mqChannel.PublishWithContext(
-					ctx,
-					"",
-					sensorQueueName,
-					false,
-					false,
-					amqp.Publishing{
-						ContentType:  "application/protobuf",
-						Body:         msgBytes,
-						DeliveryMode: amqp.Persistent,
-					},
-				)
-				Expect(err).NotTo(HaveOccurred())
-			}
-
-			testLogger.Info("published ordered sensor readings", "device_id", deviceID)
-
-			// Poll until all sensor readings appear in database.
-			Eventually(func() int {
-				resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
-					DeviceId: deviceID,
-				})
-				if err != nil {
-					return 0
-				}
-				return len(resp.GetReading())
-			}, 30*time.Second, 500*time.Millisecond).Should(BeNumerically(">=", len(timestamps)))
+			testLogger.Info("created ordered sensor readings", "device_id", device.ID())
 
 			// Get readings to verify order.
-			resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
-				DeviceId: deviceID,
+			resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
+				DeviceId: device.ID(),
 			})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(resp).NotTo(BeNil())
@@ -396,96 +178,31 @@ var _ = Describe("Backend gRPC API E2E", func() {
 		It("should support pagination with page tokens", func() {
 			ctx := context.Background()
 
-			deviceID := "api-device-203"
-
-			// Create device.
-			device := &iot.IoTDevice{
-				DeviceId:   deviceID,
-				Timestamp:  time.Now().Unix(),
-				Location:   "Pagination Test",
-				MacAddress: "22:22:22:22:22:22",
-				IpAddress:  "192.168.203.1",
-				Firmware:   "v1.0.0",
-				Latitude:   52.0,
-				Longitude:  -102.0,
-			}
-
-			msgBytes, err := proto.Marshal(device)
-			Expect(err).NotTo(HaveOccurred())
-
-			err = mqChannel.PublishWithContext(
-				ctx,
-				"",
-				deviceQueueName,
-				false,
-				false,
-				amqp.Publishing{
-					ContentType:  "application/protobuf",
-					Body:         msgBytes,
-					DeliveryMode: amqp.Persistent,
-				},
-			)
-			Expect(err).NotTo(HaveOccurred())
-
-			// Poll until device exists in database.
-			Eventually(func() error {
-				resp, err := grpcClient.GetDevice(ctx, &iot.GetDeviceByIDRequest{
-					DeviceId: deviceID,
-				})
-				if err != nil {
-					return err
-				}
-				if resp.GetDevice() == nil {
-					return fmt.Errorf("device not yet created")
-				}
-				return nil
-			}, 10*time.Second, 500*time.Millisecond).Should(Succeed())
-
-			// Publish many readings to test pagination.
+			// Create many readings to test pagination.
 			numReadings := 15
-			for i := 0; i < numReadings; i++ {
-				reading := &iot.SensorReading{
-					DeviceId:     deviceID,
-					Timestamp:    time.Now().Add(time.Duration(i) * time.Second).Unix(),
-					Temperature:  20.0,
-					Humidity:     50.0,
-					Pressure:     1000.0,
-					BatteryLevel: 85.0,
-				}
-
-				msgBytes, err := proto.Marshal(reading)
-				Expect(err).NotTo(HaveOccurred())
+			device := fixtures.NewDevice().WithReadings(numReadings).Create(ctx)
+			deviceID := device.ID()
 
-				err = mqChannel.PublishWithContext(
-					ctx,
-					"",
-					sensorQueueName,
-					false,
-					false,
-					amqp.Publishing{
-						ContentType:  "application/protobuf",
-						Body:         msgBytes,
-						DeliveryMode: amqp.Persistent,
-					},
-				)
-				Expect(err).NotTo(HaveOccurred())
-			}
+			testLogger.Info("created readings for pagination test", "count", numReadings)
 
-			testLogger.Info("published readings for pagination test", "count", numReadings)
+			// Total count matches what was published.
+			countResp, err := grpcClient.CountReadings(ctx, &iotv1.CountReadingsRequest{
+				DeviceId: deviceID,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(countResp.GetCount()).To(BeNumerically(">=", numReadings))
 
-			// Poll until sensor readings appear in database.
-			Eventually(func() int {
-				resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
-					DeviceId: deviceID,
-				})
-				if err != nil {
-					return 0
-				}
-				return len(resp.GetReading())
-			}, 30*time.Second, 500*time.Millisecond).Should(BeNumerically(">=", numReadings))
+			// Explicit page size is honored.
+			smallPage, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
+				DeviceId: deviceID,
+				PageSize: 5,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(smallPage.GetReading()).To(HaveLen(5))
+			Expect(smallPage.GetNextPageToken()).To(Equal("5"))
 
 			// Get first page.
-			resp1, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
+			resp1, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
 				DeviceId: deviceID,
 			})
 			Expect(err).NotTo(HaveOccurred())
@@ -496,7 +213,7 @@ var _ = Describe("Backend gRPC API E2E", func() {
 
 			// If there's a next page token, fetch next page.
 			if resp1.GetNextPageToken() != "" {
-				resp2, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
+				resp2, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
 					DeviceId:  deviceID,
 					PageToken: resp1.GetNextPageToken(),
 				})
@@ -511,41 +228,12 @@ var _ = Describe("Backend gRPC API E2E", func() {
 		It("should return empty list for device with no readings", func() {
 			ctx := context.Background()
 
-			deviceID := "api-device-204"
-
 			// Create device but don't publish any readings
-			device := &iot.IoTDevice{
-				DeviceId:   deviceID,
-				Timestamp:  time.Now().Unix(),
-				Location:   "No Readings Test",
-				MacAddress: "33:33:33:33:33:33",
-				IpAddress:  "192.168.204.1",
-				Firmware:   "v1.0.0",
-				Latitude:   53.0,
-				Longitude:  -103.0,
-			}
-
-			msgBytes, err := proto.Marshal(device)
-			Expect(err).NotTo(HaveOccurred())
-
-			err = mqChannel.PublishWithContext(
-				ctx,
-				"",
-				deviceQueueName,
-				false,
-				false,
-				amqp.Publishing{
-					ContentType:  "application/protobuf",
-					Body:         msgBytes,
-					DeliveryMode: amqp.Persistent,
-				},
-			)
-			Expect(err).NotTo(HaveOccurred())
-			time.Sleep(3 * time.Second)
+			device := fixtures.NewDevice().Create(ctx)
 
 			// Get readings for device with no sensor data
-			resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iot.GetSensorReadingByDeviceIDRequest{
-				DeviceId: deviceID,
+			resp, err := grpcClient.GetSensorReadingByDeviceID(ctx, &iotv1.GetSensorReadingByDeviceIDRequest{
+				DeviceId: device.ID(),
 			})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(resp).NotTo(BeNil())
@@ -554,4 +242,41 @@ var _ = Describe("Backend gRPC API E2E", func() {
 			testLogger.Info("correctly returned empty readings for device with no data")
 		})
 	})
+
+	Context("Soft delete", func() {
+		It("should hide deleted devices until they are restored", func() {
+			ctx := context.Background()
+
+			device := fixtures.NewDevice().Create(ctx)
+			deviceID := device.ID()
+
+			_, err := grpcClient.DeleteDevice(ctx, &iotv1.DeleteDeviceRequest{DeviceId: deviceID})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = grpcClient.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{DeviceId: deviceID})
+			Expect(iotv1.ErrorReason(err)).To(Equal(iotv1.ReasonDeviceNotFound))
+
+			// A re-announced device stays in the trash instead of failing the upsert
+			device.Firmware = "v1.1.0"
+			fixtures.PublishDevice(ctx, device.IoTDevice)
+
+			Eventually(func() ([]*iotv1.IoTDevice, error) {
+				deleted, err := grpcClient.ListDeletedDevices(ctx, &iotv1.ListDeletedDevicesRequest{})
+				return deleted.GetDevices(), err
+			}, 10*time.Second, 500*time.Millisecond).Should(ContainElement(And(
+				HaveField("DeviceId", deviceID),
+				HaveField("Firmware", "v1.1.0"),
+			)))
+
+			restored, err := grpcClient.RestoreDevice(ctx, &iotv1.RestoreDeviceRequest{DeviceId: deviceID})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(restored.GetDevice().GetDeletedAt()).To(BeZero())
+
+			resp, err := grpcClient.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{DeviceId: deviceID})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.GetDevice().GetFirmware()).To(Equal("v1.1.0"))
+
+			testLogger.Info("soft delete and restore round-trip succeeded", "device_id", deviceID)
+		})
+	})
 })
@@ -0,0 +1,63 @@
+package backend
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"procodus.dev/demo-app/internal/backend"
+	e2econtainers "procodus.dev/demo-app/test/e2e/testcontainers"
+)
+
+var _ = Describe("Backend Index Management E2E", func() {
+	var db *gorm.DB
+
+	BeforeEach(func() {
+		ctx := context.Background()
+
+		host, port, user, password, dbname, err := e2econtainers.GetPostgresConnectionInfo(ctx, postgresContainer, &e2econtainers.PostgresConfig{
+			User:     "testuser",
+			Password: "testpass",
+			Database: "testdb",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		db, err = backend.NewDB(&backend.DBConfig{
+			Host:     host,
+			Port:     port,
+			User:     user,
+			Password: password,
+			DBName:   dbname,
+			SSLMode:  "disable",
+			Logger:   testLogger,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		DeferCleanup(func() {
+			Expect(backend.CloseDB(db, testLogger)).To(Succeed())
+		})
+	})
+
+	It("should lead the unique reading index with the device on every partition", func() {
+		var definitions []string
+		Expect(db.Raw(`SELECT indexdef FROM pg_indexes
+			WHERE tablename LIKE 'sensor_readings%' AND indexdef LIKE 'CREATE UNIQUE INDEX % (device_id, "timestamp")'`).
+			Scan(&definitions).Error).To(Succeed())
+
+		var partitions int64
+		Expect(db.Raw(`SELECT count(*) FROM pg_inherits i JOIN pg_class p ON p.oid = i.inhparent
+			WHERE p.relname = 'sensor_readings'`).Scan(&partitions).Error).To(Succeed())
+
+		// One index on the partitioned table and one on each partition
+		Expect(definitions).To(HaveLen(int(partitions) + 1))
+	})
+
+	It("should report table statistics without pg_stat_statements", func() {
+		report, err := backend.AnalyzeIndexes(context.Background(), db, backend.AnalyzeOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.StatementsAvailable).To(BeFalse())
+		Expect(report.Candidates).To(BeEmpty())
+	})
+})
@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"procodus.dev/demo-app/internal/backend"
+	e2econtainers "procodus.dev/demo-app/test/e2e/testcontainers"
+)
+
+var _ = Describe("Backend Leader Election E2E", func() {
+	// openDB opens a separate connection pool, like another replica would.
+	openDB := func() *gorm.DB {
+		host, port, user, password, dbname, err := e2econtainers.GetPostgresConnectionInfo(context.Background(), postgresContainer, &e2econtainers.PostgresConfig{
+			User:     "testuser",
+			Password: "testpass",
+			Database: "testdb",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		db, err := backend.NewDB(&backend.DBConfig{
+			Host:     host,
+			Port:     port,
+			User:     user,
+			Password: password,
+			DBName:   dbname,
+			SSLMode:  "disable",
+			Logger:   testLogger,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		DeferCleanup(func() {
+			Expect(backend.CloseDB(db, testLogger)).To(Succeed())
+		})
+		return db
+	}
+
+	newElector := func(elected chan<- string, name string) *backend.LeaderElector {
+		elector, err := backend.NewLeaderElector(&backend.LeaderElectorConfig{
+			Logger:   testLogger,
+			DB:       openDB(),
+			Interval: 100 * time.Millisecond,
+			// Differs from the key of the backend server under test
+			LockKey: 4136,
+			OnElected: func(context.Context) error {
+				elected <- name
+				return nil
+			},
+			OnDemoted: func() {},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return elector
+	}
+
+	It("should elect one leader and fail over when it stops", func() {
+		elected := make(chan string, 4)
+
+		first := newElector(elected, "first")
+		first.Start(context.Background())
+		DeferCleanup(first.Stop)
+		Eventually(elected, 5*time.Second).Should(Receive(Equal("first")))
+		Eventually(first.IsLeader).Should(BeTrue())
+
+		second := newElector(elected, "second")
+		second.Start(context.Background())
+		DeferCleanup(second.Stop)
+		Consistently(elected, time.Second).ShouldNot(Receive())
+		Expect(second.IsLeader()).To(BeFalse())
+
+		first.Stop()
+		Expect(first.IsLeader()).To(BeFalse())
+		Eventually(elected, 5*time.Second).Should(Receive(Equal("second")))
+		Eventually(second.IsLeader).Should(BeTrue())
+	})
+})
@@ -0,0 +1,137 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"procodus.dev/demo-app/internal/backend"
+	e2econtainers "procodus.dev/demo-app/test/e2e/testcontainers"
+)
+
+var _ = Describe("Backend Reading Partitions E2E", func() {
+	var db *gorm.DB
+
+	BeforeEach(func() {
+		ctx := context.Background()
+
+		host, port, user, password, dbname, err := e2econtainers.GetPostgresConnectionInfo(ctx, postgresContainer, &e2econtainers.PostgresConfig{
+			User:     "testuser",
+			Password: "testpass",
+			Database: "testdb",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Opening the database again also checks that migrations are idempotent
+		db, err = backend.NewDB(&backend.DBConfig{
+			Host:     host,
+			Port:     port,
+			User:     user,
+			Password: password,
+			DBName:   dbname,
+			SSLMode:  "disable",
+			Logger:   testLogger,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		DeferCleanup(func() {
+			Expect(backend.CloseDB(db, testLogger)).To(Succeed())
+		})
+	})
+
+	partitions := func() []string {
+		var names []string
+		Expect(db.Raw(`
+			SELECT c.relname FROM pg_inherits i
+			JOIN pg_class c ON c.oid = i.inhrelid
+			JOIN pg_class p ON p.oid = i.inhparent
+			WHERE p.relname = 'sensor_readings'`).Scan(&names).Error).To(Succeed())
+		return names
+	}
+
+	It("should create sensor_readings partitioned by month", func() {
+		var relkind string
+		Expect(db.Raw(`SELECT relkind FROM pg_class WHERE relname = 'sensor_readings'`).Scan(&relkind).Error).To(Succeed())
+		Expect(relkind).To(Equal("p"))
+
+		now := time.Now().UTC()
+		Expect(partitions()).To(ContainElements(
+			"sensor_readings_default",
+			now.Format("sensor_readings_y2006m01"),
+			now.AddDate(0, 1, 0).Format("sensor_readings_y2006m01"),
+		))
+	})
+
+	It("should drop expired partitions and keep current ones", func() {
+		Expect(db.Exec(`CREATE TABLE sensor_readings_y2020m01 PARTITION OF sensor_readings
+			FOR VALUES FROM ('2020-01-01T00:00:00Z') TO ('2020-02-01T00:00:00Z')`).Error).To(Succeed())
+		Expect(db.Create(&backend.IoTDevice{DeviceID: "partition-e2e-device", LastSeen: time.Now()}).Error).To(Succeed())
+
+		// One reading in the old monthly partition, one in the default partition
+		for _, ts := range []time.Time{
+			time.Date(2020, 1, 15, 12, 0, 0, 0, time.UTC),
+			time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+		} {
+			Expect(db.Create(&backend.SensorReading{
+				DeviceID:     "partition-e2e-device",
+				Timestamp:    ts,
+				Temperature:  20,
+				Humidity:     50,
+				Pressure:     1013,
+				BatteryLevel: 90,
+			}).Error).To(Succeed())
+		}
+
+		job, err := backend.NewRetentionJob(&backend.RetentionJobConfig{
+			Logger:    testLogger,
+			DB:        db,
+			Retention: 365 * 24 * time.Hour,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.RunOnce(context.Background())).To(Succeed())
+
+		Expect(partitions()).NotTo(ContainElement("sensor_readings_y2020m01"))
+		Expect(partitions()).To(ContainElement(time.Now().UTC().Format("sensor_readings_y2006m01")))
+
+		var count int64
+		Expect(db.Raw(`SELECT COUNT(*) FROM sensor_readings WHERE device_id = ?`, "partition-e2e-device").Scan(&count).Error).To(Succeed())
+		Expect(count).To(BeZero(), fmt.Sprintf("expected expired readings to be removed, found %d", count))
+	})
+
+	It("should move far-future readings out of the default partition", func() {
+		// A reading for the last month kept ready, stored before its partition exists
+		now := time.Now().UTC()
+		month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 3, 0)
+		partition := month.Format("sensor_readings_y2006m01")
+		Expect(db.Exec(`DROP TABLE IF EXISTS ` + partition).Error).To(Succeed())
+
+		Expect(db.Create(&backend.IoTDevice{DeviceID: "future-e2e-device", LastSeen: now}).Error).To(Succeed())
+		Expect(db.Create(&backend.SensorReading{
+			DeviceID:     "future-e2e-device",
+			Timestamp:    month.Add(36 * time.Hour),
+			Temperature:  20,
+			Humidity:     50,
+			Pressure:     1013,
+			BatteryLevel: 90,
+		}).Error).To(Succeed())
+
+		storedIn := func() string {
+			var table string
+			Expect(db.Raw(`SELECT tableoid::regclass::text FROM sensor_readings WHERE device_id = ?`, "future-e2e-device").
+				Scan(&table).Error).To(Succeed())
+			return table
+		}
+		Expect(storedIn()).To(Equal("sensor_readings_default"))
+
+		job, err := backend.NewRetentionJob(&backend.RetentionJobConfig{Logger: testLogger, DB: db})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.RunOnce(context.Background())).To(Succeed())
+
+		Expect(partitions()).To(ContainElements("sensor_readings_default", partition))
+		Expect(storedIn()).To(Equal(partition))
+	})
+})
@@ -0,0 +1,109 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"procodus.dev/demo-app/internal/backend"
+	e2econtainers "procodus.dev/demo-app/test/e2e/testcontainers"
+)
+
+var _ = Describe("Backend Reading Rollups E2E", func() {
+	const deviceID = "rollup-e2e-device"
+
+	var (
+		db  *gorm.DB
+		job *backend.RollupJob
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		ctx := context.Background()
+
+		host, port, user, password, dbname, err := e2econtainers.GetPostgresConnectionInfo(ctx, postgresContainer, &e2econtainers.PostgresConfig{
+			User:     "testuser",
+			Password: "testpass",
+			Database: "testdb",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		db, err = backend.NewDB(&backend.DBConfig{
+			Host:     host,
+			Port:     port,
+			User:     user,
+			Password: password,
+			DBName:   dbname,
+			SSLMode:  "disable",
+			Logger:   testLogger,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		DeferCleanup(func() {
+			Expect(db.Exec(`DELETE FROM sensor_reading_rollups_hourly WHERE device_id = ?`, deviceID).Error).To(Succeed())
+			Expect(db.Exec(`DELETE FROM sensor_reading_rollups_daily WHERE device_id = ?`, deviceID).Error).To(Succeed())
+			Expect(db.Exec(`DELETE FROM sensor_readings WHERE device_id = ?`, deviceID).Error).To(Succeed())
+			Expect(db.Unscoped().Where("device_id = ?", deviceID).Delete(&backend.IoTDevice{}).Error).To(Succeed())
+			Expect(backend.CloseDB(db, testLogger)).To(Succeed())
+		})
+
+		now = time.Now().UTC()
+		Expect(db.Create(&backend.IoTDevice{DeviceID: deviceID, LastSeen: now}).Error).To(Succeed())
+
+		job, err = backend.NewRollupJob(&backend.RollupJobConfig{Logger: testLogger, DB: db})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.RunOnce(ctx)).To(Succeed())
+	})
+
+	store := func(ts time.Time) {
+		Expect(db.Create(&backend.SensorReading{
+			DeviceID:     deviceID,
+			Timestamp:    ts,
+			Temperature:  20,
+			Humidity:     50,
+			Pressure:     1013,
+			BatteryLevel: 90,
+		}).Error).To(Succeed())
+	}
+
+	buckets := func(table string) []time.Time {
+		var starts []time.Time
+		Expect(db.Table(table).Where("device_id = ?", deviceID).Order("bucket_start").
+			Pluck("bucket_start", &starts).Error).To(Succeed())
+		return starts
+	}
+
+	It("should keep rolling up current readings after a future-dated one", func() {
+		future := now.Add(48 * time.Hour)
+		store(future)
+		Expect(job.RunOnce(context.Background())).To(Succeed())
+
+		store(now)
+		Expect(job.RunOnce(context.Background())).To(Succeed())
+
+		Expect(buckets("sensor_reading_rollups_hourly")).To(ConsistOf(
+			BeTemporally("==", now.Truncate(time.Hour)),
+			BeTemporally("==", future.Truncate(time.Hour)),
+		))
+	})
+
+	It("should roll up readings stored long after their timestamp", func() {
+		store(now)
+		Expect(job.RunOnce(context.Background())).To(Succeed())
+
+		late := now.Add(-72 * time.Hour)
+		store(late)
+		Expect(job.RunOnce(context.Background())).To(Succeed())
+
+		Expect(buckets("sensor_reading_rollups_hourly")).To(ConsistOf(
+			BeTemporally("==", late.Truncate(time.Hour)),
+			BeTemporally("==", now.Truncate(time.Hour)),
+		))
+		Expect(buckets("sensor_reading_rollups_daily")).To(ContainElement(
+			BeTemporally("==", late.Truncate(24*time.Hour)),
+		))
+	})
+})
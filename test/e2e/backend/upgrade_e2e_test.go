@@ -0,0 +1,190 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"procodus.dev/demo-app/internal/backend"
+	e2econtainers "procodus.dev/demo-app/test/e2e/testcontainers"
+)
+
+// baselineReading and baselineDevice mirror the models of the first release,
+// before readings were partitioned and unique per device and timestamp.
+type baselineReading struct {
+	Timestamp    time.Time `gorm:"index:idx_device_timestamp;index:idx_timestamp;not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+	DeviceID     string    `gorm:"index:idx_device_timestamp;not null"`
+	Temperature  float64   `gorm:"not null"`
+	Humidity     float64   `gorm:"not null"`
+	Pressure     float64   `gorm:"not null"`
+	BatteryLevel float64   `gorm:"not null"`
+	ID           uint      `gorm:"primaryKey"`
+}
+
+func (baselineReading) TableName() string {
+	return "sensor_readings"
+}
+
+type baselineDevice struct {
+	SensorReadings []baselineReading `gorm:"foreignKey:DeviceID;references:DeviceID"`
+	LastSeen       time.Time         `gorm:"index:idx_last_seen"`
+	CreatedAt      time.Time         `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time         `gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt    `gorm:"index"`
+	DeviceID       string            `gorm:"uniqueIndex;not null"`
+	Location       string            `gorm:"not null"`
+	MACAddress     string            `gorm:"not null"`
+	IPAddress      string            `gorm:"not null"`
+	Firmware       string            `gorm:"not null"`
+	ID             uint              `gorm:"primaryKey"`
+	Latitude       float32           `gorm:"not null"`
+	Longitude      float32           `gorm:"not null"`
+}
+
+func (baselineDevice) TableName() string {
+	return "iot_devices"
+}
+
+var _ = Describe("Backend Schema Upgrade E2E", func() {
+	var (
+		ctx      context.Context
+		cfg      *backend.DBConfig
+		baseline *gorm.DB
+	)
+
+	// Every spec upgrades its own database, created with the schema of the
+	// first release
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		admin, err := gorm.Open(postgres.Open(postgresDSN), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+		adminDB, err := admin.DB()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(adminDB.Close)
+
+		name := fmt.Sprintf("upgrade_%d", time.Now().UnixNano())
+		Expect(admin.Exec("CREATE DATABASE " + name).Error).To(Succeed())
+		DeferCleanup(func() {
+			Expect(admin.Exec("DROP DATABASE IF EXISTS " + name + " WITH (FORCE)").Error).To(Succeed())
+		})
+
+		host, port, user, password, _, err := e2econtainers.GetPostgresConnectionInfo(ctx, postgresContainer, &e2econtainers.PostgresConfig{
+			User:     "testuser",
+			Password: "testpass",
+			Database: "testdb",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		cfg = &backend.DBConfig{
+			Host:     host,
+			Port:     port,
+			User:     user,
+			Password: password,
+			DBName:   name,
+			SSLMode:  "disable",
+			Logger:   testLogger,
+		}
+
+		baseline, err = gorm.Open(postgres.Open(fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+			host, port, user, password, name)), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+		baselineDB, err := baseline.DB()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(baselineDB.Close)
+
+		Expect(baseline.AutoMigrate(&baselineDevice{}, &baselineReading{})).To(Succeed())
+		Expect(baseline.Create(&baselineDevice{DeviceID: "upgrade-e2e-device", LastSeen: time.Now()}).Error).To(Succeed())
+	})
+
+	// upgrade migrates the baseline database like "demo-app db migrate" and
+	// opens it like a backend.
+	upgrade := func() *gorm.DB {
+		GinkgoHelper()
+
+		Expect(backend.MigrateDB(ctx, cfg)).To(Succeed())
+
+		db, err := backend.NewDB(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { Expect(backend.CloseDB(db, testLogger)).To(Succeed()) })
+		return db
+	}
+
+	seed := func(timestamps ...time.Time) {
+		GinkgoHelper()
+
+		for _, ts := range timestamps {
+			Expect(baseline.Create(&baselineReading{
+				DeviceID:     "upgrade-e2e-device",
+				Timestamp:    ts,
+				Temperature:  20,
+				Humidity:     50,
+				Pressure:     1013,
+				BatteryLevel: 90,
+			}).Error).To(Succeed())
+		}
+	}
+
+	It("should convert a plain sensor_readings table into partitions", func() {
+		now := time.Now().UTC()
+		month := time.Date(now.Year(), now.Month(), 1, 12, 0, 0, 0, time.UTC)
+		seed(month.AddDate(0, -2, 0), month.AddDate(0, -1, 0), month, month.Add(time.Hour), month.AddDate(1, 0, 0))
+
+		db := upgrade()
+
+		var relkind string
+		Expect(db.Raw(`SELECT relkind FROM pg_class WHERE relname = 'sensor_readings'`).Scan(&relkind).Error).To(Succeed())
+		Expect(relkind).To(Equal("p"))
+
+		var count int64
+		Expect(db.Model(&backend.SensorReading{}).Count(&count).Error).To(Succeed())
+		Expect(count).To(Equal(int64(5)))
+
+		// Readings past the months kept ready stay in the default partition
+		var tables []string
+		Expect(db.Raw(`SELECT tableoid::regclass::text FROM sensor_readings ORDER BY "timestamp"`).Scan(&tables).Error).To(Succeed())
+		Expect(tables).To(Equal([]string{
+			month.AddDate(0, -2, 0).Format("sensor_readings_y2006m01"),
+			month.AddDate(0, -1, 0).Format("sensor_readings_y2006m01"),
+			month.Format("sensor_readings_y2006m01"),
+			month.Format("sensor_readings_y2006m01"),
+			"sensor_readings_default",
+		}))
+
+		// New readings continue after the copied IDs
+		reading := backend.SensorReading{DeviceID: "upgrade-e2e-device", Timestamp: month.Add(2 * time.Hour)}
+		Expect(db.Create(&reading).Error).To(Succeed())
+		Expect(db.Model(&backend.SensorReading{}).Count(&count).Error).To(Succeed())
+		Expect(count).To(Equal(int64(6)))
+	})
+
+	It("should drop duplicate readings before making them unique", func() {
+		ts := time.Now().UTC().Truncate(time.Second)
+		seed(ts, ts, ts.Add(time.Second), ts)
+
+		var first baselineReading
+		Expect(baseline.Order("id").First(&first).Error).To(Succeed())
+
+		db := upgrade()
+
+		var readings []backend.SensorReading
+		Expect(db.Order("timestamp").Find(&readings).Error).To(Succeed())
+		Expect(readings).To(HaveLen(2))
+		Expect(readings[0].ID).To(Equal(first.ID))
+
+		var unique []bool
+		Expect(db.Raw(`SELECT i.indisunique FROM pg_index i JOIN pg_class c ON c.oid = i.indexrelid
+			WHERE c.relname = 'idx_device_timestamp'`).Scan(&unique).Error).To(Succeed())
+		Expect(unique).To(Equal([]bool{true}))
+
+		err := db.Create(&backend.SensorReading{DeviceID: "upgrade-e2e-device", Timestamp: ts}).Error
+		Expect(err).To(MatchError(ContainSubstring("duplicate key")))
+	})
+})
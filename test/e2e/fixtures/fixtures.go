@@ -0,0 +1,256 @@
+// Package fixtures creates test data for the e2e tests the way the generator
+// does: devices and sensor readings are published to RabbitMQ, and Create
+// waits until the backend has stored them.
+//
+// Call Use once in BeforeSuite, then build fixtures in the specs:
+//
+//	device := fixtures.NewDevice().WithReadings(5).Create(ctx)
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/protobuf/proto"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+)
+
+// defaultTimeout bounds the wait for the backend to store a fixture.
+const defaultTimeout = 30 * time.Second
+
+// Env holds the connections fixtures are published and verified with.
+type Env struct {
+	// Channel publishes the fixtures
+	Channel *amqp.Channel
+	// DeviceQueue and SensorQueue are the queues consumed by the backend
+	DeviceQueue string
+	SensorQueue string
+	// Client verifies that the backend stored the fixtures
+	Client iotv1.IoTServiceClient
+	// Timeout bounds the wait for each fixture (optional, default 30s)
+	Timeout time.Duration
+}
+
+var (
+	env *Env
+
+	// seq makes generated device IDs, MAC and IP addresses unique.
+	seq atomic.Uint32
+)
+
+// Use sets the environment of all fixtures created afterwards.
+func Use(e *Env) {
+	env = e
+}
+
+// current returns the environment set by Use.
+func current() *Env {
+	GinkgoHelper()
+	Expect(env).NotTo(BeNil(), "fixtures.Use must be called before creating fixtures")
+	return env
+}
+
+func (e *Env) timeout() time.Duration {
+	if e.Timeout > 0 {
+		return e.Timeout
+	}
+	return defaultTimeout
+}
+
+// PublishDevice publishes a device announcement without waiting for it.
+func PublishDevice(ctx context.Context, device *iotv1.IoTDevice) {
+	GinkgoHelper()
+	publish(ctx, current().DeviceQueue, device)
+}
+
+// PublishReading publishes a sensor reading without waiting for it.
+func PublishReading(ctx context.Context, reading *iotv1.SensorReading) {
+	GinkgoHelper()
+	publish(ctx, current().SensorQueue, reading)
+}
+
+func publish(ctx context.Context, queue string, msg proto.Message) {
+	GinkgoHelper()
+
+	body, err := proto.Marshal(msg)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = current().Channel.PublishWithContext(ctx, "", queue, false, false, amqp.Publishing{
+		ContentType:  "application/protobuf",
+		Body:         body,
+		DeliveryMode: amqp.Persistent,
+	})
+	Expect(err).NotTo(HaveOccurred())
+}
+
+// WaitForDevice waits until the backend returns the device.
+func WaitForDevice(ctx context.Context, deviceID string) {
+	GinkgoHelper()
+
+	e := current()
+	Eventually(func() error {
+		resp, err := e.Client.GetDevice(ctx, &iotv1.GetDeviceByIDRequest{DeviceId: deviceID})
+		if err != nil {
+			return err
+		}
+		if resp.GetDevice() == nil {
+			return errors.New("device not yet created")
+		}
+		return nil
+	}, e.timeout(), 250*time.Millisecond).Should(Succeed(), "device %s was not stored", deviceID)
+}
+
+// WaitForReadings waits until the backend stored at least n readings of the device.
+func WaitForReadings(ctx context.Context, deviceID string, n int) {
+	GinkgoHelper()
+
+	e := current()
+	Eventually(func() (int64, error) {
+		resp, err := e.Client.CountReadings(ctx, &iotv1.CountReadingsRequest{DeviceId: deviceID})
+		return resp.GetCount(), err
+	}, e.timeout(), 250*time.Millisecond).Should(BeNumerically(">=", n), "readings of device %s were not stored", deviceID)
+}
+
+// Device is a device created by a DeviceBuilder.
+type Device struct {
+	*iotv1.IoTDevice
+	// Readings are the readings published for the device, oldest first
+	Readings []*iotv1.SensorReading
+}
+
+// ID returns the device ID.
+func (d *Device) ID() string {
+	return d.GetDeviceId()
+}
+
+// DeviceBuilder builds a device and its sensor readings.
+type DeviceBuilder struct {
+	device     *iotv1.IoTDevice
+	timestamps []time.Time
+	reading    func(i int, r *iotv1.SensorReading)
+}
+
+// NewDevice returns a builder for a device with a unique ID and no readings.
+func NewDevice() *DeviceBuilder {
+	n := seq.Add(1)
+
+	return &DeviceBuilder{
+		device: &iotv1.IoTDevice{
+			DeviceId:   fmt.Sprintf("fixture-device-%d-%d", time.Now().UnixNano(), n),
+			Timestamp:  time.Now().Unix(),
+			Location:   fmt.Sprintf("Fixture Location %d", n),
+			MacAddress: fmt.Sprintf("02:00:00:%02X:%02X:%02X", byte(n>>16), byte(n>>8), byte(n)),
+			IpAddress:  fmt.Sprintf("10.%d.%d.%d", byte(n>>16), byte(n>>8), byte(n)),
+			Firmware:   "v1.0.0",
+			Latitude:   40.0,
+			Longitude:  -120.0,
+		},
+	}
+}
+
+// WithID sets the device ID.
+func (b *DeviceBuilder) WithID(id string) *DeviceBuilder {
+	b.device.DeviceId = id
+	return b
+}
+
+// WithLocation sets the device location.
+func (b *DeviceBuilder) WithLocation(location string) *DeviceBuilder {
+	b.device.Location = location
+	return b
+}
+
+// WithFirmware sets the device firmware version.
+func (b *DeviceBuilder) WithFirmware(firmware string) *DeviceBuilder {
+	b.device.Firmware = firmware
+	return b
+}
+
+// WithNetwork sets the device MAC and IP address.
+func (b *DeviceBuilder) WithNetwork(mac, ip string) *DeviceBuilder {
+	b.device.MacAddress = mac
+	b.device.IpAddress = ip
+	return b
+}
+
+// WithCoordinates sets the device latitude and longitude.
+func (b *DeviceBuilder) WithCoordinates(lat, lon float32) *DeviceBuilder {
+	b.device.Latitude = lat
+	b.device.Longitude = lon
+	return b
+}
+
+// WithReadings adds n readings, one second apart, starting now.
+func (b *DeviceBuilder) WithReadings(n int) *DeviceBuilder {
+	now := time.Now()
+	for i := range n {
+		b.timestamps = append(b.timestamps, now.Add(time.Duration(i)*time.Second))
+	}
+	return b
+}
+
+// WithReadingsAt adds one reading per timestamp.
+func (b *DeviceBuilder) WithReadingsAt(timestamps ...time.Time) *DeviceBuilder {
+	b.timestamps = append(b.timestamps, timestamps...)
+	return b
+}
+
+// WithReadingValues lets fn set the values of the i-th reading. By default,
+// readings have plausible values that vary with i.
+func (b *DeviceBuilder) WithReadingValues(fn func(i int, r *iotv1.SensorReading)) *DeviceBuilder {
+	b.reading = fn
+	return b
+}
+
+// Build returns the device and its readings without publishing them.
+func (b *DeviceBuilder) Build() *Device {
+	device := proto.Clone(b.device).(*iotv1.IoTDevice)
+
+	readings := make([]*iotv1.SensorReading, len(b.timestamps))
+	for i, ts := range b.timestamps {
+		r := &iotv1.SensorReading{
+			DeviceId:     device.GetDeviceId(),
+			Timestamp:    ts.Unix(),
+			Temperature:  20.0 + float64(i%10),
+			Humidity:     50.0 + float64(i%10),
+			Pressure:     1000.0 + float64(i%10),
+			BatteryLevel: 90.0 - float64(i%10),
+		}
+		if b.reading != nil {
+			b.reading(i, r)
+		}
+		readings[i] = r
+	}
+
+	return &Device{IoTDevice: device, Readings: readings}
+}
+
+// Create publishes the device, waits until the backend stored it, then
+// publishes its readings and waits until the backend stored them. It fails the
+// spec if the backend does not store them in time.
+func (b *DeviceBuilder) Create(ctx context.Context) *Device {
+	GinkgoHelper()
+
+	device := b.Build()
+
+	PublishDevice(ctx, device.IoTDevice)
+	WaitForDevice(ctx, device.ID())
+
+	if len(device.Readings) == 0 {
+		return device
+	}
+
+	for _, r := range device.Readings {
+		PublishReading(ctx, r)
+	}
+	WaitForReadings(ctx, device.ID(), len(device.Readings))
+
+	return device
+}
@@ -19,7 +19,7 @@ import (
 
 	"procodus.dev/demo-app/internal/backend"
 	"procodus.dev/demo-app/internal/frontend"
-	"procodus.dev/demo-app/pkg/iot"
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
 	e2econtainers "procodus.dev/demo-app/test/e2e/testcontainers"
 )
 
@@ -103,7 +103,7 @@ var _ = BeforeSuite(func() {
 	logger.Info("gRPC server listening", "address", grpcAddr)
 
 	grpcServer = grpc.NewServer()
-	iot.RegisterIoTServiceServer(grpcServer, iotService)
+	iotv1.RegisterIoTServiceServer(grpcServer, iotService)
 
 	go func() {
 		if err := grpcServer.Serve(listener); err != nil {
@@ -178,8 +178,8 @@ var _ = AfterSuite(func() {
 })
 
 // Helper function to create test devices in the database.
-func createTestDevice(_ context.Context, deviceID string) *iot.IoTDevice {
-	device := &iot.IoTDevice{
+func createTestDevice(_ context.Context, deviceID string) *iotv1.IoTDevice {
+	device := &iotv1.IoTDevice{
 		DeviceId:   deviceID,
 		Timestamp:  time.Now().Unix(),
 		Location:   "Test Location",
@@ -209,8 +209,8 @@ func createTestDevice(_ context.Context, deviceID string) *iot.IoTDevice {
 }
 
 // Helper function to create test sensor reading.
-func createTestSensorReading(_ context.Context, deviceID string, timestamp time.Time) *iot.SensorReading {
-	reading := &iot.SensorReading{
+func createTestSensorReading(_ context.Context, deviceID string, timestamp time.Time) *iotv1.SensorReading {
+	reading := &iotv1.SensorReading{
 		DeviceId:     deviceID,
 		Timestamp:    timestamp.Unix(),
 		Temperature:  25.5,
@@ -3,10 +3,12 @@ package mq
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	amqp "github.com/rabbitmq/amqp091-go"
 
 	clientmq "procodus.dev/demo-app/pkg/mq"
 )
@@ -38,6 +40,49 @@ var _ = Describe("MQ Client E2E", func() {
 			time.Sleep(1 * time.Second)
 		})
 
+		It("should declare durable queues when configured", func() {
+			var err error
+			client, err = clientmq.NewWithOptions(context.Background(), rabbitmqURL, clientmq.Options{
+				Queues:  []string{queueName},
+				Durable: true,
+			}, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.WaitReady(context.Background())).To(Succeed())
+			Expect(client.Push(context.Background(), []byte("persistent"))).To(Succeed())
+
+			// Redeclaring as durable fails with PRECONDITION_FAILED unless it is
+			conn, err := amqp.Dial(rabbitmqURL)
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = conn.Close() }()
+			ch, err := conn.Channel()
+			Expect(err).NotTo(HaveOccurred())
+
+			queue, err := ch.QueueDeclare(queueName, true, false, false, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queue.Messages).To(Equal(1))
+		})
+
+		It("should deliver messages of a priority queue by priority", func() {
+			var err error
+			client, err = clientmq.NewWithOptions(context.Background(), rabbitmqURL, clientmq.Options{
+				Queues:      []string{queueName},
+				MaxPriority: clientmq.MaxPriority,
+			}, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.WaitReady(context.Background())).To(Succeed())
+
+			Expect(client.Push(context.Background(), []byte("reading"))).To(Succeed())
+			Expect(client.PushWithOptions(context.Background(), []byte("device"), clientmq.PublishOptions{Priority: clientmq.MaxPriority})).To(Succeed())
+
+			deliveries, err := client.Consume()
+			Expect(err).NotTo(HaveOccurred())
+
+			var delivery amqp.Delivery
+			Eventually(deliveries, 5*time.Second).Should(Receive(&delivery))
+			Expect(string(delivery.Body)).To(Equal("device"))
+			Expect(delivery.Ack(false)).To(Succeed())
+		})
+
 		It("should handle invalid URL gracefully", func() {
 			invalidClient := clientmq.New("test-queue", "amqp://invalid:5672", testLogger)
 			Expect(invalidClient).NotTo(BeNil())
@@ -93,6 +138,56 @@ var _ = Describe("MQ Client E2E", func() {
 			}
 		})
 
+		It("should publish a batch of messages", func() {
+			batch := make([][]byte, 100)
+			for i := range batch {
+				batch[i] = []byte("batch message")
+			}
+
+			err := client.PushBatch(context.Background(), batch)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should confirm concurrent pushes and batches", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			// Both wait for confirmations of the same channel
+			var wg sync.WaitGroup
+			errs := make(chan error, 20)
+			for range 10 {
+				wg.Go(func() {
+					for range 10 {
+						errs <- client.Push(ctx, []byte("single message"))
+					}
+				})
+				wg.Go(func() {
+					batch := make([][]byte, 10)
+					for i := range batch {
+						batch[i] = []byte("batch message")
+					}
+					errs <- client.PushBatch(ctx, batch)
+				})
+			}
+			go func() {
+				wg.Wait()
+				close(errs)
+			}()
+			for err := range errs {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			conn, err := amqp.Dial(rabbitmqURL)
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = conn.Close() }()
+			ch, err := conn.Channel()
+			Expect(err).NotTo(HaveOccurred())
+
+			queue, err := ch.QueueDeclarePassive(queueName, false, false, false, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queue.Messages).To(Equal(200))
+		})
+
 		It("should use UnsafePush without blocking", func() {
 			message := []byte("unsafe message")
 			err := client.UnsafePush(context.Background(), message)
@@ -35,12 +35,12 @@ var _ = BeforeSuite(func() {
 
 	testLogger.Info("starting RabbitMQ container for E2E tests")
 
-	// Start RabbitMQ container using helper
+	// Start RabbitMQ container using helper. The container is not named so
+	// that parallel CI jobs on the same Docker host do not collide.
 	var err error
 	mqContainer, rabbitmqURL, err = e2econtainers.StartRabbitMQ(ctx, &e2econtainers.RabbitMQConfig{
-		User:          "guest",
-		Password:      "guest",
-		ContainerName: "rabbitmq-e2e-test",
+		User:     "guest",
+		Password: "guest",
 	})
 
 	if err != nil {
@@ -0,0 +1,94 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	iotv1 "procodus.dev/demo-app/pkg/iot/v1"
+	clientmq "procodus.dev/demo-app/pkg/mq"
+	e2econtainers "procodus.dev/demo-app/test/e2e/testcontainers"
+)
+
+// BenchmarkPush compares confirmed single pushes with batches against a real
+// broker. Run it without the specs:
+//
+//	go test -run='^$' -bench=Push ./test/e2e/mq
+func BenchmarkPush(b *testing.B) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	container, url, err := e2econtainers.StartRabbitMQ(ctx, nil)
+	if err != nil {
+		b.Fatalf("failed to start RabbitMQ container: %v", err)
+	}
+	b.Cleanup(func() { _ = container.Terminate(ctx) })
+
+	body, err := proto.Marshal(&iotv1.SensorReading{
+		DeviceId:     "bench-device",
+		Timestamp:    time.Now().Unix(),
+		Temperature:  22.5,
+		Humidity:     60,
+		Pressure:     1013,
+		BatteryLevel: 90,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	newClient := func(b *testing.B) *clientmq.Client {
+		client, err := clientmq.NewWithContext(ctx, "bench-"+b.Name(), url, logger)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Cleanup(func() { _ = client.Close() })
+
+		if err := client.WaitReady(ctx); err != nil {
+			b.Fatal(err)
+		}
+		return client
+	}
+
+	// ns/msg makes single pushes and batches comparable
+	reportPerMessage := func(b *testing.B, messages int) {
+		b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(messages), "ns/msg")
+	}
+
+	b.Run("single", func(b *testing.B) {
+		client := newClient(b)
+
+		n := 0
+		for b.Loop() {
+			if err := client.Push(ctx, body); err != nil {
+				b.Fatal(err)
+			}
+			n++
+		}
+		reportPerMessage(b, n)
+	})
+
+	for _, size := range []int{10, 100} {
+		b.Run(fmt.Sprintf("batch-%d", size), func(b *testing.B) {
+			client := newClient(b)
+
+			batch := make([][]byte, size)
+			for i := range batch {
+				batch[i] = body
+			}
+
+			n := 0
+			for b.Loop() {
+				if err := client.PushBatch(ctx, batch); err != nil {
+					b.Fatal(err)
+				}
+				n += size
+			}
+			reportPerMessage(b, n)
+		})
+	}
+}
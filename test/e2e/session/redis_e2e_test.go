@@ -0,0 +1,89 @@
+// Package session provides end-to-end tests for the Redis session store.
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"procodus.dev/demo-app/pkg/session"
+)
+
+var _ = Describe("RedisStore", func() {
+	var (
+		ctx   context.Context
+		store *session.RedisStore
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		store, err = session.NewRedisStore(&session.RedisConfig{
+			URL:       redisURL,
+			KeyPrefix: "e2e:" + CurrentSpecReport().LeafNodeText + ":",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Ping(ctx)).To(Succeed())
+
+		DeferCleanup(func() {
+			Expect(store.Close()).To(Succeed())
+		})
+	})
+
+	It("should save, load and delete records", func() {
+		record := &session.Record{
+			Values:  map[string]string{"prefs": "theme=dark"},
+			SavedAt: time.Now().UTC().Truncate(time.Second),
+		}
+		Expect(store.Save(ctx, "abc", record, time.Minute)).To(Succeed())
+
+		loaded, err := store.Load(ctx, "abc")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Values).To(Equal(record.Values))
+		Expect(loaded.SavedAt.Equal(record.SavedAt)).To(BeTrue())
+
+		Expect(store.Delete(ctx, "abc")).To(Succeed())
+		_, err = store.Load(ctx, "abc")
+		Expect(err).To(MatchError(session.ErrNotFound))
+	})
+
+	It("should expire records after their TTL", func() {
+		Expect(store.Save(ctx, "short", &session.Record{}, time.Second)).To(Succeed())
+
+		Eventually(func() error {
+			_, err := store.Load(ctx, "short")
+			return err
+		}).WithTimeout(5 * time.Second).WithPolling(200 * time.Millisecond).Should(MatchError(session.ErrNotFound))
+	})
+
+	It("should share sessions between managers of different replicas", func() {
+		newManager := func() *session.Manager {
+			m, err := session.NewManager(&session.ManagerConfig{Store: store, Logger: testLogger})
+			Expect(err).NotTo(HaveOccurred())
+			return m
+		}
+		first, second := newManager(), newManager()
+
+		rec := httptest.NewRecorder()
+		first.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			session.FromContext(r.Context()).Set("theme", "dark")
+		})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		cookies := rec.Result().Cookies()
+		Expect(cookies).To(HaveLen(1))
+
+		var theme string
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(cookies[0])
+		second.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			theme = session.FromContext(r.Context()).Get("theme")
+		})).ServeHTTP(httptest.NewRecorder(), req)
+
+		Expect(theme).To(Equal("dark"))
+	})
+})
@@ -0,0 +1,57 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+
+	e2econtainers "procodus.dev/demo-app/test/e2e/testcontainers"
+)
+
+var (
+	redisURL       string
+	testLogger     *slog.Logger
+	redisContainer testcontainers.Container
+)
+
+func TestSessionE2E(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Session E2E Suite")
+}
+
+var _ = BeforeSuite(func() {
+	ctx := context.Background()
+
+	testLogger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+
+	testLogger.Info("starting Redis container for E2E tests")
+
+	var err error
+	redisContainer, redisURL, err = e2econtainers.StartRedis(ctx, nil)
+	if err != nil {
+		Fail(fmt.Sprintf("Failed to start Redis container: %v", err))
+	}
+
+	testLogger.Info("Redis container started",
+		"container_id", redisContainer.GetContainerID(),
+		"url", redisURL,
+	)
+})
+
+var _ = AfterSuite(func() {
+	if redisContainer != nil {
+		ctx := context.Background()
+		testLogger.Info("stopping Redis container", "container_id", redisContainer.GetContainerID())
+		if err := redisContainer.Terminate(ctx); err != nil {
+			testLogger.Error("failed to stop Redis container", "error", err)
+		}
+	}
+})
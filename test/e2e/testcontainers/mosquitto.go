@@ -0,0 +1,67 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// MosquittoConfig holds configuration for Mosquitto (MQTT broker) test container.
+type MosquittoConfig struct {
+	// ContainerName is the name of the container (optional)
+	ContainerName string
+}
+
+// StartMosquitto starts a Mosquitto MQTT broker container for testing and returns the container and broker URL.
+// The broker accepts anonymous connections.
+func StartMosquitto(ctx context.Context, config *MosquittoConfig) (testcontainers.Container, string, error) {
+	// Set defaults
+	if config == nil {
+		config = &MosquittoConfig{}
+	}
+
+	// Start container
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "eclipse-mosquitto:2",
+			ExposedPorts: []string{"1883/tcp"},
+			// Mosquitto 2 only listens on localhost without a config; the image
+			// ships one that listens on all interfaces without authentication
+			Cmd: []string{"mosquitto", "-c", "/mosquitto-no-auth.conf"},
+			WaitingFor: wait.ForAll(
+				wait.ForListeningPort("1883/tcp"),
+				wait.ForLog("running"),
+			),
+			Name: config.ContainerName,
+		},
+		Started: true,
+	})
+
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start Mosquitto container: %w", err)
+	}
+
+	// Get host and port
+	host, err := container.Host(ctx)
+	if err != nil {
+		if termErr := container.Terminate(ctx); termErr != nil {
+			return nil, "", fmt.Errorf("failed to get container host: %w (cleanup error: %w)", err, termErr)
+		}
+		return nil, "", fmt.Errorf("failed to get container host: %w", err)
+	}
+
+	port, err := container.MappedPort(ctx, "1883")
+	if err != nil {
+		if termErr := container.Terminate(ctx); termErr != nil {
+			return nil, "", fmt.Errorf("failed to get container port: %w (cleanup error: %w)", err, termErr)
+		}
+		return nil, "", fmt.Errorf("failed to get container port: %w", err)
+	}
+
+	// Build broker URL
+	url := fmt.Sprintf("tcp://%s:%s", host, port.Port())
+
+	return container, url, nil
+}
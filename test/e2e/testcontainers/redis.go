@@ -0,0 +1,74 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// RedisConfig holds configuration for Redis test container.
+type RedisConfig struct {
+	// Password is the Redis password (optional, no authentication if empty)
+	Password string
+	// ContainerName is the name of the container (optional)
+	ContainerName string
+}
+
+// StartRedis starts a Redis container for testing and returns the container and connection URL.
+func StartRedis(ctx context.Context, config *RedisConfig) (testcontainers.Container, string, error) {
+	// Set defaults
+	if config == nil {
+		config = &RedisConfig{}
+	}
+
+	cmd := []string{"redis-server"}
+	if config.Password != "" {
+		cmd = append(cmd, "--requirepass", config.Password)
+	}
+
+	// Start container
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "redis:7-alpine",
+			ExposedPorts: []string{"6379/tcp"},
+			Cmd:          cmd,
+			WaitingFor: wait.ForAll(
+				wait.ForListeningPort("6379/tcp"),
+				wait.ForLog("Ready to accept connections"),
+			),
+			Name: config.ContainerName,
+		},
+		Started: true,
+	})
+
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start Redis container: %w", err)
+	}
+
+	// Get host and port
+	host, err := container.Host(ctx)
+	if err != nil {
+		if termErr := container.Terminate(ctx); termErr != nil {
+			return nil, "", fmt.Errorf("failed to get container host: %w (cleanup error: %w)", err, termErr)
+		}
+		return nil, "", fmt.Errorf("failed to get container host: %w", err)
+	}
+
+	port, err := container.MappedPort(ctx, "6379")
+	if err != nil {
+		if termErr := container.Terminate(ctx); termErr != nil {
+			return nil, "", fmt.Errorf("failed to get container port: %w (cleanup error: %w)", err, termErr)
+		}
+		return nil, "", fmt.Errorf("failed to get container port: %w", err)
+	}
+
+	// Build connection URL
+	url := fmt.Sprintf("redis://%s:%s/0", host, port.Port())
+	if config.Password != "" {
+		url = fmt.Sprintf("redis://:%s@%s:%s/0", config.Password, host, port.Port())
+	}
+
+	return container, url, nil
+}